@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+
+	"terraform-cost/db"
+	"terraform-cost/db/ingestion"
+
+	"github.com/google/uuid"
+)
+
+// runDrift compares OLD_SNAPSHOT with NEW_SNAPSHOT and prints the drift report
+func runDrift(ctx context.Context, store db.PricingStore, w io.Writer, oldSnapshot, newSnapshot string) error {
+	if oldSnapshot == "" || newSnapshot == "" {
+		return fmt.Errorf("OLD_SNAPSHOT and NEW_SNAPSHOT environment variables are required for MODE=drift")
+	}
+	oldID, err := uuid.Parse(oldSnapshot)
+	if err != nil {
+		return fmt.Errorf("invalid OLD_SNAPSHOT %q: %w", oldSnapshot, err)
+	}
+	newID, err := uuid.Parse(newSnapshot)
+	if err != nil {
+		return fmt.Errorf("invalid NEW_SNAPSHOT %q: %w", newSnapshot, err)
+	}
+
+	summary, err := ingestion.NewDriftDetector(store).DetectDrift(ctx, oldID, newID)
+	if err != nil {
+		return err
+	}
+	return formatDriftReport(w, summary)
+}
+
+// formatDriftReport renders the summary and the significant changes grouped by service
+func formatDriftReport(w io.Writer, summary *ingestion.DriftSummary) error {
+	fmt.Fprintf(w, "Old snapshot:  %s\n", summary.OldSnapshotID)
+	fmt.Fprintf(w, "New snapshot:  %s\n", summary.NewSnapshotID)
+	fmt.Fprintf(w, "%s\n\n", summary)
+
+	significant := summary.GetSignificantRecords()
+	if len(significant) == 0 {
+		fmt.Fprintln(w, "No significant changes.")
+		return nil
+	}
+
+	byService := make(map[string][]ingestion.DriftRecord)
+	for _, r := range significant {
+		byService[r.Service] = append(byService[r.Service], r)
+	}
+	services := make([]string, 0, len(byService))
+	for svc := range byService {
+		services = append(services, svc)
+	}
+	sort.Strings(services)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, svc := range services {
+		records := byService[svc]
+		sort.Slice(records, func(i, j int) bool {
+			a, b := records[i], records[j]
+			if a.ProductFamily != b.ProductFamily {
+				return a.ProductFamily < b.ProductFamily
+			}
+			if a.DimensionValue != b.DimensionValue {
+				return a.DimensionValue < b.DimensionValue
+			}
+			return a.Unit < b.Unit
+		})
+
+		fmt.Fprintf(tw, "%s (%d significant)\n", svc, len(records))
+		for _, r := range records {
+			fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\t%s\n", r.ProductFamily, r.DimensionValue, r.Unit, driftPrices(r), driftChange(r))
+		}
+	}
+	return tw.Flush()
+}
+
+// driftPrices formats the old -> new price, with "-" for a missing side
+func driftPrices(r ingestion.DriftRecord) string {
+	switch r.DriftType {
+	case ingestion.DriftNew:
+		return fmt.Sprintf("- -> %s", r.NewPrice)
+	case ingestion.DriftRemoved:
+		return fmt.Sprintf("%s -> -", r.OldPrice)
+	}
+	return fmt.Sprintf("%s -> %s", r.OldPrice, r.NewPrice)
+}
+
+// driftChange formats the percent change, or the drift type for added and removed rates
+func driftChange(r ingestion.DriftRecord) string {
+	switch r.DriftType {
+	case ingestion.DriftNew, ingestion.DriftRemoved:
+		return string(r.DriftType)
+	}
+	return fmt.Sprintf("%+.2f%%", r.PercentChange)
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"terraform-cost/db/ingestion"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+func TestFormatDriftReport(t *testing.T) {
+	summary := &ingestion.DriftSummary{
+		OldSnapshotID:      uuid.MustParse("11111111-1111-1111-1111-111111111111"),
+		NewSnapshotID:      uuid.MustParse("22222222-2222-2222-2222-222222222222"),
+		TotalChanges:       4,
+		PriceIncreases:     2,
+		NewRates:           1,
+		SignificantChanges: 3,
+		Records: []ingestion.DriftRecord{
+			{Service: "AmazonS3", ProductFamily: "Storage", DimensionValue: "standard", Unit: "GB-month",
+				OldPrice: decimal.RequireFromString("0.023"), NewPrice: decimal.RequireFromString("0.025"),
+				PercentChange: 8.6956, DriftType: ingestion.DriftIncrease, IsSignificant: true},
+			{Service: "AmazonEC2", ProductFamily: "Compute Instance", DimensionValue: "m5.large,linux", Unit: "hours",
+				OldPrice: decimal.RequireFromString("0.096"), NewPrice: decimal.RequireFromString("0.1056"),
+				PercentChange: 10, DriftType: ingestion.DriftIncrease, IsSignificant: true},
+			{Service: "AmazonEC2", ProductFamily: "Compute Instance", DimensionValue: "m7i.large,linux", Unit: "hours",
+				NewPrice: decimal.RequireFromString("0.1008"), PercentChange: 100, DriftType: ingestion.DriftNew, IsSignificant: true},
+			{Service: "AmazonEC2", ProductFamily: "Compute Instance", DimensionValue: "t3.micro,linux", Unit: "hours",
+				OldPrice: decimal.RequireFromString("0.0104"), NewPrice: decimal.RequireFromString("0.0105"),
+				PercentChange: 0.96, DriftType: ingestion.DriftIncrease},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := formatDriftReport(&buf, summary); err != nil {
+		t.Fatalf("format failed: %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"Old snapshot:  11111111-1111-1111-1111-111111111111",
+		"4 changes (3 significant)",
+		"AmazonEC2 (2 significant)",
+		"AmazonS3 (1 significant)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in report:\n%s", want, out)
+		}
+	}
+
+	lines := strings.Split(out, "\n")
+	find := func(dim string) []string {
+		for _, l := range lines {
+			if f := strings.Fields(l); len(f) > 0 && strings.Contains(l, " "+dim+" ") {
+				return f
+			}
+		}
+		t.Fatalf("no line for %s in report:\n%s", dim, out)
+		return nil
+	}
+	if got := strings.Join(find("m5.large,linux")[3:], " "); got != "hours 0.096 -> 0.1056 +10.00%" {
+		t.Errorf("m5.large line = %q", got)
+	}
+	if got := strings.Join(find("m7i.large,linux")[3:], " "); got != "hours - -> 0.1008 new" {
+		t.Errorf("m7i.large line = %q", got)
+	}
+	if got := strings.Join(find("standard")[2:], " "); got != "GB-month 0.023 -> 0.025 +8.70%" {
+		t.Errorf("S3 line = %q", got)
+	}
+	if strings.Contains(out, "t3.micro") {
+		t.Error("insignificant change should not be listed")
+	}
+
+	// EC2 is listed before S3
+	if strings.Index(out, "AmazonEC2") > strings.Index(out, "AmazonS3") {
+		t.Error("expected services in alphabetical order")
+	}
+}
+
+func TestFormatDriftReportNoChanges(t *testing.T) {
+	var buf bytes.Buffer
+	if err := formatDriftReport(&buf, &ingestion.DriftSummary{}); err != nil {
+		t.Fatalf("format failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "No significant changes.") {
+		t.Errorf("unexpected report:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"terraform-cost/db"
+	"terraform-cost/db/ingestion"
+)
+
+// runFreshness lists active snapshots older than maxAge (MAX_AGE, default 7 days)
+// and fails when any are found so schedulers can alert on the exit code
+func runFreshness(ctx context.Context, store db.PricingStore, w io.Writer, maxAgeEnv string) error {
+	maxAge := ingestion.DefaultMaxSnapshotAge
+	if maxAgeEnv != "" {
+		d, err := time.ParseDuration(maxAgeEnv)
+		if err != nil {
+			return fmt.Errorf("invalid MAX_AGE %q: %w", maxAgeEnv, err)
+		}
+		maxAge = d
+	}
+
+	stale, err := ingestion.FreshnessReport(ctx, store, maxAge)
+	if err != nil {
+		return err
+	}
+	if len(stale) == 0 {
+		fmt.Fprintf(w, "All active snapshots fetched within %s\n", maxAge)
+		return nil
+	}
+
+	if err := formatStaleSnapshots(w, stale); err != nil {
+		return err
+	}
+	return fmt.Errorf("%d active snapshot(s) older than %s", len(stale), maxAge)
+}
+
+// formatStaleSnapshots renders stale snapshots as an aligned table
+func formatStaleSnapshots(w io.Writer, stale []ingestion.StaleSnapshot) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tCLOUD\tREGION\tALIAS\tFETCHED\tAGE")
+	for _, s := range stale {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			s.SnapshotID, s.Cloud, s.Region, s.Alias,
+			s.FetchedAt.UTC().Format("2006-01-02 15:04:05"),
+			s.Age.Round(time.Hour),
+		)
+	}
+	return tw.Flush()
+}
@@ -1,14 +1,21 @@
 package main
 
 import (
+	"compress/gzip"
 	"context"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	"terraform-cost/db"
 	"terraform-cost/db/ingestion"
+	"terraform-cost/db/regions"
 
 	"github.com/golang-migrate/migrate/v4"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
@@ -24,39 +31,129 @@ func main() {
 
 func run() error {
 	// 1. Configuration from Environment
+	mode := os.Getenv("MODE")
+	if mode == "" {
+		mode = "ingest"
+	}
+
+	// Backup pruning only touches disk
+	if mode == "prune-backups" {
+		return runPruneBackups(os.Stdout, os.Getenv("BACKUP_DIR"), os.Getenv("BACKUP_KEEP_LAST"), os.Getenv("BACKUP_MAX_AGE"))
+	}
+
+	// The self-test runs against stub data and an in-memory store
+	if mode == "selftest" {
+		logger, err := ingestion.NewLogger(os.Getenv("LOG_FORMAT"), os.Stderr)
+		if err != nil {
+			return err
+		}
+		ctx, stop := interruptContext(context.Background())
+		defer stop()
+		return runSelftest(ctx, os.Stdout, logger)
+	}
+
 	dbURL := os.Getenv("DB_URL")
 	if dbURL == "" {
 		return fmt.Errorf("DB_URL environment variable is required")
 	}
 
-	cloud := db.CloudProvider(os.Getenv("CLOUD"))
-	if cloud == "" {
-		cloud = db.AWS
+	// Migration modes manage the schema without opening the pricing store
+	switch mode {
+	case "migrate-status":
+		return runMigrateStatus(dbURL, os.Stdout)
+	case "migrate-up":
+		return runMigrateUp(dbURL, os.Stdout)
+	case "migrate-down":
+		return runMigrateDown(dbURL, os.Stdout, os.Getenv("MIGRATE_STEPS"))
 	}
 
-	region := os.Getenv("REGION")
-	if region == "" {
-		region = "us-east-1"
+	// 2. Connect to Database
+	// SIGINT/SIGTERM (e.g. pod eviction) cancel the context so an in-flight
+	// commit rolls back instead of being killed mid-transaction
+	ctx, stop := interruptContext(context.Background())
+	defer stop()
+	store, err := connectStore(ctx, dbURL)
+	if err != nil {
+		return err
 	}
+	defer store.Close()
 
-	// 2. Connect to Database
+	switch mode {
+	case "ingest":
+		return runIngest(ctx, store, dbURL)
+	case "list":
+		return runList(ctx, store, os.Stdout, db.CloudProvider(os.Getenv("CLOUD")), os.Getenv("REGION"))
+	case "inspect":
+		return runInspect(ctx, store, os.Stdout, os.Getenv("SNAPSHOT_ID"))
+	case "verify":
+		return runVerify(ctx, store, os.Stdout, os.Getenv("SNAPSHOT_ID"), os.Getenv("BACKUP_PATH"))
+	case "freshness":
+		return runFreshness(ctx, store, os.Stdout, os.Getenv("MAX_AGE"))
+	case "rollback":
+		return runRollback(ctx, store, os.Stdout, db.CloudProvider(os.Getenv("CLOUD")), os.Getenv("REGION"), os.Getenv("PROVIDER_ALIAS"))
+	case "drift":
+		return runDrift(ctx, store, os.Stdout, os.Getenv("OLD_SNAPSHOT"), os.Getenv("NEW_SNAPSHOT"))
+	case "reprocess":
+		return runReprocess(ctx, store, os.Getenv("RAW_PATH"))
+	case "promote":
+		sourceURL := os.Getenv("SOURCE_DB_URL")
+		if sourceURL == "" {
+			return fmt.Errorf("SOURCE_DB_URL environment variable is required for MODE=promote")
+		}
+		source, err := connectStore(ctx, sourceURL)
+		if err != nil {
+			return err
+		}
+		defer source.Close()
+		return runPromote(ctx, source, store, os.Stdout, os.Getenv("SNAPSHOT_ID"))
+	default:
+		return fmt.Errorf("unknown MODE %q (expected ingest, list, inspect, verify, freshness, rollback, drift, reprocess, promote, selftest, prune-backups, migrate-status, migrate-up or migrate-down)", mode)
+	}
+}
+
+// interruptContext returns a context cancelled on SIGINT or SIGTERM
+func interruptContext(parent context.Context) (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(parent, os.Interrupt, syscall.SIGTERM)
+}
+
+// cancelled reports an ingestion stopped by a signal
+func cancelled(ctx context.Context, w io.Writer) error {
+	fmt.Fprintln(w, "Ingestion cancelled: the open transaction was rolled back and no snapshot was activated")
+	return fmt.Errorf("ingestion cancelled: %w", ctx.Err())
+}
+
+// connectStore opens the pricing store and waits for the database to accept connections
+func connectStore(ctx context.Context, dbURL string) (*db.PostgresStore, error) {
 	store, err := db.NewPostgresStoreFromURL(dbURL)
 	if err != nil {
-		return fmt.Errorf("failed to connect to database: %w", err)
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
-	defer store.Close()
 
 	// Wait for DB to be potentially ready (retry logic)
-	ctx := context.Background()
 	for i := 0; i < 30; i++ {
 		if err := store.Ping(ctx); err == nil {
-			fmt.Println("Connected to database successfully")
+			fmt.Fprintln(os.Stderr, "Connected to database successfully")
 			break
 		}
-		fmt.Printf("Waiting for database... (%d/30)\n", i+1)
+		fmt.Fprintf(os.Stderr, "Waiting for database... (%d/30)\n", i+1)
 		time.Sleep(1 * time.Second)
 	}
 
+	return store, nil
+}
+
+// runIngest fetches, validates and commits a fresh snapshot
+func runIngest(ctx context.Context, store db.PricingStore, dbURL string) error {
+	cloud := db.CloudProvider(os.Getenv("CLOUD"))
+	if cloud == "" {
+		cloud = db.AWS
+	}
+
+	region := os.Getenv("REGION")
+	if region == "" {
+		region = "us-east-1"
+	}
+
 	// 2a. Run Migrations
 	if err := runMigrations(dbURL); err != nil {
 		return fmt.Errorf("migration failed: %w", err)
@@ -70,6 +167,12 @@ func run() error {
 		return fmt.Errorf("failed to get fetcher: %w", err)
 	}
 
+	// AWS_SPOT ingests EC2 spot prices under their own alias
+	spot := cloud == db.AWS && os.Getenv("AWS_SPOT") == "true"
+	if spot {
+		fetcher = ingestion.NewAWSSpotFetcher(nil)
+	}
+
 	// Filter services for dev environment if requested
 	if servicesEnv := os.Getenv("SERVICES"); servicesEnv != "" {
 		// Define interface for configurable fetchers to avoid direct typing if possible,
@@ -87,9 +190,58 @@ func run() error {
 		}
 	}
 
-	normalizer, err := registry.GetNormalizer(cloud)
+	// SERVICE_TIMEOUT stops one slow service from using the whole fetch budget
+	if timeoutEnv := os.Getenv("SERVICE_TIMEOUT"); timeoutEnv != "" {
+		timeout, err := parseServiceTimeout(timeoutEnv)
+		if err != nil {
+			return err
+		}
+		type serviceTimeoutConfigurable interface {
+			SetServiceTimeout(t ingestion.ServiceTimeout)
+		}
+		if configurable, ok := fetcher.(serviceTimeoutConfigurable); ok {
+			configurable.SetServiceTimeout(timeout)
+		} else {
+			fmt.Printf("Warning: Fetcher for %s does not support per-service timeouts\n", cloud)
+		}
+	}
+
+	// PROXY_URL, CA_BUNDLE, USER_AGENT and TLS_MIN_VERSION shape outbound calls
+	transport, err := parseTransport(os.Getenv("PROXY_URL"), os.Getenv("CA_BUNDLE"), os.Getenv("USER_AGENT"), os.Getenv("TLS_MIN_VERSION"))
+	if err != nil {
+		return err
+	}
+	if transport != nil {
+		type transportConfigurable interface {
+			SetTransport(rt http.RoundTripper)
+		}
+		if configurable, ok := fetcher.(transportConfigurable); ok {
+			configurable.SetTransport(transport)
+		} else {
+			fmt.Printf("Warning: Fetcher for %s does not support transport settings\n", cloud)
+		}
+	}
+
+	// FETCH_CACHE_DIR serves repeated development runs from disk; wrapped last
+	// so the settings above reach the real fetcher
+	if cacheDir := os.Getenv("FETCH_CACHE_DIR"); cacheDir != "" {
+		var ttl time.Duration
+		if ttlEnv := os.Getenv("FETCH_CACHE_TTL"); ttlEnv != "" {
+			if ttl, err = time.ParseDuration(ttlEnv); err != nil || ttl <= 0 {
+				return fmt.Errorf("invalid FETCH_CACHE_TTL %q, expected a positive duration like 6h", ttlEnv)
+			}
+		}
+		fmt.Printf("Caching raw fetches in %s\n", cacheDir)
+		cache := ingestion.NewCachingFetcher(fetcher, cacheDir, ttl)
+		if spot {
+			cache.SetAlias(ingestion.SpotAlias)
+		}
+		fetcher = cache
+	}
+
+	normalizer, err := newNormalizer(registry, cloud)
 	if err != nil {
-		return fmt.Errorf("failed to get normalizer: %w", err)
+		return err
 	}
 
 	// 4. Setup Lifecycle
@@ -102,20 +254,109 @@ func run() error {
 		return fmt.Errorf("failed to create backup dir: %w", err)
 	}
 
-	lifecycle := ingestion.NewLifecycle(fetcher, normalizer, store)
+	logger, err := ingestion.NewLogger(os.Getenv("LOG_FORMAT"), os.Stderr)
+	if err != nil {
+		return err
+	}
 
 	config := ingestion.DefaultLifecycleConfig()
 	config.Provider = cloud
 	config.Region = region
 	config.BackupDir = backupDir
 	config.Environment = "production"
+	config.SaveRaw = os.Getenv("SAVE_RAW") == "true"
+	config.AllowRestrictedRegions = os.Getenv("ALLOW_RESTRICTED_REGIONS") == "true"
+	if level := os.Getenv("BACKUP_COMPRESSION"); level != "" {
+		if config.BackupCompression, err = parseCompressionLevel(level); err != nil {
+			return err
+		}
+	}
+	config.DistinctKeyCoverage = os.Getenv("DISTINCT_KEY_COVERAGE") == "true"
+	for env, limit := range map[string]*float64{
+		"MAX_AVG_DRIFT":    &config.MaxAvgDriftPercent,
+		"MAX_SINGLE_DRIFT": &config.MaxSingleDriftPercent,
+	} {
+		if value := os.Getenv(env); value != "" {
+			pct, err := strconv.ParseFloat(value, 64)
+			if err != nil || pct <= 0 {
+				return fmt.Errorf("invalid %s %q, expected a positive percentage", env, value)
+			}
+			*limit = pct
+		}
+	}
+	config.ForceDrift = os.Getenv("FORCE_DRIFT") == "true"
+	if config.CoverageBaselinePath = os.Getenv("COVERAGE_BASELINE"); config.CoverageBaselinePath != "" {
+		if value := os.Getenv("COVERAGE_BASELINE_TOLERANCE"); value != "" {
+			pct, err := strconv.ParseFloat(value, 64)
+			if err != nil || pct < 0 || pct > 100 {
+				return fmt.Errorf("invalid COVERAGE_BASELINE_TOLERANCE %q, expected a percentage", value)
+			}
+			config.BaselineTolerancePercent = pct
+		}
+		config.UpdateCoverageBaseline = os.Getenv("UPDATE_COVERAGE_BASELINE") == "true"
+	}
+	switch lock := os.Getenv("INGEST_LOCK"); lock {
+	case "", "wait":
+	case "abort":
+		config.AbortIfCommitting = true
+	default:
+		return fmt.Errorf("invalid INGEST_LOCK %q, expected wait or abort", lock)
+	}
+	if windowEnv := os.Getenv("MAX_FUTURE_EFFECTIVE"); windowEnv != "" {
+		window, err := time.ParseDuration(windowEnv)
+		if err != nil || window <= 0 {
+			return fmt.Errorf("invalid MAX_FUTURE_EFFECTIVE %q, expected a positive duration", windowEnv)
+		}
+		config.FutureEffectiveWindow = window
+		config.DropFutureRates = os.Getenv("DROP_FUTURE_RATES") == "true"
+	}
+	if spot {
+		config.Alias = ingestion.SpotAlias
+	}
+	if config.Metadata, err = parseMetadata(os.Getenv("SNAPSHOT_METADATA")); err != nil {
+		return err
+	}
+
+	// SERVICE refreshes a single service of the active snapshot
+	if service := os.Getenv("SERVICE"); service != "" {
+		if os.Getenv("SERVICES") != "" || os.Getenv("REGIONS") != "" || os.Getenv("PIPELINE") == "streaming" {
+			return fmt.Errorf("SERVICE cannot be combined with SERVICES, REGIONS or PIPELINE=streaming")
+		}
+		return runServiceIngest(ctx, ingestion.NewLifecycle(fetcher, normalizer, store).WithLogger(logger), config, service, os.Stdout)
+	}
+
+	// REGIONS switches to concurrent multi-region ingestion
+	if regionsEnv := os.Getenv("REGIONS"); regionsEnv != "" {
+		return runMultiRegionIngest(ctx, ingestion.NewMultiRegionLifecycle(fetcher, normalizer, store).WithLogger(logger), config, regionsEnv)
+	}
+
+	// PIPELINE=streaming bounds memory by processing prices in batches
+	streamConfig, err := parseStreamingConfig(os.Getenv("PIPELINE"), os.Getenv("STREAM_BATCH_SIZE"), os.Getenv("STREAM_MAX_MEM_MB"))
+	if err != nil {
+		return err
+	}
+	var lifecycle interface {
+		Execute(ctx context.Context, config *ingestion.LifecycleConfig) (*ingestion.LifecycleResult, error)
+	}
+	if streamConfig != nil {
+		fmt.Printf("Using streaming pipeline (batch size %d, memory limit %d MB)\n", streamConfig.BatchSize, streamConfig.MaxMemoryMB)
+		lifecycle = ingestion.NewStreamingLifecycle(fetcher, normalizer, store, streamConfig).WithLogger(logger)
+	} else {
+		lifecycle = ingestion.NewLifecycle(fetcher, normalizer, store).WithLogger(logger)
+	}
 
 	// 5. Execute Pipeline
 	fmt.Printf("Starting ingestion for %s/%s...\n", cloud, region)
 	result, err := lifecycle.Execute(ctx, config)
+	if ctx.Err() != nil {
+		return cancelled(ctx, os.Stderr)
+	}
 	if err != nil {
 		return fmt.Errorf("ingestion failed: %w", err)
 	}
+	if !result.Success {
+		return fmt.Errorf("ingestion failed: %s", result.Error)
+	}
 
 	fmt.Printf("Ingestion completed successfully!\n")
 	fmt.Printf("Snapshot ID: %s\n", result.SnapshotID)
@@ -125,12 +366,258 @@ func run() error {
 	return nil
 }
 
-func runMigrations(dbURL string) error {
-	// Look for migrations in /app/migrations (docker) or ./db/migrations (local)
-	sourceURL := "file://db/migrations"
-	if _, err := os.Stat("/app/migrations"); err == nil {
-		sourceURL = "file:///app/migrations"
+// newNormalizer builds the provider's normalizer with PRICE_SCALE,
+// NORMALIZE_WORKERS, the configured ATTRIBUTE_TRANSFORMS and
+// DIMENSION_ALLOWLIST, and canonical product families applied
+func newNormalizer(registry *ingestion.FetcherRegistry, cloud db.CloudProvider) (ingestion.PriceNormalizer, error) {
+	normalizer, err := registry.GetNormalizer(cloud)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get normalizer: %w", err)
+	}
+
+	// PRICE_SCALE quantizes prices as they are parsed, for one scale per provider
+	scales, err := ingestion.ParsePriceScales(os.Getenv("PRICE_SCALE"), cloud)
+	if err != nil {
+		return nil, fmt.Errorf("invalid PRICE_SCALE: %w", err)
+	}
+	normalizer = scales.Normalizer(normalizer)
+
+	// Normalize on NORMALIZE_WORKERS goroutines (GOMAXPROCS by default); the
+	// allowlist's duplicate removal below must see the merged output
+	workers := 0
+	if workersEnv := os.Getenv("NORMALIZE_WORKERS"); workersEnv != "" {
+		if workers, err = strconv.Atoi(workersEnv); err != nil || workers <= 0 {
+			return nil, fmt.Errorf("invalid NORMALIZE_WORKERS %q, expected a positive integer", workersEnv)
+		}
+	}
+	normalizer = ingestion.NewParallelNormalizer(normalizer, workers)
+
+	// Apply org-specific attribute canonicalization before filtering
+	if transformsPath := os.Getenv("ATTRIBUTE_TRANSFORMS"); transformsPath != "" {
+		transforms, err := ingestion.LoadTransformsFromFile(transformsPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load attribute transforms: %w", err)
+		}
+		normalizer = ingestion.NewChainNormalizer(normalizer, transforms...)
+	}
+
+	// Restrict rate key dimensions when an allowlist config is supplied
+	if allowlistPath := os.Getenv("DIMENSION_ALLOWLIST"); allowlistPath != "" {
+		allowlist, err := ingestion.LoadAllowlistFromFile(allowlistPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load dimension allowlist: %w", err)
+		}
+		normalizer = ingestion.NewFilteredNormalizer(normalizer).WithAllowlist(allowlist)
+	}
+
+	// Tag canonical product families last so the allowlist cannot drop them
+	if os.Getenv("CANONICAL_FAMILIES") == "true" || os.Getenv("PRODUCT_FAMILY_MAP") != "" {
+		mapper := ingestion.NewProductFamilyMapper()
+		if mapPath := os.Getenv("PRODUCT_FAMILY_MAP"); mapPath != "" {
+			rules, err := ingestion.LoadProductFamilyRulesFromFile(mapPath)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load product family map: %w", err)
+			}
+			mapper.WithRules(rules...)
+		}
+		normalizer = ingestion.NewProductFamilyNormalizer(normalizer, mapper)
+	}
+	return normalizer, nil
+}
+
+// parseServiceTimeout parses SERVICE_TIMEOUT: "fair" to split the remaining
+// budget across services, a duration such as "10m", or both ("fair,10m")
+func parseServiceTimeout(env string) (ingestion.ServiceTimeout, error) {
+	var timeout ingestion.ServiceTimeout
+	for _, part := range strings.Split(env, ",") {
+		part = strings.TrimSpace(part)
+		if part == "fair" {
+			timeout.FairShare = true
+			continue
+		}
+		d, err := time.ParseDuration(part)
+		if err != nil || d <= 0 {
+			return timeout, fmt.Errorf("invalid SERVICE_TIMEOUT %q, expected fair and/or a positive duration", env)
+		}
+		timeout.Fixed = d
+	}
+	return timeout, nil
+}
+
+// parseTransport builds the fetcher transport from the proxy, CA bundle,
+// User-Agent and minimum TLS version settings; nil when none is set
+func parseTransport(proxyURL, caBundle, userAgent, tlsMin string) (http.RoundTripper, error) {
+	if proxyURL == "" && caBundle == "" && userAgent == "" && tlsMin == "" {
+		return nil, nil
+	}
+	config := ingestion.TransportConfig{ProxyURL: proxyURL, CABundlePath: caBundle, UserAgent: userAgent}
+	if tlsMin != "" {
+		version, err := ingestion.ParseTLSVersion(tlsMin)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TLS_MIN_VERSION: %w", err)
+		}
+		config.TLSMinVersion = version
+	}
+	return config.NewTransport()
+}
+
+// parseCompressionLevel parses BACKUP_COMPRESSION: none, fast, default, best
+// or a gzip level from 0 to 9
+func parseCompressionLevel(env string) (int, error) {
+	switch env {
+	case "none":
+		return gzip.NoCompression, nil
+	case "fast":
+		return gzip.BestSpeed, nil
+	case "default":
+		return gzip.DefaultCompression, nil
+	case "best":
+		return gzip.BestCompression, nil
+	}
+	level, err := strconv.Atoi(env)
+	if err != nil || level < gzip.NoCompression || level > gzip.BestCompression {
+		return 0, fmt.Errorf("invalid BACKUP_COMPRESSION %q, expected none, fast, default, best or 0-9", env)
+	}
+	return level, nil
+}
+
+// parseStreamingConfig selects the pipeline from PIPELINE ("standard", the
+// default, or "streaming"). It returns nil for the standard lifecycle and the
+// streaming config, with STREAM_BATCH_SIZE and STREAM_MAX_MEM_MB applied,
+// otherwise.
+func parseStreamingConfig(pipeline, batchSize, maxMemMB string) (*ingestion.StreamingConfig, error) {
+	switch pipeline {
+	case "", "standard":
+		return nil, nil
+	case "streaming":
+	default:
+		return nil, fmt.Errorf("invalid PIPELINE %q, expected standard or streaming", pipeline)
+	}
+
+	config := ingestion.DefaultStreamingConfig()
+	if batchSize != "" {
+		n, err := strconv.Atoi(batchSize)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid STREAM_BATCH_SIZE %q, expected a positive integer", batchSize)
+		}
+		config.BatchSize = n
+	}
+	if maxMemMB != "" {
+		n, err := strconv.Atoi(maxMemMB)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid STREAM_MAX_MEM_MB %q, expected a positive integer", maxMemMB)
+		}
+		config.MaxMemoryMB = n
+	}
+	return config, nil
+}
+
+// parseMetadata parses SNAPSHOT_METADATA, a comma-separated key=value list
+// such as "ci_job=1234,git_sha=9f3c2a1,operator=alice"
+func parseMetadata(env string) (map[string]string, error) {
+	if env == "" {
+		return nil, nil
+	}
+	metadata := make(map[string]string)
+	for _, pair := range strings.Split(env, ",") {
+		key, value, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid SNAPSHOT_METADATA entry %q, expected key=value", pair)
+		}
+		metadata[key] = strings.TrimSpace(value)
+	}
+	return metadata, nil
+}
+
+// runReprocess re-normalizes raw prices saved with SAVE_RAW=true using the
+// current normalizer and commits them as a new snapshot
+func runReprocess(ctx context.Context, store db.PricingStore, rawPath string) error {
+	if rawPath == "" {
+		return fmt.Errorf("RAW_PATH environment variable is required for MODE=reprocess")
+	}
+	cloud := db.CloudProvider(os.Getenv("CLOUD"))
+	if cloud == "" {
+		cloud = db.AWS
+	}
+
+	normalizer, err := newNormalizer(ingestion.GetRegistry(), cloud)
+	if err != nil {
+		return err
+	}
+
+	result, err := ingestion.ReprocessBackup(ctx, store, rawPath, normalizer)
+	if ctx.Err() != nil {
+		return cancelled(ctx, os.Stderr)
+	}
+	if err != nil {
+		return fmt.Errorf("reprocess failed: %w", err)
+	}
+	if !result.Success {
+		return fmt.Errorf("reprocess failed: %s", result.Error)
+	}
+
+	fmt.Printf("Reprocessed %d raw prices into snapshot %s (%d rates)\n", result.RawCount, result.SnapshotID, result.NormalizedCount)
+	return nil
+}
+
+// runMultiRegionIngest ingests a comma-separated region list, or every billable
+// region for REGIONS=all, and fails if any region failed
+func runMultiRegionIngest(ctx context.Context, multi *ingestion.MultiRegionLifecycle, template *ingestion.LifecycleConfig, regionsEnv string) error {
+	config := ingestion.DefaultMultiRegionConfig()
+	config.Lifecycle = template
+	config.DetectEquivalence = true
+
+	if regionsEnv == "all" {
+		for _, r := range regions.NewRegistry().GetBillableRegions(template.Provider) {
+			config.Regions = append(config.Regions, r.Region)
+		}
+	} else {
+		for _, r := range strings.Split(regionsEnv, ",") {
+			if r = strings.TrimSpace(r); r != "" {
+				config.Regions = append(config.Regions, r)
+			}
+		}
+	}
+	if concurrencyEnv := os.Getenv("REGION_CONCURRENCY"); concurrencyEnv != "" {
+		n, err := strconv.Atoi(concurrencyEnv)
+		if err != nil || n < 1 {
+			return fmt.Errorf("invalid REGION_CONCURRENCY %q", concurrencyEnv)
+		}
+		config.Concurrency = n
+	}
+
+	fmt.Printf("Starting ingestion for %d %s regions (concurrency %d)...\n", len(config.Regions), template.Provider, config.Concurrency)
+	result, err := multi.Execute(ctx, config)
+	if ctx.Err() != nil {
+		return cancelled(ctx, os.Stderr)
+	}
+	if err != nil {
+		return fmt.Errorf("ingestion failed: %w", err)
+	}
+
+	for _, region := range result.Succeeded {
+		res := result.Results[region]
+		fmt.Printf("  %s: snapshot %s, %d rates\n", region, res.SnapshotID, res.NormalizedCount)
+	}
+	for _, region := range result.Failed {
+		fmt.Printf("  %s: FAILED at %s: %s\n", region, result.Results[region].Phase, result.Results[region].Error)
+	}
+	for _, g := range result.EquivalenceGroups {
+		if len(g.Aliases) > 0 {
+			fmt.Printf("  %s has identical pricing to %v\n", g.CanonicalRegion, g.Aliases)
+		}
 	}
+	fmt.Printf("Duration: %s\n", result.Duration)
+
+	if len(result.Failed) > 0 {
+		return fmt.Errorf("%d of %d regions failed: %v", len(result.Failed), len(config.Regions), result.Failed)
+	}
+	return nil
+}
+
+func runMigrations(dbURL string) error {
+	sourceURL := "file://" + migrationsDir()
 
 	fmt.Printf("Running migrations from %s...\n", sourceURL)
 	
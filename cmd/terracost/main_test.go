@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"terraform-cost/db"
+	"terraform-cost/db/ingestion"
+	"terraform-cost/db/memstore"
+)
+
+// signallingFetcher sends SIGTERM to the process mid-fetch and waits for the
+// context to be cancelled, like a pod eviction during a long ingestion
+type signallingFetcher struct{}
+
+func (signallingFetcher) Cloud() db.CloudProvider     { return db.AWS }
+func (signallingFetcher) SupportedRegions() []string  { return []string{"us-east-1"} }
+func (signallingFetcher) SupportedServices() []string { return []string{"AmazonEC2"} }
+func (signallingFetcher) FetchRegion(ctx context.Context, region string) ([]ingestion.RawPrice, error) {
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGTERM); err != nil {
+		return nil, err
+	}
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(5 * time.Second):
+		return nil, errors.New("context was not cancelled by SIGTERM")
+	}
+}
+
+func TestInterruptContextCancelsIngestion(t *testing.T) {
+	ctx, stop := interruptContext(context.Background())
+	defer stop()
+
+	store := memstore.NewMemoryStore()
+	config := ingestion.DefaultLifecycleConfig()
+	config.Provider = db.AWS
+	config.Region = "us-east-1"
+	config.Environment = "development"
+	config.BackupDir = t.TempDir()
+
+	result, _ := ingestion.NewLifecycle(signallingFetcher{}, ingestion.NewAWSPricingAPINormalizer(), store).Execute(ctx, config)
+	if ctx.Err() == nil {
+		t.Fatalf("expected SIGTERM to cancel the context, got result %+v", result)
+	}
+	if result != nil && result.Success {
+		t.Error("expected the cancelled ingestion to fail")
+	}
+	if active, _ := store.GetActiveSnapshot(context.Background(), db.AWS, "us-east-1", "default"); active != nil {
+		t.Errorf("expected no snapshot to be activated, got %s", active.ID)
+	}
+
+	var out bytes.Buffer
+	err := cancelled(ctx, &out)
+	if !errors.Is(err, context.Canceled) || !strings.Contains(out.String(), "cancelled") {
+		t.Errorf("unexpected cancellation report: %v / %q", err, out.String())
+	}
+}
+
+func TestParseStreamingConfig(t *testing.T) {
+	for _, pipeline := range []string{"", "standard"} {
+		if config, err := parseStreamingConfig(pipeline, "500", ""); err != nil || config != nil {
+			t.Errorf("PIPELINE=%q: expected the standard lifecycle, got %+v %v", pipeline, config, err)
+		}
+	}
+
+	config, err := parseStreamingConfig("streaming", "", "")
+	if err != nil || config == nil {
+		t.Fatalf("expected a streaming config, got %v", err)
+	}
+	if defaults := ingestion.DefaultStreamingConfig(); config.BatchSize != defaults.BatchSize || config.MaxMemoryMB != defaults.MaxMemoryMB {
+		t.Errorf("expected the default streaming config, got %+v", config)
+	}
+
+	config, err = parseStreamingConfig("streaming", "5000", "1024")
+	if err != nil || config.BatchSize != 5000 || config.MaxMemoryMB != 1024 {
+		t.Errorf("expected batch size and memory limit from the environment, got %+v %v", config, err)
+	}
+
+	for _, bad := range [][3]string{{"batch", "", ""}, {"streaming", "0", ""}, {"streaming", "", "lots"}} {
+		if _, err := parseStreamingConfig(bad[0], bad[1], bad[2]); err == nil {
+			t.Errorf("expected %v to be rejected", bad)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/golang-migrate/migrate/v4"
+)
+
+// migrationFile is one up migration in the migrations directory
+type migrationFile struct {
+	Version uint
+	Name    string
+}
+
+// migrationStatus is the database's schema version against the migrations
+// on disk
+type migrationStatus struct {
+	Version uint // 0 when no migration has been applied
+	Dirty   bool
+	Latest  uint
+	Pending []migrationFile
+}
+
+// migrationsDir returns the migrations directory: /app/migrations (docker)
+// or ./db/migrations (local)
+func migrationsDir() string {
+	if _, err := os.Stat("/app/migrations"); err == nil {
+		return "/app/migrations"
+	}
+	return "db/migrations"
+}
+
+// listMigrations returns the up migrations in dir, oldest first
+func listMigrations(dir string) ([]migrationFile, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+	var files []migrationFile
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), ".up.sql")
+		if !ok || e.IsDir() {
+			continue
+		}
+		number, title, _ := strings.Cut(name, "_")
+		version, err := strconv.ParseUint(number, 10, 64)
+		if err != nil {
+			continue
+		}
+		files = append(files, migrationFile{Version: uint(version), Name: title})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Version < files[j].Version })
+	return files, nil
+}
+
+// newMigrationStatus compares the applied version with the migrations on
+// disk; a dirty version is pending, since it did not finish
+func newMigrationStatus(version uint, dirty bool, files []migrationFile) migrationStatus {
+	status := migrationStatus{Version: version, Dirty: dirty}
+	for _, f := range files {
+		if f.Version > status.Latest {
+			status.Latest = f.Version
+		}
+		if f.Version > version || (dirty && f.Version == version) {
+			status.Pending = append(status.Pending, f)
+		}
+	}
+	return status
+}
+
+// formatMigrationStatus prints the version, dirty flag and pending migrations
+func formatMigrationStatus(w io.Writer, status migrationStatus) {
+	switch {
+	case status.Version == 0 && !status.Dirty:
+		fmt.Fprintln(w, "Version: none (no migrations applied)")
+	default:
+		fmt.Fprintf(w, "Version: %d\n", status.Version)
+	}
+	fmt.Fprintf(w, "Dirty:   %t\n", status.Dirty)
+	fmt.Fprintf(w, "Latest:  %d\n", status.Latest)
+
+	if status.Dirty {
+		fmt.Fprintf(w, "Migration %d failed part way; fix the schema, then force the version before migrating again\n", status.Version)
+	}
+	if len(status.Pending) == 0 {
+		fmt.Fprintln(w, "No pending migrations")
+		return
+	}
+	fmt.Fprintf(w, "Pending migrations (%d):\n", len(status.Pending))
+	for _, f := range status.Pending {
+		fmt.Fprintf(w, "  %03d  %s\n", f.Version, f.Name)
+	}
+}
+
+// newMigrate opens the migrations directory against the database
+func newMigrate(dbURL string) (*migrate.Migrate, error) {
+	m, err := migrate.New("file://"+migrationsDir(), dbURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create migrate instance: %w", err)
+	}
+	return m, nil
+}
+
+// migrationVersion returns the applied version, 0 when none is
+func migrationVersion(m *migrate.Migrate) (uint, bool, error) {
+	version, dirty, err := m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to get migration version: %w", err)
+	}
+	return version, dirty, nil
+}
+
+// runMigrateStatus prints the migration status without changing the schema
+func runMigrateStatus(dbURL string, w io.Writer) error {
+	files, err := listMigrations(migrationsDir())
+	if err != nil {
+		return err
+	}
+	m, err := newMigrate(dbURL)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	version, dirty, err := migrationVersion(m)
+	if err != nil {
+		return err
+	}
+	formatMigrationStatus(w, newMigrationStatus(version, dirty, files))
+	return nil
+}
+
+// runMigrateUp applies every pending migration. Unlike ingestion it never
+// forces a dirty version; that is left to the operator.
+func runMigrateUp(dbURL string, w io.Writer) error {
+	m, err := newMigrate(dbURL)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("failed to run up migrations: %w", err)
+	}
+	version, _, err := migrationVersion(m)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Database is at migration %d\n", version)
+	return nil
+}
+
+// runMigrateDown rolls back MIGRATE_STEPS migrations; the count is required
+// so a bare MODE=migrate-down cannot drop the whole schema
+func runMigrateDown(dbURL string, w io.Writer, stepsEnv string) error {
+	steps, err := strconv.Atoi(stepsEnv)
+	if err != nil || steps <= 0 {
+		return fmt.Errorf("MIGRATE_STEPS must be a positive number of migrations to roll back for MODE=migrate-down, got %q", stepsEnv)
+	}
+	m, err := newMigrate(dbURL)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	if err := m.Steps(-steps); err != nil {
+		return fmt.Errorf("failed to roll back %d migration(s): %w", steps, err)
+	}
+	version, _, err := migrationVersion(m)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Rolled back %d migration(s); database is at migration %d\n", steps, version)
+	return nil
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestListMigrations(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{
+		"010_later.up.sql", "010_later.down.sql",
+		"002_second.up.sql", "001_first.up.sql",
+		"README.md", "notes_up.sql",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, err := listMigrations(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []migrationFile{{1, "first"}, {2, "second"}, {10, "later"}}
+	if len(files) != len(want) {
+		t.Fatalf("expected %v, got %v", want, files)
+	}
+	for i := range want {
+		if files[i] != want[i] {
+			t.Errorf("migration %d = %v, want %v", i, files[i], want[i])
+		}
+	}
+
+	// The repository's own migrations parse too
+	repo, err := listMigrations("../../db/migrations")
+	if err != nil || len(repo) == 0 || repo[0].Version != 1 {
+		t.Errorf("expected the repository migrations from 001, got %v %v", repo, err)
+	}
+
+	// MODE=migrate-down needs a down migration for every up migration
+	for _, f := range repo {
+		down := filepath.Join("../../db/migrations", fmt.Sprintf("%03d_%s.down.sql", f.Version, f.Name))
+		if _, err := os.Stat(down); err != nil {
+			t.Errorf("migration %d has no down migration: %v", f.Version, err)
+		}
+	}
+}
+
+func TestFormatMigrationStatus(t *testing.T) {
+	files := []migrationFile{{1, "pricing_schema"}, {2, "pricing_dimensions"}, {13, "resolve_indexes"}, {14, "rate_labels"}}
+
+	tests := []struct {
+		name    string
+		version uint
+		dirty   bool
+		want    []string
+		missing []string
+	}{
+		{
+			name:    "fresh database",
+			version: 0,
+			want:    []string{"Version: none", "Dirty:   false", "Latest:  14", "Pending migrations (4):", "  001  pricing_schema", "  014  rate_labels"},
+		},
+		{
+			name:    "behind",
+			version: 2,
+			want:    []string{"Version: 2\n", "Pending migrations (2):", "  013  resolve_indexes", "  014  rate_labels"},
+			missing: []string{"pricing_dimensions", "failed part way"},
+		},
+		{
+			name:    "up to date",
+			version: 14,
+			want:    []string{"Version: 14\n", "No pending migrations"},
+			missing: []string{"Pending"},
+		},
+		{
+			name:    "dirty",
+			version: 13,
+			dirty:   true,
+			want:    []string{"Dirty:   true", "Migration 13 failed part way", "Pending migrations (2):", "  013  resolve_indexes"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			formatMigrationStatus(&out, newMigrationStatus(tt.version, tt.dirty, files))
+			for _, s := range tt.want {
+				if !strings.Contains(out.String(), s) {
+					t.Errorf("expected %q in:\n%s", s, out.String())
+				}
+			}
+			for _, s := range tt.missing {
+				if strings.Contains(out.String(), s) {
+					t.Errorf("did not expect %q in:\n%s", s, out.String())
+				}
+			}
+		})
+	}
+}
+
+func TestRunMigrateDownRequiresSteps(t *testing.T) {
+	for _, steps := range []string{"", "0", "-1", "all"} {
+		if err := runMigrateDown("postgres://unused", &bytes.Buffer{}, steps); err == nil || !strings.Contains(err.Error(), "MIGRATE_STEPS") {
+			t.Errorf("MIGRATE_STEPS=%q: expected a missing-steps error, got %v", steps, err)
+		}
+	}
+}
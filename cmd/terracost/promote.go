@@ -0,0 +1,32 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"terraform-cost/db"
+	"terraform-cost/db/ingestion"
+
+	"github.com/google/uuid"
+)
+
+// runPromote copies SNAPSHOT_ID from the SOURCE_DB_URL database into the
+// DB_URL database and activates it there
+func runPromote(ctx context.Context, src, dst db.PricingStore, w io.Writer, snapshotID string) error {
+	if snapshotID == "" {
+		return fmt.Errorf("SNAPSHOT_ID environment variable is required for MODE=promote")
+	}
+	id, err := uuid.Parse(snapshotID)
+	if err != nil {
+		return fmt.Errorf("invalid SNAPSHOT_ID %q: %w", snapshotID, err)
+	}
+
+	promoted, err := ingestion.PromoteSnapshot(ctx, src, dst, id)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Promoted snapshot %s to %s (%s/%s/%s, hash %s)\n",
+		id, promoted.ID, promoted.Cloud, promoted.Region, promoted.ProviderAlias, promoted.Hash)
+	return nil
+}
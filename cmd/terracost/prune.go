@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"terraform-cost/db/ingestion"
+)
+
+// runPruneBackups removes backups outside the retention policy built from
+// BACKUP_KEEP_LAST and BACKUP_MAX_AGE; at least one must be set
+func runPruneBackups(w io.Writer, backupDir, keepLastEnv, maxAgeEnv string) error {
+	if backupDir == "" {
+		backupDir = "/app/backups"
+	}
+	if keepLastEnv == "" && maxAgeEnv == "" {
+		return fmt.Errorf("BACKUP_KEEP_LAST or BACKUP_MAX_AGE is required for MODE=prune-backups")
+	}
+
+	var policy ingestion.RetentionPolicy
+	if keepLastEnv != "" {
+		n, err := strconv.Atoi(keepLastEnv)
+		if err != nil || n < 1 {
+			return fmt.Errorf("invalid BACKUP_KEEP_LAST %q", keepLastEnv)
+		}
+		policy.KeepLast = n
+	}
+	if maxAgeEnv != "" {
+		d, err := time.ParseDuration(maxAgeEnv)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("invalid BACKUP_MAX_AGE %q", maxAgeEnv)
+		}
+		policy.MaxAge = d
+	}
+
+	removed, err := ingestion.PruneBackups(backupDir, policy)
+	for _, path := range removed {
+		fmt.Fprintf(w, "Removed %s\n", path)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Pruned %d backup(s) from %s\n", len(removed), backupDir)
+	return nil
+}
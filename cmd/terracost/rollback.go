@@ -0,0 +1,28 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"terraform-cost/db"
+	"terraform-cost/db/ingestion"
+)
+
+// runRollback reactivates the snapshot before the active one for CLOUD/REGION
+// (and PROVIDER_ALIAS, default "default")
+func runRollback(ctx context.Context, store db.PricingStore, w io.Writer, cloud db.CloudProvider, region, alias string) error {
+	if cloud == "" || region == "" {
+		return fmt.Errorf("CLOUD and REGION environment variables are required for MODE=rollback")
+	}
+	if alias == "" {
+		alias = "default"
+	}
+
+	id, err := ingestion.RollbackSnapshot(ctx, store, cloud, region, alias)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Rolled back %s/%s/%s to snapshot %s\n", cloud, region, alias, id)
+	return nil
+}
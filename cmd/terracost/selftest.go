@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"terraform-cost/db"
+	"terraform-cost/db/ingestion"
+	"terraform-cost/db/memstore"
+
+	"github.com/shopspring/decimal"
+)
+
+// selftestRegion is the region MODE=selftest ingests
+const selftestRegion = "us-east-1"
+
+// selftestRequest is a rate the AWS stub data is known to contain
+var selftestRequest = db.ResolutionRequest{
+	Cloud:         db.AWS,
+	Service:       "AmazonEC2",
+	ProductFamily: "Compute Instance",
+	Region:        selftestRegion,
+	Attributes:    map[string]string{"instance_type": "m5.large", "os": "linux", "tenancy": "shared"},
+	Unit:          "hours",
+}
+
+// selftestPrice is the stub price of selftestRequest
+var selftestPrice = decimal.RequireFromString("0.096")
+
+// runSelftest runs the full ingestion lifecycle against the AWS stub fetcher
+// and an in-memory store, then checks that the snapshot was committed and
+// activated, its backup reads back with a matching hash, and a known rate
+// resolves. Nothing touches a pricing API or PostgreSQL; any failure is
+// returned so the process exits nonzero.
+func runSelftest(ctx context.Context, w io.Writer, logger *slog.Logger) error {
+	backupDir, err := os.MkdirTemp("", "terracost-selftest-")
+	if err != nil {
+		return fmt.Errorf("selftest: failed to create backup dir: %w", err)
+	}
+	defer os.RemoveAll(backupDir)
+
+	store := memstore.NewMemoryStore()
+	config := ingestion.DefaultLifecycleConfig()
+	config.Provider = db.AWS
+	config.Region = selftestRegion
+	config.Environment = "development"
+	config.AllowMockPricing = true
+	config.BackupDir = backupDir
+
+	result, err := ingestion.NewLifecycle(ingestion.NewAWSFetcher(), ingestion.NewAWSNormalizer(), store).WithLogger(logger).Execute(ctx, config)
+	if err != nil {
+		return fmt.Errorf("selftest: ingestion failed: %w", err)
+	}
+	if !result.Success {
+		return fmt.Errorf("selftest: ingestion failed in phase %s: %s", result.Phase, result.Error)
+	}
+	fmt.Fprintf(w, "ok  ingest    %d rates committed as snapshot %s\n", result.NormalizedCount, result.SnapshotID)
+
+	active, err := store.GetActiveSnapshot(ctx, db.AWS, selftestRegion, config.Alias)
+	if err != nil {
+		return fmt.Errorf("selftest: failed to load active snapshot: %w", err)
+	}
+	if active == nil || result.SnapshotID == nil || active.ID != *result.SnapshotID {
+		return fmt.Errorf("selftest: snapshot %v is not the active snapshot", result.SnapshotID)
+	}
+	fmt.Fprintf(w, "ok  activate  %s/%s/%s -> %s\n", active.Cloud, active.Region, active.ProviderAlias, active.ID)
+
+	backups := ingestion.NewBackupManager()
+	report, err := backups.ReadValidationReport(result.ValidationReportPath)
+	if err != nil {
+		return fmt.Errorf("selftest: %w", err)
+	}
+	for _, check := range report.Checks {
+		if !check.Passed {
+			return fmt.Errorf("selftest: validation check %s failed: %s", check.Name, check.Error)
+		}
+	}
+	fmt.Fprintf(w, "ok  validate  %d checks passed\n", len(report.Checks))
+
+	backup, err := backups.ReadBackup(result.BackupPath)
+	if err != nil {
+		return fmt.Errorf("selftest: %w", err)
+	}
+	if backup.ContentHash != active.Hash {
+		return fmt.Errorf("selftest: backup hash %s does not match snapshot hash %s", backup.ContentHash, active.Hash)
+	}
+	fmt.Fprintf(w, "ok  backup    %d rates, hash %s\n", backup.RateCount, backup.ContentHash)
+
+	rate, err := db.NewStrictResolver(store).WithMode(db.Strict).Resolve(ctx, selftestRequest)
+	if err != nil {
+		return fmt.Errorf("selftest: resolve failed: %w", err)
+	}
+	if rate.Price == nil || !rate.Price.Equal(selftestPrice) {
+		return fmt.Errorf("selftest: resolved %s %s/%s at %s, want %s", selftestRequest.Service,
+			selftestRequest.Attributes["instance_type"], selftestRequest.Unit, rate.Price, selftestPrice)
+	}
+	fmt.Fprintf(w, "ok  resolve   %s %s = %s %s per %s\n", selftestRequest.Service,
+		selftestRequest.Attributes["instance_type"], rate.Price, rate.Currency, selftestRequest.Unit)
+
+	fmt.Fprintln(w, "Self-test passed")
+	return nil
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestRunSelftest(t *testing.T) {
+	var out bytes.Buffer
+	if err := runSelftest(context.Background(), &out, slog.New(slog.NewTextHandler(io.Discard, nil))); err != nil {
+		t.Fatalf("self-test failed: %v\n%s", err, out.String())
+	}
+	for _, step := range []string{"ok  ingest", "ok  activate", "ok  validate", "ok  backup", "ok  resolve   AmazonEC2 m5.large = 0.096 USD", "Self-test passed"} {
+		if !strings.Contains(out.String(), step) {
+			t.Errorf("expected %q in the output:\n%s", step, out.String())
+		}
+	}
+}
+
+func TestRunSelftestFailsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := runSelftest(ctx, io.Discard, slog.New(slog.NewTextHandler(io.Discard, nil))); err == nil {
+		t.Error("expected a cancelled self-test to fail")
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"terraform-cost/db/ingestion"
+)
+
+// runServiceIngest re-fetches one service (SERVICE) and commits it together
+// with every other service's rates from the active snapshot, through the
+// same validation, backup and commit as a full ingestion
+func runServiceIngest(ctx context.Context, lifecycle *ingestion.Lifecycle, config *ingestion.LifecycleConfig, service string, w io.Writer) error {
+	service = strings.TrimSpace(service)
+	if service == "" || strings.Contains(service, ",") {
+		return fmt.Errorf("invalid SERVICE %q, expected a single service name", service)
+	}
+
+	fmt.Fprintf(w, "Updating %s for %s/%s...\n", service, config.Provider, config.Region)
+	result, err := lifecycle.UpdateServices(ctx, config, []string{service})
+	if ctx.Err() != nil {
+		return cancelled(ctx, w)
+	}
+	if err != nil {
+		return fmt.Errorf("service update failed: %w", err)
+	}
+	if !result.Success {
+		return fmt.Errorf("service update failed: %s", result.Error)
+	}
+
+	fmt.Fprintf(w, "Service update completed successfully!\n")
+	fmt.Fprintf(w, "Snapshot ID: %s\n", result.SnapshotID)
+	fmt.Fprintf(w, "Backup: %s\n", result.BackupPath)
+	fmt.Fprintf(w, "Rates: %d\n", result.NormalizedCount)
+	return nil
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"terraform-cost/db"
+	"terraform-cost/db/ingestion"
+	"terraform-cost/db/memstore"
+)
+
+// filteringStubFetcher serves the AWS stub prices restricted to the allowed
+// services, with the Lambda request price overridden when requestPrice is
+// set, and records which services each fetch returned
+type filteringStubFetcher struct {
+	*ingestion.AWSFetcher
+	allowed      []string
+	requestPrice string
+	fetched      []map[string]bool
+}
+
+func (f *filteringStubFetcher) SetAllowedServices(services []string) {
+	f.allowed = services
+}
+
+func (f *filteringStubFetcher) FetchRegion(ctx context.Context, region string) ([]ingestion.RawPrice, error) {
+	prices, err := f.AWSFetcher.FetchRegion(ctx, region)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	var kept []ingestion.RawPrice
+	for _, p := range prices {
+		if f.allowed == nil || strings.Contains(","+strings.Join(f.allowed, ",")+",", ","+p.ServiceCode+",") {
+			if p.SKU == "lambda-requests" && f.requestPrice != "" {
+				p.PricePerUnit = f.requestPrice
+			}
+			kept = append(kept, p)
+			seen[p.ServiceCode] = true
+		}
+	}
+	f.fetched = append(f.fetched, seen)
+	return kept, nil
+}
+
+func TestRunServiceIngest(t *testing.T) {
+	ctx := context.Background()
+	store := memstore.NewMemoryStore()
+	config := ingestion.DefaultLifecycleConfig()
+	config.Provider = db.AWS
+	config.Region = "us-east-1"
+	config.Environment = "development"
+	config.AllowMockPricing = true
+	config.BackupDir = t.TempDir()
+
+	fetcher := &filteringStubFetcher{AWSFetcher: ingestion.NewAWSFetcher()}
+	lifecycle := ingestion.NewLifecycle(fetcher, ingestion.NewAWSNormalizer(), store).WithLogger(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	full, err := lifecycle.Execute(ctx, config)
+	if err != nil || !full.Success {
+		t.Fatalf("full ingestion failed: %v %+v", err, full)
+	}
+
+	fetcher.requestPrice = "0.00000021"
+	var out bytes.Buffer
+	if err := runServiceIngest(ctx, lifecycle, config, "AWSLambda", &out); err != nil {
+		t.Fatalf("service update failed: %v\n%s", err, out.String())
+	}
+	if last := fetcher.fetched[len(fetcher.fetched)-1]; len(last) != 1 || !last["AWSLambda"] {
+		t.Errorf("expected only AWSLambda to be fetched, got %v", last)
+	}
+
+	active, err := store.GetActiveSnapshot(ctx, db.AWS, "us-east-1", "default")
+	if err != nil || active == nil || active.ID == *full.SnapshotID {
+		t.Fatalf("expected a new active snapshot, got %+v %v", active, err)
+	}
+	if active.Metadata["updated_services"] != "AWSLambda" {
+		t.Errorf("expected the snapshot to record the updated service, got %v", active.Metadata)
+	}
+	rates, err := store.GetRatesBySnapshot(ctx, active.ID)
+	if err != nil || len(rates) != full.NormalizedCount {
+		t.Errorf("expected the other services to be carried over: %d rates, want %d (%v)", len(rates), full.NormalizedCount, err)
+	}
+	for _, step := range []string{"Updating AWSLambda for aws/us-east-1", "Service update completed successfully!", "Backup: "} {
+		if !strings.Contains(out.String(), step) {
+			t.Errorf("expected %q in the output:\n%s", step, out.String())
+		}
+	}
+	backups, _ := filepath.Glob(filepath.Join(config.BackupDir, "aws", "us-east-1_*.json.gz"))
+	if len(backups) != 2 {
+		t.Errorf("expected the service update to write its own backup, found %d entries", len(backups))
+	}
+
+	if err := runServiceIngest(ctx, lifecycle, config, "AWSLambda,AmazonS3", io.Discard); err == nil {
+		t.Error("expected a service list to be rejected")
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"terraform-cost/db"
+	"terraform-cost/db/ingestion"
+
+	"github.com/google/uuid"
+)
+
+// topServiceCount is how many services MODE=inspect lists by rate count
+const topServiceCount = 10
+
+// snapshotRow is one line of the snapshot listing
+type snapshotRow struct {
+	Snapshot  *db.PricingSnapshot
+	RateCount int
+}
+
+// serviceCount pairs a service with its number of rates
+type serviceCount struct {
+	Service string
+	Rates   int
+}
+
+// runList prints all snapshots, optionally filtered by cloud and region
+func runList(ctx context.Context, store db.PricingStore, w io.Writer, cloud db.CloudProvider, region string) error {
+	snapshots, err := store.ListSnapshots(ctx, cloud, region)
+	if err != nil {
+		return fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	rows := make([]snapshotRow, 0, len(snapshots))
+	for _, s := range snapshots {
+		count, err := store.CountRates(ctx, s.ID)
+		if err != nil {
+			return fmt.Errorf("failed to count rates for %s: %w", s.ID, err)
+		}
+		rows = append(rows, snapshotRow{Snapshot: s, RateCount: count})
+	}
+
+	return formatSnapshotList(w, rows)
+}
+
+// runInspect prints the coverage report and top services for one snapshot
+func runInspect(ctx context.Context, store db.PricingStore, w io.Writer, snapshotID string) error {
+	if snapshotID == "" {
+		return fmt.Errorf("SNAPSHOT_ID environment variable is required for MODE=inspect")
+	}
+	id, err := uuid.Parse(snapshotID)
+	if err != nil {
+		return fmt.Errorf("invalid SNAPSHOT_ID %q: %w", snapshotID, err)
+	}
+
+	snapshot, err := store.GetSnapshot(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to load snapshot: %w", err)
+	}
+	if snapshot == nil {
+		return fmt.Errorf("snapshot %s not found", id)
+	}
+
+	stored, err := store.GetRatesBySnapshot(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to load rates: %w", err)
+	}
+	rates := ingestion.RatesFromSnapshot(stored)
+
+	report := ingestion.NewCoverageTracker().GenerateReport(snapshot, rates)
+	return formatInspectReport(w, snapshot, report, topServices(rates, topServiceCount))
+}
+
+// formatSnapshotList renders snapshots as an aligned table
+func formatSnapshotList(w io.Writer, rows []snapshotRow) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tCLOUD\tREGION\tALIAS\tACTIVE\tRATES\tCREATED")
+	for _, r := range rows {
+		s := r.Snapshot
+		active := "no"
+		if s.IsActive {
+			active = "yes"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%d\t%s\n",
+			s.ID, s.Cloud, s.Region, s.ProviderAlias, active, r.RateCount,
+			s.CreatedAt.UTC().Format("2006-01-02 15:04:05"),
+		)
+	}
+	return tw.Flush()
+}
+
+// formatInspectReport renders the coverage report and service breakdown
+func formatInspectReport(w io.Writer, snapshot *db.PricingSnapshot, report *ingestion.CoverageReport, top []serviceCount) error {
+	fmt.Fprintf(w, "Snapshot:  %s\n", snapshot.ID)
+	fmt.Fprintf(w, "Target:    %s/%s (%s)\n", snapshot.Cloud, snapshot.Region, snapshot.ProviderAlias)
+	fmt.Fprintf(w, "Source:    %s\n", snapshot.Source)
+	fmt.Fprintf(w, "Active:    %t\n", snapshot.IsActive)
+	fmt.Fprintf(w, "Hash:      %s\n", snapshot.Hash)
+	if len(snapshot.Metadata) > 0 {
+		keys := make([]string, 0, len(snapshot.Metadata))
+		for k := range snapshot.Metadata {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Fprintf(w, "Metadata:  %s=%s\n", k, snapshot.Metadata[k])
+		}
+	}
+	fmt.Fprintf(w, "Coverage:  %s\n\n", report)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SERVICE\tSTATUS\tRATES\tREQUIRED\tCOVERAGE\tMISSING DIMENSIONS")
+	reports := append([]ingestion.ServiceReport(nil), report.ServiceReports...)
+	sort.Slice(reports, func(i, j int) bool { return reports[i].Service < reports[j].Service })
+	for _, sr := range reports {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%.1f%%\t%v\n",
+			sr.Service, sr.Status, sr.RateCount, sr.RequiredCount, sr.CoveragePercent, sr.MissingDimensions)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if len(report.UnmappedServices) > 0 {
+		fmt.Fprintf(w, "\nUnmapped services (no contract or allowlist): %s\n", strings.Join(report.UnmappedServices, ", "))
+	}
+
+	fmt.Fprintf(w, "\nTop services by rate count:\n")
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SERVICE\tRATES")
+	for _, sc := range top {
+		fmt.Fprintf(tw, "%s\t%d\n", sc.Service, sc.Rates)
+	}
+	return tw.Flush()
+}
+
+// topServices returns up to n services ordered by descending rate count
+func topServices(rates []ingestion.NormalizedRate, n int) []serviceCount {
+	counts := make(map[string]int)
+	for _, r := range rates {
+		counts[r.RateKey.Service]++
+	}
+
+	result := make([]serviceCount, 0, len(counts))
+	for svc, c := range counts {
+		result = append(result, serviceCount{Service: svc, Rates: c})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Rates != result[j].Rates {
+			return result[i].Rates > result[j].Rates
+		}
+		return result[i].Service < result[j].Service
+	})
+
+	if len(result) > n {
+		result = result[:n]
+	}
+	return result
+}
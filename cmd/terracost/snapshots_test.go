@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"terraform-cost/db"
+	"terraform-cost/db/ingestion"
+
+	"github.com/google/uuid"
+)
+
+func TestFormatSnapshotList(t *testing.T) {
+	created := time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+	rows := []snapshotRow{
+		{
+			Snapshot: &db.PricingSnapshot{
+				ID:            uuid.MustParse("11111111-1111-1111-1111-111111111111"),
+				Cloud:         db.AWS,
+				Region:        "us-east-1",
+				ProviderAlias: "default",
+				IsActive:      true,
+				CreatedAt:     created,
+			},
+			RateCount: 1234,
+		},
+		{
+			Snapshot: &db.PricingSnapshot{
+				ID:            uuid.MustParse("22222222-2222-2222-2222-222222222222"),
+				Cloud:         db.GCP,
+				Region:        "europe-west1",
+				ProviderAlias: "secondary",
+				CreatedAt:     created.Add(-24 * time.Hour),
+			},
+			RateCount: 7,
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := formatSnapshotList(&buf, rows); err != nil {
+		t.Fatalf("format failed: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected header + 2 rows, got %d lines:\n%s", len(lines), buf.String())
+	}
+
+	header := strings.Fields(lines[0])
+	want := []string{"ID", "CLOUD", "REGION", "ALIAS", "ACTIVE", "RATES", "CREATED"}
+	if strings.Join(header, " ") != strings.Join(want, " ") {
+		t.Errorf("header = %v, want %v", header, want)
+	}
+
+	first := strings.Fields(lines[1])
+	if first[0] != "11111111-1111-1111-1111-111111111111" || first[1] != "aws" || first[4] != "yes" || first[5] != "1234" {
+		t.Errorf("unexpected first row: %q", lines[1])
+	}
+	if !strings.Contains(lines[1], "2024-03-01 12:30:00") {
+		t.Errorf("expected created timestamp in first row: %q", lines[1])
+	}
+
+	second := strings.Fields(lines[2])
+	if second[3] != "secondary" || second[4] != "no" || second[5] != "7" {
+		t.Errorf("unexpected second row: %q", lines[2])
+	}
+
+	// Columns are aligned: the region column starts at the same offset in every line
+	col := strings.Index(lines[0], "REGION")
+	if strings.Index(lines[1], "us-east-1") != col || strings.Index(lines[2], "europe-west1") != col {
+		t.Errorf("region column not aligned:\n%s", buf.String())
+	}
+}
+
+func TestFormatSnapshotListEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := formatSnapshotList(&buf, nil); err != nil {
+		t.Fatalf("format failed: %v", err)
+	}
+	if got := strings.Count(buf.String(), "\n"); got != 1 {
+		t.Errorf("expected only a header line, got %d lines", got)
+	}
+}
+
+func TestTopServices(t *testing.T) {
+	rates := []ingestion.NormalizedRate{
+		{RateKey: db.RateKey{Service: "AmazonS3"}},
+		{RateKey: db.RateKey{Service: "AmazonEC2"}},
+		{RateKey: db.RateKey{Service: "AmazonEC2"}},
+		{RateKey: db.RateKey{Service: "AmazonRDS"}},
+	}
+
+	top := topServices(rates, 2)
+	if len(top) != 2 {
+		t.Fatalf("expected 2 services, got %d", len(top))
+	}
+	if top[0].Service != "AmazonEC2" || top[0].Rates != 2 {
+		t.Errorf("expected AmazonEC2 first with 2 rates, got %+v", top[0])
+	}
+	// Ties break alphabetically
+	if top[1].Service != "AmazonRDS" {
+		t.Errorf("expected AmazonRDS second, got %s", top[1].Service)
+	}
+}
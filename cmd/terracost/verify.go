@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"terraform-cost/db"
+	"terraform-cost/db/ingestion"
+
+	"github.com/google/uuid"
+)
+
+// runVerify checks a stored snapshot against its archived backup
+func runVerify(ctx context.Context, store db.PricingStore, w io.Writer, snapshotID, backupPath string) error {
+	if snapshotID == "" {
+		return fmt.Errorf("SNAPSHOT_ID environment variable is required for MODE=verify")
+	}
+	if backupPath == "" {
+		return fmt.Errorf("BACKUP_PATH environment variable is required for MODE=verify")
+	}
+	id, err := uuid.Parse(snapshotID)
+	if err != nil {
+		return fmt.Errorf("invalid SNAPSHOT_ID %q: %w", snapshotID, err)
+	}
+
+	report, err := ingestion.VerifySnapshotIntegrity(ctx, store, id, backupPath)
+	if report != nil {
+		formatIntegrityReport(w, report)
+	}
+	return err
+}
+
+// formatIntegrityReport renders the compared hashes and the verdict
+func formatIntegrityReport(w io.Writer, report *ingestion.IntegrityReport) {
+	fmt.Fprintf(w, "Snapshot:      %s\n", report.SnapshotID)
+	fmt.Fprintf(w, "Backup:        %s\n", report.BackupPath)
+	fmt.Fprintf(w, "Rates:         %d in DB, %d in backup\n", report.DBRateCount, report.BackupRateCount)
+	fmt.Fprintf(w, "Computed hash: %s\n", report.ComputedHash)
+	fmt.Fprintf(w, "Stored hash:   %s\n", report.StoredHash)
+	fmt.Fprintf(w, "Backup hash:   %s\n", report.BackupHash)
+	if report.Verified {
+		fmt.Fprintln(w, "Result:        VERIFIED")
+		return
+	}
+	fmt.Fprintf(w, "Result:        MISMATCH (%s)\n", report.Mismatch)
+}
@@ -0,0 +1,38 @@
+// Package db - Rate key attribute matching
+package db
+
+import (
+	"sort"
+	"strings"
+)
+
+// AttributesContain reports whether stored contains every attribute in query
+// with an equal value. This is the contract of the PostgreSQL resolver's
+// JSONB containment (rk.attributes @> query): a query is a subset match, an
+// attribute the stored key lacks never matches (even with an empty value),
+// and an empty or nil query matches every rate key. Alternative stores must
+// resolve with this helper so backends cannot diverge.
+func AttributesContain(stored, query map[string]string) bool {
+	for k, v := range query {
+		if got, ok := stored[k]; !ok || got != v {
+			return false
+		}
+	}
+	return true
+}
+
+// AttributesSortKey renders attributes as key=value pairs sorted by key and
+// joined by commas. Stores order results by attributes with this key,
+// compared bytewise, so every backend returns the same order.
+func AttributesSortKey(attrs map[string]string) string {
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = k + "=" + attrs[k]
+	}
+	return strings.Join(pairs, ",")
+}
@@ -0,0 +1,41 @@
+// Package db - Attribute containment tests
+package db
+
+import "testing"
+
+func TestAttributesContain(t *testing.T) {
+	stored := map[string]string{"instance_type": "m5.large", "os": "linux", "tenancy": "shared"}
+
+	tests := []struct {
+		name  string
+		query map[string]string
+		want  bool
+	}{
+		{"subset", map[string]string{"instance_type": "m5.large", "os": "linux"}, true},
+		{"exact", map[string]string{"instance_type": "m5.large", "os": "linux", "tenancy": "shared"}, true},
+		{"value mismatch", map[string]string{"instance_type": "m5.large", "os": "windows"}, false},
+		{"missing attribute", map[string]string{"license": "byol"}, false},
+		{"empty value for missing attribute", map[string]string{"license": ""}, false},
+		{"superset", map[string]string{"instance_type": "m5.large", "os": "linux", "tenancy": "shared", "license": "byol"}, false},
+		{"empty query", map[string]string{}, true},
+		{"nil query", nil, true},
+	}
+	for _, tt := range tests {
+		if got := AttributesContain(stored, tt.query); got != tt.want {
+			t.Errorf("%s: AttributesContain(%v) = %v, want %v", tt.name, tt.query, got, tt.want)
+		}
+	}
+
+	if !AttributesContain(nil, nil) {
+		t.Error("empty query should match a key without attributes")
+	}
+}
+
+func TestAttributesSortKey(t *testing.T) {
+	if got := AttributesSortKey(map[string]string{"os": "linux", "instance_type": "m5.large"}); got != "instance_type=m5.large,os=linux" {
+		t.Errorf("expected pairs sorted by key, got %q", got)
+	}
+	if got := AttributesSortKey(nil); got != "" {
+		t.Errorf("expected an empty key without attributes, got %q", got)
+	}
+}
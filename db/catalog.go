@@ -0,0 +1,118 @@
+// Package db - Monthly cost catalog for an active snapshot
+package db
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// HoursPerMonth is the billing convention for hourly rates
+const HoursPerMonth = 730
+
+// CatalogCost is the monthly cost of running one priced item for a full month
+type CatalogCost struct {
+	Service       string
+	ProductFamily string
+	Attributes    map[string]string
+	Unit          string
+	HourlyPrice   decimal.Decimal
+	MonthlyCost   decimal.Decimal
+	Currency      string
+}
+
+// IsHourlyUnit reports whether a normalized unit is billed per hour,
+// including suffixed units such as "vcpu-hours"
+func IsHourlyUnit(unit string) bool {
+	u := strings.ToLower(unit)
+	switch u {
+	case "hours", "hour", "hrs", "hr":
+		return true
+	}
+	return strings.HasSuffix(u, "-hours") || strings.HasSuffix(u, "-hrs")
+}
+
+// MonthlyCostCatalog resolves every hourly rate key of a service in the
+// active snapshot in a single batch and returns its cost for HoursPerMonth, cheapest first.
+// Rates in non-hourly units are skipped.
+func MonthlyCostCatalog(ctx context.Context, resolver *StrictResolver, cloud CloudProvider, region, service string) ([]CatalogCost, error) {
+	if err := resolver.Validate(); err != nil {
+		return nil, err
+	}
+	snapshot, err := resolver.store.GetActiveSnapshot(ctx, cloud, region, resolver.defaultAlias)
+	if err != nil {
+		return nil, fmt.Errorf("snapshot lookup failed: %w", err)
+	}
+	if snapshot == nil {
+		return nil, fmt.Errorf("no active pricing snapshot for %s/%s/%s", cloud, region, resolver.defaultAlias)
+	}
+
+	rates, err := resolver.store.GetRatesBySnapshot(ctx, snapshot.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load rates: %w", err)
+	}
+
+	// Tiers and effective dates share a key; resolve each key once
+	var requests []ResolutionRequest
+	var queries []RateKeyQuery
+	seen := make(map[string]bool)
+	for _, sr := range rates {
+		if sr.RateKey.Service != service || !IsHourlyUnit(sr.Rate.Unit) {
+			continue
+		}
+		seenKey := sr.RateKey.ID.String() + "|" + sr.Rate.Unit
+		if seen[seenKey] {
+			continue
+		}
+		seen[seenKey] = true
+
+		requests = append(requests, ResolutionRequest{
+			Cloud:         cloud,
+			Service:       service,
+			ProductFamily: sr.RateKey.ProductFamily,
+			Region:        region,
+			Alias:         resolver.defaultAlias,
+			Attributes:    sr.RateKey.Attributes,
+			Unit:          sr.Rate.Unit,
+		})
+		queries = append(queries, RateKeyQuery{
+			Service:       service,
+			ProductFamily: sr.RateKey.ProductFamily,
+			Attributes:    sr.RateKey.Attributes,
+			Unit:          sr.Rate.Unit,
+		})
+	}
+
+	resolved, err := resolver.store.ResolveRatesBatch(ctx, snapshot.ID, queries, ResolveOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve rates: %w", err)
+	}
+	resolver.trackSnapshot(cloud, region, resolver.defaultAlias, snapshot.ID)
+
+	var catalog []CatalogCost
+	for i, req := range requests {
+		res, err := resolver.result(req, snapshot, resolved[i])
+		if err != nil {
+			return nil, err
+		}
+		if res.IsSymbolic {
+			continue
+		}
+
+		catalog = append(catalog, CatalogCost{
+			Service:       service,
+			ProductFamily: req.ProductFamily,
+			Attributes:    req.Attributes,
+			Unit:          req.Unit,
+			HourlyPrice:   *res.Price,
+			MonthlyCost:   res.Price.Mul(decimal.NewFromInt(HoursPerMonth)),
+			Currency:      res.Currency,
+		})
+	}
+
+	sort.SliceStable(catalog, func(i, j int) bool { return catalog[i].MonthlyCost.LessThan(catalog[j].MonthlyCost) })
+	return catalog, nil
+}
@@ -0,0 +1,128 @@
+// Package db - Monthly cost catalog tests
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// catalogStore serves one snapshot's rates and resolves by attribute containment
+type catalogStore struct {
+	PricingStore
+	snapshot *PricingSnapshot
+	rates    []SnapshotRate
+	batches  int
+}
+
+func (s *catalogStore) GetActiveSnapshot(ctx context.Context, cloud CloudProvider, region, alias string) (*PricingSnapshot, error) {
+	return s.snapshot, nil
+}
+
+func (s *catalogStore) GetRatesBySnapshot(ctx context.Context, snapshotID uuid.UUID) ([]SnapshotRate, error) {
+	return s.rates, nil
+}
+
+// matches returns every rate whose key contains the query's attributes
+func (s *catalogStore) matches(q RateKeyQuery) []ResolvedRate {
+	var rates []ResolvedRate
+	for _, sr := range s.rates {
+		if sr.RateKey.Service == q.Service && sr.RateKey.ProductFamily == q.ProductFamily &&
+			sr.Rate.Unit == q.Unit && AttributesContain(sr.RateKey.Attributes, q.Attributes) {
+			rates = append(rates, ResolvedRate{Price: sr.Rate.Price, Currency: sr.Rate.Currency, Confidence: 1,
+				SnapshotID: s.snapshot.ID, Attributes: sr.RateKey.Attributes})
+		}
+	}
+	return rates
+}
+
+func (s *catalogStore) match(q RateKeyQuery) *ResolvedRate {
+	if rates := s.matches(q); len(rates) > 0 {
+		return &rates[0]
+	}
+	return nil
+}
+
+func (s *catalogStore) ResolveRate(ctx context.Context, cloud CloudProvider, service, productFamily, region string, attrs map[string]string, unit, alias string, opts ResolveOptions) (*ResolvedRate, error) {
+	return s.match(RateKeyQuery{Service: service, ProductFamily: productFamily, Attributes: attrs, Unit: unit}), nil
+}
+
+func (s *catalogStore) ResolveAllMatching(ctx context.Context, cloud CloudProvider, service, productFamily, region string, attrs map[string]string, unit, alias string, opts ResolveOptions) ([]ResolvedRate, error) {
+	return s.matches(RateKeyQuery{Service: service, ProductFamily: productFamily, Attributes: attrs, Unit: unit}), nil
+}
+
+func (s *catalogStore) ResolveRatesBatch(ctx context.Context, snapshotID uuid.UUID, queries []RateKeyQuery, opts ResolveOptions) (map[int]*ResolvedRate, error) {
+	s.batches++
+	resolved := make(map[int]*ResolvedRate)
+	for i, q := range queries {
+		if rate := s.match(q); rate != nil {
+			resolved[i] = rate
+		}
+	}
+	return resolved, nil
+}
+
+func TestMonthlyCostCatalog(t *testing.T) {
+	rate := func(service, instanceType, unit, price string) SnapshotRate {
+		return SnapshotRate{
+			RateKey: RateKey{ID: uuid.New(), Cloud: AWS, Service: service, ProductFamily: "Compute Instance", Region: "us-east-1",
+				Attributes: map[string]string{"instance_type": instanceType}},
+			Rate: PricingRate{Unit: unit, Price: decimal.RequireFromString(price), Currency: "USD"},
+		}
+	}
+	store := &catalogStore{
+		snapshot: &PricingSnapshot{ID: uuid.New()},
+		rates: []SnapshotRate{
+			rate("AmazonEC2", "m5.large", "hours", "0.096"),
+			rate("AmazonEC2", "t3.micro", "hours", "0.0104"),
+			rate("AmazonEC2", "dedicated", "host-hours", "1.2"),
+			rate("AmazonEC2", "gp3", "GB-month", "0.08"), // not hourly
+			rate("AmazonRDS", "db.t3.micro", "hours", "0.017"),
+		},
+	}
+
+	catalog, err := MonthlyCostCatalog(context.Background(), NewStrictResolver(store), AWS, "us-east-1", "AmazonEC2")
+	if err != nil {
+		t.Fatalf("catalog failed: %v", err)
+	}
+	if store.batches != 1 {
+		t.Errorf("expected one batched resolution, got %d", store.batches)
+	}
+	if len(catalog) != 3 {
+		t.Fatalf("expected 3 hourly EC2 entries, got %d: %+v", len(catalog), catalog)
+	}
+
+	want := []struct{ instanceType, monthly string }{
+		{"t3.micro", "7.592"},
+		{"m5.large", "70.08"},
+		{"dedicated", "876"},
+	}
+	for i, w := range want {
+		got := catalog[i]
+		if got.Attributes["instance_type"] != w.instanceType {
+			t.Errorf("entry %d: expected %s, got %s", i, w.instanceType, got.Attributes["instance_type"])
+		}
+		if !got.MonthlyCost.Equal(decimal.RequireFromString(w.monthly)) {
+			t.Errorf("%s: monthly cost %s, want %s", w.instanceType, got.MonthlyCost, w.monthly)
+		}
+	}
+}
+
+func TestMonthlyCostCatalogNoSnapshot(t *testing.T) {
+	if _, err := MonthlyCostCatalog(context.Background(), NewStrictResolver(&catalogStore{}), AWS, "us-east-1", "AmazonEC2"); err == nil {
+		t.Error("expected an error without an active snapshot")
+	}
+}
+
+func TestIsHourlyUnit(t *testing.T) {
+	for unit, want := range map[string]bool{
+		"hours": true, "Hrs": true, "hr": true, "vcpu-hours": true, "node-hrs": true,
+		"GB-month": false, "requests": false, "hoursly": false,
+	} {
+		if got := IsHourlyUnit(unit); got != want {
+			t.Errorf("IsHourlyUnit(%q) = %v, want %v", unit, got, want)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+// Package db - Negotiated discount overlays applied at resolution time
+package db
+
+import (
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+// DiscountConfig describes negotiated discounts off list price, in percent
+// (15 = 15% off). A service entry overrides the global discount, so an entry
+// of 0 exempts that service.
+type DiscountConfig struct {
+	GlobalPercent   float64
+	ServicePercents map[string]float64
+}
+
+// Validate checks every discount is within 0-100%
+func (c DiscountConfig) Validate() error {
+	if c.GlobalPercent < 0 || c.GlobalPercent > 100 {
+		return fmt.Errorf("global discount %.2f%% outside 0-100%%", c.GlobalPercent)
+	}
+	for service, pct := range c.ServicePercents {
+		if pct < 0 || pct > 100 {
+			return fmt.Errorf("discount for %s %.2f%% outside 0-100%%", service, pct)
+		}
+	}
+	return nil
+}
+
+// DiscountOverlay applies a DiscountConfig to resolved list prices
+type DiscountOverlay struct {
+	config DiscountConfig
+}
+
+// NewDiscountOverlay creates an overlay for a discount config
+func NewDiscountOverlay(config DiscountConfig) *DiscountOverlay {
+	return &DiscountOverlay{config: config}
+}
+
+// PercentFor returns the discount percent for a service
+func (o *DiscountOverlay) PercentFor(service string) float64 {
+	if pct, ok := o.config.ServicePercents[service]; ok {
+		return pct
+	}
+	return o.config.GlobalPercent
+}
+
+// Apply returns the discounted price for a service along with the percent applied
+func (o *DiscountOverlay) Apply(service string, listPrice decimal.Decimal) (decimal.Decimal, float64) {
+	pct := o.PercentFor(service)
+	if pct == 0 {
+		return listPrice, 0
+	}
+	multiplier := decimal.NewFromInt(1).Sub(decimal.NewFromFloat(pct).Div(decimal.NewFromInt(100)))
+	return listPrice.Mul(multiplier), pct
+}
@@ -0,0 +1,116 @@
+// Package db - Discount overlay tests
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// tieredCatalogStore adds fixed tiers to catalogStore
+type tieredCatalogStore struct {
+	catalogStore
+	tiers []TieredRate
+}
+
+func (s *tieredCatalogStore) ResolveTieredRates(ctx context.Context, cloud CloudProvider, service, productFamily, region string, attrs map[string]string, unit, alias string, opts ResolveOptions) ([]TieredRate, error) {
+	return s.tiers, nil
+}
+
+func TestStrictResolverDiscount(t *testing.T) {
+	rate := func(service, price string) SnapshotRate {
+		return SnapshotRate{
+			RateKey: RateKey{Service: service, Attributes: map[string]string{}},
+			Rate:    PricingRate{Unit: "hours", Price: decimal.RequireFromString(price), Currency: "USD"},
+		}
+	}
+	store := &catalogStore{
+		snapshot: &PricingSnapshot{ID: uuid.New()},
+		rates:    []SnapshotRate{rate("AmazonEC2", "0.10"), rate("AmazonRDS", "0.20"), rate("AWSSupport", "100")},
+	}
+	resolver := NewStrictResolver(store).WithDiscount(DiscountConfig{
+		GlobalPercent:   15,
+		ServicePercents: map[string]float64{"AmazonRDS": 30, "AWSSupport": 0},
+	})
+	if err := resolver.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		service  string
+		price    string
+		list     string
+		discount float64
+	}{
+		{"AmazonEC2", "0.085", "0.10", 15}, // global
+		{"AmazonRDS", "0.14", "0.20", 30},  // service override
+		{"AWSSupport", "100", "", 0},       // exempted
+	}
+	for _, tt := range tests {
+		result, err := resolver.Resolve(context.Background(), ResolutionRequest{Cloud: AWS, Service: tt.service, Region: "us-east-1", Unit: "hours"})
+		if err != nil {
+			t.Fatalf("%s: resolve failed: %v", tt.service, err)
+		}
+		if !result.Price.Equal(decimal.RequireFromString(tt.price)) || result.DiscountPercent != tt.discount {
+			t.Errorf("%s: got %s at %.0f%% off, want %s at %.0f%% off", tt.service, result.Price, result.DiscountPercent, tt.price, tt.discount)
+		}
+		switch {
+		case tt.list == "" && result.ListPrice != nil:
+			t.Errorf("%s: expected no list price without a discount, got %s", tt.service, result.ListPrice)
+		case tt.list != "" && (result.ListPrice == nil || !result.ListPrice.Equal(decimal.RequireFromString(tt.list))):
+			t.Errorf("%s: list price %v, want %s", tt.service, result.ListPrice, tt.list)
+		}
+	}
+}
+
+func TestStrictResolverDiscountTiers(t *testing.T) {
+	limit := decimal.NewFromInt(10240)
+	store := &tieredCatalogStore{
+		catalogStore: catalogStore{snapshot: &PricingSnapshot{ID: uuid.New()}},
+		tiers: []TieredRate{
+			{Min: decimal.Zero, Max: &limit, Price: decimal.RequireFromString("0.09"), Currency: "USD", Confidence: 1},
+			{Min: limit, Price: decimal.RequireFromString("0.085"), Currency: "USD", Confidence: 1},
+		},
+	}
+
+	resolver := NewStrictResolver(store).WithDiscount(DiscountConfig{GlobalPercent: 15})
+	result, err := resolver.ResolveTiered(context.Background(), ResolutionRequest{Cloud: AWS, Service: "AWSDataTransfer", Region: "us-east-1", Unit: "GB"})
+	if err != nil {
+		t.Fatalf("resolve failed: %v", err)
+	}
+	if result.DiscountPercent != 15 || len(result.ListTiers) != 2 {
+		t.Fatalf("expected 15%% off with list tiers kept, got %+v", result)
+	}
+	for i, want := range []string{"0.0765", "0.07225"} {
+		if !result.Tiers[i].Price.Equal(decimal.RequireFromString(want)) {
+			t.Errorf("tier %d: price %s, want %s", i, result.Tiers[i].Price, want)
+		}
+	}
+	if !result.ListTiers[0].Price.Equal(decimal.RequireFromString("0.09")) {
+		t.Errorf("list tier was modified: %s", result.ListTiers[0].Price)
+	}
+}
+
+func TestDiscountConfigValidate(t *testing.T) {
+	if err := (DiscountConfig{GlobalPercent: 15, ServicePercents: map[string]float64{"AmazonRDS": 30}}).Validate(); err != nil {
+		t.Errorf("expected valid config, got %v", err)
+	}
+	for _, bad := range []DiscountConfig{
+		{GlobalPercent: -1},
+		{GlobalPercent: 101},
+		{ServicePercents: map[string]float64{"AmazonEC2": 150}},
+	} {
+		if err := bad.Validate(); err == nil {
+			t.Errorf("expected %+v to be rejected", bad)
+		}
+		resolver := NewStrictResolver(nil).WithDiscount(bad)
+		if err := resolver.Validate(); err == nil {
+			t.Errorf("expected Validate to reject %+v", bad)
+		}
+		if _, err := resolver.Resolve(context.Background(), ResolutionRequest{Cloud: AWS, Service: "AmazonEC2"}); err == nil {
+			t.Errorf("expected resolution to fail with %+v", bad)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+// Package db - Resolution errors
+package db
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Sentinel resolution errors, matched with errors.Is. The errors returned by
+// Resolver and StrictResolver keep their descriptive messages and wrap one
+// of these.
+var (
+	// ErrNoSnapshot means no pricing snapshot covers the cloud, region and alias
+	ErrNoSnapshot = errors.New("no pricing snapshot")
+
+	// ErrNoRate means the snapshot holds no rate matching the request
+	ErrNoRate = errors.New("no matching rate")
+
+	// ErrAmbiguousRate means several rates match a strict request
+	ErrAmbiguousRate = errors.New("ambiguous rate")
+)
+
+// resolutionError carries a descriptive message and the sentinel it wraps
+type resolutionError struct {
+	kind error
+	msg  string
+}
+
+func (e *resolutionError) Error() string {
+	return e.msg
+}
+
+func (e *resolutionError) Unwrap() error {
+	return e.kind
+}
+
+// resolutionErrorf formats a message wrapping the sentinel kind
+func resolutionErrorf(kind error, format string, args ...interface{}) error {
+	return &resolutionError{kind: kind, msg: fmt.Sprintf(format, args...)}
+}
+
+// AmbiguousRateError is returned in strict mode when the request's attributes
+// match more than one rate. It matches ErrAmbiguousRate.
+type AmbiguousRateError struct {
+	Request ResolutionRequest
+	Matches []ResolvedRate
+}
+
+func (e *AmbiguousRateError) Error() string {
+	req := e.Request
+	return fmt.Sprintf("strict mode: %d rates match %s/%s/%s unit=%s attributes=%v (first two: %v, %v); specify more attributes",
+		len(e.Matches), req.Service, req.ProductFamily, req.Region, req.Unit, req.Attributes, e.Matches[0].Attributes, e.Matches[1].Attributes)
+}
+
+func (e *AmbiguousRateError) Is(target error) bool {
+	return target == ErrAmbiguousRate
+}
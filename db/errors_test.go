@@ -0,0 +1,55 @@
+// Package db - Resolution error tests
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// ambiguousStore matches two rates for every request
+type ambiguousStore struct {
+	effectiveDateStore
+}
+
+func (s *ambiguousStore) ResolveAllMatching(ctx context.Context, cloud CloudProvider, service, productFamily, region string, attrs map[string]string, unit, alias string, opts ResolveOptions) ([]ResolvedRate, error) {
+	return []ResolvedRate{
+		{Price: decimal.NewFromFloat(0.1), Attributes: map[string]string{"os": "linux"}},
+		{Price: decimal.NewFromFloat(0.2), Attributes: map[string]string{"os": "windows"}},
+	}, nil
+}
+
+func TestResolutionErrorsMatchSentinels(t *testing.T) {
+	ctx := context.Background()
+	snapshot := &PricingSnapshot{ID: uuid.New(), Source: "test"}
+	req := ResolutionRequest{Cloud: AWS, Service: "AmazonEC2", Region: "us-east-1", Unit: "hours"}
+	legacyReq := ResolveRequest{Cloud: AWS, Service: "AmazonEC2", Region: "us-east-1", Unit: "hours"}
+
+	noSnapshot := &effectiveDateStore{}
+	if _, err := NewStrictResolver(noSnapshot).Resolve(ctx, req); !errors.Is(err, ErrNoSnapshot) {
+		t.Errorf("StrictResolver: expected ErrNoSnapshot, got %v", err)
+	}
+	if _, err := NewResolver(noSnapshot).WithStrictMode(true).Resolve(ctx, legacyReq); !errors.Is(err, ErrNoSnapshot) {
+		t.Errorf("Resolver: expected ErrNoSnapshot, got %v", err)
+	}
+
+	noRate := &effectiveDateStore{snapshot: snapshot}
+	if _, err := NewStrictResolver(noRate).WithMode(Strict).Resolve(ctx, req); !errors.Is(err, ErrNoRate) {
+		t.Errorf("StrictResolver: expected ErrNoRate, got %v", err)
+	}
+	if _, err := NewResolver(noRate).WithStrictMode(true).Resolve(ctx, legacyReq); !errors.Is(err, ErrNoRate) {
+		t.Errorf("Resolver: expected ErrNoRate, got %v", err)
+	}
+
+	_, err := NewStrictResolver(&ambiguousStore{effectiveDateStore{snapshot: snapshot}}).WithMode(Strict).Resolve(ctx, req)
+	var ambiguous *AmbiguousRateError
+	if !errors.Is(err, ErrAmbiguousRate) || !errors.As(err, &ambiguous) || len(ambiguous.Matches) != 2 {
+		t.Fatalf("expected an AmbiguousRateError with 2 matches, got %v", err)
+	}
+	if errors.Is(err, ErrNoRate) {
+		t.Error("an ambiguous rate must not match ErrNoRate")
+	}
+}
@@ -0,0 +1,129 @@
+// Package db - Explanations of rate resolution
+package db
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+// ResolutionExplanation reports how a lookup narrowed down to its result,
+// so a symbolic cost can be traced to the filter that ruled out every rate
+type ResolutionExplanation struct {
+	Request        ResolveRequest
+	Alias          string
+	SnapshotFound  bool
+	SnapshotID     uuid.UUID
+	KeyMatches     int // rate keys matching service, product family, region and unit
+	AttributeMatch int // rate keys left after the requested attributes
+	Attributes     []AttributeExplanation
+
+	// EliminatedBy lists the attributes whose requested value no candidate
+	// has; each one alone rules out every rate key
+	EliminatedBy []string
+	Reason       string
+}
+
+// AttributeExplanation reports how one requested attribute filters the
+// candidate rate keys
+type AttributeExplanation struct {
+	Name      string
+	Requested string
+	Matches   int      // candidates with the requested value
+	Available []string // distinct values among the candidates
+}
+
+// ExplainResolution reports why req resolves or stays symbolic: whether the
+// snapshot exists, how many rate keys match before and after the attributes,
+// and which attributes eliminated the candidates
+func (r *Resolver) ExplainResolution(ctx context.Context, req ResolveRequest) (*ResolutionExplanation, error) {
+	alias := req.Alias
+	if alias == "" {
+		alias = r.defaultAlias
+	}
+	explanation := &ResolutionExplanation{Request: req, Alias: alias}
+
+	snapshot, err := r.store.GetActiveSnapshot(ctx, req.Cloud, req.Region, alias)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active snapshot: %w", err)
+	}
+	if snapshot == nil {
+		explanation.Reason = fmt.Sprintf("no pricing snapshot for %s/%s/%s", req.Cloud, req.Region, alias)
+		return explanation, nil
+	}
+	explanation.SnapshotFound = true
+	explanation.SnapshotID = snapshot.ID
+
+	opts := ResolveOptions{AsOf: req.AsOf, Currency: req.Currency}
+	candidates, err := r.store.ResolveAllMatching(ctx, req.Cloud, req.Service, req.ProductFamily, req.Region, map[string]string{}, req.Unit, alias, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list candidate rates: %w", err)
+	}
+	explanation.KeyMatches = len(candidates)
+	if len(candidates) == 0 {
+		explanation.Reason = fmt.Sprintf("no rates for %s/%s/%s%s in snapshot %s",
+			req.Service, req.ProductFamily, req.Unit, currencySuffix(req.Currency), snapshot.ID)
+		return explanation, nil
+	}
+
+	for _, name := range sortedKeys(req.Attributes) {
+		attr := AttributeExplanation{
+			Name:      name,
+			Requested: req.Attributes[name],
+			Available: DistinctAttributeValues(candidates, name),
+		}
+		for _, c := range candidates {
+			if c.Attributes[name] == attr.Requested {
+				attr.Matches++
+			}
+		}
+		if attr.Matches == 0 {
+			explanation.EliminatedBy = append(explanation.EliminatedBy, name)
+		}
+		explanation.Attributes = append(explanation.Attributes, attr)
+	}
+
+	for _, c := range candidates {
+		if AttributesContain(c.Attributes, req.Attributes) {
+			explanation.AttributeMatch++
+		}
+	}
+
+	switch {
+	case explanation.AttributeMatch > 0:
+		explanation.Reason = fmt.Sprintf("%d of %d rate keys match", explanation.AttributeMatch, explanation.KeyMatches)
+	case len(explanation.EliminatedBy) > 0:
+		explanation.Reason = fmt.Sprintf("no rate key has the requested %v", explanation.EliminatedBy)
+	default:
+		explanation.Reason = "every attribute matches some rate key, but none matches them all"
+	}
+	return explanation, nil
+}
+
+// DistinctAttributeValues returns the sorted distinct values of one attribute
+// across rates, skipping rates without it
+func DistinctAttributeValues(rates []ResolvedRate, name string) []string {
+	seen := make(map[string]bool)
+	var values []string
+	for _, r := range rates {
+		v, ok := r.Attributes[name]
+		if !ok || seen[v] {
+			continue
+		}
+		seen[v] = true
+		values = append(values, v)
+	}
+	sort.Strings(values)
+	return values
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
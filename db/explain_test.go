@@ -0,0 +1,90 @@
+// Package db - Resolution explanation tests
+package db
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// keyListStore serves one active snapshot in us-east-1 holding keyAttrs,
+// matching attributes as a subset like the real stores
+type keyListStore struct {
+	PricingStore
+	snapshot *PricingSnapshot
+	keyAttrs []map[string]string
+}
+
+func (s *keyListStore) GetActiveSnapshot(ctx context.Context, cloud CloudProvider, region, alias string) (*PricingSnapshot, error) {
+	if region != "us-east-1" {
+		return nil, nil
+	}
+	return s.snapshot, nil
+}
+
+func (s *keyListStore) ResolveAllMatching(ctx context.Context, cloud CloudProvider, service, productFamily, region string, attrs map[string]string, unit, alias string, opts ResolveOptions) ([]ResolvedRate, error) {
+	var rates []ResolvedRate
+	for _, key := range s.keyAttrs {
+		if service == "AmazonEC2" && unit == "hrs" && AttributesContain(key, attrs) {
+			rates = append(rates, ResolvedRate{Price: decimal.RequireFromString("0.01"), SnapshotID: s.snapshot.ID, Attributes: key})
+		}
+	}
+	return rates, nil
+}
+
+func TestExplainResolution(t *testing.T) {
+	ctx := context.Background()
+	store := &keyListStore{
+		snapshot: &PricingSnapshot{ID: uuid.New()},
+		keyAttrs: []map[string]string{
+			{"instanceType": "t3.micro", "operatingSystem": "Linux"},
+			{"instanceType": "t3.large", "operatingSystem": "Linux"},
+			{"instanceType": "t3.micro", "operatingSystem": "Windows"},
+		},
+	}
+	resolver := NewResolver(store)
+
+	// No snapshot for the region
+	explanation, err := resolver.ExplainResolution(ctx, ResolveRequest{Cloud: AWS, Service: "AmazonEC2", Region: "eu-west-1", Unit: "hrs"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if explanation.SnapshotFound || explanation.KeyMatches != 0 || !strings.Contains(explanation.Reason, "no pricing snapshot") {
+		t.Errorf("expected a missing snapshot explanation, got %+v", explanation)
+	}
+
+	// The operating system rules out every key
+	explanation, err = resolver.ExplainResolution(ctx, ResolveRequest{
+		Cloud: AWS, Service: "AmazonEC2", Region: "us-east-1", Unit: "hrs",
+		Attributes: map[string]string{"instanceType": "t3.micro", "operatingSystem": "RHEL"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !explanation.SnapshotFound || explanation.SnapshotID != store.snapshot.ID || explanation.KeyMatches != 3 || explanation.AttributeMatch != 0 {
+		t.Errorf("unexpected counts: %+v", explanation)
+	}
+	if !reflect.DeepEqual(explanation.EliminatedBy, []string{"operatingSystem"}) {
+		t.Errorf("expected operatingSystem to eliminate the candidates, got %v", explanation.EliminatedBy)
+	}
+	os := explanation.Attributes[1]
+	if os.Name != "operatingSystem" || os.Matches != 0 || !reflect.DeepEqual(os.Available, []string{"Linux", "Windows"}) {
+		t.Errorf("expected the available operating systems, got %+v", os)
+	}
+	if it := explanation.Attributes[0]; it.Name != "instanceType" || it.Matches != 2 {
+		t.Errorf("expected two t3.micro candidates, got %+v", it)
+	}
+
+	// Each attribute matches some key, but not together
+	explanation, _ = resolver.ExplainResolution(ctx, ResolveRequest{
+		Cloud: AWS, Service: "AmazonEC2", Region: "us-east-1", Unit: "hrs",
+		Attributes: map[string]string{"instanceType": "t3.large", "operatingSystem": "Windows"},
+	})
+	if explanation.AttributeMatch != 0 || len(explanation.EliminatedBy) != 0 || !strings.Contains(explanation.Reason, "none matches them all") {
+		t.Errorf("expected a combination mismatch, got %+v", explanation)
+	}
+}
@@ -0,0 +1,189 @@
+// Package ingestion - Configurable attribute transforms applied after normalization
+package ingestion
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"terraform-cost/db"
+)
+
+// AttributeTransformer rewrites a rate key's attributes in place
+type AttributeTransformer interface {
+	Transform(attrs map[string]string)
+}
+
+// RenameTransform moves an attribute to a new key, replacing any value there
+type RenameTransform struct {
+	From, To string
+}
+
+func (t RenameTransform) Transform(attrs map[string]string) {
+	if v, ok := attrs[t.From]; ok {
+		delete(attrs, t.From)
+		attrs[t.To] = v
+	}
+}
+
+// LowercaseTransform lowercases the values of Keys (empty = every attribute)
+type LowercaseTransform struct {
+	Keys []string
+}
+
+func (t LowercaseTransform) Transform(attrs map[string]string) {
+	if len(t.Keys) == 0 {
+		for k, v := range attrs {
+			attrs[k] = strings.ToLower(v)
+		}
+		return
+	}
+	for _, k := range t.Keys {
+		if v, ok := attrs[k]; ok {
+			attrs[k] = strings.ToLower(v)
+		}
+	}
+}
+
+// StripPrefixTransform removes Prefix from the value of Key
+type StripPrefixTransform struct {
+	Key, Prefix string
+}
+
+func (t StripPrefixTransform) Transform(attrs map[string]string) {
+	if v, ok := attrs[t.Key]; ok {
+		attrs[t.Key] = strings.TrimPrefix(v, t.Prefix)
+	}
+}
+
+// DropEmptyTransform removes attributes whose value is blank
+type DropEmptyTransform struct{}
+
+func (DropEmptyTransform) Transform(attrs map[string]string) {
+	for k, v := range attrs {
+		if strings.TrimSpace(v) == "" {
+			delete(attrs, k)
+		}
+	}
+}
+
+// MapValuesTransform replaces values of Key found in Values; others are kept
+type MapValuesTransform struct {
+	Key    string
+	Values map[string]string
+}
+
+func (t MapValuesTransform) Transform(attrs map[string]string) {
+	if v, ok := attrs[t.Key]; ok {
+		if mapped, ok := t.Values[v]; ok {
+			attrs[t.Key] = mapped
+		}
+	}
+}
+
+// ChainNormalizer applies an ordered list of attribute transforms after the
+// base normalizer. Wrap it in a FilteredNormalizer to dedup rates that the
+// transforms collapse onto one key.
+type ChainNormalizer struct {
+	inner      PriceNormalizer
+	transforms []AttributeTransformer
+}
+
+// NewChainNormalizer creates a normalizer that transforms attributes in order
+func NewChainNormalizer(inner PriceNormalizer, transforms ...AttributeTransformer) *ChainNormalizer {
+	return &ChainNormalizer{inner: inner, transforms: transforms}
+}
+
+func (n *ChainNormalizer) Cloud() db.CloudProvider {
+	return n.inner.Cloud()
+}
+
+// Normalize normalizes with the base normalizer, then applies the transforms
+func (n *ChainNormalizer) Normalize(ctx context.Context, raw []RawPrice) ([]NormalizedRate, error) {
+	rates, err := n.inner.Normalize(ctx, raw)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range rates {
+		if rates[i].RateKey.Attributes == nil {
+			rates[i].RateKey.Attributes = make(map[string]string)
+		}
+		for _, t := range n.transforms {
+			t.Transform(rates[i].RateKey.Attributes)
+		}
+	}
+	return rates, nil
+}
+
+// TransformConfig is the external transform chain format
+type TransformConfig struct {
+	Transforms []TransformEntry `json:"transforms"`
+}
+
+// TransformEntry configures one transform; which fields apply depends on Type
+type TransformEntry struct {
+	Type   string            `json:"type"` // rename|lowercase|strip_prefix|drop_empty|map_values
+	From   string            `json:"from,omitempty"`
+	To     string            `json:"to,omitempty"`
+	Key    string            `json:"key,omitempty"`
+	Keys   []string          `json:"keys,omitempty"`
+	Prefix string            `json:"prefix,omitempty"`
+	Values map[string]string `json:"values,omitempty"`
+}
+
+// LoadTransformsFromJSON builds a transform chain from a JSON config:
+//
+//	{"transforms": [{"type": "rename", "from": "os", "to": "operating_system"},
+//	  {"type": "map_values", "key": "operating_system", "values": {"rhel": "redhat"}}]}
+func LoadTransformsFromJSON(r io.Reader) ([]AttributeTransformer, error) {
+	var cfg TransformConfig
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to decode transforms: %w", err)
+	}
+
+	transforms := make([]AttributeTransformer, 0, len(cfg.Transforms))
+	for i, e := range cfg.Transforms {
+		var t AttributeTransformer
+		switch e.Type {
+		case "rename":
+			if e.From == "" || e.To == "" {
+				return nil, fmt.Errorf("transform %d (rename): from and to are required", i)
+			}
+			t = RenameTransform{From: e.From, To: e.To}
+		case "lowercase":
+			t = LowercaseTransform{Keys: e.Keys}
+		case "strip_prefix":
+			if e.Key == "" || e.Prefix == "" {
+				return nil, fmt.Errorf("transform %d (strip_prefix): key and prefix are required", i)
+			}
+			t = StripPrefixTransform{Key: e.Key, Prefix: e.Prefix}
+		case "drop_empty":
+			t = DropEmptyTransform{}
+		case "map_values":
+			if e.Key == "" || len(e.Values) == 0 {
+				return nil, fmt.Errorf("transform %d (map_values): key and values are required", i)
+			}
+			t = MapValuesTransform{Key: e.Key, Values: e.Values}
+		default:
+			return nil, fmt.Errorf("transform %d: unknown type %q", i, e.Type)
+		}
+		transforms = append(transforms, t)
+	}
+	return transforms, nil
+}
+
+// LoadTransformsFromFile builds a transform chain from a JSON file
+func LoadTransformsFromFile(path string) ([]AttributeTransformer, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return LoadTransformsFromJSON(f)
+}
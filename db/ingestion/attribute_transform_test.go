@@ -0,0 +1,79 @@
+// Package ingestion - Attribute transform chain tests
+package ingestion
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"terraform-cost/db"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestChainNormalizerRenameAndMapValues(t *testing.T) {
+	inner := &fixedNormalizer{rates: []NormalizedRate{
+		{RateKey: db.RateKey{Cloud: db.AWS, Service: "AmazonEC2", Attributes: map[string]string{"os": "rhel", "instance_type": "t3.micro"}}, Price: decimal.RequireFromString("0.0716")},
+		{RateKey: db.RateKey{Cloud: db.AWS, Service: "AmazonEC2", Attributes: map[string]string{"os": "linux", "instance_type": "t3.micro"}}, Price: decimal.RequireFromString("0.0104")},
+		{RateKey: db.RateKey{Cloud: db.AWS, Service: "AmazonS3"}, Price: decimal.RequireFromString("0.023")},
+	}}
+
+	// Values are mapped under the renamed key, so order matters
+	normalizer := NewChainNormalizer(inner,
+		RenameTransform{From: "os", To: "operating_system"},
+		MapValuesTransform{Key: "operating_system", Values: map[string]string{"rhel": "redhat"}},
+	)
+	rates, err := normalizer.Normalize(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("normalize failed: %v", err)
+	}
+
+	want := []map[string]string{
+		{"operating_system": "redhat", "instance_type": "t3.micro"},
+		{"operating_system": "linux", "instance_type": "t3.micro"},
+		{},
+	}
+	for i, r := range rates {
+		got := r.RateKey.Attributes
+		if len(got) != len(want[i]) {
+			t.Errorf("rate %d: attributes = %v, want %v", i, got, want[i])
+			continue
+		}
+		for k, v := range want[i] {
+			if got[k] != v {
+				t.Errorf("rate %d: %s = %q, want %q", i, k, got[k], v)
+			}
+		}
+	}
+}
+
+func TestLoadTransformsFromJSON(t *testing.T) {
+	transforms, err := LoadTransformsFromJSON(strings.NewReader(`{"transforms": [
+		{"type": "drop_empty"},
+		{"type": "lowercase", "keys": ["usage_type"]},
+		{"type": "strip_prefix", "key": "usage_type", "prefix": "use1-"},
+		{"type": "rename", "from": "usage_type", "to": "usage"}
+	]}`))
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+
+	attrs := map[string]string{"usage_type": "USE1-NatGateway-Hours", "license": " "}
+	for _, tr := range transforms {
+		tr.Transform(attrs)
+	}
+	if len(attrs) != 1 || attrs["usage"] != "natgateway-hours" {
+		t.Errorf("unexpected attributes after chain: %v", attrs)
+	}
+
+	for _, bad := range []string{
+		`{"transforms": [{"type": "uppercase"}]}`,
+		`{"transforms": [{"type": "rename", "from": "os"}]}`,
+		`{"transforms": [{"type": "map_values", "key": "os"}]}`,
+		`{"transforms": [{"type": "drop_empty", "unknown": true}]}`,
+	} {
+		if _, err := LoadTransformsFromJSON(strings.NewReader(bad)); err == nil {
+			t.Errorf("expected %s to be rejected", bad)
+		}
+	}
+}
@@ -62,6 +62,7 @@ func (f *AWSFetcher) SupportedServices() []string {
 		"AmazonCloudWatch",
 		"AmazonVPC",
 		"AWSDataTransfer",
+		"AmazonCloudFront",
 		"AWSQueueService",
 		"AmazonSNS",
 		"awskms",
@@ -440,8 +441,18 @@ func (f *AWSFetcher) getStubPrices(region string) []RawPrice {
 		// ============================================================
 		// DATA TRANSFER (Cross-region, Internet)
 		// ============================================================
-		{SKU: "data-transfer-out-internet", ServiceCode: "AWSDataTransfer", ProductFamily: "Data Transfer", Region: region,
-			Unit: "GB", PricePerUnit: "0.09", Currency: "USD",
+		// Internet egress is tiered by monthly volume (GB): 10TB, 40TB, 100TB, then flat
+		{SKU: "data-transfer-out-internet-t1", ServiceCode: "AWSDataTransfer", ProductFamily: "Data Transfer", Region: region,
+			Unit: "GB", PricePerUnit: "0.09", Currency: "USD", TierStart: gbTier(0), TierEnd: gbTier(10240),
+			Attributes: map[string]string{"transferType": "AWS Outbound", "toLocation": "External"}},
+		{SKU: "data-transfer-out-internet-t2", ServiceCode: "AWSDataTransfer", ProductFamily: "Data Transfer", Region: region,
+			Unit: "GB", PricePerUnit: "0.085", Currency: "USD", TierStart: gbTier(10240), TierEnd: gbTier(51200),
+			Attributes: map[string]string{"transferType": "AWS Outbound", "toLocation": "External"}},
+		{SKU: "data-transfer-out-internet-t3", ServiceCode: "AWSDataTransfer", ProductFamily: "Data Transfer", Region: region,
+			Unit: "GB", PricePerUnit: "0.07", Currency: "USD", TierStart: gbTier(51200), TierEnd: gbTier(153600),
+			Attributes: map[string]string{"transferType": "AWS Outbound", "toLocation": "External"}},
+		{SKU: "data-transfer-out-internet-t4", ServiceCode: "AWSDataTransfer", ProductFamily: "Data Transfer", Region: region,
+			Unit: "GB", PricePerUnit: "0.05", Currency: "USD", TierStart: gbTier(153600),
 			Attributes: map[string]string{"transferType": "AWS Outbound", "toLocation": "External"}},
 		{SKU: "data-transfer-in-internet", ServiceCode: "AWSDataTransfer", ProductFamily: "Data Transfer", Region: region,
 			Unit: "GB", PricePerUnit: "0.00", Currency: "USD",
@@ -453,6 +464,35 @@ func (f *AWSFetcher) getStubPrices(region string) []RawPrice {
 			Unit: "GB", PricePerUnit: "0.01", Currency: "USD",
 			Attributes: map[string]string{"transferType": "IntraRegion"}},
 
+		// ============================================================
+		// CLOUDFRONT - aws_cloudfront_distribution
+		// ============================================================
+		// Egress to the internet (US/Europe edge), tiered by monthly volume (GB)
+		{SKU: "cloudfront-out-t1", ServiceCode: "AmazonCloudFront", ProductFamily: "Data Transfer", Region: region,
+			Unit: "GB", PricePerUnit: "0.085", Currency: "USD", TierStart: gbTier(0), TierEnd: gbTier(10240),
+			Attributes: map[string]string{"transferType": "CloudFront Outbound", "usagetype": "DataTransfer-Out-Bytes"}},
+		{SKU: "cloudfront-out-t2", ServiceCode: "AmazonCloudFront", ProductFamily: "Data Transfer", Region: region,
+			Unit: "GB", PricePerUnit: "0.080", Currency: "USD", TierStart: gbTier(10240), TierEnd: gbTier(51200),
+			Attributes: map[string]string{"transferType": "CloudFront Outbound", "usagetype": "DataTransfer-Out-Bytes"}},
+		{SKU: "cloudfront-out-t3", ServiceCode: "AmazonCloudFront", ProductFamily: "Data Transfer", Region: region,
+			Unit: "GB", PricePerUnit: "0.060", Currency: "USD", TierStart: gbTier(51200), TierEnd: gbTier(153600),
+			Attributes: map[string]string{"transferType": "CloudFront Outbound", "usagetype": "DataTransfer-Out-Bytes"}},
+		{SKU: "cloudfront-out-t4", ServiceCode: "AmazonCloudFront", ProductFamily: "Data Transfer", Region: region,
+			Unit: "GB", PricePerUnit: "0.040", Currency: "USD", TierStart: gbTier(153600), TierEnd: gbTier(512000),
+			Attributes: map[string]string{"transferType": "CloudFront Outbound", "usagetype": "DataTransfer-Out-Bytes"}},
+		{SKU: "cloudfront-out-t5", ServiceCode: "AmazonCloudFront", ProductFamily: "Data Transfer", Region: region,
+			Unit: "GB", PricePerUnit: "0.030", Currency: "USD", TierStart: gbTier(512000), TierEnd: gbTier(1048576),
+			Attributes: map[string]string{"transferType": "CloudFront Outbound", "usagetype": "DataTransfer-Out-Bytes"}},
+		{SKU: "cloudfront-out-t6", ServiceCode: "AmazonCloudFront", ProductFamily: "Data Transfer", Region: region,
+			Unit: "GB", PricePerUnit: "0.025", Currency: "USD", TierStart: gbTier(1048576), TierEnd: gbTier(5242880),
+			Attributes: map[string]string{"transferType": "CloudFront Outbound", "usagetype": "DataTransfer-Out-Bytes"}},
+		{SKU: "cloudfront-out-t7", ServiceCode: "AmazonCloudFront", ProductFamily: "Data Transfer", Region: region,
+			Unit: "GB", PricePerUnit: "0.020", Currency: "USD", TierStart: gbTier(5242880),
+			Attributes: map[string]string{"transferType": "CloudFront Outbound", "usagetype": "DataTransfer-Out-Bytes"}},
+		{SKU: "cloudfront-https-requests", ServiceCode: "AmazonCloudFront", ProductFamily: "Request", Region: region,
+			Unit: "Requests", PricePerUnit: "0.000001", Currency: "USD", // $0.01 per 10K requests
+			Attributes: map[string]string{"requestType": "HTTPS", "usagetype": "Requests-HTTPS-Per10K"}},
+
 		// ============================================================
 		// SQS - aws_sqs_queue
 		// ============================================================
@@ -508,6 +548,12 @@ func (f *AWSFetcher) getStubPrices(region string) []RawPrice {
 	}
 }
 
+// gbTier returns a tier boundary in GB for stub prices
+func gbTier(gb int64) *decimal.Decimal {
+	d := decimal.NewFromInt(gb)
+	return &d
+}
+
 // AWSNormalizer normalizes AWS pricing data
 type AWSNormalizer struct{}
 
@@ -520,10 +566,13 @@ func (n *AWSNormalizer) Cloud() db.CloudProvider {
 }
 
 // Normalize converts raw AWS prices to normalized rates
-func (n *AWSNormalizer) Normalize(raw []RawPrice) ([]NormalizedRate, error) {
+func (n *AWSNormalizer) Normalize(ctx context.Context, raw []RawPrice) ([]NormalizedRate, error) {
 	var rates []NormalizedRate
 	
-	for _, r := range raw {
+	for i, r := range raw {
+		if err := checkCancelled(ctx, i); err != nil {
+			return nil, err
+		}
 		price, err := ParsePrice(r.PricePerUnit)
 		if err != nil {
 			continue // Skip unparseable prices
@@ -548,17 +597,14 @@ func (n *AWSNormalizer) Normalize(raw []RawPrice) ([]NormalizedRate, error) {
 			Price:      price,
 			Currency:   r.Currency,
 			Confidence: 1.0, // Direct from AWS API
+
+			EffectiveDate: r.EffectiveDate,
+			Labels:        sourceLabels(r),
 		}
 		
 		// Handle tiers
-		if r.TierStart != nil {
-			d := decimal.NewFromFloat(*r.TierStart)
-			nr.TierMin = &d
-		}
-		if r.TierEnd != nil {
-			d := decimal.NewFromFloat(*r.TierEnd)
-			nr.TierMax = &d
-		}
+		nr.TierMin = r.TierStart
+		nr.TierMax = r.TierEnd
 		
 		rates = append(rates, nr)
 	}
@@ -2,16 +2,20 @@
 package ingestion
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
+	"regexp"
 	"strings"
 	"time"
 
 	"terraform-cost/db"
+	"terraform-cost/db/regions"
 
 	"github.com/shopspring/decimal"
 )
@@ -22,6 +26,12 @@ type AWSPricingAPIFetcher struct {
 	regions    []string
 	services   []string
 	baseURL    string
+	logger     *slog.Logger
+
+	breakerThreshold int
+	serviceTimeout   ServiceTimeout
+	chinaBaseURL     string // China regions have their own price list
+	registry         *regions.Registry
 }
 
 // NewAWSPricingAPIFetcher creates a new AWS Pricing API fetcher
@@ -47,13 +57,18 @@ func NewAWSPricingAPIFetcher() *AWSPricingAPIFetcher {
 			"me-south-1", "me-central-1", "il-central-1",
 			// Africa
 			"af-south-1",
+			// GovCloud and China (separate pricing sources)
+			"us-gov-west-1", "us-gov-east-1", "cn-north-1", "cn-northwest-1",
 		},
 		services: []string{
 			"AmazonEC2", "AmazonRDS", "AWSLambda", "AmazonS3", "ElasticLoadBalancing",
 			"AmazonDynamoDB", "AmazonElastiCache", "AmazonCloudWatch", "AmazonRoute53",
-			"AWSSecretsManager", "AWSKMS", "AmazonSNS", "AmazonSQS", "AmazonECS",
-			"AmazonEKS", "AWSFargate", "AmazonCloudFront", "AWSCodeBuild",
+			"AWSSecretsManager", "awskms", "AmazonSNS", "AWSQueueService", "AmazonECS",
+			"AmazonEKS", "AmazonCloudFront", "AWSCodeBuild",
 		},
+		breakerThreshold: DefaultCircuitBreakerThreshold,
+		chinaBaseURL:     "https://pricing.cn-north-1.amazonaws.com.cn",
+		registry:         regions.NewRegistry(),
 	}
 }
 
@@ -138,10 +153,35 @@ type AWSPriceDimension struct {
 	Unit         string `json:"unit"`
 	PricePerUnit struct {
 		USD string `json:"USD"`
+		CNY string `json:"CNY"` // China price list
 	} `json:"pricePerUnit"`
 	AppliesTo []string `json:"appliesTo"`
 }
 
+// SetCircuitBreakerThreshold sets how many consecutive service failures
+// abort FetchRegion (0 = never)
+func (f *AWSPricingAPIFetcher) SetCircuitBreakerThreshold(n int) {
+	f.breakerThreshold = n
+}
+
+// SetServiceTimeout sets the per-service deadline within FetchRegion
+func (f *AWSPricingAPIFetcher) SetServiceTimeout(t ServiceTimeout) {
+	f.serviceTimeout = t
+}
+
+// SetLogger sets the logger used for fetch warnings
+func (f *AWSPricingAPIFetcher) SetLogger(logger *slog.Logger) {
+	f.logger = logger
+}
+
+// SetTransport routes API calls through rt (see TransportConfig), keeping
+// the client's timeout
+func (f *AWSPricingAPIFetcher) SetTransport(rt http.RoundTripper) {
+	client := *f.httpClient
+	client.Transport = rt
+	f.httpClient = &client
+}
+
 // FetchRegion fetches all prices for a region from AWS Pricing API
 func (f *AWSPricingAPIFetcher) FetchRegion(ctx context.Context, region string) ([]RawPrice, error) {
 	var allPrices []RawPrice
@@ -149,142 +189,125 @@ func (f *AWSPricingAPIFetcher) FetchRegion(ctx context.Context, region string) (
 	// Core services to fetch
 	services := f.services
 	
-	for _, service := range services {
-		prices, err := f.fetchServicePricing(ctx, service, region)
+	failures := &serviceFailures{provider: db.AWS, region: region, expected: len(services), threshold: f.breakerThreshold}
+	for i, service := range services {
+		serviceCtx, cancel := f.serviceTimeout.context(ctx, len(services)-i)
+		prices, err := f.fetchServicePricing(serviceCtx, service, region)
+		cancel()
 		if err != nil {
-			// Log but continue with other services
-			fmt.Printf("Warning: failed to fetch %s pricing: %v\n", service, err)
+			err = serviceTimedOut(ctx, serviceCtx, err)
+			// Record and continue; the pipeline decides if enough services succeeded
+			loggerOrDefault(f.logger).Warn("failed to fetch service pricing", "provider", "aws", "region", region, "service", service, "error", err)
+			failures.add(service, err)
+			if open := failures.open(i + 1); open != nil {
+				return nil, open
+			}
 			continue
 		}
+		failures.succeeded()
 		allPrices = append(allPrices, prices...)
-		fmt.Printf("Fetched %d prices for %s\n", len(prices), service)
+		loggerOrDefault(f.logger).Debug("fetched service pricing", "provider", "aws", "region", region, "service", service, "rate_count", len(prices))
 	}
 
-	return allPrices, nil
+	return allPrices, failures.err()
+}
+
+// pricingEndpoint picks the price list host and partition for a region from
+// the registry's pricing source. GovCloud and edge zone offers are published
+// in the commercial price list; China has its own host, partition and currency.
+func (f *AWSPricingAPIFetcher) pricingEndpoint(region string) (baseURL, partition string, err error) {
+	source := "api"
+	if reg := f.registry.GetRegion(db.AWS, region); reg != nil {
+		source = reg.PricingSource
+	}
+	switch source {
+	case "api", "govcloud", "local-zone", "wavelength":
+		return f.baseURL, "aws", nil
+	case "china":
+		return f.chinaBaseURL, "cn", nil
+	}
+	return "", "", fmt.Errorf("no AWS price list for pricing source %q (region %s)", source, region)
 }
 
 // fetchServicePricing fetches pricing for a specific service using region_index
 func (f *AWSPricingAPIFetcher) fetchServicePricing(ctx context.Context, service, region string) ([]RawPrice, error) {
-	// Get the index first
-	indexURL := fmt.Sprintf("%s/offers/v1.0/aws/%s/current/region_index.json", f.baseURL, service)
-	
-	req, err := http.NewRequestWithContext(ctx, "GET", indexURL, nil)
+	url, err := f.regionPriceListURL(ctx, service, region)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := f.httpClient.Do(req)
+	// Fetch region-specific pricing
+	body, err := f.fetchBody(ctx, url, "region pricing")
 	if err != nil {
-		return nil, fmt.Errorf("index request failed: %w", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("index not found: %d", resp.StatusCode)
+	return f.parsePriceList(body, service, region)
+}
+
+// regionPriceListURL looks up the current price list of a service's region
+// in its region_index
+func (f *AWSPricingAPIFetcher) regionPriceListURL(ctx context.Context, service, region string) (string, error) {
+	baseURL, partition, err := f.pricingEndpoint(region)
+	if err != nil {
+		return "", err
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	// Get the index first
+	indexURL := fmt.Sprintf("%s/offers/v1.0/%s/%s/current/region_index.json", baseURL, partition, service)
+	body, err := f.fetchBody(ctx, indexURL, "index")
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
 	var regionIndex AWSRegionIndex
 	if err := json.Unmarshal(body, &regionIndex); err != nil {
-		return nil, fmt.Errorf("failed to parse region index: %w", err)
+		return "", fmt.Errorf("failed to parse region index: %w", err)
 	}
 
-	// Find the region-specific URL
+	// Find the region-specific URL. Edge zones without their own entry are
+	// published in their parent region's price list.
 	regionData, ok := regionIndex.Regions[region]
 	if !ok {
-		return nil, fmt.Errorf("region %s not found in index", region)
+		regionData, ok = regionIndex.Regions[f.registry.ParentRegion(db.AWS, region)]
 	}
+	if !ok {
+		return "", fmt.Errorf("region %s not found in index", region)
+	}
+	return baseURL + regionData.CurrentVersionURL, nil
+}
 
-	// Fetch region-specific pricing
-	regionURL := f.baseURL + regionData.CurrentVersionURL
-	req, err = http.NewRequestWithContext(ctx, "GET", regionURL, nil)
+// fetchBody GETs a URL and returns the full body, closing it before returning
+func (f *AWSPricingAPIFetcher) fetchBody(ctx context.Context, url, what string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err = f.httpClient.Do(req)
+	resp, err := f.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("region pricing request failed: %w", err)
+		return nil, fmt.Errorf("%s request failed: %w", what, err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("region pricing not found: %d", resp.StatusCode)
+		return nil, fmt.Errorf("%s not found: %d", what, resp.StatusCode)
 	}
 
-	body, err = io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	return f.parsePriceList(body, service, region)
+	return io.ReadAll(resp.Body)
 }
 
 // parsePriceList parses AWS price list JSON
 func (f *AWSPricingAPIFetcher) parsePriceList(data []byte, service, region string) ([]RawPrice, error) {
-	var priceList AWSPriceList
-	if err := json.Unmarshal(data, &priceList); err != nil {
-		return nil, fmt.Errorf("failed to parse price list: %w", err)
-	}
-
 	var prices []RawPrice
-
-	// Process on-demand terms
-	for sku, productTerms := range priceList.Terms.OnDemand {
-		product, ok := priceList.Products[sku]
-		if !ok {
-			continue
-		}
-
-		// Filter by region
-		if prodRegion := product.Attributes["regionCode"]; prodRegion != "" && prodRegion != region {
-			continue
-		}
-		if prodLocation := product.Attributes["location"]; prodLocation != "" && !matchesRegion(prodLocation, region) {
-			continue
-		}
-
-		for _, term := range productTerms {
-			for _, dim := range term.PriceDimensions {
-				price := RawPrice{
-					SKU:           sku,
-					ServiceCode:   service,
-					ProductFamily: product.ProductFamily,
-					Region:        region,
-					Unit:          dim.Unit,
-					PricePerUnit:  dim.PricePerUnit.USD,
-					Currency:      "USD",
-					Attributes:    product.Attributes,
-				}
-
-				// Parse tiers
-				if dim.BeginRange != "0" && dim.BeginRange != "" {
-					if val, err := parseFloat(dim.BeginRange); err == nil {
-						price.TierStart = &val
-					}
-				}
-				if dim.EndRange != "Inf" && dim.EndRange != "" {
-					if val, err := parseFloat(dim.EndRange); err == nil {
-						price.TierEnd = &val
-					}
-				}
-
-				// Parse effective date
-				if term.EffectiveDate != "" {
-					if t, err := time.Parse("2006-01-02T15:04:05Z", term.EffectiveDate); err == nil {
-						price.EffectiveDate = &t
-					}
-				}
-
-				prices = append(prices, price)
-			}
-		}
+	skipped, err := decodePriceList(bytes.NewReader(data), service, region, func(p RawPrice) error {
+		prices = append(prices, p)
+		return nil
+	})
+	warnInvalidTiers(f.logger, region, service, skipped)
+	if err != nil {
+		return nil, err
 	}
-
 	return prices, nil
 }
 
@@ -354,6 +377,38 @@ func matchesRegion(location, region string) bool {
 
 		// Africa
 		"af-south-1": {"Africa (Cape Town)"},
+
+		// GovCloud and China
+		"us-gov-west-1":  {"AWS GovCloud (US-West)", "AWS GovCloud (US)"},
+		"us-gov-east-1":  {"AWS GovCloud (US-East)"},
+		"cn-north-1":     {"China (Beijing)"},
+		"cn-northwest-1": {"China (Ningxia)"},
+
+		// Local Zones
+		"us-east-1-atl-1": {"US East (Atlanta)"},
+		"us-east-1-bos-1": {"US East (Boston)"},
+		"us-east-1-chi-1": {"US East (Chicago)"},
+		"us-east-1-dfw-1": {"US East (Dallas)"},
+		"us-east-1-iah-1": {"US East (Houston)"},
+		"us-east-1-mci-1": {"US East (Kansas City 2)"},
+		"us-east-1-mia-1": {"US East (Miami)"},
+		"us-east-1-msp-1": {"US East (Minneapolis)"},
+		"us-east-1-nyc-1": {"US East (New York City)"},
+		"us-east-1-phl-1": {"US East (Philadelphia)"},
+		"us-west-2-den-1": {"US West (Denver)"},
+		"us-west-2-las-1": {"US West (Las Vegas)"},
+		"us-west-2-lax-1": {"US West (Los Angeles)"},
+		"us-west-2-pdx-1": {"US West (Portland)"},
+		"us-west-2-phx-2": {"US West (Phoenix)"},
+		"us-west-2-sea-1": {"US West (Seattle)"},
+
+		// Wavelength Zones
+		"us-east-1-wl1-bos-wlz-1":      {"US East (Verizon) - Boston"},
+		"us-east-1-wl1-nyc-wlz-1":      {"US East (Verizon) - New York"},
+		"us-west-2-wl1-las-wlz-1":      {"US West (Verizon) - Las Vegas"},
+		"us-west-2-wl1-sfo-wlz-1":      {"US West (Verizon) - San Francisco Bay Area"},
+		"eu-west-2-wl1-lon-wlz-1":      {"Europe (Vodafone) - London"},
+		"ap-northeast-1-wl1-nrt-wlz-1": {"Asia Pacific (KDDI) - Tokyo"},
 	}
 	
 	candidates, ok := mapping[region]
@@ -369,12 +424,6 @@ func matchesRegion(location, region string) bool {
 	return false
 }
 
-func parseFloat(s string) (float64, error) {
-	var f float64
-	_, err := fmt.Sscanf(s, "%f", &f)
-	return f, err
-}
-
 // AWSPricingAPINormalizer normalizes real AWS pricing data
 type AWSPricingAPINormalizer struct {
 	dimensionMapping map[string]string
@@ -413,10 +462,13 @@ func (n *AWSPricingAPINormalizer) Cloud() db.CloudProvider {
 }
 
 // Normalize converts raw AWS Pricing API data to normalized rates
-func (n *AWSPricingAPINormalizer) Normalize(raw []RawPrice) ([]NormalizedRate, error) {
+func (n *AWSPricingAPINormalizer) Normalize(ctx context.Context, raw []RawPrice) ([]NormalizedRate, error) {
 	var rates []NormalizedRate
 
-	for _, r := range raw {
+	for i, r := range raw {
+		if err := checkCancelled(ctx, i); err != nil {
+			return nil, err
+		}
 		// Skip zero/empty prices
 		if r.PricePerUnit == "" || r.PricePerUnit == "0" || r.PricePerUnit == "0.0000000000" {
 			continue
@@ -451,17 +503,14 @@ func (n *AWSPricingAPINormalizer) Normalize(raw []RawPrice) ([]NormalizedRate, e
 			Price:      price,
 			Currency:   r.Currency,
 			Confidence: 1.0, // Direct from AWS API = full confidence
+
+			EffectiveDate: r.EffectiveDate,
+			Labels:        sourceLabels(r),
 		}
 
 		// Handle tiers
-		if r.TierStart != nil {
-			d := decimal.NewFromFloat(*r.TierStart)
-			nr.TierMin = &d
-		}
-		if r.TierEnd != nil {
-			d := decimal.NewFromFloat(*r.TierEnd)
-			nr.TierMax = &d
-		}
+		nr.TierMin = r.TierStart
+		nr.TierMax = r.TierEnd
 
 		rates = append(rates, nr)
 	}
@@ -489,12 +538,54 @@ func (n *AWSPricingAPINormalizer) normalizeAttributes(raw map[string]string) map
 			continue
 		}
 
+		// Region-prefixed usage types share one key; keep the original too
+		if key == "usage_type" {
+			if canonical := canonicalUsageType(strings.TrimSpace(v)); canonical != strings.TrimSpace(v) {
+				result[usageTypeRawAttribute] = val
+				val = strings.ToLower(canonical)
+			}
+		}
+
 		result[key] = val
 	}
 
 	return result
 }
 
+// usageTypeRawAttribute holds the usagetype before its region prefix was stripped
+const usageTypeRawAttribute = "usage_type_raw"
+
+// awsUsageTypeRegionCodes are the billing codes AWS prefixes usagetype values
+// with; us-east-1 usage types are often unprefixed
+var awsUsageTypeRegionCodes = map[string]bool{
+	"USE1": true, "USE2": true, "USW1": true, "USW2": true, "UGE1": true, "UGW1": true,
+	"CAN1": true, "CAN2": true, "SAE1": true, "MXC1": true,
+	"EU": true, "EUW1": true, "EUW2": true, "EUW3": true, "EUC1": true, "EUC2": true,
+	"EUN1": true, "EUS1": true, "EUS2": true,
+	"APN1": true, "APN2": true, "APN3": true, "APS1": true, "APS2": true, "APS3": true,
+	"APS4": true, "APS5": true, "APS6": true, "APS7": true, "APE1": true,
+	"MES1": true, "MEC1": true, "ILC1": true, "AFS1": true,
+}
+
+// awsLocalZoneCode matches a local zone segment such as LAX1 in USW2-LAX1-...
+var awsLocalZoneCode = regexp.MustCompile(`^[A-Z]{3}[0-9]+$`)
+
+// canonicalUsageType strips the region prefix, and a local zone segment after
+// it, from an AWS usagetype: USE1-NatGateway-Hours and EU-NatGateway-Hours
+// both become NatGateway-Hours. A second region code is kept because it names
+// a transfer destination (USE1-EUC1-AWS-Out-Bytes becomes EUC1-AWS-Out-Bytes).
+func canonicalUsageType(usageType string) string {
+	parts := strings.SplitN(usageType, "-", 3)
+	if len(parts) < 2 || !awsUsageTypeRegionCodes[parts[0]] {
+		return usageType
+	}
+	rest := strings.TrimPrefix(usageType, parts[0]+"-")
+	if len(parts) == 3 && awsLocalZoneCode.MatchString(parts[1]) && !awsUsageTypeRegionCodes[parts[1]] {
+		rest = parts[2]
+	}
+	return rest
+}
+
 func (n *AWSPricingAPINormalizer) normalizeUnit(unit string) string {
 	mapping := map[string]string{
 		"Hrs":           "hours",
@@ -0,0 +1,242 @@
+// Package ingestion - AWS EC2 spot price fetcher
+// Spot prices come from the EC2 DescribeSpotPriceHistory API, which needs
+// signed requests. Ingest them under their own provider alias so they do
+// not replace the on-demand snapshot of the region.
+package ingestion
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"terraform-cost/db"
+	"terraform-cost/db/regions"
+)
+
+// SpotAlias is the provider alias spot snapshots are ingested under
+const SpotAlias = "spot"
+
+// spotProductOS maps DescribeSpotPriceHistory product descriptions to the
+// operatingSystem values of the price list, so spot and on-demand rate keys
+// share os values
+var spotProductOS = map[string]string{
+	"Linux/UNIX":                            "Linux",
+	"Linux/UNIX (Amazon VPC)":               "Linux",
+	"Windows":                               "Windows",
+	"Windows (Amazon VPC)":                  "Windows",
+	"Red Hat Enterprise Linux":              "RHEL",
+	"Red Hat Enterprise Linux (Amazon VPC)": "RHEL",
+	"SUSE Linux":                            "SUSE",
+	"SUSE Linux (Amazon VPC)":               "SUSE",
+}
+
+// AWSSpotFetcher fetches current EC2 spot prices per instance type,
+// availability zone and operating system
+type AWSSpotFetcher struct {
+	httpClient *http.Client
+	creds      *AWSCredentials
+	endpoint   string // overrides the regional EC2 endpoint (tests)
+	logger     *slog.Logger
+	now        func() time.Time
+	registry   *regions.Registry
+}
+
+// NewAWSSpotFetcher creates a spot fetcher signing with creds
+// (AWSCredentialsFromEnv when nil)
+func NewAWSSpotFetcher(creds *AWSCredentials) *AWSSpotFetcher {
+	if creds == nil {
+		creds = AWSCredentialsFromEnv()
+	}
+	return &AWSSpotFetcher{
+		httpClient: &http.Client{Timeout: 60 * time.Second},
+		creds:      creds,
+		now:        time.Now,
+		registry:   regions.NewRegistry(),
+	}
+}
+
+func (f *AWSSpotFetcher) Cloud() db.CloudProvider {
+	return db.AWS
+}
+
+// IsRealAPI implements RealAPIFetcher - THIS IS A REAL API
+func (f *AWSSpotFetcher) IsRealAPI() bool {
+	return true
+}
+
+// SupportedRegions returns the price list regions outside China, whose spot
+// prices are not in USD
+func (f *AWSSpotFetcher) SupportedRegions() []string {
+	var supported []string
+	for _, region := range NewAWSPricingAPIFetcher().SupportedRegions() {
+		if f.pricingSource(region) != "china" {
+			supported = append(supported, region)
+		}
+	}
+	return supported
+}
+
+// pricingSource returns the region's pricing source from the registry
+func (f *AWSSpotFetcher) pricingSource(region string) string {
+	if reg := f.registry.GetRegion(db.AWS, region); reg != nil {
+		return reg.PricingSource
+	}
+	return "api"
+}
+
+// SupportedServices returns the services spot prices are fetched for
+func (f *AWSSpotFetcher) SupportedServices() []string {
+	return []string{"AmazonEC2"}
+}
+
+// SetLogger sets the logger used for fetch progress
+func (f *AWSSpotFetcher) SetLogger(logger *slog.Logger) {
+	f.logger = logger
+}
+
+// SetTransport routes API calls through rt (see TransportConfig), keeping
+// the client's timeout
+func (f *AWSSpotFetcher) SetTransport(rt http.RoundTripper) {
+	client := *f.httpClient
+	client.Transport = rt
+	f.httpClient = &client
+}
+
+// spotPriceHistoryResponse is the DescribeSpotPriceHistory XML response
+type spotPriceHistoryResponse struct {
+	Items []struct {
+		InstanceType       string `xml:"instanceType"`
+		ProductDescription string `xml:"productDescription"`
+		SpotPrice          string `xml:"spotPrice"`
+		Timestamp          string `xml:"timestamp"`
+		AvailabilityZone   string `xml:"availabilityZone"`
+	} `xml:"spotPriceHistorySet>item"`
+	NextToken string `xml:"nextToken"`
+}
+
+// FetchRegion returns the current spot price of every instance type, zone
+// and operating system in the region. EffectiveDate is when the price was
+// last set, which for spot can be minutes before the fetch.
+func (f *AWSSpotFetcher) FetchRegion(ctx context.Context, region string) ([]RawPrice, error) {
+	if f.creds == nil {
+		return nil, fmt.Errorf("spot prices need AWS credentials (AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY)")
+	}
+	if source := f.pricingSource(region); source != "api" && source != "govcloud" {
+		return nil, fmt.Errorf("no AWS spot prices for pricing source %q (region %s)", source, region)
+	}
+
+	// A start time of now returns the price in effect now for each
+	// type, zone and product
+	params := map[string]string{
+		"Action":     "DescribeSpotPriceHistory",
+		"Version":    "2016-11-15",
+		"StartTime":  f.now().UTC().Format(time.RFC3339),
+		"MaxResults": "1000",
+	}
+
+	var pages []spotPriceHistoryResponse
+	for {
+		page, err := f.fetchPage(ctx, region, params)
+		if err != nil {
+			return nil, err
+		}
+		pages = append(pages, *page)
+		if page.NextToken == "" {
+			break
+		}
+		params["NextToken"] = page.NextToken
+	}
+
+	prices := spotRawPrices(region, pages)
+	if f.logger != nil {
+		f.logger.Info("fetched spot prices", "region", region, "prices", len(prices), "pages", len(pages))
+	}
+	return prices, nil
+}
+
+// fetchPage requests one signed page of spot price history
+func (f *AWSSpotFetcher) fetchPage(ctx context.Context, region string, params map[string]string) (*spotPriceHistoryResponse, error) {
+	endpoint := f.endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://ec2.%s.amazonaws.com", region)
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint+"/?"+sigV4Query(params), nil)
+	if err != nil {
+		return nil, err
+	}
+	signSigV4(req, f.creds, region, "ec2", f.now())
+
+	resp, err := f.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("spot price history request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spot price history: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("spot price history request failed: %d: %s", resp.StatusCode, body)
+	}
+
+	var page spotPriceHistoryResponse
+	if err := xml.Unmarshal(body, &page); err != nil {
+		return nil, fmt.Errorf("failed to parse spot price history: %w", err)
+	}
+	return &page, nil
+}
+
+// spotRawPrices keeps the latest price per instance type, zone and operating
+// system, tagged purchase_option=spot
+func spotRawPrices(region string, pages []spotPriceHistoryResponse) []RawPrice {
+	latest := make(map[string]int)
+	var prices []RawPrice
+	for _, page := range pages {
+		for _, item := range page.Items {
+			osName, ok := spotProductOS[item.ProductDescription]
+			if !ok {
+				continue
+			}
+			if _, err := strconv.ParseFloat(item.SpotPrice, 64); err != nil {
+				continue
+			}
+			ts, err := time.Parse(time.RFC3339, item.Timestamp)
+			if err != nil {
+				continue
+			}
+
+			price := RawPrice{
+				SKU:           fmt.Sprintf("spot:%s:%s:%s", item.AvailabilityZone, item.InstanceType, osName),
+				ServiceCode:   "AmazonEC2",
+				ProductFamily: "Compute Instance",
+				Region:        region,
+				Unit:          "Hrs",
+				PricePerUnit:  item.SpotPrice,
+				Currency:      "USD",
+				Attributes: map[string]string{
+					"instanceType":      item.InstanceType,
+					"operatingSystem":   osName,
+					"availability_zone": item.AvailabilityZone,
+					"purchase_option":   "spot",
+				},
+				EffectiveDate: &ts,
+			}
+
+			if i, seen := latest[price.SKU]; seen {
+				if ts.After(*prices[i].EffectiveDate) {
+					prices[i] = price
+				}
+				continue
+			}
+			latest[price.SKU] = len(prices)
+			prices = append(prices, price)
+		}
+	}
+	return prices
+}
@@ -0,0 +1,133 @@
+// Package ingestion - AWS spot price tests
+package ingestion
+
+import (
+	"context"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"terraform-cost/db"
+	"terraform-cost/db/memstore"
+)
+
+func TestAWSSpotFetcherNormalizesSpotHistory(t *testing.T) {
+	fixture, err := os.ReadFile("testdata/aws_spot/spot_price_history.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	const page2 = `<DescribeSpotPriceHistoryResponse><spotPriceHistorySet><item>
+		<instanceType>c5.xlarge</instanceType><productDescription>SUSE Linux</productDescription>
+		<spotPrice>0.071000</spotPrice><timestamp>2024-05-01T11:00:00.000Z</timestamp>
+		<availabilityZone>us-east-1c</availabilityZone></item></spotPriceHistorySet><nextToken/></DescribeSpotPriceHistoryResponse>`
+
+	var pages int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pages++
+		q := r.URL.Query()
+		if q.Get("Action") != "DescribeSpotPriceHistory" || q.Get("StartTime") != "2024-05-01T12:00:00Z" {
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+		if auth := r.Header.Get("Authorization"); !strings.Contains(auth, "Credential=AKIDEXAMPLE/20240501/us-east-1/ec2/aws4_request") {
+			t.Errorf("expected a SigV4 signed request, got %q", auth)
+		}
+		if q.Get("NextToken") == "page-2" {
+			w.Write([]byte(page2))
+			return
+		}
+		w.Write(fixture)
+	}))
+	defer server.Close()
+
+	fetcher := NewAWSSpotFetcher(&AWSCredentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "secret"})
+	fetcher.endpoint = server.URL
+	fetcher.now = func() time.Time { return time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC) }
+
+	raw, err := fetcher.FetchRegion(context.Background(), "us-east-1")
+	if err != nil {
+		t.Fatalf("fetch failed: %v", err)
+	}
+	if pages != 2 {
+		t.Errorf("expected both pages to be fetched, got %d", pages)
+	}
+
+	rates, err := NewAWSPricingAPINormalizer().Normalize(context.Background(), raw)
+	if err != nil {
+		t.Fatalf("normalize failed: %v", err)
+	}
+	// Latest us-east-1a and us-east-1b Linux, Windows and page 2 SUSE; the
+	// unknown product description is skipped
+	want := map[string]string{
+		"us-east-1a/linux/m5.large":   "0.0371",
+		"us-east-1b/linux/m5.large":   "0.0392",
+		"us-east-1a/windows/m5.large": "0.1291",
+		"us-east-1c/suse/c5.xlarge":   "0.071",
+	}
+	if len(rates) != len(want) {
+		t.Fatalf("expected %d rates, got %+v", len(want), rates)
+	}
+	for _, r := range rates {
+		a := r.RateKey.Attributes
+		key := a["availability_zone"] + "/" + a["os"] + "/" + a["instance_type"]
+		price, ok := want[key]
+		if !ok || r.Price.String() != price {
+			t.Errorf("unexpected rate %s = %s", key, r.Price)
+		}
+		if a["purchase_option"] != "spot" || r.Unit != "hours" || r.RateKey.Service != "AmazonEC2" || r.RateKey.ProductFamily != "Compute Instance" {
+			t.Errorf("expected an hourly EC2 spot rate, got %+v", r)
+		}
+		if r.EffectiveDate == nil || r.EffectiveDate.After(fetcher.now()) {
+			t.Errorf("expected the spot price timestamp as effective date, got %v", r.EffectiveDate)
+		}
+	}
+
+	// Spot snapshots live under their own alias next to on-demand pricing
+	store := memstore.NewMemoryStore()
+	config := DefaultLifecycleConfig()
+	config.Provider = db.AWS
+	config.Region = "us-east-1"
+	config.Alias = SpotAlias
+	config.BackupDir = t.TempDir()
+	result, err := NewLifecycle(fetcher, NewAWSPricingAPINormalizer(), store).Execute(context.Background(), config)
+	if err != nil || !result.Success {
+		t.Fatalf("spot ingestion failed: %v %+v", err, result)
+	}
+	res, err := db.NewResolver(store).Resolve(context.Background(), db.ResolveRequest{
+		Cloud: db.AWS, Service: "AmazonEC2", ProductFamily: "Compute Instance", Region: "us-east-1", Alias: SpotAlias, Unit: "hours",
+		Attributes: map[string]string{"instance_type": "m5.large", "os": "linux", "availability_zone": "us-east-1b", "purchase_option": "spot"},
+	})
+	if err != nil || res.Rate == nil || res.Rate.Price.String() != "0.0392" {
+		t.Errorf("expected the resolver to serve the spot price, got %+v (err %v)", res, err)
+	}
+}
+
+func TestAWSSpotFetcherNeedsCredentials(t *testing.T) {
+	fetcher := &AWSSpotFetcher{}
+	if _, err := fetcher.FetchRegion(context.Background(), "us-east-1"); err == nil || !strings.Contains(err.Error(), "credentials") {
+		t.Errorf("expected a credentials error, got %v", err)
+	}
+}
+
+// AWS's documented IAM ListUsers signing example
+func TestSignSigV4MatchesAWSExample(t *testing.T) {
+	const secret = "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"
+	if key := hex.EncodeToString(sigV4SigningKey(secret, "20150830", "us-east-1", "iam")); key != "c4afb1cc5771d871763a393e44b703571b55cc28424d1a5e86da6ed3c154a4b9" {
+		t.Errorf("unexpected signing key %s", key)
+	}
+
+	req, _ := http.NewRequest("GET", "https://iam.amazonaws.com/?"+sigV4Query(map[string]string{"Version": "2010-05-08", "Action": "ListUsers"}), nil)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	signSigV4(req, &AWSCredentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: secret}, "us-east-1", "iam", time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/iam/aws4_request, SignedHeaders=content-type;host;x-amz-date, Signature=5d672d79c15b13162d9279b0855cfba6789a8edb4c82c400e06b5924a6f2b5d7"
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Errorf("Authorization = %s\nwant %s", got, want)
+	}
+	if q := sigV4Query(map[string]string{"ProductDescription.1": "Red Hat Enterprise Linux", "A": "x/y~"}); q != "A=x%2Fy~&ProductDescription.1=Red%20Hat%20Enterprise%20Linux" {
+		t.Errorf("unexpected canonical query %s", q)
+	}
+}
@@ -0,0 +1,302 @@
+// Package ingestion - Incremental decoding of AWS bulk price lists
+package ingestion
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"terraform-cost/db"
+
+	"github.com/shopspring/decimal"
+)
+
+// StreamRegion fetches a region's prices service by service, decoding each
+// price list as it downloads and passing every price to emit. Only the
+// region's products are held in memory; terms are never buffered. A service
+// that fails part-way may already have emitted some of its prices.
+func (f *AWSPricingAPIFetcher) StreamRegion(ctx context.Context, region string, emit func(RawPrice) error) error {
+	failures := &serviceFailures{provider: db.AWS, region: region, expected: len(f.services), threshold: f.breakerThreshold}
+	for i, service := range f.services {
+		count := 0
+		serviceCtx, cancel := f.serviceTimeout.context(ctx, len(f.services)-i)
+		skipped, err := f.streamServicePricing(serviceCtx, service, region, func(p RawPrice) error {
+			count++
+			return emit(p)
+		})
+		cancel()
+		warnInvalidTiers(f.logger, region, service, skipped)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			err = serviceTimedOut(ctx, serviceCtx, err)
+			loggerOrDefault(f.logger).Warn("failed to stream service pricing", "provider", "aws", "region", region, "service", service, "error", err)
+			failures.add(service, err)
+			if open := failures.open(i + 1); open != nil {
+				return open
+			}
+			continue
+		}
+		failures.succeeded()
+		loggerOrDefault(f.logger).Debug("streamed service pricing", "provider", "aws", "region", region, "service", service, "rate_count", count)
+	}
+	return failures.err()
+}
+
+// streamServicePricing decodes a service's regional price list straight from
+// the response body, returning how many price dimensions had invalid tiers
+func (f *AWSPricingAPIFetcher) streamServicePricing(ctx context.Context, service, region string, emit func(RawPrice) error) (int, error) {
+	url, err := f.regionPriceListURL(ctx, service, region)
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := f.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("region pricing request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("region pricing not found: %d", resp.StatusCode)
+	}
+	return decodePriceList(resp.Body, service, region, emit)
+}
+
+// decodePriceList walks an AWS price list with json.Decoder tokens, keeping
+// the region's products and emitting a RawPrice per on-demand price dimension.
+// Reserved terms and other sections are skipped without being decoded. AWS
+// publishes products before terms; if terms come first they are buffered.
+// A price dimension whose tier bounds are not valid decimals is skipped and
+// counted in the returned total rather than emitted with a broken tier.
+func decodePriceList(r io.Reader, service, region string, emit func(RawPrice) error) (int, error) {
+	dec := json.NewDecoder(r)
+	if err := expectDelim(dec, '{'); err != nil {
+		return 0, err
+	}
+
+	products := make(map[string]AWSProduct)
+	seenProducts := false
+	var pending map[string]map[string]AWSTerm
+	skipped := 0
+
+	emitTerms := func(sku string, terms map[string]AWSTerm) error {
+		product, ok := products[sku]
+		if !ok {
+			return nil
+		}
+		for _, term := range terms {
+			for _, dim := range term.PriceDimensions {
+				price, err := awsRawPrice(sku, service, region, product, term, dim)
+				if err != nil {
+					skipped++
+					continue
+				}
+				if err := emit(price); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	for dec.More() {
+		key, err := stringToken(dec)
+		if err != nil {
+			return skipped, err
+		}
+
+		switch key {
+		case "products":
+			err = decodeObject(dec, func(sku string) error {
+				var product AWSProduct
+				if err := dec.Decode(&product); err != nil {
+					return fmt.Errorf("failed to parse product %s: %w", sku, err)
+				}
+				if productInRegion(product, region) {
+					products[sku] = product
+				}
+				return nil
+			})
+			seenProducts = true
+		case "terms":
+			err = decodeObject(dec, func(termType string) error {
+				if termType != "OnDemand" {
+					return skipValue(dec)
+				}
+				return decodeObject(dec, func(sku string) error {
+					var terms map[string]AWSTerm
+					if err := dec.Decode(&terms); err != nil {
+						return fmt.Errorf("failed to parse terms for %s: %w", sku, err)
+					}
+					if !seenProducts {
+						if pending == nil {
+							pending = make(map[string]map[string]AWSTerm)
+						}
+						pending[sku] = terms
+						return nil
+					}
+					return emitTerms(sku, terms)
+				})
+			})
+		default:
+			err = skipValue(dec)
+		}
+		if err != nil {
+			return skipped, fmt.Errorf("failed to parse price list: %w", err)
+		}
+	}
+	if err := expectDelim(dec, '}'); err != nil {
+		return skipped, err
+	}
+
+	for sku, terms := range pending {
+		if err := emitTerms(sku, terms); err != nil {
+			return skipped, err
+		}
+	}
+	return skipped, nil
+}
+
+// productInRegion applies the price list's region filters to a product
+func productInRegion(product AWSProduct, region string) bool {
+	if prodRegion := product.Attributes["regionCode"]; prodRegion != "" && prodRegion != region {
+		return false
+	}
+	if prodLocation := product.Attributes["location"]; prodLocation != "" && !matchesRegion(prodLocation, region) {
+		return false
+	}
+	return true
+}
+
+// awsRawPrice builds the RawPrice for one on-demand price dimension. It fails
+// when beginRange or endRange is not a decimal.
+func awsRawPrice(sku, service, region string, product AWSProduct, term AWSTerm, dim AWSPriceDimension) (RawPrice, error) {
+	price := RawPrice{
+		SKU:           sku,
+		ServiceCode:   service,
+		ProductFamily: product.ProductFamily,
+		Region:        region,
+		Unit:          dim.Unit,
+		PricePerUnit:  dim.PricePerUnit.USD,
+		Currency:      "USD",
+		Attributes:    product.Attributes,
+		RateCode:      dim.RateCode,
+	}
+	if price.PricePerUnit == "" && dim.PricePerUnit.CNY != "" {
+		price.PricePerUnit = dim.PricePerUnit.CNY
+		price.Currency = "CNY"
+	}
+
+	// Parse tiers; a start of 0 and an end of Inf are unbounded
+	var err error
+	if price.TierStart, err = parseTierBound(dim.BeginRange, "0"); err != nil {
+		return RawPrice{}, fmt.Errorf("sku %s: invalid beginRange: %w", sku, err)
+	}
+	if price.TierEnd, err = parseTierBound(dim.EndRange, "Inf"); err != nil {
+		return RawPrice{}, fmt.Errorf("sku %s: invalid endRange: %w", sku, err)
+	}
+
+	// Parse effective date
+	if term.EffectiveDate != "" {
+		if t, err := time.Parse("2006-01-02T15:04:05Z", term.EffectiveDate); err == nil {
+			price.EffectiveDate = &t
+		}
+	}
+	return price, nil
+}
+
+// parseTierBound parses a tier bound exactly; "" and unbounded yield nil
+func parseTierBound(s, unbounded string) (*decimal.Decimal, error) {
+	if s == "" || s == unbounded {
+		return nil, nil
+	}
+	d, err := decimal.NewFromString(s)
+	if err != nil {
+		return nil, err
+	}
+	if d.IsNegative() {
+		return nil, fmt.Errorf("negative bound %s", s)
+	}
+	return &d, nil
+}
+
+// warnInvalidTiers logs the price dimensions a service's price list lost to
+// invalid tier bounds
+func warnInvalidTiers(logger *slog.Logger, region, service string, skipped int) {
+	if skipped > 0 {
+		loggerOrDefault(logger).Warn("skipped prices with invalid tier bounds", "provider", "aws", "region", region, "service", service, "skipped", skipped)
+	}
+}
+
+// decodeObject reads a JSON object, calling member for each key with the
+// decoder positioned at its value; member must consume the value
+func decodeObject(dec *json.Decoder, member func(key string) error) error {
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+	for dec.More() {
+		key, err := stringToken(dec)
+		if err != nil {
+			return err
+		}
+		if err := member(key); err != nil {
+			return err
+		}
+	}
+	return expectDelim(dec, '}')
+}
+
+// skipValue consumes the next value token by token, without decoding it
+func skipValue(dec *json.Decoder) error {
+	depth := 0
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+// expectDelim consumes the next token, which must be the given delimiter
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return fmt.Errorf("failed to parse price list: %w", err)
+	}
+	if tok != want {
+		return fmt.Errorf("failed to parse price list: expected %q, got %v", want, tok)
+	}
+	return nil
+}
+
+// stringToken consumes the next token, which must be an object key
+func stringToken(dec *json.Decoder) (string, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return "", err
+	}
+	key, ok := tok.(string)
+	if !ok {
+		return "", fmt.Errorf("expected object key, got %v", tok)
+	}
+	return key, nil
+}
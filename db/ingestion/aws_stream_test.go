@@ -0,0 +1,309 @@
+// Package ingestion - AWS streaming price list decoder tests
+package ingestion
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"terraform-cost/db"
+	"terraform-cost/db/memstore"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestDecodePriceList(t *testing.T) {
+	product := `{"sku": "%s", "productFamily": "Compute Instance", "attributes": {"regionCode": "%s", "instanceType": "m5.large"}}`
+	onDemand := `{"%[1]s.T1": {"sku": "%[1]s", "effectiveDate": "2024-01-01T00:00:00Z", "priceDimensions": {"%[1]s.T1.D1": {"unit": "Hrs", "pricePerUnit": {"USD": "%[2]s"}}}}}`
+	products := `"products": {"A": ` + fmt.Sprintf(product, "A", "us-east-1") + `, "B": ` + fmt.Sprintf(product, "B", "us-west-2") + `}`
+	terms := `"terms": {"Reserved": {"A": {"A.R1": {"priceDimensions": {"A.R1.D1": {"unit": "Quantity", "pricePerUnit": {"USD": "500"}}}}}},
+		"OnDemand": {"A": ` + fmt.Sprintf(onDemand, "A", "0.096") + `, "B": ` + fmt.Sprintf(onDemand, "B", "0.1") + `}}`
+
+	// AWS puts products first, but either order decodes the same
+	for name, doc := range map[string]string{
+		"products first": `{"formatVersion": "v1.0", ` + products + `, ` + terms + `, "attributesList": {}}`,
+		"terms first":    `{"formatVersion": "v1.0", ` + terms + `, ` + products + `}`,
+	} {
+		var prices []RawPrice
+		_, err := decodePriceList(strings.NewReader(doc), "AmazonEC2", "us-east-1", func(p RawPrice) error {
+			prices = append(prices, p)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("%s: decode failed: %v", name, err)
+		}
+		if len(prices) != 1 || prices[0].SKU != "A" || prices[0].PricePerUnit != "0.096" || prices[0].Unit != "Hrs" || prices[0].EffectiveDate == nil {
+			t.Errorf("%s: expected only A's on-demand price, got %+v", name, prices)
+		}
+	}
+
+	if _, err := decodePriceList(strings.NewReader(`{"products": [`), "AmazonEC2", "us-east-1", func(RawPrice) error { return nil }); err == nil {
+		t.Error("expected a malformed price list to fail")
+	}
+}
+
+func TestDecodePriceListTierBounds(t *testing.T) {
+	dimension := `"%[1]s": {"unit": "GB-Mo", "beginRange": "%[2]s", "endRange": "%[3]s", "pricePerUnit": {"USD": "0.023"}}`
+	dims := strings.Join([]string{
+		fmt.Sprintf(dimension, "first", "0", "51200"),
+		fmt.Sprintf(dimension, "middle", "51200", "512000.5"),
+		fmt.Sprintf(dimension, "last", "512000.5", "Inf"),
+		fmt.Sprintf(dimension, "garbage", "1024abc", "Inf"),
+		fmt.Sprintf(dimension, "negative", "-5", "10"),
+		fmt.Sprintf(dimension, "infinite-start", "Inf", "Inf"),
+	}, ", ")
+	doc := `{"products": {"S": {"sku": "S", "productFamily": "Storage", "attributes": {"regionCode": "us-east-1"}}},
+		"terms": {"OnDemand": {"S": {"S.T1": {"sku": "S", "priceDimensions": {` + dims + `}}}}}}`
+
+	tiers := make(map[string][2]string)
+	skipped, err := decodePriceList(strings.NewReader(doc), "AmazonS3", "us-east-1", func(p RawPrice) error {
+		bound := func(d *decimal.Decimal) string {
+			if d == nil {
+				return "nil"
+			}
+			return d.String()
+		}
+		tiers[bound(p.TierStart)] = [2]string{bound(p.TierStart), bound(p.TierEnd)}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if skipped != 3 {
+		t.Errorf("expected the malformed, negative and infinite starts to be skipped, skipped %d", skipped)
+	}
+	want := map[string][2]string{
+		"nil":      {"nil", "51200"},
+		"51200":    {"51200", "512000.5"},
+		"512000.5": {"512000.5", "nil"},
+	}
+	if !reflect.DeepEqual(tiers, want) {
+		t.Errorf("tiers = %v, want %v", tiers, want)
+	}
+}
+
+// writeSyntheticPriceList writes an n-product price list shaped like AWS's,
+// with reserved terms outweighing on-demand ones, and returns its size
+func writeSyntheticPriceList(w io.Writer, n int) (int64, error) {
+	cw := &countingWriter{w: w}
+	fmt.Fprint(cw, `{"formatVersion": "v1.0", "publicationDate": "2024-01-01T00:00:00Z", "products": {`)
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			fmt.Fprint(cw, ",")
+		}
+		fmt.Fprintf(cw, `"SKU%[1]d": {"sku": "SKU%[1]d", "productFamily": "Compute Instance", "attributes": {"regionCode": "us-east-1", "location": "US East (N. Virginia)", "instanceType": "m5.%[1]dxlarge"}}`, i)
+	}
+	fmt.Fprint(cw, `}, "terms": {"OnDemand": {`)
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			fmt.Fprint(cw, ",")
+		}
+		fmt.Fprintf(cw, `"SKU%[1]d": {"SKU%[1]d.JRTCKXETXF": {"sku": "SKU%[1]d", "effectiveDate": "2024-01-01T00:00:00Z", "priceDimensions": {"SKU%[1]d.JRTCKXETXF.6YS6EN2CT7": {"unit": "Hrs", "description": "$0.096 per On Demand Linux m5.large Instance Hour", "pricePerUnit": {"USD": "0.0960000000"}}}}}`, i)
+	}
+	fmt.Fprint(cw, `}, "Reserved": {`)
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			fmt.Fprint(cw, ",")
+		}
+		fmt.Fprintf(cw, `"SKU%d": {`, i)
+		for j := 0; j < 8; j++ {
+			if j > 0 {
+				fmt.Fprint(cw, ",")
+			}
+			fmt.Fprintf(cw, `"SKU%[1]d.R%[2]d": {"sku": "SKU%[1]d", "effectiveDate": "2024-01-01T00:00:00Z", "priceDimensions": {"SKU%[1]d.R%[2]d.2TG2D8R56U": {"unit": "Quantity", "description": "Upfront Fee", "pricePerUnit": {"USD": "1234"}}}, "termAttributes": {"LeaseContractLength": "1yr", "PurchaseOption": "All Upfront"}}`, i, j)
+		}
+		fmt.Fprint(cw, "}")
+	}
+	fmt.Fprint(cw, "}}}")
+	return cw.n, cw.err
+}
+
+// countingWriter counts bytes written and keeps the first error
+type countingWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	c.err = err
+	return n, err
+}
+
+func heapInUse() uint64 {
+	runtime.GC()
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return m.HeapAlloc
+}
+
+func TestDecodePriceListBoundedMemory(t *testing.T) {
+	if testing.Short() {
+		t.Skip("generates a large synthetic price list")
+	}
+	const products = 20000
+
+	// The document is generated on the fly and never exists in memory as a whole
+	pr, pw := io.Pipe()
+	sizes := make(chan int64, 1)
+	go func() {
+		n, err := writeSyntheticPriceList(pw, products)
+		pw.CloseWithError(err)
+		sizes <- n
+	}()
+
+	baseline := heapInUse()
+	var peak uint64
+	emitted := 0
+	_, err := decodePriceList(pr, "AmazonEC2", "us-east-1", func(p RawPrice) error {
+		emitted++
+		if emitted%2000 == 0 {
+			if used := heapInUse(); used > peak {
+				peak = used
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	size := <-sizes
+
+	if emitted != products {
+		t.Errorf("expected %d on-demand prices, got %d", products, emitted)
+	}
+	growth := int64(peak) - int64(baseline)
+	t.Logf("document %d bytes, peak heap growth %d bytes", size, growth)
+	if growth > size/3 {
+		t.Errorf("heap grew by %d bytes decoding a %d byte price list; expected well under the document size", growth, size)
+	}
+}
+
+// streamOnlyFetcher streams prices and fails if the whole region is requested
+type streamOnlyFetcher struct {
+	staticFetcher
+}
+
+func (f *streamOnlyFetcher) FetchRegion(ctx context.Context, region string) ([]RawPrice, error) {
+	return nil, fmt.Errorf("FetchRegion should not be used by the streaming lifecycle")
+}
+
+func (f *streamOnlyFetcher) StreamRegion(ctx context.Context, region string, emit func(RawPrice) error) error {
+	for _, p := range f.prices {
+		if err := emit(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestStreamingLifecycleUsesStreamRegion(t *testing.T) {
+	streamCfg := DefaultStreamingConfig()
+	streamCfg.WorkDir = t.TempDir()
+	streamCfg.BatchSize = 3
+	fetcher := &streamOnlyFetcher{staticFetcher{cloud: db.AWS, prices: testRawPrices("us-east-1", 7)}}
+
+	config := DefaultLifecycleConfig()
+	config.Provider = db.AWS
+	config.Region = "us-east-1"
+	config.BackupDir = t.TempDir()
+	config.DryRun = true
+
+	result, _ := NewStreamingLifecycle(fetcher, &passthroughNormalizer{cloud: db.AWS}, &emptyStore{}, streamCfg).Execute(context.Background(), config)
+	if !result.Success || result.RawCount != 7 || result.NormalizedCount != 7 {
+		t.Errorf("expected 7 streamed prices in 3 batches, got %+v", result)
+	}
+}
+
+// estimatingFetcher streams prices and reports its catalog size up front
+type estimatingFetcher struct {
+	streamOnlyFetcher
+	estimate int
+}
+
+func (f *estimatingFetcher) EstimatedRateCount(region string) int {
+	return f.estimate
+}
+
+func TestStreamingLifecycleReportsEstimatedTotal(t *testing.T) {
+	streamCfg := DefaultStreamingConfig()
+	streamCfg.WorkDir = t.TempDir()
+	streamCfg.BatchSize = 3
+	fetcher := &estimatingFetcher{streamOnlyFetcher{staticFetcher{cloud: db.AWS, prices: testRawPrices("us-east-1", 7)}}, 8}
+
+	config := DefaultLifecycleConfig()
+	config.Provider = db.AWS
+	config.Region = "us-east-1"
+	config.BackupDir = t.TempDir()
+	config.DryRun = true
+
+	var out bytes.Buffer
+	lifecycle := NewStreamingLifecycle(fetcher, &passthroughNormalizer{cloud: db.AWS}, &emptyStore{}, streamCfg).
+		WithLogger(slog.New(NewConsoleHandler(&out)))
+	if result, _ := lifecycle.Execute(context.Background(), config); !result.Success {
+		t.Fatalf("ingestion failed: %+v", result)
+	}
+	for _, want := range []string{"~8 prices, ~3 batches", "3/8 prices (estimated)", "6/8 prices (estimated)"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected %q in the progress output:\n%s", want, out.String())
+		}
+	}
+
+	if n := estimatedRateCount(&streamOnlyFetcher{}, "us-east-1"); n != -1 {
+		t.Errorf("expected -1 from a fetcher without an estimate, got %d", n)
+	}
+}
+
+func TestAWSLabelsCarrySKUToStorage(t *testing.T) {
+	doc := `{"products": {"JRTCKXETXF": {"sku": "JRTCKXETXF", "productFamily": "Compute Instance",
+		"attributes": {"regionCode": "us-east-1", "instanceType": "m5.large", "operatingSystem": "Linux"}}},
+		"terms": {"OnDemand": {"JRTCKXETXF": {"JRTCKXETXF.JRTCKXETXF": {"sku": "JRTCKXETXF", "priceDimensions": {
+			"JRTCKXETXF.JRTCKXETXF.6YS6EN2CT7": {"rateCode": "JRTCKXETXF.JRTCKXETXF.6YS6EN2CT7", "unit": "Hrs", "pricePerUnit": {"USD": "0.096"}}}}}}}}`
+
+	var raw []RawPrice
+	if _, err := decodePriceList(strings.NewReader(doc), "AmazonEC2", "us-east-1", func(p RawPrice) error {
+		raw = append(raw, p)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	store := memstore.NewMemoryStore()
+	config := DefaultLifecycleConfig()
+	config.Provider = db.AWS
+	config.Region = "us-east-1"
+	config.Environment = "development"
+	config.BackupDir = t.TempDir()
+	result, err := NewLifecycle(&staticFetcher{cloud: db.AWS, prices: raw}, NewAWSPricingAPINormalizer(), store).Execute(context.Background(), config)
+	if err != nil || !result.Success {
+		t.Fatalf("ingestion failed: %v %+v", err, result)
+	}
+
+	stored, _ := store.GetRatesBySnapshot(context.Background(), *result.SnapshotID)
+	if len(stored) != 1 {
+		t.Fatalf("expected 1 stored rate, got %d", len(stored))
+	}
+	want := map[string]string{"sku": "JRTCKXETXF", "rate_code": "JRTCKXETXF.JRTCKXETXF.6YS6EN2CT7"}
+	if !reflect.DeepEqual(stored[0].Rate.Labels, want) {
+		t.Errorf("labels = %v, want %v", stored[0].Rate.Labels, want)
+	}
+	if _, ok := stored[0].RateKey.Attributes["sku"]; ok {
+		t.Errorf("labels must not leak into the match attributes: %v", stored[0].RateKey.Attributes)
+	}
+
+	backup, err := NewBackupManager().ReadBackup(result.BackupPath)
+	if err != nil || !reflect.DeepEqual(backup.Rates[0].Labels, want) {
+		t.Errorf("expected the backup to keep the labels, got %v (%v)", backup.Rates[0].Labels, err)
+	}
+}
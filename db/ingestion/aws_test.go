@@ -0,0 +1,263 @@
+// Package ingestion - AWS pricing tests
+package ingestion
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"terraform-cost/db"
+	"terraform-cost/db/memstore"
+	"terraform-cost/db/regions"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestAWSStubTieredEgress(t *testing.T) {
+	ctx := context.Background()
+	store := memstore.NewMemoryStore()
+
+	config := DefaultLifecycleConfig()
+	config.Provider = db.AWS
+	config.Region = "us-east-1"
+	config.Environment = "development"
+	config.BackupDir = t.TempDir()
+
+	result, err := NewLifecycle(NewAWSFetcher(), NewAWSNormalizer(), store).Execute(ctx, config)
+	if err != nil || !result.Success {
+		t.Fatalf("ingestion failed: %v %+v", err, result)
+	}
+
+	resolver := db.NewResolver(store)
+	tests := []struct {
+		service string
+		attrs   map[string]string
+		tiers   int
+		usage   string
+		want    string
+	}{
+		// 60TB: 10TB @ 0.085 + 40TB @ 0.080 + 10TB @ 0.060
+		{"AmazonCloudFront", map[string]string{"transfertype": "cloudfront outbound"}, 7, "61440", "4761.6"},
+		// 200TB: 10TB @ 0.09 + 40TB @ 0.085 + 100TB @ 0.07 + 50TB @ 0.05
+		{"AWSDataTransfer", map[string]string{"transfertype": "aws outbound"}, 4, "204800", "14131.2"},
+	}
+	for _, tt := range tests {
+		tiers, err := resolver.ResolveTiered(ctx, db.ResolveRequest{
+			Cloud: db.AWS, Service: tt.service, ProductFamily: "Data Transfer", Region: "us-east-1",
+			Attributes: tt.attrs, Unit: "GB",
+		})
+		if err != nil {
+			t.Fatalf("%s: resolve failed: %v", tt.service, err)
+		}
+		if len(tiers) != tt.tiers {
+			t.Fatalf("%s: expected %d tiers, got %d", tt.service, tt.tiers, len(tiers))
+		}
+		if !tiers[0].Min.IsZero() || tiers[len(tiers)-1].Max != nil {
+			t.Errorf("%s: expected tiers from 0 to unlimited, got %+v", tt.service, tiers)
+		}
+
+		cost, _ := db.CalculateTieredCost(decimal.RequireFromString(tt.usage), tiers)
+		if !cost.Equal(decimal.RequireFromString(tt.want)) {
+			t.Errorf("%s: cost for %s GB = %s, want %s", tt.service, tt.usage, cost, tt.want)
+		}
+	}
+}
+
+func TestAWSStubCloudFrontRequestsPricedPerRequest(t *testing.T) {
+	prices, err := NewAWSFetcher().FetchRegion(context.Background(), "us-east-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range prices {
+		if p.SKU != "cloudfront-https-requests" {
+			continue
+		}
+		// $0.01 per 10K requests, stored in the per-request Requests unit
+		if p.Unit != "Requests" || !decimal.RequireFromString(p.PricePerUnit).Equal(decimal.RequireFromString("0.000001")) {
+			t.Errorf("expected 0.000001 per request, got %s per %s", p.PricePerUnit, p.Unit)
+		}
+		return
+	}
+	t.Fatal("expected a CloudFront HTTPS request price")
+}
+
+func TestCanonicalUsageType(t *testing.T) {
+	tests := []struct {
+		usageType, want string
+	}{
+		{"USE1-NatGateway-Hours", "NatGateway-Hours"},
+		{"USW2-NatGateway-Hours", "NatGateway-Hours"},
+		{"EU-NatGateway-Hours", "NatGateway-Hours"},
+		{"APS3-NatGateway-Hours", "NatGateway-Hours"},
+		{"NatGateway-Hours", "NatGateway-Hours"},
+		{"USW2-LAX1-BoxUsage:t3.micro", "BoxUsage:t3.micro"},
+		{"USE1-EUC1-AWS-Out-Bytes", "EUC1-AWS-Out-Bytes"},
+		{"DDB-WriteUnits", "DDB-WriteUnits"},
+		{"BoxUsage:t3.micro", "BoxUsage:t3.micro"},
+	}
+	for _, tt := range tests {
+		if got := canonicalUsageType(tt.usageType); got != tt.want {
+			t.Errorf("canonicalUsageType(%q) = %q, want %q", tt.usageType, got, tt.want)
+		}
+	}
+}
+
+func TestAWSNormalizerStripsUsageTypePrefix(t *testing.T) {
+	normalizer := NewAWSPricingAPINormalizer()
+	detector := NewEquivalenceDetector(db.AWS)
+
+	regions := map[string]string{"us-east-1": "USE1", "us-west-2": "USW2", "eu-west-1": "EU"}
+	for region, code := range regions {
+		rates, err := normalizer.Normalize(context.Background(), []RawPrice{{
+			SKU:           "NAT-" + code,
+			ServiceCode:   "AmazonEC2",
+			ProductFamily: "NAT Gateway",
+			Region:        region,
+			Unit:          "Hrs",
+			PricePerUnit:  "0.045",
+			Currency:      "USD",
+			Attributes:    map[string]string{"usagetype": code + "-NatGateway-Hours"},
+		}})
+		if err != nil || len(rates) != 1 {
+			t.Fatalf("%s: normalize returned %d rates (err %v)", region, len(rates), err)
+		}
+
+		attrs := rates[0].RateKey.Attributes
+		if attrs["usage_type"] != "natgateway-hours" {
+			t.Errorf("%s: usage_type = %q, want natgateway-hours", region, attrs["usage_type"])
+		}
+		if want := strings.ToLower(code) + "-natgateway-hours"; attrs[usageTypeRawAttribute] != want {
+			t.Errorf("%s: %s = %q, want %q", region, usageTypeRawAttribute, attrs[usageTypeRawAttribute], want)
+		}
+		detector.AddRegionRates(region, rates)
+	}
+
+	groups := detector.DetectEquivalence()
+	if len(groups) != 1 || len(groups[0].Aliases) != 2 {
+		t.Errorf("expected all regions to be equivalent, got %+v", groups)
+	}
+
+	// Unprefixed usage types carry no raw copy
+	rates, _ := normalizer.Normalize(context.Background(), []RawPrice{{
+		ServiceCode: "AmazonEC2", Region: "us-east-1", Unit: "Hrs", PricePerUnit: "0.0104", Currency: "USD",
+		Attributes: map[string]string{"usagetype": "BoxUsage:t3.micro"},
+	}})
+	if _, ok := rates[0].RateKey.Attributes[usageTypeRawAttribute]; ok {
+		t.Errorf("expected no %s for an unprefixed usage type, got %v", usageTypeRawAttribute, rates[0].RateKey.Attributes)
+	}
+}
+
+func TestAWSPricingEndpointBySource(t *testing.T) {
+	fetcher := NewAWSPricingAPIFetcher()
+
+	tests := []struct {
+		region, baseURL, partition string
+	}{
+		{"us-east-1", fetcher.baseURL, "aws"},
+		{"us-gov-west-1", fetcher.baseURL, "aws"},
+		{"cn-north-1", fetcher.chinaBaseURL, "cn"},
+		{"cn-northwest-1", fetcher.chinaBaseURL, "cn"},
+	}
+	for _, tt := range tests {
+		baseURL, partition, err := fetcher.pricingEndpoint(tt.region)
+		if err != nil || baseURL != tt.baseURL || partition != tt.partition {
+			t.Errorf("pricingEndpoint(%s) = %s, %s, %v; want %s, %s", tt.region, baseURL, partition, err, tt.baseURL, tt.partition)
+		}
+	}
+	if fetcher.chinaBaseURL == fetcher.baseURL {
+		t.Error("expected China to use its own price list host")
+	}
+}
+
+func TestAWSFetchRegionChinaPriceList(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		switch r.URL.Path {
+		case "/offers/v1.0/cn/AmazonS3/current/region_index.json":
+			fmt.Fprint(w, `{"regions": {"cn-north-1": {"currentVersionUrl": "/offers/v1.0/cn/AmazonS3/20240101/cn-north-1/index.json"}}}`)
+		case "/offers/v1.0/cn/AmazonS3/20240101/cn-north-1/index.json":
+			fmt.Fprint(w, `{"products": {"SKU1": {"sku": "SKU1", "productFamily": "Storage", "attributes": {"regionCode": "cn-north-1", "location": "China (Beijing)"}}},
+				"terms": {"OnDemand": {"SKU1": {"SKU1.T1": {"sku": "SKU1", "priceDimensions": {"SKU1.T1.D1": {"unit": "GB-Mo", "pricePerUnit": {"CNY": "0.1755"}}}}}}}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	fetcher := NewAWSPricingAPIFetcher()
+	fetcher.baseURL = "http://commercial.invalid"
+	fetcher.chinaBaseURL = server.URL
+	fetcher.SetAllowedServices([]string{"AmazonS3"})
+
+	prices, err := fetcher.FetchRegion(context.Background(), "cn-north-1")
+	if err != nil {
+		t.Fatalf("fetch failed: %v (requested %v)", err, paths)
+	}
+	if len(prices) != 1 || prices[0].Currency != "CNY" || prices[0].PricePerUnit != "0.1755" {
+		t.Errorf("expected one CNY price from the China price list, got %+v", prices)
+	}
+}
+
+func TestMatchesRegionEdgeZones(t *testing.T) {
+	tests := []struct {
+		location, region string
+		want             bool
+	}{
+		{"US West (Los Angeles)", "us-west-2-lax-1", true},
+		{"US West (Los Angeles)", "us-west-2", false},
+		{"US West (Oregon)", "us-west-2-lax-1", false},
+		{"US East (Verizon) - Boston", "us-east-1-wl1-bos-wlz-1", true},
+		{"US East (Verizon) - Boston", "us-east-1-bos-1", false},
+		{"US East (Boston)", "us-east-1-bos-1", true},
+	}
+	for _, tt := range tests {
+		if got := matchesRegion(tt.location, tt.region); got != tt.want {
+			t.Errorf("matchesRegion(%q, %s) = %v, want %v", tt.location, tt.region, got, tt.want)
+		}
+	}
+
+	registry := regions.NewRegistry()
+	for zone, parent := range map[string]string{"us-west-2-lax-1": "us-west-2", "us-east-1-wl1-bos-wlz-1": "us-east-1", "us-west-2": "us-west-2"} {
+		if got := registry.ParentRegion(db.AWS, zone); got != parent {
+			t.Errorf("ParentRegion(%s) = %s, want %s", zone, got, parent)
+		}
+	}
+}
+
+func TestAWSFetchRegionLocalZoneUsesParentPriceList(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/offers/v1.0/aws/AmazonEC2/current/region_index.json":
+			fmt.Fprint(w, `{"regions": {"us-west-2": {"currentVersionUrl": "/offers/v1.0/aws/AmazonEC2/20240101/us-west-2/index.json"}}}`)
+		case "/offers/v1.0/aws/AmazonEC2/20240101/us-west-2/index.json":
+			fmt.Fprint(w, `{"products": {
+				"OREGON": {"sku": "OREGON", "productFamily": "Compute Instance", "attributes": {"regionCode": "us-west-2", "location": "US West (Oregon)"}},
+				"LAX": {"sku": "LAX", "productFamily": "Compute Instance", "attributes": {"regionCode": "us-west-2-lax-1", "location": "US West (Los Angeles)"}}},
+				"terms": {"OnDemand": {
+				"OREGON": {"OREGON.T1": {"sku": "OREGON", "priceDimensions": {"OREGON.T1.D1": {"unit": "Hrs", "pricePerUnit": {"USD": "0.096"}}}}},
+				"LAX": {"LAX.T1": {"sku": "LAX", "priceDimensions": {"LAX.T1.D1": {"unit": "Hrs", "pricePerUnit": {"USD": "0.115"}}}}}}}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	fetcher := NewAWSPricingAPIFetcher()
+	fetcher.baseURL = server.URL
+	fetcher.SetAllowedServices([]string{"AmazonEC2"})
+
+	prices, err := fetcher.FetchRegion(context.Background(), "us-west-2-lax-1")
+	if err != nil {
+		t.Fatalf("fetch failed: %v", err)
+	}
+	if len(prices) != 1 || prices[0].SKU != "LAX" || prices[0].Region != "us-west-2-lax-1" {
+		t.Errorf("expected only the Los Angeles price, got %+v", prices)
+	}
+	if err := ValidateRegion(regions.NewRegistry(), db.AWS, "us-west-2-lax-1", false); err != nil {
+		t.Errorf("expected the Local Zone to be a billable region, got %v", err)
+	}
+}
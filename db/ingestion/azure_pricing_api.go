@@ -6,12 +6,17 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
 	"terraform-cost/db"
+	"terraform-cost/db/regions"
+
+	"github.com/shopspring/decimal"
 )
 
 // AzurePricingAPIClient fetches pricing from Azure Retail Prices API
@@ -20,6 +25,15 @@ type AzurePricingAPIClient struct {
 	httpClient   *http.Client
 	baseURL      string
 	servicesList []string
+	priceTypes   []string
+	families     []string
+	filterPrefix string
+	pageSize     int
+	registry     *regions.Registry
+	logger       *slog.Logger
+
+	breakerThreshold int
+	serviceTimeout   ServiceTimeout
 }
 
 // AzurePricingConfig configures the Azure pricing client
@@ -27,15 +41,43 @@ type AzurePricingConfig struct {
 	// HTTPTimeout for API calls
 	HTTPTimeout time.Duration
 
+	// HTTPClient, when set, is used for API calls instead of a client
+	// built from HTTPTimeout, so fetchers can share one configured client
+	HTTPClient *http.Client
+
 	// Services to fetch (empty = ALL services)
 	Services []string
+
+	// PriceTypes are the item types to ingest: "Consumption" (pay-as-you-go
+	// and spot meters), "Reservation", "DevTestConsumption" (empty = all)
+	PriceTypes []string
+
+	// ServiceFamilies limits the crawl to these serviceFamily values, e.g.
+	// "Compute", "Storage", "Databases" (empty = every family)
+	ServiceFamilies []string
+
+	// FilterPrefix is an OData expression ANDed into every request, e.g.
+	// "serviceName eq 'Virtual Machines'"
+	FilterPrefix string
+
+	// PageSize sets $top, the items per page (0 = API default, max 1000)
+	PageSize int
+
+	// BreakerThreshold is how many consecutive service failures abort the
+	// region fetch (0 = never)
+	BreakerThreshold int
+
+	// ServiceTimeout is the per-service deadline within FetchRegion
+	ServiceTimeout ServiceTimeout
 }
 
 // DefaultAzurePricingConfig returns production defaults
 func DefaultAzurePricingConfig() *AzurePricingConfig {
 	return &AzurePricingConfig{
-		HTTPTimeout: 10 * time.Minute,
-		Services:    AllAzureServices(),
+		HTTPTimeout:      10 * time.Minute,
+		Services:         AllAzureServices(),
+		PriceTypes:       []string{AzurePriceTypeConsumption, AzurePriceTypeReservation},
+		BreakerThreshold: DefaultCircuitBreakerThreshold,
 	}
 }
 
@@ -123,11 +165,17 @@ func NewAzurePricingAPIClient(cfg *AzurePricingConfig) *AzurePricingAPIClient {
 	}
 
 	return &AzurePricingAPIClient{
-		httpClient: &http.Client{
-			Timeout: cfg.HTTPTimeout,
-		},
+		httpClient: httpClientOrDefault(cfg.HTTPClient, cfg.HTTPTimeout),
 		baseURL:      "https://prices.azure.com/api/retail/prices",
 		servicesList: cfg.Services,
+		priceTypes:   cfg.PriceTypes,
+		families:     cfg.ServiceFamilies,
+		filterPrefix: cfg.FilterPrefix,
+		pageSize:     min(max(cfg.PageSize, 0), AzureMaxPageSize),
+		registry:     regions.NewRegistry(),
+
+		breakerThreshold: cfg.BreakerThreshold,
+		serviceTimeout:   cfg.ServiceTimeout,
 	}
 }
 
@@ -179,15 +227,84 @@ func (c *AzurePricingAPIClient) SupportedServices() []string {
 	return c.servicesList
 }
 
+// SetLogger sets the logger used for fetch warnings
+func (c *AzurePricingAPIClient) SetLogger(logger *slog.Logger) {
+	c.logger = logger
+}
+
+// SetTransport routes API calls through rt (see TransportConfig), keeping
+// the client's timeout
+func (c *AzurePricingAPIClient) SetTransport(rt http.RoundTripper) {
+	client := *c.httpClient
+	client.Transport = rt
+	c.httpClient = &client
+}
+
+// SetServiceTimeout sets the per-service deadline within FetchRegion
+func (c *AzurePricingAPIClient) SetServiceTimeout(t ServiceTimeout) {
+	c.serviceTimeout = t
+}
+
 // FetchRegion fetches ALL pricing for a region from Azure Retail Prices API
-// This is mapper-agnostic - fetches complete catalogs
+// This is mapper-agnostic - fetches complete catalogs.
+// With a services list each service is crawled separately so one failing
+// service is reported in a *PartialFetchError instead of aborting the region.
 func (c *AzurePricingAPIClient) FetchRegion(ctx context.Context, region string) ([]RawPrice, error) {
+	// The Retail Prices API covers US Gov regions but not Azure China (21Vianet)
+	if reg := c.registry.GetRegion(db.Azure, region); reg != nil && reg.PricingSource == "china" {
+		return nil, fmt.Errorf("Azure region %s is priced by the %s pricing source, which the Retail Prices API does not serve", region, reg.PricingSource)
+	}
+
+	filter := c.regionFilter(region)
+
+	if len(c.servicesList) == 0 {
+		// Paginate through ALL prices for the region
+		allPrices, err := c.fetchFiltered(ctx, filter, region)
+		if err != nil {
+			return nil, err
+		}
+		if len(allPrices) == 0 {
+			return nil, fmt.Errorf("failed to fetch any pricing for Azure region %s", region)
+		}
+		return allPrices, nil
+	}
+
 	var allPrices []RawPrice
+	failures := &serviceFailures{provider: db.Azure, region: region, expected: len(c.servicesList), threshold: c.breakerThreshold}
+	for i, service := range c.servicesList {
+		serviceFilter := fmt.Sprintf("%s and serviceName eq '%s'", filter, strings.ReplaceAll(service, "'", "''"))
+		serviceCtx, cancel := c.serviceTimeout.context(ctx, len(c.servicesList)-i)
+		prices, err := c.fetchFiltered(serviceCtx, serviceFilter, region)
+		cancel()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			err = serviceTimedOut(ctx, serviceCtx, err)
+			loggerOrDefault(c.logger).Warn("failed to fetch service pricing", "provider", "azure", "region", region, "service", service, "error", err)
+			failures.add(service, err)
+			if open := failures.open(i + 1); open != nil {
+				return nil, open
+			}
+			continue
+		}
+		failures.succeeded()
+		allPrices = append(allPrices, prices...)
+	}
 
-	// Azure Retail Prices API uses OData filter syntax
-	// We paginate through ALL prices for the region
-	filter := fmt.Sprintf("armRegionName eq '%s'", region)
+	if len(allPrices) == 0 {
+		if err := failures.err(); err != nil {
+			return nil, fmt.Errorf("failed to fetch any pricing for Azure region %s: %w", region, err)
+		}
+		return nil, fmt.Errorf("failed to fetch any pricing for Azure region %s", region)
+	}
+
+	return allPrices, failures.err()
+}
 
+// fetchFiltered pages through every price matching an OData filter
+func (c *AzurePricingAPIClient) fetchFiltered(ctx context.Context, filter, region string) ([]RawPrice, error) {
+	var allPrices []RawPrice
 	nextLink := c.buildURL(filter)
 
 	for nextLink != "" {
@@ -205,17 +322,37 @@ func (c *AzurePricingAPIClient) FetchRegion(ctx context.Context, region string)
 		allPrices = append(allPrices, prices...)
 		nextLink = next
 
-		// Log progress
-		if len(allPrices)%10000 == 0 {
-			fmt.Printf("  Fetched %d Azure prices for %s...\n", len(allPrices), region)
-		}
+		loggerOrDefault(c.logger).Debug("fetched pricing page", "provider", "azure", "region", region, "rate_count", len(allPrices))
 	}
 
-	if len(allPrices) == 0 {
-		return nil, fmt.Errorf("failed to fetch any pricing for Azure region %s", region)
+	return allPrices, nil
+}
+
+// AzureMaxPageSize is the largest $top the Retail Prices API accepts
+const AzureMaxPageSize = 1000
+
+// regionFilter builds the OData filter for a region from the configured
+// prefix, price types and service families
+func (c *AzurePricingAPIClient) regionFilter(region string) string {
+	filter := fmt.Sprintf("armRegionName eq '%s'", region)
+	if c.filterPrefix != "" {
+		filter = "(" + c.filterPrefix + ") and " + filter
 	}
+	filter += odataAnyOf("type", c.priceTypes)
+	filter += odataAnyOf("serviceFamily", c.families)
+	return filter
+}
 
-	return allPrices, nil
+// odataAnyOf returns " and (field eq 'a' or field eq 'b')", or "" for no values
+func odataAnyOf(field string, values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	terms := make([]string, len(values))
+	for i, v := range values {
+		terms[i] = fmt.Sprintf("%s eq '%s'", field, strings.ReplaceAll(v, "'", "''"))
+	}
+	return " and (" + strings.Join(terms, " or ") + ")"
 }
 
 // buildURL constructs the API URL with filter
@@ -223,6 +360,9 @@ func (c *AzurePricingAPIClient) buildURL(filter string) string {
 	params := url.Values{}
 	params.Set("$filter", filter)
 	params.Set("api-version", "2023-01-01-preview")
+	if c.pageSize > 0 {
+		params.Set("$top", strconv.Itoa(c.pageSize))
+	}
 	return c.baseURL + "?" + params.Encode()
 }
 
@@ -251,8 +391,8 @@ func (c *AzurePricingAPIClient) fetchPage(ctx context.Context, pageURL string) (
 	// Convert to RawPrice
 	var prices []RawPrice
 	for _, item := range response.Items {
-		// Skip zero-priced items and reservation pricing
-		if item.RetailPrice == 0 {
+		// Skip zero-priced items and types the filter should have excluded
+		if item.RetailPrice == 0 || !c.wantsPriceType(item.Type) {
 			continue
 		}
 
@@ -276,7 +416,7 @@ func (c *AzurePricingAPIClient) fetchPage(ctx context.Context, pageURL string) (
 
 		// Handle tiered pricing
 		if item.TierMinimumUnits > 0 {
-			tierStart := item.TierMinimumUnits
+			tierStart := decimal.NewFromFloat(item.TierMinimumUnits)
 			price.TierStart = &tierStart
 		}
 
@@ -286,9 +426,25 @@ func (c *AzurePricingAPIClient) fetchPage(ctx context.Context, pageURL string) (
 	return prices, response.NextPageLink, nil
 }
 
+// wantsPriceType reports whether items of type t are ingested
+func (c *AzurePricingAPIClient) wantsPriceType(t string) bool {
+	if len(c.priceTypes) == 0 {
+		return true
+	}
+	for _, want := range c.priceTypes {
+		if strings.EqualFold(t, want) {
+			return true
+		}
+	}
+	return false
+}
+
 // buildAttributes creates normalized attributes from Azure pricing item
 func (c *AzurePricingAPIClient) buildAttributes(item AzurePriceItem) map[string]string {
-	attrs := make(map[string]string)
+	attrs := map[string]string{"pricingType": azurePricingType(item)}
+	if item.ReservationTerm != "" {
+		attrs["reservationTerm"] = item.ReservationTerm
+	}
 
 	if item.SkuName != "" {
 		attrs["skuName"] = item.SkuName
@@ -354,7 +510,46 @@ type AzurePriceItem struct {
 	IsPrimaryMeterRegion  bool    `json:"isPrimaryMeterRegion"`
 	ArmSkuName            string  `json:"armSkuName"`
 	ReservationTerm       string  `json:"reservationTerm,omitempty"`
-	SavingsPlan           string  `json:"savingsPlan,omitempty"`
+
+	// SavingsPlan lists savings plan prices for the meter (not ingested)
+	SavingsPlan []AzureSavingsPlanPrice `json:"savingsPlan,omitempty"`
+}
+
+// AzureSavingsPlanPrice is a savings plan price attached to a meter
+type AzureSavingsPlanPrice struct {
+	UnitPrice   float64 `json:"unitPrice"`
+	RetailPrice float64 `json:"retailPrice"`
+	Term        string  `json:"term"`
+}
+
+// Azure price item types
+const (
+	AzurePriceTypeConsumption = "Consumption"
+	AzurePriceTypeReservation = "Reservation"
+)
+
+// azurePricingType classifies an item as on_demand, spot, low_priority or
+// reservation. Spot and low priority VMs are Consumption meters named after
+// their SKU ("D2s v3 Spot").
+func azurePricingType(item AzurePriceItem) string {
+	if strings.EqualFold(item.Type, AzurePriceTypeReservation) {
+		return "reservation"
+	}
+	name := strings.ToLower(item.SkuName + " " + item.MeterName)
+	switch {
+	case strings.Contains(name, "spot"):
+		return "spot"
+	case strings.Contains(name, "low priority"):
+		return "low_priority"
+	}
+	return "on_demand"
+}
+
+// azureReservationHours maps a reservation term to the hours it covers
+var azureReservationHours = map[string]int64{
+	"1 year":  8760,
+	"3 years": 26280,
+	"5 years": 43800,
 }
 
 // AzurePricingNormalizer normalizes raw Azure pricing to canonical format
@@ -371,10 +566,13 @@ func (n *AzurePricingNormalizer) Cloud() db.CloudProvider {
 }
 
 // Normalize converts raw Azure prices to normalized rates
-func (n *AzurePricingNormalizer) Normalize(raw []RawPrice) ([]NormalizedRate, error) {
+func (n *AzurePricingNormalizer) Normalize(ctx context.Context, raw []RawPrice) ([]NormalizedRate, error) {
 	var rates []NormalizedRate
 
-	for _, r := range raw {
+	for i, r := range raw {
+		if err := checkCancelled(ctx, i); err != nil {
+			return nil, err
+		}
 		price, err := ParsePrice(r.PricePerUnit)
 		if err != nil {
 			continue
@@ -392,12 +590,28 @@ func (n *AzurePricingNormalizer) Normalize(raw []RawPrice) ([]NormalizedRate, er
 			Attributes:    attrs,
 		}
 
+		unit := n.normalizeUnit(r.Unit)
+
+		// Reservations are priced for the whole term; store the hourly
+		// equivalent so they resolve like pay-as-you-go compute hours
+		if r.Attributes["type"] == AzurePriceTypeReservation {
+			hours, ok := azureReservationHours[strings.ToLower(r.Attributes["reservationTerm"])]
+			if !ok {
+				continue
+			}
+			price = price.DivRound(decimal.NewFromInt(hours), 10)
+			unit = "hours"
+		}
+
 		nr := NormalizedRate{
 			RateKey:    rateKey,
-			Unit:       n.normalizeUnit(r.Unit),
+			Unit:       unit,
 			Price:      price,
 			Currency:   r.Currency,
 			Confidence: 1.0,
+
+			EffectiveDate: r.EffectiveDate,
+			Labels:        sourceLabels(r),
 		}
 
 		rates = append(rates, nr)
@@ -0,0 +1,100 @@
+// Package ingestion - Azure Retail Prices client tests
+package ingestion
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestAzurePricingTypesFixture(t *testing.T) {
+	body, err := os.ReadFile("testdata/azure_prices.json")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		filter := r.URL.Query().Get("$filter")
+		if !strings.Contains(filter, "(type eq 'Consumption' or type eq 'Reservation')") {
+			t.Errorf("expected the filter to select price types, got %q", filter)
+		}
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	cfg := DefaultAzurePricingConfig()
+	cfg.Services = nil
+	client := NewAzurePricingAPIClient(cfg)
+	client.baseURL = server.URL
+
+	raw, err := client.FetchRegion(context.Background(), "eastus")
+	if err != nil {
+		t.Fatalf("fetch failed: %v", err)
+	}
+	if len(raw) != 4 {
+		t.Fatalf("expected the dev/test meter to be dropped leaving 4 prices, got %d", len(raw))
+	}
+
+	rates, err := NewAzurePricingNormalizer().Normalize(context.Background(), raw)
+	if err != nil {
+		t.Fatalf("normalize failed: %v", err)
+	}
+	byType := make(map[string]NormalizedRate)
+	for _, r := range rates {
+		byType[r.RateKey.Attributes["pricing_type"]+"/"+r.RateKey.Attributes["reservation_term"]] = r
+	}
+
+	tests := []struct {
+		key   string
+		price string
+	}{
+		{"on_demand/", "0.096"},
+		{"spot/", "0.0192"},
+		{"reservation/1 year", "0.0575"}, // 503.7 / 8760
+		{"reservation/3 years", "0.04"},  // 1051.2 / 26280
+	}
+	for _, tt := range tests {
+		r, ok := byType[tt.key]
+		if !ok {
+			t.Errorf("missing %s rate, got %v", tt.key, byType)
+			continue
+		}
+		if r.Unit != "hours" || r.Price.String() != tt.price {
+			t.Errorf("%s: expected %s per hour, got %s per %s", tt.key, tt.price, r.Price, r.Unit)
+		}
+		if r.RateKey.Attributes["vm_size"] != "standard_d2s_v3" {
+			t.Errorf("%s: unexpected attributes %v", tt.key, r.RateKey.Attributes)
+		}
+	}
+}
+
+func TestAzureBuildURLPageSizeAndFilter(t *testing.T) {
+	cfg := DefaultAzurePricingConfig()
+	cfg.PageSize = 500
+	cfg.ServiceFamilies = []string{"Compute", "Storage"}
+	cfg.FilterPrefix = "serviceName eq 'Virtual Machines'"
+	client := NewAzurePricingAPIClient(cfg)
+
+	u, err := url.Parse(client.buildURL(client.regionFilter("westeurope")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := u.Query()
+	if got := query.Get("$top"); got != "500" {
+		t.Errorf("$top = %q, want 500", got)
+	}
+	want := "(serviceName eq 'Virtual Machines') and armRegionName eq 'westeurope'" +
+		" and (type eq 'Consumption' or type eq 'Reservation')" +
+		" and (serviceFamily eq 'Compute' or serviceFamily eq 'Storage')"
+	if got := query.Get("$filter"); got != want {
+		t.Errorf("$filter = %q\nwant      %q", got, want)
+	}
+
+	cfg.PageSize = 5000
+	if got := NewAzurePricingAPIClient(cfg).pageSize; got != AzureMaxPageSize {
+		t.Errorf("expected the page size capped at %d, got %d", AzureMaxPageSize, got)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"terraform-cost/db"
@@ -41,38 +42,122 @@ type SnapshotBackup struct {
 	Rates []NormalizedRate `json:"rates"`
 }
 
+// BackupSchemaVersion is the SnapshotBackup layout written by this version
+const BackupSchemaVersion = "1.0"
+
+// MigrateBackup upgrades a backup written with an older schema to
+// BackupSchemaVersion. There are no older layouts yet, so it only passes
+// current backups through; each future version adds a case here.
+func MigrateBackup(old *SnapshotBackup) (*SnapshotBackup, error) {
+	switch old.SchemaVersion {
+	case BackupSchemaVersion:
+		return old, nil
+	default:
+		return nil, fmt.Errorf("unsupported backup schema version %q (supported: %s); restore it with the release that wrote it",
+			old.SchemaVersion, BackupSchemaVersion)
+	}
+}
+
+// BackupNamingConfig controls backup filenames:
+// region_timestamp[_hashprefix][_counter].json.gz
+type BackupNamingConfig struct {
+	// HashPrefixLen is how many content hash characters to include (0 = none)
+	HashPrefixLen int
+
+	// Counter appends a per-manager sequence number so identical content
+	// written within the same second still gets distinct files
+	Counter bool
+
+	// Overwrite replaces an existing file instead of failing
+	Overwrite bool
+}
+
+// DefaultBackupNamingConfig returns the default naming: a 12-character hash
+// prefix and no overwriting
+func DefaultBackupNamingConfig() BackupNamingConfig {
+	return BackupNamingConfig{HashPrefixLen: 12}
+}
+
 // BackupManager handles backup creation and reading
-type BackupManager struct{}
+type BackupManager struct {
+	naming      BackupNamingConfig
+	compression int
+	counter     atomic.Uint64
+}
 
 // NewBackupManager creates a backup manager
 func NewBackupManager() *BackupManager {
-	return &BackupManager{}
+	return &BackupManager{naming: DefaultBackupNamingConfig(), compression: gzip.DefaultCompression}
+}
+
+// WithNaming sets the backup filename strategy
+func (m *BackupManager) WithNaming(naming BackupNamingConfig) *BackupManager {
+	m.naming = naming
+	return m
+}
+
+// WithCompression sets the gzip level of backups and raw price dumps, from
+// gzip.NoCompression to gzip.BestCompression (default gzip.DefaultCompression).
+// Reading does not depend on the level.
+func (m *BackupManager) WithCompression(level int) *BackupManager {
+	m.compression = level
+	return m
+}
+
+// backupFilename builds the filename for a backup under the naming config
+func (m *BackupManager) backupFilename(backup *SnapshotBackup) string {
+	name := backup.Region + "_" + backup.Timestamp.Format(backupTimestampLayout)
+	if n := m.naming.HashPrefixLen; n > 0 && backup.ContentHash != "" {
+		if n > len(backup.ContentHash) {
+			n = len(backup.ContentHash)
+		}
+		name += "_" + backup.ContentHash[:n]
+	}
+	if m.naming.Counter {
+		name += fmt.Sprintf("_%06d", m.counter.Add(1))
+	}
+	return name + ".json.gz"
+}
+
+// checkCompression rejects an invalid gzip level before any file is created
+func (m *BackupManager) checkCompression() error {
+	if m.compression < gzip.HuffmanOnly || m.compression > gzip.BestCompression {
+		return fmt.Errorf("invalid backup compression level %d", m.compression)
+	}
+	return nil
 }
 
-// WriteBackup writes a snapshot backup to disk
+// WriteBackup writes a snapshot backup to disk. It refuses to replace an
+// existing file unless the naming config allows overwriting.
 func (m *BackupManager) WriteBackup(baseDir string, backup *SnapshotBackup) (string, error) {
+	if err := m.checkCompression(); err != nil {
+		return "", err
+	}
+
 	// Create directory structure: baseDir/provider/timestamp.json.gz
 	providerDir := filepath.Join(baseDir, string(backup.Provider))
 	if err := os.MkdirAll(providerDir, 0755); err != nil {
 		return "", fmt.Errorf("failed to create backup directory: %w", err)
 	}
 
-	// Generate filename with timestamp
-	filename := fmt.Sprintf("%s_%s.json.gz",
-		backup.Region,
-		backup.Timestamp.Format("2006-01-02T15-04-05"),
-	)
-	fullPath := filepath.Join(providerDir, filename)
+	fullPath := filepath.Join(providerDir, m.backupFilename(backup))
 
-	// Create file
-	file, err := os.Create(fullPath)
+	// Create file; O_EXCL makes the collision check atomic
+	flags := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if m.naming.Overwrite {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+	file, err := os.OpenFile(fullPath, flags, 0644)
+	if os.IsExist(err) {
+		return "", fmt.Errorf("backup file %s already exists", fullPath)
+	}
 	if err != nil {
 		return "", fmt.Errorf("failed to create backup file: %w", err)
 	}
 	defer file.Close()
 
 	// Write gzipped JSON
-	gzWriter := gzip.NewWriter(file)
+	gzWriter, _ := gzip.NewWriterLevel(file, m.compression)
 	defer gzWriter.Close()
 
 	encoder := json.NewEncoder(gzWriter)
@@ -104,21 +189,28 @@ func (m *BackupManager) ReadBackup(path string) (*SnapshotBackup, error) {
 		reader = gzReader
 	}
 
-	var backup SnapshotBackup
-	if err := json.NewDecoder(reader).Decode(&backup); err != nil {
+	var decoded SnapshotBackup
+	if err := json.NewDecoder(reader).Decode(&decoded); err != nil {
 		return nil, fmt.Errorf("failed to decode backup: %w", err)
 	}
+	backup, err := MigrateBackup(&decoded)
+	if err != nil {
+		return nil, err
+	}
 
 	// Validate
-	if err := m.ValidateBackup(&backup); err != nil {
+	if err := m.ValidateBackup(backup); err != nil {
 		return nil, fmt.Errorf("backup validation failed: %w", err)
 	}
 
-	return &backup, nil
+	return backup, nil
 }
 
 // ValidateBackup validates a backup file
 func (m *BackupManager) ValidateBackup(backup *SnapshotBackup) error {
+	if backup.SchemaVersion != BackupSchemaVersion {
+		return fmt.Errorf("unsupported backup schema version %q (supported: %s)", backup.SchemaVersion, BackupSchemaVersion)
+	}
 	if backup.Provider == "" {
 		return fmt.Errorf("backup missing provider")
 	}
@@ -144,11 +236,88 @@ func (m *BackupManager) ValidateBackup(backup *SnapshotBackup) error {
 	return nil
 }
 
+// RawPriceDump holds the raw fetched prices behind a backup, so a region can
+// be re-normalized without fetching it again
+type RawPriceDump struct {
+	Provider  db.CloudProvider `json:"provider"`
+	Region    string           `json:"region"`
+	Alias     string           `json:"alias"`
+	Timestamp time.Time        `json:"timestamp"`
+
+	// RealAPI records whether the prices came from a fetcher that passed the
+	// production guard, so a replay is held to the same standard
+	RealAPI bool `json:"real_api"`
+
+	RawCount int        `json:"raw_count"`
+	Prices   []RawPrice `json:"prices"`
+}
+
+// rawPricesSuffix names a raw price dump stored next to its backup
+const rawPricesSuffix = ".raw.json.gz"
+
+// RawPricesPath returns where the raw prices of a backup are stored
+func RawPricesPath(backupPath string) string {
+	return strings.TrimSuffix(backupPath, ".json.gz") + rawPricesSuffix
+}
+
+// WriteRawPrices writes the raw prices of a backup next to it as gzipped JSON
+func (m *BackupManager) WriteRawPrices(backupPath string, dump *RawPriceDump) (string, error) {
+	if err := m.checkCompression(); err != nil {
+		return "", err
+	}
+	path := RawPricesPath(backupPath)
+	flags := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if m.naming.Overwrite {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+	file, err := os.OpenFile(path, flags, 0644)
+	if err != nil {
+		return "", fmt.Errorf("failed to create raw price file: %w", err)
+	}
+	defer file.Close()
+
+	gzWriter, _ := gzip.NewWriterLevel(file, m.compression)
+	if err := json.NewEncoder(gzWriter).Encode(dump); err != nil {
+		return "", fmt.Errorf("failed to write raw prices: %w", err)
+	}
+	if err := gzWriter.Close(); err != nil {
+		return "", fmt.Errorf("failed to write raw prices: %w", err)
+	}
+	return path, nil
+}
+
+// ReadRawPrices reads a raw price dump written by WriteRawPrices
+func (m *BackupManager) ReadRawPrices(path string) (*RawPriceDump, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open raw price file: %w", err)
+	}
+	defer file.Close()
+
+	gzReader, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gzip reader: %w", err)
+	}
+	defer gzReader.Close()
+
+	var dump RawPriceDump
+	if err := json.NewDecoder(gzReader).Decode(&dump); err != nil {
+		return nil, fmt.Errorf("failed to decode raw prices: %w", err)
+	}
+	if dump.Provider == "" || dump.Region == "" {
+		return nil, fmt.Errorf("raw price file missing provider or region")
+	}
+	if len(dump.Prices) != dump.RawCount {
+		return nil, fmt.Errorf("raw price count mismatch: header says %d, actual %d", dump.RawCount, len(dump.Prices))
+	}
+	return &dump, nil
+}
+
 // ListBackups lists all backups in a directory
 func (m *BackupManager) ListBackups(baseDir string) ([]BackupInfo, error) {
 	var backups []BackupInfo
 
-	providers := []string{"aws", "azure", "gcp"}
+	providers := []string{"aws", "azure", "gcp", "oci", "digitalocean"}
 	for _, provider := range providers {
 		providerDir := filepath.Join(baseDir, provider)
 		if _, err := os.Stat(providerDir); os.IsNotExist(err) {
@@ -167,6 +336,9 @@ func (m *BackupManager) ListBackups(baseDir string) ([]BackupInfo, error) {
 			if !strings.HasSuffix(entry.Name(), ".json") && !strings.HasSuffix(entry.Name(), ".json.gz") {
 				continue
 			}
+			if strings.HasSuffix(entry.Name(), rawPricesSuffix) || strings.HasSuffix(entry.Name(), validationReportSuffix) {
+				continue
+			}
 
 			info, err := entry.Info()
 			if err != nil {
@@ -0,0 +1,147 @@
+// Package ingestion - Backup naming tests
+package ingestion
+
+import (
+	"compress/gzip"
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"terraform-cost/db"
+)
+
+// namedBackup builds a valid backup of n test rates taken at ts
+func namedBackup(t *testing.T, ts time.Time, n int) *SnapshotBackup {
+	t.Helper()
+	rates, _ := (&passthroughNormalizer{cloud: db.AWS}).Normalize(context.Background(), testRawPrices("us-east-1", n))
+	return &SnapshotBackup{
+		Provider:      db.AWS,
+		Region:        "us-east-1",
+		Alias:         "default",
+		Timestamp:     ts,
+		ContentHash:   calculateHash(rates),
+		RateCount:     len(rates),
+		SchemaVersion: "1.0",
+		Rates:         rates,
+	}
+}
+
+func TestWriteBackupSameSecondKeepsBoth(t *testing.T) {
+	dir := t.TempDir()
+	ts := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	mgr := NewBackupManager()
+
+	first, err := mgr.WriteBackup(dir, namedBackup(t, ts, 2))
+	if err != nil {
+		t.Fatalf("first backup: %v", err)
+	}
+	second, err := mgr.WriteBackup(dir, namedBackup(t, ts.Add(300*time.Millisecond), 3))
+	if err != nil {
+		t.Fatalf("second backup: %v", err)
+	}
+	if first == second {
+		t.Fatalf("both backups written to %s", first)
+	}
+
+	for path, want := range map[string]int{first: 2, second: 3} {
+		backup, err := mgr.ReadBackup(path)
+		if err != nil {
+			t.Fatalf("read %s: %v", path, err)
+		}
+		if backup.RateCount != want {
+			t.Errorf("%s: %d rates, want %d", path, backup.RateCount, want)
+		}
+	}
+
+	backups, _ := mgr.ListBackups(dir)
+	if len(backups) != 2 {
+		t.Errorf("expected 2 backups on disk, got %d", len(backups))
+	}
+}
+
+func TestWriteBackupCollision(t *testing.T) {
+	ts := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	backup := namedBackup(t, ts, 2)
+
+	// Identical content in the same second collides rather than overwriting
+	dir := t.TempDir()
+	mgr := NewBackupManager()
+	if _, err := mgr.WriteBackup(dir, backup); err != nil {
+		t.Fatalf("first backup: %v", err)
+	}
+	if _, err := mgr.WriteBackup(dir, backup); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("expected collision error, got %v", err)
+	}
+
+	// Overwrite replaces the file
+	mgr.WithNaming(BackupNamingConfig{HashPrefixLen: 12, Overwrite: true})
+	if _, err := mgr.WriteBackup(dir, backup); err != nil {
+		t.Errorf("expected overwrite to succeed, got %v", err)
+	}
+
+	// A counter keeps every copy
+	dir = t.TempDir()
+	mgr = NewBackupManager().WithNaming(BackupNamingConfig{Counter: true})
+	a, err := mgr.WriteBackup(dir, backup)
+	if err != nil {
+		t.Fatalf("counted backup: %v", err)
+	}
+	b, err := mgr.WriteBackup(dir, backup)
+	if err != nil {
+		t.Fatalf("counted backup: %v", err)
+	}
+	if a == b || !strings.HasSuffix(b, "_000002.json.gz") {
+		t.Errorf("unexpected counted filenames %s and %s", a, b)
+	}
+}
+
+func TestWriteBackupCompressionLevels(t *testing.T) {
+	backup := namedBackup(t, time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC), 2000)
+	sizes := make(map[int]int64)
+	for _, level := range []int{gzip.NoCompression, gzip.DefaultCompression, gzip.BestCompression} {
+		mgr := NewBackupManager().WithCompression(level)
+		path, err := mgr.WriteBackup(t.TempDir(), backup)
+		if err != nil {
+			t.Fatalf("level %d: %v", level, err)
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sizes[level] = info.Size()
+
+		read, err := mgr.ReadBackup(path)
+		if err != nil || read.RateCount != backup.RateCount {
+			t.Fatalf("level %d: backup not readable: %v", level, err)
+		}
+	}
+	if sizes[gzip.BestCompression] >= sizes[gzip.DefaultCompression] || sizes[gzip.DefaultCompression] >= sizes[gzip.NoCompression] {
+		t.Errorf("expected best < default < none, got sizes %v", sizes)
+	}
+
+	if _, err := NewBackupManager().WithCompression(12).WriteBackup(t.TempDir(), backup); err == nil {
+		t.Error("expected an invalid level to be rejected")
+	}
+}
+
+func TestReadBackupRejectsUnsupportedSchemaVersion(t *testing.T) {
+	backup := namedBackup(t, time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC), 3)
+	mgr := NewBackupManager()
+	if migrated, err := MigrateBackup(backup); err != nil || migrated != backup {
+		t.Fatalf("expected a current backup to pass through, got %v", err)
+	}
+
+	backup.SchemaVersion = "2.0"
+	if err := mgr.ValidateBackup(backup); err == nil || !strings.Contains(err.Error(), `"2.0"`) {
+		t.Errorf("expected validation to reject schema 2.0, got %v", err)
+	}
+	path, err := mgr.WriteBackup(t.TempDir(), backup)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := mgr.ReadBackup(path); err == nil || !strings.Contains(err.Error(), "unsupported backup schema version") {
+		t.Errorf("expected restore to reject schema 2.0, got %v", err)
+	}
+}
@@ -0,0 +1,65 @@
+// Package ingestion - Adaptive batch sizing for the streaming pipeline
+// A fixed batch wastes time on a box with spare RAM and can still run out of
+// memory under pressure; the sizer grows or shrinks batches from the heap
+// observed after each one.
+package ingestion
+
+import "runtime"
+
+// DefaultTargetMemoryPercent is the share of MaxMemoryMB adaptive batching
+// aims to keep the heap at
+const DefaultTargetMemoryPercent = 70
+
+// BatchSizer adjusts the streaming batch size between a minimum and maximum
+// so heap usage stays near a target. Batches grow by half while memory is
+// below three quarters of the target and halve once it exceeds the target.
+type BatchSizer struct {
+	min      int
+	max      int
+	size     int
+	targetMB uint64
+	heapMB   func() uint64
+}
+
+// NewBatchSizer returns the sizer for config, or nil when config does not
+// enable adaptive batching (MaxBatchSize unset). BatchSize is the starting
+// size, clamped to the bounds.
+func NewBatchSizer(config *StreamingConfig) *BatchSizer {
+	if config == nil || config.MaxBatchSize <= 0 {
+		return nil
+	}
+	b := &BatchSizer{min: config.MinBatchSize, max: config.MaxBatchSize, heapMB: heapAllocMB}
+	if b.min <= 0 || b.min > b.max {
+		b.min = min(1000, b.max)
+	}
+	target := config.TargetMemoryPercent
+	if target <= 0 || target > 100 {
+		target = DefaultTargetMemoryPercent
+	}
+	b.targetMB = uint64(config.MaxMemoryMB * target / 100)
+	b.size = max(b.min, min(config.BatchSize, b.max))
+	return b
+}
+
+// Size returns the current batch size
+func (b *BatchSizer) Size() int {
+	return b.size
+}
+
+// Observe reads heap usage after a batch and returns the next batch size
+func (b *BatchSizer) Observe() int {
+	used := b.heapMB()
+	switch {
+	case used > b.targetMB:
+		b.size = max(b.min, b.size/2)
+	case used < b.targetMB*3/4:
+		b.size = min(b.max, b.size+b.size/2)
+	}
+	return b.size
+}
+
+func heapAllocMB() uint64 {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	return m.HeapAlloc / 1024 / 1024
+}
@@ -0,0 +1,59 @@
+// Package ingestion - Adaptive batch sizer tests
+package ingestion
+
+import "testing"
+
+func TestBatchSizerFollowsMemory(t *testing.T) {
+	config := DefaultStreamingConfig()
+	config.MaxMemoryMB = 1000
+	config.MinBatchSize = 2000
+	config.MaxBatchSize = 40000
+	sizer := NewBatchSizer(config)
+	if sizer == nil || sizer.Size() != config.BatchSize {
+		t.Fatalf("expected adaptive batching to start at BatchSize, got %+v", sizer)
+	}
+
+	// The target is 70% of 1000MB: below 525MB grows, above 700MB shrinks
+	var heapMB uint64
+	sizer.heapMB = func() uint64 { return heapMB }
+	steps := []struct {
+		heapMB uint64
+		want   int
+	}{
+		{100, 15000},
+		{200, 22500},
+		{300, 33750},
+		{400, 40000}, // capped at MaxBatchSize
+		{600, 40000}, // comfortable band holds
+		{900, 20000},
+		{900, 10000},
+		{950, 5000},
+		{990, 2500},
+		{990, 2000}, // floored at MinBatchSize
+		{650, 2000},
+		{100, 3000},
+	}
+	for i, step := range steps {
+		heapMB = step.heapMB
+		if got := sizer.Observe(); got != step.want {
+			t.Fatalf("step %d at %dMB: batch size %d, want %d", i, step.heapMB, got, step.want)
+		}
+	}
+}
+
+func TestNewBatchSizerDefaults(t *testing.T) {
+	if sizer := NewBatchSizer(DefaultStreamingConfig()); sizer != nil {
+		t.Errorf("expected a fixed batch size without MaxBatchSize, got %+v", sizer)
+	}
+
+	config := DefaultStreamingConfig()
+	config.BatchSize = 100000
+	config.MaxBatchSize = 20000
+	sizer := NewBatchSizer(config)
+	if sizer.Size() != 20000 || sizer.min != 1000 {
+		t.Errorf("expected the start clamped to MaxBatchSize and a default minimum, got size %d min %d", sizer.Size(), sizer.min)
+	}
+	if want := uint64(config.MaxMemoryMB * DefaultTargetMemoryPercent / 100); sizer.targetMB != want {
+		t.Errorf("expected a %dMB target, got %d", want, sizer.targetMB)
+	}
+}
@@ -0,0 +1,323 @@
+// Package ingestion - Portable pricing bundles for moving snapshots between environments
+package ingestion
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"time"
+
+	"terraform-cost/db"
+
+	"github.com/google/uuid"
+)
+
+// bundleManifestName is the manifest's path inside a bundle
+const bundleManifestName = "manifest.json"
+
+// BundleManifest describes the snapshots in a pricing bundle
+type BundleManifest struct {
+	SchemaVersion string           `json:"schema_version"`
+	Provider      db.CloudProvider `json:"provider"`
+	CreatedAt     time.Time        `json:"created_at"`
+	Entries       []BundleEntry    `json:"entries"`
+}
+
+// BundleEntry is one region's snapshot within a bundle
+type BundleEntry struct {
+	File        string    `json:"file"`
+	Region      string    `json:"region"`
+	Alias       string    `json:"alias"`
+	SnapshotID  uuid.UUID `json:"snapshot_id"`  // ID in the exporting store
+	ContentHash string    `json:"content_hash"` // hash of the rates
+	FileHash    string    `json:"file_hash"`    // sha256 of the backup file
+	RateCount   int       `json:"rate_count"`
+}
+
+// ExportBundle packs every active snapshot for a provider into a tar.gz holding
+// one SnapshotBackup per region plus a manifest of hashes
+func ExportBundle(ctx context.Context, store db.PricingStore, cloud db.CloudProvider) (io.Reader, error) {
+	snapshots, err := store.ListSnapshots(ctx, cloud, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	manifest := BundleManifest{
+		SchemaVersion: "1.0",
+		Provider:      cloud,
+		CreatedAt:     time.Now().UTC(),
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+
+	for _, s := range snapshots {
+		if !s.IsActive {
+			continue
+		}
+
+		stored, err := store.GetRatesBySnapshot(ctx, s.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load rates for %s: %w", s.ID, err)
+		}
+
+		// Rehash rather than trusting the stored hash so the bundle is self-consistent
+		rates := RatesFromSnapshot(stored)
+		backup := &SnapshotBackup{
+			Provider:      s.Cloud,
+			Region:        s.Region,
+			Alias:         s.ProviderAlias,
+			Timestamp:     s.FetchedAt,
+			ContentHash:   calculateHash(rates),
+			RateCount:     len(rates),
+			SchemaVersion: BackupSchemaVersion,
+			Rates:         rates,
+		}
+		data, err := json.Marshal(backup)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal snapshot %s: %w", s.ID, err)
+		}
+
+		entry := BundleEntry{
+			File:        path.Join("snapshots", fmt.Sprintf("%s_%s.json", s.Region, s.ProviderAlias)),
+			Region:      s.Region,
+			Alias:       s.ProviderAlias,
+			SnapshotID:  s.ID,
+			ContentHash: backup.ContentHash,
+			FileHash:    sha256Hex(data),
+			RateCount:   backup.RateCount,
+		}
+		if err := writeTarFile(tw, entry.File, data); err != nil {
+			return nil, err
+		}
+		manifest.Entries = append(manifest.Entries, entry)
+	}
+
+	if len(manifest.Entries) == 0 {
+		return nil, fmt.Errorf("no active snapshots for %s", cloud)
+	}
+
+	sort.Slice(manifest.Entries, func(i, j int) bool { return manifest.Entries[i].File < manifest.Entries[j].File })
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	if err := writeTarFile(tw, bundleManifestName, data); err != nil {
+		return nil, err
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish bundle: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish bundle: %w", err)
+	}
+
+	return &buf, nil
+}
+
+// ImportBundle restores every snapshot in a bundle and activates it.
+// The whole bundle is verified before anything is written; each region is
+// then committed and activated in its own transaction. Snapshots already
+// present with the same hash are reactivated instead of duplicated.
+func ImportBundle(ctx context.Context, store db.PricingStore, r io.Reader) (*BundleManifest, error) {
+	manifest, backups, err := readBundle(r)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, entry := range manifest.Entries {
+		if err := restoreBackup(ctx, store, backups[i]); err != nil {
+			return nil, fmt.Errorf("failed to import %s/%s: %w", entry.Region, entry.Alias, err)
+		}
+	}
+
+	return manifest, nil
+}
+
+// readBundle extracts and verifies the manifest and backups, in manifest order
+func readBundle(r io.Reader) (*BundleManifest, []*SnapshotBackup, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open bundle: %w", err)
+	}
+	defer gz.Close()
+
+	files := make(map[string][]byte)
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read bundle: %w", err)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read %s: %w", hdr.Name, err)
+		}
+		files[hdr.Name] = data
+	}
+
+	data, ok := files[bundleManifestName]
+	if !ok {
+		return nil, nil, fmt.Errorf("bundle has no %s", bundleManifestName)
+	}
+	var manifest BundleManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+
+	mgr := NewBackupManager()
+	backups := make([]*SnapshotBackup, len(manifest.Entries))
+	for i, entry := range manifest.Entries {
+		data, ok := files[entry.File]
+		if !ok {
+			return nil, nil, fmt.Errorf("bundle is missing %s", entry.File)
+		}
+		if hash := sha256Hex(data); hash != entry.FileHash {
+			return nil, nil, fmt.Errorf("%s: file hash mismatch: manifest %s, got %s", entry.File, entry.FileHash, hash)
+		}
+
+		var decoded SnapshotBackup
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse %s: %w", entry.File, err)
+		}
+		backup, err := MigrateBackup(&decoded)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", entry.File, err)
+		}
+		if err := mgr.ValidateBackup(backup); err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", entry.File, err)
+		}
+		if backup.ContentHash != entry.ContentHash || backup.Provider != manifest.Provider {
+			return nil, nil, fmt.Errorf("%s does not match its manifest entry", entry.File)
+		}
+		backups[i] = backup
+	}
+
+	return &manifest, backups, nil
+}
+
+// restoreBackup commits a backup as a new active snapshot in one transaction
+func restoreBackup(ctx context.Context, store db.PricingStore, backup *SnapshotBackup) error {
+	unlock, err := store.LockIngestion(ctx, backup.Provider, backup.Region, backup.Alias, true)
+	if err != nil {
+		return fmt.Errorf("failed to take ingestion lock: %w", err)
+	}
+	defer unlock()
+
+	existing, err := store.FindSnapshotByHash(ctx, backup.Provider, backup.Region, backup.Alias, backup.ContentHash)
+	if err != nil {
+		return fmt.Errorf("failed to check for existing snapshot: %w", err)
+	}
+	if existing != nil {
+		if existing.IsActive {
+			return nil
+		}
+		return store.ActivateSnapshot(ctx, existing.ID)
+	}
+
+	snapshot := &db.PricingSnapshot{
+		ID:            uuid.New(),
+		Cloud:         backup.Provider,
+		Region:        backup.Region,
+		ProviderAlias: backup.Alias,
+		Source:        "bundle_import",
+		FetchedAt:     backup.Timestamp,
+		ValidFrom:     time.Now(),
+		Hash:          backup.ContentHash,
+		Version:       backup.SchemaVersion,
+		IsActive:      false, // Not active until transaction commits
+	}
+	return commitSnapshotRates(ctx, store, snapshot, backup.Rates)
+}
+
+// commitSnapshotRates creates snapshot with rates and activates it in one
+// transaction
+func commitSnapshotRates(ctx context.Context, store db.PricingStore, snapshot *db.PricingSnapshot, rates []NormalizedRate) error {
+	tx, err := store.BeginTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	committed := false
+	defer func() {
+		if !committed {
+			tx.Rollback()
+		}
+	}()
+
+	if err := tx.CreateSnapshot(ctx, snapshot); err != nil {
+		return fmt.Errorf("failed to create snapshot: %w", err)
+	}
+
+	for _, nr := range rates {
+		nr.RateKey.ID = uuid.New()
+		key, err := tx.UpsertRateKey(ctx, &nr.RateKey)
+		if err != nil {
+			return fmt.Errorf("failed to upsert rate key: %w", err)
+		}
+
+		rate := &db.PricingRate{
+			ID:         uuid.New(),
+			SnapshotID: snapshot.ID,
+			RateKeyID:  key.ID,
+			Unit:       nr.Unit,
+			Price:      nr.Price,
+			Currency:   nr.Currency,
+			Confidence: nr.Confidence,
+			TierMin:    nr.TierMin,
+			TierMax:    nr.TierMax,
+
+			EffectiveDate: nr.EffectiveDate,
+			Labels:        nr.Labels,
+		}
+		if err := tx.CreateRate(ctx, rate); err != nil {
+			return fmt.Errorf("failed to create rate: %w", err)
+		}
+	}
+
+	if err := tx.ActivateSnapshot(ctx, snapshot.ID); err != nil {
+		return fmt.Errorf("failed to activate snapshot: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit failed: %w", err)
+	}
+	committed = true
+	return nil
+}
+
+// writeTarFile adds a regular file to a tar archive
+func writeTarFile(tw *tar.Writer, name string, data []byte) error {
+	hdr := &tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(data)),
+		ModTime: time.Now(),
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+// sha256Hex returns the hex-encoded sha256 of data
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,112 @@
+// Package ingestion - Pricing bundle export/import tests
+package ingestion
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"terraform-cost/db"
+	"terraform-cost/db/memstore"
+)
+
+// seedSnapshot commits an active snapshot of n test rates for a region
+func seedSnapshot(t *testing.T, store db.PricingStore, region string, n int) {
+	t.Helper()
+	rates, _ := (&passthroughNormalizer{cloud: db.AWS}).Normalize(context.Background(), testRawPrices(region, n))
+	err := restoreBackup(context.Background(), store, &SnapshotBackup{
+		Provider:      db.AWS,
+		Region:        region,
+		Alias:         "default",
+		ContentHash:   calculateHash(rates),
+		RateCount:     len(rates),
+		SchemaVersion: "1.0",
+		Rates:         rates,
+	})
+	if err != nil {
+		t.Fatalf("failed to seed %s: %v", region, err)
+	}
+}
+
+func TestBundleRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	source := memstore.NewMemoryStore()
+	seedSnapshot(t, source, "us-east-1", 3)
+	seedSnapshot(t, source, "eu-west-1", 2)
+
+	bundle, err := ExportBundle(ctx, source, db.AWS)
+	if err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+
+	target := memstore.NewMemoryStore()
+	manifest, err := ImportBundle(ctx, target, bundle)
+	if err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+	if len(manifest.Entries) != 2 {
+		t.Fatalf("expected 2 manifest entries, got %d", len(manifest.Entries))
+	}
+
+	for _, region := range []string{"us-east-1", "eu-west-1"} {
+		src, _ := source.ListSnapshots(ctx, db.AWS, region)
+		dst, _ := target.ListSnapshots(ctx, db.AWS, region)
+		if len(dst) != 1 || !dst[0].IsActive {
+			t.Fatalf("%s: expected one active imported snapshot, got %+v", region, dst)
+		}
+		if dst[0].Hash != src[0].Hash {
+			t.Errorf("%s: hash %s, want %s", region, dst[0].Hash, src[0].Hash)
+		}
+		srcRates, _ := source.GetRatesBySnapshot(ctx, src[0].ID)
+		dstRates, _ := target.GetRatesBySnapshot(ctx, dst[0].ID)
+		if calculateHash(RatesFromSnapshot(dstRates)) != calculateHash(RatesFromSnapshot(srcRates)) {
+			t.Errorf("%s: imported rates differ from exported rates", region)
+		}
+	}
+}
+
+func TestImportBundleRejectsTamperedManifest(t *testing.T) {
+	ctx := context.Background()
+	source := memstore.NewMemoryStore()
+	seedSnapshot(t, source, "us-east-1", 2)
+
+	bundle, err := ExportBundle(ctx, source, db.AWS)
+	if err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	manifest, backups, err := readBundle(bundle)
+	if err != nil || len(backups) != 1 {
+		t.Fatalf("failed to read bundle: %v", err)
+	}
+
+	// Re-pack with a wrong file hash
+	manifest.Entries[0].FileHash = "deadbeef"
+	if _, _, err := readBundle(repack(t, manifest, backups)); err == nil {
+		t.Error("expected tampered file hash to be rejected")
+	}
+}
+
+// repack writes a manifest and its backups into a new bundle
+func repack(t *testing.T, manifest *BundleManifest, backups []*SnapshotBackup) io.Reader {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for i, entry := range manifest.Entries {
+		data, _ := json.Marshal(backups[i])
+		if err := writeTarFile(tw, entry.File, data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data, _ := json.Marshal(manifest)
+	if err := writeTarFile(tw, bundleManifestName, data); err != nil {
+		t.Fatal(err)
+	}
+	tw.Close()
+	gz.Close()
+	return &buf
+}
@@ -0,0 +1,121 @@
+// Package ingestion - Incremental ingestion of changed prices
+package ingestion
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"terraform-cost/db"
+)
+
+// changedPricesFetcher serves the prices FetchChangedSince returned, keeping
+// the wrapped fetcher's identity for the production guard and confidence
+type changedPricesFetcher struct {
+	PriceFetcher
+	prices []RawPrice
+}
+
+func (f *changedPricesFetcher) FetchRegion(ctx context.Context, region string) ([]RawPrice, error) {
+	return f.prices, nil
+}
+
+func (f *changedPricesFetcher) IsRealAPI() bool {
+	return isRealAPI(f.PriceFetcher)
+}
+
+// changedMergeNormalizer normalizes the changed prices and adds every carried
+// rate whose rate key and unit did not change. A changed key replaces all of
+// its carried tiers.
+type changedMergeNormalizer struct {
+	inner   PriceNormalizer
+	carried []NormalizedRate
+}
+
+func (n *changedMergeNormalizer) Cloud() db.CloudProvider {
+	return n.inner.Cloud()
+}
+
+func (n *changedMergeNormalizer) Normalize(ctx context.Context, raw []RawPrice) ([]NormalizedRate, error) {
+	changed, err := n.inner.Normalize(ctx, raw)
+	if err != nil {
+		return nil, err
+	}
+	replaced := make(map[string]bool, len(changed))
+	for _, r := range changed {
+		replaced[rateKeyString(r.RateKey)+"|"+r.Unit] = true
+	}
+	rates := make([]NormalizedRate, 0, len(changed)+len(n.carried))
+	rates = append(rates, changed...)
+	for _, r := range n.carried {
+		if !replaced[rateKeyString(r.RateKey)+"|"+r.Unit] {
+			rates = append(rates, r)
+		}
+	}
+	return rates, nil
+}
+
+// ExecuteIncremental fetches only the prices changed since the active
+// snapshot was fetched and commits them over that snapshot's other rates,
+// through the full lifecycle: validation, backup and commit. It runs a full
+// Execute instead when the fetcher is not a ChangedSinceFetcher, there is no
+// active snapshot, or the fetcher reports an incremental fetch is not
+// possible. With no changes the active snapshot is kept.
+func (l *Lifecycle) ExecuteIncremental(ctx context.Context, config *LifecycleConfig) (*LifecycleResult, error) {
+	if config == nil {
+		config = DefaultLifecycleConfig()
+	}
+	fetcher, ok := l.fetcher.(ChangedSinceFetcher)
+	if !ok {
+		l.log().Info("fetcher cannot fetch changes, fetching the whole region", "provider", config.Provider, "region", config.Region)
+		return l.Execute(ctx, config)
+	}
+
+	active, err := l.store.GetActiveSnapshot(ctx, config.Provider, config.Region, config.Alias)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active snapshot: %w", err)
+	}
+	if active == nil {
+		l.log().Info("no active snapshot to update, fetching the whole region", "provider", config.Provider, "region", config.Region)
+		return l.Execute(ctx, config)
+	}
+
+	changed, ok, err := fetcher.FetchChangedSince(ctx, config.Region, active.FetchedAt)
+	if err != nil {
+		return nil, fmt.Errorf("incremental fetch failed: %w", err)
+	}
+	if !ok {
+		l.log().Info("incremental fetch not possible, fetching the whole region", "provider", config.Provider, "region", config.Region, "since", active.FetchedAt)
+		return l.Execute(ctx, config)
+	}
+	if len(changed) == 0 {
+		l.log().Info("no prices changed since the active snapshot", "provider", config.Provider, "region", config.Region, "snapshot_id", active.ID)
+		return &LifecycleResult{
+			Success:     true,
+			Phase:       PhaseActive,
+			Message:     "no prices changed",
+			SnapshotID:  &active.ID,
+			ContentHash: active.Hash,
+		}, nil
+	}
+
+	stored, err := l.store.GetRatesBySnapshot(ctx, active.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load active snapshot rates: %w", err)
+	}
+
+	cfg := *config
+	cfg.Metadata = make(map[string]string, len(config.Metadata)+2)
+	for k, v := range config.Metadata {
+		cfg.Metadata[k] = v
+	}
+	cfg.Metadata["changed_since"] = active.FetchedAt.UTC().Format(time.RFC3339)
+	cfg.Metadata["updated_from"] = active.ID.String()
+
+	update := NewLifecycle(&changedPricesFetcher{PriceFetcher: l.fetcher, prices: changed},
+		&changedMergeNormalizer{inner: l.normalizer, carried: RatesFromSnapshot(stored)}, l.store)
+	update.validator = l.validator
+	update.backupMgr = l.backupMgr
+	update.logger = l.logger
+	return update.Execute(ctx, &cfg)
+}
@@ -0,0 +1,130 @@
+// Package ingestion - Incremental ingestion tests
+package ingestion
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"terraform-cost/db"
+	"terraform-cost/db/memstore"
+
+	"github.com/shopspring/decimal"
+)
+
+// changedSinceFetcher serves a full catalog, and the changed prices when
+// incremental is set
+type changedSinceFetcher struct {
+	staticFetcher
+	incremental bool
+	changed     []RawPrice
+	fullFetches int
+	since       []time.Time
+}
+
+func (f *changedSinceFetcher) FetchRegion(ctx context.Context, region string) ([]RawPrice, error) {
+	f.fullFetches++
+	return f.staticFetcher.FetchRegion(ctx, region)
+}
+
+func (f *changedSinceFetcher) FetchChangedSince(ctx context.Context, region string, since time.Time) ([]RawPrice, bool, error) {
+	f.since = append(f.since, since)
+	if !f.incremental {
+		return nil, false, nil
+	}
+	return f.changed, true, nil
+}
+
+func incrementalTestConfig(t *testing.T) *LifecycleConfig {
+	config := DefaultLifecycleConfig()
+	config.Provider = db.AWS
+	config.Region = "us-east-1"
+	config.Environment = "development"
+	config.BackupDir = t.TempDir()
+	return config
+}
+
+func TestExecuteIncrementalMergesChangedPrices(t *testing.T) {
+	ctx := context.Background()
+	store := memstore.NewMemoryStore()
+	config := incrementalTestConfig(t)
+
+	fetcher := &changedSinceFetcher{staticFetcher: staticFetcher{cloud: db.AWS, prices: append(testRawPrices("us-east-1", 5), lambdaRawPrices("0.0000166667")...)}}
+	lifecycle := NewLifecycle(fetcher, &passthroughNormalizer{cloud: db.AWS}, store)
+
+	// Without an active snapshot the whole region is fetched
+	full, err := lifecycle.ExecuteIncremental(ctx, config)
+	if err != nil || !full.Success || fetcher.fullFetches != 1 || len(fetcher.since) != 0 {
+		t.Fatalf("expected a full first ingestion, got %+v %v (%d full fetches)", full, err, fetcher.fullFetches)
+	}
+	active, _ := store.GetSnapshot(ctx, *full.SnapshotID)
+
+	// Only the x86 Lambda price changed upstream
+	fetcher.incremental = true
+	fetcher.changed = lambdaRawPrices("0.0000133334")[:1]
+	result, err := lifecycle.ExecuteIncremental(ctx, config)
+	if err != nil || !result.Success {
+		t.Fatalf("incremental ingestion failed: %v %+v", err, result)
+	}
+	if fetcher.fullFetches != 1 || len(fetcher.since) != 1 || !fetcher.since[0].Equal(active.FetchedAt) {
+		t.Fatalf("expected one incremental fetch since %s, got %v and %d full fetches", active.FetchedAt, fetcher.since, fetcher.fullFetches)
+	}
+	if result.SnapshotID == nil || *result.SnapshotID == *full.SnapshotID || result.NormalizedCount != full.NormalizedCount {
+		t.Fatalf("expected a new snapshot with the same %d rates, got %s with %d", full.NormalizedCount, result.SnapshotID, result.NormalizedCount)
+	}
+
+	rates, _ := store.GetRatesBySnapshot(ctx, *result.SnapshotID)
+	for _, r := range rates {
+		want := "0.023"
+		if r.RateKey.Service == "AWSLambda" {
+			want = "0.0000166667"
+			if r.RateKey.Attributes["arch"] == "x86" {
+				want = "0.0000133334"
+			}
+		}
+		if !r.Rate.Price.Equal(decimal.RequireFromString(want)) {
+			t.Errorf("%s %v = %s, want %s", r.RateKey.Service, r.RateKey.Attributes, r.Rate.Price, want)
+		}
+	}
+	snapshot, _ := store.GetSnapshot(ctx, *result.SnapshotID)
+	if snapshot.Metadata["updated_from"] != full.SnapshotID.String() || snapshot.Metadata["changed_since"] == "" {
+		t.Errorf("expected incremental provenance in metadata, got %v", snapshot.Metadata)
+	}
+
+	// Nothing changed: the active snapshot stays
+	fetcher.changed = nil
+	unchanged, err := lifecycle.ExecuteIncremental(ctx, config)
+	if err != nil || !unchanged.Success || *unchanged.SnapshotID != *result.SnapshotID {
+		t.Errorf("expected the active snapshot to be kept, got %+v %v", unchanged, err)
+	}
+}
+
+func TestExecuteIncrementalFallsBackToFullFetch(t *testing.T) {
+	ctx := context.Background()
+	store := memstore.NewMemoryStore()
+	config := incrementalTestConfig(t)
+	prices := testRawPrices("us-east-1", 5)
+
+	// The fetcher supports incremental fetching but cannot do it this time
+	declining := &changedSinceFetcher{staticFetcher: staticFetcher{cloud: db.AWS, prices: prices}}
+	lifecycle := NewLifecycle(declining, &passthroughNormalizer{cloud: db.AWS}, store)
+	if _, err := lifecycle.Execute(ctx, config); err != nil {
+		t.Fatal(err)
+	}
+	config.BackupDir = t.TempDir() // same content, so keep the backups apart
+	result, err := lifecycle.ExecuteIncremental(ctx, config)
+	if err != nil || !result.Success {
+		t.Fatalf("fallback ingestion failed: %v %+v", err, result)
+	}
+	if len(declining.since) != 1 || declining.fullFetches != 2 {
+		t.Errorf("expected a declined incremental fetch then a full one, got %d incremental and %d full", len(declining.since), declining.fullFetches)
+	}
+
+	// A fetcher without the interface always fetches everything
+	plain := NewLifecycle(&staticFetcher{cloud: db.AWS, prices: prices}, &passthroughNormalizer{cloud: db.AWS}, store)
+	config.BackupDir = t.TempDir()
+	result, err = plain.ExecuteIncremental(ctx, config)
+	if err != nil || !result.Success || result.NormalizedCount != len(prices) {
+		t.Errorf("expected a full ingestion, got %+v %v", result, err)
+	}
+}
@@ -0,0 +1,67 @@
+// Package ingestion - Source-based confidence for normalized rates
+package ingestion
+
+import (
+	"context"
+	"terraform-cost/db"
+)
+
+const (
+	// RealAPIConfidence is the confidence of rates fetched from a provider API
+	RealAPIConfidence = 1.0
+
+	// DefaultStubConfidence is the confidence of rates from files, stubs and
+	// other synthetic sources
+	DefaultStubConfidence = 0.5
+)
+
+// ConfidenceNormalizer scales the confidence of every rate by a base
+// confidence reflecting where the raw prices came from
+type ConfidenceNormalizer struct {
+	inner PriceNormalizer
+	base  float64
+}
+
+// NewConfidenceNormalizer creates a normalizer whose rates carry base
+// confidence. base is clamped to [0, 1].
+func NewConfidenceNormalizer(inner PriceNormalizer, base float64) *ConfidenceNormalizer {
+	if base < 0 {
+		base = 0
+	}
+	if base > 1 {
+		base = 1
+	}
+	return &ConfidenceNormalizer{inner: inner, base: base}
+}
+
+func (n *ConfidenceNormalizer) Cloud() db.CloudProvider {
+	return n.inner.Cloud()
+}
+
+// Normalize normalizes with the inner normalizer, then scales confidence
+func (n *ConfidenceNormalizer) Normalize(ctx context.Context, raw []RawPrice) ([]NormalizedRate, error) {
+	rates, err := n.inner.Normalize(ctx, raw)
+	if err != nil {
+		return nil, err
+	}
+	for i := range rates {
+		rates[i].Confidence *= n.base
+	}
+	return rates, nil
+}
+
+// sourceNormalizer wraps normalizer with stubConfidence unless fetcher
+// calls a real provider API
+func sourceNormalizer(fetcher PriceFetcher, normalizer PriceNormalizer, stubConfidence float64) PriceNormalizer {
+	if isRealAPI(fetcher) {
+		return normalizer
+	}
+	return NewConfidenceNormalizer(normalizer, stubConfidence)
+}
+
+// isRealAPI reports whether fetcher passes the real API guard;
+// fetchers that do not implement RealAPIFetcher are trusted
+func isRealAPI(fetcher PriceFetcher) bool {
+	realAPI, ok := fetcher.(RealAPIFetcher)
+	return !ok || realAPI.IsRealAPI()
+}
@@ -0,0 +1,61 @@
+// Package ingestion - Source-based confidence tests
+package ingestion
+
+import (
+	"context"
+	"testing"
+
+	"terraform-cost/db"
+	"terraform-cost/db/memstore"
+)
+
+// stubFetcher serves static prices but reports a synthetic source
+type stubFetcher struct {
+	staticFetcher
+}
+
+func (f *stubFetcher) IsRealAPI() bool { return false }
+
+func TestStubSourcedRatesGetReducedConfidence(t *testing.T) {
+	ingest := func(fetcher PriceFetcher, stubConfidence float64) []db.SnapshotRate {
+		t.Helper()
+		store := memstore.NewMemoryStore()
+		config := DefaultLifecycleConfig()
+		config.Provider = db.AWS
+		config.Region = "us-east-1"
+		config.Environment = "development"
+		config.BackupDir = t.TempDir()
+		config.StubConfidence = stubConfidence
+
+		result, err := NewLifecycle(fetcher, &passthroughNormalizer{cloud: db.AWS}, store).Execute(context.Background(), config)
+		if err != nil || !result.Success {
+			t.Fatalf("ingestion failed: %v %+v", err, result)
+		}
+		rates, err := store.GetRatesBySnapshot(context.Background(), *result.SnapshotID)
+		if err != nil || len(rates) == 0 {
+			t.Fatalf("expected committed rates: %v", err)
+		}
+		return rates
+	}
+	assertConfidence := func(rates []db.SnapshotRate, want float64) {
+		t.Helper()
+		for _, r := range rates {
+			if r.Rate.Confidence != want {
+				t.Fatalf("expected confidence %v, got %v", want, r.Rate.Confidence)
+			}
+		}
+	}
+
+	prices := testRawPrices("us-east-1", 5)
+	assertConfidence(ingest(&staticFetcher{cloud: db.AWS, prices: prices}, 0), RealAPIConfidence)
+	assertConfidence(ingest(&stubFetcher{staticFetcher{cloud: db.AWS, prices: prices}}, 0), DefaultStubConfidence)
+	assertConfidence(ingest(&stubFetcher{staticFetcher{cloud: db.AWS, prices: prices}}, 0.25), 0.25)
+}
+
+func TestConfidenceNormalizerClampsBase(t *testing.T) {
+	raw := testRawPrices("us-east-1", 1)
+	rates, err := NewConfidenceNormalizer(&passthroughNormalizer{cloud: db.AWS}, 3).Normalize(context.Background(), raw)
+	if err != nil || rates[0].Confidence != 1.0 {
+		t.Errorf("expected base above 1 to keep full confidence, got %+v %v", rates, err)
+	}
+}
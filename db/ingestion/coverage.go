@@ -3,6 +3,7 @@ package ingestion
 
 import (
 	"fmt"
+	"sort"
 
 	"terraform-cost/db"
 
@@ -169,6 +170,7 @@ type CoverageReport struct {
 	IsComplete       bool
 	ServiceReports   []ServiceReport
 	MissingServices  []string
+	UnmappedServices []string // in the data but without a contract or allowlist
 }
 
 // ServiceReport is a per-service coverage summary
@@ -230,9 +232,44 @@ func (ct *CoverageTracker) GenerateReport(snapshot *db.PricingSnapshot, rates []
 		report.ServiceReports = append(report.ServiceReports, sr)
 	}
 
+	contracts := make([]IngestionContract, 0, len(ct.contracts))
+	for _, c := range ct.contracts {
+		contracts = append(contracts, c)
+	}
+	report.UnmappedServices = ReportUnmappedServices(rates, ct.allowlists, contracts)
+
 	return report
 }
 
+// ReportUnmappedServices lists, sorted, the services present in rates that
+// have neither an ingestion contract nor a dimension allowlist. Their rates
+// pass through unfiltered, so they are candidates for new configuration.
+func ReportUnmappedServices(rates []NormalizedRate, allowlist *DimensionAllowlist, contracts []IngestionContract) []string {
+	contracted := make(map[string]bool, len(contracts))
+	for _, c := range contracts {
+		contracted[string(c.Cloud)+":"+c.Service] = true
+	}
+
+	seen := make(map[string]bool)
+	var unmapped []string
+	for _, r := range rates {
+		key := string(r.RateKey.Cloud) + ":" + r.RateKey.Service
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		if contracted[key] {
+			continue
+		}
+		if allowlist != nil && allowlist.GetAllowed(r.RateKey.Cloud, r.RateKey.Service) != nil {
+			continue
+		}
+		unmapped = append(unmapped, r.RateKey.Service)
+	}
+	sort.Strings(unmapped)
+	return unmapped
+}
+
 // String returns a summary string
 func (r *CoverageReport) String() string {
 	status := "INCOMPLETE"
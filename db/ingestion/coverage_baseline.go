@@ -0,0 +1,127 @@
+// Package ingestion - Committed coverage baseline
+// The previous snapshot only catches a sudden drop; a baseline file records
+// the per-service rate counts a region is expected to have, so a gradual
+// erosion across many runs fails validation too.
+package ingestion
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"terraform-cost/db"
+)
+
+// DefaultCoverageBaselineFile is the conventional name of the baseline file
+const DefaultCoverageBaselineFile = "coverage_baseline.json"
+
+// CoverageBaseline holds the expected per-service rate counts of each
+// cloud/region, keyed by "<cloud>/<region>"
+type CoverageBaseline struct {
+	Regions map[string]*RegionBaseline `json:"regions"`
+}
+
+// RegionBaseline is the expected rate count of every service in one region
+type RegionBaseline struct {
+	Cloud     db.CloudProvider `json:"cloud"`
+	Region    string           `json:"region"`
+	UpdatedAt time.Time        `json:"updated_at"`
+	Services  map[string]int   `json:"services"`
+}
+
+// LoadCoverageBaseline reads a baseline file; a missing file is an empty baseline
+func LoadCoverageBaseline(path string) (*CoverageBaseline, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &CoverageBaseline{Regions: make(map[string]*RegionBaseline)}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read coverage baseline: %w", err)
+	}
+	var baseline CoverageBaseline
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return nil, fmt.Errorf("failed to parse coverage baseline %s: %w", path, err)
+	}
+	if baseline.Regions == nil {
+		baseline.Regions = make(map[string]*RegionBaseline)
+	}
+	return &baseline, nil
+}
+
+// Save writes the baseline as indented JSON, replacing the file atomically
+func (b *CoverageBaseline) Save(path string) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Lookup returns the baseline of cloud/region, or nil when none is recorded
+func (b *CoverageBaseline) Lookup(cloud db.CloudProvider, region string) *RegionBaseline {
+	return b.Regions[baselineKey(cloud, region)]
+}
+
+// Update records the per-service rate counts of rates as the baseline of
+// cloud/region, replacing any previous entry
+func (b *CoverageBaseline) Update(cloud db.CloudProvider, region string, rates []NormalizedRate, now time.Time) *RegionBaseline {
+	entry := &RegionBaseline{
+		Cloud:     cloud,
+		Region:    region,
+		UpdatedAt: now.UTC(),
+		Services:  ServiceRateCounts(rates),
+	}
+	b.Regions[baselineKey(cloud, region)] = entry
+	return entry
+}
+
+// ServiceRateCounts counts rates per service
+func ServiceRateCounts(rates []NormalizedRate) map[string]int {
+	counts := make(map[string]int)
+	for _, r := range rates {
+		counts[r.RateKey.Service]++
+	}
+	return counts
+}
+
+func baselineKey(cloud db.CloudProvider, region string) string {
+	return string(cloud) + "/" + region
+}
+
+// ValidateAgainstBaseline fails when any service in baseline has fewer rates
+// than its expected count less tolerancePercent. A service missing entirely
+// counts as 0 rates; services absent from the baseline are not checked.
+func (v *IngestionValidator) ValidateAgainstBaseline(rates []NormalizedRate, baseline *RegionBaseline, tolerancePercent float64) error {
+	if baseline == nil {
+		return nil
+	}
+	counts := ServiceRateCounts(rates)
+
+	var regressions []string
+	for service, expected := range baseline.Services {
+		minimum := float64(expected) * (1 - tolerancePercent/100)
+		if got := counts[service]; float64(got) < minimum {
+			regressions = append(regressions, fmt.Sprintf("%s: %d rates, baseline %d", service, got, expected))
+		}
+	}
+	if len(regressions) > 0 {
+		sort.Strings(regressions)
+		return fmt.Errorf("coverage below baseline for %s/%s (tolerance %.1f%%): %s",
+			baseline.Cloud, baseline.Region, tolerancePercent, strings.Join(regressions, "; "))
+	}
+	return nil
+}
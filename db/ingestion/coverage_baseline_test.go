@@ -0,0 +1,79 @@
+// Package ingestion - Coverage baseline tests
+package ingestion
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"terraform-cost/db"
+	"terraform-cost/db/memstore"
+)
+
+func TestLifecycleCoverageBaseline(t *testing.T) {
+	path := filepath.Join(t.TempDir(), DefaultCoverageBaselineFile)
+	run := func(n int, update bool) *LifecycleResult {
+		config := DefaultLifecycleConfig()
+		config.Provider = db.AWS
+		config.Region = "us-east-1"
+		config.Environment = "development"
+		config.BackupDir = t.TempDir()
+		config.CoverageBaselinePath = path
+		config.BaselineTolerancePercent = 15
+		config.UpdateCoverageBaseline = update
+		fetcher := &staticFetcher{cloud: db.AWS, prices: testRawPrices("us-east-1", n)}
+		result, _ := NewLifecycle(fetcher, &passthroughNormalizer{cloud: db.AWS}, memstore.NewMemoryStore()).Execute(context.Background(), config)
+		return result
+	}
+
+	// Without a recorded baseline there is nothing to compare against
+	if result := run(10, false); !result.Success {
+		t.Fatalf("expected a run without a baseline to pass: %+v", result)
+	}
+
+	// Baseline update records the committed counts
+	if result := run(10, true); !result.Success {
+		t.Fatalf("baseline update run failed: %+v", result)
+	}
+	baseline, err := LoadCoverageBaseline(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := baseline.Lookup(db.AWS, "us-east-1")
+	if entry == nil || entry.Services["TestStorage"] != 10 {
+		t.Fatalf("expected a baseline of 10 TestStorage rates, got %+v", entry)
+	}
+
+	// Pass: within the 15% tolerance
+	if result := run(9, false); !result.Success {
+		t.Errorf("expected 9 of 10 rates to pass, got %+v", result)
+	}
+
+	// Regression: below the tolerance
+	result := run(8, false)
+	if result.Success || !strings.Contains(result.Error, "TestStorage: 8 rates, baseline 10") {
+		t.Errorf("expected a baseline regression, got %+v", result)
+	}
+
+	// A fresh update accepts the smaller count as the new baseline
+	if result := run(8, true); !result.Success {
+		t.Fatalf("baseline update run failed: %+v", result)
+	}
+	if result := run(8, false); !result.Success {
+		t.Errorf("expected the updated baseline to pass, got %+v", result)
+	}
+}
+
+func TestValidateAgainstBaselineMissingService(t *testing.T) {
+	baseline := &CoverageBaseline{Regions: make(map[string]*RegionBaseline)}
+	rates, _ := (&passthroughNormalizer{cloud: db.AWS}).Normalize(context.Background(), testRawPrices("us-east-1", 3))
+	entry := baseline.Update(db.AWS, "us-east-1", rates, time.Now())
+	entry.Services["AmazonEC2"] = 100
+
+	err := NewIngestionValidator().ValidateAgainstBaseline(rates, entry, 50)
+	if err == nil || !strings.Contains(err.Error(), "AmazonEC2: 0 rates, baseline 100") || strings.Contains(err.Error(), "TestStorage") {
+		t.Errorf("expected only the missing service to regress, got %v", err)
+	}
+}
@@ -0,0 +1,38 @@
+// Package ingestion - Coverage tracking tests
+package ingestion
+
+import (
+	"reflect"
+	"testing"
+
+	"terraform-cost/db"
+
+	"github.com/google/uuid"
+)
+
+func TestReportUnmappedServices(t *testing.T) {
+	rate := func(cloud db.CloudProvider, service string) NormalizedRate {
+		return NormalizedRate{RateKey: db.RateKey{Cloud: cloud, Service: service, Region: "us-east-1"}}
+	}
+	rates := []NormalizedRate{
+		rate(db.AWS, "AmazonEC2"),        // contract and allowlist
+		rate(db.AWS, "AmazonEC2"),        // repeated
+		rate(db.AWS, "AmazonMQ"),         // allowlist only
+		rate(db.AWS, "AWSShield"),        // neither
+		rate(db.AWS, "AmazonCloudWatch"), // neither
+		rate(db.GCP, "AmazonMQ"),         // allowlisted for AWS, not GCP
+	}
+	allowlist := NewDimensionAllowlist()
+	allowlist.Add(db.AWS, "AmazonMQ", "instance_type", true, 100)
+
+	got := ReportUnmappedServices(rates, allowlist, DefaultContracts())
+	want := []string{"AWSShield", "AmazonCloudWatch", "AmazonMQ"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ReportUnmappedServices = %v, want %v", got, want)
+	}
+
+	report := NewCoverageTracker().GenerateReport(&db.PricingSnapshot{ID: uuid.New(), Cloud: db.AWS, Region: "us-east-1"}, rates[:5])
+	if want := []string{"AWSShield", "AmazonCloudWatch", "AmazonMQ"}; !reflect.DeepEqual(report.UnmappedServices, want) {
+		t.Errorf("expected the coverage report to list %v, got %v", want, report.UnmappedServices)
+	}
+}
@@ -0,0 +1,86 @@
+// Package ingestion - Maintained DigitalOcean price catalog
+// Source: https://www.digitalocean.com/pricing (USD, flat across regions)
+package ingestion
+
+import "fmt"
+
+// doRegions lists DigitalOcean datacenter regions
+var doRegions = []string{
+	"nyc1", "nyc3", "sfo2", "sfo3", "tor1",
+	"ams3", "fra1", "lon1",
+	"sgp1", "blr1", "syd1",
+}
+
+// doVolumePricePerGB is the block storage volume price per GB-month
+const doVolumePricePerGB = 0.10
+
+// doDropletCatalog is used when no API token is configured
+var doDropletCatalog = []DOSize{
+	// Basic
+	{Slug: "s-1vcpu-512mb-10gb", VCPUs: 1, Memory: 512, Disk: 10, PriceMonthly: 4, PriceHourly: 0.00595, Regions: doRegions, Available: true},
+	{Slug: "s-1vcpu-1gb", VCPUs: 1, Memory: 1024, Disk: 25, PriceMonthly: 6, PriceHourly: 0.00893, Regions: doRegions, Available: true},
+	{Slug: "s-1vcpu-2gb", VCPUs: 1, Memory: 2048, Disk: 50, PriceMonthly: 12, PriceHourly: 0.01786, Regions: doRegions, Available: true},
+	{Slug: "s-2vcpu-2gb", VCPUs: 2, Memory: 2048, Disk: 60, PriceMonthly: 18, PriceHourly: 0.02679, Regions: doRegions, Available: true},
+	{Slug: "s-2vcpu-4gb", VCPUs: 2, Memory: 4096, Disk: 80, PriceMonthly: 24, PriceHourly: 0.03571, Regions: doRegions, Available: true},
+	{Slug: "s-4vcpu-8gb", VCPUs: 4, Memory: 8192, Disk: 160, PriceMonthly: 48, PriceHourly: 0.07143, Regions: doRegions, Available: true},
+	{Slug: "s-8vcpu-16gb", VCPUs: 8, Memory: 16384, Disk: 320, PriceMonthly: 96, PriceHourly: 0.14286, Regions: doRegions, Available: true},
+
+	// Premium Intel
+	{Slug: "s-1vcpu-1gb-intel", VCPUs: 1, Memory: 1024, Disk: 25, PriceMonthly: 8, PriceHourly: 0.01190, Regions: doRegions, Available: true},
+	{Slug: "s-2vcpu-4gb-intel", VCPUs: 2, Memory: 4096, Disk: 120, PriceMonthly: 32, PriceHourly: 0.04762, Regions: doRegions, Available: true},
+
+	// Dedicated CPU
+	{Slug: "g-2vcpu-8gb", VCPUs: 2, Memory: 8192, Disk: 25, PriceMonthly: 63, PriceHourly: 0.09375, Regions: doRegions, Available: true},
+	{Slug: "c-2", VCPUs: 2, Memory: 4096, Disk: 25, PriceMonthly: 42, PriceHourly: 0.06250, Regions: doRegions, Available: true},
+	{Slug: "m-2vcpu-16gb", VCPUs: 2, Memory: 16384, Disk: 50, PriceMonthly: 84, PriceHourly: 0.12500, Regions: doRegions, Available: true},
+}
+
+// doDatabaseSizes are single-node managed database prices per month
+var doDatabaseSizes = []struct {
+	Slug    string
+	Monthly float64
+}{
+	{"db-s-1vcpu-1gb", 15},
+	{"db-s-1vcpu-2gb", 30},
+	{"db-s-2vcpu-4gb", 60},
+	{"db-s-4vcpu-8gb", 120},
+	{"db-s-6vcpu-16gb", 240},
+	{"db-s-8vcpu-32gb", 480},
+}
+
+// doDatabaseEngines share the same size pricing
+var doDatabaseEngines = []string{"pg", "mysql", "valkey"}
+
+// doVolumePrices returns block storage volume pricing for a region
+func doVolumePrices(region string) []RawPrice {
+	return []RawPrice{{
+		SKU:           "volume",
+		ServiceCode:   doVolumesService,
+		ProductFamily: "Storage",
+		Region:        region,
+		Unit:          "GB-month",
+		PricePerUnit:  fmt.Sprintf("%.10f", doVolumePricePerGB),
+		Currency:      "USD",
+		Attributes:    map[string]string{"volumeType": "block"},
+	}}
+}
+
+// doDatabasePrices returns managed database node pricing for a region
+func doDatabasePrices(region string) []RawPrice {
+	prices := make([]RawPrice, 0, len(doDatabaseSizes)*len(doDatabaseEngines))
+	for _, engine := range doDatabaseEngines {
+		for _, size := range doDatabaseSizes {
+			prices = append(prices, RawPrice{
+				SKU:           engine + "-" + size.Slug,
+				ServiceCode:   doDatabasesService,
+				ProductFamily: "Database",
+				Region:        region,
+				Unit:          "month",
+				PricePerUnit:  fmt.Sprintf("%.10f", size.Monthly),
+				Currency:      "USD",
+				Attributes:    map[string]string{"slug": size.Slug, "engine": engine},
+			})
+		}
+	}
+	return prices
+}
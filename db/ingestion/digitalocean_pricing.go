@@ -0,0 +1,394 @@
+// Package ingestion - DigitalOcean pricing fetcher
+// Droplet prices come from the DigitalOcean sizes API; volumes and managed
+// databases have no pricing API and are served from a maintained catalog.
+package ingestion
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"terraform-cost/db"
+)
+
+// DigitalOcean service names used as RateKey.Service
+const (
+	doDropletsService  = "Droplets"
+	doVolumesService   = "Volumes"
+	doDatabasesService = "Managed Databases"
+)
+
+// DOPricingFetcher fetches DigitalOcean pricing
+type DOPricingFetcher struct {
+	httpClient   *http.Client
+	baseURL      string
+	token        string
+	servicesList []string
+	logger       *slog.Logger
+}
+
+// DOPricingConfig configures the DigitalOcean pricing fetcher
+type DOPricingConfig struct {
+	// HTTPTimeout for API calls
+	HTTPTimeout time.Duration
+
+	// HTTPClient, when set, is used for API calls instead of a client
+	// built from HTTPTimeout, so fetchers can share one configured client
+	HTTPClient *http.Client
+
+	// Token is a DigitalOcean API token; without it droplet
+	// prices fall back to the static catalog
+	Token string
+
+	// Services to fetch
+	Services []string
+}
+
+// DefaultDOPricingConfig returns production defaults, reading the API
+// token from DIGITALOCEAN_TOKEN
+func DefaultDOPricingConfig() *DOPricingConfig {
+	return &DOPricingConfig{
+		HTTPTimeout: 60 * time.Second,
+		Token:       os.Getenv("DIGITALOCEAN_TOKEN"),
+		Services:    AllDOServices(),
+	}
+}
+
+// AllDOServices returns the DigitalOcean services with pricing support
+func AllDOServices() []string {
+	return []string{
+		doDropletsService,
+		doVolumesService,
+		doDatabasesService,
+	}
+}
+
+// NewDOPricingFetcher creates a DigitalOcean pricing fetcher
+func NewDOPricingFetcher(cfg *DOPricingConfig) *DOPricingFetcher {
+	if cfg == nil {
+		cfg = DefaultDOPricingConfig()
+	}
+
+	return &DOPricingFetcher{
+		httpClient:   httpClientOrDefault(cfg.HTTPClient, cfg.HTTPTimeout),
+		baseURL:      "https://api.digitalocean.com/v2",
+		token:        cfg.Token,
+		servicesList: cfg.Services,
+	}
+}
+
+// Cloud implements PriceFetcher
+func (f *DOPricingFetcher) Cloud() db.CloudProvider {
+	return db.DigitalOcean
+}
+
+// IsRealAPI implements RealAPIFetcher. Droplet prices only come from
+// the live API when a token is configured.
+func (f *DOPricingFetcher) IsRealAPI() bool {
+	return f.token != ""
+}
+
+// SupportedRegions returns all DigitalOcean datacenter regions
+func (f *DOPricingFetcher) SupportedRegions() []string {
+	return append([]string(nil), doRegions...)
+}
+
+// SupportedServices returns all supported services
+func (f *DOPricingFetcher) SupportedServices() []string {
+	return f.servicesList
+}
+
+// SetAllowedServices restricts fetching to the given services
+func (f *DOPricingFetcher) SetAllowedServices(services []string) {
+	if len(services) > 0 {
+		f.servicesList = services
+	}
+}
+
+// SetLogger sets the logger used for fetch warnings
+func (f *DOPricingFetcher) SetLogger(logger *slog.Logger) {
+	f.logger = logger
+}
+
+// SetTransport routes API calls through rt (see TransportConfig), keeping
+// the client's timeout
+func (f *DOPricingFetcher) SetTransport(rt http.RoundTripper) {
+	client := *f.httpClient
+	client.Transport = rt
+	f.httpClient = &client
+}
+
+// FetchRegion returns droplet, volume and managed database pricing for a region.
+// DigitalOcean prices are flat across regions; the region filters droplet
+// sizes to those offered there and labels the rates.
+func (f *DOPricingFetcher) FetchRegion(ctx context.Context, region string) ([]RawPrice, error) {
+	var allPrices []RawPrice
+	failures := &serviceFailures{provider: db.DigitalOcean, region: region, expected: len(f.servicesList)}
+
+	for _, service := range f.servicesList {
+		switch service {
+		case doDropletsService:
+			prices, err := f.fetchDroplets(ctx, region)
+			if err != nil {
+				loggerOrDefault(f.logger).Warn("failed to fetch service pricing", "provider", "digitalocean", "region", region, "service", service, "error", err)
+				failures.add(service, err)
+				continue
+			}
+			allPrices = append(allPrices, prices...)
+		case doVolumesService:
+			allPrices = append(allPrices, doVolumePrices(region)...)
+		case doDatabasesService:
+			allPrices = append(allPrices, doDatabasePrices(region)...)
+		default:
+			loggerOrDefault(f.logger).Warn("unsupported service", "provider", "digitalocean", "region", region, "service", service)
+		}
+	}
+
+	if len(allPrices) == 0 {
+		if err := failures.err(); err != nil {
+			return nil, fmt.Errorf("failed to fetch any pricing for DigitalOcean region %s: %w", region, err)
+		}
+		return nil, fmt.Errorf("failed to fetch any pricing for DigitalOcean region %s", region)
+	}
+
+	return allPrices, failures.err()
+}
+
+// fetchDroplets lists droplet sizes from the API, or the catalog without a token
+func (f *DOPricingFetcher) fetchDroplets(ctx context.Context, region string) ([]RawPrice, error) {
+	if f.token == "" {
+		loggerOrDefault(f.logger).Warn("no API token, using static droplet catalog", "provider", "digitalocean", "region", region)
+		return dropletPrices(doDropletCatalog, region), nil
+	}
+
+	var sizes []DOSize
+	next := f.baseURL + "/sizes?per_page=200"
+	for next != "" {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		page, err := f.fetchSizesPage(ctx, next)
+		if err != nil {
+			return nil, err
+		}
+		sizes = append(sizes, page.Sizes...)
+		next = page.Links.Pages.Next
+	}
+
+	loggerOrDefault(f.logger).Debug("fetched droplet sizes", "provider", "digitalocean", "region", region, "rate_count", len(sizes))
+	return dropletPrices(sizes, region), nil
+}
+
+// fetchSizesPage fetches a single page of droplet sizes
+func (f *DOPricingFetcher) fetchSizesPage(ctx context.Context, pageURL string) (*DOSizesResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", pageURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+f.token)
+
+	resp, err := f.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch sizes: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("DigitalOcean API returned status %d", resp.StatusCode)
+	}
+
+	var page DOSizesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &page, nil
+}
+
+// dropletPrices converts sizes offered in a region into hourly raw prices
+func dropletPrices(sizes []DOSize, region string) []RawPrice {
+	var prices []RawPrice
+	for _, s := range sizes {
+		if !s.Available || !s.offeredIn(region) || s.PriceHourly == 0 {
+			continue
+		}
+		prices = append(prices, RawPrice{
+			SKU:           s.Slug,
+			ServiceCode:   doDropletsService,
+			ProductFamily: "Compute",
+			Region:        region,
+			Unit:          "hour",
+			PricePerUnit:  fmt.Sprintf("%.10f", s.PriceHourly),
+			Currency:      "USD",
+			Attributes: map[string]string{
+				"slug":   s.Slug,
+				"vcpus":  strconv.Itoa(s.VCPUs),
+				"memory": strconv.Itoa(s.Memory),
+				"disk":   strconv.Itoa(s.Disk),
+			},
+		})
+	}
+	return prices
+}
+
+// DOSizesResponse represents the DigitalOcean /v2/sizes response
+type DOSizesResponse struct {
+	Sizes []DOSize `json:"sizes"`
+	Links struct {
+		Pages struct {
+			Next string `json:"next"`
+		} `json:"pages"`
+	} `json:"links"`
+}
+
+// DOSize represents a droplet size
+type DOSize struct {
+	Slug         string   `json:"slug"`
+	Memory       int      `json:"memory"` // MiB
+	VCPUs        int      `json:"vcpus"`
+	Disk         int      `json:"disk"` // GB
+	PriceMonthly float64  `json:"price_monthly"`
+	PriceHourly  float64  `json:"price_hourly"`
+	Regions      []string `json:"regions"`
+	Available    bool     `json:"available"`
+}
+
+// offeredIn reports whether the size can be created in a region
+func (s DOSize) offeredIn(region string) bool {
+	for _, r := range s.Regions {
+		if r == region {
+			return true
+		}
+	}
+	return false
+}
+
+// DOPricingNormalizer normalizes raw DigitalOcean pricing to canonical format
+type DOPricingNormalizer struct{}
+
+// NewDOPricingNormalizer creates a DigitalOcean normalizer
+func NewDOPricingNormalizer() *DOPricingNormalizer {
+	return &DOPricingNormalizer{}
+}
+
+// Cloud implements PriceNormalizer
+func (n *DOPricingNormalizer) Cloud() db.CloudProvider {
+	return db.DigitalOcean
+}
+
+// Normalize converts raw DigitalOcean prices to normalized rates.
+// Pricing is flat, so no tiers are produced.
+func (n *DOPricingNormalizer) Normalize(ctx context.Context, raw []RawPrice) ([]NormalizedRate, error) {
+	var rates []NormalizedRate
+
+	for i, r := range raw {
+		if err := checkCancelled(ctx, i); err != nil {
+			return nil, err
+		}
+		price, err := ParsePrice(r.PricePerUnit)
+		if err != nil {
+			continue
+		}
+
+		rateKey := db.RateKey{
+			Cloud:         db.DigitalOcean,
+			Service:       r.ServiceCode,
+			ProductFamily: r.ProductFamily,
+			Region:        r.Region,
+			Attributes:    n.normalizeAttributes(r.Attributes),
+		}
+
+		rates = append(rates, NormalizedRate{
+			RateKey:    rateKey,
+			Unit:       n.normalizeUnit(r.Unit),
+			Price:      price,
+			Currency:   r.Currency,
+			Confidence: 1.0,
+
+			EffectiveDate: r.EffectiveDate,
+			Labels:        sourceLabels(r),
+		})
+	}
+
+	return rates, nil
+}
+
+// normalizeAttributes converts DigitalOcean attributes to canonical form
+func (n *DOPricingNormalizer) normalizeAttributes(raw map[string]string) map[string]string {
+	result := make(map[string]string)
+
+	mapping := map[string]string{
+		"slug":   "size",
+		"memory": "memory_mb",
+		"disk":   "disk_gb",
+	}
+
+	for k, v := range raw {
+		if v == "" {
+			continue
+		}
+		if canonical, ok := mapping[k]; ok {
+			result[canonical] = strings.ToLower(v)
+		} else {
+			result[toSnakeCase(k)] = strings.ToLower(v)
+		}
+	}
+
+	if size, ok := result["size"]; ok {
+		if class := dropletSizeClass(size); class != "" {
+			result["size_class"] = class
+		}
+	}
+
+	return result
+}
+
+// dropletSizeClass derives the droplet plan from its size slug,
+// e.g. s-2vcpu-4gb-intel is "premium-intel" and c-4 is "cpu-optimized"
+func dropletSizeClass(slug string) string {
+	prefix, _, _ := strings.Cut(slug, "-")
+	switch {
+	case prefix == "s" && strings.HasSuffix(slug, "-intel"):
+		return "premium-intel"
+	case prefix == "s" && strings.HasSuffix(slug, "-amd"):
+		return "premium-amd"
+	}
+
+	classes := map[string]string{
+		"s":   "basic",
+		"g":   "general-purpose",
+		"gd":  "general-purpose",
+		"c":   "cpu-optimized",
+		"c2":  "cpu-optimized",
+		"m":   "memory-optimized",
+		"m3":  "memory-optimized",
+		"m6":  "memory-optimized",
+		"so":  "storage-optimized",
+		"so1": "storage-optimized",
+		"gpu": "gpu",
+	}
+	return classes[prefix]
+}
+
+// normalizeUnit converts DigitalOcean units to canonical form
+func (n *DOPricingNormalizer) normalizeUnit(unit string) string {
+	mapping := map[string]string{
+		"hour":     "hours",
+		"month":    "month",
+		"GB-month": "GB-month",
+	}
+
+	if normalized, ok := mapping[unit]; ok {
+		return normalized
+	}
+
+	return strings.ToLower(unit)
+}
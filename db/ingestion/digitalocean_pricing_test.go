@@ -0,0 +1,96 @@
+// Package ingestion - DigitalOcean fetcher and normalizer tests
+package ingestion
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"terraform-cost/db"
+)
+
+func TestDropletSizeNormalization(t *testing.T) {
+	sizes := []DOSize{
+		{Slug: "s-2vcpu-4gb", VCPUs: 2, Memory: 4096, Disk: 80, PriceHourly: 0.03571, Regions: []string{"nyc3", "fra1"}, Available: true},
+		{Slug: "s-2vcpu-4gb-intel", VCPUs: 2, Memory: 4096, Disk: 120, PriceHourly: 0.04762, Regions: []string{"nyc3"}, Available: true},
+		{Slug: "c-4", VCPUs: 4, Memory: 8192, Disk: 50, PriceHourly: 0.125, Regions: []string{"nyc3"}, Available: true},
+		{Slug: "so1_5-2vcpu-16gb", VCPUs: 2, Memory: 16384, Disk: 300, PriceHourly: 0.1949, Regions: []string{"nyc3"}, Available: false},
+		{Slug: "m-2vcpu-16gb", VCPUs: 2, Memory: 16384, Disk: 50, PriceHourly: 0.125, Regions: []string{"sfo3"}, Available: true},
+	}
+
+	rates, err := NewDOPricingNormalizer().Normalize(context.Background(), dropletPrices(sizes, "nyc3"))
+	if err != nil {
+		t.Fatalf("normalize failed: %v", err)
+	}
+
+	// Unavailable sizes and sizes not offered in nyc3 are dropped
+	if len(rates) != 3 {
+		t.Fatalf("expected 3 droplet rates, got %d", len(rates))
+	}
+
+	want := map[string]string{
+		"s-2vcpu-4gb":       "basic",
+		"s-2vcpu-4gb-intel": "premium-intel",
+		"c-4":               "cpu-optimized",
+	}
+	for _, r := range rates {
+		attrs := r.RateKey.Attributes
+		if r.RateKey.Cloud != db.DigitalOcean || r.RateKey.Service != "Droplets" || r.Unit != "hours" {
+			t.Errorf("unexpected rate: %+v unit=%s", r.RateKey, r.Unit)
+		}
+		if class := want[attrs["size"]]; class == "" || attrs["size_class"] != class {
+			t.Errorf("size %s: size_class = %q, want %q", attrs["size"], attrs["size_class"], class)
+		}
+		if r.TierMin != nil || r.TierMax != nil {
+			t.Errorf("flat DigitalOcean pricing should not be tiered: %+v", r)
+		}
+	}
+
+	if attrs := rates[0].RateKey.Attributes; attrs["vcpus"] != "2" || attrs["memory_mb"] != "4096" || attrs["disk_gb"] != "80" {
+		t.Errorf("unexpected size attributes: %v", attrs)
+	}
+}
+
+func TestDOFetchRegionUsesSizesAPI(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			t.Errorf("missing bearer token")
+		}
+		w.Write([]byte(`{"sizes": [{"slug": "s-1vcpu-1gb", "memory": 1024, "vcpus": 1, "disk": 25,
+			"price_monthly": 6, "price_hourly": 0.00893, "regions": ["lon1"], "available": true}], "links": {}}`))
+	}))
+	defer server.Close()
+
+	cfg := DefaultDOPricingConfig()
+	cfg.Token = "test-token"
+	fetcher := NewDOPricingFetcher(cfg)
+	fetcher.baseURL = server.URL
+
+	raw, err := fetcher.FetchRegion(context.Background(), "lon1")
+	if err != nil {
+		t.Fatalf("fetch failed: %v", err)
+	}
+
+	// 1 droplet + 1 volume + 3 engines x 6 database sizes
+	if len(raw) != 20 {
+		t.Errorf("expected 20 raw prices, got %d", len(raw))
+	}
+	if !fetcher.IsRealAPI() {
+		t.Error("expected fetcher with a token to report a real API")
+	}
+}
+
+func TestDORegisteredByDefault(t *testing.T) {
+	registry := NewFetcherRegistry()
+	registry.RegisterDefaults()
+
+	fetcher, err := registry.GetFetcher(db.DigitalOcean)
+	if err != nil || fetcher.Cloud() != db.DigitalOcean {
+		t.Fatalf("expected DigitalOcean fetcher, got %v (%v)", fetcher, err)
+	}
+	normalizer, err := registry.GetNormalizer(db.DigitalOcean)
+	if err != nil || normalizer.Cloud() != db.DigitalOcean {
+		t.Errorf("expected DigitalOcean normalizer, got %v (%v)", normalizer, err)
+	}
+}
@@ -2,9 +2,18 @@
 package ingestion
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
 	"terraform-cost/db"
 )
 
+// Dimension priorities range from 0 (least important) to maxDimensionPriority
+const maxDimensionPriority = 100
+
 // DimensionAllowlist defines which dimensions to keep per service
 type DimensionAllowlist struct {
 	dimensions map[string]map[string]DimensionConfig // cloud:service -> dimension -> config
@@ -35,6 +44,8 @@ func (al *DimensionAllowlist) loadDefaults() {
 	al.Add(db.AWS, "AmazonEC2", "volume_type", false, 70)
 	al.Add(db.AWS, "AmazonEC2", "capacity_status", false, 50)
 	al.Add(db.AWS, "AmazonEC2", "product_family", false, 60)
+	al.Add(db.AWS, "AmazonEC2", "purchase_option", false, 85) // spot
+	al.Add(db.AWS, "AmazonEC2", "availability_zone", false, 75)
 
 	// AWS RDS
 	al.Add(db.AWS, "AmazonRDS", "instance_type", true, 100)
@@ -63,6 +74,58 @@ func (al *DimensionAllowlist) loadDefaults() {
 	al.Add(db.AWS, "AmazonEC2", "usage_type", false, 60)
 }
 
+// AllowlistConfig is the external allowlist format, merged over the defaults
+type AllowlistConfig struct {
+	Dimensions []AllowlistEntry `json:"dimensions"`
+}
+
+// AllowlistEntry allows one dimension for a cloud/service
+type AllowlistEntry struct {
+	Cloud     db.CloudProvider `json:"cloud"`
+	Service   string           `json:"service"`
+	Dimension string           `json:"dimension"`
+	Required  bool             `json:"required"`
+	Priority  int              `json:"priority"`
+}
+
+// LoadAllowlistFromJSON builds an allowlist from the defaults plus a JSON config:
+//
+//	{"dimensions": [{"cloud": "aws", "service": "AmazonEC2",
+//	  "dimension": "capacity_status", "required": true, "priority": 50}]}
+//
+// Entries for an existing cloud/service/dimension replace the default.
+func LoadAllowlistFromJSON(r io.Reader) (*DimensionAllowlist, error) {
+	var cfg AllowlistConfig
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to decode allowlist: %w", err)
+	}
+
+	al := NewDimensionAllowlist()
+	for i, e := range cfg.Dimensions {
+		if e.Cloud == "" || e.Service == "" || e.Dimension == "" {
+			return nil, fmt.Errorf("allowlist entry %d: cloud, service and dimension are required", i)
+		}
+		if e.Priority < 0 || e.Priority > maxDimensionPriority {
+			return nil, fmt.Errorf("allowlist entry %d (%s/%s/%s): priority %d outside 0-%d",
+				i, e.Cloud, e.Service, e.Dimension, e.Priority, maxDimensionPriority)
+		}
+		al.Add(e.Cloud, e.Service, e.Dimension, e.Required, e.Priority)
+	}
+	return al, nil
+}
+
+// LoadAllowlistFromFile builds an allowlist from the defaults plus a JSON file
+func LoadAllowlistFromFile(path string) (*DimensionAllowlist, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return LoadAllowlistFromJSON(f)
+}
+
 // Add adds a dimension to the allowlist
 func (al *DimensionAllowlist) Add(cloud db.CloudProvider, service, dimension string, required bool, priority int) {
 	key := string(cloud) + ":" + service
@@ -129,10 +192,24 @@ func (al *DimensionAllowlist) IsAllowed(cloud db.CloudProvider, service, dimensi
 	return ok
 }
 
+// DedupStrategy picks which rate survives when filtering collapses several
+// rates onto the same key
+type DedupStrategy string
+
+const (
+	// DedupKeepFirst keeps the first rate in input order
+	DedupKeepFirst DedupStrategy = "keep-first"
+	// DedupLowestPrice keeps the cheapest rate
+	DedupLowestPrice DedupStrategy = "keep-lowest-price"
+	// DedupHighestConfidence keeps the rate with the highest confidence
+	DedupHighestConfidence DedupStrategy = "keep-highest-confidence"
+)
+
 // FilteredNormalizer wraps a normalizer with dimension filtering
 type FilteredNormalizer struct {
 	inner     PriceNormalizer
 	allowlist *DimensionAllowlist
+	dedup     DedupStrategy
 }
 
 // NewFilteredNormalizer creates a normalizer that filters dimensions
@@ -140,17 +217,31 @@ func NewFilteredNormalizer(inner PriceNormalizer) *FilteredNormalizer {
 	return &FilteredNormalizer{
 		inner:     inner,
 		allowlist: NewDimensionAllowlist(),
+		dedup:     DedupKeepFirst,
 	}
 }
 
+// WithAllowlist replaces the default allowlist, e.g. with one loaded from config
+func (n *FilteredNormalizer) WithAllowlist(al *DimensionAllowlist) *FilteredNormalizer {
+	n.allowlist = al
+	return n
+}
+
+// WithDedupStrategy sets how rates that collapse onto one key are merged.
+// Ties under any strategy keep the earlier rate.
+func (n *FilteredNormalizer) WithDedupStrategy(strategy DedupStrategy) *FilteredNormalizer {
+	n.dedup = strategy
+	return n
+}
+
 func (n *FilteredNormalizer) Cloud() db.CloudProvider {
 	return n.inner.Cloud()
 }
 
 // Normalize normalizes and filters dimensions
-func (n *FilteredNormalizer) Normalize(raw []RawPrice) ([]NormalizedRate, error) {
+func (n *FilteredNormalizer) Normalize(ctx context.Context, raw []RawPrice) ([]NormalizedRate, error) {
 	// First normalize with inner normalizer
-	rates, err := n.inner.Normalize(raw)
+	rates, err := n.inner.Normalize(ctx, raw)
 	if err != nil {
 		return nil, err
 	}
@@ -168,22 +259,41 @@ func (n *FilteredNormalizer) Normalize(raw []RawPrice) ([]NormalizedRate, error)
 	return n.deduplicate(rates), nil
 }
 
-// deduplicate removes duplicate rates (keeping first)
+// deduplicate merges rates sharing a key, unit and tier using the configured
+// strategy. Output keeps the order in which each key was first seen.
 func (n *FilteredNormalizer) deduplicate(rates []NormalizedRate) []NormalizedRate {
-	seen := make(map[string]bool)
+	index := make(map[string]int)
 	var result []NormalizedRate
 
 	for _, r := range rates {
-		key := rateKeyString(r.RateKey) + "|" + r.Unit
-		if !seen[key] {
-			seen[key] = true
+		// Tiers of one key are distinct rates, never duplicates
+		key := rateKeyString(r.RateKey) + "|" + r.Unit + "|" + tierBound(r.TierMin) + "|" + tierBound(r.TierMax)
+		i, ok := index[key]
+		if !ok {
+			index[key] = len(result)
 			result = append(result, r)
+			continue
+		}
+		if n.prefer(r, result[i]) {
+			result[i] = r
 		}
 	}
 
 	return result
 }
 
+// prefer reports whether candidate should replace the kept rate
+func (n *FilteredNormalizer) prefer(candidate, kept NormalizedRate) bool {
+	switch n.dedup {
+	case DedupLowestPrice:
+		return candidate.Price.LessThan(kept.Price)
+	case DedupHighestConfidence:
+		return candidate.Confidence > kept.Confidence
+	default:
+		return false
+	}
+}
+
 // Stats returns filtering statistics
 type FilteringStats struct {
 	TotalRates    int
@@ -193,15 +303,15 @@ type FilteringStats struct {
 }
 
 // WithStats normalizes and returns stats
-func (n *FilteredNormalizer) WithStats(raw []RawPrice) ([]NormalizedRate, *FilteringStats, error) {
+func (n *FilteredNormalizer) WithStats(ctx context.Context, raw []RawPrice) ([]NormalizedRate, *FilteringStats, error) {
 	// Normalize without filtering first
-	allRates, err := n.inner.Normalize(raw)
+	allRates, err := n.inner.Normalize(ctx, raw)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	// Then filter
-	filtered, err := n.Normalize(raw)
+	filtered, err := n.Normalize(ctx, raw)
 	if err != nil {
 		return nil, nil, err
 	}
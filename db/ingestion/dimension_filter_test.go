@@ -0,0 +1,150 @@
+// Package ingestion - Dimension allowlist tests
+package ingestion
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+
+	"terraform-cost/db"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestLoadAllowlistFromFile(t *testing.T) {
+	al, err := LoadAllowlistFromFile("testdata/allowlist.json")
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+
+	required := al.GetRequired(db.AWS, "AmazonEC2")
+	sort.Strings(required)
+	if strings.Join(required, ",") != "capacity_status,instance_type,os" {
+		t.Errorf("required = %v, want capacity_status, instance_type and os", required)
+	}
+
+	// Defaults for other services are kept
+	if !al.IsAllowed(db.AWS, "AmazonS3", "storage_class") {
+		t.Error("expected default S3 dimensions to survive the merge")
+	}
+
+	normalizer := NewFilteredNormalizer(&passthroughNormalizer{cloud: db.AWS}).WithAllowlist(al)
+	rates, err := normalizer.Normalize(context.Background(), []RawPrice{{
+		ServiceCode:  "AmazonEC2",
+		Region:       "us-east-1",
+		Unit:         "hours",
+		PricePerUnit: "0.0104",
+		Currency:     "USD",
+		Attributes: map[string]string{
+			"instance_type":   "t3.micro",
+			"capacity_status": "used",
+			"instance_family": "general purpose",
+			"operation":       "runinstances",
+		},
+	}})
+	if err != nil {
+		t.Fatalf("normalize failed: %v", err)
+	}
+
+	attrs := rates[0].RateKey.Attributes
+	if attrs["capacity_status"] != "used" || attrs["instance_family"] != "general purpose" {
+		t.Errorf("expected configured dimensions to be kept, got %v", attrs)
+	}
+	if _, ok := attrs["operation"]; ok {
+		t.Errorf("expected unlisted dimension to be dropped, got %v", attrs)
+	}
+}
+
+func TestLoadAllowlistValidation(t *testing.T) {
+	bad := []string{
+		`{"dimensions": [{"cloud": "aws", "service": "AmazonEC2", "dimension": "", "priority": 50}]}`,
+		`{"dimensions": [{"cloud": "aws", "service": "AmazonEC2", "dimension": "os", "priority": 500}]}`,
+		`{"dimensions": [{"cloud": "aws", "service": "AmazonEC2", "dimension": "os", "priority": -1}]}`,
+		`{"dims": []}`,
+	}
+	for _, in := range bad {
+		if _, err := LoadAllowlistFromJSON(strings.NewReader(in)); err == nil {
+			t.Errorf("expected %s to be rejected", in)
+		}
+	}
+}
+
+// fixedNormalizer returns a copy of preset rates regardless of input
+type fixedNormalizer struct {
+	rates []NormalizedRate
+}
+
+func (n *fixedNormalizer) Cloud() db.CloudProvider { return db.AWS }
+func (n *fixedNormalizer) Normalize(ctx context.Context, raw []RawPrice) ([]NormalizedRate, error) {
+	rates := make([]NormalizedRate, len(n.rates))
+	for i, r := range n.rates {
+		r.RateKey.Attributes = make(map[string]string)
+		for k, v := range n.rates[i].RateKey.Attributes {
+			r.RateKey.Attributes[k] = v
+		}
+		rates[i] = r
+	}
+	return rates, nil
+}
+
+func TestFilteredNormalizerDedupStrategy(t *testing.T) {
+	ec2 := func(tenancy, price string, confidence float64) NormalizedRate {
+		return NormalizedRate{
+			RateKey: db.RateKey{Cloud: db.AWS, Service: "AmazonEC2", ProductFamily: "Compute Instance", Region: "us-east-1",
+				Attributes: map[string]string{"instance_type": "m5.large", "os": "linux", "tenancy": tenancy, "operation": "runinstances"}},
+			Unit:       "hours",
+			Price:      decimal.RequireFromString(price),
+			Confidence: confidence,
+		}
+	}
+	// Dropping tenancy merges the shared and dedicated rows
+	al := NewDimensionAllowlist()
+	al.dimensions["aws:AmazonEC2"] = map[string]DimensionConfig{
+		"instance_type": {Key: "instance_type", IsRequired: true, Priority: 100},
+		"os":            {Key: "os", IsRequired: true, Priority: 90},
+	}
+	inner := &fixedNormalizer{rates: []NormalizedRate{
+		ec2("dedicated", "0.106", 0.9),
+		ec2("shared", "0.096", 0.8),
+		ec2("host", "0.110", 1.0),
+	}}
+
+	tests := []struct {
+		strategy DedupStrategy
+		want     string
+	}{
+		{DedupKeepFirst, "0.106"},
+		{DedupLowestPrice, "0.096"},
+		{DedupHighestConfidence, "0.110"},
+	}
+	for _, tt := range tests {
+		rates, err := NewFilteredNormalizer(inner).WithAllowlist(al).WithDedupStrategy(tt.strategy).Normalize(context.Background(), nil)
+		if err != nil {
+			t.Fatalf("%s: normalize failed: %v", tt.strategy, err)
+		}
+		if len(rates) != 1 {
+			t.Fatalf("%s: expected rows to merge into 1 rate, got %d", tt.strategy, len(rates))
+		}
+		if !rates[0].Price.Equal(decimal.RequireFromString(tt.want)) {
+			t.Errorf("%s: kept price %s, want %s", tt.strategy, rates[0].Price, tt.want)
+		}
+	}
+}
+
+func TestFilteredNormalizerKeepsTiers(t *testing.T) {
+	bound := func(s string) *decimal.Decimal {
+		d := decimal.RequireFromString(s)
+		return &d
+	}
+	key := db.RateKey{Cloud: db.AWS, Service: "AmazonCloudFront", ProductFamily: "Data Transfer", Region: "us-east-1"}
+	inner := &fixedNormalizer{rates: []NormalizedRate{
+		{RateKey: key, Unit: "GB", Price: decimal.RequireFromString("0.085"), TierMin: bound("0"), TierMax: bound("10240")},
+		{RateKey: key, Unit: "GB", Price: decimal.RequireFromString("0.080"), TierMin: bound("10240")},
+	}}
+
+	rates, _ := NewFilteredNormalizer(inner).WithDedupStrategy(DedupLowestPrice).Normalize(context.Background(), nil)
+	if len(rates) != 2 {
+		t.Errorf("expected both tiers to survive deduplication, got %d", len(rates))
+	}
+}
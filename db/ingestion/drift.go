@@ -4,6 +4,8 @@ package ingestion
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 
 	"terraform-cost/db"
 
@@ -15,6 +17,8 @@ import (
 type DriftDetector struct {
 	store                  db.PricingStore
 	significanceThreshold  float64 // Percent change considered significant
+	serviceThresholds      map[string]float64
+	absoluteThreshold      decimal.Decimal // Minimum absolute price delta considered significant
 }
 
 // NewDriftDetector creates a new drift detector
@@ -22,6 +26,7 @@ func NewDriftDetector(store db.PricingStore) *DriftDetector {
 	return &DriftDetector{
 		store:                 store,
 		significanceThreshold: 0.05, // 5% default
+		serviceThresholds:     make(map[string]float64),
 	}
 }
 
@@ -31,6 +36,27 @@ func (d *DriftDetector) WithThreshold(pct float64) *DriftDetector {
 	return d
 }
 
+// WithServiceThreshold overrides the significance threshold for one service
+func (d *DriftDetector) WithServiceThreshold(service string, pct float64) *DriftDetector {
+	d.serviceThresholds[service] = pct
+	return d
+}
+
+// WithAbsoluteThreshold sets the minimum absolute price delta for a change to
+// be significant. A change must exceed both this and the percent threshold.
+func (d *DriftDetector) WithAbsoluteThreshold(amount decimal.Decimal) *DriftDetector {
+	d.absoluteThreshold = amount
+	return d
+}
+
+// thresholdFor returns the percent threshold for a service
+func (d *DriftDetector) thresholdFor(service string) float64 {
+	if pct, ok := d.serviceThresholds[service]; ok {
+		return pct
+	}
+	return d.significanceThreshold
+}
+
 // DriftRecord represents a single price change
 type DriftRecord struct {
 	Service        string
@@ -96,16 +122,19 @@ func (d *DriftDetector) DetectDrift(ctx context.Context, oldSnapshotID, newSnaps
 		return nil, fmt.Errorf("snapshots must be for same cloud/region")
 	}
 
-	summary := &DriftSummary{
-		OldSnapshotID: oldSnapshotID,
-		NewSnapshotID: newSnapshotID,
-		Cloud:         oldSnapshot.Cloud,
-		Records:       make([]DriftRecord, 0),
+	oldRates, err := d.store.GetRatesBySnapshot(ctx, oldSnapshotID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load old rates: %w", err)
+	}
+	newRates, err := d.store.GetRatesBySnapshot(ctx, newSnapshotID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load new rates: %w", err)
 	}
 
-	// This would typically query the database for rate comparisons
-	// For now, we'll create the structure for the comparison
-
+	summary := d.DetectDriftFromRates(RatesFromSnapshot(oldRates), RatesFromSnapshot(newRates))
+	summary.OldSnapshotID = oldSnapshotID
+	summary.NewSnapshotID = newSnapshotID
+	summary.Cloud = oldSnapshot.Cloud
 	return summary, nil
 }
 
@@ -151,16 +180,19 @@ func (d *DriftDetector) DetectDriftFromRates(oldRates, newRates []NormalizedRate
 			}
 		} else {
 			// New rate
+			dimKey, dimValue := dimensionLabels(newRate.RateKey.Attributes)
 			record := DriftRecord{
-				Service:       newRate.RateKey.Service,
-				ProductFamily: newRate.RateKey.ProductFamily,
-				OldPrice:      decimal.Zero,
-				NewPrice:      newRate.Price,
-				PriceDelta:    newRate.Price,
-				PercentChange: 100,
-				Unit:          newRate.Unit,
-				DriftType:     DriftNew,
-				IsSignificant: true,
+				Service:        newRate.RateKey.Service,
+				ProductFamily:  newRate.RateKey.ProductFamily,
+				DimensionKey:   dimKey,
+				DimensionValue: dimValue,
+				OldPrice:       decimal.Zero,
+				NewPrice:       newRate.Price,
+				PriceDelta:     newRate.Price,
+				PercentChange:  100,
+				Unit:           newRate.Unit,
+				DriftType:      DriftNew,
+				IsSignificant:  true,
 			}
 			summary.Records = append(summary.Records, record)
 			summary.TotalChanges++
@@ -172,16 +204,19 @@ func (d *DriftDetector) DetectDriftFromRates(oldRates, newRates []NormalizedRate
 	// Find removals
 	for key, oldRate := range oldIndex {
 		if _, exists := newIndex[key]; !exists {
+			dimKey, dimValue := dimensionLabels(oldRate.RateKey.Attributes)
 			record := DriftRecord{
-				Service:       oldRate.RateKey.Service,
-				ProductFamily: oldRate.RateKey.ProductFamily,
-				OldPrice:      oldRate.Price,
-				NewPrice:      decimal.Zero,
-				PriceDelta:    oldRate.Price.Neg(),
-				PercentChange: -100,
-				Unit:          oldRate.Unit,
-				DriftType:     DriftRemoved,
-				IsSignificant: true,
+				Service:        oldRate.RateKey.Service,
+				ProductFamily:  oldRate.RateKey.ProductFamily,
+				DimensionKey:   dimKey,
+				DimensionValue: dimValue,
+				OldPrice:       oldRate.Price,
+				NewPrice:       decimal.Zero,
+				PriceDelta:     oldRate.Price.Neg(),
+				PercentChange:  -100,
+				Unit:           oldRate.Unit,
+				DriftType:      DriftRemoved,
+				IsSignificant:  true,
 			}
 			summary.Records = append(summary.Records, record)
 			summary.TotalChanges++
@@ -211,6 +246,48 @@ func (d *DriftDetector) DetectDriftFromRates(oldRates, newRates []NormalizedRate
 	return summary
 }
 
+// CheckDriftLimits fails when prices present in both rate sets moved more
+// than maxAvgPct on average or any one moved more than maxSinglePct (0
+// disables a limit). Unchanged rates count as 0% and rates that were free
+// before are skipped. Moves that large usually mean a corrupt fetch rather
+// than a price change.
+func CheckDriftLimits(oldRates, newRates []NormalizedRate, maxAvgPct, maxSinglePct float64) error {
+	oldIndex := make(map[string]NormalizedRate, len(oldRates))
+	for _, r := range oldRates {
+		oldIndex[rateHashKey(r)] = r
+	}
+
+	var matched int
+	var total, worst float64
+	var worstRate NormalizedRate
+	for _, r := range newRates {
+		old, ok := oldIndex[rateHashKey(r)]
+		if !ok || old.Price.IsZero() {
+			continue
+		}
+		pct, _ := r.Price.Sub(old.Price).Div(old.Price).Abs().Mul(decimal.NewFromInt(100)).Float64()
+		matched++
+		total += pct
+		if pct > worst {
+			worst, worstRate = pct, r
+		}
+	}
+	if matched == 0 {
+		return nil
+	}
+
+	if maxSinglePct > 0 && worst > maxSinglePct {
+		label, value := dimensionLabels(worstRate.RateKey.Attributes)
+		return fmt.Errorf("price of %s %s=%s moved %.1f%%, over the %.1f%% single-rate drift limit",
+			worstRate.RateKey.Service, label, value, worst, maxSinglePct)
+	}
+	if avg := total / float64(matched); maxAvgPct > 0 && avg > maxAvgPct {
+		return fmt.Errorf("prices moved %.1f%% on average across %d rates, over the %.1f%% drift limit",
+			avg, matched, maxAvgPct)
+	}
+	return nil
+}
+
 func (d *DriftDetector) createDriftRecord(oldRate, newRate NormalizedRate) DriftRecord {
 	delta := newRate.Price.Sub(oldRate.Price)
 	
@@ -229,21 +306,39 @@ func (d *DriftDetector) createDriftRecord(oldRate, newRate NormalizedRate) Drift
 	if absPct < 0 {
 		absPct = -absPct
 	}
-	if absPct >= d.significanceThreshold*100 {
+	if absPct >= d.thresholdFor(newRate.RateKey.Service)*100 && delta.Abs().GreaterThanOrEqual(d.absoluteThreshold) {
 		isSignificant = true
 	}
 
+	dimKey, dimValue := dimensionLabels(newRate.RateKey.Attributes)
 	return DriftRecord{
-		Service:       newRate.RateKey.Service,
-		ProductFamily: newRate.RateKey.ProductFamily,
-		OldPrice:      oldRate.Price,
-		NewPrice:      newRate.Price,
-		PriceDelta:    delta,
-		PercentChange: pctChange,
-		Unit:          newRate.Unit,
-		DriftType:     driftType,
-		IsSignificant: isSignificant,
+		Service:        newRate.RateKey.Service,
+		ProductFamily:  newRate.RateKey.ProductFamily,
+		DimensionKey:   dimKey,
+		DimensionValue: dimValue,
+		OldPrice:       oldRate.Price,
+		NewPrice:       newRate.Price,
+		PriceDelta:     delta,
+		PercentChange:  pctChange,
+		Unit:           newRate.Unit,
+		DriftType:      driftType,
+		IsSignificant:  isSignificant,
+	}
+}
+
+// dimensionLabels joins attribute names and their values, sorted by name,
+// e.g. "instance_type,os" and "m5.large,linux"
+func dimensionLabels(attrs map[string]string) (string, string) {
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	values := make([]string, len(keys))
+	for i, k := range keys {
+		values[i] = attrs[k]
 	}
+	return strings.Join(keys, ","), strings.Join(values, ",")
 }
 
 // HasSignificantDrift returns true if there are significant price changes
@@ -0,0 +1,208 @@
+// Package ingestion - Drift detection tests
+package ingestion
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"terraform-cost/db"
+	"terraform-cost/db/memstore"
+
+	"github.com/shopspring/decimal"
+)
+
+// priceChange builds old and new rates for one service differing only in price
+func priceChange(service, oldPrice, newPrice string) (NormalizedRate, NormalizedRate) {
+	key := db.RateKey{Cloud: db.AWS, Service: service, Region: "us-east-1", Attributes: map[string]string{}}
+	return NormalizedRate{RateKey: key, Unit: "requests", Price: decimal.RequireFromString(oldPrice)},
+		NormalizedRate{RateKey: key, Unit: "requests", Price: decimal.RequireFromString(newPrice)}
+}
+
+func TestDriftAbsoluteThreshold(t *testing.T) {
+	detector := NewDriftDetector(nil).WithAbsoluteThreshold(decimal.RequireFromString("0.01"))
+
+	// +50% on a Lambda request price is a tiny dollar change
+	oldLambda, newLambda := priceChange("AWSLambda", "0.0000002", "0.0000003")
+	if record := detector.createDriftRecord(oldLambda, newLambda); record.IsSignificant {
+		t.Errorf("expected %.0f%% change of %s to be below the absolute floor", record.PercentChange, record.PriceDelta)
+	}
+
+	// +10% on a Private CA is both large in percent and in dollars
+	oldCA, newCA := priceChange("AWSCertificateManager", "400", "440")
+	if record := detector.createDriftRecord(oldCA, newCA); !record.IsSignificant {
+		t.Error("expected $40 change to be significant")
+	}
+}
+
+func TestDriftServiceThreshold(t *testing.T) {
+	detector := NewDriftDetector(nil).WithServiceThreshold("AmazonEC2", 0.20)
+
+	// 10% is below EC2's 20% override but above the 5% global default
+	oldEC2, newEC2 := priceChange("AmazonEC2", "1.00", "1.10")
+	if detector.createDriftRecord(oldEC2, newEC2).IsSignificant {
+		t.Error("expected EC2 to use its 20% threshold")
+	}
+	oldS3, newS3 := priceChange("AmazonS3", "1.00", "1.10")
+	if !detector.createDriftRecord(oldS3, newS3).IsSignificant {
+		t.Error("expected S3 to fall back to the 5% global threshold")
+	}
+
+	summary := detector.DetectDriftFromRates([]NormalizedRate{oldEC2}, []NormalizedRate{newEC2})
+	if summary.TotalChanges != 1 || summary.SignificantChanges != 0 {
+		t.Errorf("unexpected summary: %s", summary)
+	}
+}
+
+func TestDetectDriftLoadsSnapshotRates(t *testing.T) {
+	ctx := context.Background()
+	store := memstore.NewMemoryStore()
+	seedSnapshot(t, store, "us-east-1", 3)
+	old, _ := store.GetActiveSnapshot(ctx, db.AWS, "us-east-1", "default")
+
+	// Drop tier a, raise tier b, keep tier c and add tier d
+	rates, _ := (&passthroughNormalizer{cloud: db.AWS}).Normalize(context.Background(), testRawPrices("us-east-1", 4))
+	rates = rates[1:]
+	rates[0].Price = decimal.RequireFromString("0.030")
+	err := restoreBackup(ctx, store, &SnapshotBackup{
+		Provider: db.AWS, Region: "us-east-1", Alias: "default",
+		ContentHash: calculateHash(rates), RateCount: len(rates), SchemaVersion: "1.0", Rates: rates,
+	})
+	if err != nil {
+		t.Fatalf("failed to seed new snapshot: %v", err)
+	}
+	current, _ := store.GetActiveSnapshot(ctx, db.AWS, "us-east-1", "default")
+
+	summary, err := NewDriftDetector(store).DetectDrift(ctx, old.ID, current.ID)
+	if err != nil {
+		t.Fatalf("drift failed: %v", err)
+	}
+	if summary.OldSnapshotID != old.ID || summary.NewSnapshotID != current.ID || summary.Cloud != db.AWS {
+		t.Errorf("unexpected summary identity: %+v", summary)
+	}
+	if summary.PriceIncreases != 1 || summary.NewRates != 1 || summary.RemovedRates != 1 {
+		t.Errorf("expected 1 increase, 1 new and 1 removed, got %s", summary)
+	}
+	for _, r := range summary.Records {
+		if r.DimensionKey != "tier" || r.DimensionValue == "" {
+			t.Errorf("expected tier dimension on %+v", r)
+		}
+	}
+}
+
+// repriced returns the test prices with the first n set to price
+func repriced(n int, price string) []RawPrice {
+	prices := testRawPrices("us-east-1", 5)
+	for i := 0; i < n; i++ {
+		prices[i].PricePerUnit = price
+	}
+	return prices
+}
+
+func TestLifecycleDriftLimits(t *testing.T) {
+	tests := []struct {
+		name    string
+		prices  []RawPrice
+		force   bool
+		wantErr string
+	}{
+		{"within limits", repriced(5, "0.025"), false, ""},
+		{"single rate over limit", repriced(1, "0.2"), false, "single-rate drift limit"},
+		{"average over limit", repriced(5, "0.040"), false, "on average"},
+		{"forced", repriced(1, "0.2"), true, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := memstore.NewMemoryStore()
+			run := func(prices []RawPrice, configure func(*LifecycleConfig)) *LifecycleResult {
+				config := DefaultLifecycleConfig()
+				config.Provider = db.AWS
+				config.Region = "us-east-1"
+				config.Environment = "development"
+				config.BackupDir = t.TempDir()
+				configure(config)
+				fetcher := &staticFetcher{cloud: db.AWS, prices: prices}
+				result, _ := NewLifecycle(fetcher, &passthroughNormalizer{cloud: db.AWS}, store).Execute(context.Background(), config)
+				return result
+			}
+
+			baseline := run(testRawPrices("us-east-1", 5), func(*LifecycleConfig) {})
+			if !baseline.Success {
+				t.Fatalf("baseline ingestion failed: %s", baseline.Error)
+			}
+			result := run(tt.prices, func(c *LifecycleConfig) {
+				c.MaxAvgDriftPercent = 50
+				c.MaxSingleDriftPercent = 500
+				c.ForceDrift = tt.force
+			})
+
+			if tt.wantErr == "" {
+				if !result.Success {
+					t.Fatalf("expected the ingestion to commit, got %s", result.Error)
+				}
+				return
+			}
+			if result.Success || !strings.Contains(result.Error, tt.wantErr) {
+				t.Fatalf("expected a drift failure mentioning %q, got %+v", tt.wantErr, result)
+			}
+			active, _ := store.GetActiveSnapshot(context.Background(), db.AWS, "us-east-1", "default")
+			if active == nil || active.ID != *baseline.SnapshotID {
+				t.Error("expected the baseline snapshot to stay active")
+			}
+		})
+	}
+}
+
+func TestCheckDriftLimitsMatchesTiers(t *testing.T) {
+	tier := func(min string, price string) NormalizedRate {
+		bound := decimal.RequireFromString(min)
+		key := db.RateKey{Cloud: db.AWS, Service: "AmazonS3", Region: "us-east-1", Attributes: map[string]string{}}
+		return NormalizedRate{RateKey: key, Unit: "GB-Mo", TierMin: &bound, Price: decimal.RequireFromString(price)}
+	}
+	oldRates := []NormalizedRate{tier("0", "0.023"), tier("51200", "0.022")}
+	newRates := []NormalizedRate{tier("0", "0.023"), tier("51200", "0.022")}
+
+	// Each tier is compared with its own old price, not whichever tier came last
+	if err := CheckDriftLimits(oldRates, newRates, 1, 1); err != nil {
+		t.Errorf("expected unchanged tiers to pass, got %v", err)
+	}
+}
+
+func TestStreamingLifecycleDriftLimits(t *testing.T) {
+	tests := []struct {
+		name    string
+		force   bool
+		wantErr bool
+	}{
+		{"over limit", false, true},
+		{"forced", true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := memstore.NewMemoryStore()
+			run := func(prices []RawPrice, configure func(*LifecycleConfig)) *LifecycleResult {
+				streamCfg := DefaultStreamingConfig()
+				streamCfg.WorkDir = t.TempDir()
+				config := DefaultLifecycleConfig()
+				config.Provider = db.AWS
+				config.Region = "us-east-1"
+				config.BackupDir = t.TempDir()
+				configure(config)
+				fetcher := &staticFetcher{cloud: db.AWS, prices: prices}
+				result, _ := NewStreamingLifecycle(fetcher, &passthroughNormalizer{cloud: db.AWS}, store, streamCfg).Execute(context.Background(), config)
+				return result
+			}
+
+			if baseline := run(testRawPrices("us-east-1", 5), func(*LifecycleConfig) {}); !baseline.Success {
+				t.Fatalf("baseline ingestion failed: %s", baseline.Error)
+			}
+			result := run(repriced(1, "0.2"), func(c *LifecycleConfig) {
+				c.MaxSingleDriftPercent = 500
+				c.ForceDrift = tt.force
+			})
+			if tt.wantErr != (!result.Success && strings.Contains(result.Error, "single-rate drift limit")) {
+				t.Errorf("expected drift failure = %t, got %+v", tt.wantErr, result)
+			}
+		})
+	}
+}
@@ -104,7 +104,7 @@ func (d *EquivalenceDetector) computeEquivalenceHash(rates []NormalizedRate) str
 		// Exclude region from attributes for comparison
 		attrs := make(map[string]string)
 		for k, v := range r.RateKey.Attributes {
-			if k != "region" && k != "regionCode" && k != "location" {
+			if !isRegionAttribute(k) {
 				attrs[k] = v
 			}
 		}
@@ -136,6 +136,12 @@ func (d *EquivalenceDetector) computeEquivalenceHash(rates []NormalizedRate) str
 	return hex.EncodeToString(hash[:16]) // Use first 16 bytes for storage efficiency
 }
 
+// isRegionAttribute reports whether a rate key attribute names the region,
+// and so differs between otherwise identical rates of two regions
+func isRegionAttribute(name string) bool {
+	return name == "region" || name == "regionCode" || name == "location" || name == usageTypeRawAttribute
+}
+
 // GetCanonicalRegion returns the canonical region for a given region
 func (d *EquivalenceDetector) GetCanonicalRegion(region string) string {
 	hash, ok := d.regionToHash[region]
@@ -0,0 +1,91 @@
+// Package ingestion - Streaming snapshot export
+package ingestion
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"terraform-cost/db"
+
+	"github.com/google/uuid"
+)
+
+// DefaultCursorBatchSize is how many rates a RateCursor reads per query
+const DefaultCursorBatchSize = 1000
+
+// RateCursor walks a snapshot's rates in ID order a page at a time, so a
+// snapshot of any size can be read without loading it into memory
+type RateCursor struct {
+	store      db.PricingStore
+	snapshotID uuid.UUID
+	batchSize  int
+	after      uuid.UUID
+	done       bool
+}
+
+// NewRateCursor creates a cursor over a snapshot's rates
+func NewRateCursor(store db.PricingStore, snapshotID uuid.UUID, batchSize int) *RateCursor {
+	if batchSize <= 0 {
+		batchSize = DefaultCursorBatchSize
+	}
+	return &RateCursor{store: store, snapshotID: snapshotID, batchSize: batchSize}
+}
+
+// Next returns the next page of rates, or nil once the snapshot is exhausted
+func (c *RateCursor) Next(ctx context.Context) ([]db.SnapshotRate, error) {
+	if c.done {
+		return nil, nil
+	}
+	page, err := c.store.GetRatesPage(ctx, c.snapshotID, c.after, c.batchSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rates after %s: %w", c.after, err)
+	}
+	if len(page) < c.batchSize {
+		c.done = true
+	}
+	if len(page) == 0 {
+		return nil, nil
+	}
+	c.after = page[len(page)-1].Rate.ID
+	return page, nil
+}
+
+// ExportSnapshotJSONL writes every rate of a snapshot to w as line-delimited
+// JSON, one NormalizedRate per line, streaming page by page
+func ExportSnapshotJSONL(ctx context.Context, store db.PricingStore, snapshotID uuid.UUID, w io.Writer) error {
+	snapshot, err := store.GetSnapshot(ctx, snapshotID)
+	if err != nil {
+		return fmt.Errorf("failed to load snapshot: %w", err)
+	}
+	if snapshot == nil {
+		return fmt.Errorf("snapshot %s not found", snapshotID)
+	}
+
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	cursor := NewRateCursor(store, snapshotID, DefaultCursorBatchSize)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		page, err := cursor.Next(ctx)
+		if err != nil {
+			return err
+		}
+		if page == nil {
+			break
+		}
+		for _, rate := range RatesFromSnapshot(page) {
+			if err := enc.Encode(rate); err != nil {
+				return fmt.Errorf("failed to write rate: %w", err)
+			}
+		}
+		if err := bw.Flush(); err != nil {
+			return fmt.Errorf("failed to write rates: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,236 @@
+// Package ingestion - FinOps FOCUS export
+// Snapshots are written as CSV with FinOps Open Cost and Usage Specification
+// (FOCUS) column names, so standard FinOps dashboards can load our price list.
+package ingestion
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"terraform-cost/db"
+
+	"github.com/google/uuid"
+)
+
+// FOCUSColumns are the FOCUS columns ExportFOCUS populates, in output order
+var FOCUSColumns = []string{
+	"BilledCost",
+	"BillingCurrency",
+	"ChargeCategory",
+	"ChargeDescription",
+	"ChargePeriodStart",
+	"ChargePeriodEnd",
+	"EffectiveCost",
+	"ListCost",
+	"ListUnitPrice",
+	"PricingCategory",
+	"PricingQuantity",
+	"PricingUnit",
+	"ProviderName",
+	"PublisherName",
+	"RegionId",
+	"ServiceCategory",
+	"ServiceName",
+	"SkuId",
+	"SkuPriceId",
+}
+
+// focusProviderNames maps a cloud onto its FOCUS ProviderName
+var focusProviderNames = map[db.CloudProvider]string{
+	db.AWS:          "AWS",
+	db.Azure:        "Microsoft",
+	db.GCP:          "Google Cloud",
+	db.OCI:          "Oracle Cloud Infrastructure",
+	db.DigitalOcean: "DigitalOcean",
+}
+
+// focusServiceCategories maps keywords in a service or product family onto a
+// FOCUS ServiceCategory; the first match wins
+var focusServiceCategories = []struct {
+	keyword  string
+	category string
+}{
+	{"sagemaker", "AI and Machine Learning"},
+	{"machine learning", "AI and Machine Learning"},
+	{"rds", "Databases"},
+	{"dynamodb", "Databases"},
+	{"database", "Databases"},
+	{"sql", "Databases"},
+	{"redis", "Databases"},
+	{"cache", "Databases"},
+	{"s3", "Storage"},
+	{"storage", "Storage"},
+	{"disk", "Storage"},
+	{"backup", "Storage"},
+	{"elb", "Networking"},
+	{"load balancer", "Networking"},
+	{"cloudfront", "Networking"},
+	{"network", "Networking"},
+	{"data transfer", "Networking"},
+	{"bandwidth", "Networking"},
+	{"vpc", "Networking"},
+	{"dns", "Networking"},
+	{"route53", "Networking"},
+	{"cloudwatch", "Management and Governance"},
+	{"monitor", "Management and Governance"},
+	{"kms", "Security"},
+	{"key vault", "Security"},
+	{"secret", "Security"},
+	{"sqs", "Integration"},
+	{"sns", "Integration"},
+	{"pub/sub", "Integration"},
+	{"service bus", "Integration"},
+	{"ec2", "Compute"},
+	{"lambda", "Compute"},
+	{"virtual machines", "Compute"},
+	{"compute", "Compute"},
+	{"functions", "Compute"},
+	{"kubernetes", "Compute"},
+	{"container", "Compute"},
+}
+
+// ExportFOCUS writes every rate of a snapshot to w as FOCUS CSV, streaming
+// page by page. Each row prices one PricingUnit: BilledCost, EffectiveCost,
+// ListCost and ListUnitPrice are all the list price, and the charge period
+// is the rate's effective date (else the snapshot's validity) onwards.
+func ExportFOCUS(ctx context.Context, store db.PricingStore, snapshotID uuid.UUID, w io.Writer) error {
+	snapshot, err := store.GetSnapshot(ctx, snapshotID)
+	if err != nil {
+		return fmt.Errorf("failed to load snapshot: %w", err)
+	}
+	if snapshot == nil {
+		return fmt.Errorf("snapshot %s not found", snapshotID)
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(FOCUSColumns); err != nil {
+		return fmt.Errorf("failed to write header: %w", err)
+	}
+	cursor := NewRateCursor(store, snapshotID, DefaultCursorBatchSize)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		page, err := cursor.Next(ctx)
+		if err != nil {
+			return err
+		}
+		if page == nil {
+			break
+		}
+		for _, sr := range page {
+			if err := cw.Write(focusRow(snapshot, sr)); err != nil {
+				return fmt.Errorf("failed to write rate: %w", err)
+			}
+		}
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return fmt.Errorf("failed to write rates: %w", err)
+		}
+	}
+	return nil
+}
+
+// focusRow maps one stored rate onto FOCUSColumns
+func focusRow(snapshot *db.PricingSnapshot, sr db.SnapshotRate) []string {
+	key, rate := sr.RateKey, sr.Rate
+	price := rate.Price.String()
+
+	start := snapshot.ValidFrom
+	if rate.EffectiveDate != nil {
+		start = *rate.EffectiveDate
+	}
+	var end string
+	if snapshot.ValidTo != nil {
+		end = snapshot.ValidTo.UTC().Format(time.RFC3339)
+	}
+
+	provider := focusProviderNames[key.Cloud]
+	if provider == "" {
+		provider = string(key.Cloud)
+	}
+
+	return []string{
+		price,
+		rate.Currency,
+		"Usage",
+		focusChargeDescription(sr),
+		start.UTC().Format(time.RFC3339),
+		end,
+		price,
+		price,
+		price,
+		focusPricingCategory(key.Attributes),
+		"1",
+		rate.Unit,
+		provider,
+		provider,
+		key.Region,
+		focusServiceCategory(key.Service, key.ProductFamily),
+		key.Service,
+		key.ID.String(),
+		rate.ID.String(),
+	}
+}
+
+// focusChargeDescription names the rate by product family, attributes and tier
+func focusChargeDescription(sr db.SnapshotRate) string {
+	parts := []string{sr.RateKey.Service}
+	if sr.RateKey.ProductFamily != "" {
+		parts = append(parts, sr.RateKey.ProductFamily)
+	}
+	keys := make([]string, 0, len(sr.RateKey.Attributes))
+	for k := range sr.RateKey.Attributes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		parts = append(parts, k+"="+sr.RateKey.Attributes[k])
+	}
+	if sr.Rate.TierMin != nil || sr.Rate.TierMax != nil {
+		tier := "tier "
+		if sr.Rate.TierMin != nil {
+			tier += sr.Rate.TierMin.String()
+		} else {
+			tier += "0"
+		}
+		tier += "-"
+		if sr.Rate.TierMax != nil {
+			tier += sr.Rate.TierMax.String()
+		}
+		parts = append(parts, tier)
+	}
+	return strings.Join(parts, " ")
+}
+
+// focusPricingCategory maps our purchase attributes onto the FOCUS
+// PricingCategory: Committed, Dynamic or Standard
+func focusPricingCategory(attrs map[string]string) string {
+	switch attrs["pricing_type"] {
+	case "reservation", "committed_use":
+		return "Committed"
+	case "spot", "low_priority", "preemptible":
+		return "Dynamic"
+	}
+	if attrs["purchase_option"] == "spot" {
+		return "Dynamic"
+	}
+	return "Standard"
+}
+
+// focusServiceCategory picks the FOCUS ServiceCategory of a service, falling
+// back to Other
+func focusServiceCategory(service, productFamily string) string {
+	name := strings.ToLower(service + " " + productFamily)
+	for _, c := range focusServiceCategories {
+		if strings.Contains(name, c.keyword) {
+			return c.category
+		}
+	}
+	return "Other"
+}
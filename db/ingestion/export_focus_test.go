@@ -0,0 +1,96 @@
+// Package ingestion - FOCUS export tests
+package ingestion
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"reflect"
+	"testing"
+	"time"
+
+	"terraform-cost/db"
+	"terraform-cost/db/memstore"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+func TestExportFOCUS(t *testing.T) {
+	ctx := context.Background()
+	store := memstore.NewMemoryStore()
+	tierMax := decimal.NewFromInt(51200)
+	rates := []NormalizedRate{
+		{
+			RateKey:  db.RateKey{Cloud: db.AWS, Service: "AmazonEC2", ProductFamily: "Compute Instance", Region: "us-east-1", Attributes: map[string]string{"instance_type": "m5.large", "purchase_option": "spot"}},
+			Unit:     "Hrs",
+			Price:    decimal.RequireFromString("0.035"),
+			Currency: "USD",
+		},
+		{
+			RateKey:  db.RateKey{Cloud: db.AWS, Service: "AmazonS3", ProductFamily: "Storage", Region: "us-east-1", Attributes: map[string]string{"storage_class": "standard"}},
+			Unit:     "GB-Mo",
+			Price:    decimal.RequireFromString("0.023"),
+			Currency: "USD",
+			TierMax:  &tierMax,
+		},
+	}
+	validFrom := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	snapshot := &db.PricingSnapshot{ID: uuid.New(), Cloud: db.AWS, Region: "us-east-1", ProviderAlias: "default", Source: "test", ValidFrom: validFrom, Hash: calculateHash(rates)}
+	if err := commitSnapshotRates(ctx, store, snapshot, rates); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := ExportFOCUS(ctx, store, snapshot.ID, &buf); err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("output is not CSV: %v", err)
+	}
+	if !reflect.DeepEqual(records[0], FOCUSColumns) {
+		t.Fatalf("unexpected header %v", records[0])
+	}
+	if len(records) != 3 {
+		t.Fatalf("expected a header and 2 rows, got %d records", len(records))
+	}
+
+	rows := make(map[string]map[string]string)
+	for _, record := range records[1:] {
+		row := make(map[string]string)
+		for i, col := range FOCUSColumns {
+			row[col] = record[i]
+		}
+		rows[row["ServiceName"]] = row
+	}
+
+	want := map[string]map[string]string{
+		"AmazonEC2": {
+			"BilledCost": "0.035", "ListUnitPrice": "0.035", "BillingCurrency": "USD", "PricingUnit": "Hrs",
+			"PricingQuantity": "1", "PricingCategory": "Dynamic", "ServiceCategory": "Compute",
+			"ProviderName": "AWS", "RegionId": "us-east-1", "ChargeCategory": "Usage",
+			"ChargePeriodStart": "2025-03-01T00:00:00Z",
+			"ChargeDescription": "AmazonEC2 Compute Instance instance_type=m5.large purchase_option=spot",
+		},
+		"AmazonS3": {
+			"BilledCost": "0.023", "PricingUnit": "GB-Mo", "PricingCategory": "Standard", "ServiceCategory": "Storage",
+			"ChargeDescription": "AmazonS3 Storage storage_class=standard tier 0-51200",
+		},
+	}
+	for service, cols := range want {
+		row, ok := rows[service]
+		if !ok {
+			t.Errorf("missing %s row", service)
+			continue
+		}
+		for col, value := range cols {
+			if row[col] != value {
+				t.Errorf("%s %s = %q, want %q", service, col, row[col], value)
+			}
+		}
+		if _, err := uuid.Parse(row["SkuPriceId"]); err != nil {
+			t.Errorf("%s: expected the rate ID as SkuPriceId, got %q", service, row["SkuPriceId"])
+		}
+	}
+}
@@ -0,0 +1,74 @@
+// Package ingestion - Streaming snapshot export tests
+package ingestion
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"terraform-cost/db"
+	"terraform-cost/db/memstore"
+
+	"github.com/google/uuid"
+)
+
+func TestExportSnapshotJSONL(t *testing.T) {
+	ctx := context.Background()
+	store := memstore.NewMemoryStore()
+	seedSnapshot(t, store, "us-east-1", 25)
+
+	snapshot, _ := store.GetActiveSnapshot(ctx, db.AWS, "us-east-1", "default")
+	count, _ := store.CountRates(ctx, snapshot.ID)
+
+	var buf bytes.Buffer
+	if err := ExportSnapshotJSONL(ctx, store, snapshot.ID, &buf); err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+
+	var lines int
+	var rates []NormalizedRate
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		lines++
+		var rate NormalizedRate
+		if err := json.Unmarshal(scanner.Bytes(), &rate); err != nil {
+			t.Fatalf("line %d does not parse: %v", lines, err)
+		}
+		rates = append(rates, rate)
+	}
+	if lines != count {
+		t.Fatalf("exported %d lines, snapshot has %d rates", lines, count)
+	}
+	if calculateHash(rates) != snapshot.Hash {
+		t.Error("exported rates do not hash to the snapshot's content hash")
+	}
+}
+
+func TestRateCursorPages(t *testing.T) {
+	ctx := context.Background()
+	store := memstore.NewMemoryStore()
+	seedSnapshot(t, store, "us-east-1", 7)
+	snapshot, _ := store.GetActiveSnapshot(ctx, db.AWS, "us-east-1", "default")
+
+	cursor := NewRateCursor(store, snapshot.ID, 3)
+	var sizes []int
+	for {
+		page, err := cursor.Next(ctx)
+		if err != nil {
+			t.Fatalf("cursor failed: %v", err)
+		}
+		if page == nil {
+			break
+		}
+		sizes = append(sizes, len(page))
+	}
+	if len(sizes) != 3 || sizes[0] != 3 || sizes[1] != 3 || sizes[2] != 1 {
+		t.Errorf("expected pages of [3 3 1], got %v", sizes)
+	}
+
+	if err := ExportSnapshotJSONL(ctx, store, uuid.New(), &bytes.Buffer{}); err == nil {
+		t.Error("expected an error for an unknown snapshot")
+	}
+}
@@ -0,0 +1,131 @@
+// Package ingestion - Response body lifecycle tests for paginated fetchers
+package ingestion
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// trackingBody records when a response body is closed
+type trackingBody struct {
+	io.Reader
+	closed bool
+}
+
+func (b *trackingBody) Close() error {
+	b.closed = true
+	return nil
+}
+
+// trackingTransport serves canned responses and asserts that every
+// previously returned body has been closed before the next request is made.
+type trackingTransport struct {
+	t       *testing.T
+	mu      sync.Mutex
+	bodies  []*trackingBody
+	respond func(req *http.Request) string
+}
+
+func (tr *trackingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+
+	for i, b := range tr.bodies {
+		if !b.closed {
+			tr.t.Errorf("request %d (%s) issued while body %d is still open", len(tr.bodies), req.URL, i)
+		}
+	}
+
+	body := &trackingBody{Reader: strings.NewReader(tr.respond(req))}
+	tr.bodies = append(tr.bodies, body)
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     make(http.Header),
+		Body:       body,
+		Request:    req,
+	}, nil
+}
+
+// allClosed reports whether every body handed out has been closed
+func (tr *trackingTransport) allClosed() bool {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	for _, b := range tr.bodies {
+		if !b.closed {
+			return false
+		}
+	}
+	return true
+}
+
+func TestGCPPaginationClosesBodiesBetweenPages(t *testing.T) {
+	transport := &trackingTransport{t: t, respond: func(req *http.Request) string {
+		page := req.URL.Query().Get("pageToken")
+		next := map[string]string{"": "p2", "p2": "p3", "p3": ""}[page]
+		if strings.HasSuffix(req.URL.Path, "/skus") {
+			return fmt.Sprintf(`{"skus": [{"skuId": "sku-%s", "description": "SKU", "category": {"serviceDisplayName": "Cloud Storage"},
+				"pricingInfo": [{"pricingExpression": {"usageUnit": "GiBy.mo", "tieredRates": [{"unitPrice": {"currencyCode": "USD", "units": "0", "nanos": 20000000}}]}}]}],
+				"nextPageToken": %q}`, page, next)
+		}
+		return fmt.Sprintf(`{"services": [{"name": "services/%s", "displayName": "svc"}], "nextPageToken": %q}`, page, next)
+	}}
+
+	cfg := DefaultGCPPricingConfig()
+	cfg.RequestsPerSecond = 0
+	client := NewGCPPricingAPIClient(cfg)
+	client.baseURL = "http://gcp.test/v1"
+	client.httpClient = &http.Client{Transport: transport}
+
+	services, err := client.listServices(context.Background())
+	if err != nil {
+		t.Fatalf("listServices failed: %v", err)
+	}
+	if len(services) != 3 {
+		t.Errorf("expected 3 services, got %d", len(services))
+	}
+
+	prices, err := client.fetchServiceSKUs(context.Background(), "services/95FF-2EF5-5EA1", "global")
+	if err != nil {
+		t.Fatalf("fetchServiceSKUs failed: %v", err)
+	}
+	if len(prices) != 3 {
+		t.Errorf("expected 3 prices, got %d", len(prices))
+	}
+
+	if len(transport.bodies) != 6 {
+		t.Errorf("expected 6 requests, got %d", len(transport.bodies))
+	}
+	if !transport.allClosed() {
+		t.Error("expected all response bodies to be closed")
+	}
+}
+
+func TestAWSFetchClosesIndexBodyBeforePriceList(t *testing.T) {
+	transport := &trackingTransport{t: t, respond: func(req *http.Request) string {
+		if strings.HasSuffix(req.URL.Path, "region_index.json") {
+			return `{"regions": {"us-east-1": {"currentVersionUrl": "/offers/v1.0/aws/AmazonS3/20240101/us-east-1/index.json"}}}`
+		}
+		return `{"products": {"SKU1": {"sku": "SKU1", "productFamily": "Storage", "attributes": {"regionCode": "us-east-1", "storageClass": "General Purpose"}}},
+			"terms": {"OnDemand": {"SKU1": {"SKU1.T1": {"sku": "SKU1", "priceDimensions": {"SKU1.T1.D1": {"unit": "GB-Mo", "beginRange": "0", "endRange": "Inf", "pricePerUnit": {"USD": "0.023"}}}}}}}}`
+	}}
+
+	fetcher := NewAWSPricingAPIFetcher()
+	fetcher.baseURL = "http://aws.test"
+	fetcher.httpClient = &http.Client{Transport: transport}
+
+	prices, err := fetcher.fetchServicePricing(context.Background(), "AmazonS3", "us-east-1")
+	if err != nil {
+		t.Fatalf("fetchServicePricing failed: %v", err)
+	}
+	if len(prices) != 1 {
+		t.Errorf("expected 1 price, got %d", len(prices))
+	}
+	if len(transport.bodies) != 2 || !transport.allClosed() {
+		t.Errorf("expected 2 closed bodies, got %d (all closed: %v)", len(transport.bodies), transport.allClosed())
+	}
+}
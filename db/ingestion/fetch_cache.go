@@ -0,0 +1,191 @@
+// Package ingestion - Disk cache for raw fetch results
+// Development re-runs serve a region from the cache instead of calling the
+// pricing API again, until the cached copy is older than the TTL.
+package ingestion
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"terraform-cost/db"
+)
+
+// DefaultFetchCacheTTL is how long a cached region stays fresh
+const DefaultFetchCacheTTL = 24 * time.Hour
+
+// CachingFetcher wraps a fetcher and caches its FetchRegion results on disk
+// under <dir>/<provider>/<region>.raw.json.gz, or under
+// <dir>/<provider>/<alias>/ for a fetcher ingested under its own provider
+// alias (such as AWS spot prices). A region is fetched from the
+// wrapped fetcher only when its cache file is missing, unreadable or older
+// than the TTL. Failed and partial fetches are never cached.
+type CachingFetcher struct {
+	inner    PriceFetcher
+	dir      string
+	ttl      time.Duration
+	services []string
+	alias    string
+	logger   *slog.Logger
+	now      func() time.Time
+}
+
+// NewCachingFetcher creates a cache over inner in dir. A ttl <= 0 uses
+// DefaultFetchCacheTTL.
+func NewCachingFetcher(inner PriceFetcher, dir string, ttl time.Duration) *CachingFetcher {
+	if ttl <= 0 {
+		ttl = DefaultFetchCacheTTL
+	}
+	return &CachingFetcher{inner: inner, dir: dir, ttl: ttl, now: time.Now}
+}
+
+// Cloud implements PriceFetcher
+func (f *CachingFetcher) Cloud() db.CloudProvider {
+	return f.inner.Cloud()
+}
+
+// SupportedRegions implements PriceFetcher
+func (f *CachingFetcher) SupportedRegions() []string {
+	return f.inner.SupportedRegions()
+}
+
+// SupportedServices implements PriceFetcher
+func (f *CachingFetcher) SupportedServices() []string {
+	return f.inner.SupportedServices()
+}
+
+// IsRealAPI implements RealAPIFetcher by reporting the wrapped fetcher
+func (f *CachingFetcher) IsRealAPI() bool {
+	return isRealAPI(f.inner)
+}
+
+// SetAllowedServices restricts the wrapped fetcher when it supports service
+// filtering. Each service selection is cached separately.
+func (f *CachingFetcher) SetAllowedServices(services []string) {
+	if filterable, ok := f.inner.(serviceFilterable); ok {
+		filterable.SetAllowedServices(services)
+		f.services = services
+	}
+}
+
+// SetAlias keeps the wrapped fetcher's results apart from those of other
+// fetchers for the same provider; "" and "default" share the provider cache
+func (f *CachingFetcher) SetAlias(alias string) {
+	f.alias = alias
+}
+
+// SetLogger sets the logger for cache hits and misses and hands it to the
+// wrapped fetcher when it accepts one
+func (f *CachingFetcher) SetLogger(logger *slog.Logger) {
+	f.logger = logger
+	if ls, ok := f.inner.(loggerSetter); ok {
+		ls.SetLogger(logger)
+	}
+}
+
+// FetchRegion serves region from the cache when fresh, otherwise fetches it
+// and refreshes the cache
+func (f *CachingFetcher) FetchRegion(ctx context.Context, region string) ([]RawPrice, error) {
+	path := f.cachePath(region)
+	if dump, err := readFetchCache(path); err == nil {
+		age := f.now().Sub(dump.Timestamp)
+		if age >= 0 && age < f.ttl {
+			loggerOrDefault(f.logger).Debug("serving fetch from cache", "provider", f.Cloud(), "region", region, "file", path, "age", age.Round(time.Second), "raw_count", dump.RawCount)
+			return dump.Prices, nil
+		}
+	}
+
+	prices, err := f.inner.FetchRegion(ctx, region)
+	if err != nil {
+		return prices, err
+	}
+	dump := &RawPriceDump{
+		Provider:  f.Cloud(),
+		Region:    region,
+		Timestamp: f.now(),
+		RealAPI:   f.IsRealAPI(),
+		RawCount:  len(prices),
+		Prices:    prices,
+	}
+	if err := writeFetchCache(path, dump); err != nil {
+		loggerOrDefault(f.logger).Warn("failed to cache fetch", "provider", f.Cloud(), "region", region, "error", err)
+	}
+	return prices, nil
+}
+
+// cachePath names the cache file for region, the alias and the current
+// service selection
+func (f *CachingFetcher) cachePath(region string) string {
+	name := region
+	if len(f.services) > 0 {
+		services := append([]string(nil), f.services...)
+		sort.Strings(services)
+		sum := sha256.Sum256([]byte(strings.Join(services, ",")))
+		name += "-" + hex.EncodeToString(sum[:4])
+	}
+	dir := filepath.Join(f.dir, string(f.Cloud()))
+	if f.alias != "" && f.alias != "default" {
+		dir = filepath.Join(dir, f.alias)
+	}
+	return filepath.Join(dir, name+rawPricesSuffix)
+}
+
+// readFetchCache reads a cached dump, rejecting a truncated one
+func readFetchCache(path string) (*RawPriceDump, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	gzReader, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, err
+	}
+	defer gzReader.Close()
+
+	var dump RawPriceDump
+	if err := json.NewDecoder(gzReader).Decode(&dump); err != nil {
+		return nil, err
+	}
+	if len(dump.Prices) != dump.RawCount {
+		return nil, fmt.Errorf("cached price count mismatch: header says %d, actual %d", dump.RawCount, len(dump.Prices))
+	}
+	return &dump, nil
+}
+
+// writeFetchCache writes dump to a temporary file and renames it into place,
+// so a concurrent or interrupted run never reads a partial cache
+func writeFetchCache(path string, dump *RawPriceDump) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	gzWriter := gzip.NewWriter(tmp)
+	if err := json.NewEncoder(gzWriter).Encode(dump); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := gzWriter.Close(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,90 @@
+// Package ingestion - Fetch cache tests
+package ingestion
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"terraform-cost/db"
+)
+
+// countingFetcher counts FetchRegion calls on a static price list
+type countingFetcher struct {
+	staticFetcher
+	calls int
+}
+
+func (f *countingFetcher) FetchRegion(ctx context.Context, region string) ([]RawPrice, error) {
+	f.calls++
+	return f.staticFetcher.FetchRegion(ctx, region)
+}
+
+func TestCachingFetcherHitMissAndExpiry(t *testing.T) {
+	ctx := context.Background()
+	inner := &countingFetcher{staticFetcher: staticFetcher{cloud: db.AWS, prices: testRawPrices("us-east-1", 4)}}
+	cache := NewCachingFetcher(inner, t.TempDir(), time.Hour)
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return now }
+
+	// Miss: fetched and written to disk
+	prices, err := cache.FetchRegion(ctx, "us-east-1")
+	if err != nil || len(prices) != 4 || inner.calls != 1 {
+		t.Fatalf("expected a miss to fetch 4 prices once, got %d prices, %d calls, %v", len(prices), inner.calls, err)
+	}
+
+	// Hit: served from disk while fresh
+	now = now.Add(59 * time.Minute)
+	prices, err = cache.FetchRegion(ctx, "us-east-1")
+	if err != nil || len(prices) != 4 || inner.calls != 1 {
+		t.Fatalf("expected a hit without fetching, got %d prices, %d calls, %v", len(prices), inner.calls, err)
+	}
+	if prices[0].SKU != inner.prices[0].SKU || prices[0].PricePerUnit != inner.prices[0].PricePerUnit {
+		t.Errorf("cached price differs: %+v vs %+v", prices[0], inner.prices[0])
+	}
+
+	// Another region is a separate entry
+	if _, err := cache.FetchRegion(ctx, "us-west-2"); err != nil || inner.calls != 2 {
+		t.Fatalf("expected us-west-2 to miss, got %d calls, %v", inner.calls, err)
+	}
+
+	// Expiry: refetched once the TTL has passed, then fresh again
+	now = now.Add(2 * time.Minute)
+	if _, err := cache.FetchRegion(ctx, "us-east-1"); err != nil || inner.calls != 3 {
+		t.Fatalf("expected an expired entry to refetch, got %d calls, %v", inner.calls, err)
+	}
+	if _, err := cache.FetchRegion(ctx, "us-east-1"); err != nil || inner.calls != 3 {
+		t.Fatalf("expected the refreshed entry to hit, got %d calls, %v", inner.calls, err)
+	}
+}
+
+func TestCachingFetcherReportsWrappedRealAPI(t *testing.T) {
+	if !NewCachingFetcher(&staticFetcher{cloud: db.AWS}, t.TempDir(), 0).IsRealAPI() {
+		t.Error("expected a fetcher without IsRealAPI to pass through as real")
+	}
+	if NewCachingFetcher(NewFileFetcher(db.AWS, t.TempDir()), t.TempDir(), 0).IsRealAPI() {
+		t.Error("expected a cached file fetcher to report it is not a real API")
+	}
+}
+
+func TestCachingFetcherSeparatesAliases(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	onDemand := &countingFetcher{staticFetcher: staticFetcher{cloud: db.AWS, prices: testRawPrices("us-east-1", 4)}}
+	spot := &countingFetcher{staticFetcher: staticFetcher{cloud: db.AWS, prices: testRawPrices("us-east-1", 2)}}
+
+	if _, err := NewCachingFetcher(onDemand, dir, time.Hour).FetchRegion(ctx, "us-east-1"); err != nil {
+		t.Fatal(err)
+	}
+	spotCache := NewCachingFetcher(spot, dir, time.Hour)
+	spotCache.SetAlias(SpotAlias)
+
+	// The on-demand entry for the same provider and region must not be served
+	prices, err := spotCache.FetchRegion(ctx, "us-east-1")
+	if err != nil || len(prices) != 2 || spot.calls != 1 {
+		t.Fatalf("expected the spot fetch to miss, got %d prices, %d calls, %v", len(prices), spot.calls, err)
+	}
+	if prices, _ := spotCache.FetchRegion(ctx, "us-east-1"); len(prices) != 2 || spot.calls != 1 {
+		t.Errorf("expected the spot entry to hit, got %d prices, %d calls", len(prices), spot.calls)
+	}
+}
@@ -0,0 +1,180 @@
+// Package ingestion - Partial fetch policy shared by all fetchers
+// Fetchers keep going when single services fail and report the failures;
+// the pipeline decides whether enough of the catalog arrived to continue.
+package ingestion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"terraform-cost/db"
+)
+
+// DefaultMinServicesFraction is the share of services that must fetch cleanly
+const DefaultMinServicesFraction = 0.8
+
+// DefaultCircuitBreakerThreshold is how many services in a row may fail
+// before FetchRegion stops calling a provider API that looks down
+const DefaultCircuitBreakerThreshold = 3
+
+// ServiceTimeout bounds how long one service may take within FetchRegion,
+// so a pathological service is skipped with a warning instead of using up
+// the whole fetch budget. The zero value sets no per-service deadline.
+type ServiceTimeout struct {
+	// Fixed caps every service at this duration (0 = no fixed cap)
+	Fixed time.Duration
+
+	// FairShare caps each service at the time left before the fetch
+	// context's deadline divided by the services still to fetch
+	FairShare bool
+}
+
+// context derives the deadline for the next service; remaining counts the
+// services still to fetch, including this one
+func (t ServiceTimeout) context(ctx context.Context, remaining int) (context.Context, context.CancelFunc) {
+	limit := t.Fixed
+	if t.FairShare && remaining > 0 {
+		if deadline, ok := ctx.Deadline(); ok {
+			if share := time.Until(deadline) / time.Duration(remaining); limit == 0 || share < limit {
+				limit = share
+			}
+		}
+	}
+	if limit <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, limit)
+}
+
+// serviceTimedOut wraps err when the service ran out of its own deadline
+// while the fetch as a whole still has time
+func serviceTimedOut(ctx, serviceCtx context.Context, err error) error {
+	if ctx.Err() == nil && errors.Is(serviceCtx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("per-service timeout exceeded, skipped: %w", err)
+	}
+	return err
+}
+
+// ServiceFetchError is one service whose fetch failed
+type ServiceFetchError struct {
+	Service string
+	Err     error
+}
+
+// PartialFetchError is returned by FetchRegion alongside the prices that
+// were fetched when one or more services failed
+type PartialFetchError struct {
+	Provider db.CloudProvider
+	Region   string
+	Expected int // services attempted
+	Failed   []ServiceFetchError
+}
+
+func (e *PartialFetchError) Error() string {
+	parts := make([]string, len(e.Failed))
+	for i, f := range e.Failed {
+		parts[i] = fmt.Sprintf("%s: %v", f.Service, f.Err)
+	}
+	return fmt.Sprintf("%d of %d %s services failed in %s: %s",
+		len(e.Failed), e.Expected, e.Provider, e.Region, strings.Join(parts, "; "))
+}
+
+// SucceededFraction is the share of attempted services that fetched cleanly
+func (e *PartialFetchError) SucceededFraction() float64 {
+	if e.Expected == 0 {
+		return 0
+	}
+	return float64(e.Expected-len(e.Failed)) / float64(e.Expected)
+}
+
+// CircuitOpenError is returned by FetchRegion when consecutive service
+// failures tripped the circuit breaker and the remaining services were skipped
+type CircuitOpenError struct {
+	Provider    db.CloudProvider
+	Region      string
+	Consecutive int // failures in a row that tripped the breaker
+	Skipped     int // services not attempted
+	Last        error
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("%s pricing API looks unavailable in %s: %d consecutive service fetches failed, skipped %d remaining: %v",
+		e.Provider, e.Region, e.Consecutive, e.Skipped, e.Last)
+}
+
+func (e *CircuitOpenError) Unwrap() error {
+	return e.Last
+}
+
+// serviceFailures collects per-service errors during one FetchRegion call.
+// A new value per call means the circuit breaker resets per region.
+type serviceFailures struct {
+	provider    db.CloudProvider
+	region      string
+	expected    int
+	threshold   int // consecutive failures that open the circuit (0 = never)
+	consecutive int
+	failed      []ServiceFetchError
+}
+
+// add records a failed service
+func (s *serviceFailures) add(service string, err error) {
+	s.failed = append(s.failed, ServiceFetchError{Service: service, Err: err})
+	s.consecutive++
+}
+
+// succeeded records a service that fetched cleanly, closing the circuit
+func (s *serviceFailures) succeeded() {
+	s.consecutive = 0
+}
+
+// open reports whether enough services failed in a row to stop fetching.
+// done is how many services were attempted so far.
+func (s *serviceFailures) open(done int) *CircuitOpenError {
+	if s.threshold <= 0 || s.consecutive < s.threshold {
+		return nil
+	}
+	return &CircuitOpenError{
+		Provider:    s.provider,
+		Region:      s.region,
+		Consecutive: s.consecutive,
+		Skipped:     s.expected - done,
+		Last:        s.failed[len(s.failed)-1].Err,
+	}
+}
+
+// err returns a *PartialFetchError, or nil when every service succeeded
+func (s *serviceFailures) err() error {
+	if len(s.failed) == 0 {
+		return nil
+	}
+	return &PartialFetchError{
+		Provider: s.provider,
+		Region:   s.region,
+		Expected: s.expected,
+		Failed:   s.failed,
+	}
+}
+
+// applyMinServices enforces the minimum-services guard on a fetch result.
+// A partial fetch at or above minFraction is tolerated and returned as
+// partial so the caller can log it; anything else is a hard failure.
+// A minFraction of 0 tolerates any partial fetch.
+func applyMinServices(prices []RawPrice, err error, minFraction float64) ([]RawPrice, *PartialFetchError, error) {
+	if err == nil {
+		return prices, nil, nil
+	}
+
+	var partial *PartialFetchError
+	if !errors.As(err, &partial) {
+		return nil, nil, err
+	}
+	if partial.SucceededFraction() < minFraction {
+		return nil, nil, fmt.Errorf("only %d of %d services returned data (minimum %.0f%%): %w",
+			partial.Expected-len(partial.Failed), partial.Expected, minFraction*100, partial)
+	}
+	return prices, partial, nil
+}
@@ -0,0 +1,258 @@
+// Package ingestion - Partial fetch policy tests
+package ingestion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"terraform-cost/db"
+)
+
+// partialFetcher returns prices together with n failed services out of total
+type partialFetcher struct {
+	prices        []RawPrice
+	failed, total int
+}
+
+func (f *partialFetcher) Cloud() db.CloudProvider     { return db.AWS }
+func (f *partialFetcher) SupportedRegions() []string  { return nil }
+func (f *partialFetcher) SupportedServices() []string { return nil }
+func (f *partialFetcher) FetchRegion(ctx context.Context, region string) ([]RawPrice, error) {
+	failures := &serviceFailures{provider: db.AWS, region: region, expected: f.total}
+	for i := 0; i < f.failed; i++ {
+		failures.add(fmt.Sprintf("Service%d", i), errors.New("status 503"))
+	}
+	return f.prices, failures.err()
+}
+
+func TestPipelineMinServicesGuard(t *testing.T) {
+	tests := []struct {
+		name          string
+		failed, total int
+		wantAbort     bool
+	}{
+		{"2 of 3 failed", 2, 3, true},
+		{"1 of 10 failed", 1, 10, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetcher := &partialFetcher{prices: testRawPrices("us-east-1", 3), failed: tt.failed, total: tt.total}
+			pipeline := NewPipeline(fetcher, &passthroughNormalizer{cloud: db.AWS}, &emptyStore{})
+
+			config := DefaultPipelineConfig()
+			config.Provider = db.AWS
+			config.Region = "us-east-1"
+			config.BackupDir = t.TempDir()
+			config.DryRun = true
+
+			result, err := pipeline.Execute(context.Background(), config)
+			if err != nil {
+				t.Fatalf("execute failed: %v", err)
+			}
+
+			aborted := result.FailedPhase == PhaseFetch
+			if aborted != tt.wantAbort {
+				t.Fatalf("aborted at fetch = %t, want %t (error: %s)", aborted, tt.wantAbort, result.Error)
+			}
+			if tt.wantAbort && (!strings.Contains(result.Error, "Service0") || !strings.Contains(result.Error, "Service1")) {
+				t.Errorf("expected failed services listed, got %q", result.Error)
+			}
+			if !tt.wantAbort && result.Stats.RawPricesCount != 3 {
+				t.Errorf("expected the fetched prices to be kept, got %d", result.Stats.RawPricesCount)
+			}
+		})
+	}
+}
+
+func TestStreamingLifecycleMinServicesGuard(t *testing.T) {
+	tests := []struct {
+		name          string
+		failed, total int
+		wantAbort     bool
+	}{
+		{"2 of 3 failed", 2, 3, true},
+		{"1 of 10 failed", 1, 10, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			streamCfg := DefaultStreamingConfig()
+			streamCfg.WorkDir = t.TempDir()
+			fetcher := &partialFetcher{prices: testRawPrices("us-east-1", 3), failed: tt.failed, total: tt.total}
+
+			config := DefaultLifecycleConfig()
+			config.Provider = db.AWS
+			config.Region = "us-east-1"
+			config.BackupDir = t.TempDir()
+			config.DryRun = true
+
+			result, _ := NewStreamingLifecycle(fetcher, &passthroughNormalizer{cloud: db.AWS}, &emptyStore{}, streamCfg).Execute(context.Background(), config)
+			if result.Success == tt.wantAbort {
+				t.Fatalf("success = %t, want %t (error: %v)", result.Success, !tt.wantAbort, result.Error)
+			}
+			if !tt.wantAbort && result.RawCount != 3 {
+				t.Errorf("expected the fetched prices to be kept, got %d", result.RawCount)
+			}
+		})
+	}
+}
+
+func TestAWSFetchRegionReportsFailedServices(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.Contains(r.URL.Path, "/AmazonS3/current/region_index.json"):
+			fmt.Fprint(w, `{"regions": {"us-east-1": {"currentVersionUrl": "/offers/v1.0/aws/AmazonS3/20240101/us-east-1/index.json"}}}`)
+		case strings.HasSuffix(r.URL.Path, "/AmazonS3/20240101/us-east-1/index.json"):
+			fmt.Fprint(w, `{"products": {"SKU1": {"sku": "SKU1", "productFamily": "Storage", "attributes": {"regionCode": "us-east-1"}}},
+				"terms": {"OnDemand": {"SKU1": {"SKU1.T1": {"sku": "SKU1", "priceDimensions": {"SKU1.T1.D1": {"unit": "GB-Mo", "beginRange": "0", "endRange": "Inf", "pricePerUnit": {"USD": "0.023"}}}}}}}}`)
+		default:
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	fetcher := NewAWSPricingAPIFetcher()
+	fetcher.baseURL = server.URL
+	fetcher.SetAllowedServices([]string{"AmazonS3", "AmazonEC2", "AmazonRDS"})
+
+	prices, err := fetcher.FetchRegion(context.Background(), "us-east-1")
+	if len(prices) != 1 {
+		t.Errorf("expected S3 prices to be returned, got %d", len(prices))
+	}
+
+	var partial *PartialFetchError
+	if !errors.As(err, &partial) {
+		t.Fatalf("expected *PartialFetchError, got %v", err)
+	}
+	if partial.Expected != 3 || len(partial.Failed) != 2 || partial.Failed[0].Service != "AmazonEC2" {
+		t.Errorf("unexpected partial fetch error: %+v", partial)
+	}
+}
+
+func TestAWSFetchRegionCircuitBreaker(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	fetcher := NewAWSPricingAPIFetcher()
+	fetcher.baseURL = server.URL
+	fetcher.SetAllowedServices([]string{"AmazonEC2", "AmazonRDS", "AmazonS3", "AWSLambda", "AWSQueueService", "AmazonSNS"})
+	fetcher.SetCircuitBreakerThreshold(2)
+
+	prices, err := fetcher.FetchRegion(context.Background(), "us-east-1")
+	if prices != nil {
+		t.Errorf("expected no prices, got %d", len(prices))
+	}
+	var open *CircuitOpenError
+	if !errors.As(err, &open) {
+		t.Fatalf("expected *CircuitOpenError, got %v", err)
+	}
+	if n := atomic.LoadInt32(&requests); n != 2 || open.Consecutive != 2 || open.Skipped != 4 {
+		t.Errorf("expected abort after 2 requests skipping 4 services, got %d requests, %+v", n, open)
+	}
+
+	// The breaker is a hard failure, not a tolerable partial fetch
+	if _, partial, err := applyMinServices(prices, err, 0); err == nil || partial != nil {
+		t.Errorf("expected circuit open to fail the fetch, got partial %v", partial)
+	}
+
+	// Each FetchRegion call starts with a closed circuit
+	atomic.StoreInt32(&requests, 0)
+	if _, err := fetcher.FetchRegion(context.Background(), "us-west-2"); !errors.As(err, &open) || atomic.LoadInt32(&requests) != 2 {
+		t.Errorf("expected the breaker to reset per region, got %d requests (err %v)", atomic.LoadInt32(&requests), err)
+	}
+
+	// Disabled breaker tries every service
+	fetcher.SetCircuitBreakerThreshold(0)
+	atomic.StoreInt32(&requests, 0)
+	if _, err := fetcher.FetchRegion(context.Background(), "us-east-1"); errors.As(err, &open) || atomic.LoadInt32(&requests) != 6 {
+		t.Errorf("expected all 6 services attempted without a breaker, got %d requests (err %v)", atomic.LoadInt32(&requests), err)
+	}
+}
+
+func TestAWSFetchRegionSkipsHungService(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.Contains(r.URL.Path, "/AmazonEC2/"):
+			<-r.Context().Done() // a price list that never finishes
+		case strings.HasSuffix(r.URL.Path, "/current/region_index.json"):
+			service := strings.Split(r.URL.Path, "/")[4]
+			fmt.Fprintf(w, `{"regions": {"us-east-1": {"currentVersionUrl": "/offers/v1.0/aws/%s/20240101/us-east-1/index.json"}}}`, service)
+		default:
+			fmt.Fprint(w, `{"products": {"SKU1": {"sku": "SKU1", "productFamily": "Storage", "attributes": {"regionCode": "us-east-1"}}},
+				"terms": {"OnDemand": {"SKU1": {"SKU1.T1": {"sku": "SKU1", "priceDimensions": {"SKU1.T1.D1": {"unit": "GB-Mo", "pricePerUnit": {"USD": "0.023"}}}}}}}}`)
+		}
+	}))
+	defer server.Close()
+
+	fetcher := NewAWSPricingAPIFetcher()
+	fetcher.baseURL = server.URL
+	fetcher.SetAllowedServices([]string{"AmazonEC2", "AmazonS3", "AmazonRDS"})
+
+	assertSkipped := func(ctx context.Context) {
+		t.Helper()
+		start := time.Now()
+		prices, err := fetcher.FetchRegion(ctx, "us-east-1")
+		if len(prices) != 2 {
+			t.Errorf("expected S3 and RDS prices after the hung service, got %d", len(prices))
+		}
+		var partial *PartialFetchError
+		if !errors.As(err, &partial) || len(partial.Failed) != 1 || partial.Failed[0].Service != "AmazonEC2" ||
+			!strings.Contains(partial.Failed[0].Err.Error(), "per-service timeout") {
+			t.Fatalf("expected only EC2 to time out, got %v", err)
+		}
+		if elapsed := time.Since(start); elapsed > 2*time.Second {
+			t.Errorf("expected the hung service to be cut short, took %s", elapsed)
+		}
+	}
+
+	fetcher.SetServiceTimeout(ServiceTimeout{Fixed: 100 * time.Millisecond})
+	assertSkipped(context.Background())
+
+	// Fair share: EC2 gets a third of the 900ms budget, leaving time for the rest
+	fetcher.SetServiceTimeout(ServiceTimeout{FairShare: true})
+	ctx, cancel := context.WithTimeout(context.Background(), 900*time.Millisecond)
+	defer cancel()
+	assertSkipped(ctx)
+}
+
+func TestServiceTimeoutContext(t *testing.T) {
+	parent, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+
+	tests := []struct {
+		timeout   ServiceTimeout
+		remaining int
+		want      time.Duration // 0 = parent deadline
+	}{
+		{ServiceTimeout{}, 4, 0},
+		{ServiceTimeout{Fixed: time.Minute}, 4, time.Minute},
+		{ServiceTimeout{FairShare: true}, 4, 15 * time.Minute},
+		{ServiceTimeout{Fixed: time.Minute, FairShare: true}, 4, time.Minute},
+		{ServiceTimeout{Fixed: time.Hour, FairShare: true}, 2, 30 * time.Minute},
+	}
+	for _, tt := range tests {
+		ctx, cancel := tt.timeout.context(parent, tt.remaining)
+		deadline, _ := ctx.Deadline()
+		cancel()
+
+		want, _ := parent.Deadline()
+		if tt.want > 0 {
+			want = time.Now().Add(tt.want)
+		}
+		if diff := deadline.Sub(want); diff < -time.Second || diff > time.Second {
+			t.Errorf("%+v with %d remaining: deadline off by %s", tt.timeout, tt.remaining, diff)
+		}
+	}
+}
@@ -0,0 +1,223 @@
+// Package ingestion - Offline fetcher for vendored price list files
+// Used for air-gapped environments and reproducible tests
+package ingestion
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"terraform-cost/db"
+)
+
+// FileFetcher loads pricing from a directory of JSON files instead of a live API.
+//
+// Prices for a region are read from <dir>/<region>.json and every
+// <dir>/<region>/*.json. Each file holds either a RawPrice array or an
+// AWS bulk price list (offer file) for a single service.
+type FileFetcher struct {
+	cloud    db.CloudProvider
+	dir      string
+	services []string
+	logger   *slog.Logger
+}
+
+// NewFileFetcher creates a fetcher reading one provider's price files from dir
+func NewFileFetcher(cloud db.CloudProvider, dir string) *FileFetcher {
+	return &FileFetcher{cloud: cloud, dir: dir}
+}
+
+// NewFileFetcherRegistry creates a registry of file fetchers for offline ingestion.
+// Each subdirectory of dir named after a cloud provider ("aws", "gcp", ...) gets
+// a FileFetcher paired with that provider's production normalizer.
+func NewFileFetcherRegistry(dir string) (*FetcherRegistry, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pricing directory: %w", err)
+	}
+
+	defaults := NewFetcherRegistry()
+	defaults.RegisterDefaults()
+
+	registry := NewFetcherRegistry()
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		cloud := db.CloudProvider(entry.Name())
+		normalizer, err := defaults.GetNormalizer(cloud)
+		if err != nil {
+			continue
+		}
+		registry.RegisterFetcher(cloud, NewFileFetcher(cloud, filepath.Join(dir, entry.Name())))
+		registry.RegisterNormalizer(cloud, normalizer)
+	}
+
+	return registry, nil
+}
+
+// Cloud implements PriceFetcher
+func (f *FileFetcher) Cloud() db.CloudProvider {
+	return f.cloud
+}
+
+// IsRealAPI implements RealAPIFetcher - vendored files are NOT a real API,
+// so production guards reject this fetcher
+func (f *FileFetcher) IsRealAPI() bool {
+	return false
+}
+
+// SupportedRegions returns the regions with price files in the directory
+func (f *FileFetcher) SupportedRegions() []string {
+	entries, err := os.ReadDir(f.dir)
+	if err != nil {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	var regions []string
+	for _, entry := range entries {
+		region := entry.Name()
+		if !entry.IsDir() {
+			if !strings.HasSuffix(region, ".json") {
+				continue
+			}
+			region = strings.TrimSuffix(region, ".json")
+		}
+		if !seen[region] {
+			seen[region] = true
+			regions = append(regions, region)
+		}
+	}
+	sort.Strings(regions)
+	return regions
+}
+
+// SupportedServices returns the allowed services (nil = every service in the files)
+func (f *FileFetcher) SupportedServices() []string {
+	return f.services
+}
+
+// SetAllowedServices restricts loading to the given services
+func (f *FileFetcher) SetAllowedServices(services []string) {
+	if len(services) > 0 {
+		f.services = services
+	}
+}
+
+// SetLogger sets the logger used for fetch warnings
+func (f *FileFetcher) SetLogger(logger *slog.Logger) {
+	f.logger = logger
+}
+
+// FetchRegion loads all price files for a region
+func (f *FileFetcher) FetchRegion(ctx context.Context, region string) ([]RawPrice, error) {
+	files, err := f.regionFiles(region)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no price files for %s region %s in %s", f.cloud, region, f.dir)
+	}
+
+	var allPrices []RawPrice
+	for _, path := range files {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		prices, err := f.loadFile(path, region)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load %s: %w", path, err)
+		}
+		allPrices = append(allPrices, f.filterServices(prices)...)
+		loggerOrDefault(f.logger).Debug("loaded price file", "provider", f.cloud, "region", region, "file", path, "rate_count", len(prices))
+	}
+
+	return allPrices, nil
+}
+
+// regionFiles lists the price files for a region in a stable order
+func (f *FileFetcher) regionFiles(region string) ([]string, error) {
+	var files []string
+
+	single := filepath.Join(f.dir, region+".json")
+	if _, err := os.Stat(single); err == nil {
+		files = append(files, single)
+	}
+
+	matches, err := filepath.Glob(filepath.Join(f.dir, region, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(matches)
+	return append(files, matches...), nil
+}
+
+// loadFile parses a RawPrice array or an AWS bulk price list
+func (f *FileFetcher) loadFile(path, region string) ([]RawPrice, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		var prices []RawPrice
+		if err := json.Unmarshal(trimmed, &prices); err != nil {
+			return nil, fmt.Errorf("failed to parse raw prices: %w", err)
+		}
+		return regionPrices(prices, region), nil
+	}
+
+	var offer struct {
+		OfferCode string `json:"offerCode"`
+	}
+	if err := json.Unmarshal(trimmed, &offer); err != nil {
+		return nil, fmt.Errorf("failed to parse price list: %w", err)
+	}
+	if offer.OfferCode == "" {
+		return nil, fmt.Errorf("unrecognized price file: expected a RawPrice array or an AWS offer file")
+	}
+	return (&AWSPricingAPIFetcher{}).parsePriceList(trimmed, offer.OfferCode, region)
+}
+
+// regionPrices fills in a missing region and drops prices for other regions
+func regionPrices(prices []RawPrice, region string) []RawPrice {
+	result := prices[:0]
+	for _, p := range prices {
+		if p.Region == "" {
+			p.Region = region
+		}
+		if p.Region == region {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+// filterServices keeps only the allowed services
+func (f *FileFetcher) filterServices(prices []RawPrice) []RawPrice {
+	if len(f.services) == 0 {
+		return prices
+	}
+	allowed := make(map[string]bool, len(f.services))
+	for _, s := range f.services {
+		allowed[s] = true
+	}
+	result := prices[:0]
+	for _, p := range prices {
+		if allowed[p.ServiceCode] {
+			result = append(result, p)
+		}
+	}
+	return result
+}
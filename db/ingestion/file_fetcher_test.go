@@ -0,0 +1,82 @@
+// Package ingestion - File fetcher tests
+package ingestion
+
+import (
+	"context"
+	"testing"
+
+	"terraform-cost/db"
+)
+
+func TestFileFetcherLoadsFixtureDirectory(t *testing.T) {
+	fetcher := NewFileFetcher(db.AWS, "testdata/pricing/aws")
+
+	if regions := fetcher.SupportedRegions(); len(regions) != 1 || regions[0] != "us-east-1" {
+		t.Errorf("expected [us-east-1], got %v", regions)
+	}
+
+	raw, err := fetcher.FetchRegion(context.Background(), "us-east-1")
+	if err != nil {
+		t.Fatalf("fetch failed: %v", err)
+	}
+
+	// One EC2 price from the RawPrice file (us-west-2 dropped), two S3 tiers from the offer file
+	services := make(map[string]int)
+	for _, p := range raw {
+		if p.Region != "us-east-1" {
+			t.Errorf("unexpected region %q", p.Region)
+		}
+		services[p.ServiceCode]++
+	}
+	if services["AmazonEC2"] != 1 || services["AmazonS3"] != 2 {
+		t.Fatalf("unexpected prices by service: %v", services)
+	}
+
+	rates, err := NewAWSPricingAPINormalizer().Normalize(context.Background(), raw)
+	if err != nil {
+		t.Fatalf("normalize failed: %v", err)
+	}
+	tiered := 0
+	for _, r := range rates {
+		if r.RateKey.Service == "AmazonS3" && r.TierMin != nil {
+			tiered++
+		}
+	}
+	if tiered != 1 {
+		t.Errorf("expected the second S3 tier to carry a tier minimum, got %d", tiered)
+	}
+
+	fetcher.SetAllowedServices([]string{"AmazonS3"})
+	raw, _ = fetcher.FetchRegion(context.Background(), "us-east-1")
+	if len(raw) != 2 {
+		t.Errorf("expected service filter to keep 2 S3 prices, got %d", len(raw))
+	}
+
+	if _, err := fetcher.FetchRegion(context.Background(), "eu-west-1"); err == nil {
+		t.Error("expected error for region without price files")
+	}
+}
+
+func TestFileFetcherRegistry(t *testing.T) {
+	registry, err := NewFileFetcherRegistry("testdata/pricing")
+	if err != nil {
+		t.Fatalf("registry failed: %v", err)
+	}
+
+	fetcher, err := registry.GetFetcher(db.AWS)
+	if err != nil {
+		t.Fatalf("expected AWS file fetcher: %v", err)
+	}
+	if _, ok := fetcher.(*FileFetcher); !ok {
+		t.Errorf("expected *FileFetcher, got %T", fetcher)
+	}
+	if registry.IsRealAPI(db.AWS) {
+		t.Error("file fetcher must not report a real API")
+	}
+	if _, err := registry.GetNormalizer(db.AWS); err != nil {
+		t.Errorf("expected AWS normalizer: %v", err)
+	}
+	if _, err := registry.GetFetcher(db.GCP); err == nil {
+		t.Error("expected no fetcher for a provider without a directory")
+	}
+}
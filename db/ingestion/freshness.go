@@ -0,0 +1,67 @@
+// Package ingestion - Snapshot freshness reporting for stale-pricing alerts
+package ingestion
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"terraform-cost/db"
+
+	"github.com/google/uuid"
+)
+
+// DefaultMaxSnapshotAge is the age after which an active snapshot is stale
+const DefaultMaxSnapshotAge = 7 * 24 * time.Hour
+
+// StaleSnapshot is an active snapshot older than the allowed age
+type StaleSnapshot struct {
+	SnapshotID uuid.UUID        `json:"snapshot_id"`
+	Cloud      db.CloudProvider `json:"cloud"`
+	Region     string           `json:"region"`
+	Alias      string           `json:"alias"`
+	FetchedAt  time.Time        `json:"fetched_at"`
+	Age        time.Duration    `json:"age"`
+}
+
+// FreshnessReport returns every active snapshot whose FetchedAt is older than
+// maxAge, ordered by cloud, region and alias
+func FreshnessReport(ctx context.Context, store db.PricingStore, maxAge time.Duration) ([]StaleSnapshot, error) {
+	snapshots, err := store.ListSnapshots(ctx, "", "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	now := time.Now()
+	var stale []StaleSnapshot
+	for _, s := range snapshots {
+		if !s.IsActive {
+			continue
+		}
+		age := now.Sub(s.FetchedAt)
+		if age <= maxAge {
+			continue
+		}
+		stale = append(stale, StaleSnapshot{
+			SnapshotID: s.ID,
+			Cloud:      s.Cloud,
+			Region:     s.Region,
+			Alias:      s.ProviderAlias,
+			FetchedAt:  s.FetchedAt,
+			Age:        age,
+		})
+	}
+
+	sort.Slice(stale, func(i, j int) bool {
+		if stale[i].Cloud != stale[j].Cloud {
+			return stale[i].Cloud < stale[j].Cloud
+		}
+		if stale[i].Region != stale[j].Region {
+			return stale[i].Region < stale[j].Region
+		}
+		return stale[i].Alias < stale[j].Alias
+	})
+
+	return stale, nil
+}
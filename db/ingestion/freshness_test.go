@@ -0,0 +1,52 @@
+// Package ingestion - Snapshot freshness tests
+package ingestion
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"terraform-cost/db"
+	"terraform-cost/db/memstore"
+
+	"github.com/google/uuid"
+)
+
+func TestFreshnessReportFlagsOnlyStaleSnapshots(t *testing.T) {
+	now := time.Now()
+	fresh := &db.PricingSnapshot{
+		ID: uuid.New(), Cloud: db.AWS, Region: "us-east-1", ProviderAlias: "default",
+		Hash: "fresh", FetchedAt: now.Add(-24 * time.Hour), IsActive: true,
+	}
+	stale := &db.PricingSnapshot{
+		ID: uuid.New(), Cloud: db.AWS, Region: "eu-west-1", ProviderAlias: "default",
+		Hash: "stale", FetchedAt: now.Add(-10 * 24 * time.Hour), IsActive: true,
+	}
+	superseded := &db.PricingSnapshot{
+		ID: uuid.New(), Cloud: db.AWS, Region: "us-east-1", ProviderAlias: "default",
+		Hash: "superseded", FetchedAt: now.Add(-30 * 24 * time.Hour),
+	}
+
+	store := memstore.NewMemoryStore()
+	for _, snap := range []*db.PricingSnapshot{fresh, stale, superseded} {
+		if err := store.CreateSnapshot(context.Background(), snap); err != nil {
+			t.Fatalf("failed to seed snapshot: %v", err)
+		}
+	}
+
+	report, err := FreshnessReport(context.Background(), store, DefaultMaxSnapshotAge)
+	if err != nil {
+		t.Fatalf("freshness report failed: %v", err)
+	}
+	if len(report) != 1 {
+		t.Fatalf("expected 1 stale snapshot, got %d: %+v", len(report), report)
+	}
+
+	got := report[0]
+	if got.SnapshotID != stale.ID || got.Region != "eu-west-1" || got.Alias != "default" {
+		t.Errorf("unexpected stale snapshot: %+v", got)
+	}
+	if got.Age < 10*24*time.Hour {
+		t.Errorf("age = %s, want at least 10 days", got.Age)
+	}
+}
@@ -0,0 +1,169 @@
+// Package ingestion - GCP SKU attribute extraction
+// Compute Engine SKUs carry almost everything in free-text descriptions;
+// this turns them into structured keys the resolver can match on.
+package ingestion
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// gcpComputeService is the Category.ServiceDisplayName of Compute Engine SKUs
+const gcpComputeService = "Compute Engine"
+
+// gcpMachineSeriesPattern matches machine series tokens such as n1, n2d, e2, c3
+var gcpMachineSeriesPattern = regexp.MustCompile(`\b([acegmntz]\d[a-z]?)\b`)
+
+// gcpPredefinedTypePattern matches predefined machine types such as n2-standard-8
+var gcpPredefinedTypePattern = regexp.MustCompile(`\b([acegmnt]\d[a-z]?)-(standard|highmem|highcpu)-(\d+)\b`)
+
+// gcpMemoryPerVCPU is GiB of memory per vCPU by machine family.
+// N1 predates the 4/8/1 GiB ratios used by every later series.
+var gcpMemoryPerVCPU = map[string]map[string]float64{
+	"n1":      {"standard": 3.75, "highmem": 6.5, "highcpu": 0.9},
+	"default": {"standard": 4, "highmem": 8, "highcpu": 1},
+}
+
+// gcpSharedCoreTypes maps shared-core resource groups to their machine type
+var gcpSharedCoreTypes = map[string]string{
+	"f1micro": "f1-micro",
+	"g1small": "g1-small",
+}
+
+// gcpUsageTypes maps Category.UsageType values to canonical usage types
+var gcpUsageTypes = map[string]string{
+	"ondemand":    "on_demand",
+	"preemptible": "preemptible",
+	"commit1yr":   "commit_1yr",
+	"commit3yr":   "commit_3yr",
+}
+
+// gcpPricingType classifies a SKU as on_demand, preemptible, committed_use or
+// sustained_use so discount SKUs can be applied or ignored at resolution time.
+// Sustained-use discounts are OnDemand SKUs told apart only by description.
+func gcpPricingType(usageType, description string) string {
+	if strings.Contains(strings.ToLower(description), "sustained usage discount") {
+		return "sustained_use"
+	}
+	switch usage := canonicalGCPUsageType(usageType); {
+	case strings.HasPrefix(usage, "commit"):
+		return "committed_use"
+	default:
+		return usage
+	}
+}
+
+// extractGCPPredefinedType derives instance_type, vcpu and memory from a
+// predefined machine type named in a description, mirroring the AWS keys:
+//
+//	"n2-standard-8 running in americas" -> instance_type=n2-standard-8, vcpu=8, memory=32 gib
+//
+// Nothing is returned when no predefined type is named.
+func extractGCPPredefinedType(desc string) map[string]string {
+	m := gcpPredefinedTypePattern.FindStringSubmatch(desc)
+	if m == nil {
+		return nil
+	}
+	series, family := m[1], m[2]
+	vcpus, err := strconv.Atoi(m[3])
+	if err != nil || vcpus == 0 {
+		return nil
+	}
+
+	ratios, ok := gcpMemoryPerVCPU[series]
+	if !ok {
+		ratios = gcpMemoryPerVCPU["default"]
+	}
+	memory := float64(vcpus) * ratios[family]
+
+	return map[string]string{
+		"instance_type": m[0],
+		"vcpu":          strconv.Itoa(vcpus),
+		"memory":        strconv.FormatFloat(memory, 'f', -1, 64) + " gib",
+	}
+}
+
+// canonicalGCPUsageType converts a Category.UsageType to canonical form
+func canonicalGCPUsageType(usageType string) string {
+	lower := strings.ToLower(usageType)
+	if canonical, ok := gcpUsageTypes[lower]; ok {
+		return canonical
+	}
+	return lower
+}
+
+// extractGCPComputeAttributes derives machine_type, machine_class,
+// resource_type and usage_type from a Compute Engine SKU.
+// Only attributes that can be determined are returned.
+//
+// Examples:
+//
+//	"N1 Predefined Instance Core running in Americas" -> machine_type=n1, machine_class=predefined, resource_type=cpu
+//	"Preemptible N2 Instance Ram running in EMEA"    -> machine_type=n2, resource_type=ram, usage_type=preemptible
+//	"Commitment v1: N2 Cpu in Americas for 1 Year"   -> machine_type=n2, resource_type=cpu, usage_type=commit_1yr
+func extractGCPComputeAttributes(description, resourceGroup string) map[string]string {
+	attrs := make(map[string]string)
+	desc := strings.ToLower(description)
+	group := strings.ToLower(resourceGroup)
+
+	// Shared-core machines are priced per instance, not per core
+	if machineType, ok := gcpSharedCoreTypes[group]; ok {
+		attrs["machine_type"] = machineType
+		attrs["machine_class"] = "shared-core"
+		attrs["instance_type"] = machineType
+		return attrs
+	}
+
+	for k, v := range extractGCPPredefinedType(desc) {
+		attrs[k] = v
+	}
+
+	words := strings.Fields(strings.NewReplacer(":", " ", ",", " ").Replace(desc))
+	for _, w := range words {
+		switch w {
+		case "core", "cpu", "vcpu":
+			attrs["resource_type"] = "cpu"
+		case "ram", "memory":
+			attrs["resource_type"] = "ram"
+		}
+	}
+	if _, ok := attrs["resource_type"]; !ok {
+		switch group {
+		case "cpu":
+			attrs["resource_type"] = "cpu"
+		case "ram":
+			attrs["resource_type"] = "ram"
+		}
+	}
+
+	isCustom := strings.Contains(desc, "custom")
+	if m := gcpMachineSeriesPattern.FindStringSubmatch(desc); m != nil {
+		attrs["machine_type"] = m[1]
+	} else if m := gcpMachineSeriesPattern.FindStringSubmatch(group); m != nil {
+		// Resource groups such as "N1Standard"
+		attrs["machine_type"] = m[1]
+	} else if isCustom || strings.Contains(desc, "predefined") {
+		// Unqualified custom/predefined SKUs are the original N1 series
+		attrs["machine_type"] = "n1"
+	}
+
+	if _, ok := attrs["machine_type"]; ok && attrs["resource_type"] != "" {
+		if isCustom {
+			attrs["machine_class"] = "custom"
+		} else {
+			attrs["machine_class"] = "predefined"
+		}
+	}
+
+	switch {
+	case strings.HasPrefix(desc, "preemptible") || strings.HasPrefix(desc, "spot"):
+		attrs["usage_type"] = "preemptible"
+	case strings.HasPrefix(desc, "commitment") && strings.Contains(desc, "3 year"):
+		attrs["usage_type"] = "commit_3yr"
+	case strings.HasPrefix(desc, "commitment"):
+		attrs["usage_type"] = "commit_1yr"
+	}
+
+	return attrs
+}
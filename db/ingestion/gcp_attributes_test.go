@@ -0,0 +1,220 @@
+// Package ingestion - GCP attribute extraction tests
+package ingestion
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+// gcpComputeSKUFixture mirrors the shape of a Cloud Billing skus.list page
+const gcpComputeSKUFixture = `{
+  "skus": [
+    {
+      "name": "services/6F81-5844-456A/skus/2E27-4F75-95CD",
+      "skuId": "2E27-4F75-95CD",
+      "description": "N1 Predefined Instance Core running in Americas",
+      "category": {"serviceDisplayName": "Compute Engine", "resourceFamily": "Compute", "resourceGroup": "N1Standard", "usageType": "OnDemand"},
+      "serviceRegions": ["us-central1"],
+      "pricingInfo": [{"pricingExpression": {"usageUnit": "h", "tieredRates": [{"startUsageAmount": 0, "unitPrice": {"currencyCode": "USD", "units": "0", "nanos": 31611000}}]}}]
+    },
+    {
+      "name": "services/6F81-5844-456A/skus/6C71-E4A2-1A3A",
+      "skuId": "6C71-E4A2-1A3A",
+      "description": "N1 Predefined Instance Ram running in Americas",
+      "category": {"serviceDisplayName": "Compute Engine", "resourceFamily": "Compute", "resourceGroup": "N1Standard", "usageType": "OnDemand"},
+      "serviceRegions": ["us-central1"],
+      "pricingInfo": [{"pricingExpression": {"usageUnit": "GiBy.h", "tieredRates": [{"startUsageAmount": 0, "unitPrice": {"currencyCode": "USD", "units": "0", "nanos": 4237000}}]}}]
+    },
+    {
+      "name": "services/6F81-5844-456A/skus/BB77-5FDA-6B23",
+      "skuId": "BB77-5FDA-6B23",
+      "description": "Preemptible N2 Instance Core running in Americas",
+      "category": {"serviceDisplayName": "Compute Engine", "resourceFamily": "Compute", "resourceGroup": "CPU", "usageType": "Preemptible"},
+      "serviceRegions": ["us-central1"],
+      "pricingInfo": [{"pricingExpression": {"usageUnit": "h", "tieredRates": [{"startUsageAmount": 0, "unitPrice": {"currencyCode": "USD", "units": "0", "nanos": 7650000}}]}}]
+    },
+    {
+      "name": "services/6F81-5844-456A/skus/F449-33EC-A5EF",
+      "skuId": "F449-33EC-A5EF",
+      "description": "N2 Custom Instance Ram running in Americas",
+      "category": {"serviceDisplayName": "Compute Engine", "resourceFamily": "Compute", "resourceGroup": "RAM", "usageType": "OnDemand"},
+      "serviceRegions": ["us-central1"],
+      "pricingInfo": [{"pricingExpression": {"usageUnit": "GiBy.h", "tieredRates": [{"startUsageAmount": 0, "unitPrice": {"currencyCode": "USD", "units": "0", "nanos": 4970000}}]}}]
+    },
+    {
+      "name": "services/6F81-5844-456A/skus/8A2E-6F6C-F1F4",
+      "skuId": "8A2E-6F6C-F1F4",
+      "description": "Commitment v1: N2 Cpu in Americas for 1 Year",
+      "category": {"serviceDisplayName": "Compute Engine", "resourceFamily": "Compute", "resourceGroup": "CPU", "usageType": "Commit1Yr"},
+      "serviceRegions": ["us-central1"],
+      "pricingInfo": [{"pricingExpression": {"usageUnit": "h", "tieredRates": [{"startUsageAmount": 0, "unitPrice": {"currencyCode": "USD", "units": "0", "nanos": 19915000}}]}}]
+    },
+    {
+      "name": "services/6F81-5844-456A/skus/9C77-1C34-7E1A",
+      "skuId": "9C77-1C34-7E1A",
+      "description": "Micro Instance with burstable CPU running in Americas",
+      "category": {"serviceDisplayName": "Compute Engine", "resourceFamily": "Compute", "resourceGroup": "F1Micro", "usageType": "OnDemand"},
+      "serviceRegions": ["us-central1"],
+      "pricingInfo": [{"pricingExpression": {"usageUnit": "h", "tieredRates": [{"startUsageAmount": 0, "unitPrice": {"currencyCode": "USD", "units": "0", "nanos": 7600000}}]}}]
+    }
+  ]
+}`
+
+func TestGCPNormalizerExtractsComputeAttributes(t *testing.T) {
+	var page GCPSKUsResponse
+	if err := json.Unmarshal([]byte(gcpComputeSKUFixture), &page); err != nil {
+		t.Fatalf("failed to decode fixture: %v", err)
+	}
+
+	client := NewGCPPricingAPIClient(nil)
+	var raw []RawPrice
+	for _, sku := range page.SKUs {
+		raw = append(raw, client.skuToPrices(sku, "us-central1")...)
+	}
+
+	rates, err := NewGCPPricingNormalizer().Normalize(context.Background(), raw)
+	if err != nil {
+		t.Fatalf("normalize failed: %v", err)
+	}
+	if len(rates) != len(page.SKUs) {
+		t.Fatalf("expected %d rates, got %d", len(page.SKUs), len(rates))
+	}
+
+	want := []map[string]string{
+		{"machine_type": "n1", "machine_class": "predefined", "resource_type": "cpu", "resource_group": "n1standard", "usage_type": "on_demand"},
+		{"machine_type": "n1", "machine_class": "predefined", "resource_type": "ram", "resource_group": "n1standard", "usage_type": "on_demand"},
+		{"machine_type": "n2", "machine_class": "predefined", "resource_type": "cpu", "resource_group": "cpu", "usage_type": "preemptible"},
+		{"machine_type": "n2", "machine_class": "custom", "resource_type": "ram", "resource_group": "ram", "usage_type": "on_demand"},
+		{"machine_type": "n2", "machine_class": "predefined", "resource_type": "cpu", "usage_type": "commit_1yr"},
+		{"machine_type": "f1-micro", "machine_class": "shared-core", "usage_type": "on_demand"},
+	}
+
+	for i, rate := range rates {
+		if rate.RateKey.Service != "Compute Engine" {
+			t.Errorf("rate %d: service = %q", i, rate.RateKey.Service)
+		}
+		for k, v := range want[i] {
+			if got := rate.RateKey.Attributes[k]; got != v {
+				t.Errorf("rate %d (%s): %s = %q, want %q", i, rate.RateKey.Attributes["description"], k, got, v)
+			}
+		}
+	}
+}
+
+func TestExtractGCPComputeAttributesUnknown(t *testing.T) {
+	// Non-instance SKUs yield nothing rather than guesses
+	attrs := extractGCPComputeAttributes("Storage PD Capacity", "PDStandard")
+	if len(attrs) != 0 {
+		t.Errorf("expected no attributes, got %v", attrs)
+	}
+
+	attrs = extractGCPComputeAttributes("Custom Instance Core running in EMEA", "N1Standard")
+	if attrs["machine_type"] != "n1" || attrs["machine_class"] != "custom" {
+		t.Errorf("expected legacy custom SKU to map to n1/custom, got %v", attrs)
+	}
+
+	attrs = extractGCPComputeAttributes("Commitment v1: N2D AMD Ram in Americas for 3 Year", "RAM")
+	if attrs["machine_type"] != "n2d" || attrs["usage_type"] != "commit_3yr" || attrs["resource_type"] != "ram" {
+		t.Errorf("unexpected commitment attributes: %v", attrs)
+	}
+}
+
+// gcpDiscountSKUFixture has a free-tier storage SKU and a committed-use SKU
+const gcpDiscountSKUFixture = `{
+  "skus": [
+    {
+      "skuId": "E5F0-6A5D-7BAD",
+      "description": "Standard Storage US Multi-region",
+      "category": {"serviceDisplayName": "Cloud Storage", "resourceFamily": "Storage", "resourceGroup": "MultiRegionalStorage", "usageType": "OnDemand"},
+      "serviceRegions": ["us"],
+      "pricingInfo": [{"pricingExpression": {"usageUnit": "GiBy.mo", "tieredRates": [
+        {"startUsageAmount": 0, "unitPrice": {"currencyCode": "USD", "units": "0", "nanos": 0}},
+        {"startUsageAmount": 5, "unitPrice": {"currencyCode": "USD", "units": "0", "nanos": 26000000}}
+      ]}}]
+    },
+    {
+      "skuId": "8A2E-6F6C-F1F4",
+      "description": "Commitment v1: N2 Cpu in Americas for 1 Year",
+      "category": {"serviceDisplayName": "Compute Engine", "resourceFamily": "Compute", "resourceGroup": "CPU", "usageType": "Commit1Yr"},
+      "serviceRegions": ["us-central1"],
+      "pricingInfo": [{"pricingExpression": {"usageUnit": "h", "tieredRates": [{"startUsageAmount": 0, "unitPrice": {"currencyCode": "USD", "units": "0", "nanos": 19915000}}]}}]
+    }
+  ]
+}`
+
+func TestGCPFreeTierAndCommittedUse(t *testing.T) {
+	var page GCPSKUsResponse
+	if err := json.Unmarshal([]byte(gcpDiscountSKUFixture), &page); err != nil {
+		t.Fatalf("failed to decode fixture: %v", err)
+	}
+
+	client := NewGCPPricingAPIClient(nil)
+	var raw []RawPrice
+	for _, sku := range page.SKUs {
+		raw = append(raw, client.skuToPrices(sku, "us-central1")...)
+	}
+
+	rates, err := NewGCPPricingNormalizer().Normalize(context.Background(), raw)
+	if err != nil {
+		t.Fatalf("normalize failed: %v", err)
+	}
+	if len(rates) != 3 {
+		t.Fatalf("expected free tier, paid tier and commitment rates, got %d", len(rates))
+	}
+
+	free, paid, commit := rates[0], rates[1], rates[2]
+	if !free.Price.IsZero() || free.RateKey.Attributes["free_tier"] != "true" {
+		t.Errorf("expected zero-priced tier flagged free_tier, got price=%s attrs=%v", free.Price, free.RateKey.Attributes)
+	}
+	if _, ok := paid.RateKey.Attributes["free_tier"]; ok || paid.Price.IsZero() {
+		t.Errorf("paid tier should not be flagged free, got %v", paid.RateKey.Attributes)
+	}
+	if paid.RateKey.Attributes["pricing_type"] != "on_demand" {
+		t.Errorf("paid tier pricing_type = %q, want on_demand", paid.RateKey.Attributes["pricing_type"])
+	}
+	if commit.RateKey.Attributes["pricing_type"] != "committed_use" || commit.RateKey.Attributes["usage_type"] != "commit_1yr" {
+		t.Errorf("unexpected commitment attributes: %v", commit.RateKey.Attributes)
+	}
+
+	if got := gcpPricingType("OnDemand", "N1 Sustained Usage Discount in Americas"); got != "sustained_use" {
+		t.Errorf("sustained-use pricing_type = %q", got)
+	}
+}
+
+func TestGCPPredefinedMachineTypeAttributes(t *testing.T) {
+	tests := []struct {
+		description string
+		want        map[string]string
+	}{
+		{
+			"N2-standard-8 VM running in Americas",
+			map[string]string{"instance_type": "n2-standard-8", "vcpu": "8", "memory": "32 gib", "machine_type": "n2"},
+		},
+		{
+			"n1-highmem-4 running in EMEA",
+			map[string]string{"instance_type": "n1-highmem-4", "vcpu": "4", "memory": "26 gib"},
+		},
+		{
+			"c2d-highcpu-16 running in APAC",
+			map[string]string{"instance_type": "c2d-highcpu-16", "vcpu": "16", "memory": "16 gib"},
+		},
+	}
+
+	for _, tt := range tests {
+		attrs := extractGCPComputeAttributes(tt.description, "")
+		for k, v := range tt.want {
+			if attrs[k] != v {
+				t.Errorf("%q: %s = %q, want %q", tt.description, k, attrs[k], v)
+			}
+		}
+	}
+
+	// Per-core SKUs name no machine type, so no instance_type is derived
+	attrs := extractGCPComputeAttributes("N2 Instance Core running in Americas", "CPU")
+	for _, k := range []string{"instance_type", "vcpu", "memory"} {
+		if _, ok := attrs[k]; ok {
+			t.Errorf("per-core SKU should not get %s, got %v", k, attrs)
+		}
+	}
+}
@@ -6,11 +6,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"strings"
 	"time"
 
 	"terraform-cost/db"
+
+	"github.com/shopspring/decimal"
 )
 
 // GCPPricingAPIClient fetches pricing from GCP Cloud Billing Catalog API
@@ -19,6 +22,12 @@ type GCPPricingAPIClient struct {
 	httpClient   *http.Client
 	baseURL      string
 	servicesList []string
+	limiter      *RateLimiter
+	maxRetries   int
+	logger       *slog.Logger
+
+	breakerThreshold int
+	serviceTimeout   ServiceTimeout
 }
 
 // GCPPricingConfig configures the GCP pricing client
@@ -26,15 +35,35 @@ type GCPPricingConfig struct {
 	// HTTPTimeout for API calls
 	HTTPTimeout time.Duration
 
+	// HTTPClient, when set, is used for API calls instead of a client
+	// built from HTTPTimeout, so fetchers can share one configured client
+	HTTPClient *http.Client
+
 	// Services to fetch (empty = ALL services)
 	Services []string
+
+	// RequestsPerSecond caps API calls across all services (0 = unlimited)
+	RequestsPerSecond float64
+
+	// MaxRetries is how many times a 429 response is retried
+	MaxRetries int
+
+	// BreakerThreshold is how many consecutive service failures abort the
+	// region fetch (0 = never)
+	BreakerThreshold int
+
+	// ServiceTimeout is the per-service deadline within FetchRegion
+	ServiceTimeout ServiceTimeout
 }
 
 // DefaultGCPPricingConfig returns production defaults
 func DefaultGCPPricingConfig() *GCPPricingConfig {
 	return &GCPPricingConfig{
-		HTTPTimeout: 10 * time.Minute,
-		Services:    AllGCPServices(),
+		HTTPTimeout:       10 * time.Minute,
+		Services:          AllGCPServices(),
+		RequestsPerSecond: 5, // Well under the default Cloud Billing quota
+		MaxRetries:        5,
+		BreakerThreshold:  DefaultCircuitBreakerThreshold,
 	}
 }
 
@@ -135,11 +164,14 @@ func NewGCPPricingAPIClient(cfg *GCPPricingConfig) *GCPPricingAPIClient {
 	}
 
 	return &GCPPricingAPIClient{
-		httpClient: &http.Client{
-			Timeout: cfg.HTTPTimeout,
-		},
+		httpClient: httpClientOrDefault(cfg.HTTPClient, cfg.HTTPTimeout),
 		baseURL:      "https://cloudbilling.googleapis.com/v1",
 		servicesList: cfg.Services,
+		limiter:      NewRateLimiter(cfg.RequestsPerSecond),
+		maxRetries:   cfg.MaxRetries,
+
+		breakerThreshold: cfg.BreakerThreshold,
+		serviceTimeout:   cfg.ServiceTimeout,
 	}
 }
 
@@ -189,6 +221,24 @@ func (c *GCPPricingAPIClient) SupportedServices() []string {
 	return c.servicesList
 }
 
+// SetLogger sets the logger used for fetch warnings
+func (c *GCPPricingAPIClient) SetLogger(logger *slog.Logger) {
+	c.logger = logger
+}
+
+// SetTransport routes API calls through rt (see TransportConfig), keeping
+// the client's timeout
+func (c *GCPPricingAPIClient) SetTransport(rt http.RoundTripper) {
+	client := *c.httpClient
+	client.Transport = rt
+	c.httpClient = &client
+}
+
+// SetServiceTimeout sets the per-service deadline within FetchRegion
+func (c *GCPPricingAPIClient) SetServiceTimeout(t ServiceTimeout) {
+	c.serviceTimeout = t
+}
+
 // FetchRegion fetches ALL pricing for a region from GCP Cloud Billing API
 // This is mapper-agnostic - fetches complete catalogs
 func (c *GCPPricingAPIClient) FetchRegion(ctx context.Context, region string) ([]RawPrice, error) {
@@ -201,28 +251,40 @@ func (c *GCPPricingAPIClient) FetchRegion(ctx context.Context, region string) ([
 	}
 
 	// Fetch SKUs for each service
-	for _, service := range services {
+	failures := &serviceFailures{provider: db.GCP, region: region, expected: len(services), threshold: c.breakerThreshold}
+	for i, service := range services {
 		select {
 		case <-ctx.Done():
 			return nil, ctx.Err()
 		default:
 		}
 
-		skus, err := c.fetchServiceSKUs(ctx, service.ServiceID, region)
+		serviceCtx, cancel := c.serviceTimeout.context(ctx, len(services)-i)
+		skus, err := c.fetchServiceSKUs(serviceCtx, service.ServiceID, region)
+		cancel()
 		if err != nil {
-			// Log but continue
-			fmt.Printf("Warning: failed to fetch SKUs for %s: %v\n", service.DisplayName, err)
+			err = serviceTimedOut(ctx, serviceCtx, err)
+			// Record and continue; the pipeline decides if enough services succeeded
+			loggerOrDefault(c.logger).Warn("failed to fetch service SKUs", "provider", "gcp", "region", region, "service", service.DisplayName, "error", err)
+			failures.add(service.DisplayName, err)
+			if open := failures.open(i + 1); open != nil {
+				return nil, open
+			}
 			continue
 		}
+		failures.succeeded()
 
 		allPrices = append(allPrices, skus...)
 	}
 
 	if len(allPrices) == 0 {
+		if err := failures.err(); err != nil {
+			return nil, fmt.Errorf("failed to fetch any pricing for GCP region %s: %w", region, err)
+		}
 		return nil, fmt.Errorf("failed to fetch any pricing for GCP region %s", region)
 	}
 
-	return allPrices, nil
+	return allPrices, failures.err()
 }
 
 // listServices fetches all billable GCP services
@@ -231,28 +293,8 @@ func (c *GCPPricingAPIClient) listServices(ctx context.Context) ([]GCPService, e
 	pageToken := ""
 
 	for {
-		url := fmt.Sprintf("%s/services", c.baseURL)
-		if pageToken != "" {
-			url += "?pageToken=" + pageToken
-		}
-
-		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-		if err != nil {
-			return nil, err
-		}
-
-		resp, err := c.httpClient.Do(req)
-		if err != nil {
-			return nil, err
-		}
-		defer resp.Body.Close()
-
-		if resp.StatusCode != http.StatusOK {
-			return nil, fmt.Errorf("GCP API returned status %d", resp.StatusCode)
-		}
-
 		var response GCPServicesResponse
-		if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		if err := c.getPage(ctx, fmt.Sprintf("%s/services", c.baseURL), pageToken, &response); err != nil {
 			return nil, err
 		}
 
@@ -273,28 +315,8 @@ func (c *GCPPricingAPIClient) fetchServiceSKUs(ctx context.Context, serviceID, r
 	pageToken := ""
 
 	for {
-		url := fmt.Sprintf("%s/%s/skus", c.baseURL, serviceID)
-		if pageToken != "" {
-			url += "?pageToken=" + pageToken
-		}
-
-		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-		if err != nil {
-			return nil, err
-		}
-
-		resp, err := c.httpClient.Do(req)
-		if err != nil {
-			return nil, err
-		}
-		defer resp.Body.Close()
-
-		if resp.StatusCode != http.StatusOK {
-			return nil, fmt.Errorf("GCP SKUs API returned status %d", resp.StatusCode)
-		}
-
 		var response GCPSKUsResponse
-		if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		if err := c.getPage(ctx, fmt.Sprintf("%s/%s/skus", c.baseURL, serviceID), pageToken, &response); err != nil {
 			return nil, err
 		}
 
@@ -318,6 +340,56 @@ func (c *GCPPricingAPIClient) fetchServiceSKUs(ctx context.Context, serviceID, r
 	return allPrices, nil
 }
 
+// getPage fetches one page of a paginated list and decodes it into out.
+// The response body is closed before returning so long crawls don't hold
+// a connection per page.
+func (c *GCPPricingAPIClient) getPage(ctx context.Context, baseURL, pageToken string, out interface{}) error {
+	url := baseURL
+	if pageToken != "" {
+		url += "?pageToken=" + pageToken
+	}
+
+	resp, err := c.get(ctx, url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GCP API %s returned status %d", baseURL, resp.StatusCode)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// get issues a rate-limited GET, honouring Retry-After on 429 responses
+func (c *GCPPricingAPIClient) get(ctx context.Context, url string) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		if err := c.limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusTooManyRequests || attempt >= c.maxRetries {
+			return resp, nil
+		}
+
+		wait := parseRetryAfter(resp.Header.Get("Retry-After"), time.Duration(attempt+1)*time.Second)
+		resp.Body.Close()
+		if err := sleepContext(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
 // skuMatchesRegion checks if a SKU applies to a region
 func (c *GCPPricingAPIClient) skuMatchesRegion(sku GCPSKU, region string) bool {
 	if len(sku.ServiceRegions) == 0 {
@@ -341,8 +413,10 @@ func (c *GCPPricingAPIClient) skuToPrices(sku GCPSKU, region string) []RawPrice
 			unitPrice := float64(tierRate.UnitPrice.Units) +
 				float64(tierRate.UnitPrice.Nanos)/1e9
 
+			attrs := c.buildSKUAttributes(sku)
 			if unitPrice == 0 {
-				continue // Skip free tiers
+				// Keep free-tier allowances as cost modeling inputs
+				attrs["freeTier"] = "true"
 			}
 
 			price := RawPrice{
@@ -353,12 +427,12 @@ func (c *GCPPricingAPIClient) skuToPrices(sku GCPSKU, region string) []RawPrice
 				Unit:          pricingInfo.PricingExpression.UsageUnit,
 				PricePerUnit:  fmt.Sprintf("%.10f", unitPrice),
 				Currency:      tierRate.UnitPrice.CurrencyCode,
-				Attributes:    c.buildSKUAttributes(sku),
+				Attributes:    attrs,
 			}
 
 			// Handle tiered pricing
 			if tierRate.StartUsageAmount > 0 {
-				start := tierRate.StartUsageAmount
+				start := decimal.NewFromFloat(tierRate.StartUsageAmount)
 				price.TierStart = &start
 			}
 
@@ -459,7 +533,7 @@ type GCPTieredRate struct {
 // GCPMoney represents a monetary amount
 type GCPMoney struct {
 	CurrencyCode string `json:"currencyCode"`
-	Units        int64  `json:"units"`
+	Units        int64  `json:"units,string"` // int64 is encoded as a JSON string
 	Nanos        int32  `json:"nanos"`
 }
 
@@ -477,16 +551,27 @@ func (n *GCPPricingNormalizer) Cloud() db.CloudProvider {
 }
 
 // Normalize converts raw GCP prices to normalized rates
-func (n *GCPPricingNormalizer) Normalize(raw []RawPrice) ([]NormalizedRate, error) {
+func (n *GCPPricingNormalizer) Normalize(ctx context.Context, raw []RawPrice) ([]NormalizedRate, error) {
 	var rates []NormalizedRate
 
-	for _, r := range raw {
+	for i, r := range raw {
+		if err := checkCancelled(ctx, i); err != nil {
+			return nil, err
+		}
 		price, err := ParsePrice(r.PricePerUnit)
 		if err != nil {
 			continue
 		}
 
 		attrs := n.normalizeAttributes(r.Attributes)
+		if r.ServiceCode == gcpComputeService {
+			// Structured keys parsed from the description; explicit attributes win
+			for k, v := range extractGCPComputeAttributes(r.Attributes["description"], r.Attributes["resourceGroup"]) {
+				if _, exists := attrs[k]; !exists {
+					attrs[k] = v
+				}
+			}
+		}
 
 		rateKey := db.RateKey{
 			Cloud:         db.GCP,
@@ -502,6 +587,9 @@ func (n *GCPPricingNormalizer) Normalize(raw []RawPrice) ([]NormalizedRate, erro
 			Price:      price,
 			Currency:   r.Currency,
 			Confidence: 1.0,
+
+			EffectiveDate: r.EffectiveDate,
+			Labels:        sourceLabels(r),
 		}
 
 		rates = append(rates, nr)
@@ -519,13 +607,17 @@ func (n *GCPPricingNormalizer) normalizeAttributes(raw map[string]string) map[st
 		"usageType":      "usage_type",
 		"description":    "description",
 		"serviceRegion":  "service_region",
+		"freeTier":       "free_tier",
 	}
 
 	for k, v := range raw {
 		if v == "" {
 			continue
 		}
-		if canonical, ok := mapping[k]; ok {
+		if k == "usageType" {
+			result["usage_type"] = canonicalGCPUsageType(v)
+			result["pricing_type"] = gcpPricingType(v, raw["description"])
+		} else if canonical, ok := mapping[k]; ok {
 			result[canonical] = strings.ToLower(v)
 		} else {
 			result[toSnakeCase(k)] = strings.ToLower(v)
@@ -4,7 +4,10 @@ package ingestion
 import (
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"terraform-cost/db"
@@ -38,6 +41,13 @@ func DefaultContracts() []IngestionContract {
 		// GCP
 		{db.GCP, "Compute Engine", []string{}, 100},
 		{db.GCP, "Cloud Storage", []string{}, 10},
+		// OCI
+		{db.OCI, "Compute", []string{}, 20},
+		{db.OCI, "Block Storage", []string{}, 2},
+		// DigitalOcean
+		{db.DigitalOcean, "Droplets", []string{}, 5},
+		{db.DigitalOcean, "Volumes", []string{}, 1},
+		{db.DigitalOcean, "Managed Databases", []string{}, 5},
 	}
 }
 
@@ -67,8 +77,13 @@ const (
 
 // IngestionValidator validates ingestion against contracts
 type IngestionValidator struct {
-	contracts          map[string]IngestionContract
-	minCoveragePercent float64
+	contracts            map[string]IngestionContract
+	minCoveragePercent   float64
+	requireProductFamily bool
+	strictUnits          bool
+	distinctKeyCoverage  bool
+	futureWindow         time.Duration
+	maxZeroFraction      float64
 }
 
 // NewIngestionValidator creates a new validator with default contracts
@@ -76,6 +91,7 @@ func NewIngestionValidator() *IngestionValidator {
 	v := &IngestionValidator{
 		contracts:          make(map[string]IngestionContract),
 		minCoveragePercent: 95.0, // Very high coverage required
+		maxZeroFraction:    DefaultMaxZeroPriceFraction,
 	}
 	for _, c := range DefaultContracts() {
 		key := fmt.Sprintf("%s:%s", c.Cloud, c.Service)
@@ -89,6 +105,37 @@ func (v *IngestionValidator) SetMinCoveragePercent(pct float64) {
 	v.minCoveragePercent = pct
 }
 
+// SetRequireProductFamily makes an empty product family a validation failure.
+// Off by default because some services publish rates without one.
+func (v *IngestionValidator) SetRequireProductFamily(require bool) {
+	v.requireProductFamily = require
+}
+
+// SetStrictUnits makes non-canonical units a validation failure instead of a warning
+func (v *IngestionValidator) SetStrictUnits(strict bool) {
+	v.strictUnits = strict
+}
+
+// SetFutureEffectiveWindow sets how far past now an effective date may be
+// before ValidateEffectiveDates flags the rate (0 disables the check)
+func (v *IngestionValidator) SetFutureEffectiveWindow(window time.Duration) {
+	v.futureWindow = window
+}
+
+// SetMaxZeroPriceFraction sets the share of zero-priced rates
+// ValidateNonZeroCoverage allows across a run (1 allows any share)
+func (v *IngestionValidator) SetMaxZeroPriceFraction(fraction float64) {
+	v.maxZeroFraction = fraction
+}
+
+// SetDistinctKeyCoverage makes the coverage check compare distinct rate keys
+// instead of rows, so a change in tiers or effective dates is not a coverage
+// change. The previous count passed to ValidateAllChecks must then come from
+// CountDistinctRateKeys.
+func (v *IngestionValidator) SetDistinctKeyCoverage(distinct bool) {
+	v.distinctKeyCoverage = distinct
+}
+
 // AddContract adds a custom contract
 func (v *IngestionValidator) AddContract(contract IngestionContract) {
 	key := fmt.Sprintf("%s:%s", contract.Cloud, contract.Service)
@@ -105,6 +152,20 @@ type ValidationResult struct {
 	Errors            []string
 }
 
+// FailingServices returns the sorted names of services that failed their
+// contract or were missing entirely
+func (r *ValidationResult) FailingServices() []string {
+	var names []string
+	for _, sv := range r.ServiceResults {
+		if !sv.IsValid {
+			names = append(names, sv.Service)
+		}
+	}
+	names = append(names, r.MissingServices...)
+	sort.Strings(names)
+	return names
+}
+
 // ServiceValidation contains per-service validation
 type ServiceValidation struct {
 	Service           string
@@ -216,32 +277,106 @@ func CalculateChecksum(rates []NormalizedRate) string {
 	return hex.EncodeToString(hasher.Sum(nil))
 }
 
-// ValidateAll runs all pre-commit validations (abort on failure)
-func (v *IngestionValidator) ValidateAll(rates []NormalizedRate, prevRateCount int) error {
-	// 1. Validate no negative prices
-	if err := v.ValidatePricesPositive(rates); err != nil {
-		return err
+// ValidationCheck is the outcome of one pre-commit check
+type ValidationCheck struct {
+	Name   string `json:"name"`
+	Passed bool   `json:"passed"`
+	Error  string `json:"error,omitempty"`
+}
+
+// ErrValidationFailed is matched with errors.Is by every error a pre-commit
+// validation check fails with
+var ErrValidationFailed = errors.New("validation failed")
+
+// ValidationError names the check that failed and wraps its details. It
+// matches ErrValidationFailed.
+type ValidationError struct {
+	Check string
+	Err   error
+}
+
+func (e *ValidationError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrValidationFailed
+}
+
+// validationFailure wraps a failed check's error in a ValidationError
+func validationFailure(check string, err error) error {
+	if err == nil {
+		return nil
 	}
+	return &ValidationError{Check: check, Err: err}
+}
 
-	// 2. Validate required dimensions exist
-	if err := v.ValidateDimensionsComplete(rates); err != nil {
-		return err
+// newValidationCheck records a check's name and error
+func newValidationCheck(name string, err error) ValidationCheck {
+	check := ValidationCheck{Name: name, Passed: err == nil}
+	if err != nil {
+		check.Error = err.Error()
 	}
+	return check
+}
 
-	// 3. Duplicate check disabled - AWS pricing naturally has tiered rates
-	// with the same rate key (different price tiers, effective dates, etc.)
-	// if err := v.ValidateNoDuplicates(rates); err != nil {
-	// 	return err
-	// }
+// ValidateAll runs all pre-commit validations (abort on failure). A failure
+// is a *ValidationError.
+func (v *IngestionValidator) ValidateAll(rates []NormalizedRate, prevRateCount int) error {
+	_, err := v.ValidateAllChecks(rates, prevRateCount)
+	return err
+}
 
-	// 4. Validate coverage not decreased (if previous exists)
-	if prevRateCount > 0 {
-		if err := v.ValidateCoverageNotDecreased(len(rates), prevRateCount); err != nil {
+// ValidateAllChecks runs the ValidateAll checks in order and returns the
+// outcome of each one that ran, stopping at the first failure
+func (v *IngestionValidator) ValidateAllChecks(rates []NormalizedRate, prevRateCount int) ([]ValidationCheck, error) {
+	type check struct {
+		name string
+		run  func() error
+	}
+	checks := []check{
+		// 1. Validate no negative prices
+		{"prices_positive", func() error { return v.ValidatePricesPositive(rates) }},
+		// 2. Validate prices did not collapse to zero
+		{"prices_nonzero", func() error { return v.ValidateNonZeroCoverage(rates) }},
+		// 3. Validate every rate key can be resolved
+		{"rate_key_completeness", func() error { return v.ValidateRateKeyCompleteness(rates) }},
+		// 4. Validate required dimensions exist
+		{"dimensions_complete", func() error { return v.ValidateDimensionsComplete(rates) }},
+		// 5. Validate units are canonical (only fails in strict mode)
+		{"unit_consistency", func() error {
+			_, err := v.ValidateUnitConsistency(rates)
 			return err
-		}
+		}},
+		// 6. Duplicate check disabled - AWS pricing naturally has tiered rates
+		// with the same rate key (different price tiers, effective dates, etc.)
+		// 7. Validate prices fit the price column without rounding
+		{"decimal_scale", func() error { return v.ValidateDecimalScale(rates, PriceColumnScale) }},
+	}
+	// 8. Validate coverage not decreased (if previous exists)
+	if prevRateCount > 0 {
+		checks = append(checks, check{"coverage_not_decreased", func() error {
+			newCount := len(rates)
+			if v.distinctKeyCoverage {
+				newCount = CountDistinctRateKeys(rates)
+			}
+			return v.ValidateCoverageNotDecreased(newCount, prevRateCount)
+		}})
 	}
 
-	return nil
+	results := make([]ValidationCheck, 0, len(checks))
+	for _, c := range checks {
+		err := validationFailure(c.name, c.run())
+		results = append(results, newValidationCheck(c.name, err))
+		if err != nil {
+			return results, err
+		}
+	}
+	return results, nil
 }
 
 // ValidatePricesPositive ensures no negative prices
@@ -256,6 +391,115 @@ func (v *IngestionValidator) ValidatePricesPositive(rates []NormalizedRate) erro
 	return nil
 }
 
+// DefaultMaxZeroPriceFraction is the share of zero-priced rates a run may
+// have; free tiers and free meters are legitimately zero, but not most rates
+const DefaultMaxZeroPriceFraction = 0.5
+
+// ValidateNonZeroCoverage catches prices that collapsed to zero, which
+// ValidatePricesPositive lets through: it fails when every rate of a
+// contracted service is zero, or when more than the maximum fraction of all
+// rates are zero
+func (v *IngestionValidator) ValidateNonZeroCoverage(rates []NormalizedRate) error {
+	if len(rates) == 0 {
+		return nil
+	}
+
+	type counts struct{ total, zero int }
+	byService := make(map[string]*counts)
+	var zero int
+	for _, r := range rates {
+		key := fmt.Sprintf("%s:%s", r.RateKey.Cloud, r.RateKey.Service)
+		c := byService[key]
+		if c == nil {
+			c = &counts{}
+			byService[key] = c
+		}
+		c.total++
+		if r.Price.IsZero() {
+			c.zero++
+			zero++
+		}
+	}
+
+	var collapsed []string
+	for key, c := range byService {
+		if contract, ok := v.contracts[key]; ok && c.zero == c.total {
+			collapsed = append(collapsed, fmt.Sprintf("%s (%d rates)", contract.Service, c.total))
+		}
+	}
+	if len(collapsed) > 0 {
+		sort.Strings(collapsed)
+		return fmt.Errorf("every price is zero for %s", strings.Join(collapsed, ", "))
+	}
+
+	if fraction := float64(zero) / float64(len(rates)); fraction > v.maxZeroFraction {
+		return fmt.Errorf("%d of %d rates (%.1f%%) are zero-priced, maximum %.1f%%",
+			zero, len(rates), fraction*100, v.maxZeroFraction*100)
+	}
+	return nil
+}
+
+// PriceColumnScale is the scale of pricing_rates.price, NUMERIC(20, 10)
+const PriceColumnScale = 10
+
+// ValidateDecimalScale rejects prices with more decimal places than maxScale.
+// Postgres would silently round them on insert, so the stored snapshot would
+// no longer match its backup's content hash.
+func (v *IngestionValidator) ValidateDecimalScale(rates []NormalizedRate, maxScale int) error {
+	var over int
+	var first string
+	for _, r := range rates {
+		if !r.Price.Equal(r.Price.Round(int32(maxScale))) {
+			if over == 0 {
+				first = fmt.Sprintf("%s/%s/%s = %s", r.RateKey.Service, r.RateKey.ProductFamily, r.RateKey.Region, r.Price)
+			}
+			over++
+		}
+	}
+	if over > 0 {
+		return fmt.Errorf("%d price(s) exceed scale %d, e.g. %s", over, maxScale, first)
+	}
+	return nil
+}
+
+// RoundDecimalScale rounds prices to maxScale decimal places in place, the
+// way Postgres would on insert, and returns how many were rounded. Run it
+// before hashing and backup so backup and database hold the same value.
+func RoundDecimalScale(rates []NormalizedRate, maxScale int) int {
+	var rounded int
+	for i := range rates {
+		if p := rates[i].Price.Round(int32(maxScale)); !p.Equal(rates[i].Price) {
+			rates[i].Price = p
+			rounded++
+		}
+	}
+	return rounded
+}
+
+// ValidateRateKeyCompleteness rejects rate keys with an empty cloud, service or
+// region (and product family when required), which would never match a query
+func (v *IngestionValidator) ValidateRateKeyCompleteness(rates []NormalizedRate) error {
+	for i, r := range rates {
+		k := r.RateKey
+		var missing string
+		switch {
+		case k.Cloud == "":
+			missing = "cloud"
+		case k.Service == "":
+			missing = "service"
+		case k.Region == "":
+			missing = "region"
+		case v.requireProductFamily && k.ProductFamily == "":
+			missing = "product family"
+		default:
+			continue
+		}
+		return fmt.Errorf("rate %d has empty %s: %s/%s/%s/%s",
+			i, missing, k.Cloud, k.Service, k.ProductFamily, k.Region)
+	}
+	return nil
+}
+
 // ValidateDimensionsComplete ensures required dimensions exist
 func (v *IngestionValidator) ValidateDimensionsComplete(rates []NormalizedRate) error {
 	// Group by service
@@ -285,6 +529,54 @@ func (v *IngestionValidator) ValidateDimensionsComplete(rates []NormalizedRate)
 	return nil
 }
 
+// CanonicalUnits is the set of units the normalizers emit. Anything else is a
+// raw provider unit that slipped through normalization.
+var CanonicalUnits = map[string]bool{
+	"hours": true, "seconds": true, "month": true,
+	"GB": true, "GB-hours": true, "GB-month": true, "GB-seconds": true, "bytes": true,
+	"requests": true, "10K-requests": true, "1M-requests": true, "10K-transactions": true,
+	"unit": true, "units": true, "100-units": true, "count": true,
+	"LCU-hours": true, "NLCU-hours": true, "OCPU-hours": true, "GPU-hours": true, "VPU-GB-month": true,
+}
+
+// UnitIssue lists the non-canonical units found for one service
+type UnitIssue struct {
+	Service string
+	Units   []string
+}
+
+// ValidateUnitConsistency reports, per service, units outside CanonicalUnits.
+// Mixed spellings of a unit fragment rate keys and cause lookup misses. The
+// issues are always returned; an error is only returned in strict mode.
+func (v *IngestionValidator) ValidateUnitConsistency(rates []NormalizedRate) ([]UnitIssue, error) {
+	unknown := make(map[string]map[string]bool)
+	for _, r := range rates {
+		if CanonicalUnits[r.Unit] {
+			continue
+		}
+		if unknown[r.RateKey.Service] == nil {
+			unknown[r.RateKey.Service] = make(map[string]bool)
+		}
+		unknown[r.RateKey.Service][r.Unit] = true
+	}
+
+	issues := make([]UnitIssue, 0, len(unknown))
+	for service, units := range unknown {
+		issue := UnitIssue{Service: service}
+		for u := range units {
+			issue.Units = append(issue.Units, u)
+		}
+		sort.Strings(issue.Units)
+		issues = append(issues, issue)
+	}
+	sort.Slice(issues, func(i, j int) bool { return issues[i].Service < issues[j].Service })
+
+	if v.strictUnits && len(issues) > 0 {
+		return issues, fmt.Errorf("service %s has non-canonical units: %q", issues[0].Service, issues[0].Units)
+	}
+	return issues, nil
+}
+
 // ValidateNoDuplicates ensures no duplicate rate keys
 func (v *IngestionValidator) ValidateNoDuplicates(rates []NormalizedRate) error {
 	seen := make(map[string]bool)
@@ -298,6 +590,15 @@ func (v *IngestionValidator) ValidateNoDuplicates(rates []NormalizedRate) error
 	return nil
 }
 
+// CountDistinctRateKeys returns the number of distinct rate keys among rates
+func CountDistinctRateKeys(rates []NormalizedRate) int {
+	seen := make(map[string]bool)
+	for _, r := range rates {
+		seen[rateKeyForDedupe(r.RateKey)] = true
+	}
+	return len(seen)
+}
+
 func rateKeyForDedupe(k db.RateKey) string {
 	return fmt.Sprintf("%s|%s|%s|%s|%v", k.Cloud, k.Service, k.ProductFamily, k.Region, k.Attributes)
 }
@@ -318,3 +619,69 @@ func (v *IngestionValidator) ValidateCoverageNotDecreased(newCount, prevCount in
 
 	return nil
 }
+
+// ValidateRegionDistribution ensures every expected region has at least one
+// rate, catching multi-region runs whose data collapsed onto one region
+func (v *IngestionValidator) ValidateRegionDistribution(rates []NormalizedRate, expectedRegions []string) error {
+	counts := make(map[string]int)
+	for _, r := range rates {
+		counts[r.RateKey.Region]++
+	}
+
+	var missing []string
+	for _, region := range expectedRegions {
+		if counts[region] == 0 {
+			missing = append(missing, region)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	found := make([]string, 0, len(counts))
+	for region, n := range counts {
+		found = append(found, fmt.Sprintf("%s=%d", region, n))
+	}
+	sort.Strings(found)
+	return fmt.Errorf("no rates for %d of %d expected regions %v (rates by region: %v)",
+		len(missing), len(expectedRegions), missing, found)
+}
+
+// ValidateEffectiveDates fails if any rate takes effect more than the future
+// window after now; such terms are published ahead of time and are not yet
+// the price to estimate with
+func (v *IngestionValidator) ValidateEffectiveDates(rates []NormalizedRate, now time.Time) error {
+	if v.futureWindow <= 0 {
+		return nil
+	}
+	cutoff := now.Add(v.futureWindow)
+	var future int
+	var first *NormalizedRate
+	for i, r := range rates {
+		if r.EffectiveDate != nil && r.EffectiveDate.After(cutoff) {
+			if first == nil {
+				first = &rates[i]
+			}
+			future++
+		}
+	}
+	if future == 0 {
+		return nil
+	}
+	return fmt.Errorf("%d rates take effect more than %s from now (first: %s/%s effective %s)",
+		future, v.futureWindow, first.RateKey.Service, first.RateKey.Region, first.EffectiveDate.Format(time.RFC3339))
+}
+
+// DropFutureEffectiveDates removes rates taking effect more than window
+// after now, returning the remaining rates and how many were dropped
+func DropFutureEffectiveDates(rates []NormalizedRate, now time.Time, window time.Duration) ([]NormalizedRate, int) {
+	cutoff := now.Add(window)
+	kept := rates[:0:0]
+	for _, r := range rates {
+		if r.EffectiveDate != nil && r.EffectiveDate.After(cutoff) {
+			continue
+		}
+		kept = append(kept, r)
+	}
+	return kept, len(rates) - len(kept)
+}
@@ -0,0 +1,78 @@
+// Package ingestion - Snapshot integrity verification against archived backups
+package ingestion
+
+import (
+	"context"
+	"fmt"
+
+	"terraform-cost/db"
+
+	"github.com/google/uuid"
+)
+
+// IntegrityReport holds the three hashes compared by VerifySnapshotIntegrity
+type IntegrityReport struct {
+	SnapshotID      uuid.UUID `json:"snapshot_id"`
+	BackupPath      string    `json:"backup_path"`
+	ComputedHash    string    `json:"computed_hash"` // recomputed from DB rates
+	StoredHash      string    `json:"stored_hash"`   // pricing_snapshots.hash
+	BackupHash      string    `json:"backup_hash"`   // backup content_hash
+	DBRateCount     int       `json:"db_rate_count"`
+	BackupRateCount int       `json:"backup_rate_count"`
+	Verified        bool      `json:"verified"`
+	Mismatch        string    `json:"mismatch,omitempty"` // first disagreement found
+}
+
+// VerifySnapshotIntegrity proves a stored snapshot matches its archived backup.
+// The rates are reloaded from the DB and rehashed, then compared with the
+// snapshot's stored hash and the backup's content hash. A mismatch returns
+// the report together with an error describing the first disagreement.
+func VerifySnapshotIntegrity(ctx context.Context, store db.PricingStore, snapshotID uuid.UUID, backupPath string) (*IntegrityReport, error) {
+	snapshot, err := store.GetSnapshot(ctx, snapshotID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load snapshot: %w", err)
+	}
+	if snapshot == nil {
+		return nil, fmt.Errorf("snapshot %s not found", snapshotID)
+	}
+
+	stored, err := store.GetRatesBySnapshot(ctx, snapshotID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load rates: %w", err)
+	}
+
+	// ReadBackup already checks the backup against its own content hash
+	backup, err := NewBackupManager().ReadBackup(backupPath)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &IntegrityReport{
+		SnapshotID:      snapshotID,
+		BackupPath:      backupPath,
+		ComputedHash:    calculateHash(RatesFromSnapshot(stored)),
+		StoredHash:      snapshot.Hash,
+		BackupHash:      backup.ContentHash,
+		DBRateCount:     len(stored),
+		BackupRateCount: backup.RateCount,
+	}
+
+	switch {
+	case backup.Provider != snapshot.Cloud || backup.Region != snapshot.Region:
+		report.Mismatch = fmt.Sprintf("backup is for %s/%s, snapshot is %s/%s",
+			backup.Provider, backup.Region, snapshot.Cloud, snapshot.Region)
+	case report.ComputedHash != report.StoredHash:
+		report.Mismatch = fmt.Sprintf("DB rates hash %s does not match stored snapshot hash %s",
+			report.ComputedHash, report.StoredHash)
+	case report.StoredHash != report.BackupHash:
+		report.Mismatch = fmt.Sprintf("stored snapshot hash %s does not match backup hash %s",
+			report.StoredHash, report.BackupHash)
+	}
+
+	if report.Mismatch != "" {
+		return report, fmt.Errorf("integrity check failed: %s", report.Mismatch)
+	}
+
+	report.Verified = true
+	return report, nil
+}
@@ -0,0 +1,132 @@
+// Package ingestion - Snapshot integrity verification tests
+package ingestion
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"terraform-cost/db"
+	"terraform-cost/db/memstore"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// snapshotStore serves a single snapshot and its rates
+type snapshotStore struct {
+	db.PricingStore
+	snapshot *db.PricingSnapshot
+	rates    []db.SnapshotRate
+}
+
+func (s *snapshotStore) GetSnapshot(ctx context.Context, id uuid.UUID) (*db.PricingSnapshot, error) {
+	if s.snapshot == nil || s.snapshot.ID != id {
+		return nil, nil
+	}
+	return s.snapshot, nil
+}
+
+func (s *snapshotStore) GetRatesBySnapshot(ctx context.Context, id uuid.UUID) ([]db.SnapshotRate, error) {
+	return s.rates, nil
+}
+
+// storedRates converts normalized rates into what the DB would return
+func storedRates(snapshotID uuid.UUID, rates []NormalizedRate) []db.SnapshotRate {
+	out := make([]db.SnapshotRate, len(rates))
+	for i, r := range rates {
+		out[i] = db.SnapshotRate{
+			RateKey: r.RateKey,
+			Rate: db.PricingRate{
+				ID:         uuid.New(),
+				SnapshotID: snapshotID,
+				Unit:       r.Unit,
+				Price:      r.Price,
+				Currency:   r.Currency,
+				Confidence: r.Confidence,
+			},
+		}
+	}
+	return out
+}
+
+func TestVerifySnapshotIntegrity(t *testing.T) {
+	rates, _ := (&passthroughNormalizer{cloud: db.AWS}).Normalize(context.Background(), testRawPrices("us-east-1", 3))
+	hash := calculateHash(rates)
+
+	backupPath, err := NewBackupManager().WriteBackup(t.TempDir(), &SnapshotBackup{
+		Provider:      db.AWS,
+		Region:        "us-east-1",
+		Alias:         "default",
+		Timestamp:     time.Now(),
+		ContentHash:   hash,
+		RateCount:     len(rates),
+		SchemaVersion: "1.0",
+		Rates:         rates,
+	})
+	if err != nil {
+		t.Fatalf("failed to write backup: %v", err)
+	}
+
+	snapshotID := uuid.New()
+	store := &snapshotStore{
+		snapshot: &db.PricingSnapshot{ID: snapshotID, Cloud: db.AWS, Region: "us-east-1", Hash: hash},
+		rates:    storedRates(snapshotID, rates),
+	}
+
+	report, err := VerifySnapshotIntegrity(context.Background(), store, snapshotID, backupPath)
+	if err != nil {
+		t.Fatalf("expected matching data to verify, got %v", err)
+	}
+	if !report.Verified || report.ComputedHash != hash || report.DBRateCount != 3 {
+		t.Errorf("unexpected report: %+v", report)
+	}
+
+	// Alter one price in the DB
+	store.rates[1].Rate.Price = decimal.RequireFromString("0.024")
+	report, err = VerifySnapshotIntegrity(context.Background(), store, snapshotID, backupPath)
+	if err == nil {
+		t.Fatal("expected altered price to fail verification")
+	}
+	if report == nil || report.Verified || !strings.Contains(report.Mismatch, "DB rates hash") {
+		t.Errorf("expected DB hash mismatch to be reported first, got %+v", report)
+	}
+
+	// Unknown snapshot
+	if _, err := VerifySnapshotIntegrity(context.Background(), store, uuid.New(), backupPath); err == nil {
+		t.Error("expected error for missing snapshot")
+	}
+}
+
+func TestLifecycleRoundsPricesBeforeBackup(t *testing.T) {
+	config := DefaultLifecycleConfig()
+	config.Provider = db.AWS
+	config.Region = "us-east-1"
+	config.Environment = "development"
+	config.BackupDir = t.TempDir()
+
+	prices := testRawPrices("us-east-1", 5)
+	prices[2].PricePerUnit = "0.123456789012345"
+	store := memstore.NewMemoryStore()
+	result, err := NewLifecycle(&staticFetcher{cloud: db.AWS, prices: prices}, &passthroughNormalizer{cloud: db.AWS}, store).Execute(context.Background(), config)
+	if err != nil || !result.Success {
+		t.Fatalf("ingestion failed: %v %+v", err, result)
+	}
+
+	// Store what Postgres would keep in a NUMERIC(20, 10) column
+	stored, err := store.GetRatesBySnapshot(context.Background(), *result.SnapshotID)
+	if err != nil {
+		t.Fatalf("failed to read rates: %v", err)
+	}
+	for i := range stored {
+		stored[i].Rate.Price = stored[i].Rate.Price.Round(PriceColumnScale)
+	}
+	snapshot, _ := store.GetSnapshot(context.Background(), *result.SnapshotID)
+	verifier := &snapshotStore{snapshot: snapshot, rates: stored}
+
+	report, err := VerifySnapshotIntegrity(context.Background(), verifier, snapshot.ID, result.BackupPath)
+	if err != nil {
+		t.Fatalf("expected rounded snapshot to verify, got %v (%+v)", err, report)
+	}
+}
@@ -3,13 +3,17 @@
 package ingestion
 
 import (
+	"compress/gzip"
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
 	"sync"
 	"time"
 
 	"terraform-cost/db"
+	"terraform-cost/db/regions"
 
 	"github.com/google/uuid"
 )
@@ -67,9 +71,16 @@ type LifecycleState struct {
 
 	// Backup verification
 	BackupPath    string
+	RawPath       string
 	BackupHash    string
 	BackupVerified bool
 
+	// Validation outcome, persisted as the validation report
+	Checks               []ValidationCheck
+	Validation           *ValidationResult
+	Coverage             *CoverageReport
+	ValidationReportPath string
+
 	// Only assigned after successful commit
 	SnapshotID    *uuid.UUID
 
@@ -80,28 +91,102 @@ type LifecycleState struct {
 
 // LifecycleConfig configures the ingestion lifecycle
 type LifecycleConfig struct {
-	Provider         db.CloudProvider
-	Region           string
-	Alias            string
-	Environment      string // "production" | "staging" | "development"
-	BackupDir        string
-	DryRun           bool
-	AllowMockPricing bool   // MUST BE FALSE IN PRODUCTION
-	MinCoverage      float64
-	Timeout          time.Duration
+	Provider            db.CloudProvider
+	Region              string
+	Alias               string
+	Environment         string // "production" | "staging" | "development"
+	BackupDir           string
+	DryRun              bool
+	AllowMockPricing    bool // MUST BE FALSE IN PRODUCTION
+	MinCoverage         float64
+	MinServicesFraction float64 // share of services that must fetch cleanly
+	Timeout             time.Duration
+	DeterministicIDs    bool          // derive snapshot IDs from cloud, region, alias and content hash
+	CommitRetries       int           // retries after a serialization failure or deadlock
+	CommitBackoff       time.Duration // delay before the first retry, doubled for each one after
+
+	// AllowRestrictedRegions permits GovCloud/China regions, which need a
+	// fetcher for their own pricing source
+	AllowRestrictedRegions bool
+
+	// MaxAvgDriftPercent and MaxSingleDriftPercent fail validation when prices
+	// moved more than this against the active snapshot, on average or for any
+	// one rate (0 = no limit). ForceDrift commits anyway.
+	MaxAvgDriftPercent    float64
+	MaxSingleDriftPercent float64
+	ForceDrift            bool
+
+	// AbortIfCommitting fails the commit when another ingestion of the same
+	// cloud, region and alias holds the ingestion lock, instead of waiting
+	// for it (a retried CI job racing the original)
+	AbortIfCommitting bool
+
+	// BackupCompression is the gzip level of backups and raw price dumps
+	// (gzip.DefaultCompression in DefaultLifecycleConfig; gzip.NoCompression is 0)
+	BackupCompression int
+
+	// SaveRaw stores the fetched raw prices next to the backup so a later
+	// normalizer can reprocess them (see ReprocessBackup)
+	SaveRaw bool
+
+	// StubConfidence is the confidence given to rates from fetchers that are
+	// not a real API, such as files and stubs (0 = DefaultStubConfidence)
+	StubConfidence float64
+
+	// RequireRegionRates fails validation unless some rates belong to Region;
+	// multi-region runs set it so one region's data cannot land under another
+	RequireRegionRates bool
+
+	// DistinctKeyCoverage compares coverage by distinct rate keys instead of
+	// rows, so tiered rates do not inflate or deflate it
+	DistinctKeyCoverage bool
+
+	// FutureEffectiveWindow fails validation for rates that take effect more
+	// than this long after the run (0 disables the check); with
+	// DropFutureRates they are removed before validation instead
+	FutureEffectiveWindow time.Duration
+	DropFutureRates       bool
+
+	// CoverageBaselinePath names a coverage baseline file (see
+	// CoverageBaseline); validation fails when a service has fewer rates than
+	// its baseline less BaselineTolerancePercent. UpdateCoverageBaseline skips
+	// the comparison and records this run's counts once it has committed.
+	CoverageBaselinePath     string
+	BaselineTolerancePercent float64
+	UpdateCoverageBaseline   bool
+
+	// Metadata is stored on the snapshot as provenance (CI job ID, git SHA, operator)
+	Metadata map[string]string
+
+	// OnValidationFailure, if set, receives the per-service validation
+	// detail before any failed check aborts the run
+	OnValidationFailure func(*ValidationResult)
 }
 
 // DefaultLifecycleConfig returns safe production defaults
 func DefaultLifecycleConfig() *LifecycleConfig {
 	return &LifecycleConfig{
-		Alias:            "default",
-		Environment:      "production",
-		BackupDir:        "./pricing-backups",
-		DryRun:           false,
-		AllowMockPricing: false, // Safe default
-		MinCoverage:      95.0,
-		Timeout:          30 * time.Minute,
+		Alias:               "default",
+		Environment:         "production",
+		BackupDir:           "./pricing-backups",
+		DryRun:              false,
+		AllowMockPricing:    false, // Safe default
+		MinCoverage:         95.0,
+		MinServicesFraction: DefaultMinServicesFraction,
+		Timeout:             30 * time.Minute,
+		CommitRetries:       3,
+		CommitBackoff:       200 * time.Millisecond,
+		StubConfidence:      DefaultStubConfidence,
+		BackupCompression:   gzip.DefaultCompression,
+	}
+}
+
+// stubConfidence returns StubConfidence, defaulting unset values
+func (c *LifecycleConfig) stubConfidence() float64 {
+	if c.StubConfidence <= 0 {
+		return DefaultStubConfidence
 	}
+	return c.StubConfidence
 }
 
 // Lifecycle manages the strict ingestion state machine
@@ -114,6 +199,7 @@ type Lifecycle struct {
 	validator *IngestionValidator
 	backupMgr *BackupManager
 	store     db.PricingStore
+	logger    *slog.Logger
 }
 
 // NewLifecycle creates a new strict ingestion lifecycle
@@ -134,6 +220,16 @@ func NewLifecycle(
 	}
 }
 
+// WithLogger sets the structured logger for lifecycle events.
+// The logger is also handed to the fetcher when it accepts one.
+func (l *Lifecycle) WithLogger(logger *slog.Logger) *Lifecycle {
+	l.logger = logger
+	if ls, ok := l.fetcher.(loggerSetter); ok {
+		ls.SetLogger(logger)
+	}
+	return l
+}
+
 // Execute runs the complete strict ingestion lifecycle
 func (l *Lifecycle) Execute(ctx context.Context, config *LifecycleConfig) (*LifecycleResult, error) {
 	l.mu.Lock()
@@ -143,6 +239,7 @@ func (l *Lifecycle) Execute(ctx context.Context, config *LifecycleConfig) (*Life
 		config = DefaultLifecycleConfig()
 	}
 	l.config = config
+	l.backupMgr.WithCompression(config.BackupCompression)
 
 	// Initialize state
 	l.state = &LifecycleState{
@@ -168,40 +265,55 @@ func (l *Lifecycle) Execute(ctx context.Context, config *LifecycleConfig) (*Life
 		return l.fail(err)
 	}
 
+	// Unknown regions would fetch nothing and fail confusingly later
+	if err := ValidateRegion(regions.NewRegistry(), config.Provider, config.Region, config.AllowRestrictedRegions); err != nil {
+		return l.fail(err)
+	}
+
 	// ==================================================
 	// PHASE: FETCHING (NO DB ACCESS)
 	// ==================================================
+	phaseStart := time.Now()
 	if err := l.phaseFetching(ctx); err != nil {
 		return l.fail(err)
 	}
+	l.logPhase(phaseStart)
 
 	// ==================================================
 	// PHASE: NORMALIZING (NO DB ACCESS)
 	// ==================================================
+	phaseStart = time.Now()
 	if err := l.phaseNormalizing(ctx); err != nil {
 		return l.fail(err)
 	}
+	l.logPhase(phaseStart)
 
 	// ==================================================
 	// PHASE: VALIDATING (NO DB ACCESS)
 	// ==================================================
+	phaseStart = time.Now()
 	if err := l.phaseValidating(ctx); err != nil {
 		return l.fail(err)
 	}
+	l.logPhase(phaseStart)
 
 	// ==================================================
 	// PHASE: STAGING (NO DB ACCESS)
 	// ==================================================
+	phaseStart = time.Now()
 	if err := l.phaseStaging(ctx); err != nil {
 		return l.fail(err)
 	}
+	l.logPhase(phaseStart)
 
 	// ==================================================
 	// PHASE: BACKUP (MANDATORY)
 	// ==================================================
+	phaseStart = time.Now()
 	if err := l.phaseBackup(ctx); err != nil {
 		return l.fail(err)
 	}
+	l.logPhase(phaseStart)
 
 	// ==================================================
 	// DRY-RUN CHECK - Stop before DB writes
@@ -214,38 +326,73 @@ func (l *Lifecycle) Execute(ctx context.Context, config *LifecycleConfig) (*Life
 	// ==================================================
 	// PHASE: COMMITTING (SINGLE DB TRANSACTION)
 	// ==================================================
+	phaseStart = time.Now()
 	if err := l.phaseCommitting(ctx); err != nil {
 		return l.fail(err)
 	}
+	l.logPhase(phaseStart)
+
+	if config.UpdateCoverageBaseline && config.CoverageBaselinePath != "" {
+		updateCoverageBaseline(l.config, l.state.Normalized, l.log())
+	}
 
 	return l.success("ingestion complete")
 }
 
+// updateCoverageBaseline records the committed rates as the region's coverage
+// baseline. The snapshot is already active, so a failure only warns.
+func updateCoverageBaseline(config *LifecycleConfig, rates []NormalizedRate, logger *slog.Logger) {
+	path := config.CoverageBaselinePath
+	baseline, err := LoadCoverageBaseline(path)
+	if err == nil {
+		baseline.Update(config.Provider, config.Region, rates, time.Now())
+		err = baseline.Save(path)
+	}
+	if err != nil {
+		logger.Warn("failed to update coverage baseline", "path", path, "error", err)
+		return
+	}
+	logger.Info("coverage baseline updated", "path", path)
+}
+
 // enforceProductionGuards blocks unsafe operations
 func (l *Lifecycle) enforceProductionGuards() error {
+	return enforceProductionGuards(l.config, l.fetcher)
+}
+
+// enforceProductionGuards blocks mock pricing and fetchers that are not a
+// real API in production; every lifecycle runs it before fetching
+func enforceProductionGuards(config *LifecycleConfig, fetcher PriceFetcher) error {
 	// HARD GUARD: No mocks in production
-	if l.config.Environment == "production" && l.config.AllowMockPricing {
+	if config.Environment == "production" && config.AllowMockPricing {
 		return fmt.Errorf("FATAL: mock pricing forbidden in production environment")
 	}
 
 	// HARD GUARD: Fetcher must be real API
-	if realAPI, ok := l.fetcher.(RealAPIFetcher); ok {
-		if !realAPI.IsRealAPI() && l.config.Environment == "production" {
-			return fmt.Errorf("FATAL: fetcher is not a real API implementation")
-		}
+	if !isRealAPI(fetcher) && config.Environment == "production" {
+		return fmt.Errorf("FATAL: fetcher is not a real API implementation")
 	}
 
 	return nil
 }
 
+// fetcherIsRealAPI reports whether the fetcher passes the real API guard
+func (l *Lifecycle) fetcherIsRealAPI() bool {
+	return isRealAPI(l.fetcher)
+}
+
 // phaseFetching downloads raw pricing (NO DB ACCESS)
 func (l *Lifecycle) phaseFetching(ctx context.Context) error {
 	l.state.Phase = PhaseFetching
 
 	rawPrices, err := l.fetcher.FetchRegion(ctx, l.config.Region)
+	rawPrices, partial, err := applyMinServices(rawPrices, err, l.config.MinServicesFraction)
 	if err != nil {
 		return fmt.Errorf("fetch failed: %w", err)
 	}
+	if partial != nil {
+		l.log().Warn("proceeding with partial fetch", "failed_services", len(partial.Failed), "expected_services", partial.Expected, "error", partial)
+	}
 
 	if len(rawPrices) == 0 {
 		return fmt.Errorf("fetch returned 0 prices")
@@ -260,35 +407,170 @@ func (l *Lifecycle) phaseFetching(ctx context.Context) error {
 func (l *Lifecycle) phaseNormalizing(ctx context.Context) error {
 	l.state.Phase = PhaseNormalizing
 
-	normalized, err := l.normalizer.Normalize(l.state.RawPrices)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	normalizer := sourceNormalizer(l.fetcher, l.normalizer, l.config.stubConfidence())
+	normalized, err := normalizer.Normalize(ctx, l.state.RawPrices)
 	if err != nil {
 		return fmt.Errorf("normalization failed: %w", err)
 	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	normalized = dropFutureRates(l.config, normalized, l.log())
 
 	if len(normalized) == 0 {
 		return fmt.Errorf("normalization produced 0 rates")
 	}
 
+	// Round to the column scale now so the hash and backup match the DB
+	if n := RoundDecimalScale(normalized, PriceColumnScale); n > 0 {
+		l.log().Warn("rounded prices to column scale", "count", n, "scale", PriceColumnScale)
+	}
+
 	// Store in memory only - NO DB WRITES
 	l.state.Normalized = normalized
 	l.state.ContentHash = calculateHash(normalized)
 	return nil
 }
 
+// dropFutureRates removes rates that take effect beyond FutureEffectiveWindow
+// when DropFutureRates is set; future-dated terms are not yet the price to
+// estimate with
+func dropFutureRates(config *LifecycleConfig, rates []NormalizedRate, logger *slog.Logger) []NormalizedRate {
+	if !config.DropFutureRates || config.FutureEffectiveWindow <= 0 {
+		return rates
+	}
+	rates, dropped := DropFutureEffectiveDates(rates, time.Now(), config.FutureEffectiveWindow)
+	if dropped > 0 {
+		logger.Warn("dropped future-dated rates", "count", dropped, "window", config.FutureEffectiveWindow)
+	}
+	return rates
+}
+
+// previousRateCount returns a snapshot's size for the coverage check, in
+// distinct rate keys when distinct is set (0 without a snapshot)
+func previousRateCount(ctx context.Context, store db.PricingStore, prevSnapshot *db.PricingSnapshot, distinct bool) int {
+	if prevSnapshot == nil {
+		return 0
+	}
+	var count int
+	if distinct {
+		count, _ = store.CountDistinctRateKeys(ctx, prevSnapshot.ID)
+	} else {
+		count, _ = store.CountRates(ctx, prevSnapshot.ID)
+	}
+	return count
+}
+
 // phaseValidating runs governance checks (NO DB ACCESS)
 func (l *Lifecycle) phaseValidating(ctx context.Context) error {
 	l.state.Phase = PhaseValidating
 
-	l.validator.SetMinCoveragePercent(l.config.MinCoverage)
+	// Recorded for the validation report, which is written pass or fail
+	result, checks, err := validateRates(ctx, l.store, l.validator, l.config, l.state.Normalized, l.log())
+	l.state.Validation = result
+	l.state.Checks = append(l.state.Checks, checks...)
+	l.state.Coverage = NewCoverageTracker().GenerateReport(
+		&db.PricingSnapshot{Cloud: l.config.Provider, Region: l.config.Region}, l.state.Normalized)
+	return err
+}
+
+// validateRates runs the governance checks a commit is gated on against the
+// active snapshot. It returns the per-service result and the checks that ran;
+// every failed check, whichever it is, reaches OnValidationFailure.
+func validateRates(ctx context.Context, store db.PricingStore, validator *IngestionValidator, config *LifecycleConfig, rates []NormalizedRate, logger *slog.Logger) (*ValidationResult, []ValidationCheck, error) {
+	validator.SetMinCoveragePercent(config.MinCoverage)
+	validator.SetDistinctKeyCoverage(config.DistinctKeyCoverage)
 
 	// Get previous snapshot for coverage comparison
-	prevSnapshot, _ := l.store.GetActiveSnapshot(ctx, l.config.Provider, l.config.Region, l.config.Alias)
-	var prevRateCount int
-	if prevSnapshot != nil {
-		prevRateCount, _ = l.store.CountRates(ctx, prevSnapshot.ID)
+	prevSnapshot, _ := store.GetActiveSnapshot(ctx, config.Provider, config.Region, config.Alias)
+	prevRateCount := previousRateCount(ctx, store, prevSnapshot, config.DistinctKeyCoverage)
+
+	// Surface normalizer gaps even when they are not fatal
+	issues, _ := validator.ValidateUnitConsistency(rates)
+	for _, issue := range issues {
+		logger.Warn("non-canonical units", "service", issue.Service, "units", issue.Units)
+	}
+
+	result := validator.Validate(config.Provider, rates)
+	checks, err := runValidationChecks(ctx, store, validator, config, rates, prevSnapshot, prevRateCount, logger)
+	if errors.Is(err, ErrValidationFailed) {
+		result.IsValid = false
+		result.Errors = append(result.Errors, err.Error())
+		if config.OnValidationFailure != nil {
+			config.OnValidationFailure(result)
+		}
 	}
+	return result, checks, err
+}
+
+// runValidationChecks runs the configured checks in order and returns the
+// checks that ran with the first failure as a *ValidationError, or the error
+// that stopped a check from running.
+func runValidationChecks(ctx context.Context, store db.PricingStore, validator *IngestionValidator, config *LifecycleConfig, rates []NormalizedRate, prevSnapshot *db.PricingSnapshot, prevRateCount int, logger *slog.Logger) ([]ValidationCheck, error) {
+	var checks []ValidationCheck
 
-	return l.validator.ValidateAll(l.state.Normalized, prevRateCount)
+	if config.RequireRegionRates {
+		err := validationFailure("region_distribution", validator.ValidateRegionDistribution(rates, []string{config.Region}))
+		checks = append(checks, newValidationCheck("region_distribution", err))
+		if err != nil {
+			return checks, err
+		}
+	}
+
+	if config.FutureEffectiveWindow > 0 {
+		validator.SetFutureEffectiveWindow(config.FutureEffectiveWindow)
+		err := validationFailure("effective_dates", validator.ValidateEffectiveDates(rates, time.Now()))
+		checks = append(checks, newValidationCheck("effective_dates", err))
+		if err != nil {
+			return checks, err
+		}
+	}
+
+	if config.CoverageBaselinePath != "" && !config.UpdateCoverageBaseline {
+		baseline, err := LoadCoverageBaseline(config.CoverageBaselinePath)
+		if err != nil {
+			return checks, err
+		}
+		err = validationFailure("coverage_baseline", validator.ValidateAgainstBaseline(
+			rates, baseline.Lookup(config.Provider, config.Region), config.BaselineTolerancePercent))
+		checks = append(checks, newValidationCheck("coverage_baseline", err))
+		if err != nil {
+			return checks, err
+		}
+	}
+
+	if prevSnapshot != nil && (config.MaxAvgDriftPercent > 0 || config.MaxSingleDriftPercent > 0) {
+		err := checkDriftLimits(ctx, store, config, rates, prevSnapshot.ID, logger)
+		if err != nil && !errors.Is(err, ErrValidationFailed) {
+			return checks, err
+		}
+		checks = append(checks, newValidationCheck("drift_limits", err))
+		if err != nil {
+			return checks, err
+		}
+	}
+
+	allChecks, err := validator.ValidateAllChecks(rates, prevRateCount)
+	return append(checks, allChecks...), err
+}
+
+// checkDriftLimits compares the new rates with the active snapshot's for the
+// drift_limits check; ForceDrift downgrades a failure to a warning
+func checkDriftLimits(ctx context.Context, store db.PricingStore, config *LifecycleConfig, rates []NormalizedRate, prevSnapshotID uuid.UUID, logger *slog.Logger) error {
+	prev, err := store.GetRatesBySnapshot(ctx, prevSnapshotID)
+	if err != nil {
+		return fmt.Errorf("failed to load active snapshot rates for drift check: %w", err)
+	}
+	err = validationFailure("drift_limits", CheckDriftLimits(RatesFromSnapshot(prev), rates, config.MaxAvgDriftPercent, config.MaxSingleDriftPercent))
+	if err != nil && config.ForceDrift {
+		logger.Warn("drift limit exceeded, committing because of ForceDrift", "error", err)
+		return nil
+	}
+	return err
 }
 
 // phaseStaging prepares for commit (NO DB ACCESS)
@@ -310,7 +592,7 @@ func (l *Lifecycle) phaseBackup(ctx context.Context) error {
 		Timestamp:     time.Now(),
 		ContentHash:   l.state.ContentHash,
 		RateCount:     len(l.state.Normalized),
-		SchemaVersion: "1.0",
+		SchemaVersion: BackupSchemaVersion,
 		Rates:         l.state.Normalized,
 	}
 
@@ -320,10 +602,26 @@ func (l *Lifecycle) phaseBackup(ctx context.Context) error {
 	}
 
 	// Verify backup was written correctly
-	if err := l.verifyBackup(backupPath); err != nil {
+	if err := verifyBackup(l.backupMgr, backupPath, l.state.ContentHash); err != nil {
 		return fmt.Errorf("backup verification failed: %w", err)
 	}
 
+	if l.config.SaveRaw {
+		rawPath, err := l.backupMgr.WriteRawPrices(backupPath, &RawPriceDump{
+			Provider:  l.config.Provider,
+			Region:    l.config.Region,
+			Alias:     l.config.Alias,
+			Timestamp: backup.Timestamp,
+			RealAPI:   l.fetcherIsRealAPI(),
+			RawCount:  len(l.state.RawPrices),
+			Prices:    l.state.RawPrices,
+		})
+		if err != nil {
+			return fmt.Errorf("backup failed: %w", err)
+		}
+		l.state.RawPath = rawPath
+	}
+
 	l.state.BackupPath = backupPath
 	l.state.BackupHash = l.state.ContentHash
 	l.state.BackupVerified = true
@@ -332,28 +630,46 @@ func (l *Lifecycle) phaseBackup(ctx context.Context) error {
 	return nil
 }
 
-// verifyBackup reads back and verifies the backup
-func (l *Lifecycle) verifyBackup(path string) error {
+// verifyBackup reads back a backup and checks it holds contentHash
+func verifyBackup(backupMgr *BackupManager, path, contentHash string) error {
 	// Check file exists
 	if _, err := os.Stat(path); os.IsNotExist(err) {
 		return fmt.Errorf("backup file does not exist: %s", path)
 	}
 
 	// Read and validate
-	backup, err := l.backupMgr.ReadBackup(path)
+	backup, err := backupMgr.ReadBackup(path)
 	if err != nil {
 		return fmt.Errorf("backup read failed: %w", err)
 	}
 
 	// Verify hash matches
-	if backup.ContentHash != l.state.ContentHash {
+	if backup.ContentHash != contentHash {
 		return fmt.Errorf("backup hash mismatch: expected %s, got %s",
-			l.state.ContentHash, backup.ContentHash)
+			contentHash, backup.ContentHash)
 	}
 
 	return nil
 }
 
+// cancelCheckInterval is how many rates the normalize and commit loops
+// process between context cancellation checks
+const cancelCheckInterval = 1000
+
+// checkCancelled returns the context's error every cancelCheckInterval
+// iterations so long loops stop promptly on timeout or shutdown
+func checkCancelled(ctx context.Context, i int) error {
+	if i%cancelCheckInterval != 0 {
+		return nil
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		return nil
+	}
+}
+
 // phaseCommitting performs atomic DB transaction
 func (l *Lifecycle) phaseCommitting(ctx context.Context) error {
 	l.state.Phase = PhaseCommitting
@@ -366,17 +682,59 @@ func (l *Lifecycle) phaseCommitting(ctx context.Context) error {
 		return fmt.Errorf("FATAL: backup path is empty")
 	}
 
+	snapshotID, err := commitSnapshot(ctx, l.store, l.config, l.state.ContentHash, l.log(), l.commitTransaction)
+	if err != nil {
+		return err
+	}
+	l.state.SnapshotID = &snapshotID
+	l.state.Phase = PhaseActive
+	return nil
+}
+
+// commitSnapshot takes the ingestion lock and runs commit, retrying the whole
+// transaction on serialization failures and deadlocks. Content that is
+// already ingested is not committed again: the existing snapshot's ID is
+// returned instead.
+func commitSnapshot(ctx context.Context, store db.PricingStore, config *LifecycleConfig, contentHash string, logger *slog.Logger, commit func(context.Context) (uuid.UUID, error)) (uuid.UUID, error) {
+	// Only one ingestion per cloud, region and alias commits at a time;
+	// a run that waited then finds the winner's snapshot by hash below
+	unlock, err := store.LockIngestion(ctx, config.Provider, config.Region, config.Alias, !config.AbortIfCommitting)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to take ingestion lock: %w", err)
+	}
+	defer unlock()
+
 	// Check for existing snapshot with same hash (idempotency)
-	existing, _ := l.store.FindSnapshotByHash(ctx, l.config.Provider, l.config.Region, l.config.Alias, l.state.ContentHash)
+	existing, _ := store.FindSnapshotByHash(ctx, config.Provider, config.Region, config.Alias, contentHash)
 	if existing != nil {
 		// Already ingested with same content
-		l.state.SnapshotID = &existing.ID
-		l.state.Phase = PhaseActive
-		return nil
+		return existing.ID, nil
+	}
+
+	backoff := config.CommitBackoff
+	for attempt := 0; ; attempt++ {
+		snapshotID, err := commit(ctx)
+		if err == nil {
+			return snapshotID, nil
+		}
+		if !db.IsRetryableTxError(err) || attempt >= config.CommitRetries {
+			return uuid.Nil, err
+		}
+
+		logger.Warn("retrying commit", "attempt", attempt+1, "backoff", backoff, "error", err)
+		select {
+		case <-ctx.Done():
+			return uuid.Nil, fmt.Errorf("commit retry cancelled: %w", ctx.Err())
+		case <-time.After(backoff):
+		}
+		backoff *= 2
 	}
+}
 
-	// Create snapshot
-	snapshotID := uuid.New()
+// commitTransaction writes and activates the snapshot in one transaction,
+// under a fresh snapshot ID for each attempt
+func (l *Lifecycle) commitTransaction(ctx context.Context) (uuid.UUID, error) {
+	snapshotID := newSnapshotID(l.config.DeterministicIDs, l.config.Provider, l.config.Region, l.config.Alias, l.state.ContentHash)
 	snapshot := &db.PricingSnapshot{
 		ID:            snapshotID,
 		Cloud:         l.config.Provider,
@@ -388,12 +746,13 @@ func (l *Lifecycle) phaseCommitting(ctx context.Context) error {
 		Hash:          l.state.ContentHash,
 		Version:       "1.0",
 		IsActive:      false, // Not active until transaction commits
+		Metadata:      l.config.Metadata,
 	}
 
 	// Begin transaction
 	tx, err := l.store.BeginTx(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
+		return uuid.Nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
 
 	// Rollback on any error
@@ -406,15 +765,18 @@ func (l *Lifecycle) phaseCommitting(ctx context.Context) error {
 
 	// Insert snapshot (state='staging')
 	if err = tx.CreateSnapshot(ctx, snapshot); err != nil {
-		return fmt.Errorf("failed to create snapshot: %w", err)
+		return uuid.Nil, fmt.Errorf("failed to create snapshot: %w", err)
 	}
 
 	// Insert all rates
-	for _, nr := range l.state.Normalized {
+	for i, nr := range l.state.Normalized {
+		if err := checkCancelled(ctx, i); err != nil {
+			return uuid.Nil, fmt.Errorf("commit cancelled after %d rates: %w", i, err)
+		}
 		nr.RateKey.ID = uuid.New()
 		key, err := tx.UpsertRateKey(ctx, &nr.RateKey)
 		if err != nil {
-			return fmt.Errorf("failed to upsert rate key: %w", err)
+			return uuid.Nil, fmt.Errorf("failed to upsert rate key: %w", err)
 		}
 
 		rate := &db.PricingRate{
@@ -427,71 +789,155 @@ func (l *Lifecycle) phaseCommitting(ctx context.Context) error {
 			Confidence: nr.Confidence,
 			TierMin:    nr.TierMin,
 			TierMax:    nr.TierMax,
+
+			EffectiveDate: nr.EffectiveDate,
+			Labels:        nr.Labels,
 		}
 		if err = tx.CreateRate(ctx, rate); err != nil {
-			return fmt.Errorf("failed to create rate: %w", err)
+			return uuid.Nil, fmt.Errorf("failed to create rate: %w", err)
 		}
 	}
 
 	// Activate snapshot (state='ready', is_active=true)
 	if err = tx.ActivateSnapshot(ctx, snapshotID); err != nil {
-		return fmt.Errorf("failed to activate snapshot: %w", err)
+		return uuid.Nil, fmt.Errorf("failed to activate snapshot: %w", err)
 	}
 
 	// Commit transaction
 	if err = tx.Commit(); err != nil {
-		return fmt.Errorf("commit failed: %w", err)
+		return uuid.Nil, fmt.Errorf("commit failed: %w", err)
 	}
 	committed = true
+	return snapshotID, nil
+}
 
-	l.state.SnapshotID = &snapshotID
-	l.state.Phase = PhaseActive
-	return nil
+// log returns the lifecycle logger scoped to the current run
+func (l *Lifecycle) log() *slog.Logger {
+	logger := loggerOrDefault(l.logger)
+	if l.config != nil {
+		logger = logger.With("provider", string(l.config.Provider), "region", l.config.Region, "alias", l.config.Alias)
+	}
+	return logger
+}
+
+// rateCount is the number of rates held in memory at the current phase
+func (l *Lifecycle) rateCount() int {
+	if len(l.state.Normalized) > 0 {
+		return len(l.state.Normalized)
+	}
+	return len(l.state.RawPrices)
+}
+
+// logPhase records completion of the current phase
+func (l *Lifecycle) logPhase(start time.Time) {
+	l.log().Info("phase complete",
+		"phase", l.state.Phase.String(),
+		"rate_count", l.rateCount(),
+		"duration", time.Since(start))
 }
 
 // fail marks the lifecycle as failed
 func (l *Lifecycle) fail(err error) (*LifecycleResult, error) {
+	failedPhase := l.state.Phase
 	l.state.Phase = PhaseFailed
 	l.state.Errors = append(l.state.Errors, err.Error())
 
+	l.log().Error("ingestion failed",
+		"phase", failedPhase.String(),
+		"rate_count", l.rateCount(),
+		"duration", time.Since(l.state.StartTime),
+		"error", err)
+	l.writeValidationReport(failedPhase, err.Error())
+
 	return &LifecycleResult{
-		Success:      false,
-		Phase:        l.state.Phase,
-		Error:        err.Error(),
-		Duration:     time.Since(l.state.StartTime),
-		BackupPath:   l.state.BackupPath,
-		RawCount:     len(l.state.RawPrices),
-		NormalizedCount: len(l.state.Normalized),
+		Success:              false,
+		Phase:                l.state.Phase,
+		Error:                err.Error(),
+		Duration:             time.Since(l.state.StartTime),
+		BackupPath:           l.state.BackupPath,
+		ValidationReportPath: l.state.ValidationReportPath,
+		RawCount:             len(l.state.RawPrices),
+		NormalizedCount:      len(l.state.Normalized),
 	}, nil
 }
 
+// writeValidationReport persists the validation outcome next to the backup
+// once the validating phase has run. A write failure is logged rather than
+// failing the run, which may already be committed.
+func (l *Lifecycle) writeValidationReport(phase IngestionPhase, errMsg string) {
+	if l.state.Validation == nil {
+		return
+	}
+	if l.state.Coverage != nil && l.state.SnapshotID != nil {
+		l.state.Coverage.SnapshotID = *l.state.SnapshotID
+	}
+
+	path, err := l.backupMgr.WriteValidationReport(l.config.BackupDir, l.state.BackupPath, &ValidationReport{
+		Provider:    l.config.Provider,
+		Region:      l.config.Region,
+		Alias:       l.config.Alias,
+		Environment: l.config.Environment,
+		Success:     errMsg == "",
+		Phase:       phase.String(),
+		Error:       errMsg,
+		SnapshotID:  l.state.SnapshotID,
+		ContentHash: l.state.ContentHash,
+		RateCount:   len(l.state.Normalized),
+		StartedAt:   l.state.StartTime,
+		FinishedAt:  time.Now(),
+		Checks:      l.state.Checks,
+		Validation:  l.state.Validation,
+		Coverage:    l.state.Coverage,
+	})
+	if err != nil {
+		l.log().Warn("validation report not written", "error", err)
+		return
+	}
+	l.state.ValidationReportPath = path
+}
+
 // success marks the lifecycle as successful
 func (l *Lifecycle) success(msg string) (*LifecycleResult, error) {
+	attrs := []any{
+		"phase", l.state.Phase.String(),
+		"rate_count", len(l.state.Normalized),
+		"duration", time.Since(l.state.StartTime),
+	}
+	if l.state.SnapshotID != nil {
+		attrs = append(attrs, "snapshot_id", l.state.SnapshotID.String())
+	}
+	l.log().Info(msg, attrs...)
+	l.writeValidationReport(l.state.Phase, "")
+
 	return &LifecycleResult{
-		Success:         true,
-		Phase:           l.state.Phase,
-		Message:         msg,
-		Duration:        time.Since(l.state.StartTime),
-		SnapshotID:      l.state.SnapshotID,
-		BackupPath:      l.state.BackupPath,
-		ContentHash:     l.state.ContentHash,
-		RawCount:        len(l.state.RawPrices),
-		NormalizedCount: len(l.state.Normalized),
+		Success:              true,
+		Phase:                l.state.Phase,
+		Message:              msg,
+		Duration:             time.Since(l.state.StartTime),
+		SnapshotID:           l.state.SnapshotID,
+		BackupPath:           l.state.BackupPath,
+		RawPath:              l.state.RawPath,
+		ValidationReportPath: l.state.ValidationReportPath,
+		ContentHash:          l.state.ContentHash,
+		RawCount:             len(l.state.RawPrices),
+		NormalizedCount:      len(l.state.Normalized),
 	}, nil
 }
 
 // LifecycleResult is the outcome of an ingestion run
 type LifecycleResult struct {
-	Success         bool           `json:"success"`
-	Phase           IngestionPhase `json:"phase"`
-	Message         string         `json:"message,omitempty"`
-	Error           string         `json:"error,omitempty"`
-	Duration        time.Duration  `json:"duration"`
-	SnapshotID      *uuid.UUID     `json:"snapshot_id,omitempty"`
-	BackupPath      string         `json:"backup_path,omitempty"`
-	ContentHash     string         `json:"content_hash,omitempty"`
-	RawCount        int            `json:"raw_count"`
-	NormalizedCount int            `json:"normalized_count"`
+	Success              bool           `json:"success"`
+	Phase                IngestionPhase `json:"phase"`
+	Message              string         `json:"message,omitempty"`
+	Error                string         `json:"error,omitempty"`
+	Duration             time.Duration  `json:"duration"`
+	SnapshotID           *uuid.UUID     `json:"snapshot_id,omitempty"`
+	BackupPath           string         `json:"backup_path,omitempty"`
+	RawPath              string         `json:"raw_path,omitempty"`
+	ValidationReportPath string         `json:"validation_report_path,omitempty"`
+	ContentHash          string         `json:"content_hash,omitempty"`
+	RawCount             int            `json:"raw_count"`
+	NormalizedCount      int            `json:"normalized_count"`
 }
 
 // RealAPIFetcher is an interface for fetchers that can verify they use real APIs
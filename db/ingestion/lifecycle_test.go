@@ -3,8 +3,18 @@ package ingestion
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
+
+	"terraform-cost/db"
+	"terraform-cost/db/memstore"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 func TestLifecyclePhaseProgression(t *testing.T) {
@@ -153,3 +163,431 @@ func TestSnapshotStates(t *testing.T) {
 		}
 	}
 }
+
+// cancellingStore cancels the ingestion context after a number of rates are
+// written, as a timeout or SIGTERM would mid-commit
+type cancellingStore struct {
+	*memstore.MemoryStore
+	cancelAfter int
+	cancel      context.CancelFunc
+	written     int
+	rolledBack  bool
+}
+
+func (s *cancellingStore) BeginTx(ctx context.Context) (db.Tx, error) {
+	tx, err := s.MemoryStore.BeginTx(ctx)
+	return &cancellingTx{Tx: tx, store: s}, err
+}
+
+type cancellingTx struct {
+	db.Tx
+	store *cancellingStore
+}
+
+func (tx *cancellingTx) CreateRate(ctx context.Context, rate *db.PricingRate) error {
+	tx.store.written++
+	if tx.store.written == tx.store.cancelAfter {
+		tx.store.cancel()
+	}
+	return tx.Tx.CreateRate(ctx, rate)
+}
+
+func (tx *cancellingTx) Rollback() error {
+	tx.store.rolledBack = true
+	return tx.Tx.Rollback()
+}
+
+func TestLifecycleCancelMidCommitRollsBack(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	total := 3 * cancelCheckInterval
+	store := &cancellingStore{MemoryStore: memstore.NewMemoryStore(), cancelAfter: cancelCheckInterval / 2, cancel: cancel}
+	fetcher := &staticFetcher{cloud: db.AWS, prices: testRawPrices("us-east-1", total)}
+
+	config := DefaultLifecycleConfig()
+	config.Provider = db.AWS
+	config.Region = "us-east-1"
+	config.Environment = "development"
+	config.BackupDir = t.TempDir()
+
+	result, _ := NewLifecycle(fetcher, &passthroughNormalizer{cloud: db.AWS}, store).Execute(ctx, config)
+	if result.Success || result.Phase != PhaseFailed || !strings.Contains(result.Error, context.Canceled.Error()) {
+		t.Fatalf("expected a cancelled failure, got %+v", result)
+	}
+
+	if !store.rolledBack {
+		t.Error("expected the transaction to be rolled back")
+	}
+	if store.written > cancelCheckInterval {
+		t.Errorf("wrote %d of %d rates after cancellation; expected to stop at the next check", store.written, total)
+	}
+	if snapshots, _ := store.ListSnapshots(context.Background(), db.AWS, "us-east-1"); len(snapshots) != 0 {
+		t.Errorf("cancelled commit left %d snapshot(s)", len(snapshots))
+	}
+}
+
+// pollCancelledContext reports cancellation from the polls-th call to Done,
+// so a loop can be cancelled at an exact check
+type pollCancelledContext struct {
+	context.Context
+	polls, polled int
+	done          chan struct{}
+}
+
+func newPollCancelledContext(polls int) *pollCancelledContext {
+	return &pollCancelledContext{Context: context.Background(), polls: polls, done: make(chan struct{})}
+}
+
+func (c *pollCancelledContext) Done() <-chan struct{} {
+	if c.polled++; c.polled == c.polls {
+		close(c.done)
+	}
+	return c.done
+}
+
+func (c *pollCancelledContext) Err() error {
+	select {
+	case <-c.done:
+		return context.Canceled
+	default:
+		return nil
+	}
+}
+
+func TestNormalizersStopPartwayOnCancel(t *testing.T) {
+	raw := testRawPrices("us-east-1", 3*cancelCheckInterval)
+	normalizers := map[string]PriceNormalizer{
+		"aws":          NewAWSNormalizer(),
+		"aws-api":      NewAWSPricingAPINormalizer(),
+		"azure":        NewAzurePricingNormalizer(),
+		"gcp":          NewGCPPricingNormalizer(),
+		"oci":          NewOCIPricingNormalizer(),
+		"digitalocean": NewDOPricingNormalizer(),
+	}
+	for name, normalizer := range normalizers {
+		t.Run(name, func(t *testing.T) {
+			// The first check passes; the second, at rate cancelCheckInterval, stops
+			ctx := newPollCancelledContext(2)
+			rates, err := normalizer.Normalize(ctx, raw)
+			if !errors.Is(err, context.Canceled) || rates != nil {
+				t.Fatalf("expected cancellation, got %d rates (err %v)", len(rates), err)
+			}
+			if ctx.polled != 2 {
+				t.Errorf("polled %d times; expected to stop at the second check", ctx.polled)
+			}
+		})
+	}
+}
+
+func TestLifecycleDeterministicSnapshotIDs(t *testing.T) {
+	ingest := func(deterministic bool) uuid.UUID {
+		config := DefaultLifecycleConfig()
+		config.Provider = db.AWS
+		config.Region = "us-east-1"
+		config.Environment = "development"
+		config.BackupDir = t.TempDir()
+		config.DeterministicIDs = deterministic
+
+		fetcher := &staticFetcher{cloud: db.AWS, prices: testRawPrices("us-east-1", 10)}
+		result, err := NewLifecycle(fetcher, &passthroughNormalizer{cloud: db.AWS}, memstore.NewMemoryStore()).Execute(context.Background(), config)
+		if err != nil || !result.Success {
+			t.Fatalf("ingestion failed: %v %+v", err, result)
+		}
+		return *result.SnapshotID
+	}
+
+	first, second := ingest(true), ingest(true)
+	if first != second {
+		t.Errorf("identical ingestions got different snapshot IDs: %s != %s", first, second)
+	}
+	if first.Version() != 5 {
+		t.Errorf("expected a UUIDv5, got version %d", first.Version())
+	}
+	if ingest(false) == ingest(false) {
+		t.Error("expected random snapshot IDs without DeterministicIDs")
+	}
+}
+
+func TestLifecycleValidationFailureHook(t *testing.T) {
+	store := memstore.NewMemoryStore()
+	seedSnapshot(t, store, "us-east-1", 20)
+
+	var got *ValidationResult
+	config := DefaultLifecycleConfig()
+	config.Provider = db.AWS
+	config.Region = "us-east-1"
+	config.Environment = "development"
+	config.BackupDir = t.TempDir()
+	config.OnValidationFailure = func(r *ValidationResult) { got = r }
+
+	// Half the previous snapshot's rates fails the coverage check
+	lc := NewLifecycle(&staticFetcher{cloud: db.AWS, prices: testRawPrices("us-east-1", 10)}, &passthroughNormalizer{cloud: db.AWS}, store)
+	lc.validator.AddContract(IngestionContract{Cloud: db.AWS, Service: "TestStorage", MinRateCount: 15})
+
+	result, _ := lc.Execute(context.Background(), config)
+	if result.Success {
+		t.Fatal("expected validation to fail")
+	}
+	if got == nil {
+		t.Fatal("expected the validation failure hook to be called")
+	}
+	if got.IsValid || got.TotalRates != 10 {
+		t.Errorf("unexpected result: valid=%v rates=%d", got.IsValid, got.TotalRates)
+	}
+
+	failing := got.FailingServices()
+	for _, want := range []string{"TestStorage", "AmazonEC2"} {
+		found := false
+		for _, s := range failing {
+			found = found || s == want
+		}
+		if !found {
+			t.Errorf("expected %s among failing services %v", want, failing)
+		}
+	}
+	if !strings.Contains(strings.Join(got.Errors, "\n"), "coverage decreased") {
+		t.Errorf("expected the coverage error in %v", got.Errors)
+	}
+}
+
+// datedNormalizer gives every rate the same effective date
+type datedNormalizer struct {
+	passthroughNormalizer
+	effective time.Time
+}
+
+func (n *datedNormalizer) Normalize(ctx context.Context, raw []RawPrice) ([]NormalizedRate, error) {
+	rates, err := n.passthroughNormalizer.Normalize(ctx, raw)
+	for i := range rates {
+		rates[i].EffectiveDate = &n.effective
+	}
+	return rates, err
+}
+
+func TestLifecycleValidationFailureHookEveryCheck(t *testing.T) {
+	tests := []struct {
+		check      string
+		prices     []RawPrice
+		normalizer PriceNormalizer
+		configure  func(t *testing.T, store db.PricingStore, config *LifecycleConfig)
+	}{
+		{
+			check:  "region_distribution",
+			prices: testRawPrices("us-west-2", 10),
+			configure: func(t *testing.T, store db.PricingStore, config *LifecycleConfig) {
+				config.RequireRegionRates = true
+			},
+		},
+		{
+			check:      "effective_dates",
+			normalizer: &datedNormalizer{passthroughNormalizer{cloud: db.AWS}, time.Now().AddDate(1, 0, 0)},
+			configure: func(t *testing.T, store db.PricingStore, config *LifecycleConfig) {
+				config.FutureEffectiveWindow = 24 * time.Hour
+			},
+		},
+		{
+			check: "coverage_baseline",
+			configure: func(t *testing.T, store db.PricingStore, config *LifecycleConfig) {
+				config.CoverageBaselinePath = filepath.Join(t.TempDir(), DefaultCoverageBaselineFile)
+				config.BaselineTolerancePercent = 15
+				baseline := &CoverageBaseline{Regions: map[string]*RegionBaseline{
+					baselineKey(db.AWS, "us-east-1"): {Cloud: db.AWS, Region: "us-east-1", Services: map[string]int{"TestStorage": 20}},
+				}}
+				if err := baseline.Save(config.CoverageBaselinePath); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+		{
+			check:  "drift_limits",
+			prices: repriced(1, "0.2"),
+			configure: func(t *testing.T, store db.PricingStore, config *LifecycleConfig) {
+				seedSnapshot(t, store, "us-east-1", 5)
+				config.MaxSingleDriftPercent = 500
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.check, func(t *testing.T) {
+			store := memstore.NewMemoryStore()
+			config := DefaultLifecycleConfig()
+			config.Provider = db.AWS
+			config.Region = "us-east-1"
+			config.Environment = "development"
+			config.BackupDir = t.TempDir()
+			var got *ValidationResult
+			config.OnValidationFailure = func(r *ValidationResult) { got = r }
+			tt.configure(t, store, config)
+
+			prices, normalizer := tt.prices, tt.normalizer
+			if prices == nil {
+				prices = testRawPrices("us-east-1", 10)
+			}
+			if normalizer == nil {
+				normalizer = &passthroughNormalizer{cloud: db.AWS}
+			}
+			lc := NewLifecycle(&staticFetcher{cloud: db.AWS, prices: prices}, normalizer, store)
+			if result, _ := lc.Execute(context.Background(), config); result.Success {
+				t.Fatal("expected validation to fail")
+			}
+			if got == nil || got.IsValid || len(got.Errors) == 0 {
+				t.Fatalf("expected the hook to receive an invalid result, got %+v", got)
+			}
+			if last := lc.state.Checks[len(lc.state.Checks)-1]; last.Name != tt.check || last.Passed {
+				t.Errorf("expected %s to be the failing check, got %+v", tt.check, last)
+			}
+		})
+	}
+}
+
+// flakyCommitStore fails the first commits with a given error, as Postgres
+// does when concurrent ingestions conflict
+type flakyCommitStore struct {
+	*memstore.MemoryStore
+	failures  int
+	err       error
+	commits   int
+	snapshots []uuid.UUID
+}
+
+func (s *flakyCommitStore) BeginTx(ctx context.Context) (db.Tx, error) {
+	tx, err := s.MemoryStore.BeginTx(ctx)
+	return &flakyCommitTx{Tx: tx, store: s}, err
+}
+
+type flakyCommitTx struct {
+	db.Tx
+	store *flakyCommitStore
+}
+
+func (tx *flakyCommitTx) CreateSnapshot(ctx context.Context, snapshot *db.PricingSnapshot) error {
+	tx.store.snapshots = append(tx.store.snapshots, snapshot.ID)
+	return tx.Tx.CreateSnapshot(ctx, snapshot)
+}
+
+func (tx *flakyCommitTx) Commit() error {
+	tx.store.commits++
+	if tx.store.commits <= tx.store.failures {
+		return tx.store.err
+	}
+	return tx.Tx.Commit()
+}
+
+func TestLifecycleRetriesSerializationFailure(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		success bool
+		commits int
+	}{
+		{"serialization failure", &pq.Error{Code: "40001"}, true, 2},
+		{"deadlock", fmt.Errorf("wrapped: %w", &pq.Error{Code: "40P01"}), true, 2},
+		{"not retryable", &pq.Error{Code: "23505"}, false, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &flakyCommitStore{MemoryStore: memstore.NewMemoryStore(), failures: 1, err: tt.err}
+
+			config := DefaultLifecycleConfig()
+			config.Provider = db.AWS
+			config.Region = "us-east-1"
+			config.Environment = "development"
+			config.BackupDir = t.TempDir()
+			config.CommitBackoff = time.Millisecond
+
+			fetcher := &staticFetcher{cloud: db.AWS, prices: testRawPrices("us-east-1", 5)}
+			result, _ := NewLifecycle(fetcher, &passthroughNormalizer{cloud: db.AWS}, store).Execute(context.Background(), config)
+			if result.Success != tt.success {
+				t.Fatalf("success = %v, want %v (%s)", result.Success, tt.success, result.Error)
+			}
+			if store.commits != tt.commits {
+				t.Errorf("expected %d commit attempts, got %d", tt.commits, store.commits)
+			}
+			if !tt.success {
+				return
+			}
+
+			if store.snapshots[0] == store.snapshots[1] {
+				t.Error("expected a fresh snapshot ID for the retry")
+			}
+			if *result.SnapshotID != store.snapshots[1] {
+				t.Errorf("result snapshot %s is not the committed attempt %s", result.SnapshotID, store.snapshots[1])
+			}
+			if snapshots, _ := store.ListSnapshots(context.Background(), db.AWS, "us-east-1"); len(snapshots) != 1 {
+				t.Errorf("expected 1 stored snapshot, got %d", len(snapshots))
+			}
+		})
+	}
+}
+
+func TestLifecycleConcurrentRunsCommitOnce(t *testing.T) {
+	store := memstore.NewMemoryStore()
+	newConfig := func() *LifecycleConfig {
+		config := DefaultLifecycleConfig()
+		config.Provider = db.AWS
+		config.Region = "us-east-1"
+		config.Environment = "development"
+		config.BackupDir = t.TempDir()
+		return config
+	}
+	run := func(config *LifecycleConfig) *LifecycleResult {
+		fetcher := &staticFetcher{cloud: db.AWS, prices: testRawPrices("us-east-1", 5)}
+		result, _ := NewLifecycle(fetcher, &passthroughNormalizer{cloud: db.AWS}, store).Execute(context.Background(), config)
+		return result
+	}
+
+	// Another ingestion holds the lock: an aborting run gives up
+	unlock, err := store.LockIngestion(context.Background(), db.AWS, "us-east-1", "default", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := newConfig()
+	config.AbortIfCommitting = true
+	if result := run(config); result.Success || !strings.Contains(result.Error, db.ErrIngestionLocked.Error()) {
+		t.Fatalf("expected the run to abort on the held lock, got %+v", result)
+	}
+
+	// Two retried jobs wait for the lock, then commit one after the other
+	results := make(chan *LifecycleResult, 2)
+	for i := 0; i < 2; i++ {
+		go func() { results <- run(newConfig()) }()
+	}
+	time.Sleep(20 * time.Millisecond)
+	unlock()
+
+	first, second := <-results, <-results
+	if !first.Success || !second.Success {
+		t.Fatalf("expected both runs to succeed: %+v %+v", first, second)
+	}
+	if *first.SnapshotID != *second.SnapshotID {
+		t.Errorf("expected the second run to reuse the first snapshot, got %s and %s", first.SnapshotID, second.SnapshotID)
+	}
+	if snapshots, _ := store.ListSnapshots(context.Background(), db.AWS, "us-east-1"); len(snapshots) != 1 {
+		t.Errorf("expected 1 stored snapshot, got %d", len(snapshots))
+	}
+}
+
+func TestLifecycleRecordsSnapshotMetadata(t *testing.T) {
+	config := DefaultLifecycleConfig()
+	config.Provider = db.AWS
+	config.Region = "us-east-1"
+	config.Environment = "development"
+	config.BackupDir = t.TempDir()
+	config.Metadata = map[string]string{"ci_job": "1234", "git_sha": "9f3c2a1"}
+
+	store := memstore.NewMemoryStore()
+	fetcher := &staticFetcher{cloud: db.AWS, prices: testRawPrices("us-east-1", 5)}
+	result, err := NewLifecycle(fetcher, &passthroughNormalizer{cloud: db.AWS}, store).Execute(context.Background(), config)
+	if err != nil || !result.Success {
+		t.Fatalf("ingestion failed: %v %+v", err, result)
+	}
+
+	snapshot, err := store.GetActiveSnapshot(context.Background(), db.AWS, "us-east-1", "default")
+	if err != nil || snapshot == nil {
+		t.Fatalf("expected an active snapshot, got %v (err %v)", snapshot, err)
+	}
+	if snapshot.Metadata["ci_job"] != "1234" || snapshot.Metadata["git_sha"] != "9f3c2a1" {
+		t.Errorf("expected provenance metadata on the snapshot, got %v", snapshot.Metadata)
+	}
+}
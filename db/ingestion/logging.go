@@ -0,0 +1,90 @@
+// Package ingestion - Structured logging
+package ingestion
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+)
+
+// Log formats accepted by NewLogger
+const (
+	LogFormatText    = "text"    // key=value lines
+	LogFormatJSON    = "json"    // one JSON object per event
+	LogFormatConsole = "console" // human-oriented output with progress bars
+)
+
+// NewLogger builds a logger writing to w in the given format
+func NewLogger(format string, w io.Writer) (*slog.Logger, error) {
+	switch format {
+	case "", LogFormatText:
+		return slog.New(slog.NewTextHandler(w, nil)), nil
+	case LogFormatJSON:
+		return slog.New(slog.NewJSONHandler(w, nil)), nil
+	case LogFormatConsole:
+		return slog.New(NewConsoleHandler(w)), nil
+	default:
+		return nil, fmt.Errorf("unknown log format %q (expected text, json or console)", format)
+	}
+}
+
+// loggerOrDefault returns l, or a text logger on stderr when l is nil
+func loggerOrDefault(l *slog.Logger) *slog.Logger {
+	if l != nil {
+		return l
+	}
+	return slog.New(slog.NewTextHandler(os.Stderr, nil))
+}
+
+// loggerSetter is implemented by fetchers that accept a logger
+type loggerSetter interface {
+	SetLogger(logger *slog.Logger)
+}
+
+// ConsoleHandler is a text handler for interactive terminals.
+// Components that detect it may also draw progress bars to its writer.
+type ConsoleHandler struct {
+	slog.Handler
+	w io.Writer
+}
+
+// NewConsoleHandler creates a console handler writing to w
+func NewConsoleHandler(w io.Writer) *ConsoleHandler {
+	return &ConsoleHandler{
+		Handler: slog.NewTextHandler(w, &slog.HandlerOptions{
+			ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+				// Timestamps are noise on a terminal
+				if a.Key == slog.TimeKey && len(groups) == 0 {
+					return slog.Attr{}
+				}
+				return a
+			},
+		}),
+		w: w,
+	}
+}
+
+// Handle implements slog.Handler
+func (h *ConsoleHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.Handler.Handle(ctx, r)
+}
+
+// WithAttrs implements slog.Handler, preserving console mode
+func (h *ConsoleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &ConsoleHandler{Handler: h.Handler.WithAttrs(attrs), w: h.w}
+}
+
+// WithGroup implements slog.Handler, preserving console mode
+func (h *ConsoleHandler) WithGroup(name string) slog.Handler {
+	return &ConsoleHandler{Handler: h.Handler.WithGroup(name), w: h.w}
+}
+
+// consoleWriter returns the terminal writer if l uses a ConsoleHandler
+func consoleWriter(l *slog.Logger) (io.Writer, bool) {
+	if h, ok := l.Handler().(*ConsoleHandler); ok {
+		return h.w, true
+	}
+	return nil, false
+}
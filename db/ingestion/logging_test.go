@@ -0,0 +1,218 @@
+// Package ingestion - Structured logging tests
+package ingestion
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"terraform-cost/db"
+
+	"github.com/shopspring/decimal"
+)
+
+// staticFetcher returns a fixed set of raw prices
+type staticFetcher struct {
+	cloud  db.CloudProvider
+	prices []RawPrice
+}
+
+func (f *staticFetcher) Cloud() db.CloudProvider     { return f.cloud }
+func (f *staticFetcher) SupportedRegions() []string  { return nil }
+func (f *staticFetcher) SupportedServices() []string { return nil }
+func (f *staticFetcher) FetchRegion(ctx context.Context, region string) ([]RawPrice, error) {
+	return f.prices, nil
+}
+
+// passthroughNormalizer maps each raw price to one rate without interpretation
+type passthroughNormalizer struct {
+	cloud db.CloudProvider
+}
+
+func (n *passthroughNormalizer) Cloud() db.CloudProvider { return n.cloud }
+func (n *passthroughNormalizer) Normalize(ctx context.Context, raw []RawPrice) ([]NormalizedRate, error) {
+	rates := make([]NormalizedRate, 0, len(raw))
+	for _, r := range raw {
+		rates = append(rates, NormalizedRate{
+			RateKey: db.RateKey{
+				Cloud:         n.cloud,
+				Service:       r.ServiceCode,
+				ProductFamily: r.ProductFamily,
+				Region:        r.Region,
+				Attributes:    r.Attributes,
+			},
+			Unit:       r.Unit,
+			Price:      decimal.RequireFromString(r.PricePerUnit),
+			Currency:   r.Currency,
+			Confidence: 1.0,
+		})
+	}
+	return rates, nil
+}
+
+// emptyStore has no snapshots; any other call panics via the nil interface
+type emptyStore struct {
+	db.PricingStore
+}
+
+func (s *emptyStore) GetActiveSnapshot(ctx context.Context, cloud db.CloudProvider, region, alias string) (*db.PricingSnapshot, error) {
+	return nil, nil
+}
+
+// testRawPrices builds n storage prices for a region
+func testRawPrices(region string, n int) []RawPrice {
+	prices := make([]RawPrice, n)
+	for i := range prices {
+		prices[i] = RawPrice{
+			SKU:           "SKU" + string(rune('A'+i)),
+			ServiceCode:   "TestStorage",
+			ProductFamily: "Storage",
+			Region:        region,
+			Unit:          "GB-Mo",
+			PricePerUnit:  "0.023",
+			Currency:      "USD",
+			Attributes:    map[string]string{"tier": string(rune('a' + i))},
+		}
+	}
+	return prices
+}
+
+// decodeLogRecords parses JSON handler output into one map per record
+func decodeLogRecords(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var records []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var rec map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("invalid log line %q: %v", line, err)
+		}
+		records = append(records, rec)
+	}
+	return records
+}
+
+func TestLifecycleLogsPhaseAttributes(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := NewLogger(LogFormatJSON, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fetcher := &staticFetcher{cloud: db.AWS, prices: testRawPrices("us-east-1", 3)}
+	lifecycle := NewLifecycle(fetcher, &passthroughNormalizer{cloud: db.AWS}, &emptyStore{}).WithLogger(logger)
+
+	config := DefaultLifecycleConfig()
+	config.Provider = db.AWS
+	config.Region = "us-east-1"
+	config.Environment = "development"
+	config.BackupDir = t.TempDir()
+	config.DryRun = true
+
+	result, err := lifecycle.Execute(context.Background(), config)
+	if err != nil || !result.Success {
+		t.Fatalf("dry run failed: %v %+v", err, result)
+	}
+
+	phases := make(map[string]map[string]interface{})
+	for _, rec := range decodeLogRecords(t, &buf) {
+		if rec["msg"] == "phase complete" {
+			phases[rec["phase"].(string)] = rec
+		}
+	}
+
+	for _, phase := range []string{"fetching", "normalizing", "validating", "staging", "backed_up"} {
+		rec, ok := phases[phase]
+		if !ok {
+			t.Errorf("missing phase complete event for %s", phase)
+			continue
+		}
+		if rec["provider"] != "aws" || rec["region"] != "us-east-1" {
+			t.Errorf("%s: expected provider/region attributes, got %v", phase, rec)
+		}
+		if rec["rate_count"] != float64(3) {
+			t.Errorf("%s: rate_count = %v, want 3", phase, rec["rate_count"])
+		}
+		if _, ok := rec["duration"].(float64); !ok {
+			t.Errorf("%s: expected numeric duration, got %v", phase, rec["duration"])
+		}
+	}
+	if strings.Contains(buf.String(), "█") {
+		t.Error("progress bars should not be written to a JSON logger")
+	}
+}
+
+func TestLifecycleLogsFailure(t *testing.T) {
+	var buf bytes.Buffer
+	logger, _ := NewLogger(LogFormatJSON, &buf)
+
+	fetcher := &staticFetcher{cloud: db.GCP}
+	lifecycle := NewLifecycle(fetcher, &passthroughNormalizer{cloud: db.GCP}, &emptyStore{}).WithLogger(logger)
+
+	config := DefaultLifecycleConfig()
+	config.Provider = db.GCP
+	config.Region = "us-central1"
+	config.Environment = "development"
+
+	result, _ := lifecycle.Execute(context.Background(), config)
+	if result.Success {
+		t.Fatal("expected empty fetch to fail")
+	}
+
+	records := decodeLogRecords(t, &buf)
+	last := records[len(records)-1]
+	if last["level"] != "ERROR" || last["phase"] != "fetching" || last["provider"] != "gcp" {
+		t.Errorf("unexpected failure record: %v", last)
+	}
+	if !strings.Contains(last["error"].(string), "0 prices") {
+		t.Errorf("expected error attribute, got %v", last["error"])
+	}
+}
+
+func TestStreamingProgressBarOnlyInConsoleMode(t *testing.T) {
+	run := func(format string) string {
+		var buf bytes.Buffer
+		logger, err := NewLogger(format, &buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		streamCfg := DefaultStreamingConfig()
+		streamCfg.WorkDir = t.TempDir()
+		streamCfg.BatchSize = 2
+		fetcher := &staticFetcher{cloud: db.AWS, prices: testRawPrices("us-east-1", 4)}
+		sl := NewStreamingLifecycle(fetcher, &passthroughNormalizer{cloud: db.AWS}, &emptyStore{}, streamCfg).WithLogger(logger)
+
+		config := DefaultLifecycleConfig()
+		config.Provider = db.AWS
+		config.Region = "us-east-1"
+		config.BackupDir = t.TempDir()
+		config.DryRun = true
+
+		if result, _ := sl.Execute(context.Background(), config); !result.Success {
+			t.Fatalf("%s: streaming dry run failed: %s", format, result.Error)
+		}
+		return buf.String()
+	}
+
+	if out := run(LogFormatConsole); !strings.Contains(out, "█") || !strings.Contains(out, "PHASE 1/4") {
+		t.Errorf("console mode should draw banners and progress bars, got:\n%s", out)
+	}
+	out := run(LogFormatText)
+	if strings.Contains(out, "█") || strings.Contains(out, "PHASE 1/4") {
+		t.Errorf("text mode should not draw progress bars, got:\n%s", out)
+	}
+	if !strings.Contains(out, `msg="phase complete"`) || !strings.Contains(out, "rate_count=4") {
+		t.Errorf("text mode should log structured phase events, got:\n%s", out)
+	}
+}
+
+func TestNewLoggerRejectsUnknownFormat(t *testing.T) {
+	if _, err := NewLogger("xml", &bytes.Buffer{}); err == nil {
+		t.Error("expected unknown format to be rejected")
+	}
+}
@@ -0,0 +1,158 @@
+// Package ingestion - Concurrent ingestion across many regions of one provider
+package ingestion
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"terraform-cost/db"
+)
+
+// MultiRegionConfig configures a multi-region ingestion run
+type MultiRegionConfig struct {
+	// Lifecycle is the per-region template; Region is set for each run
+	Lifecycle *LifecycleConfig
+
+	// Regions to ingest
+	Regions []string
+
+	// Concurrency bounds how many regions run at once
+	Concurrency int
+
+	// DetectEquivalence groups regions whose normalized pricing is identical
+	DetectEquivalence bool
+}
+
+// DefaultMultiRegionConfig returns safe production defaults
+func DefaultMultiRegionConfig() *MultiRegionConfig {
+	return &MultiRegionConfig{
+		Lifecycle:   DefaultLifecycleConfig(),
+		Concurrency: 4,
+	}
+}
+
+// MultiRegionResult aggregates the per-region lifecycle results
+type MultiRegionResult struct {
+	Results           map[string]*LifecycleResult `json:"results"`
+	Succeeded         []string                    `json:"succeeded"`
+	Failed            []string                    `json:"failed"`
+	EquivalenceGroups []RegionGroup               `json:"equivalence_groups,omitempty"`
+	Duration          time.Duration               `json:"duration"`
+}
+
+// MultiRegionLifecycle runs the strict ingestion lifecycle for many regions
+// against one shared store. Each region commits in its own transaction, so a
+// failing region never blocks or rolls back the others.
+type MultiRegionLifecycle struct {
+	fetcher    PriceFetcher
+	normalizer PriceNormalizer
+	store      db.PricingStore
+	logger     *slog.Logger
+}
+
+// NewMultiRegionLifecycle creates a multi-region ingestion orchestrator
+func NewMultiRegionLifecycle(fetcher PriceFetcher, normalizer PriceNormalizer, store db.PricingStore) *MultiRegionLifecycle {
+	return &MultiRegionLifecycle{
+		fetcher:    fetcher,
+		normalizer: normalizer,
+		store:      store,
+	}
+}
+
+// WithLogger sets the structured logger shared by every region's lifecycle
+func (m *MultiRegionLifecycle) WithLogger(logger *slog.Logger) *MultiRegionLifecycle {
+	m.logger = logger
+	if ls, ok := m.fetcher.(loggerSetter); ok {
+		ls.SetLogger(logger)
+	}
+	return m
+}
+
+// Execute ingests every configured region with bounded concurrency.
+// Region failures are recorded in the result rather than returned as errors.
+func (m *MultiRegionLifecycle) Execute(ctx context.Context, config *MultiRegionConfig) (*MultiRegionResult, error) {
+	if config == nil {
+		config = DefaultMultiRegionConfig()
+	}
+	if len(config.Regions) == 0 {
+		return nil, fmt.Errorf("no regions to ingest")
+	}
+	template := config.Lifecycle
+	if template == nil {
+		template = DefaultLifecycleConfig()
+	}
+	concurrency := config.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	start := time.Now()
+	result := &MultiRegionResult{
+		Results: make(map[string]*LifecycleResult, len(config.Regions)),
+	}
+
+	var mu sync.Mutex
+	var detector *EquivalenceDetector
+	if config.DetectEquivalence {
+		detector = NewEquivalenceDetector(template.Provider)
+	}
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for _, region := range config.Regions {
+		wg.Add(1)
+		go func(region string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			regionConfig := *template
+			regionConfig.Region = region
+			regionConfig.RequireRegionRates = true
+
+			// The fetcher's logger was set once in WithLogger, so set the field directly
+			lifecycle := NewLifecycle(m.fetcher, m.normalizer, m.store)
+			lifecycle.logger = m.logger
+
+			res, err := lifecycle.Execute(ctx, &regionConfig)
+			if err != nil {
+				res = &LifecycleResult{Phase: PhaseFailed, Error: err.Error()}
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			result.Results[region] = res
+			if res.Success && detector != nil {
+				detector.AddRegionRates(region, lifecycle.state.Normalized)
+			}
+		}(region)
+	}
+	wg.Wait()
+
+	for region, res := range result.Results {
+		if res.Success {
+			result.Succeeded = append(result.Succeeded, region)
+		} else {
+			result.Failed = append(result.Failed, region)
+		}
+	}
+	sort.Strings(result.Succeeded)
+	sort.Strings(result.Failed)
+
+	if detector != nil {
+		result.EquivalenceGroups = detector.DetectEquivalence()
+	}
+	result.Duration = time.Since(start)
+
+	loggerOrDefault(m.logger).Info("multi-region ingestion complete",
+		"provider", string(template.Provider),
+		"succeeded", len(result.Succeeded),
+		"failed", len(result.Failed),
+		"duration", result.Duration)
+
+	return result, nil
+}
@@ -0,0 +1,96 @@
+// Package ingestion - Multi-region ingestion tests
+package ingestion
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"terraform-cost/db"
+	"terraform-cost/db/memstore"
+)
+
+// regionFetcher serves fixed prices per region and fails for unknown regions
+type regionFetcher struct {
+	prices map[string][]RawPrice
+}
+
+func (f *regionFetcher) Cloud() db.CloudProvider     { return db.AWS }
+func (f *regionFetcher) SupportedRegions() []string  { return nil }
+func (f *regionFetcher) SupportedServices() []string { return nil }
+func (f *regionFetcher) FetchRegion(ctx context.Context, region string) ([]RawPrice, error) {
+	prices, ok := f.prices[region]
+	if !ok {
+		return nil, fmt.Errorf("region %s unavailable", region)
+	}
+	return prices, nil
+}
+
+func TestMultiRegionLifecycleIsolatesFailures(t *testing.T) {
+	fetcher := &regionFetcher{prices: map[string][]RawPrice{
+		"us-east-1": testRawPrices("us-east-1", 3),
+		"us-west-2": testRawPrices("us-west-2", 3),
+	}}
+	store := memstore.NewMemoryStore()
+
+	config := DefaultMultiRegionConfig()
+	config.Lifecycle.Provider = db.AWS
+	config.Lifecycle.Environment = "development"
+	config.Lifecycle.BackupDir = t.TempDir()
+	config.Regions = []string{"us-east-1", "us-west-2", "eu-west-1"}
+	config.Concurrency = 2
+	config.DetectEquivalence = true
+
+	result, err := NewMultiRegionLifecycle(fetcher, &passthroughNormalizer{cloud: db.AWS}, store).
+		Execute(context.Background(), config)
+	if err != nil {
+		t.Fatalf("execute failed: %v", err)
+	}
+
+	if len(result.Results) != 3 {
+		t.Fatalf("expected a result per region, got %d", len(result.Results))
+	}
+	if fmt.Sprint(result.Succeeded) != "[us-east-1 us-west-2]" || fmt.Sprint(result.Failed) != "[eu-west-1]" {
+		t.Errorf("succeeded=%v failed=%v", result.Succeeded, result.Failed)
+	}
+	if res := result.Results["eu-west-1"]; res.Success || res.Phase != PhaseFailed {
+		t.Errorf("expected eu-west-1 to fail, got %+v", res)
+	}
+
+	for _, region := range result.Succeeded {
+		active, _ := store.GetActiveSnapshot(context.Background(), db.AWS, region, "default")
+		if active == nil || *result.Results[region].SnapshotID != active.ID {
+			t.Errorf("%s: expected committed active snapshot, got %+v", region, active)
+		}
+	}
+
+	// The two healthy regions serve identical prices
+	if len(result.EquivalenceGroups) != 1 || result.EquivalenceGroups[0].CanonicalRegion != "us-east-1" {
+		t.Errorf("unexpected equivalence groups: %+v", result.EquivalenceGroups)
+	}
+}
+
+func TestMultiRegionLifecycleRejectsCollapsedRegions(t *testing.T) {
+	// A fetcher bug that serves us-east-1's prices for every region
+	prices := testRawPrices("us-east-1", 3)
+	fetcher := &regionFetcher{prices: map[string][]RawPrice{"us-east-1": prices, "us-west-2": prices}}
+	store := memstore.NewMemoryStore()
+
+	config := DefaultMultiRegionConfig()
+	config.Lifecycle.Provider = db.AWS
+	config.Lifecycle.Environment = "development"
+	config.Lifecycle.BackupDir = t.TempDir()
+	config.Regions = []string{"us-east-1", "us-west-2"}
+
+	result, err := NewMultiRegionLifecycle(fetcher, &passthroughNormalizer{cloud: db.AWS}, store).
+		Execute(context.Background(), config)
+	if err != nil {
+		t.Fatalf("execute failed: %v", err)
+	}
+	if fmt.Sprint(result.Succeeded) != "[us-east-1]" || fmt.Sprint(result.Failed) != "[us-west-2]" {
+		t.Errorf("succeeded=%v failed=%v", result.Succeeded, result.Failed)
+	}
+	if active, _ := store.GetActiveSnapshot(context.Background(), db.AWS, "us-west-2", "default"); active != nil {
+		t.Errorf("expected no us-west-2 snapshot, got %s", active.ID)
+	}
+}
@@ -0,0 +1,391 @@
+// Package ingestion - Production Oracle Cloud (OCI) price list API client
+// OCI list prices are global, so every region receives the same catalog
+package ingestion
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"terraform-cost/db"
+
+	"github.com/shopspring/decimal"
+)
+
+// OCI service names used as RateKey.Service
+const (
+	ociComputeService      = "Compute"
+	ociBlockStorageService = "Block Storage"
+)
+
+// ociPayAsYouGo is the only price model ingested; commitment models are skipped
+const ociPayAsYouGo = "PAY_AS_YOU_GO"
+
+// OCIPricingAPIFetcher fetches pricing from the OCI price list API
+type OCIPricingAPIFetcher struct {
+	httpClient   *http.Client
+	baseURL      string
+	currency     string
+	servicesList []string
+	logger       *slog.Logger
+}
+
+// OCIPricingConfig configures the OCI pricing client
+type OCIPricingConfig struct {
+	// HTTPTimeout for API calls
+	HTTPTimeout time.Duration
+
+	// HTTPClient, when set, is used for API calls instead of a client
+	// built from HTTPTimeout, so fetchers can share one configured client
+	HTTPClient *http.Client
+
+	// Currency of the returned prices
+	Currency string
+
+	// Services to fetch
+	Services []string
+}
+
+// DefaultOCIPricingConfig returns production defaults
+func DefaultOCIPricingConfig() *OCIPricingConfig {
+	return &OCIPricingConfig{
+		HTTPTimeout: 2 * time.Minute,
+		Currency:    "USD",
+		Services:    AllOCIServices(),
+	}
+}
+
+// AllOCIServices returns the OCI services with pricing support
+func AllOCIServices() []string {
+	return []string{
+		ociComputeService,
+		ociBlockStorageService,
+	}
+}
+
+// NewOCIPricingAPIFetcher creates a production OCI pricing client
+func NewOCIPricingAPIFetcher(cfg *OCIPricingConfig) *OCIPricingAPIFetcher {
+	if cfg == nil {
+		cfg = DefaultOCIPricingConfig()
+	}
+
+	return &OCIPricingAPIFetcher{
+		httpClient:   httpClientOrDefault(cfg.HTTPClient, cfg.HTTPTimeout),
+		baseURL:      "https://apexapps.oracle.com/pls/apex/cetools/api/v1/products/",
+		currency:     cfg.Currency,
+		servicesList: cfg.Services,
+	}
+}
+
+// Cloud implements PriceFetcher
+func (f *OCIPricingAPIFetcher) Cloud() db.CloudProvider {
+	return db.OCI
+}
+
+// IsRealAPI implements RealAPIFetcher - THIS IS A REAL API
+func (f *OCIPricingAPIFetcher) IsRealAPI() bool {
+	return true
+}
+
+// SupportedRegions returns all OCI commercial regions
+func (f *OCIPricingAPIFetcher) SupportedRegions() []string {
+	return []string{
+		// Americas
+		"us-ashburn-1", "us-phoenix-1", "us-sanjose-1", "us-chicago-1",
+		"ca-toronto-1", "ca-montreal-1",
+		"sa-saopaulo-1", "sa-vinhedo-1", "sa-santiago-1", "sa-bogota-1", "sa-valparaiso-1",
+		"mx-queretaro-1", "mx-monterrey-1",
+
+		// Europe
+		"uk-london-1", "uk-cardiff-1",
+		"eu-frankfurt-1", "eu-amsterdam-1", "eu-zurich-1",
+		"eu-madrid-1", "eu-marseille-1", "eu-milan-1", "eu-paris-1",
+		"eu-stockholm-1",
+
+		// Asia Pacific
+		"ap-tokyo-1", "ap-osaka-1", "ap-seoul-1", "ap-chuncheon-1",
+		"ap-mumbai-1", "ap-hyderabad-1", "ap-singapore-1", "ap-singapore-2",
+		"ap-sydney-1", "ap-melbourne-1",
+
+		// Middle East & Africa
+		"me-jeddah-1", "me-dubai-1", "me-abudhabi-1", "me-riyadh-1",
+		"il-jerusalem-1", "af-johannesburg-1",
+	}
+}
+
+// SupportedServices returns all supported services
+func (f *OCIPricingAPIFetcher) SupportedServices() []string {
+	return f.servicesList
+}
+
+// SetAllowedServices restricts fetching to the given services
+func (f *OCIPricingAPIFetcher) SetAllowedServices(services []string) {
+	if len(services) > 0 {
+		f.servicesList = services
+	}
+}
+
+// SetLogger sets the logger used for fetch warnings
+func (f *OCIPricingAPIFetcher) SetLogger(logger *slog.Logger) {
+	f.logger = logger
+}
+
+// SetTransport routes API calls through rt (see TransportConfig), keeping
+// the client's timeout
+func (f *OCIPricingAPIFetcher) SetTransport(rt http.RoundTripper) {
+	client := *f.httpClient
+	client.Transport = rt
+	f.httpClient = &client
+}
+
+// FetchRegion fetches the OCI price list and stamps it with the region.
+// OCI publishes a single global list, so the region only labels the rates.
+func (f *OCIPricingAPIFetcher) FetchRegion(ctx context.Context, region string) ([]RawPrice, error) {
+	params := url.Values{}
+	params.Set("currencyCode", f.currency)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", f.baseURL+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := f.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch OCI pricing: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("OCI API returned status %d", resp.StatusCode)
+	}
+
+	var response OCIPricingResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	prices := f.convertItems(response.Items, region)
+	loggerOrDefault(f.logger).Debug("fetched price list", "provider", "oci", "region", region, "rate_count", len(prices))
+
+	if len(prices) == 0 {
+		return nil, fmt.Errorf("failed to fetch any pricing for OCI region %s", region)
+	}
+
+	return prices, nil
+}
+
+// convertItems turns price list items into raw prices for the allowed services
+func (f *OCIPricingAPIFetcher) convertItems(items []OCIPriceItem, region string) []RawPrice {
+	allowed := make(map[string]bool, len(f.servicesList))
+	for _, s := range f.servicesList {
+		allowed[s] = true
+	}
+
+	var prices []RawPrice
+	for _, item := range items {
+		service := ociServiceFor(item.ServiceCategory)
+		if !allowed[service] {
+			continue
+		}
+
+		for _, loc := range item.CurrencyCodeLocalizations {
+			if loc.CurrencyCode != f.currency {
+				continue
+			}
+			for _, p := range loc.Prices {
+				// Skip commitment pricing and free allowances
+				if p.Model != ociPayAsYouGo || p.Value == 0 {
+					continue
+				}
+
+				price := RawPrice{
+					SKU:           item.PartNumber,
+					ServiceCode:   service,
+					ProductFamily: item.ServiceCategory,
+					Region:        region,
+					Unit:          item.MetricName,
+					PricePerUnit:  fmt.Sprintf("%.10f", p.Value),
+					Currency:      loc.CurrencyCode,
+					Attributes: map[string]string{
+						"partNumber":      item.PartNumber,
+						"displayName":     item.DisplayName,
+						"metricName":      item.MetricName,
+						"serviceCategory": item.ServiceCategory,
+					},
+				}
+
+				// Handle tiered pricing
+				if p.RangeMin != nil && *p.RangeMin > 0 {
+					tierStart := decimal.NewFromFloat(*p.RangeMin)
+					price.TierStart = &tierStart
+				}
+				if p.RangeMax != nil {
+					tierEnd := decimal.NewFromFloat(*p.RangeMax)
+					price.TierEnd = &tierEnd
+				}
+
+				prices = append(prices, price)
+			}
+		}
+	}
+
+	return prices
+}
+
+// ociServiceFor maps an OCI service category onto a canonical service name
+func ociServiceFor(category string) string {
+	lower := strings.ToLower(category)
+	switch {
+	case strings.HasPrefix(lower, "compute"):
+		return ociComputeService
+	case strings.Contains(lower, "block volume"), strings.Contains(lower, "block storage"):
+		return ociBlockStorageService
+	default:
+		return category
+	}
+}
+
+// OCIPricingResponse represents the OCI price list API response
+type OCIPricingResponse struct {
+	LastUpdated string         `json:"lastUpdated"`
+	Items       []OCIPriceItem `json:"items"`
+}
+
+// OCIPriceItem represents a single OCI product
+type OCIPriceItem struct {
+	PartNumber                string                    `json:"partNumber"`
+	DisplayName               string                    `json:"displayName"`
+	MetricName                string                    `json:"metricName"`
+	ServiceCategory           string                    `json:"serviceCategory"`
+	CurrencyCodeLocalizations []OCICurrencyLocalization `json:"currencyCodeLocalizations"`
+}
+
+// OCICurrencyLocalization holds an item's prices in one currency
+type OCICurrencyLocalization struct {
+	CurrencyCode string     `json:"currencyCode"`
+	Prices       []OCIPrice `json:"prices"`
+}
+
+// OCIPrice is one price point; ranges mark tiered pricing
+type OCIPrice struct {
+	Model    string   `json:"model"`
+	Value    float64  `json:"value"`
+	RangeMin *float64 `json:"rangeMin,omitempty"`
+	RangeMax *float64 `json:"rangeMax,omitempty"`
+}
+
+// OCIPricingNormalizer normalizes raw OCI pricing to canonical format
+type OCIPricingNormalizer struct{}
+
+// NewOCIPricingNormalizer creates a production normalizer
+func NewOCIPricingNormalizer() *OCIPricingNormalizer {
+	return &OCIPricingNormalizer{}
+}
+
+// Cloud implements PriceNormalizer
+func (n *OCIPricingNormalizer) Cloud() db.CloudProvider {
+	return db.OCI
+}
+
+// Normalize converts raw OCI prices to normalized rates
+func (n *OCIPricingNormalizer) Normalize(ctx context.Context, raw []RawPrice) ([]NormalizedRate, error) {
+	var rates []NormalizedRate
+
+	for i, r := range raw {
+		if err := checkCancelled(ctx, i); err != nil {
+			return nil, err
+		}
+		price, err := ParsePrice(r.PricePerUnit)
+		if err != nil {
+			continue
+		}
+
+		rateKey := db.RateKey{
+			Cloud:         db.OCI,
+			Service:       r.ServiceCode,
+			ProductFamily: r.ProductFamily,
+			Region:        r.Region,
+			Attributes:    n.normalizeAttributes(r.Attributes),
+		}
+
+		nr := NormalizedRate{
+			RateKey:    rateKey,
+			Unit:       n.normalizeUnit(r.Unit),
+			Price:      price,
+			Currency:   r.Currency,
+			Confidence: 1.0,
+
+			EffectiveDate: r.EffectiveDate,
+			Labels:        sourceLabels(r),
+		}
+
+		nr.TierMin = r.TierStart
+		nr.TierMax = r.TierEnd
+
+		rates = append(rates, nr)
+	}
+
+	return rates, nil
+}
+
+// normalizeAttributes converts OCI attributes to canonical form.
+// Display names look like "Compute - Standard - E4 - OCPU": the last segment
+// is the billed resource and the middle segments name the shape series.
+func (n *OCIPricingNormalizer) normalizeAttributes(raw map[string]string) map[string]string {
+	result := make(map[string]string)
+
+	mapping := map[string]string{
+		"partNumber":      "part_number",
+		"displayName":     "display_name",
+		"metricName":      "metric_name",
+		"serviceCategory": "service_category",
+	}
+
+	for k, v := range raw {
+		if v == "" {
+			continue
+		}
+		if canonical, ok := mapping[k]; ok {
+			result[canonical] = strings.ToLower(v)
+		} else {
+			result[toSnakeCase(k)] = strings.ToLower(v)
+		}
+	}
+
+	parts := strings.Split(raw["displayName"], " - ")
+	if len(parts) >= 2 {
+		result["resource"] = strings.ToLower(strings.TrimSpace(parts[len(parts)-1]))
+	}
+	if len(parts) >= 3 {
+		series := make([]string, 0, len(parts)-2)
+		for _, p := range parts[1 : len(parts)-1] {
+			series = append(series, strings.ToLower(strings.TrimSpace(p)))
+		}
+		result["shape_series"] = strings.Join(series, ".")
+	}
+
+	return result
+}
+
+// normalizeUnit converts OCI metric names to canonical form
+func (n *OCIPricingNormalizer) normalizeUnit(unit string) string {
+	mapping := map[string]string{
+		"OCPU Per Hour":                            "OCPU-hours",
+		"Gigabyte Per Hour":                        "GB-hours",
+		"Gigabyte Storage Capacity Per Month":      "GB-month",
+		"Performance Units Per Gigabyte Per Month": "VPU-GB-month",
+		"Node Per Hour":                            "hours",
+		"GPU Per Hour":                             "GPU-hours",
+	}
+
+	if normalized, ok := mapping[unit]; ok {
+		return normalized
+	}
+
+	return strings.ToLower(strings.ReplaceAll(unit, " ", "-"))
+}
@@ -0,0 +1,97 @@
+// Package ingestion - OCI fetcher and normalizer tests
+package ingestion
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"terraform-cost/db"
+)
+
+// ociFixtureServer serves the OCI price list fixture
+func ociFixtureServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	body, err := os.ReadFile("testdata/oci_prices.json")
+	if err != nil {
+		t.Fatalf("failed to read fixture: %v", err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("currencyCode"); got != "USD" {
+			t.Errorf("currencyCode = %q, want USD", got)
+		}
+		w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestOCINormalizeFixture(t *testing.T) {
+	fetcher := NewOCIPricingAPIFetcher(nil)
+	fetcher.baseURL = ociFixtureServer(t).URL
+
+	raw, err := fetcher.FetchRegion(context.Background(), "us-ashburn-1")
+	if err != nil {
+		t.Fatalf("fetch failed: %v", err)
+	}
+
+	// Free tier and the non-Compute/Block Storage item are dropped, as is EUR pricing
+	if len(raw) != 4 {
+		t.Fatalf("expected 4 raw prices, got %d", len(raw))
+	}
+
+	rates, err := NewOCIPricingNormalizer().Normalize(context.Background(), raw)
+	if err != nil {
+		t.Fatalf("normalize failed: %v", err)
+	}
+
+	byPart := make(map[string]NormalizedRate)
+	for _, r := range rates {
+		if r.RateKey.Cloud != db.OCI || r.RateKey.Region != "us-ashburn-1" || r.Currency != "USD" {
+			t.Errorf("unexpected rate key: %+v", r.RateKey)
+		}
+		byPart[r.RateKey.Attributes["part_number"]] = r
+	}
+
+	ocpu := byPart["b93113"]
+	if ocpu.RateKey.Service != "Compute" || ocpu.Unit != "OCPU-hours" || ocpu.Price.String() != "0.025" {
+		t.Errorf("unexpected OCPU rate: service=%s unit=%s price=%s", ocpu.RateKey.Service, ocpu.Unit, ocpu.Price)
+	}
+	if ocpu.RateKey.Attributes["shape_series"] != "standard.e4" || ocpu.RateKey.Attributes["resource"] != "ocpu" {
+		t.Errorf("unexpected OCPU attributes: %v", ocpu.RateKey.Attributes)
+	}
+
+	if mem := byPart["b93114"]; mem.Unit != "GB-hours" || mem.RateKey.Attributes["resource"] != "memory" {
+		t.Errorf("unexpected memory rate: unit=%s attrs=%v", mem.Unit, mem.RateKey.Attributes)
+	}
+
+	storage := byPart["b91961"]
+	if storage.RateKey.Service != "Block Storage" || storage.Unit != "GB-month" {
+		t.Errorf("unexpected block storage rate: service=%s unit=%s", storage.RateKey.Service, storage.Unit)
+	}
+
+	vpu := byPart["b91962"]
+	if vpu.Unit != "VPU-GB-month" || vpu.TierMin == nil || vpu.TierMin.String() != "10" || vpu.TierMax != nil {
+		t.Errorf("expected VPU tier starting at 10, got unit=%s min=%v max=%v", vpu.Unit, vpu.TierMin, vpu.TierMax)
+	}
+}
+
+func TestOCIRegisteredByDefault(t *testing.T) {
+	registry := NewFetcherRegistry()
+	registry.RegisterDefaults()
+
+	fetcher, err := registry.GetFetcher(db.OCI)
+	if err != nil {
+		t.Fatalf("expected OCI fetcher: %v", err)
+	}
+	if fetcher.Cloud() != db.OCI || !registry.IsRealAPI(db.OCI) {
+		t.Error("expected OCI fetcher to be the real API client")
+	}
+
+	normalizer, err := registry.GetNormalizer(db.OCI)
+	if err != nil || normalizer.Cloud() != db.OCI {
+		t.Errorf("expected OCI normalizer, got %v (%v)", normalizer, err)
+	}
+}
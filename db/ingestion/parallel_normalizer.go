@@ -0,0 +1,80 @@
+// Package ingestion - Parallel normalization across worker goroutines
+package ingestion
+
+import (
+	"context"
+	"runtime"
+	"sync"
+
+	"terraform-cost/db"
+)
+
+// minParallelChunk is the smallest chunk worth handing to a worker; smaller
+// inputs are normalized on the calling goroutine
+const minParallelChunk = 1000
+
+// ParallelNormalizer splits raw prices into contiguous chunks, normalizes
+// them on worker goroutines and concatenates the results in input order, so
+// the output (and its content hash) matches a serial run.
+//
+// The inner normalizer must treat each price independently and be safe for
+// concurrent use. Wrap it before normalizers that look across prices, such
+// as FilteredNormalizer's duplicate removal.
+type ParallelNormalizer struct {
+	inner   PriceNormalizer
+	workers int
+}
+
+// NewParallelNormalizer creates a normalizer running on workers goroutines
+// (GOMAXPROCS when workers <= 0)
+func NewParallelNormalizer(inner PriceNormalizer, workers int) *ParallelNormalizer {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	return &ParallelNormalizer{inner: inner, workers: workers}
+}
+
+func (n *ParallelNormalizer) Cloud() db.CloudProvider {
+	return n.inner.Cloud()
+}
+
+// Normalize normalizes the chunks concurrently. The first failing chunk's
+// error, in input order, is returned.
+func (n *ParallelNormalizer) Normalize(ctx context.Context, raw []RawPrice) ([]NormalizedRate, error) {
+	workers := n.workers
+	if max := len(raw) / minParallelChunk; workers > max {
+		workers = max
+	}
+	if workers <= 1 {
+		return n.inner.Normalize(ctx, raw)
+	}
+
+	chunkSize := (len(raw) + workers - 1) / workers
+	results := make([][]NormalizedRate, workers)
+	errs := make([]error, workers)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		start := i * chunkSize
+		end := min(start+chunkSize, len(raw))
+		wg.Add(1)
+		go func(i int, chunk []RawPrice) {
+			defer wg.Done()
+			results[i], errs[i] = n.inner.Normalize(ctx, chunk)
+		}(i, raw[start:end])
+	}
+	wg.Wait()
+
+	total := 0
+	for i, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+		total += len(results[i])
+	}
+	rates := make([]NormalizedRate, 0, total)
+	for _, chunk := range results {
+		rates = append(rates, chunk...)
+	}
+	return rates, nil
+}
@@ -0,0 +1,71 @@
+// Package ingestion - Parallel normalization tests
+package ingestion
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// ec2RawPrices builds n distinct on-demand EC2 prices in us-east-1
+func ec2RawPrices(n int) []RawPrice {
+	prices := make([]RawPrice, n)
+	for i := range prices {
+		prices[i] = RawPrice{
+			SKU:           fmt.Sprintf("SKU%06d", i),
+			ServiceCode:   "AmazonEC2",
+			ProductFamily: "Compute Instance",
+			Region:        "us-east-1",
+			Unit:          "Hrs",
+			PricePerUnit:  fmt.Sprintf("0.%04d", i%10000+1),
+			Currency:      "USD",
+			Attributes: map[string]string{
+				"instanceType":    fmt.Sprintf("m%d.size%d", i%7, i),
+				"operatingSystem": []string{"Linux", "Windows", "RHEL"}[i%3],
+				"tenancy":         "Shared",
+			},
+		}
+	}
+	return prices
+}
+
+func TestParallelNormalizerMatchesSerial(t *testing.T) {
+	raw := ec2RawPrices(10*minParallelChunk + 37)
+	serial, err := NewAWSPricingAPINormalizer().Normalize(context.Background(), raw)
+	if err != nil || len(serial) == 0 {
+		t.Fatalf("serial normalization failed: %v", err)
+	}
+
+	for _, workers := range []int{0, 1, 3, 8} {
+		parallel, err := NewParallelNormalizer(NewAWSPricingAPINormalizer(), workers).Normalize(context.Background(), raw)
+		if err != nil {
+			t.Fatalf("workers=%d: %v", workers, err)
+		}
+		if !reflect.DeepEqual(parallel, serial) {
+			t.Fatalf("workers=%d: parallel output differs from serial (%d vs %d rates)", workers, len(parallel), len(serial))
+		}
+		if calculateHash(parallel) != calculateHash(serial) {
+			t.Errorf("workers=%d: content hash differs", workers)
+		}
+	}
+}
+
+func BenchmarkNormalize(b *testing.B) {
+	raw := ec2RawPrices(100000)
+	for _, bench := range []struct {
+		name       string
+		normalizer PriceNormalizer
+	}{
+		{"serial", NewAWSPricingAPINormalizer()},
+		{"parallel", NewParallelNormalizer(NewAWSPricingAPINormalizer(), 0)},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := bench.normalizer.Normalize(context.Background(), raw); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
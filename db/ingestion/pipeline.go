@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"terraform-cost/db"
+	"terraform-cost/db/regions"
 
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
@@ -49,18 +50,35 @@ type PipelineConfig struct {
 	// MinCoveragePercent is the minimum required coverage (default 95%)
 	MinCoveragePercent float64
 
+	// MinServicesFraction is the share of services that must fetch without
+	// error for a partial fetch to proceed (default 80%)
+	MinServicesFraction float64
+
+	// DistinctKeyCoverage compares coverage by distinct rate keys instead of
+	// rows, so tiered rates do not inflate or deflate it
+	DistinctKeyCoverage bool
+
 	// Timeout for the entire pipeline
 	Timeout time.Duration
+
+	// DeterministicIDs derives the snapshot ID from (cloud, region, alias,
+	// content hash) so identical ingestions yield the same ID
+	DeterministicIDs bool
+
+	// AllowRestrictedRegions permits GovCloud/China regions, which need a
+	// fetcher for their own pricing source
+	AllowRestrictedRegions bool
 }
 
 // DefaultPipelineConfig returns production defaults
 func DefaultPipelineConfig() *PipelineConfig {
 	return &PipelineConfig{
-		Alias:              "default",
-		DryRun:             false,
-		BackupDir:          "./pricing-backups",
-		MinCoveragePercent: 95.0, // Very high coverage required
-		Timeout:            30 * time.Minute,
+		Alias:               "default",
+		DryRun:              false,
+		BackupDir:           "./pricing-backups",
+		MinCoveragePercent:  95.0, // Very high coverage required
+		MinServicesFraction: DefaultMinServicesFraction,
+		Timeout:             30 * time.Minute,
 	}
 }
 
@@ -110,9 +128,26 @@ type RawPrice struct {
 	PricePerUnit  string            `json:"price_per_unit"`
 	Currency      string            `json:"currency"`
 	Attributes    map[string]string `json:"attributes"`
-	TierStart     *float64          `json:"tier_start,omitempty"`
-	TierEnd       *float64          `json:"tier_end,omitempty"`
+	TierStart     *decimal.Decimal  `json:"tier_start,omitempty"`
+	TierEnd       *decimal.Decimal  `json:"tier_end,omitempty"`
 	EffectiveDate *time.Time        `json:"effective_date,omitempty"`
+	RateCode      string            `json:"rate_code,omitempty"` // AWS price dimension rate code
+}
+
+// sourceLabels returns the source identifiers of a raw price, stored as
+// rate labels for tracing a rate back to the provider's catalog
+func sourceLabels(r RawPrice) map[string]string {
+	labels := make(map[string]string, 2)
+	if r.SKU != "" {
+		labels["sku"] = r.SKU
+	}
+	if r.RateCode != "" {
+		labels["rate_code"] = r.RateCode
+	}
+	if len(labels) == 0 {
+		return nil
+	}
+	return labels
 }
 
 // NormalizedRate is the output of normalization
@@ -124,6 +159,13 @@ type NormalizedRate struct {
 	Confidence float64         `json:"confidence"`
 	TierMin    *decimal.Decimal `json:"tier_min,omitempty"`
 	TierMax    *decimal.Decimal `json:"tier_max,omitempty"`
+
+	// EffectiveDate is when the price takes effect (nil if unknown)
+	EffectiveDate *time.Time `json:"effective_date,omitempty"`
+
+	// Labels carry source identifiers (SKU, rate code) for traceability.
+	// Unlike RateKey attributes they play no part in matching or the hash.
+	Labels map[string]string `json:"labels,omitempty"`
 }
 
 // PriceFetcher fetches raw prices from a cloud API
@@ -141,13 +183,53 @@ type PriceFetcher interface {
 	SupportedServices() []string
 }
 
+// StreamingPriceFetcher is a PriceFetcher that can emit prices as they are
+// decoded, so StreamingLifecycle never holds a region's full price list
+type StreamingPriceFetcher interface {
+	PriceFetcher
+
+	// StreamRegion calls emit for every price of a region (NO DB WRITES);
+	// an emit error aborts the fetch
+	StreamRegion(ctx context.Context, region string, emit func(RawPrice) error) error
+}
+
+// CatalogSizeEstimator is implemented by fetchers that know roughly how many
+// prices a region has before fetching it, for progress reporting and batch
+// planning
+type CatalogSizeEstimator interface {
+	// EstimatedRateCount returns the approximate number of prices in the
+	// region, or -1 when it cannot be estimated
+	EstimatedRateCount(region string) int
+}
+
+// estimatedRateCount asks fetcher for an estimate, returning -1 when it
+// cannot give one
+func estimatedRateCount(fetcher PriceFetcher, region string) int {
+	if e, ok := fetcher.(CatalogSizeEstimator); ok {
+		return e.EstimatedRateCount(region)
+	}
+	return -1
+}
+
+// ChangedSinceFetcher is implemented by fetchers whose source marks changed
+// prices, so a daily update can fetch only what changed instead of the
+// whole catalog (see Lifecycle.ExecuteIncremental)
+type ChangedSinceFetcher interface {
+	PriceFetcher
+
+	// FetchChangedSince returns the prices of a region changed after since
+	// (NO DB WRITES). ok is false when an incremental fetch is not possible,
+	// and the caller fetches the whole region instead.
+	FetchChangedSince(ctx context.Context, region string, since time.Time) (prices []RawPrice, ok bool, err error)
+}
+
 // PriceNormalizer converts raw prices to normalized rates
 type PriceNormalizer interface {
 	// Cloud returns the cloud provider
 	Cloud() db.CloudProvider
 
 	// Normalize converts raw prices to normalized rates
-	Normalize(raw []RawPrice) ([]NormalizedRate, error)
+	Normalize(ctx context.Context, raw []RawPrice) ([]NormalizedRate, error)
 }
 
 // Pipeline orchestrates the full ingestion flow with 5 strict phases
@@ -274,7 +356,12 @@ func (p *Pipeline) Execute(ctx context.Context, config *PipelineConfig) (*Pipeli
 
 // phaseFetch downloads raw pricing (NO DB WRITES)
 func (p *Pipeline) phaseFetch(ctx context.Context, config *PipelineConfig) ([]RawPrice, error) {
+	if err := ValidateRegion(regions.NewRegistry(), config.Provider, config.Region, config.AllowRestrictedRegions); err != nil {
+		return nil, err
+	}
+
 	rawPrices, err := p.fetcher.FetchRegion(ctx, config.Region)
+	rawPrices, _, err = applyMinServices(rawPrices, err, config.MinServicesFraction)
 	if err != nil {
 		return nil, fmt.Errorf("fetch failed for %s/%s: %w", config.Provider, config.Region, err)
 	}
@@ -288,15 +375,23 @@ func (p *Pipeline) phaseFetch(ctx context.Context, config *PipelineConfig) ([]Ra
 
 // phaseNormalize transforms raw prices to canonical rates
 func (p *Pipeline) phaseNormalize(ctx context.Context, rawPrices []RawPrice) ([]NormalizedRate, error) {
-	normalized, err := p.normalizer.Normalize(rawPrices)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	normalized, err := p.normalizer.Normalize(ctx, rawPrices)
 	if err != nil {
 		return nil, fmt.Errorf("normalization failed: %w", err)
 	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
 	if len(normalized) == 0 {
 		return nil, fmt.Errorf("normalization produced 0 rates")
 	}
 
+	// Round to the column scale so the hash and backup match the DB
+	RoundDecimalScale(normalized, PriceColumnScale)
 	return normalized, nil
 }
 
@@ -304,13 +399,11 @@ func (p *Pipeline) phaseNormalize(ctx context.Context, rawPrices []RawPrice) ([]
 func (p *Pipeline) phaseValidate(ctx context.Context, config *PipelineConfig, rates []NormalizedRate) error {
 	// Configure validator
 	p.validator.SetMinCoveragePercent(config.MinCoveragePercent)
+	p.validator.SetDistinctKeyCoverage(config.DistinctKeyCoverage)
 
 	// Get previous snapshot for coverage comparison
 	prevSnapshot, _ := p.store.GetActiveSnapshot(ctx, config.Provider, config.Region, config.Alias)
-	var prevRateCount int
-	if prevSnapshot != nil {
-		prevRateCount, _ = p.store.CountRates(ctx, prevSnapshot.ID)
-	}
+	prevRateCount := previousRateCount(ctx, p.store, prevSnapshot, config.DistinctKeyCoverage)
 
 	// Run all validations
 	return p.validator.ValidateAll(rates, prevRateCount)
@@ -325,7 +418,7 @@ func (p *Pipeline) phaseBackup(ctx context.Context, config *PipelineConfig, rate
 		Timestamp:     time.Now(),
 		ContentHash:   stats.ContentHash,
 		RateCount:     len(rates),
-		SchemaVersion: "1.0",
+		SchemaVersion: BackupSchemaVersion,
 		Rates:         rates,
 	}
 
@@ -334,6 +427,12 @@ func (p *Pipeline) phaseBackup(ctx context.Context, config *PipelineConfig, rate
 
 // phaseCommit atomically writes to database
 func (p *Pipeline) phaseCommit(ctx context.Context, config *PipelineConfig, rates []NormalizedRate, contentHash string) (uuid.UUID, error) {
+	unlock, err := p.store.LockIngestion(ctx, config.Provider, config.Region, config.Alias, true)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to take ingestion lock: %w", err)
+	}
+	defer unlock()
+
 	// Check for existing snapshot with same hash (idempotency)
 	existing, _ := p.store.FindSnapshotByHash(ctx, config.Provider, config.Region, config.Alias, contentHash)
 	if existing != nil {
@@ -343,7 +442,7 @@ func (p *Pipeline) phaseCommit(ctx context.Context, config *PipelineConfig, rate
 
 	// Create snapshot in a single transaction
 	snapshot := &db.PricingSnapshot{
-		ID:            uuid.New(),
+		ID:            newSnapshotID(config.DeterministicIDs, config.Provider, config.Region, config.Alias, contentHash),
 		Cloud:         config.Provider,
 		Region:        config.Region,
 		ProviderAlias: config.Alias,
@@ -362,8 +461,9 @@ func (p *Pipeline) phaseCommit(ctx context.Context, config *PipelineConfig, rate
 	}
 
 	// Rollback on any error
+	committed := false
 	defer func() {
-		if err != nil {
+		if !committed {
 			tx.Rollback()
 		}
 	}()
@@ -374,7 +474,10 @@ func (p *Pipeline) phaseCommit(ctx context.Context, config *PipelineConfig, rate
 	}
 
 	// Insert all rates
-	for _, nr := range rates {
+	for i, nr := range rates {
+		if err := checkCancelled(ctx, i); err != nil {
+			return uuid.Nil, fmt.Errorf("commit cancelled after %d rates: %w", i, err)
+		}
 		nr.RateKey.ID = uuid.New()
 		key, err := tx.UpsertRateKey(ctx, &nr.RateKey)
 		if err != nil {
@@ -391,6 +494,9 @@ func (p *Pipeline) phaseCommit(ctx context.Context, config *PipelineConfig, rate
 			Confidence: nr.Confidence,
 			TierMin:    nr.TierMin,
 			TierMax:    nr.TierMax,
+
+			EffectiveDate: nr.EffectiveDate,
+			Labels:        nr.Labels,
 		}
 		if err = tx.CreateRate(ctx, rate); err != nil {
 			return uuid.Nil, fmt.Errorf("failed to create rate: %w", err)
@@ -406,31 +512,58 @@ func (p *Pipeline) phaseCommit(ctx context.Context, config *PipelineConfig, rate
 	if err = tx.Commit(); err != nil {
 		return uuid.Nil, fmt.Errorf("commit failed: %w", err)
 	}
+	committed = true
 
 	return snapshot.ID, nil
 }
 
 // calculateHash computes a deterministic hash of rates
 func calculateHash(rates []NormalizedRate) string {
-	// Sort for determinism
-	sorted := make([]NormalizedRate, len(rates))
-	copy(sorted, rates)
-	sort.Slice(sorted, func(i, j int) bool {
-		ki := rateKeyString(sorted[i].RateKey)
-		kj := rateKeyString(sorted[j].RateKey)
-		return ki < kj
-	})
+	// Sort for determinism: rates sharing a key differ by unit, tier or
+	// effective date
+	contents := make([]string, len(rates))
+	for i, r := range rates {
+		contents[i] = rateHashContent(r)
+	}
+	sort.Strings(contents)
 
 	hasher := sha256.New()
-	for _, r := range sorted {
-		hasher.Write([]byte(rateKeyString(r.RateKey)))
-		hasher.Write([]byte(r.Unit))
-		hasher.Write([]byte(r.Price.String()))
+	for _, c := range contents {
+		hasher.Write([]byte(c))
 	}
 
 	return hex.EncodeToString(hasher.Sum(nil))
 }
 
+// rateHashKey identifies a rate within a snapshot: key, unit, tier bounds
+// and currency
+func rateHashKey(r NormalizedRate) string {
+	return fmt.Sprintf("%s|%s|%s|%s|%s", rateKeyString(r.RateKey), r.Unit, tierBound(r.TierMin), tierBound(r.TierMax), r.Currency)
+}
+
+// rateHashContent is what the content hash covers for one rate: its
+// identity, effective date and price
+func rateHashContent(r NormalizedRate) string {
+	return fmt.Sprintf("%s|%s|%s", rateHashKey(r), effectiveDay(r.EffectiveDate), r.Price.String())
+}
+
+// effectiveDay formats an optional effective date as its UTC day, the
+// precision the rates table stores, empty when undated
+func effectiveDay(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format("2006-01-02")
+}
+
+// tierBound formats an optional tier bound, empty when unbounded
+func tierBound(d *decimal.Decimal) string {
+	if d == nil {
+		return ""
+	}
+	return d.String()
+}
+
 func rateKeyString(k db.RateKey) string {
 	attrs := make([]string, 0, len(k.Attributes))
 	for k, v := range k.Attributes {
@@ -472,3 +605,23 @@ func ParsePrice(s string) (decimal.Decimal, error) {
 func MarshalRateKey(k db.RateKey) ([]byte, error) {
 	return json.Marshal(k.Attributes)
 }
+
+// RatesFromSnapshot converts stored snapshot rates back into normalized rates
+func RatesFromSnapshot(stored []db.SnapshotRate) []NormalizedRate {
+	rates := make([]NormalizedRate, 0, len(stored))
+	for _, sr := range stored {
+		rates = append(rates, NormalizedRate{
+			RateKey:    sr.RateKey,
+			Unit:       sr.Rate.Unit,
+			Price:      sr.Rate.Price,
+			Currency:   sr.Rate.Currency,
+			Confidence: sr.Rate.Confidence,
+			TierMin:    sr.Rate.TierMin,
+			TierMax:    sr.Rate.TierMax,
+
+			EffectiveDate: sr.Rate.EffectiveDate,
+			Labels:        sr.Rate.Labels,
+		})
+	}
+	return rates
+}
@@ -2,10 +2,17 @@
 package ingestion
 
 import (
+	"context"
+	"errors"
+	"math/rand"
+	"strings"
 	"testing"
+	"time"
 
 	"terraform-cost/db"
+	"terraform-cost/db/memstore"
 
+	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 )
 
@@ -78,6 +85,116 @@ func TestValidateCoverageNotDecreased(t *testing.T) {
 	}
 }
 
+func TestDistinctKeyCoverage(t *testing.T) {
+	// Three tiers of one key plus a second key: 4 rows, 2 distinct keys
+	tiered := db.RateKey{Cloud: db.AWS, Service: "AmazonS3", Region: "us-east-1", Attributes: map[string]string{"storageClass": "General Purpose"}}
+	other := db.RateKey{Cloud: db.AWS, Service: "AmazonS3", Region: "us-east-1", Attributes: map[string]string{"storageClass": "Glacier"}}
+	rates := []NormalizedRate{
+		{RateKey: tiered, Unit: "GB-Mo", Price: decimal.NewFromFloat(0.023)},
+		{RateKey: tiered, Unit: "GB-Mo", Price: decimal.NewFromFloat(0.022)},
+		{RateKey: tiered, Unit: "GB-Mo", Price: decimal.NewFromFloat(0.021)},
+		{RateKey: other, Unit: "GB-Mo", Price: decimal.NewFromFloat(0.004)},
+	}
+	if n := CountDistinctRateKeys(rates); n != 2 {
+		t.Fatalf("CountDistinctRateKeys = %d, want 2", n)
+	}
+
+	// The previous snapshot had 3 distinct keys; row counting hides the loss
+	validator := NewIngestionValidator()
+	if _, err := validator.ValidateAllChecks(rates, 3); err != nil {
+		t.Fatalf("expected row coverage to pass, got: %v", err)
+	}
+	validator.SetDistinctKeyCoverage(true)
+	checks, err := validator.ValidateAllChecks(rates, 3)
+	if err == nil || checks[len(checks)-1].Name != "coverage_not_decreased" {
+		t.Errorf("expected distinct-key coverage to fail, got %v %+v", err, checks)
+	}
+}
+
+// tieredStore reports every distinct rate key as three tier rows
+type tieredStore struct {
+	*memstore.MemoryStore
+}
+
+func (s *tieredStore) CountRates(ctx context.Context, snapshotID uuid.UUID) (int, error) {
+	keys, err := s.CountDistinctRateKeys(ctx, snapshotID)
+	return 3 * keys, err
+}
+
+func TestDistinctKeyCoverageAcrossPaths(t *testing.T) {
+	store := &tieredStore{memstore.NewMemoryStore()}
+	lifecycleConfig := func(distinct bool) *LifecycleConfig {
+		config := DefaultLifecycleConfig()
+		config.Provider = db.AWS
+		config.Region = "us-east-1"
+		config.BackupDir = t.TempDir()
+		config.DistinctKeyCoverage = distinct
+		return config
+	}
+	stream := func(distinct bool) *LifecycleResult {
+		streamCfg := DefaultStreamingConfig()
+		streamCfg.WorkDir = t.TempDir()
+		fetcher := &staticFetcher{cloud: db.AWS, prices: testRawPrices("us-east-1", 5)}
+		result, _ := NewStreamingLifecycle(fetcher, &passthroughNormalizer{cloud: db.AWS}, store, streamCfg).Execute(context.Background(), lifecycleConfig(distinct))
+		return result
+	}
+	if baseline := stream(true); !baseline.Success {
+		t.Fatalf("baseline ingestion failed: %s", baseline.Error)
+	}
+
+	// 5 rates against 15 previous rows is a coverage drop; against 5 keys it is not
+	for _, distinct := range []bool{false, true} {
+		result := stream(distinct)
+		if dropped := strings.Contains(result.Error, "coverage decreased"); dropped == distinct {
+			t.Errorf("streaming with distinct=%t: error=%s", distinct, result.Error)
+		}
+
+		config := DefaultPipelineConfig()
+		config.Provider = db.AWS
+		config.Region = "us-east-1"
+		config.DryRun = true
+		config.BackupDir = t.TempDir()
+		config.DistinctKeyCoverage = distinct
+		fetcher := &staticFetcher{cloud: db.AWS, prices: testRawPrices("us-east-1", 5)}
+		pipelineResult, _ := NewPipeline(fetcher, &passthroughNormalizer{cloud: db.AWS}, store).Execute(context.Background(), config)
+		if failed := pipelineResult.FailedPhase == PhaseValidate; failed == distinct {
+			t.Errorf("pipeline with distinct=%t: failed phase %q, error=%s", distinct, pipelineResult.FailedPhase, pipelineResult.Error)
+		}
+	}
+}
+
+func TestValidateEffectiveDates(t *testing.T) {
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	current := now.AddDate(0, 0, -10)
+	yearAhead := now.AddDate(1, 0, 0)
+	key := db.RateKey{Cloud: db.AWS, Service: "AmazonEC2", Region: "us-east-1"}
+	rates := []NormalizedRate{
+		{RateKey: key, Price: decimal.NewFromFloat(0.10), EffectiveDate: &current},
+		{RateKey: key, Price: decimal.NewFromFloat(0.10)},
+	}
+
+	validator := NewIngestionValidator()
+	validator.SetFutureEffectiveWindow(30 * 24 * time.Hour)
+	if err := validator.ValidateEffectiveDates(rates, now); err != nil {
+		t.Errorf("expected current and undated rates to pass, got: %v", err)
+	}
+
+	rates = append(rates, NormalizedRate{RateKey: key, Price: decimal.NewFromFloat(0.08), EffectiveDate: &yearAhead})
+	if err := validator.ValidateEffectiveDates(rates, now); err == nil || !strings.Contains(err.Error(), "1 rates") {
+		t.Errorf("expected the year-ahead rate to fail, got: %v", err)
+	}
+
+	kept, dropped := DropFutureEffectiveDates(rates, now, 30*24*time.Hour)
+	if dropped != 1 || len(kept) != 2 || validator.ValidateEffectiveDates(kept, now) != nil {
+		t.Errorf("expected the year-ahead rate to be dropped, got %d dropped %+v", dropped, kept)
+	}
+
+	validator.SetFutureEffectiveWindow(0)
+	if err := validator.ValidateEffectiveDates(rates, now); err != nil {
+		t.Errorf("expected a zero window to disable the check, got: %v", err)
+	}
+}
+
 func TestBackupWriteRead(t *testing.T) {
 	backup := &SnapshotBackup{
 		Provider:      db.AWS,
@@ -154,3 +271,239 @@ func TestPipelineResult(t *testing.T) {
 		t.Errorf("expected 5 phases, got %d", len(result.PhasesCompleted))
 	}
 }
+
+func TestCalculateHashIgnoresTierOrder(t *testing.T) {
+	key := db.RateKey{Cloud: db.AWS, Service: "AmazonS3", Region: "us-east-1", Attributes: map[string]string{"storage_class": "standard"}}
+	bound := func(s string) *decimal.Decimal {
+		d := decimal.RequireFromString(s)
+		return &d
+	}
+	rates := []NormalizedRate{
+		{RateKey: key, Unit: "GB-month", Price: decimal.RequireFromString("0.023"), TierMin: bound("0"), TierMax: bound("51200")},
+		{RateKey: key, Unit: "GB-month", Price: decimal.RequireFromString("0.022"), TierMin: bound("51200"), TierMax: bound("512000")},
+		{RateKey: key, Unit: "GB-month", Price: decimal.RequireFromString("0.021"), TierMin: bound("512000")},
+		{RateKey: key, Unit: "requests", Price: decimal.RequireFromString("0.0000004")},
+	}
+	want := calculateHash(rates)
+
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 20; i++ {
+		shuffled := append([]NormalizedRate(nil), rates...)
+		rng.Shuffle(len(shuffled), func(a, b int) { shuffled[a], shuffled[b] = shuffled[b], shuffled[a] })
+		if got := calculateHash(shuffled); got != want {
+			t.Fatalf("hash changed after shuffle: %s != %s", got, want)
+		}
+	}
+
+	// Tier bounds are part of the content
+	moved := append([]NormalizedRate(nil), rates...)
+	moved[1].TierMax = bound("256000")
+	if calculateHash(moved) == want {
+		t.Error("expected changed tier bound to change the hash")
+	}
+}
+
+func TestCalculateHashCoversEffectiveDate(t *testing.T) {
+	key := db.RateKey{Cloud: db.AWS, Service: "AmazonEC2", Region: "us-east-1", Attributes: map[string]string{"instanceType": "m5.large"}}
+	march := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	april := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+	rates := []NormalizedRate{
+		{RateKey: key, Unit: "hours", Price: decimal.RequireFromString("0.096"), EffectiveDate: &march},
+		{RateKey: key, Unit: "hours", Price: decimal.RequireFromString("0.096")},
+	}
+	want := calculateHash(rates)
+
+	redated := append([]NormalizedRate(nil), rates...)
+	redated[0].EffectiveDate = &april
+	if calculateHash(redated) == want {
+		t.Error("expected changed effective date to change the hash")
+	}
+
+	// Only the UTC day is stored, so the time of day does not matter
+	sameDay := append([]NormalizedRate(nil), rates...)
+	local := march.Add(6 * time.Hour).In(time.FixedZone("UTC+8", 8*60*60))
+	sameDay[0].EffectiveDate = &local
+	if calculateHash(sameDay) != want {
+		t.Error("expected the same UTC day to keep the hash")
+	}
+}
+
+func TestCalculateHashCoversCurrency(t *testing.T) {
+	key := db.RateKey{Cloud: db.Azure, Service: "Virtual Machines", Region: "eastus", Attributes: map[string]string{"armSkuName": "Standard_D2s_v3"}}
+	rates := []NormalizedRate{
+		{RateKey: key, Unit: "hours", Price: decimal.RequireFromString("0.096"), Currency: "USD"},
+	}
+	want := calculateHash(rates)
+
+	// The same numbers in another currency are a different catalog
+	converted := append([]NormalizedRate(nil), rates...)
+	converted[0].Currency = "EUR"
+	if calculateHash(converted) == want {
+		t.Error("expected changed currency to change the hash")
+	}
+}
+
+func TestValidateRateKeyCompleteness(t *testing.T) {
+	validator := NewIngestionValidator()
+
+	complete := []NormalizedRate{
+		{RateKey: db.RateKey{Cloud: db.AWS, Service: "AmazonEC2", ProductFamily: "Compute Instance", Region: "us-east-1"}, Price: decimal.RequireFromString("0.096")},
+		{RateKey: db.RateKey{Cloud: db.AWS, Service: "AWSDataTransfer", Region: "us-east-1"}, Price: decimal.RequireFromString("0.09")},
+	}
+	if err := validator.ValidateRateKeyCompleteness(complete); err != nil {
+		t.Errorf("expected complete rate keys to pass, got: %v", err)
+	}
+	if err := validator.ValidateAll(complete, 0); err != nil {
+		t.Errorf("expected ValidateAll to pass, got: %v", err)
+	}
+
+	emptyService := append(complete, NormalizedRate{
+		RateKey: db.RateKey{Cloud: db.AWS, ProductFamily: "Storage", Region: "us-east-1"},
+	})
+	if err := validator.ValidateRateKeyCompleteness(emptyService); err == nil {
+		t.Error("expected empty service to fail validation")
+	}
+	if err := validator.ValidateAll(emptyService, 0); err == nil {
+		t.Error("expected ValidateAll to reject empty service")
+	}
+
+	// Product family is optional unless required
+	validator.SetRequireProductFamily(true)
+	if err := validator.ValidateRateKeyCompleteness(complete); err == nil {
+		t.Error("expected empty product family to fail when required")
+	}
+}
+
+func TestValidateUnitConsistency(t *testing.T) {
+	validator := NewIngestionValidator()
+
+	canonical := []NormalizedRate{
+		{RateKey: db.RateKey{Service: "AmazonEC2"}, Unit: "hours"},
+		{RateKey: db.RateKey{Service: "AmazonS3"}, Unit: "GB-month"},
+		{RateKey: db.RateKey{Service: "Storage"}, Unit: "10K-transactions"},
+	}
+	issues, err := validator.ValidateUnitConsistency(canonical)
+	if err != nil || len(issues) != 0 {
+		t.Errorf("expected canonical units to pass, got %v, %v", issues, err)
+	}
+
+	mixed := append(canonical,
+		NormalizedRate{RateKey: db.RateKey{Service: "AmazonEC2"}, Unit: "hrs"},
+		NormalizedRate{RateKey: db.RateKey{Service: "Storage"}, Unit: "10,000-transactions"},
+		NormalizedRate{RateKey: db.RateKey{Service: "Storage"}, Unit: "10,000-transactions"},
+	)
+
+	// Warning by default: issues reported, no error
+	issues, err = validator.ValidateUnitConsistency(mixed)
+	if err != nil {
+		t.Errorf("expected non-strict check to only warn, got: %v", err)
+	}
+	if len(issues) != 2 {
+		t.Fatalf("expected issues for 2 services, got %v", issues)
+	}
+	if issues[0].Service != "AmazonEC2" || len(issues[0].Units) != 1 || issues[0].Units[0] != "hrs" {
+		t.Errorf("unexpected AmazonEC2 issue: %+v", issues[0])
+	}
+	if issues[1].Service != "Storage" || len(issues[1].Units) != 1 || issues[1].Units[0] != "10,000-transactions" {
+		t.Errorf("unexpected Storage issue: %+v", issues[1])
+	}
+
+	validator.SetStrictUnits(true)
+	if _, err := validator.ValidateUnitConsistency(mixed); err == nil {
+		t.Error("expected strict check to fail on non-canonical units")
+	}
+	if _, err := validator.ValidateUnitConsistency(canonical); err != nil {
+		t.Errorf("expected strict check to pass canonical units, got: %v", err)
+	}
+}
+
+func TestValidateDecimalScale(t *testing.T) {
+	validator := NewIngestionValidator()
+	key := db.RateKey{Cloud: db.AWS, Service: "AmazonEC2", ProductFamily: "Compute Instance", Region: "us-east-1"}
+
+	rates := []NormalizedRate{
+		{RateKey: key, Unit: "hours", Price: decimal.RequireFromString("0.0104")},
+		{RateKey: key, Unit: "GB-month", Price: decimal.RequireFromString("0.123456789012345")},
+	}
+	if err := validator.ValidateDecimalScale(rates, PriceColumnScale); err == nil {
+		t.Error("expected 15-decimal price to fail validation")
+	}
+	if err := validator.ValidateAll(rates, 0); err == nil {
+		t.Error("expected ValidateAll to reject 15-decimal price")
+	}
+
+	if n := RoundDecimalScale(rates, PriceColumnScale); n != 1 {
+		t.Errorf("expected 1 rounded price, got %d", n)
+	}
+	if want := decimal.RequireFromString("0.1234567890"); !rates[1].Price.Equal(want) {
+		t.Errorf("rounded price = %s, want %s", rates[1].Price, want)
+	}
+	if err := validator.ValidateDecimalScale(rates, PriceColumnScale); err != nil {
+		t.Errorf("expected rounded prices to pass, got: %v", err)
+	}
+
+	// Rounding is idempotent, so the content hash is stable
+	hash := calculateHash(rates)
+	if n := RoundDecimalScale(rates, PriceColumnScale); n != 0 || calculateHash(rates) != hash {
+		t.Errorf("expected second rounding to be a no-op, rounded %d", n)
+	}
+}
+
+func TestValidateRegionDistribution(t *testing.T) {
+	validator := NewIngestionValidator()
+	expected := []string{"us-east-1", "us-west-2", "eu-west-1"}
+
+	var concentrated []NormalizedRate
+	for i := 0; i < 30; i++ {
+		key := db.RateKey{Cloud: db.AWS, Service: "AmazonEC2", ProductFamily: "Compute Instance", Region: "us-east-1"}
+		concentrated = append(concentrated, NormalizedRate{RateKey: key, Unit: "hours", Price: decimal.NewFromInt(int64(i + 1))})
+	}
+	err := validator.ValidateRegionDistribution(concentrated, expected)
+	if err == nil || !strings.Contains(err.Error(), "[us-west-2 eu-west-1]") {
+		t.Errorf("expected us-west-2 and eu-west-1 to be reported missing, got: %v", err)
+	}
+
+	spread := append([]NormalizedRate(nil), concentrated...)
+	for i, region := range expected[1:] {
+		spread[i].RateKey.Region = region
+	}
+	if err := validator.ValidateRegionDistribution(spread, expected); err != nil {
+		t.Errorf("expected rates in every region to pass, got: %v", err)
+	}
+}
+
+func TestValidateNonZeroCoverage(t *testing.T) {
+	validator := NewIngestionValidator()
+	rate := func(service, price string) NormalizedRate {
+		return NormalizedRate{
+			RateKey: db.RateKey{Cloud: db.AWS, Service: service, ProductFamily: "Compute Instance", Region: "us-east-1"},
+			Price:   decimal.RequireFromString(price),
+		}
+	}
+
+	// Mostly non-zero: a free tier among priced rates passes
+	mostly := []NormalizedRate{rate("AmazonEC2", "0.096"), rate("AmazonEC2", "0.192"), rate("AWSLambda", "0"), rate("AWSLambda", "0.0000002")}
+	if err := validator.ValidateNonZeroCoverage(mostly); err != nil {
+		t.Errorf("expected mostly non-zero prices to pass, got %v", err)
+	}
+
+	// All zero for a contracted service fails even though the run overall is mostly priced
+	allZero := []NormalizedRate{rate("AmazonEC2", "0"), rate("AmazonEC2", "0.000"), rate("AmazonS3", "0.023"), rate("AmazonS3", "0.022"), rate("AmazonS3", "0.021")}
+	err := validator.ValidateNonZeroCoverage(allZero)
+	if err == nil || !strings.Contains(err.Error(), "AmazonEC2 (2 rates)") {
+		t.Errorf("expected AmazonEC2 to be reported as all zero, got %v", err)
+	}
+	if err := validator.ValidateAll(allZero, 0); !errors.Is(err, ErrValidationFailed) {
+		t.Errorf("expected ValidateAll to fail the prices_nonzero check, got %v", err)
+	}
+
+	// An uncontracted service may be free, but not most of the run
+	uncontracted := []NormalizedRate{rate("AWSFreeService", "0"), rate("AWSFreeService", "0"), rate("AmazonEC2", "0.096")}
+	if err := validator.ValidateNonZeroCoverage(uncontracted); err == nil || !strings.Contains(err.Error(), "2 of 3 rates") {
+		t.Errorf("expected the zero-priced fraction to fail, got %v", err)
+	}
+	validator.SetMaxZeroPriceFraction(1)
+	if err := validator.ValidateNonZeroCoverage(uncontracted); err != nil {
+		t.Errorf("expected a fraction of 1 to allow any share of zero prices, got %v", err)
+	}
+}
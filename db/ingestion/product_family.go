@@ -0,0 +1,166 @@
+// Package ingestion - Canonical product families for cross-cloud queries
+package ingestion
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"terraform-cost/db"
+)
+
+// CanonicalFamilyAttribute is the rate key attribute holding the canonical
+// product family; the provider's own family stays in RateKey.ProductFamily
+const CanonicalFamilyAttribute = "canonical_family"
+
+// Canonical product families shared by every provider
+const (
+	FamilyCompute       = "compute"
+	FamilyBlockStorage  = "block_storage"
+	FamilyObjectStorage = "object_storage"
+	FamilyDatabase      = "database"
+	FamilyNetwork       = "network"
+)
+
+// ProductFamilyRule maps one provider product family, optionally only for one
+// service, to a canonical family
+type ProductFamilyRule struct {
+	Cloud     db.CloudProvider `json:"cloud"`
+	Service   string           `json:"service,omitempty"` // empty = any service
+	Family    string           `json:"family"`
+	Canonical string           `json:"canonical"`
+}
+
+// DefaultProductFamilyRules maps each provider's compute, storage, database
+// and network families. Providers that use one family for block and object
+// storage are told apart by service.
+func DefaultProductFamilyRules() []ProductFamilyRule {
+	return []ProductFamilyRule{
+		{db.AWS, "", "Compute Instance", FamilyCompute},
+		{db.AWS, "AmazonEC2", "Storage", FamilyBlockStorage},
+		{db.AWS, "AmazonS3", "Storage", FamilyObjectStorage},
+		{db.AWS, "", "Database Instance", FamilyDatabase},
+		{db.AWS, "", "Data Transfer", FamilyNetwork},
+
+		{db.Azure, "", "Compute", FamilyCompute},
+		{db.Azure, "", "Databases", FamilyDatabase},
+		{db.Azure, "", "Networking", FamilyNetwork},
+
+		{db.GCP, "", "Compute", FamilyCompute},
+		{db.GCP, "Compute Engine", "Storage", FamilyBlockStorage},
+		{db.GCP, "Cloud Storage", "Storage", FamilyObjectStorage},
+		{db.GCP, "", "Network", FamilyNetwork},
+
+		{db.OCI, "", "Compute - Virtual Machine", FamilyCompute},
+		{db.OCI, "", "Storage - Block Volume", FamilyBlockStorage},
+		{db.OCI, "", "Storage - Object Storage", FamilyObjectStorage},
+
+		{db.DigitalOcean, "", "Compute", FamilyCompute},
+		{db.DigitalOcean, "", "Storage", FamilyBlockStorage},
+		{db.DigitalOcean, "", "Database", FamilyDatabase},
+	}
+}
+
+// ProductFamilyMapper looks up the canonical family of a provider family
+type ProductFamilyMapper struct {
+	rules map[string]string // cloud|service|family -> canonical
+}
+
+// NewProductFamilyMapper creates a mapper with the default rules
+func NewProductFamilyMapper() *ProductFamilyMapper {
+	m := &ProductFamilyMapper{rules: make(map[string]string)}
+	return m.WithRules(DefaultProductFamilyRules()...)
+}
+
+// WithRules adds rules, replacing any rule for the same cloud, service and family
+func (m *ProductFamilyMapper) WithRules(rules ...ProductFamilyRule) *ProductFamilyMapper {
+	for _, r := range rules {
+		m.rules[productFamilyKey(r.Cloud, r.Service, r.Family)] = r.Canonical
+	}
+	return m
+}
+
+// Map returns the canonical family of a rate, preferring a rule for its
+// service over one for any service
+func (m *ProductFamilyMapper) Map(cloud db.CloudProvider, service, family string) (string, bool) {
+	if canonical, ok := m.rules[productFamilyKey(cloud, service, family)]; ok {
+		return canonical, true
+	}
+	canonical, ok := m.rules[productFamilyKey(cloud, "", family)]
+	return canonical, ok
+}
+
+func productFamilyKey(cloud db.CloudProvider, service, family string) string {
+	return string(cloud) + "|" + service + "|" + family
+}
+
+// ProductFamilyNormalizer adds the canonical_family attribute to every rate
+// whose product family the mapper knows
+type ProductFamilyNormalizer struct {
+	inner  PriceNormalizer
+	mapper *ProductFamilyMapper
+}
+
+// NewProductFamilyNormalizer creates a normalizer tagging canonical families
+func NewProductFamilyNormalizer(inner PriceNormalizer, mapper *ProductFamilyMapper) *ProductFamilyNormalizer {
+	return &ProductFamilyNormalizer{inner: inner, mapper: mapper}
+}
+
+func (n *ProductFamilyNormalizer) Cloud() db.CloudProvider {
+	return n.inner.Cloud()
+}
+
+// Normalize normalizes with the inner normalizer, then tags canonical families
+func (n *ProductFamilyNormalizer) Normalize(ctx context.Context, raw []RawPrice) ([]NormalizedRate, error) {
+	rates, err := n.inner.Normalize(ctx, raw)
+	if err != nil {
+		return nil, err
+	}
+	for i := range rates {
+		key := &rates[i].RateKey
+		canonical, ok := n.mapper.Map(key.Cloud, key.Service, key.ProductFamily)
+		if !ok {
+			continue
+		}
+		if key.Attributes == nil {
+			key.Attributes = make(map[string]string)
+		}
+		key.Attributes[CanonicalFamilyAttribute] = canonical
+	}
+	return rates, nil
+}
+
+// ProductFamilyConfig is the external product family rule format
+type ProductFamilyConfig struct {
+	Rules []ProductFamilyRule `json:"rules"`
+}
+
+// LoadProductFamilyRulesFromJSON reads rules such as
+//
+//	{"rules": [{"cloud": "azure", "service": "Storage", "family": "Storage", "canonical": "object_storage"}]}
+func LoadProductFamilyRulesFromJSON(r io.Reader) ([]ProductFamilyRule, error) {
+	var cfg ProductFamilyConfig
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("failed to decode product family rules: %w", err)
+	}
+	for i, rule := range cfg.Rules {
+		if rule.Cloud == "" || rule.Family == "" || rule.Canonical == "" {
+			return nil, fmt.Errorf("product family rule %d: cloud, family and canonical are required", i)
+		}
+	}
+	return cfg.Rules, nil
+}
+
+// LoadProductFamilyRulesFromFile reads product family rules from a JSON file
+func LoadProductFamilyRulesFromFile(path string) ([]ProductFamilyRule, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return LoadProductFamilyRulesFromJSON(f)
+}
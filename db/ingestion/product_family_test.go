@@ -0,0 +1,64 @@
+// Package ingestion - Canonical product family tests
+package ingestion
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"terraform-cost/db"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestProductFamilyNormalizerMapsCompute(t *testing.T) {
+	computeKeys := []db.RateKey{
+		{Cloud: db.AWS, Service: "AmazonEC2", ProductFamily: "Compute Instance", Attributes: map[string]string{"instance_type": "m5.large"}},
+		{Cloud: db.Azure, Service: "Virtual Machines", ProductFamily: "Compute", Attributes: map[string]string{"sku_name": "D2s v3"}},
+		{Cloud: db.GCP, Service: "Compute Engine", ProductFamily: "Compute"},
+		{Cloud: db.OCI, Service: "Compute", ProductFamily: "Compute - Virtual Machine"},
+		{Cloud: db.DigitalOcean, Service: "Droplets", ProductFamily: "Compute"},
+	}
+	inner := &fixedNormalizer{}
+	for _, key := range computeKeys {
+		inner.rates = append(inner.rates, NormalizedRate{RateKey: key, Price: decimal.RequireFromString("0.1")})
+	}
+
+	rates, err := NewProductFamilyNormalizer(inner, NewProductFamilyMapper()).Normalize(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("normalize failed: %v", err)
+	}
+	for i, r := range rates {
+		if got := r.RateKey.Attributes[CanonicalFamilyAttribute]; got != FamilyCompute {
+			t.Errorf("%s %q: canonical family = %q, want compute", r.RateKey.Cloud, r.RateKey.ProductFamily, got)
+		}
+		if r.RateKey.ProductFamily != computeKeys[i].ProductFamily {
+			t.Errorf("%s: original family %q not preserved, got %q", r.RateKey.Cloud, computeKeys[i].ProductFamily, r.RateKey.ProductFamily)
+		}
+	}
+}
+
+func TestProductFamilyMapperPrefersServiceRules(t *testing.T) {
+	mapper := NewProductFamilyMapper()
+	if got, _ := mapper.Map(db.AWS, "AmazonEC2", "Storage"); got != FamilyBlockStorage {
+		t.Errorf("EBS storage = %q, want block_storage", got)
+	}
+	if got, _ := mapper.Map(db.AWS, "AmazonS3", "Storage"); got != FamilyObjectStorage {
+		t.Errorf("S3 storage = %q, want object_storage", got)
+	}
+	if _, ok := mapper.Map(db.Azure, "Storage", "Storage"); ok {
+		t.Error("expected Azure storage to be unmapped by default")
+	}
+
+	rules, err := LoadProductFamilyRulesFromJSON(strings.NewReader(
+		`{"rules": [{"cloud": "azure", "service": "Storage", "family": "Storage", "canonical": "object_storage"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := mapper.WithRules(rules...).Map(db.Azure, "Storage", "Storage"); got != FamilyObjectStorage {
+		t.Errorf("configured Azure storage = %q, want object_storage", got)
+	}
+	if _, err := LoadProductFamilyRulesFromJSON(strings.NewReader(`{"rules": [{"cloud": "aws", "family": "Storage"}]}`)); err == nil {
+		t.Error("expected a rule without a canonical family to be rejected")
+	}
+}
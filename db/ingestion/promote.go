@@ -0,0 +1,86 @@
+// Package ingestion - Promoting a snapshot between pricing databases
+package ingestion
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"terraform-cost/db"
+
+	"github.com/google/uuid"
+)
+
+// PromoteSnapshot copies snapshot snapshotID from src into dst as a new
+// active snapshot, so pricing validated in one environment (staging) reaches
+// another (production) without fetching it again. The copy keeps the source's
+// content hash, source and metadata, and adds a promoted_from tag naming the
+// source snapshot. The rates are checked against the source hash first, and
+// failed snapshots are refused. When dst already holds a snapshot with that
+// hash it is activated instead of copied again.
+func PromoteSnapshot(ctx context.Context, src, dst db.PricingStore, snapshotID uuid.UUID) (*db.PricingSnapshot, error) {
+	source, err := src.GetSnapshot(ctx, snapshotID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load snapshot %s: %w", snapshotID, err)
+	}
+	if source == nil {
+		return nil, fmt.Errorf("snapshot %s not found in source store", snapshotID)
+	}
+	if source.State == string(StateFailed) {
+		return nil, fmt.Errorf("snapshot %s failed ingestion and cannot be promoted", snapshotID)
+	}
+
+	stored, err := src.GetRatesBySnapshot(ctx, snapshotID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load rates of snapshot %s: %w", snapshotID, err)
+	}
+	rates := RatesFromSnapshot(stored)
+	if len(rates) == 0 {
+		return nil, fmt.Errorf("snapshot %s has no rates", snapshotID)
+	}
+	if hash := calculateHash(rates); hash != source.Hash {
+		return nil, fmt.Errorf("snapshot %s rates hash to %s, not its recorded %s", snapshotID, hash, source.Hash)
+	}
+
+	unlock, err := dst.LockIngestion(ctx, source.Cloud, source.Region, source.ProviderAlias, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to take ingestion lock: %w", err)
+	}
+	defer unlock()
+
+	existing, err := dst.FindSnapshotByHash(ctx, source.Cloud, source.Region, source.ProviderAlias, source.Hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for existing snapshot: %w", err)
+	}
+	if existing != nil {
+		if !existing.IsActive {
+			if err := dst.ActivateSnapshot(ctx, existing.ID); err != nil {
+				return nil, fmt.Errorf("failed to activate existing snapshot %s: %w", existing.ID, err)
+			}
+		}
+		return dst.GetSnapshot(ctx, existing.ID)
+	}
+
+	metadata := make(map[string]string, len(source.Metadata)+1)
+	for k, v := range source.Metadata {
+		metadata[k] = v
+	}
+	metadata["promoted_from"] = snapshotID.String()
+
+	promoted := &db.PricingSnapshot{
+		ID:            uuid.New(),
+		Cloud:         source.Cloud,
+		Region:        source.Region,
+		ProviderAlias: source.ProviderAlias,
+		Source:        source.Source,
+		FetchedAt:     source.FetchedAt,
+		ValidFrom:     time.Now(),
+		Hash:          source.Hash,
+		Version:       source.Version,
+		Metadata:      metadata,
+	}
+	if err := commitSnapshotRates(ctx, dst, promoted, rates); err != nil {
+		return nil, err
+	}
+	return dst.GetSnapshot(ctx, promoted.ID)
+}
@@ -0,0 +1,55 @@
+// Package ingestion - Snapshot promotion tests
+package ingestion
+
+import (
+	"context"
+	"testing"
+
+	"terraform-cost/db"
+	"terraform-cost/db/memstore"
+)
+
+func TestPromoteSnapshotBetweenStores(t *testing.T) {
+	ctx := context.Background()
+	staging := memstore.NewMemoryStore()
+	production := memstore.NewMemoryStore()
+
+	config := DefaultLifecycleConfig()
+	config.Provider = db.AWS
+	config.Region = "us-east-1"
+	config.Environment = "development"
+	config.BackupDir = t.TempDir()
+	config.Metadata = map[string]string{"git_sha": "abc123"}
+	fetcher := &staticFetcher{cloud: db.AWS, prices: testRawPrices("us-east-1", 5)}
+	result, err := NewLifecycle(fetcher, &passthroughNormalizer{cloud: db.AWS}, staging).Execute(ctx, config)
+	if err != nil || !result.Success {
+		t.Fatalf("staging ingestion failed: %v %+v", err, result)
+	}
+	source, _ := staging.GetSnapshot(ctx, *result.SnapshotID)
+
+	promoted, err := PromoteSnapshot(ctx, staging, production, source.ID)
+	if err != nil {
+		t.Fatalf("promote failed: %v", err)
+	}
+	if promoted.ID == source.ID || promoted.Hash != source.Hash || !promoted.IsActive {
+		t.Errorf("expected a new active snapshot with hash %s, got %+v", source.Hash, promoted)
+	}
+	if promoted.Metadata["promoted_from"] != source.ID.String() || promoted.Metadata["git_sha"] != "abc123" {
+		t.Errorf("expected source metadata plus promoted_from, got %v", promoted.Metadata)
+	}
+
+	active, _ := production.GetActiveSnapshot(ctx, db.AWS, "us-east-1", "default")
+	if active == nil || active.ID != promoted.ID {
+		t.Fatalf("expected the promoted snapshot to be active in production, got %+v", active)
+	}
+	stored, _ := production.GetRatesBySnapshot(ctx, promoted.ID)
+	if len(stored) != 5 || calculateHash(RatesFromSnapshot(stored)) != source.Hash {
+		t.Errorf("expected the 5 staging rates with the same content hash, got %d", len(stored))
+	}
+
+	// Promoting again finds the copy rather than duplicating it
+	again, err := PromoteSnapshot(ctx, staging, production, source.ID)
+	if err != nil || again.ID != promoted.ID {
+		t.Errorf("expected a repeat promotion to return %s, got %+v, %v", promoted.ID, again, err)
+	}
+}
@@ -0,0 +1,92 @@
+// Package ingestion - Backup retention
+package ingestion
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// backupTimestampLayout is the timestamp in backup filenames
+const backupTimestampLayout = "2006-01-02T15-04-05"
+
+// RetentionPolicy decides which backups PruneBackups removes. A backup is
+// removed when it is outside the newest KeepLast for its region or older than
+// MaxAge; zero disables a rule. The newest backup per region is always kept.
+type RetentionPolicy struct {
+	KeepLast int
+	MaxAge   time.Duration
+}
+
+// PruneBackups deletes backups under baseDir that the policy does not retain
+// and returns the removed paths. Files whose names do not follow the backup
+// naming scheme are left alone.
+func PruneBackups(baseDir string, policy RetentionPolicy) ([]string, error) {
+	if policy.KeepLast < 0 || policy.MaxAge < 0 {
+		return nil, fmt.Errorf("retention policy values must not be negative")
+	}
+
+	backups, err := NewBackupManager().ListBackups(baseDir)
+	if err != nil {
+		return nil, err
+	}
+
+	type datedBackup struct {
+		path string
+		at   time.Time
+	}
+	byRegion := make(map[string][]datedBackup)
+	for _, b := range backups {
+		region, at, ok := parseBackupFilename(b.Filename)
+		if !ok {
+			continue
+		}
+		key := string(b.Provider) + "/" + region
+		byRegion[key] = append(byRegion[key], datedBackup{path: b.Path, at: at})
+	}
+
+	now := time.Now()
+	var removed []string
+	for _, group := range byRegion {
+		sort.Slice(group, func(i, j int) bool { return group[i].at.After(group[j].at) })
+		for i, b := range group[1:] {
+			beyondCount := policy.KeepLast > 0 && i+1 >= policy.KeepLast
+			tooOld := policy.MaxAge > 0 && now.Sub(b.at) > policy.MaxAge
+			if !beyondCount && !tooOld {
+				continue
+			}
+			if err := os.Remove(b.path); err != nil {
+				return removed, fmt.Errorf("failed to remove %s: %w", b.path, err)
+			}
+			removed = append(removed, b.path)
+
+			// Raw prices saved with SaveRaw and the validation report go with their backup
+			if err := os.Remove(RawPricesPath(b.path)); err != nil && !os.IsNotExist(err) {
+				return removed, fmt.Errorf("failed to remove raw prices of %s: %w", b.path, err)
+			}
+			if err := os.Remove(ValidationReportPath(b.path)); err != nil && !os.IsNotExist(err) {
+				return removed, fmt.Errorf("failed to remove validation report of %s: %w", b.path, err)
+			}
+		}
+	}
+	sort.Strings(removed)
+	return removed, nil
+}
+
+// parseBackupFilename extracts the region and timestamp from
+// region_timestamp[_hash][_counter].json(.gz)
+func parseBackupFilename(name string) (string, time.Time, bool) {
+	name = strings.TrimSuffix(strings.TrimSuffix(name, ".gz"), ".json")
+	parts := strings.Split(name, "_")
+	if len(parts) < 2 || parts[0] == "" {
+		return "", time.Time{}, false
+	}
+	// Timestamps are written in the ingesting process's local time
+	at, err := time.ParseInLocation(backupTimestampLayout, parts[1], time.Local)
+	if err != nil {
+		return "", time.Time{}, false
+	}
+	return parts[0], at, true
+}
@@ -0,0 +1,80 @@
+// Package ingestion - Backup retention tests
+package ingestion
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+)
+
+// writeDatedBackups creates empty backup files for region, one per age
+func writeDatedBackups(t *testing.T, dir, region string, ages ...time.Duration) []string {
+	t.Helper()
+	providerDir := filepath.Join(dir, "aws")
+	if err := os.MkdirAll(providerDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, age := range ages {
+		name := region + "_" + time.Now().Add(-age).Format(backupTimestampLayout) + "_abcdef123456.json.gz"
+		path := filepath.Join(providerDir, name)
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+func TestPruneBackupsKeepLast(t *testing.T) {
+	dir := t.TempDir()
+	day := 24 * time.Hour
+	east := writeDatedBackups(t, dir, "us-east-1", 0, day, 2*day, 3*day)
+	west := writeDatedBackups(t, dir, "us-west-2", 5*day)
+
+	removed, err := PruneBackups(dir, RetentionPolicy{KeepLast: 2})
+	if err != nil {
+		t.Fatalf("prune failed: %v", err)
+	}
+
+	want := []string{east[2], east[3]}
+	sort.Strings(want)
+	if len(removed) != 2 || removed[0] != want[0] || removed[1] != want[1] {
+		t.Errorf("removed %v, want %v", removed, want)
+	}
+	for _, kept := range []string{east[0], east[1], west[0]} {
+		if _, err := os.Stat(kept); err != nil {
+			t.Errorf("expected %s to be kept: %v", kept, err)
+		}
+	}
+}
+
+func TestPruneBackupsMaxAgeKeepsNewest(t *testing.T) {
+	dir := t.TempDir()
+	day := 24 * time.Hour
+	east := writeDatedBackups(t, dir, "us-east-1", day, 10*day, 40*day)
+	// Every backup for this region is old, but the newest must survive
+	west := writeDatedBackups(t, dir, "us-west-2", 60*day, 90*day)
+
+	// Unrelated files are never touched
+	stray := filepath.Join(dir, "aws", "notes.json")
+	os.WriteFile(stray, nil, 0644)
+
+	removed, err := PruneBackups(dir, RetentionPolicy{MaxAge: 30 * day})
+	if err != nil {
+		t.Fatalf("prune failed: %v", err)
+	}
+
+	want := []string{east[2], west[1]}
+	sort.Strings(want)
+	if len(removed) != 2 || removed[0] != want[0] || removed[1] != want[1] {
+		t.Errorf("removed %v, want %v", removed, want)
+	}
+	for _, kept := range []string{east[0], east[1], west[0], stray} {
+		if _, err := os.Stat(kept); err != nil {
+			t.Errorf("expected %s to be kept: %v", kept, err)
+		}
+	}
+}
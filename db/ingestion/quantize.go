@@ -0,0 +1,103 @@
+// Package ingestion - Price quantization
+// Providers publish prices at different precisions (AWS as 10-decimal
+// strings, GCP derived from nanos), so the same price can be stored at
+// different scales. Quantizing during normalization gives every stored price
+// of a provider one scale, which keeps hashes and diffs stable.
+package ingestion
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"terraform-cost/db"
+)
+
+// PriceScales maps a provider to the decimal places its prices are quantized
+// to; providers without an entry keep full precision
+type PriceScales map[db.CloudProvider]int
+
+// ParsePriceScales parses a scale for every provider ("6") or per provider
+// ("aws=6,gcp=10"). Scales must be between 0 and PriceColumnScale.
+func ParsePriceScales(s string, providers ...db.CloudProvider) (PriceScales, error) {
+	scales := make(PriceScales)
+	if s = strings.TrimSpace(s); s == "" {
+		return scales, nil
+	}
+	parse := func(v string) (int, error) {
+		places, err := strconv.Atoi(strings.TrimSpace(v))
+		if err != nil || places < 0 || places > PriceColumnScale {
+			return 0, fmt.Errorf("invalid price scale %q, expected 0-%d decimal places", v, PriceColumnScale)
+		}
+		return places, nil
+	}
+
+	if !strings.Contains(s, "=") {
+		places, err := parse(s)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range providers {
+			scales[p] = places
+		}
+		return scales, nil
+	}
+	for _, pair := range strings.Split(s, ",") {
+		provider, value, ok := strings.Cut(pair, "=")
+		provider = strings.TrimSpace(provider)
+		if !ok || provider == "" {
+			return nil, fmt.Errorf("invalid price scale entry %q, expected provider=places", pair)
+		}
+		places, err := parse(value)
+		if err != nil {
+			return nil, err
+		}
+		scales[db.CloudProvider(strings.ToLower(provider))] = places
+	}
+	return scales, nil
+}
+
+// Normalizer wraps inner so its prices are quantized to the provider's scale,
+// or returns inner unchanged when the provider has none
+func (s PriceScales) Normalizer(inner PriceNormalizer) PriceNormalizer {
+	places, ok := s[inner.Cloud()]
+	if !ok {
+		return inner
+	}
+	return NewQuantizingNormalizer(inner, places)
+}
+
+// QuantizingNormalizer rounds every normalized price to a fixed number of
+// decimal places
+type QuantizingNormalizer struct {
+	inner  PriceNormalizer
+	places int
+}
+
+// NewQuantizingNormalizer creates a normalizer rounding prices to places
+func NewQuantizingNormalizer(inner PriceNormalizer, places int) *QuantizingNormalizer {
+	return &QuantizingNormalizer{inner: inner, places: places}
+}
+
+func (n *QuantizingNormalizer) Cloud() db.CloudProvider {
+	return n.inner.Cloud()
+}
+
+// Normalize normalizes with the inner normalizer, then rounds the prices. A
+// nonzero price that would round to zero fails instead, since the scale is
+// too coarse for the provider and storing it would make the rate free.
+func (n *QuantizingNormalizer) Normalize(ctx context.Context, raw []RawPrice) ([]NormalizedRate, error) {
+	rates, err := n.inner.Normalize(ctx, raw)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range rates {
+		if !r.Price.IsZero() && r.Price.Round(int32(n.places)).IsZero() {
+			return nil, fmt.Errorf("price %s of %s/%s per %s rounds to zero at %d decimal places",
+				r.Price, r.RateKey.Service, r.RateKey.ProductFamily, r.Unit, n.places)
+		}
+	}
+	RoundDecimalScale(rates, n.places)
+	return rates, nil
+}
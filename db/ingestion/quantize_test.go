@@ -0,0 +1,77 @@
+// Package ingestion - Price quantization tests
+package ingestion
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"terraform-cost/db"
+)
+
+func TestParsePriceScales(t *testing.T) {
+	scales, err := ParsePriceScales("6", db.AWS)
+	if err != nil || len(scales) != 1 || scales[db.AWS] != 6 {
+		t.Errorf("expected 6 places for aws, got %v %v", scales, err)
+	}
+	scales, err = ParsePriceScales("aws=6, GCP=10", db.AWS)
+	if err != nil || scales[db.AWS] != 6 || scales[db.GCP] != 10 {
+		t.Errorf("expected per-provider scales, got %v %v", scales, err)
+	}
+	if scales, err := ParsePriceScales("", db.AWS); err != nil || len(scales) != 0 {
+		t.Errorf("expected no quantization by default, got %v %v", scales, err)
+	}
+	for _, bad := range []string{"11", "-1", "aws=x", "=6", "aws"} {
+		if _, err := ParsePriceScales(bad, db.AWS); err == nil {
+			t.Errorf("expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestQuantizingNormalizerSharesScale(t *testing.T) {
+	raw := []RawPrice{
+		{ServiceCode: "AWSLambda", Region: "us-east-1", Unit: "GB-Second", PricePerUnit: "0.0000166667", Currency: "USD"},
+		{ServiceCode: "AmazonEC2", Region: "us-east-1", Unit: "Hrs", PricePerUnit: "0.0960000000", Currency: "USD"},
+		{ServiceCode: "AmazonS3", Region: "us-east-1", Unit: "GB-Mo", PricePerUnit: "0.02299999999999", Currency: "USD"},
+	}
+	scales := PriceScales{db.AWS: 6, db.GCP: 10}
+
+	rates, err := scales.Normalizer(&passthroughNormalizer{cloud: db.AWS}).Normalize(context.Background(), raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []string{"0.000017", "0.096", "0.023"} {
+		if got := rates[i].Price.String(); got != want {
+			t.Errorf("%s quantized to 6 places = %s, want %s", rates[i].RateKey.Service, got, want)
+		}
+	}
+
+	// GCP prices derived from nanos carry more places than the column holds
+	gcp := []RawPrice{{ServiceCode: "Compute Engine", Region: "us-central1", Unit: "h", PricePerUnit: "0.0475166666666667", Currency: "USD"}}
+	rates, err = scales.Normalizer(&passthroughNormalizer{cloud: db.GCP}).Normalize(context.Background(), gcp)
+	if err != nil || rates[0].Price.String() != "0.0475166667" {
+		t.Errorf("expected 10 places for gcp, got %s %v", rates[0].Price, err)
+	}
+
+	// Providers without a scale keep full precision
+	inner := &passthroughNormalizer{cloud: db.Azure}
+	if scales.Normalizer(inner) != PriceNormalizer(inner) {
+		t.Error("expected a provider without a scale to keep its normalizer")
+	}
+}
+
+func TestQuantizingNormalizerRejectsPricesRoundedToZero(t *testing.T) {
+	raw := []RawPrice{
+		{ServiceCode: "AWSLambda", Region: "us-east-1", Unit: "Requests", PricePerUnit: "0.0000002", Currency: "USD"},
+		{ServiceCode: "AmazonS3", Region: "us-east-1", Unit: "GB-Mo", PricePerUnit: "0", Currency: "USD"},
+	}
+	normalizer := NewQuantizingNormalizer(&passthroughNormalizer{cloud: db.AWS}, 6)
+	if _, err := normalizer.Normalize(context.Background(), raw); err == nil || !strings.Contains(err.Error(), "AWSLambda") {
+		t.Errorf("expected the Lambda request price to be rejected at 6 places, got %v", err)
+	}
+
+	// Free rates stay free
+	if _, err := normalizer.Normalize(context.Background(), raw[1:]); err != nil {
+		t.Errorf("expected a zero price to pass, got %v", err)
+	}
+}
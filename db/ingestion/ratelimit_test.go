@@ -0,0 +1,138 @@
+// Package ingestion - Rate limiter tests
+package ingestion
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterSpacing(t *testing.T) {
+	limiter := NewRateLimiter(20) // one token every 50ms
+	ctx := context.Background()
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		if err := limiter.Wait(ctx); err != nil {
+			t.Fatalf("wait failed: %v", err)
+		}
+	}
+	// First call uses the initial token, the remaining four wait ~50ms each
+	if elapsed := time.Since(start); elapsed < 190*time.Millisecond {
+		t.Errorf("5 waits at 20 rps took %v, expected at least ~200ms", elapsed)
+	}
+}
+
+func TestRateLimiterRespectsContext(t *testing.T) {
+	limiter := NewRateLimiter(0.5) // one token every 2s
+	limiter.Wait(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := limiter.Wait(ctx); err != context.DeadlineExceeded {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestNilRateLimiterIsUnlimited(t *testing.T) {
+	limiter := NewRateLimiter(0)
+	if limiter != nil {
+		t.Fatal("expected nil limiter for 0 rps")
+	}
+	if err := limiter.Wait(context.Background()); err != nil {
+		t.Errorf("nil limiter should not fail: %v", err)
+	}
+}
+
+func TestGCPClientSpacesRequests(t *testing.T) {
+	var mu sync.Mutex
+	var arrivals []time.Time
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		arrivals = append(arrivals, time.Now())
+		page := len(arrivals)
+		mu.Unlock()
+
+		next := ""
+		if page < 4 {
+			next = fmt.Sprintf("page%d", page+1)
+		}
+		fmt.Fprintf(w, `{"services": [{"name": "services/%d", "displayName": "svc%d"}], "nextPageToken": %q}`, page, page, next)
+	}))
+	defer server.Close()
+
+	cfg := DefaultGCPPricingConfig()
+	cfg.HTTPTimeout = 5 * time.Second
+	cfg.RequestsPerSecond = 20
+	client := NewGCPPricingAPIClient(cfg)
+	client.baseURL = server.URL
+
+	services, err := client.listServices(context.Background())
+	if err != nil {
+		t.Fatalf("listServices failed: %v", err)
+	}
+	if len(services) != 4 {
+		t.Fatalf("expected 4 services across pages, got %d", len(services))
+	}
+
+	for i := 1; i < len(arrivals); i++ {
+		if gap := arrivals[i].Sub(arrivals[i-1]); gap < 40*time.Millisecond {
+			t.Errorf("requests %d and %d only %v apart, expected ~50ms", i-1, i, gap)
+		}
+	}
+}
+
+func TestGCPClientRetriesAfter429(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) <= 2 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		fmt.Fprint(w, `{"services": [{"name": "services/A", "displayName": "Compute Engine"}]}`)
+	}))
+	defer server.Close()
+
+	cfg := DefaultGCPPricingConfig()
+	cfg.RequestsPerSecond = 0
+	client := NewGCPPricingAPIClient(cfg)
+	client.baseURL = server.URL
+
+	services, err := client.listServices(context.Background())
+	if err != nil {
+		t.Fatalf("expected retry to succeed, got %v", err)
+	}
+	if len(services) != 1 || atomic.LoadInt32(&calls) != 3 {
+		t.Errorf("expected 1 service after 3 calls, got %d services after %d calls", len(services), calls)
+	}
+
+	// Retries are bounded
+	atomic.StoreInt32(&calls, 0)
+	client.maxRetries = 1
+	if _, err := client.listServices(context.Background()); err == nil {
+		t.Error("expected failure once retries are exhausted")
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	if d := parseRetryAfter("3", time.Second); d != 3*time.Second {
+		t.Errorf("seconds form: got %v", d)
+	}
+	if d := parseRetryAfter("", 2*time.Second); d != 2*time.Second {
+		t.Errorf("missing header should use fallback: got %v", d)
+	}
+	if d := parseRetryAfter("soon", 2*time.Second); d != 2*time.Second {
+		t.Errorf("malformed header should use fallback: got %v", d)
+	}
+	future := time.Now().Add(5 * time.Second).UTC().Format(http.TimeFormat)
+	if d := parseRetryAfter(future, time.Second); d <= 3*time.Second || d > 5*time.Second {
+		t.Errorf("date form: got %v", d)
+	}
+}
@@ -0,0 +1,41 @@
+// Package ingestion - Region validation against the billable region registry
+package ingestion
+
+import (
+	"fmt"
+	"strings"
+
+	"terraform-cost/db"
+	"terraform-cost/db/regions"
+)
+
+// restrictedPricingSources are region pricing sources the public pricing
+// APIs do not cover
+var restrictedPricingSources = map[string]bool{
+	"govcloud": true,
+	"china":    true,
+}
+
+// ValidateRegion rejects a region the registry does not list as billable for
+// provider, listing the valid ones. GovCloud and China regions are rejected
+// unless allowRestricted is set, since they need a different pricing source.
+func ValidateRegion(registry *regions.Registry, provider db.CloudProvider, region string, allowRestricted bool) error {
+	if reg := registry.GetRegion(provider, region); reg != nil && reg.Billable {
+		if restrictedPricingSources[reg.PricingSource] && !allowRestricted {
+			return fmt.Errorf("%s region %s (%s) is priced by the %s pricing source, not the public pricing API; set AllowRestrictedRegions to ingest it from a fetcher for that source",
+				provider, region, reg.DisplayName, reg.PricingSource)
+		}
+		return nil
+	}
+
+	var valid []string
+	for _, r := range registry.GetBillableRegions(provider) {
+		if allowRestricted || !restrictedPricingSources[r.PricingSource] {
+			valid = append(valid, r.Region)
+		}
+	}
+	if len(valid) == 0 {
+		return fmt.Errorf("unknown provider %q: no billable regions registered", provider)
+	}
+	return fmt.Errorf("%s region %q is not a billable region; valid regions: %s", provider, region, strings.Join(valid, ", "))
+}
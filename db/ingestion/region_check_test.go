@@ -0,0 +1,65 @@
+// Package ingestion - Region validation tests
+package ingestion
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"terraform-cost/db"
+	"terraform-cost/db/memstore"
+	"terraform-cost/db/regions"
+)
+
+func TestValidateRegion(t *testing.T) {
+	registry := regions.NewRegistry()
+
+	if err := ValidateRegion(registry, db.AWS, "us-east-1", false); err != nil {
+		t.Errorf("expected us-east-1 to be valid, got: %v", err)
+	}
+
+	err := ValidateRegion(registry, db.AWS, "us-east-9", false)
+	if err == nil || !strings.Contains(err.Error(), "us-east-9") || !strings.Contains(err.Error(), "eu-west-1") {
+		t.Errorf("expected invalid region error listing valid regions, got: %v", err)
+	}
+	if strings.Contains(err.Error(), "us-gov-west-1") {
+		t.Errorf("expected GovCloud regions left out of the valid list, got: %v", err)
+	}
+
+	// GovCloud and China need a different pricing source
+	for _, region := range []string{"us-gov-west-1", "cn-north-1"} {
+		if err := ValidateRegion(registry, db.AWS, region, false); err == nil || !strings.Contains(err.Error(), "pricing source") {
+			t.Errorf("expected %s to be flagged for its pricing source, got: %v", region, err)
+		}
+		if err := ValidateRegion(registry, db.AWS, region, true); err != nil {
+			t.Errorf("expected %s to be allowed when restricted regions are, got: %v", region, err)
+		}
+	}
+}
+
+func TestLifecycleRejectsUnknownRegion(t *testing.T) {
+	config := DefaultLifecycleConfig()
+	config.Provider = db.AWS
+	config.Region = "us-east-9"
+	config.Environment = "development"
+	config.BackupDir = t.TempDir()
+
+	fetcher := &staticFetcher{cloud: db.AWS, prices: testRawPrices("us-east-9", 3)}
+	result, _ := NewLifecycle(fetcher, &passthroughNormalizer{cloud: db.AWS}, memstore.NewMemoryStore()).Execute(context.Background(), config)
+	if result.Success || !strings.Contains(result.Error, "not a billable region") {
+		t.Errorf("expected lifecycle to reject us-east-9, got %+v", result)
+	}
+	if result.RawCount != 0 {
+		t.Errorf("expected rejection before fetching, got %d raw prices", result.RawCount)
+	}
+}
+
+func TestAzureFetchRegionRejectsChina(t *testing.T) {
+	client := NewAzurePricingAPIClient(nil)
+	client.baseURL = "http://retail.invalid"
+
+	_, err := client.FetchRegion(context.Background(), "chinaeast2")
+	if err == nil || !strings.Contains(err.Error(), "china pricing source") {
+		t.Errorf("expected Azure China to be rejected by pricing source, got: %v", err)
+	}
+}
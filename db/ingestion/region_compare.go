@@ -0,0 +1,160 @@
+// Package ingestion - Cross-region price comparison for anomaly detection
+package ingestion
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"terraform-cost/db"
+
+	"github.com/google/uuid"
+)
+
+// DefaultRegionOutlierThreshold is the relative deviation from the peer
+// median (0.5 = 50%) beyond which CompareRegions flags a region
+const DefaultRegionOutlierThreshold = 0.5
+
+// RegionComparison compares one service's prices across regions on the rate
+// keys every compared region has
+type RegionComparison struct {
+	Cloud          db.CloudProvider  `json:"cloud"`
+	Service        string            `json:"service"`
+	Threshold      float64           `json:"threshold"`
+	CommonKeys     int               `json:"common_keys"`
+	Regions        []RegionDeviation `json:"regions"`
+	MissingRegions []string          `json:"missing_regions,omitempty"` // no active snapshot
+}
+
+// RegionDeviation is how far one region's prices sit from the peer median
+type RegionDeviation struct {
+	Region     string    `json:"region"`
+	SnapshotID uuid.UUID `json:"snapshot_id"`
+
+	// MedianDeviation is the median over the common keys of
+	// (price - median price of all regions) / median price
+	MedianDeviation float64 `json:"median_deviation"`
+	Outlier         bool    `json:"outlier"`
+}
+
+// Outliers returns the flagged regions
+func (c *RegionComparison) Outliers() []string {
+	var outliers []string
+	for _, r := range c.Regions {
+		if r.Outlier {
+			outliers = append(outliers, r.Region)
+		}
+	}
+	return outliers
+}
+
+// CompareRegions flags regions whose prices for service deviate from their
+// peers by more than DefaultRegionOutlierThreshold. A region priced far from
+// every other usually points at a region mapping bug in ingestion.
+func CompareRegions(ctx context.Context, store db.PricingStore, cloud db.CloudProvider, regions []string, service string) (*RegionComparison, error) {
+	return CompareRegionsWithThreshold(ctx, store, cloud, regions, service, DefaultRegionOutlierThreshold)
+}
+
+// CompareRegionsWithThreshold is CompareRegions with an explicit threshold
+func CompareRegionsWithThreshold(ctx context.Context, store db.PricingStore, cloud db.CloudProvider, regions []string, service string, threshold float64) (*RegionComparison, error) {
+	comparison := &RegionComparison{Cloud: cloud, Service: service, Threshold: threshold}
+
+	// region -> comparable key -> price
+	prices := make(map[string]map[string]float64)
+	var compared []RegionDeviation
+	for _, region := range regions {
+		snapshot, err := store.GetActiveSnapshot(ctx, cloud, region, "default")
+		if err != nil {
+			return nil, fmt.Errorf("failed to get active snapshot for %s: %w", region, err)
+		}
+		if snapshot == nil {
+			comparison.MissingRegions = append(comparison.MissingRegions, region)
+			continue
+		}
+		rates, err := store.GetRatesBySnapshot(ctx, snapshot.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load rates for %s: %w", region, err)
+		}
+		prices[region] = comparablePrices(rates, service)
+		compared = append(compared, RegionDeviation{Region: region, SnapshotID: snapshot.ID})
+	}
+	if len(compared) < 2 {
+		return nil, fmt.Errorf("need at least two regions with pricing to compare, got %d", len(compared))
+	}
+
+	var common []string
+	for key := range prices[compared[0].Region] {
+		inAll := true
+		for _, r := range compared[1:] {
+			if _, ok := prices[r.Region][key]; !ok {
+				inAll = false
+				break
+			}
+		}
+		if inAll {
+			common = append(common, key)
+		}
+	}
+	if len(common) == 0 {
+		return nil, fmt.Errorf("no %s rate key is priced in all of %d regions", service, len(compared))
+	}
+	comparison.CommonKeys = len(common)
+
+	deviations := make([][]float64, len(compared))
+	for _, key := range common {
+		values := make([]float64, len(compared))
+		for i, r := range compared {
+			values[i] = prices[r.Region][key]
+		}
+		peer := median(values)
+		if peer == 0 {
+			continue
+		}
+		for i, v := range values {
+			deviations[i] = append(deviations[i], (v-peer)/peer)
+		}
+	}
+	for i := range compared {
+		compared[i].MedianDeviation = median(deviations[i])
+		compared[i].Outlier = math.Abs(compared[i].MedianDeviation) > threshold
+	}
+	comparison.Regions = compared
+	return comparison, nil
+}
+
+// comparablePrices keys the first-tier prices of service by product family,
+// unit, currency and the attributes that do not name the region
+func comparablePrices(rates []db.SnapshotRate, service string) map[string]float64 {
+	prices := make(map[string]float64)
+	for _, r := range rates {
+		if r.RateKey.Service != service || (r.Rate.TierMin != nil && r.Rate.TierMin.IsPositive()) {
+			continue
+		}
+		attrs := make([]string, 0, len(r.RateKey.Attributes))
+		for k, v := range r.RateKey.Attributes {
+			if !isRegionAttribute(k) {
+				attrs = append(attrs, k+"="+v)
+			}
+		}
+		sort.Strings(attrs)
+		key := fmt.Sprintf("%s|%s|%s|%s", r.RateKey.ProductFamily, r.Rate.Unit, r.Rate.Currency, strings.Join(attrs, ","))
+		prices[key] = r.Rate.Price.InexactFloat64()
+	}
+	return prices
+}
+
+// median returns the median of values, 0 when empty
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
@@ -0,0 +1,57 @@
+// Package ingestion - Cross-region comparison tests
+package ingestion
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"terraform-cost/db"
+	"terraform-cost/db/memstore"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestCompareRegionsFlagsOutlier(t *testing.T) {
+	ctx := context.Background()
+	store := memstore.NewMemoryStore()
+
+	// eu-west-1 is priced ten times its peers, as if fed another region's data
+	for region, factor := range map[string]string{"us-east-1": "1", "us-west-2": "1.1", "eu-west-1": "10"} {
+		rates, _ := (&passthroughNormalizer{cloud: db.AWS}).Normalize(context.Background(), testRawPrices(region, 3))
+		for i := range rates {
+			rates[i].Price = rates[i].Price.Mul(decimal.RequireFromString(factor))
+			rates[i].RateKey.Attributes["location"] = region
+		}
+		err := restoreBackup(ctx, store, &SnapshotBackup{
+			Provider:      db.AWS,
+			Region:        region,
+			Alias:         "default",
+			ContentHash:   calculateHash(rates),
+			RateCount:     len(rates),
+			SchemaVersion: BackupSchemaVersion,
+			Rates:         rates,
+		})
+		if err != nil {
+			t.Fatalf("failed to seed %s: %v", region, err)
+		}
+	}
+
+	comparison, err := CompareRegions(ctx, store, db.AWS, []string{"us-east-1", "us-west-2", "eu-west-1", "ap-south-1"}, "TestStorage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if comparison.CommonKeys != 3 {
+		t.Errorf("expected the 3 keys to match across regions despite their location, got %d", comparison.CommonKeys)
+	}
+	if got := comparison.Outliers(); !reflect.DeepEqual(got, []string{"eu-west-1"}) {
+		t.Errorf("expected only eu-west-1 to be flagged, got %v (%+v)", got, comparison.Regions)
+	}
+	if !reflect.DeepEqual(comparison.MissingRegions, []string{"ap-south-1"}) {
+		t.Errorf("expected ap-south-1 to be reported missing, got %v", comparison.MissingRegions)
+	}
+
+	if _, err := CompareRegions(ctx, store, db.AWS, []string{"us-east-1", "ap-south-1"}, "TestStorage"); err == nil {
+		t.Error("expected a comparison with one priced region to fail")
+	}
+}
@@ -54,6 +54,14 @@ func (r *FetcherRegistry) RegisterDefaults() {
 	// GCP - Production API client
 	r.fetchers[db.GCP] = NewGCPPricingAPIClient(nil)
 	r.normalizers[db.GCP] = NewGCPPricingNormalizer()
+
+	// OCI - Production API client
+	r.fetchers[db.OCI] = NewOCIPricingAPIFetcher(nil)
+	r.normalizers[db.OCI] = NewOCIPricingNormalizer()
+
+	// DigitalOcean - sizes API plus maintained catalog
+	r.fetchers[db.DigitalOcean] = NewDOPricingFetcher(nil)
+	r.normalizers[db.DigitalOcean] = NewDOPricingNormalizer()
 }
 
 // GetFetcher returns the fetcher for a cloud provider
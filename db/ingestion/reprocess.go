@@ -0,0 +1,53 @@
+// Package ingestion - Re-normalizing stored raw prices without fetching again
+package ingestion
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"terraform-cost/db"
+)
+
+// rawDumpFetcher replays a raw price dump as if it were the original fetch
+type rawDumpFetcher struct {
+	dump *RawPriceDump
+}
+
+func (f *rawDumpFetcher) Cloud() db.CloudProvider     { return f.dump.Provider }
+func (f *rawDumpFetcher) SupportedRegions() []string  { return []string{f.dump.Region} }
+func (f *rawDumpFetcher) SupportedServices() []string { return nil }
+
+// IsRealAPI is true only if the original fetch passed the real API guard
+func (f *rawDumpFetcher) IsRealAPI() bool { return f.dump.RealAPI }
+
+func (f *rawDumpFetcher) FetchRegion(ctx context.Context, region string) ([]RawPrice, error) {
+	if region != f.dump.Region {
+		return nil, fmt.Errorf("raw prices are for region %s, not %s", f.dump.Region, region)
+	}
+	return f.dump.Prices, nil
+}
+
+// ReprocessBackup re-runs normalization, validation, backup and commit on raw
+// prices saved with LifecycleConfig.SaveRaw, e.g. after a normalizer fix. The
+// new backup is written next to the raw file and the snapshot records which
+// raw file it came from. Identical output resolves to the existing snapshot.
+func ReprocessBackup(ctx context.Context, store db.PricingStore, rawPath string, normalizer PriceNormalizer) (*LifecycleResult, error) {
+	dump, err := NewBackupManager().ReadRawPrices(rawPath)
+	if err != nil {
+		return nil, err
+	}
+	if normalizer.Cloud() != dump.Provider {
+		return nil, fmt.Errorf("normalizer is for %s but raw prices are for %s", normalizer.Cloud(), dump.Provider)
+	}
+
+	config := DefaultLifecycleConfig()
+	config.Provider = dump.Provider
+	config.Region = dump.Region
+	config.Alias = dump.Alias
+	config.BackupDir = filepath.Dir(filepath.Dir(rawPath)) // <dir>/<provider>/<file>
+	config.AllowRestrictedRegions = true                   // the original run already checked
+	config.Metadata = map[string]string{"reprocessed_from": filepath.Base(rawPath)}
+
+	return NewLifecycle(&rawDumpFetcher{dump: dump}, normalizer, store).Execute(ctx, config)
+}
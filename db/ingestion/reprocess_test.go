@@ -0,0 +1,81 @@
+// Package ingestion - Raw price reprocessing tests
+package ingestion
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"terraform-cost/db"
+	"terraform-cost/db/memstore"
+)
+
+func TestReprocessBackupFromRawPrices(t *testing.T) {
+	ctx := context.Background()
+	backupDir := t.TempDir()
+	store := memstore.NewMemoryStore()
+
+	config := DefaultLifecycleConfig()
+	config.Provider = db.AWS
+	config.Region = "us-east-1"
+	config.Environment = "development"
+	config.BackupDir = backupDir
+	config.SaveRaw = true
+
+	fetcher := &staticFetcher{cloud: db.AWS, prices: testRawPrices("us-east-1", 5)}
+	first, err := NewLifecycle(fetcher, &passthroughNormalizer{cloud: db.AWS}, store).Execute(ctx, config)
+	if err != nil || !first.Success {
+		t.Fatalf("ingestion failed: %v %+v", err, first)
+	}
+	if first.RawPath != RawPricesPath(first.BackupPath) {
+		t.Fatalf("expected raw prices next to the backup, got %q", first.RawPath)
+	}
+	if backups, _ := NewBackupManager().ListBackups(backupDir); len(backups) != 1 {
+		t.Errorf("raw price files must not be listed as backups, got %+v", backups)
+	}
+
+	// An improved normalizer renames an attribute
+	improved := NewChainNormalizer(&passthroughNormalizer{cloud: db.AWS}, RenameTransform{From: "tier", To: "storage_tier"})
+	second, err := ReprocessBackup(ctx, store, first.RawPath, improved)
+	if err != nil || !second.Success {
+		t.Fatalf("reprocess failed: %v %+v", err, second)
+	}
+	if *second.SnapshotID == *first.SnapshotID || second.RawCount != 5 {
+		t.Errorf("expected a new snapshot from the 5 raw prices, got %+v", second)
+	}
+	if filepath.Dir(second.BackupPath) != filepath.Dir(first.BackupPath) {
+		t.Errorf("expected the new backup next to the raw file, got %s", second.BackupPath)
+	}
+
+	active, _ := store.GetActiveSnapshot(ctx, db.AWS, "us-east-1", "default")
+	if active == nil || active.ID != *second.SnapshotID || active.Metadata["reprocessed_from"] != filepath.Base(first.RawPath) {
+		t.Fatalf("expected the reprocessed snapshot to be active with provenance, got %+v", active)
+	}
+	rates, _ := store.GetRatesBySnapshot(ctx, active.ID)
+	for _, r := range rates {
+		if _, ok := r.RateKey.Attributes["storage_tier"]; !ok {
+			t.Errorf("expected reprocessed rate keys to use the new attribute, got %v", r.RateKey.Attributes)
+		}
+	}
+}
+
+func TestReprocessBackupKeepsProductionGuard(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "aws")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "us-east-1_mock.json.gz")
+	dump := &RawPriceDump{Provider: db.AWS, Region: "us-east-1", Alias: "default", RawCount: 2, Prices: testRawPrices("us-east-1", 2)}
+	rawPath, err := NewBackupManager().WriteRawPrices(path, dump)
+	if err != nil {
+		t.Fatalf("write raw prices: %v", err)
+	}
+
+	// RealAPI is false: the prices came from a mock fetcher
+	result, err := ReprocessBackup(context.Background(), memstore.NewMemoryStore(), rawPath, &passthroughNormalizer{cloud: db.AWS})
+	if err != nil || result.Success || !strings.Contains(result.Error, "not a real API") {
+		t.Errorf("expected mock raw prices to be rejected in production, got %+v (err %v)", result, err)
+	}
+}
@@ -0,0 +1,59 @@
+// Package ingestion - Reverting to a previous pricing snapshot
+package ingestion
+
+import (
+	"context"
+	"fmt"
+
+	"terraform-cost/db"
+
+	"github.com/google/uuid"
+)
+
+// RollbackSnapshot reactivates the most recent snapshot created before the
+// currently active one for cloud/region/alias, deactivating the current one.
+// Failed snapshots are skipped. Repeated rollbacks walk further back through
+// history.
+func RollbackSnapshot(ctx context.Context, store db.PricingStore, cloud db.CloudProvider, region, alias string) (uuid.UUID, error) {
+	current, err := store.GetActiveSnapshot(ctx, cloud, region, alias)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to load active snapshot: %w", err)
+	}
+	if current == nil {
+		return uuid.Nil, fmt.Errorf("no active snapshot for %s/%s/%s", cloud, region, alias)
+	}
+
+	// Newest first
+	snapshots, err := store.ListSnapshots(ctx, cloud, region)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+
+	var target *db.PricingSnapshot
+	for _, s := range snapshots {
+		if s.ProviderAlias != alias || s.IsActive || s.State == string(StateFailed) {
+			continue
+		}
+		if s.CreatedAt.Before(current.CreatedAt) {
+			target = s
+			break
+		}
+	}
+	if target == nil {
+		return uuid.Nil, fmt.Errorf("no earlier snapshot to roll back to for %s/%s/%s", cloud, region, alias)
+	}
+
+	tx, err := store.BeginTx(ctx)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := tx.ActivateSnapshot(ctx, target.ID); err != nil {
+		return uuid.Nil, fmt.Errorf("failed to activate snapshot %s: %w", target.ID, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return uuid.Nil, fmt.Errorf("failed to commit rollback: %w", err)
+	}
+	return target.ID, nil
+}
@@ -0,0 +1,52 @@
+// Package ingestion - Snapshot rollback tests
+package ingestion
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"terraform-cost/db"
+	"terraform-cost/db/memstore"
+)
+
+func TestRollbackSnapshotReactivatesPrevious(t *testing.T) {
+	ctx := context.Background()
+	store := memstore.NewMemoryStore()
+
+	// oldest, previous, bad: each activation supersedes the last
+	var ids []string
+	for _, hash := range []string{"oldest", "previous", "bad"} {
+		snap := db.NewSnapshotBuilder(db.AWS, "us-east-1", "test").Build(hash)
+		if err := store.CreateSnapshot(ctx, snap); err != nil {
+			t.Fatalf("create %s: %v", hash, err)
+		}
+		if err := store.ActivateSnapshot(ctx, snap.ID); err != nil {
+			t.Fatalf("activate %s: %v", hash, err)
+		}
+		ids = append(ids, snap.ID.String())
+		time.Sleep(time.Millisecond) // distinct created_at ordering
+	}
+
+	restored, err := RollbackSnapshot(ctx, store, db.AWS, "us-east-1", "default")
+	if err != nil {
+		t.Fatalf("rollback failed: %v", err)
+	}
+	if restored.String() != ids[1] {
+		t.Fatalf("rolled back to %s, want previous snapshot %s", restored, ids[1])
+	}
+	active, _ := store.GetActiveSnapshot(ctx, db.AWS, "us-east-1", "default")
+	if active == nil || active.ID != restored {
+		t.Errorf("expected %s active, got %+v", restored, active)
+	}
+
+	// A second rollback walks further back instead of returning to the bad snapshot
+	restored, err = RollbackSnapshot(ctx, store, db.AWS, "us-east-1", "default")
+	if err != nil || restored.String() != ids[0] {
+		t.Errorf("second rollback = %s (err %v), want oldest snapshot %s", restored, err, ids[0])
+	}
+
+	if _, err := RollbackSnapshot(ctx, store, db.AWS, "us-east-1", "default"); err == nil {
+		t.Error("expected an error with no earlier snapshot left")
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -15,6 +16,7 @@ import (
 	"time"
 
 	"terraform-cost/db"
+	"terraform-cost/db/regions"
 
 	"github.com/google/uuid"
 )
@@ -31,6 +33,17 @@ type StreamingConfig struct {
 	// Default: 2048 (2GB, safe for 4GB server)
 	MaxMemoryMB int
 
+	// MinBatchSize and MaxBatchSize enable adaptive batching when MaxBatchSize
+	// is set: starting from BatchSize, the batch grows while the heap is well
+	// under TargetMemoryPercent of MaxMemoryMB and shrinks above it.
+	// Default: 0 (fixed BatchSize)
+	MinBatchSize int
+	MaxBatchSize int
+
+	// TargetMemoryPercent is the heap target of adaptive batching as a
+	// percentage of MaxMemoryMB. Default: 70
+	TargetMemoryPercent int
+
 	// WorkDir is where temporary files are stored during processing
 	// Default: system temp directory
 	WorkDir string
@@ -91,6 +104,8 @@ type StreamingLifecycle struct {
 	fetcher     PriceFetcher
 	normalizer  PriceNormalizer
 	store       db.PricingStore
+	logger      *slog.Logger
+	sizer       *BatchSizer
 	
 	// Progress tracking
 	totalFetched    int
@@ -101,6 +116,14 @@ type StreamingLifecycle struct {
 	// Temporary storage
 	tempFiles   []string
 	checkpoint  *IngestionCheckpoint
+
+	// Outcome of the current run, for its validation report
+	startTime   time.Time
+	contentHash string
+	backupPath  string
+	validation  *ValidationResult
+	checks      []ValidationCheck
+	coverage    *CoverageReport
 }
 
 // IngestionCheckpoint tracks progress for resumable ingestion
@@ -132,6 +155,16 @@ func NewStreamingLifecycle(
 	}
 }
 
+// WithLogger sets the structured logger for pipeline events.
+// Progress bars are only drawn when the logger uses a ConsoleHandler.
+func (s *StreamingLifecycle) WithLogger(logger *slog.Logger) *StreamingLifecycle {
+	s.logger = logger
+	if ls, ok := s.fetcher.(loggerSetter); ok {
+		ls.SetLogger(logger)
+	}
+	return s
+}
+
 // Execute runs the streaming ingestion pipeline
 func (s *StreamingLifecycle) Execute(ctx context.Context, config *LifecycleConfig) (*LifecycleResult, error) {
 	s.mu.Lock()
@@ -141,10 +174,24 @@ func (s *StreamingLifecycle) Execute(ctx context.Context, config *LifecycleConfi
 		config = DefaultLifecycleConfig()
 	}
 	s.lcConfig = config
+	s.reset()
 
-	startTime := time.Now()
+	startTime := s.startTime
 	s.logProgress("STARTING", "Initializing streaming ingestion lifecycle...")
 
+	// Raw prices are never held in full, so there is nothing to save
+	if config.SaveRaw {
+		return s.fail(fmt.Errorf("streaming ingestion does not support SaveRaw"), PhaseInit)
+	}
+
+	// The strict lifecycle's guards apply to streaming runs too
+	if err := enforceProductionGuards(config, s.fetcher); err != nil {
+		return s.fail(err, PhaseInit)
+	}
+	if err := ValidateRegion(regions.NewRegistry(), config.Provider, config.Region, config.AllowRestrictedRegions); err != nil {
+		return s.fail(err, PhaseInit)
+	}
+
 	// Check for existing checkpoint
 	if s.config.EnableCheckpointing {
 		if err := s.loadCheckpoint(); err == nil {
@@ -161,81 +208,100 @@ func (s *StreamingLifecycle) Execute(ctx context.Context, config *LifecycleConfi
 
 	s.logProgress("CONFIG", fmt.Sprintf("batch=%d, maxMem=%dMB, concurrency=%d",
 		s.config.BatchSize, s.config.MaxMemoryMB, s.config.ConcurrentFetches))
+	if s.sizer = NewBatchSizer(s.config); s.sizer != nil {
+		s.logProgress("CONFIG", fmt.Sprintf("adaptive batches of %d-%d prices", s.sizer.min, s.sizer.max))
+	}
 
 	// Phase 1: Stream fetch and normalize to temp files
 	s.logPhaseStart(1, 4, "FETCH & NORMALIZE", "Fetching pricing from cloud APIs...")
+	phaseStart := time.Now()
 	if err := s.streamFetchAndNormalize(ctx); err != nil {
 		s.cleanup()
-		return s.fail(err, startTime)
+		return s.fail(err, PhaseNormalizing)
 	}
-	s.logPhaseComplete(1, 4, "FETCH & NORMALIZE", fmt.Sprintf("Fetched %d raw prices", s.totalFetched))
+	s.logPhaseComplete(1, 4, "FETCH & NORMALIZE", s.totalNormalized, phaseStart, fmt.Sprintf("Fetched %d raw prices", s.totalFetched))
 
 	// Phase 2: Merge and validate
 	s.logPhaseStart(2, 4, "MERGE & VALIDATE", "Merging temp files and validating...")
+	phaseStart = time.Now()
 	allRates, err := s.mergeAndValidate(ctx)
 	if err != nil {
 		s.cleanup()
-		return s.fail(err, startTime)
+		return s.fail(err, PhaseValidating)
 	}
-	s.logPhaseComplete(2, 4, "MERGE & VALIDATE", fmt.Sprintf("Validated %d normalized rates", len(allRates)))
+	s.logPhaseComplete(2, 4, "MERGE & VALIDATE", len(allRates), phaseStart, fmt.Sprintf("Validated %d normalized rates", len(allRates)))
 
 	// Phase 3: Backup
 	s.logPhaseStart(3, 4, "BACKUP", "Writing backup file...")
-	backupPath, err := s.writeBackup(allRates)
-	if err != nil {
+	phaseStart = time.Now()
+	if err := s.writeBackup(allRates); err != nil {
 		s.cleanup()
-		return s.fail(fmt.Errorf("backup failed: %w", err), startTime)
+		return s.fail(fmt.Errorf("backup failed: %w", err), PhaseBackedUp)
 	}
-	s.logPhaseComplete(3, 4, "BACKUP", fmt.Sprintf("Backup saved to %s", backupPath))
+	s.logPhaseComplete(3, 4, "BACKUP", len(allRates), phaseStart, fmt.Sprintf("Backup saved to %s", s.backupPath))
 
 	// Phase 4: Commit (if not dry-run)
 	var snapshotID *uuid.UUID
 	if !config.DryRun {
 		s.logPhaseStart(4, 4, "COMMIT", "Committing to database...")
+		phaseStart = time.Now()
 		sid, err := s.streamCommit(ctx, allRates)
 		if err != nil {
 			s.cleanup()
-			return s.fail(fmt.Errorf("commit failed: %w", err), startTime)
+			return s.fail(fmt.Errorf("commit failed: %w", err), PhaseCommitting)
 		}
 		snapshotID = &sid
-		s.logPhaseComplete(4, 4, "COMMIT", fmt.Sprintf("Snapshot %s activated", sid))
+		s.logPhaseComplete(4, 4, "COMMIT", len(allRates), phaseStart, fmt.Sprintf("Snapshot %s activated", sid))
 	} else {
 		s.logProgress("DRY-RUN", "Skipping database commit (dry-run mode)")
 	}
 
+	if !config.DryRun && config.UpdateCoverageBaseline && config.CoverageBaselinePath != "" {
+		updateCoverageBaseline(config, allRates, s.log())
+	}
+
 	// Cleanup
 	s.cleanup()
 	s.deleteCheckpoint()
 
-	s.logProgress("COMPLETE", fmt.Sprintf("Ingestion finished in %s", time.Since(startTime).Round(time.Second)))
+	attrs := []any{"rate_count", len(allRates), "duration", time.Since(startTime)}
+	if snapshotID != nil {
+		attrs = append(attrs, "snapshot_id", snapshotID.String())
+	}
+	s.log().Info("streaming ingestion complete", attrs...)
 
 	return &LifecycleResult{
-		Success:         true,
-		Phase:           PhaseActive,
-		Message:         "streaming ingestion complete",
-		Duration:        time.Since(startTime),
-		SnapshotID:      snapshotID,
-		BackupPath:      backupPath,
-		ContentHash:     calculateHash(allRates),
-		RawCount:        s.totalFetched,
-		NormalizedCount: len(allRates),
+		Success:              true,
+		Phase:                PhaseActive,
+		Message:              "streaming ingestion complete",
+		Duration:             time.Since(startTime),
+		SnapshotID:           snapshotID,
+		BackupPath:           s.backupPath,
+		ValidationReportPath: s.writeValidationReport(PhaseActive, snapshotID, ""),
+		ContentHash:          s.contentHash,
+		RawCount:             s.totalFetched,
+		NormalizedCount:      len(allRates),
 	}, nil
 }
 
+// reset clears the progress and outcome of the previous run
+func (s *StreamingLifecycle) reset() {
+	s.totalFetched = 0
+	s.totalNormalized = 0
+	s.totalWritten = 0
+	s.batchCount = 0
+	s.tempFiles = nil
+	s.checkpoint = nil
+	s.startTime = time.Now()
+	s.contentHash = ""
+	s.backupPath = ""
+	s.validation = nil
+	s.checks = nil
+	s.coverage = nil
+}
+
 // streamFetchAndNormalize fetches pricing in batches and writes to temp files
 func (s *StreamingLifecycle) streamFetchAndNormalize(ctx context.Context) error {
-
-	s.logProgress("FETCHING", "Fetching all pricing data from cloud API...")
-	
-	// Fetch ALL prices once (not per-service to avoid duplication)
-	rawPrices, err := s.fetcher.FetchRegion(ctx, s.lcConfig.Region)
-	if err != nil {
-		return fmt.Errorf("failed to fetch pricing: %w", err)
-	}
-	
-	totalPrices := len(rawPrices)
-	s.logProgress("FETCHED", fmt.Sprintf("Retrieved %d raw prices", totalPrices))
-	
 	// Create temp file for normalized rates
 	tempFile := filepath.Join(s.config.WorkDir, fmt.Sprintf("pricing_%s_%s_%d.jsonl.gz",
 		s.lcConfig.Provider, s.lcConfig.Region, time.Now().UnixNano()))
@@ -250,67 +316,178 @@ func (s *StreamingLifecycle) streamFetchAndNormalize(ctx context.Context) error
 	defer gzw.Close()
 
 	writer := bufio.NewWriter(gzw)
-	
-	s.logProgress("NORMALIZING", fmt.Sprintf("Processing %d prices in batches of %d...", totalPrices, s.config.BatchSize))
+
+	if sf, ok := s.fetcher.(StreamingPriceFetcher); ok {
+		err = s.streamDecoded(ctx, sf, writer)
+	} else {
+		err = s.fetchAllThenBatch(ctx, writer)
+	}
+	if err != nil {
+		return err
+	}
+
+	writer.Flush()
+	runtime.GC()
+
+	s.tempFiles = append(s.tempFiles, tempFile)
+	s.logProgress("NORMALIZED", fmt.Sprintf("Written %d normalized rates to temp file", s.totalNormalized))
+
+	return nil
+}
+
+// streamDecoded normalizes prices in batches as the fetcher decodes them, so
+// at most one batch of raw prices is in memory
+func (s *StreamingLifecycle) streamDecoded(ctx context.Context, fetcher StreamingPriceFetcher, writer *bufio.Writer) error {
+	s.logProgress("FETCHING", fmt.Sprintf("Streaming pricing data in batches of %d...", s.batchSize()))
+
+	// Without an estimate the total is only known once the stream ends
+	estimate := estimatedRateCount(fetcher, s.lcConfig.Region)
+	if estimate > 0 {
+		s.logProgress("ESTIMATE", fmt.Sprintf("~%d prices, ~%d batches", estimate, (estimate+s.batchSize()-1)/s.batchSize()))
+	}
+
+	batch := make([]RawPrice, 0, s.batchSize())
+	batchNum := 0
+	err := fetcher.StreamRegion(ctx, s.lcConfig.Region, func(p RawPrice) error {
+		batch = append(batch, p)
+		if len(batch) < s.batchSize() {
+			return nil
+		}
+		if err := s.writeBatch(ctx, writer, batch, batchNum); err != nil {
+			return err
+		}
+		batch = batch[:0]
+		batchNum++
+		if estimate > 0 {
+			s.logBatch("PROCESSING", "prices (estimated)", s.totalFetched, max(estimate, s.totalFetched))
+		} else {
+			s.logProgress("PROCESSING", fmt.Sprintf("%d prices streamed", s.totalFetched))
+		}
+		return nil
+	})
+	if _, err := s.tolerateMissingServices(nil, err); err != nil {
+		return err
+	}
+	if len(batch) > 0 {
+		if err := s.writeBatch(ctx, writer, batch, batchNum); err != nil {
+			return err
+		}
+	}
+
+	s.logProgress("FETCHED", fmt.Sprintf("Streamed %d raw prices", s.totalFetched))
+	return nil
+}
+
+// fetchAllThenBatch fetches the whole region, then normalizes it in batches
+func (s *StreamingLifecycle) fetchAllThenBatch(ctx context.Context, writer *bufio.Writer) error {
+	s.logProgress("FETCHING", "Fetching all pricing data from cloud API...")
+
+	// Fetch ALL prices once (not per-service to avoid duplication)
+	rawPrices, err := s.tolerateMissingServices(s.fetcher.FetchRegion(ctx, s.lcConfig.Region))
+	if err != nil {
+		return err
+	}
+
+	totalPrices := len(rawPrices)
+	s.logProgress("FETCHED", fmt.Sprintf("Retrieved %d raw prices", totalPrices))
+	s.logProgress("NORMALIZING", fmt.Sprintf("Processing %d prices in batches of %d...", totalPrices, s.batchSize()))
 
 	// Process in batches to control memory
 	batchNum := 0
-	for i := 0; i < len(rawPrices); i += s.config.BatchSize {
-		end := i + s.config.BatchSize
+	for i, end := 0, 0; i < len(rawPrices); i = end {
+		end = i + s.batchSize()
 		if end > len(rawPrices) {
 			end = len(rawPrices)
 		}
 
-		batch := rawPrices[i:end]
-
-		// Normalize batch
-		normalized, err := s.normalizer.Normalize(batch)
-		if err != nil {
-			s.logProgress("WARNING", fmt.Sprintf("Batch %d normalization error: %v", batchNum, err))
-			continue
+		if err := s.writeBatch(ctx, writer, rawPrices[i:end], batchNum); err != nil {
+			return err
 		}
+		batchNum++
 
-		// Write to temp file (JSON Lines format)
-		for _, rate := range normalized {
-			data, err := json.Marshal(rate)
-			if err != nil {
-				continue
-			}
-			writer.Write(data)
-			writer.WriteString("\n")
-			s.totalNormalized++
-		}
+		s.logBatch("PROCESSING", "prices", end, totalPrices)
+	}
+	return nil
+}
 
-		s.totalFetched += len(batch)
-		batchNum++
+// tolerateMissingServices applies the minimum-services guard to a fetch
+// result, logging a tolerated partial fetch the way Lifecycle does
+func (s *StreamingLifecycle) tolerateMissingServices(rawPrices []RawPrice, err error) ([]RawPrice, error) {
+	rawPrices, partial, err := applyMinServices(rawPrices, err, s.lcConfig.MinServicesFraction)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch pricing: %w", err)
+	}
+	if partial != nil {
+		s.log().Warn("proceeding with partial fetch", "failed_services", len(partial.Failed), "expected_services", partial.Expected, "error", partial)
+	}
+	return rawPrices, nil
+}
 
-		// Progress update
-		progress := float64(i+len(batch)) / float64(totalPrices) * 100
-		s.logProgress("PROCESSING", fmt.Sprintf("%s %d/%d prices (%.1f%%)", s.progressBar(progress), i+len(batch), totalPrices, progress))
+// writeBatch normalizes a batch of raw prices and appends them to the temp file
+func (s *StreamingLifecycle) writeBatch(ctx context.Context, writer *bufio.Writer, batch []RawPrice, batchNum int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 
-		// Memory management - flush and GC
-		if batchNum%s.config.GCInterval == 0 {
-			writer.Flush()
-			s.checkMemoryAndGC()
+	// Normalize batch
+	normalizer := sourceNormalizer(s.fetcher, s.normalizer, s.lcConfig.stubConfidence())
+	normalized, err := normalizer.Normalize(ctx, batch)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
 		}
+		s.log().Warn("batch normalization failed", "batch", batchNum, "error", err)
+		return nil
+	}
+	if n := RoundDecimalScale(normalized, PriceColumnScale); n > 0 {
+		s.log().Warn("rounded prices to column scale", "batch", batchNum, "count", n, "scale", PriceColumnScale)
 	}
 
-	writer.Flush()
-	
-	// Clear raw prices from memory
-	rawPrices = nil
-	runtime.GC()
-	
-	s.tempFiles = append(s.tempFiles, tempFile)
-	s.logProgress("NORMALIZED", fmt.Sprintf("Written %d normalized rates to temp file", s.totalNormalized))
+	// Write to temp file (JSON Lines format)
+	for _, rate := range normalized {
+		data, err := json.Marshal(rate)
+		if err != nil {
+			continue
+		}
+		writer.Write(data)
+		writer.WriteString("\n")
+		s.totalNormalized++
+	}
+
+	s.totalFetched += len(batch)
 
+	// Memory management - flush and GC
+	if (batchNum+1)%s.config.GCInterval == 0 {
+		writer.Flush()
+		s.checkMemoryAndGC()
+	}
+	s.adaptBatchSize(batchNum)
 	return nil
 }
 
+// batchSize returns the size of the next batch: the adaptive size when
+// enabled, else the configured BatchSize
+func (s *StreamingLifecycle) batchSize() int {
+	if s.sizer != nil {
+		return s.sizer.Size()
+	}
+	return s.config.BatchSize
+}
+
+// adaptBatchSize resizes the next batch from the memory used by this one
+func (s *StreamingLifecycle) adaptBatchSize(batchNum int) {
+	if s.sizer == nil {
+		return
+	}
+	previous := s.sizer.Size()
+	if next := s.sizer.Observe(); next != previous {
+		s.log().Debug("adjusted batch size", "batch", batchNum, "from", previous, "to", next)
+	}
+}
+
 // fetchServicePricing fetches pricing for a service (uses streaming internally)
 func (s *StreamingLifecycle) fetchServicePricing(ctx context.Context, service string) ([]RawPrice, error) {
-	// For now, delegate to fetcher
-	// In future, implement streaming JSON parsing for very large responses
+	// Fetchers that decode incrementally are used through StreamRegion instead
 	return s.fetcher.FetchRegion(ctx, s.lcConfig.Region)
 }
 
@@ -323,21 +500,29 @@ func (s *StreamingLifecycle) mergeAndValidate(ctx context.Context) ([]Normalized
 		s.logProgress("READING", fmt.Sprintf("[%d/%d] Processing temp file...", i+1, totalFiles))
 		rates, err := s.readTempFile(tempFile)
 		if err != nil {
-			s.logProgress("WARNING", fmt.Sprintf("Failed to read temp file: %v", err))
+			s.log().Warn("failed to read temp file", "path", tempFile, "error", err)
 			continue
 		}
 		allRates = append(allRates, rates...)
 		s.logProgress("MERGED", fmt.Sprintf("Loaded %d rates (total: %d)", len(rates), len(allRates)))
 	}
 
-	s.logProgress("VALIDATING", fmt.Sprintf("Validating %d total rates...", len(allRates)))
+	allRates = dropFutureRates(s.lcConfig, allRates, s.log())
+	if len(allRates) == 0 {
+		return nil, fmt.Errorf("normalization produced 0 rates")
+	}
 
-	// Validate
-	validator := NewIngestionValidator()
-	validator.SetMinCoveragePercent(s.lcConfig.MinCoverage)
+	s.logProgress("VALIDATING", fmt.Sprintf("Validating %d total rates...", len(allRates)))
 
-	if err := validator.ValidateAll(allRates, 0); err != nil {
-		return nil, fmt.Errorf("validation failed: %w", err)
+	// The same checks as the batch lifecycle, recorded for the validation report
+	result, checks, err := validateRates(ctx, s.store, NewIngestionValidator(), s.lcConfig, allRates, s.log())
+	s.validation = result
+	s.checks = checks
+	s.coverage = NewCoverageTracker().GenerateReport(
+		&db.PricingSnapshot{Cloud: s.lcConfig.Provider, Region: s.lcConfig.Region}, allRates)
+	s.contentHash = calculateHash(allRates)
+	if err != nil {
+		return nil, err
 	}
 
 	return allRates, nil
@@ -375,37 +560,48 @@ func (s *StreamingLifecycle) readTempFile(path string) ([]NormalizedRate, error)
 	return rates, scanner.Err()
 }
 
-// writeBackup writes the final backup
-func (s *StreamingLifecycle) writeBackup(rates []NormalizedRate) (string, error) {
-	fmt.Println("\nPhase 3: Write backup...")
-
+// writeBackup writes the final backup and reads it back to verify it
+func (s *StreamingLifecycle) writeBackup(rates []NormalizedRate) error {
 	backup := &SnapshotBackup{
 		Provider:      s.lcConfig.Provider,
 		Region:        s.lcConfig.Region,
 		Alias:         s.lcConfig.Alias,
 		Timestamp:     time.Now(),
-		ContentHash:   calculateHash(rates),
+		ContentHash:   s.contentHash,
 		RateCount:     len(rates),
-		SchemaVersion: "1.0",
+		SchemaVersion: BackupSchemaVersion,
 		Rates:         rates,
 	}
 
-	backupMgr := NewBackupManager()
+	backupMgr := NewBackupManager().WithCompression(s.lcConfig.BackupCompression)
 	path, err := backupMgr.WriteBackup(s.lcConfig.BackupDir, backup)
 	if err != nil {
-		return "", err
+		return err
 	}
+	if err := verifyBackup(backupMgr, path, s.contentHash); err != nil {
+		return fmt.Errorf("backup verification failed: %w", err)
+	}
+	s.backupPath = path
 
-	fmt.Printf("  ✓ Backup written: %s\n", path)
-	return path, nil
+	s.logProgress("BACKUP", fmt.Sprintf("Backup written: %s", path))
+	return nil
 }
 
-// streamCommit commits rates in batches to reduce memory
+// streamCommit commits rates in batches to reduce memory, unless the same
+// content is already ingested, retrying on serialization failures
 func (s *StreamingLifecycle) streamCommit(ctx context.Context, rates []NormalizedRate) (uuid.UUID, error) {
-	totalRates := len(rates)
-	s.logProgress("COMMIT", fmt.Sprintf("Starting database commit of %d rates...", totalRates))
+	s.logProgress("COMMIT", fmt.Sprintf("Starting database commit of %d rates...", len(rates)))
 
-	snapshotID := uuid.New()
+	return commitSnapshot(ctx, s.store, s.lcConfig, s.contentHash, s.log(), func(ctx context.Context) (uuid.UUID, error) {
+		return s.commitBatches(ctx, rates)
+	})
+}
+
+// commitBatches writes and activates the snapshot in one transaction, under a
+// fresh snapshot ID for each attempt
+func (s *StreamingLifecycle) commitBatches(ctx context.Context, rates []NormalizedRate) (uuid.UUID, error) {
+	contentHash := s.contentHash
+	snapshotID := newSnapshotID(s.lcConfig.DeterministicIDs, s.lcConfig.Provider, s.lcConfig.Region, s.lcConfig.Alias, contentHash)
 	snapshot := &db.PricingSnapshot{
 		ID:            snapshotID,
 		Cloud:         s.lcConfig.Provider,
@@ -414,11 +610,13 @@ func (s *StreamingLifecycle) streamCommit(ctx context.Context, rates []Normalize
 		Source:        "streaming_ingestion",
 		FetchedAt:     time.Now(),
 		ValidFrom:     time.Now(),
-		Hash:          calculateHash(rates),
+		Hash:          contentHash,
 		Version:       "1.0",
 		IsActive:      false,
+		Metadata:      s.lcConfig.Metadata,
 	}
 
+	s.totalWritten = 0
 	tx, err := s.store.BeginTx(ctx)
 	if err != nil {
 		return uuid.Nil, err
@@ -443,6 +641,9 @@ func (s *StreamingLifecycle) streamCommit(ctx context.Context, rates []Normalize
 			end = len(rates)
 		}
 
+		if err := ctx.Err(); err != nil {
+			return uuid.Nil, fmt.Errorf("commit cancelled after %d rates: %w", i, err)
+		}
 		for _, nr := range rates[i:end] {
 			nr.RateKey.ID = uuid.New()
 			key, err := tx.UpsertRateKey(ctx, &nr.RateKey)
@@ -460,6 +661,9 @@ func (s *StreamingLifecycle) streamCommit(ctx context.Context, rates []Normalize
 				Confidence: nr.Confidence,
 				TierMin:    nr.TierMin,
 				TierMax:    nr.TierMax,
+
+				EffectiveDate: nr.EffectiveDate,
+				Labels:        nr.Labels,
 			}
 			if err = tx.CreateRate(ctx, rate); err != nil {
 				return uuid.Nil, err
@@ -467,8 +671,7 @@ func (s *StreamingLifecycle) streamCommit(ctx context.Context, rates []Normalize
 		}
 
 		s.totalWritten += (end - i)
-		progress := float64(s.totalWritten) / float64(len(rates)) * 100
-		s.logProgress("WRITING", fmt.Sprintf("%s %d/%d rates (%.1f%%)", s.progressBar(progress), s.totalWritten, len(rates), progress))
+		s.logBatch("WRITING", "rates", s.totalWritten, len(rates))
 
 		// GC between batches
 		if (i/batchSize)%s.config.GCInterval == 0 {
@@ -485,7 +688,7 @@ func (s *StreamingLifecycle) streamCommit(ctx context.Context, rates []Normalize
 	}
 	committed = true
 
-	fmt.Printf("  ✓ Snapshot activated: %s\n", snapshotID)
+	s.logProgress("ACTIVATED", fmt.Sprintf("Snapshot activated: %s", snapshotID))
 	return snapshotID, nil
 }
 
@@ -496,30 +699,62 @@ func (s *StreamingLifecycle) checkMemoryAndGC() {
 
 	usedMB := m.Alloc / 1024 / 1024
 	if int(usedMB) > s.config.MaxMemoryMB*80/100 { // 80% threshold
-		s.logProgress("MEMORY", fmt.Sprintf("Usage: %dMB (threshold: %dMB) - triggering GC", usedMB, s.config.MaxMemoryMB))
+		s.log().Debug("memory threshold reached, triggering GC", "used_mb", usedMB, "max_mb", s.config.MaxMemoryMB)
 		runtime.GC()
 	}
 }
 
-// logProgress prints a timestamped progress message
+// log returns the pipeline logger scoped to the current run
+func (s *StreamingLifecycle) log() *slog.Logger {
+	logger := loggerOrDefault(s.logger)
+	if s.lcConfig != nil {
+		logger = logger.With("provider", string(s.lcConfig.Provider), "region", s.lcConfig.Region, "alias", s.lcConfig.Alias)
+	}
+	return logger
+}
+
+// logProgress prints a timestamped progress line in console mode,
+// and a debug event otherwise
 func (s *StreamingLifecycle) logProgress(stage, message string) {
-	timestamp := time.Now().Format("15:04:05")
-	fmt.Printf("[%s] %-12s │ %s\n", timestamp, stage, message)
+	if w, ok := consoleWriter(loggerOrDefault(s.logger)); ok {
+		timestamp := time.Now().Format("15:04:05")
+		fmt.Fprintf(w, "[%s] %-12s │ %s\n", timestamp, stage, message)
+		return
+	}
+	s.log().Debug(message, "stage", stage)
 }
 
-// logPhaseStart prints a phase start banner
+// logBatch reports batch progress, as a progress bar in console mode
+func (s *StreamingLifecycle) logBatch(stage, what string, done, total int) {
+	if _, ok := consoleWriter(loggerOrDefault(s.logger)); ok {
+		progress := float64(done) / float64(total) * 100
+		s.logProgress(stage, fmt.Sprintf("%s %d/%d %s (%.1f%%)", s.progressBar(progress), done, total, what, progress))
+		return
+	}
+	s.log().Debug("batch processed", "stage", stage, "done", done, "total", total)
+}
+
+// logPhaseStart prints a phase start banner in console mode
 func (s *StreamingLifecycle) logPhaseStart(current, total int, name, description string) {
-	timestamp := time.Now().Format("15:04:05")
-	fmt.Printf("\n[%s] ══════════════════════════════════════════════════════════\n", timestamp)
-	fmt.Printf("[%s] PHASE %d/%d: %s\n", timestamp, current, total, name)
-	fmt.Printf("[%s] %s\n", timestamp, description)
-	fmt.Printf("[%s] ══════════════════════════════════════════════════════════\n", timestamp)
+	if w, ok := consoleWriter(loggerOrDefault(s.logger)); ok {
+		timestamp := time.Now().Format("15:04:05")
+		fmt.Fprintf(w, "\n[%s] ══════════════════════════════════════════════════════════\n", timestamp)
+		fmt.Fprintf(w, "[%s] PHASE %d/%d: %s\n", timestamp, current, total, name)
+		fmt.Fprintf(w, "[%s] %s\n", timestamp, description)
+		fmt.Fprintf(w, "[%s] ══════════════════════════════════════════════════════════\n", timestamp)
+		return
+	}
+	s.log().Info("phase started", "phase", name)
 }
 
-// logPhaseComplete prints a phase completion message
-func (s *StreamingLifecycle) logPhaseComplete(current, total int, name, result string) {
-	timestamp := time.Now().Format("15:04:05")
-	fmt.Printf("[%s] ✓ PHASE %d/%d COMPLETE: %s\n", timestamp, current, total, result)
+// logPhaseComplete records phase completion with its rate count and duration
+func (s *StreamingLifecycle) logPhaseComplete(current, total int, name string, rateCount int, start time.Time, result string) {
+	if w, ok := consoleWriter(loggerOrDefault(s.logger)); ok {
+		timestamp := time.Now().Format("15:04:05")
+		fmt.Fprintf(w, "[%s] ✓ PHASE %d/%d COMPLETE: %s\n", timestamp, current, total, result)
+		return
+	}
+	s.log().Info("phase complete", "phase", name, "rate_count", rateCount, "duration", time.Since(start))
 }
 
 // progressBar generates a progress bar string
@@ -606,13 +841,58 @@ func (s *StreamingLifecycle) markServiceCompleted(service string) {
 	s.saveCheckpoint()
 }
 
-func (s *StreamingLifecycle) fail(err error, startTime time.Time) (*LifecycleResult, error) {
+// fail logs the failed run and writes its validation report once
+// validation has run
+func (s *StreamingLifecycle) fail(err error, phase IngestionPhase) (*LifecycleResult, error) {
+	s.log().Error("streaming ingestion failed",
+		"phase", phase.String(),
+		"rate_count", s.totalNormalized,
+		"duration", time.Since(s.startTime),
+		"error", err)
+
 	return &LifecycleResult{
-		Success:         false,
-		Phase:           PhaseFailed,
-		Error:           err.Error(),
-		Duration:        time.Since(startTime),
-		RawCount:        s.totalFetched,
-		NormalizedCount: s.totalNormalized,
+		Success:              false,
+		Phase:                PhaseFailed,
+		Error:                err.Error(),
+		Duration:             time.Since(s.startTime),
+		BackupPath:           s.backupPath,
+		ValidationReportPath: s.writeValidationReport(phase, nil, err.Error()),
+		RawCount:             s.totalFetched,
+		NormalizedCount:      s.totalNormalized,
 	}, nil
 }
+
+// writeValidationReport persists the validation outcome next to the backup,
+// as Lifecycle does, returning its path. A write failure is logged rather
+// than failing the run, which may already be committed.
+func (s *StreamingLifecycle) writeValidationReport(phase IngestionPhase, snapshotID *uuid.UUID, errMsg string) string {
+	if s.validation == nil {
+		return ""
+	}
+	if s.coverage != nil && snapshotID != nil {
+		s.coverage.SnapshotID = *snapshotID
+	}
+
+	path, err := NewBackupManager().WriteValidationReport(s.lcConfig.BackupDir, s.backupPath, &ValidationReport{
+		Provider:    s.lcConfig.Provider,
+		Region:      s.lcConfig.Region,
+		Alias:       s.lcConfig.Alias,
+		Environment: s.lcConfig.Environment,
+		Success:     errMsg == "",
+		Phase:       phase.String(),
+		Error:       errMsg,
+		SnapshotID:  snapshotID,
+		ContentHash: s.contentHash,
+		RateCount:   s.coverage.TotalRates,
+		StartedAt:   s.startTime,
+		FinishedAt:  time.Now(),
+		Checks:      s.checks,
+		Validation:  s.validation,
+		Coverage:    s.coverage,
+	})
+	if err != nil {
+		s.log().Warn("validation report not written", "error", err)
+		return ""
+	}
+	return path
+}
@@ -0,0 +1,131 @@
+// Package ingestion - Streaming lifecycle tests
+package ingestion
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"terraform-cost/db"
+	"terraform-cost/db/memstore"
+)
+
+func streamingTestConfig(t *testing.T) *LifecycleConfig {
+	t.Helper()
+	config := DefaultLifecycleConfig()
+	config.Provider = db.AWS
+	config.Region = "us-east-1"
+	config.Environment = "development"
+	config.BackupDir = t.TempDir()
+	return config
+}
+
+func newTestStreamingLifecycle(t *testing.T, fetcher PriceFetcher, store db.PricingStore) *StreamingLifecycle {
+	t.Helper()
+	streamCfg := DefaultStreamingConfig()
+	streamCfg.WorkDir = t.TempDir()
+	streamCfg.BatchSize = 2
+	return NewStreamingLifecycle(fetcher, &passthroughNormalizer{cloud: db.AWS}, store, streamCfg)
+}
+
+func TestStreamingEnforcesLifecycleGuards(t *testing.T) {
+	tests := []struct {
+		name      string
+		fetcher   PriceFetcher
+		configure func(*LifecycleConfig)
+		wantErr   string
+	}{
+		{
+			name:      "mock pricing in production",
+			fetcher:   &staticFetcher{cloud: db.AWS, prices: testRawPrices("us-east-1", 3)},
+			configure: func(c *LifecycleConfig) { c.Environment = "production"; c.AllowMockPricing = true },
+			wantErr:   "mock pricing forbidden",
+		},
+		{
+			name:      "stub fetcher in production",
+			fetcher:   &stubFetcher{staticFetcher{cloud: db.AWS, prices: testRawPrices("us-east-1", 3)}},
+			configure: func(c *LifecycleConfig) { c.Environment = "production" },
+			wantErr:   "not a real API",
+		},
+		{
+			name:      "unknown region",
+			fetcher:   &staticFetcher{cloud: db.AWS, prices: testRawPrices("us-east-1", 3)},
+			configure: func(c *LifecycleConfig) { c.Region = "mars-north-1" },
+			wantErr:   "mars-north-1",
+		},
+		{
+			name:      "raw prices",
+			fetcher:   &staticFetcher{cloud: db.AWS, prices: testRawPrices("us-east-1", 3)},
+			configure: func(c *LifecycleConfig) { c.SaveRaw = true },
+			wantErr:   "SaveRaw",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := streamingTestConfig(t)
+			tt.configure(config)
+
+			result, _ := newTestStreamingLifecycle(t, tt.fetcher, memstore.NewMemoryStore()).Execute(context.Background(), config)
+			if result.Success || !strings.Contains(result.Error, tt.wantErr) {
+				t.Fatalf("expected failure mentioning %q, got %+v", tt.wantErr, result)
+			}
+			if result.RawCount != 0 {
+				t.Errorf("expected the run to stop before fetching, got %d raw prices", result.RawCount)
+			}
+		})
+	}
+}
+
+func TestStreamingReingestIsIdempotent(t *testing.T) {
+	store := memstore.NewMemoryStore()
+	fetcher := &staticFetcher{cloud: db.AWS, prices: testRawPrices("us-east-1", 5)}
+	sl := newTestStreamingLifecycle(t, fetcher, store)
+
+	first, _ := sl.Execute(context.Background(), streamingTestConfig(t))
+	if !first.Success {
+		t.Fatalf("first ingestion failed: %s", first.Error)
+	}
+
+	// Unchanged prices find the existing snapshot instead of inserting a duplicate
+	second, _ := sl.Execute(context.Background(), streamingTestConfig(t))
+	if !second.Success {
+		t.Fatalf("re-ingesting unchanged prices failed: %s", second.Error)
+	}
+	if *second.SnapshotID != *first.SnapshotID {
+		t.Errorf("expected snapshot %s to be reused, got %s", first.SnapshotID, second.SnapshotID)
+	}
+
+	// Counters start from zero on each run
+	if second.RawCount != 5 || second.NormalizedCount != 5 {
+		t.Errorf("expected 5 raw and 5 normalized prices on the second run, got %d and %d", second.RawCount, second.NormalizedCount)
+	}
+}
+
+func TestStreamingRunsLifecycleValidationChecks(t *testing.T) {
+	config := streamingTestConfig(t)
+	config.RequireRegionRates = true
+	var got *ValidationResult
+	config.OnValidationFailure = func(r *ValidationResult) { got = r }
+
+	// Another region's prices must not land under us-east-1
+	fetcher := &staticFetcher{cloud: db.AWS, prices: testRawPrices("eu-west-1", 5)}
+	result, _ := newTestStreamingLifecycle(t, fetcher, memstore.NewMemoryStore()).Execute(context.Background(), config)
+	if result.Success {
+		t.Fatal("expected the region distribution check to fail")
+	}
+	if got == nil || got.IsValid {
+		t.Fatalf("expected OnValidationFailure to receive an invalid result, got %+v", got)
+	}
+
+	if result.ValidationReportPath == "" {
+		t.Fatal("expected a validation report for the failed run")
+	}
+	report, err := NewBackupManager().ReadValidationReport(result.ValidationReportPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last := report.Checks[len(report.Checks)-1]; last.Name != "region_distribution" || last.Passed {
+		t.Errorf("expected region_distribution to be the failing check, got %+v", last)
+	}
+}
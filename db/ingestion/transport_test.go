@@ -0,0 +1,82 @@
+// Package ingestion - Outbound HTTP transport tests
+package ingestion
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestTransportSendsUserAgentThroughProxy(t *testing.T) {
+	var mu sync.Mutex
+	var agents, hosts []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		agents = append(agents, r.Header.Get("User-Agent"))
+		hosts = append(hosts, r.URL.Host)
+		mu.Unlock()
+		http.NotFound(w, r)
+	}))
+	defer proxy.Close()
+
+	transport, err := TransportConfig{ProxyURL: proxy.URL, UserAgent: "terracost/1.0 (corp)"}.NewTransport()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fetcher := NewAWSPricingAPIFetcher()
+	fetcher.baseURL = "http://pricing.example.invalid"
+	fetcher.SetAllowedServices([]string{"AmazonEC2"})
+	fetcher.SetTransport(transport)
+
+	// The proxy answers 404, so the fetch fails after one request
+	fetcher.FetchRegion(context.Background(), "us-east-1")
+
+	if len(agents) == 0 {
+		t.Fatal("expected the request to go through the proxy")
+	}
+	for i := range agents {
+		if agents[i] != "terracost/1.0 (corp)" || hosts[i] != "pricing.example.invalid" {
+			t.Errorf("request %d: User-Agent %q to %s, want the configured agent to pricing.example.invalid", i, agents[i], hosts[i])
+		}
+	}
+	if fetcher.httpClient.Timeout == 0 {
+		t.Error("expected SetTransport to keep the client timeout")
+	}
+}
+
+func TestTransportTrustsCABundle(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	bundle := filepath.Join(t.TempDir(), "ca.pem")
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(bundle, cert, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := (&http.Client{}).Get(server.URL); err == nil {
+		t.Fatal("expected the test certificate to be untrusted by default")
+	}
+	transport, err := TransportConfig{CABundlePath: bundle, TLSMinVersion: tls.VersionTLS12}.NewTransport()
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+	if err != nil {
+		t.Fatalf("expected the CA bundle to be trusted: %v", err)
+	}
+	resp.Body.Close()
+
+	if _, err := (TransportConfig{CABundlePath: filepath.Join(t.TempDir(), "missing.pem")}).NewTransport(); err == nil {
+		t.Error("expected a missing CA bundle to fail")
+	}
+	if _, err := ParseTLSVersion("1.4"); err == nil {
+		t.Error("expected an unknown TLS version to fail")
+	}
+}
@@ -0,0 +1,111 @@
+// Package ingestion - Incremental updates of selected services
+package ingestion
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"terraform-cost/db"
+)
+
+// serviceFilterable is a fetcher that can fetch a subset of its services
+type serviceFilterable interface {
+	SetAllowedServices(services []string)
+}
+
+// serviceMergeNormalizer normalizes the re-fetched services and adds the
+// carried-over rates of every other service
+type serviceMergeNormalizer struct {
+	inner    PriceNormalizer
+	services map[string]bool
+	carried  []NormalizedRate
+}
+
+func (n *serviceMergeNormalizer) Cloud() db.CloudProvider {
+	return n.inner.Cloud()
+}
+
+func (n *serviceMergeNormalizer) Normalize(ctx context.Context, raw []RawPrice) ([]NormalizedRate, error) {
+	fetched, err := n.inner.Normalize(ctx, raw)
+	if err != nil {
+		return nil, err
+	}
+	rates := make([]NormalizedRate, 0, len(fetched)+len(n.carried))
+	for _, r := range fetched {
+		if n.services[r.RateKey.Service] {
+			rates = append(rates, r)
+		}
+	}
+	if len(rates) == 0 {
+		return nil, fmt.Errorf("fetch returned no rates for %s", strings.Join(sortedServiceNames(n.services), ", "))
+	}
+	return append(rates, n.carried...), nil
+}
+
+// UpdateServices re-fetches only services and commits a new snapshot holding
+// their new rates plus every other service's rates copied from the active
+// snapshot, so coverage is kept without fetching the whole catalog. The
+// fetcher must support SetAllowedServices and is left restricted to services.
+// The new snapshot goes through the full lifecycle: validation, backup and
+// commit.
+func (l *Lifecycle) UpdateServices(ctx context.Context, config *LifecycleConfig, services []string) (*LifecycleResult, error) {
+	if len(services) == 0 {
+		return nil, fmt.Errorf("no services to update")
+	}
+	if config == nil {
+		config = DefaultLifecycleConfig()
+	}
+	filterable, ok := l.fetcher.(serviceFilterable)
+	if !ok {
+		return nil, fmt.Errorf("fetcher for %s cannot fetch selected services", l.fetcher.Cloud())
+	}
+
+	active, err := l.store.GetActiveSnapshot(ctx, config.Provider, config.Region, config.Alias)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active snapshot: %w", err)
+	}
+	if active == nil {
+		return nil, fmt.Errorf("no active snapshot for %s/%s/%s to update; run a full ingestion first", config.Provider, config.Region, config.Alias)
+	}
+	stored, err := l.store.GetRatesBySnapshot(ctx, active.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load active snapshot rates: %w", err)
+	}
+
+	updated := make(map[string]bool, len(services))
+	for _, s := range services {
+		updated[s] = true
+	}
+	var carried []NormalizedRate
+	for _, r := range RatesFromSnapshot(stored) {
+		if !updated[r.RateKey.Service] {
+			carried = append(carried, r)
+		}
+	}
+	filterable.SetAllowedServices(services)
+
+	cfg := *config
+	cfg.Metadata = make(map[string]string, len(config.Metadata)+2)
+	for k, v := range config.Metadata {
+		cfg.Metadata[k] = v
+	}
+	cfg.Metadata["updated_services"] = strings.Join(sortedServiceNames(updated), ",")
+	cfg.Metadata["updated_from"] = active.ID.String()
+
+	update := NewLifecycle(l.fetcher, &serviceMergeNormalizer{inner: l.normalizer, services: updated, carried: carried}, l.store)
+	update.validator = l.validator
+	update.backupMgr = l.backupMgr
+	update.logger = l.logger
+	return update.Execute(ctx, &cfg)
+}
+
+func sortedServiceNames(services map[string]bool) []string {
+	names := make([]string, 0, len(services))
+	for s := range services {
+		names = append(names, s)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,125 @@
+// Package ingestion - Incremental service update tests
+package ingestion
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"terraform-cost/db"
+	"terraform-cost/db/memstore"
+
+	"github.com/shopspring/decimal"
+)
+
+// serviceFetcher serves prices per service, honouring SetAllowedServices
+type serviceFetcher struct {
+	prices  map[string][]RawPrice
+	allowed []string
+}
+
+func (f *serviceFetcher) Cloud() db.CloudProvider     { return db.AWS }
+func (f *serviceFetcher) SupportedRegions() []string  { return nil }
+func (f *serviceFetcher) SupportedServices() []string { return nil }
+func (f *serviceFetcher) SetAllowedServices(services []string) {
+	f.allowed = services
+}
+func (f *serviceFetcher) FetchRegion(ctx context.Context, region string) ([]RawPrice, error) {
+	var prices []RawPrice
+	for service, p := range f.prices {
+		if f.allowed == nil || contains(f.allowed, service) {
+			prices = append(prices, p...)
+		}
+	}
+	return prices, nil
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func lambdaRawPrices(price string) []RawPrice {
+	prices := make([]RawPrice, 3)
+	for i := range prices {
+		prices[i] = RawPrice{
+			SKU:           fmt.Sprintf("LAMBDA%d", i),
+			ServiceCode:   "AWSLambda",
+			ProductFamily: "Serverless",
+			Region:        "us-east-1",
+			Unit:          "Lambda-GB-Second",
+			PricePerUnit:  price,
+			Currency:      "USD",
+			Attributes:    map[string]string{"arch": []string{"x86", "arm", "any"}[i]},
+		}
+	}
+	return prices
+}
+
+func TestLifecycleUpdateServicesCarriesOtherServices(t *testing.T) {
+	ctx := context.Background()
+	store := memstore.NewMemoryStore()
+	config := DefaultLifecycleConfig()
+	config.Provider = db.AWS
+	config.Region = "us-east-1"
+	config.Environment = "development"
+	config.BackupDir = t.TempDir()
+
+	fetcher := &serviceFetcher{prices: map[string][]RawPrice{
+		"TestStorage": testRawPrices("us-east-1", 5),
+		"AWSLambda":   lambdaRawPrices("0.0000166667"),
+	}}
+	lifecycle := NewLifecycle(fetcher, &passthroughNormalizer{cloud: db.AWS}, store)
+	full, err := lifecycle.Execute(ctx, config)
+	if err != nil || !full.Success {
+		t.Fatalf("full ingestion failed: %v %+v", err, full)
+	}
+
+	// Only Lambda changed upstream; storage would fail if fetched again
+	fetcher.prices["AWSLambda"] = lambdaRawPrices("0.0000133334")
+	fetcher.prices["TestStorage"] = nil
+	result, err := lifecycle.UpdateServices(ctx, config, []string{"AWSLambda"})
+	if err != nil || !result.Success {
+		t.Fatalf("update failed: %v %+v", err, result)
+	}
+	if !contains(fetcher.allowed, "AWSLambda") || len(fetcher.allowed) != 1 {
+		t.Errorf("expected only AWSLambda to be fetched, allowed %v", fetcher.allowed)
+	}
+	if result.SnapshotID == nil || *result.SnapshotID == *full.SnapshotID || result.NormalizedCount != full.NormalizedCount {
+		t.Fatalf("expected a new snapshot with the same %d rates, got %s with %d", full.NormalizedCount, result.SnapshotID, result.NormalizedCount)
+	}
+
+	before, _ := store.GetRatesBySnapshot(ctx, *full.SnapshotID)
+	after, _ := store.GetRatesBySnapshot(ctx, *result.SnapshotID)
+	storage := make(map[string]decimal.Decimal)
+	for _, r := range before {
+		if r.RateKey.Service == "TestStorage" {
+			storage[r.RateKey.Attributes["tier"]] = r.Rate.Price
+		}
+	}
+	for _, r := range after {
+		switch r.RateKey.Service {
+		case "TestStorage":
+			if !r.Rate.Price.Equal(storage[r.RateKey.Attributes["tier"]]) {
+				t.Errorf("storage tier %s changed: %s", r.RateKey.Attributes["tier"], r.Rate.Price)
+			}
+			delete(storage, r.RateKey.Attributes["tier"])
+		case "AWSLambda":
+			if !r.Rate.Price.Equal(decimal.RequireFromString("0.0000133334")) {
+				t.Errorf("expected the updated Lambda price, got %s", r.Rate.Price)
+			}
+		}
+	}
+	if len(storage) != 0 {
+		t.Errorf("storage rates not carried over: %v", storage)
+	}
+
+	snapshot, _ := store.GetSnapshot(ctx, *result.SnapshotID)
+	if snapshot.Metadata["updated_services"] != "AWSLambda" || snapshot.Metadata["updated_from"] != full.SnapshotID.String() {
+		t.Errorf("expected update provenance in metadata, got %v", snapshot.Metadata)
+	}
+}
@@ -1,7 +1,16 @@
 // Package ingestion - Shared utilities
 package ingestion
 
-import "strings"
+import (
+	"strings"
+
+	"terraform-cost/db"
+
+	"github.com/google/uuid"
+)
+
+// snapshotIDNamespace is the UUIDv5 namespace for deterministic snapshot IDs
+var snapshotIDNamespace = uuid.NewSHA1(uuid.NameSpaceURL, []byte("terraform-cost/pricing-snapshots"))
 
 // toSnakeCase converts camelCase to snake_case
 func toSnakeCase(s string) string {
@@ -14,3 +23,13 @@ func toSnakeCase(s string) string {
 	}
 	return strings.ToLower(result.String())
 }
+
+// newSnapshotID returns a random snapshot ID, or one derived from the
+// snapshot's target and content hash when deterministic
+func newSnapshotID(deterministic bool, cloud db.CloudProvider, region, alias, contentHash string) uuid.UUID {
+	if !deterministic {
+		return uuid.New()
+	}
+	name := strings.Join([]string{string(cloud), region, alias, contentHash}, "\x00")
+	return uuid.NewSHA1(snapshotIDNamespace, []byte(name))
+}
@@ -0,0 +1,84 @@
+// Package ingestion - Validation report artifact for compliance
+package ingestion
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"terraform-cost/db"
+)
+
+// validationReportSuffix names a validation report stored next to its backup
+const validationReportSuffix = ".validation_report.json"
+
+// ValidationReport records which validations an ingestion run performed and
+// their outcome. It is written whether or not the run succeeded.
+type ValidationReport struct {
+	Provider    db.CloudProvider `json:"provider"`
+	Region      string           `json:"region"`
+	Alias       string           `json:"alias"`
+	Environment string           `json:"environment"`
+	Success     bool             `json:"success"`
+	Phase       string           `json:"phase"`
+	Error       string           `json:"error,omitempty"`
+	SnapshotID  *uuid.UUID       `json:"snapshot_id,omitempty"`
+	ContentHash string           `json:"content_hash"`
+	RateCount   int              `json:"rate_count"`
+	StartedAt   time.Time        `json:"started_at"`
+	FinishedAt  time.Time        `json:"finished_at"`
+
+	// Checks are the gating checks in the order they ran; a failed run
+	// ends with the check that failed
+	Checks []ValidationCheck `json:"checks"`
+
+	// Validation and Coverage are the per-service contract detail
+	Validation *ValidationResult `json:"validation"`
+	Coverage   *CoverageReport   `json:"coverage"`
+}
+
+// ValidationReportPath returns where the validation report of a backup is stored
+func ValidationReportPath(backupPath string) string {
+	return strings.TrimSuffix(backupPath, ".json.gz") + validationReportSuffix
+}
+
+// WriteValidationReport writes report as indented JSON. Runs that failed
+// before writing a backup (empty backupPath) get a report named after the
+// region and start time in the provider's backup directory.
+func (m *BackupManager) WriteValidationReport(baseDir, backupPath string, report *ValidationReport) (string, error) {
+	path := ValidationReportPath(backupPath)
+	if backupPath == "" {
+		providerDir := filepath.Join(baseDir, string(report.Provider))
+		if err := os.MkdirAll(providerDir, 0755); err != nil {
+			return "", fmt.Errorf("failed to create backup directory: %w", err)
+		}
+		path = filepath.Join(providerDir, report.Region+"_"+report.StartedAt.Format(backupTimestampLayout)+validationReportSuffix)
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode validation report: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write validation report: %w", err)
+	}
+	return path, nil
+}
+
+// ReadValidationReport reads a validation report from disk
+func (m *BackupManager) ReadValidationReport(path string) (*ValidationReport, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read validation report: %w", err)
+	}
+	var report ValidationReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("failed to decode validation report: %w", err)
+	}
+	return &report, nil
+}
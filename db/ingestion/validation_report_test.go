@@ -0,0 +1,104 @@
+// Package ingestion - Validation report tests
+package ingestion
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"terraform-cost/db"
+	"terraform-cost/db/memstore"
+)
+
+func TestLifecycleWritesValidationReport(t *testing.T) {
+	newConfig := func() *LifecycleConfig {
+		config := DefaultLifecycleConfig()
+		config.Provider = db.AWS
+		config.Region = "us-east-1"
+		config.Environment = "development"
+		config.BackupDir = t.TempDir()
+		return config
+	}
+	store := memstore.NewMemoryStore()
+	backups := NewBackupManager()
+
+	fetcher := &staticFetcher{cloud: db.AWS, prices: testRawPrices("us-east-1", 5)}
+	result, err := NewLifecycle(fetcher, &passthroughNormalizer{cloud: db.AWS}, store).Execute(context.Background(), newConfig())
+	if err != nil || !result.Success {
+		t.Fatalf("ingestion failed: %v %+v", err, result)
+	}
+	if result.ValidationReportPath != ValidationReportPath(result.BackupPath) {
+		t.Fatalf("expected the report next to the backup, got %q", result.ValidationReportPath)
+	}
+	report, err := backups.ReadValidationReport(result.ValidationReportPath)
+	if err != nil {
+		t.Fatalf("report not parseable: %v", err)
+	}
+	if !report.Success || report.ContentHash != result.ContentHash || report.RateCount != 5 ||
+		report.SnapshotID == nil || *report.SnapshotID != *result.SnapshotID {
+		t.Errorf("unexpected report for a successful run: %+v", report)
+	}
+	if len(report.Checks) == 0 {
+		t.Error("expected the checks that ran")
+	}
+	for _, c := range report.Checks {
+		if !c.Passed {
+			t.Errorf("expected every check to pass, got %+v", c)
+		}
+	}
+	if report.Validation == nil || report.Coverage == nil || report.Coverage.SnapshotID != *result.SnapshotID {
+		t.Errorf("expected contract and coverage detail, got %+v %+v", report.Validation, report.Coverage)
+	}
+	if report.FinishedAt.Before(report.StartedAt) {
+		t.Errorf("expected finish after start, got %s before %s", report.FinishedAt, report.StartedAt)
+	}
+
+	// A failed validation still leaves a report explaining why
+	config := newConfig()
+	config.RequireRegionRates = true
+	fetcher = &staticFetcher{cloud: db.AWS, prices: testRawPrices("us-west-2", 5)}
+	result, err = NewLifecycle(fetcher, &passthroughNormalizer{cloud: db.AWS}, store).Execute(context.Background(), config)
+	if err != nil || result.Success {
+		t.Fatalf("expected validation to fail: %v %+v", err, result)
+	}
+	if result.BackupPath != "" || filepath.Dir(result.ValidationReportPath) != filepath.Join(config.BackupDir, "aws") {
+		t.Fatalf("expected a report in the provider backup directory, got %q", result.ValidationReportPath)
+	}
+	report, err = backups.ReadValidationReport(result.ValidationReportPath)
+	if err != nil {
+		t.Fatalf("report not parseable: %v", err)
+	}
+	if report.Success || report.Phase != PhaseValidating.String() || report.Error != result.Error {
+		t.Errorf("unexpected report for a failed run: %+v", report)
+	}
+	last := report.Checks[len(report.Checks)-1]
+	if last.Name != "region_distribution" || last.Passed || !strings.Contains(last.Error, "us-east-1") {
+		t.Errorf("expected the failing check in the report, got %+v", report.Checks)
+	}
+	if report.Validation.IsValid {
+		t.Error("expected the contract detail to be marked invalid")
+	}
+	if listed, _ := backups.ListBackups(config.BackupDir); len(listed) != 0 {
+		t.Errorf("validation reports must not be listed as backups, got %+v", listed)
+	}
+}
+
+func TestValidateAllFailureMatchesErrValidationFailed(t *testing.T) {
+	rates, _ := (&passthroughNormalizer{cloud: db.AWS}).Normalize(context.Background(), testRawPrices("us-east-1", 3))
+	validator := NewIngestionValidator()
+	if err := validator.ValidateAll(rates, 0); err != nil {
+		t.Fatalf("expected valid rates to pass, got %v", err)
+	}
+
+	rates[1].Price = rates[1].Price.Neg()
+	err := validator.ValidateAll(rates, 0)
+	var failure *ValidationError
+	if !errors.Is(err, ErrValidationFailed) || !errors.As(err, &failure) || failure.Check != "prices_positive" {
+		t.Fatalf("expected a prices_positive ValidationError, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "negative price") {
+		t.Errorf("expected the check's details in the message, got %q", err)
+	}
+}
@@ -0,0 +1,883 @@
+// Package memstore - Store conformance tests shared by MemoryStore and PostgresStore
+package memstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"terraform-cost/db"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+func TestMemoryStoreConformance(t *testing.T) {
+	runConformance(t, func(t *testing.T) (db.PricingStore, string) {
+		return NewMemoryStore(), "test-region"
+	})
+}
+
+func TestPostgresStoreConformance(t *testing.T) {
+	url := os.Getenv("DB_URL")
+	if url == "" {
+		t.Skip("DB_URL not set; skipping PostgreSQL conformance test")
+	}
+
+	runConformance(t, func(t *testing.T) (db.PricingStore, string) {
+		store, err := db.NewPostgresStoreFromURL(url)
+		if err != nil {
+			t.Fatalf("failed to connect: %v", err)
+		}
+		conn, err := sql.Open("postgres", url)
+		if err != nil {
+			t.Fatalf("failed to open cleanup connection: %v", err)
+		}
+
+		// A region unique to this subtest keeps it away from real snapshots
+		region := "test-" + uuid.NewString()[:8]
+		t.Cleanup(func() {
+			ctx := context.Background()
+			conn.ExecContext(ctx, "DELETE FROM pricing_snapshots WHERE region = $1", region)
+			conn.ExecContext(ctx, "DELETE FROM pricing_rate_keys WHERE region = $1", region)
+			conn.Close()
+			store.Close()
+		})
+		return store, region
+	})
+}
+
+// runConformance runs the same assertions against any PricingStore.
+// newStore returns a store with nothing under the returned region.
+func runConformance(t *testing.T, newStore func(t *testing.T) (db.PricingStore, string)) {
+	t.Run("CommitMakesWritesVisible", func(t *testing.T) {
+		store, region := newStore(t)
+		ctx := context.Background()
+
+		snapshot := commitSnapshot(t, store, region, "hash-a", []conformanceRate{
+			{attrs: map[string]string{"instance_type": "t3.micro", "os": "linux"}, price: "0.0104"},
+			{attrs: map[string]string{"instance_type": "t3.large", "os": "linux"}, price: "0.0832"},
+		})
+
+		active, err := store.GetActiveSnapshot(ctx, db.AWS, region, "default")
+		if err != nil || active == nil || active.ID != snapshot.ID {
+			t.Fatalf("expected %s to be active, got %+v (err %v)", snapshot.ID, active, err)
+		}
+		if n, _ := store.CountRates(ctx, snapshot.ID); n != 2 {
+			t.Errorf("CountRates = %d, want 2", n)
+		}
+		rates, err := store.GetRatesBySnapshot(ctx, snapshot.ID)
+		if err != nil || len(rates) != 2 {
+			t.Fatalf("GetRatesBySnapshot returned %d rates (err %v)", len(rates), err)
+		}
+		if rates[0].RateKey.Region != region || rates[0].RateKey.Attributes["os"] != "linux" {
+			t.Errorf("rate key not joined: %+v", rates[0].RateKey)
+		}
+		found, _ := store.FindSnapshotByHash(ctx, db.AWS, region, "default", "hash-a")
+		if found == nil || found.ID != snapshot.ID {
+			t.Errorf("FindSnapshotByHash = %+v, want %s", found, snapshot.ID)
+		}
+	})
+
+	t.Run("CountDistinctRateKeysCollapsesTiers", func(t *testing.T) {
+		store, region := newStore(t)
+		ctx := context.Background()
+
+		tiered := map[string]string{"instance_type": "t3.micro", "os": "linux"}
+		snapshot := commitSnapshot(t, store, region, "hash-distinct", []conformanceRate{
+			{attrs: tiered, price: "0.0104", tierMin: "0", tierMax: "100"},
+			{attrs: tiered, price: "0.0100", tierMin: "100"},
+			{attrs: map[string]string{"instance_type": "t3.large", "os": "linux"}, price: "0.0832"},
+		})
+
+		if n, _ := store.CountRates(ctx, snapshot.ID); n != 3 {
+			t.Errorf("CountRates = %d, want 3", n)
+		}
+		if n, err := store.CountDistinctRateKeys(ctx, snapshot.ID); err != nil || n != 2 {
+			t.Errorf("CountDistinctRateKeys = %d (err %v), want 2", n, err)
+		}
+	})
+
+	t.Run("LockIngestionSerializesCommits", func(t *testing.T) {
+		store, region := newStore(t)
+		ctx := context.Background()
+
+		unlock, err := store.LockIngestion(ctx, db.AWS, region, "default", true)
+		if err != nil {
+			t.Fatalf("lock: %v", err)
+		}
+		if _, err := store.LockIngestion(ctx, db.AWS, region, "default", false); !errors.Is(err, db.ErrIngestionLocked) {
+			t.Errorf("expected ErrIngestionLocked for a held lock, got %v", err)
+		}
+		other, err := store.LockIngestion(ctx, db.AWS, region, "other", false)
+		if err != nil {
+			t.Fatalf("expected another alias to lock independently: %v", err)
+		}
+		other()
+
+		// A waiting ingestion gets the lock only once it is released
+		acquired := make(chan error)
+		go func() {
+			unlockWaiter, err := store.LockIngestion(ctx, db.AWS, region, "default", true)
+			if err == nil {
+				defer unlockWaiter()
+			}
+			acquired <- err
+		}()
+		select {
+		case err := <-acquired:
+			t.Fatalf("waiter returned while the lock was held (err %v)", err)
+		case <-time.After(50 * time.Millisecond):
+		}
+		unlock()
+		select {
+		case err := <-acquired:
+			if err != nil {
+				t.Errorf("waiter: %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("waiter never acquired the released lock")
+		}
+	})
+
+	t.Run("RollbackDiscardsWrites", func(t *testing.T) {
+		store, region := newStore(t)
+		ctx := context.Background()
+
+		tx, err := store.BeginTx(ctx)
+		if err != nil {
+			t.Fatalf("begin: %v", err)
+		}
+		snapshot := db.NewSnapshotBuilder(db.AWS, region, "test").Build("hash-rollback")
+		if err := tx.CreateSnapshot(ctx, snapshot); err != nil {
+			t.Fatalf("create snapshot: %v", err)
+		}
+		if err := tx.Rollback(); err != nil {
+			t.Fatalf("rollback: %v", err)
+		}
+
+		if got, err := store.GetSnapshot(ctx, snapshot.ID); err != nil || got != nil {
+			t.Errorf("expected rolled back snapshot to be absent, got %+v (err %v)", got, err)
+		}
+	})
+
+	t.Run("ActivationSupersedesPrevious", func(t *testing.T) {
+		store, region := newStore(t)
+		ctx := context.Background()
+
+		attrs := map[string]string{"instance_type": "t3.micro"}
+		first := commitSnapshot(t, store, region, "hash-1", []conformanceRate{{attrs: attrs, price: "0.0100"}})
+		second := commitSnapshot(t, store, region, "hash-2", []conformanceRate{{attrs: attrs, price: "0.0200"}})
+
+		snapshots, err := store.ListSnapshots(ctx, db.AWS, region)
+		if err != nil || len(snapshots) != 2 {
+			t.Fatalf("ListSnapshots returned %d snapshots (err %v)", len(snapshots), err)
+		}
+		if snapshots[0].ID != second.ID || !snapshots[0].IsActive || snapshots[1].IsActive {
+			t.Errorf("expected only the newest snapshot active and listed first, got %+v", snapshots)
+		}
+
+		// Rate keys are shared across snapshots; resolution follows the active one
+		rate, err := store.ResolveRate(ctx, db.AWS, "AmazonEC2", "Compute Instance", region, attrs, "hrs", "default", db.ResolveOptions{})
+		if err != nil || rate == nil {
+			t.Fatalf("resolve failed: %+v (err %v)", rate, err)
+		}
+		if !rate.Price.Equal(decimal.RequireFromString("0.0200")) || rate.SnapshotID != second.ID {
+			t.Errorf("resolved %s from %s, want 0.0200 from %s", rate.Price, rate.SnapshotID, second.ID)
+		}
+
+		if err := store.ActivateSnapshot(ctx, first.ID); err != nil {
+			t.Fatalf("reactivate: %v", err)
+		}
+		active, _ := store.GetActiveSnapshot(ctx, db.AWS, region, "default")
+		if active == nil || active.ID != first.ID {
+			t.Errorf("expected rollback to %s, got %+v", first.ID, active)
+		}
+	})
+
+	t.Run("SupersededSnapshotGetsValidTo", func(t *testing.T) {
+		store, region := newStore(t)
+		ctx := context.Background()
+
+		attrs := map[string]string{"instance_type": "t3.micro"}
+		first := commitSnapshot(t, store, region, "hash-1", []conformanceRate{{attrs: attrs, price: "0.0100"}})
+
+		// Same region under another alias is a separate series
+		other := db.NewSnapshotBuilder(db.AWS, region, "test").WithAlias("secondary").Build("hash-other")
+		if err := store.CreateSnapshot(ctx, other); err != nil {
+			t.Fatalf("create snapshot: %v", err)
+		}
+		if err := store.ActivateSnapshot(ctx, other.ID); err != nil {
+			t.Fatalf("activate: %v", err)
+		}
+
+		before := time.Now().Add(-time.Minute)
+		second := commitSnapshot(t, store, region, "hash-2", []conformanceRate{{attrs: attrs, price: "0.0200"}})
+
+		got, _ := store.GetSnapshot(ctx, first.ID)
+		if got == nil || got.ValidTo == nil || got.ValidTo.Before(before) {
+			t.Fatalf("expected superseded snapshot to get ValidTo, got %+v", got)
+		}
+		if got, _ := store.GetSnapshot(ctx, second.ID); got == nil || got.ValidTo != nil {
+			t.Errorf("expected active snapshot to stay open-ended, got %+v", got)
+		}
+		if got, _ := store.GetSnapshot(ctx, other.ID); got == nil || !got.IsActive || got.ValidTo != nil {
+			t.Errorf("expected other alias to be untouched, got %+v", got)
+		}
+
+		// Reactivating reopens the window and closes the replaced snapshot
+		if err := store.ActivateSnapshot(ctx, first.ID); err != nil {
+			t.Fatalf("reactivate: %v", err)
+		}
+		if got, _ := store.GetSnapshot(ctx, first.ID); got == nil || got.ValidTo != nil {
+			t.Errorf("expected reactivated snapshot to be open-ended, got %+v", got)
+		}
+		if got, _ := store.GetSnapshot(ctx, second.ID); got == nil || got.ValidTo == nil {
+			t.Errorf("expected replaced snapshot to get ValidTo, got %+v", got)
+		}
+	})
+
+	t.Run("SnapshotAsOfFollowsValidity", func(t *testing.T) {
+		store, region := newStore(t)
+		ctx := context.Background()
+
+		// Windows far from now keep the test independent of DB clock skew
+		now := time.Now()
+		attrs := map[string]string{"instance_type": "t3.micro"}
+		first := commitSnapshotFrom(t, store, region, "hash-1", now.Add(-72*time.Hour), []conformanceRate{{attrs: attrs, price: "0.0100"}})
+		second := commitSnapshotFrom(t, store, region, "hash-2", now.Add(-24*time.Hour), []conformanceRate{{attrs: attrs, price: "0.0200"}})
+
+		tests := []struct {
+			name string
+			at   time.Time
+			want *db.PricingSnapshot
+		}{
+			{"before any snapshot", now.Add(-96 * time.Hour), nil},
+			{"first window", now.Add(-48 * time.Hour), first},
+			{"second window", now.Add(-time.Hour), second},
+		}
+		resolver := db.NewStrictResolver(store).WithMode(db.Strict)
+		for _, tt := range tests {
+			got, err := store.GetSnapshotAsOf(ctx, db.AWS, region, "default", tt.at)
+			if err != nil {
+				t.Fatalf("%s: %v", tt.name, err)
+			}
+			if tt.want == nil {
+				if got != nil {
+					t.Errorf("%s: expected no snapshot, got %s", tt.name, got.ID)
+				}
+				continue
+			}
+			if got == nil || got.ID != tt.want.ID {
+				t.Errorf("%s: got %+v, want %s", tt.name, got, tt.want.ID)
+				continue
+			}
+
+			result, err := resolver.ResolveAsOf(ctx, db.ResolutionRequest{
+				Cloud: db.AWS, Service: "AmazonEC2", ProductFamily: "Compute Instance",
+				Region: region, Attributes: attrs, Unit: "hrs",
+			}, tt.at)
+			if err != nil || result.SnapshotID != tt.want.ID {
+				t.Errorf("%s: ResolveAsOf = %+v (err %v), want snapshot %s", tt.name, result, err, tt.want.ID)
+			}
+		}
+	})
+
+	t.Run("SnapshotMetadataRoundTrips", func(t *testing.T) {
+		store, region := newStore(t)
+		ctx := context.Background()
+
+		metadata := map[string]string{"ci_job": "1234", "git_sha": "9f3c2a1", "operator": "alice"}
+		snapshot := db.NewSnapshotBuilder(db.AWS, region, "test").Build("hash-meta")
+		snapshot.Metadata = metadata
+		if err := store.CreateSnapshot(ctx, snapshot); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		metadata["operator"] = "mallory" // the store must keep its own copy
+		plain := commitSnapshot(t, store, region, "hash-plain", nil)
+
+		got, err := store.GetSnapshot(ctx, snapshot.ID)
+		if err != nil || got == nil {
+			t.Fatalf("get: %v", err)
+		}
+		want := map[string]string{"ci_job": "1234", "git_sha": "9f3c2a1", "operator": "alice"}
+		if len(got.Metadata) != len(want) {
+			t.Fatalf("metadata = %v, want %v", got.Metadata, want)
+		}
+		for k, v := range want {
+			if got.Metadata[k] != v {
+				t.Errorf("metadata[%s] = %q, want %q", k, got.Metadata[k], v)
+			}
+		}
+
+		listed, err := store.ListSnapshots(ctx, db.AWS, region)
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		for _, s := range listed {
+			switch s.ID {
+			case snapshot.ID:
+				if s.Metadata["git_sha"] != "9f3c2a1" {
+					t.Errorf("expected listed snapshot to carry metadata, got %v", s.Metadata)
+				}
+			case plain.ID:
+				if s.Metadata != nil {
+					t.Errorf("expected no metadata on a plain snapshot, got %v", s.Metadata)
+				}
+			}
+		}
+	})
+
+	t.Run("RateLabelsRoundTrip", func(t *testing.T) {
+		store, region := newStore(t)
+		ctx := context.Background()
+
+		labels := map[string]string{"sku": "JRTCKXETXF", "rate_code": "JRTCKXETXF.JRTCKXETXF.6YS6EN2CT7"}
+		snapshot := commitSnapshot(t, store, region, "hash-labels", []conformanceRate{
+			{attrs: map[string]string{"instance_type": "m5.large"}, price: "0.096", labels: labels},
+			{attrs: map[string]string{"instance_type": "m5.xlarge"}, price: "0.192"},
+		})
+		labels["sku"] = "changed" // the store must keep its own copy
+
+		rates, err := store.GetRatesBySnapshot(ctx, snapshot.ID)
+		if err != nil || len(rates) != 2 {
+			t.Fatalf("get rates: %d, %v", len(rates), err)
+		}
+		for _, r := range rates {
+			switch r.RateKey.Attributes["instance_type"] {
+			case "m5.large":
+				if r.Rate.Labels["sku"] != "JRTCKXETXF" || r.Rate.Labels["rate_code"] != "JRTCKXETXF.JRTCKXETXF.6YS6EN2CT7" {
+					t.Errorf("labels = %v, want the stored SKU and rate code", r.Rate.Labels)
+				}
+			case "m5.xlarge":
+				if r.Rate.Labels != nil {
+					t.Errorf("expected no labels on an unlabelled rate, got %v", r.Rate.Labels)
+				}
+			}
+		}
+	})
+
+	t.Run("RateKeysAreUnique", func(t *testing.T) {
+		store, region := newStore(t)
+		ctx := context.Background()
+
+		key := func() *db.RateKey {
+			return &db.RateKey{
+				ID:            uuid.New(),
+				Cloud:         db.AWS,
+				Service:       "AmazonS3",
+				ProductFamily: "Storage",
+				Region:        region,
+				Attributes:    map[string]string{"storage_class": "standard", "volume_type": "general"},
+			}
+		}
+		a, err := store.UpsertRateKey(ctx, key())
+		if err != nil {
+			t.Fatalf("upsert: %v", err)
+		}
+		b, err := store.UpsertRateKey(ctx, key())
+		if err != nil {
+			t.Fatalf("upsert: %v", err)
+		}
+		if a.ID != b.ID {
+			t.Errorf("same identity produced two keys: %s and %s", a.ID, b.ID)
+		}
+
+		got, _ := store.GetRateKey(ctx, db.AWS, "AmazonS3", "Storage", region, map[string]string{"storage_class": "standard", "volume_type": "general"})
+		if got == nil || got.ID != a.ID {
+			t.Errorf("GetRateKey = %+v, want %s", got, a.ID)
+		}
+		// GetRateKey is an exact match, not containment
+		if got, _ := store.GetRateKey(ctx, db.AWS, "AmazonS3", "Storage", region, map[string]string{"storage_class": "standard"}); got != nil {
+			t.Errorf("expected no exact match for a subset of attributes, got %+v", got)
+		}
+	})
+
+	t.Run("ListRateKeysFiltersCatalog", func(t *testing.T) {
+		store, region := newStore(t)
+		ctx := context.Background()
+
+		// Keys are listed whether or not a snapshot uses them
+		commitSnapshot(t, store, region, "hash-a", []conformanceRate{
+			{attrs: map[string]string{"instance_type": "t3.micro", "os": "linux"}, price: "0.0104"},
+			{attrs: map[string]string{"instance_type": "t3.large", "os": "linux"}, price: "0.0832"},
+		})
+		for _, key := range []db.RateKey{
+			{Service: "AmazonS3", ProductFamily: "Storage", Attributes: map[string]string{"storage_class": "standard"}},
+			{Service: "AmazonS3", ProductFamily: "API Request", Attributes: map[string]string{"request_type": "get"}},
+			// jsonb's text form would list this after the linux keys
+			{Service: "AmazonEC2", ProductFamily: "Compute Instance", Attributes: map[string]string{"instance_type": "m5.large", "os": "windows"}},
+		} {
+			key.ID, key.Cloud, key.Region = uuid.New(), db.AWS, region
+			if _, err := store.UpsertRateKey(ctx, &key); err != nil {
+				t.Fatalf("upsert: %v", err)
+			}
+		}
+
+		all, err := store.ListRateKeys(ctx, db.AWS, region, db.RateKeyFilter{})
+		if err != nil || len(all) != 5 {
+			t.Fatalf("expected all 5 keys, got %d (err %v)", len(all), err)
+		}
+
+		ec2, _ := store.ListRateKeys(ctx, db.AWS, region, db.RateKeyFilter{Service: "AmazonEC2"})
+		var types []string
+		for _, key := range ec2 {
+			if key.Service != "AmazonEC2" || key.Region != region {
+				t.Errorf("unexpected key %+v", key)
+			}
+			types = append(types, key.Attributes["instance_type"])
+		}
+		if strings.Join(types, ",") != "m5.large,t3.large,t3.micro" {
+			t.Errorf("expected the EC2 keys ordered by attributes, got %v", types)
+		}
+
+		micro, _ := store.ListRateKeys(ctx, db.AWS, region, db.RateKeyFilter{Attributes: map[string]string{"instance_type": "t3.micro"}})
+		if len(micro) != 1 || micro[0].Attributes["os"] != "linux" {
+			t.Errorf("expected the t3.micro key by containment, got %+v", micro)
+		}
+		storage, _ := store.ListRateKeys(ctx, db.AWS, region, db.RateKeyFilter{Service: "AmazonS3", ProductFamily: "Storage"})
+		if len(storage) != 1 || storage[0].Attributes["storage_class"] != "standard" {
+			t.Errorf("expected the S3 storage key, got %+v", storage)
+		}
+		if limited, _ := store.ListRateKeys(ctx, db.AWS, region, db.RateKeyFilter{Limit: 2}); len(limited) != 2 {
+			t.Errorf("expected Limit to cap the keys, got %d", len(limited))
+		}
+		if none, _ := store.ListRateKeys(ctx, db.Azure, region, db.RateKeyFilter{}); len(none) != 0 {
+			t.Errorf("expected no keys for another cloud, got %d", len(none))
+		}
+	})
+
+	t.Run("ResolveUsesContainment", func(t *testing.T) {
+		store, region := newStore(t)
+		ctx := context.Background()
+
+		commitSnapshot(t, store, region, "hash-c", []conformanceRate{
+			{attrs: map[string]string{"instance_type": "m5.large", "os": "linux", "tenancy": "shared"}, price: "0.0960"},
+		})
+
+		resolve := func(attrs map[string]string) *db.ResolvedRate {
+			rate, err := store.ResolveRate(ctx, db.AWS, "AmazonEC2", "Compute Instance", region, attrs, "hrs", "default", db.ResolveOptions{})
+			if err != nil {
+				t.Fatalf("resolve %v: %v", attrs, err)
+			}
+			return rate
+		}
+		if resolve(map[string]string{"instance_type": "m5.large", "os": "linux"}) == nil {
+			t.Error("expected a subset of the key's attributes to match")
+		}
+		if resolve(map[string]string{"instance_type": "m5.large", "os": "windows"}) != nil {
+			t.Error("expected a differing attribute value not to match")
+		}
+		if resolve(map[string]string{"instance_type": "m5.large", "license": "byol"}) != nil {
+			t.Error("expected an attribute the key lacks not to match")
+		}
+	})
+
+	t.Run("ResolveAllMatchingOrdersByAttributes", func(t *testing.T) {
+		store, region := newStore(t)
+		ctx := context.Background()
+
+		// jsonb's text form puts the shorter "os" key first and would list
+		// m5.xlarge first; AttributesSortKey orders by instance_type
+		commitSnapshot(t, store, region, "hash-o", []conformanceRate{
+			{attrs: map[string]string{"instance_type": "m5.xlarge", "os": "linux"}, price: "0.192"},
+			{attrs: map[string]string{"instance_type": "m5.large", "os": "windows"}, price: "0.188"},
+		})
+		rates, err := store.ResolveAllMatching(ctx, db.AWS, "AmazonEC2", "Compute Instance", region, nil, "hrs", "default", db.ResolveOptions{})
+		if err != nil || len(rates) != 2 {
+			t.Fatalf("expected both rates, got %+v (err %v)", rates, err)
+		}
+		if rates[0].Attributes["instance_type"] != "m5.large" || rates[1].Attributes["instance_type"] != "m5.xlarge" {
+			t.Errorf("expected the rates ordered by AttributesSortKey, got %v then %v", rates[0].Attributes, rates[1].Attributes)
+		}
+	})
+
+	t.Run("ResolveAllMatchingDetectsAmbiguity", func(t *testing.T) {
+		store, region := newStore(t)
+		ctx := context.Background()
+
+		// Two rows for the linux key must still count as one match
+		lastYear := time.Now().AddDate(-1, 0, 0)
+		linux := map[string]string{"instance_type": "m5.large", "os": "linux"}
+		commitSnapshot(t, store, region, "hash-m", []conformanceRate{
+			{attrs: linux, price: "0.0960"},
+			{attrs: linux, price: "0.0900", effective: &lastYear},
+			{attrs: map[string]string{"instance_type": "m5.large", "os": "windows"}, price: "0.1880"},
+		})
+
+		partial := map[string]string{"instance_type": "m5.large"}
+		rates, err := store.ResolveAllMatching(ctx, db.AWS, "AmazonEC2", "Compute Instance", region, partial, "hrs", "default", db.ResolveOptions{})
+		if err != nil {
+			t.Fatalf("resolve all: %v", err)
+		}
+		if len(rates) != 2 {
+			t.Fatalf("expected one rate per matching key, got %+v", rates)
+		}
+		for _, r := range rates {
+			if r.Attributes["os"] == "linux" && !r.Price.Equal(decimal.RequireFromString("0.0900")) {
+				t.Errorf("expected the dated linux rate, got %s", r.Price)
+			}
+		}
+
+		req := db.ResolutionRequest{Cloud: db.AWS, Service: "AmazonEC2", ProductFamily: "Compute Instance", Region: region, Attributes: partial, Unit: "hrs"}
+		resolver := db.NewStrictResolver(store).WithMode(db.Strict)
+		if _, err := resolver.Resolve(ctx, req); err == nil || !strings.Contains(err.Error(), "2 rates match") {
+			t.Errorf("expected strict mode to reject the ambiguous request, got %v", err)
+		}
+		req.Attributes = linux
+		if result, err := resolver.Resolve(ctx, req); err != nil || !result.Price.Equal(decimal.RequireFromString("0.0900")) {
+			t.Errorf("expected the fully specified request to resolve, got %+v (err %v)", result, err)
+		}
+
+		// Permissive mode picks one rate but, by default, marks the match as fuzzy
+		fuzzy := db.NewStrictResolver(store)
+		req.Attributes = partial
+		if result, err := fuzzy.Resolve(ctx, req); err != nil || result.Confidence != db.DefaultFuzzyConfidence {
+			t.Errorf("expected an ambiguous permissive match to get the default fuzzy confidence, got %+v (err %v)", result, err)
+		}
+		if result, err := db.NewStrictResolver(store).WithFuzzyConfidence(0).Resolve(ctx, req); err != nil || result.Confidence != 1 {
+			t.Errorf("expected a disabled fuzzy check to keep full confidence, got %+v (err %v)", result, err)
+		}
+		req.Attributes = linux
+		if result, err := fuzzy.Resolve(ctx, req); err != nil || result.Confidence != 1 {
+			t.Errorf("expected an exact match to keep full confidence, got %+v (err %v)", result, err)
+		}
+	})
+
+	t.Run("ResolvePrefersHigherConfidence", func(t *testing.T) {
+		store, region := newStore(t)
+		ctx := context.Background()
+
+		// Same tier and effective date; only confidence separates them
+		commitSnapshot(t, store, region, "hash-conf", []conformanceRate{
+			{attrs: map[string]string{"instance_type": "m5.large", "source": "derived"}, price: "0.0500", confidence: 0.6},
+			{attrs: map[string]string{"instance_type": "m5.large", "source": "api"}, price: "0.0960"},
+			{attrs: map[string]string{"instance_type": "m5.large", "source": "stub"}, price: "0.0100", confidence: 0.5},
+		})
+
+		attrs := map[string]string{"instance_type": "m5.large"}
+		rate, err := store.ResolveRate(ctx, db.AWS, "AmazonEC2", "Compute Instance", region, attrs, "hrs", "default", db.ResolveOptions{})
+		if err != nil || rate == nil {
+			t.Fatalf("resolve: %v, %v", rate, err)
+		}
+		if rate.Confidence != 1 || !rate.Price.Equal(decimal.RequireFromString("0.0960")) {
+			t.Errorf("expected the confidence 1.0 rate to win, got %s at %v", rate.Price, rate.Confidence)
+		}
+
+		snapshot, _ := store.GetActiveSnapshot(ctx, db.AWS, region, "default")
+		batch, err := store.ResolveRatesBatch(ctx, snapshot.ID, []db.RateKeyQuery{
+			{Service: "AmazonEC2", ProductFamily: "Compute Instance", Attributes: attrs, Unit: "hrs"},
+		}, db.ResolveOptions{})
+		if err != nil || batch[0] == nil || batch[0].Confidence != 1 {
+			t.Errorf("expected the batch lookup to pick the confidence 1.0 rate, got %+v, %v", batch[0], err)
+		}
+	})
+
+	t.Run("ResolveHonoursEffectiveDate", func(t *testing.T) {
+		store, region := newStore(t)
+		ctx := context.Background()
+
+		day := func(m time.Month) *time.Time {
+			tm := time.Date(2024, m, 1, 0, 0, 0, 0, time.UTC)
+			return &tm
+		}
+		attrs := map[string]string{"instance_type": "t3.micro"}
+		commitSnapshot(t, store, region, "hash-d", []conformanceRate{
+			{attrs: attrs, price: "0.0900"},
+			{attrs: attrs, price: "0.1000", effective: day(time.January)},
+			{attrs: attrs, price: "0.1100", effective: day(time.June)},
+		})
+
+		tests := []struct {
+			asOf time.Time
+			want string
+		}{
+			{time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC), "0.0900"},
+			{time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), "0.1000"},
+			{time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC), "0.1100"},
+		}
+		for _, tt := range tests {
+			rate, err := store.ResolveRate(ctx, db.AWS, "AmazonEC2", "Compute Instance", region, attrs, "hrs", "default", db.ResolveOptions{AsOf: tt.asOf})
+			if err != nil || rate == nil {
+				t.Fatalf("as of %s: %+v (err %v)", tt.asOf.Format("2006-01-02"), rate, err)
+			}
+			if !rate.Price.Equal(decimal.RequireFromString(tt.want)) {
+				t.Errorf("as of %s: price %s, want %s", tt.asOf.Format("2006-01-02"), rate.Price, tt.want)
+			}
+		}
+	})
+
+	t.Run("ResolveFiltersCurrency", func(t *testing.T) {
+		store, region := newStore(t)
+		ctx := context.Background()
+
+		attrs := map[string]string{"instance_type": "d2s_v3"}
+		snapshot := commitSnapshot(t, store, region, "hash-fx", []conformanceRate{
+			{attrs: attrs, price: "0.0960", currency: "USD"},
+			{attrs: attrs, price: "0.0890", currency: "EUR"},
+		})
+
+		currencies, err := store.DistinctCurrencies(ctx, snapshot.ID)
+		if err != nil || len(currencies) != 2 || currencies[0] != "EUR" || currencies[1] != "USD" {
+			t.Fatalf("DistinctCurrencies = %v (err %v), want [EUR USD]", currencies, err)
+		}
+
+		for currency, want := range map[string]string{"USD": "0.0960", "EUR": "0.0890"} {
+			rate, err := store.ResolveRate(ctx, db.AWS, "AmazonEC2", "Compute Instance", region, attrs, "hrs", "default", db.ResolveOptions{Currency: currency})
+			if err != nil || rate == nil {
+				t.Fatalf("%s: %+v (err %v)", currency, rate, err)
+			}
+			if rate.Currency != currency || !rate.Price.Equal(decimal.RequireFromString(want)) {
+				t.Errorf("%s: resolved %s %s, want %s", currency, rate.Price, rate.Currency, want)
+			}
+		}
+		if rate, _ := store.ResolveRate(ctx, db.AWS, "AmazonEC2", "Compute Instance", region, attrs, "hrs", "default", db.ResolveOptions{Currency: "GBP"}); rate != nil {
+			t.Errorf("expected no GBP rate, got %+v", rate)
+		}
+	})
+
+	t.Run("ResolveRatesBatchMatchesSingleLookups", func(t *testing.T) {
+		store, region := newStore(t)
+		ctx := context.Background()
+
+		lastYear := time.Now().AddDate(-1, 0, 0)
+		linux := map[string]string{"instance_type": "m5.large", "os": "linux"}
+		snapshot := commitSnapshot(t, store, region, "hash-batch", []conformanceRate{
+			{attrs: linux, price: "0.0960"},
+			{attrs: linux, price: "0.0900", effective: &lastYear},
+			{attrs: map[string]string{"instance_type": "m5.large", "os": "windows"}, price: "0.1880"},
+			{attrs: map[string]string{"instance_type": "t3.micro", "os": "linux"}, price: "0.0104", tierMin: "0", tierMax: "100"},
+			{attrs: map[string]string{"instance_type": "t3.micro", "os": "linux"}, price: "0.0090", tierMin: "100"},
+		})
+
+		reqs := []db.RateKeyQuery{
+			{Service: "AmazonEC2", ProductFamily: "Compute Instance", Attributes: linux, Unit: "hrs"},
+			{Service: "AmazonEC2", ProductFamily: "Compute Instance", Attributes: map[string]string{"instance_type": "m5.xlarge"}, Unit: "hrs"},
+			{Service: "AmazonEC2", ProductFamily: "Compute Instance", Attributes: map[string]string{"os": "windows"}, Unit: "hrs"},
+			{Service: "AmazonEC2", ProductFamily: "Compute Instance", Attributes: map[string]string{"instance_type": "t3.micro"}, Unit: "hrs"},
+			{Service: "AmazonEC2", ProductFamily: "Compute Instance", Attributes: linux, Unit: "GB-Mo"},
+		}
+		rates, err := store.ResolveRatesBatch(ctx, snapshot.ID, reqs, db.ResolveOptions{})
+		if err != nil {
+			t.Fatalf("resolve batch: %v", err)
+		}
+		if len(rates) != 3 {
+			t.Errorf("expected 3 resolved queries, got %d", len(rates))
+		}
+		for i, req := range reqs {
+			single, err := store.ResolveRate(ctx, db.AWS, req.Service, req.ProductFamily, region, req.Attributes, req.Unit, "default", db.ResolveOptions{})
+			if err != nil {
+				t.Fatalf("resolve %d: %v", i, err)
+			}
+			got, ok := rates[i]
+			if (single == nil) != !ok {
+				t.Errorf("query %d: batch found %v, single lookup found %v", i, ok, single != nil)
+				continue
+			}
+			if ok && (!got.Price.Equal(single.Price) || got.SnapshotID != snapshot.ID) {
+				t.Errorf("query %d: batch rate %+v, single rate %+v", i, got, single)
+			}
+		}
+		if rate := rates[3]; rate == nil || !rate.Price.Equal(decimal.RequireFromString("0.0104")) {
+			t.Errorf("expected the first t3.micro tier, got %+v", rate)
+		}
+
+		if rates, err := store.ResolveRatesBatch(ctx, uuid.New(), reqs, db.ResolveOptions{}); err != nil || len(rates) != 0 {
+			t.Errorf("expected nothing from an unknown snapshot, got %v (err %v)", rates, err)
+		}
+	})
+
+	t.Run("RatesPageWalksSnapshot", func(t *testing.T) {
+		store, region := newStore(t)
+		ctx := context.Background()
+
+		var rates []conformanceRate
+		for _, it := range []string{"t3.nano", "t3.micro", "t3.small", "t3.medium", "t3.large"} {
+			rates = append(rates, conformanceRate{attrs: map[string]string{"instance_type": it}, price: "0.01"})
+		}
+		snapshot := commitSnapshot(t, store, region, "hash-page", rates)
+
+		seen := make(map[uuid.UUID]bool)
+		after := uuid.Nil
+		for pages := 0; ; pages++ {
+			page, err := store.GetRatesPage(ctx, snapshot.ID, after, 2)
+			if err != nil {
+				t.Fatalf("page %d: %v", pages, err)
+			}
+			if len(page) == 0 {
+				break
+			}
+			if len(page) > 2 || pages > 3 {
+				t.Fatalf("page %d has %d rates; expected at most 2 per page and 3 pages", pages, len(page))
+			}
+			for _, sr := range page {
+				if seen[sr.Rate.ID] {
+					t.Fatalf("rate %s returned twice", sr.Rate.ID)
+				}
+				seen[sr.Rate.ID] = true
+				if sr.RateKey.ID != sr.Rate.RateKeyID || sr.RateKey.Attributes["instance_type"] == "" {
+					t.Errorf("rate %s not joined with its key: %+v", sr.Rate.ID, sr.RateKey)
+				}
+			}
+			after = page[len(page)-1].Rate.ID
+		}
+		if len(seen) != len(rates) {
+			t.Errorf("paged %d rates, want %d", len(seen), len(rates))
+		}
+	})
+
+	t.Run("TieredRatesAscend", func(t *testing.T) {
+		store, region := newStore(t)
+		ctx := context.Background()
+
+		attrs := map[string]string{"storage_class": "standard"}
+		commitSnapshot(t, store, region, "hash-t", []conformanceRate{
+			{attrs: attrs, price: "0.0210", tierMin: "512000"},
+			{attrs: attrs, price: "0.0230", tierMin: "0", tierMax: "51200"},
+			{attrs: attrs, price: "0.0220", tierMin: "51200", tierMax: "512000"},
+		})
+
+		tiers, err := store.ResolveTieredRates(ctx, db.AWS, "AmazonEC2", "Compute Instance", region, attrs, "hrs", "default", db.ResolveOptions{})
+		if err != nil || len(tiers) != 3 {
+			t.Fatalf("expected 3 tiers, got %d (err %v)", len(tiers), err)
+		}
+		for i, want := range []string{"0.0230", "0.0220", "0.0210"} {
+			if !tiers[i].Price.Equal(decimal.RequireFromString(want)) {
+				t.Errorf("tier %d: price %s, want %s", i, tiers[i].Price, want)
+			}
+		}
+		if tiers[2].Max != nil {
+			t.Errorf("top tier should be unbounded, got max %s", tiers[2].Max)
+		}
+	})
+
+	t.Run("TieredRatesFollowEffectivePeriod", func(t *testing.T) {
+		store, region := newStore(t)
+		ctx := context.Background()
+
+		day := func(m time.Month) *time.Time {
+			tm := time.Date(2024, m, 1, 0, 0, 0, 0, time.UTC)
+			return &tm
+		}
+		attrs := map[string]string{"storage_class": "infrequent"}
+		commitSnapshot(t, store, region, "hash-tp", []conformanceRate{
+			{attrs: attrs, price: "0.0130", tierMin: "0", tierMax: "51200", effective: day(time.January)},
+			{attrs: attrs, price: "0.0120", tierMin: "51200", effective: day(time.January)},
+			{attrs: attrs, price: "0.0125", tierMin: "0", tierMax: "51200", effective: day(time.June)},
+			{attrs: attrs, price: "0.0115", tierMin: "51200", effective: day(time.June)},
+			{attrs: attrs, price: "0.0110", tierMin: "0", effective: day(time.June), currency: "EUR"},
+		})
+
+		tests := []struct {
+			asOf     time.Time
+			currency string
+			want     []string
+		}{
+			{time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC), "", nil},
+			{time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), "USD", []string{"0.0130", "0.0120"}},
+			{time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC), "USD", []string{"0.0125", "0.0115"}},
+			{time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC), "EUR", []string{"0.0110"}},
+		}
+		for _, tt := range tests {
+			tiers, err := store.ResolveTieredRates(ctx, db.AWS, "AmazonEC2", "Compute Instance", region, attrs, "hrs", "default",
+				db.ResolveOptions{AsOf: tt.asOf, Currency: tt.currency})
+			if err != nil || len(tiers) != len(tt.want) {
+				t.Fatalf("as of %s %s: %d tiers, want %d (err %v)", tt.asOf.Format("2006-01-02"), tt.currency, len(tiers), len(tt.want), err)
+			}
+			for i, want := range tt.want {
+				if !tiers[i].Price.Equal(decimal.RequireFromString(want)) {
+					t.Errorf("as of %s %s: tier %d price %s, want %s", tt.asOf.Format("2006-01-02"), tt.currency, i, tiers[i].Price, want)
+				}
+			}
+		}
+	})
+}
+
+// conformanceRate is one hourly EC2 rate written by commitSnapshot
+type conformanceRate struct {
+	attrs            map[string]string
+	price            string
+	currency         string // defaults to USD
+	tierMin, tierMax string
+	effective        *time.Time
+	confidence       float64 // defaults to 1.0
+	labels           map[string]string
+}
+
+// commitSnapshot writes and activates a snapshot in one transaction
+func commitSnapshot(t testing.TB, store db.PricingStore, region, hash string, rates []conformanceRate) *db.PricingSnapshot {
+	t.Helper()
+	return commitSnapshotFrom(t, store, region, hash, time.Time{}, rates)
+}
+
+// commitSnapshotFrom is commitSnapshot with an explicit ValidFrom (zero = now)
+func commitSnapshotFrom(t testing.TB, store db.PricingStore, region, hash string, validFrom time.Time, rates []conformanceRate) *db.PricingSnapshot {
+	t.Helper()
+	ctx := context.Background()
+
+	tx, err := store.BeginTx(ctx)
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	defer tx.Rollback()
+
+	snapshot := db.NewSnapshotBuilder(db.AWS, region, "test").Build(hash)
+	if !validFrom.IsZero() {
+		snapshot.ValidFrom = validFrom
+	}
+	if err := tx.CreateSnapshot(ctx, snapshot); err != nil {
+		t.Fatalf("create snapshot: %v", err)
+	}
+	for _, r := range rates {
+		key, err := tx.UpsertRateKey(ctx, &db.RateKey{
+			ID:            uuid.New(),
+			Cloud:         db.AWS,
+			Service:       "AmazonEC2",
+			ProductFamily: "Compute Instance",
+			Region:        region,
+			Attributes:    r.attrs,
+		})
+		if err != nil {
+			t.Fatalf("upsert key: %v", err)
+		}
+		rate := &db.PricingRate{
+			ID:            uuid.New(),
+			SnapshotID:    snapshot.ID,
+			RateKeyID:     key.ID,
+			Unit:          "hrs",
+			Price:         decimal.RequireFromString(r.price),
+			Currency:      "USD",
+			Confidence:    1.0,
+			EffectiveDate: r.effective,
+			Labels:        r.labels,
+		}
+		if r.currency != "" {
+			rate.Currency = r.currency
+		}
+		if r.confidence != 0 {
+			rate.Confidence = r.confidence
+		}
+		if r.tierMin != "" {
+			v := decimal.RequireFromString(r.tierMin)
+			rate.TierMin = &v
+		}
+		if r.tierMax != "" {
+			v := decimal.RequireFromString(r.tierMax)
+			rate.TierMax = &v
+		}
+		if err := tx.CreateRate(ctx, rate); err != nil {
+			t.Fatalf("create rate: %v", err)
+		}
+	}
+	if err := tx.ActivateSnapshot(ctx, snapshot.ID); err != nil {
+		t.Fatalf("activate: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	return snapshot
+}
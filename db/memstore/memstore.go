@@ -0,0 +1,785 @@
+// Package memstore - In-memory PricingStore for tests and offline use
+// Mirrors the PostgreSQL store's semantics without a database: snapshot
+// activation, unique rate keys, JSONB-style attribute containment and
+// transactions that only become visible on commit.
+package memstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"terraform-cost/db"
+
+	"github.com/google/uuid"
+)
+
+// MemoryStore implements db.PricingStore backed by maps.
+// It is safe for concurrent use.
+type MemoryStore struct {
+	mu        sync.RWMutex
+	snapshots []*db.PricingSnapshot // insertion order
+	keys      map[uuid.UUID]*db.RateKey
+	keyIndex  map[string]uuid.UUID // rateKeyIdentity -> key ID
+	rates     []*db.PricingRate
+
+	// locks holds a channel per held ingestion lock, closed on unlock
+	lockMu sync.Mutex
+	locks  map[string]chan struct{}
+}
+
+// NewMemoryStore creates an empty store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		keys:     make(map[uuid.UUID]*db.RateKey),
+		keyIndex: make(map[string]uuid.UUID),
+		locks:    make(map[string]chan struct{}),
+	}
+}
+
+// Ping implements db.PricingStore
+func (s *MemoryStore) Ping(ctx context.Context) error {
+	return nil
+}
+
+// Close implements db.PricingStore
+func (s *MemoryStore) Close() error {
+	return nil
+}
+
+// CreateSnapshot inserts a new pricing snapshot
+func (s *MemoryStore) CreateSnapshot(ctx context.Context, snapshot *db.PricingSnapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.createSnapshot(snapshot)
+}
+
+// GetSnapshot retrieves a snapshot by ID
+func (s *MemoryStore) GetSnapshot(ctx context.Context, id uuid.UUID) (*db.PricingSnapshot, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if snap := s.snapshot(id); snap != nil {
+		return copySnapshot(snap), nil
+	}
+	return nil, nil
+}
+
+// GetActiveSnapshot retrieves the active snapshot for a cloud/region/alias
+func (s *MemoryStore) GetActiveSnapshot(ctx context.Context, cloud db.CloudProvider, region, alias string) (*db.PricingSnapshot, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if snap := s.activeSnapshot(cloud, region, alias); snap != nil {
+		return copySnapshot(snap), nil
+	}
+	return nil, nil
+}
+
+// ActivateSnapshot activates a snapshot (deactivates others)
+func (s *MemoryStore) ActivateSnapshot(ctx context.Context, id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.activate(id)
+}
+
+// ListSnapshots lists snapshots for a cloud/region, newest first.
+// An empty cloud or region matches all values.
+func (s *MemoryStore) ListSnapshots(ctx context.Context, cloud db.CloudProvider, region string) ([]*db.PricingSnapshot, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []*db.PricingSnapshot
+	for i := len(s.snapshots) - 1; i >= 0; i-- {
+		snap := s.snapshots[i]
+		if (cloud == "" || snap.Cloud == cloud) && (region == "" || snap.Region == region) {
+			result = append(result, copySnapshot(snap))
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].CreatedAt.After(result[j].CreatedAt) })
+	return result, nil
+}
+
+// FindSnapshotByHash finds a snapshot with matching content hash
+func (s *MemoryStore) FindSnapshotByHash(ctx context.Context, cloud db.CloudProvider, region, alias, hash string) (*db.PricingSnapshot, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, snap := range s.snapshots {
+		if snap.Cloud == cloud && snap.Region == region && snap.ProviderAlias == alias && snap.Hash == hash {
+			return copySnapshot(snap), nil
+		}
+	}
+	return nil, nil
+}
+
+// GetSnapshotAsOf returns the snapshot whose [ValidFrom, ValidTo) window
+// contains at, or nil. Snapshots that were never activated are ignored.
+func (s *MemoryStore) GetSnapshotAsOf(ctx context.Context, cloud db.CloudProvider, region, alias string, at time.Time) (*db.PricingSnapshot, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var best *db.PricingSnapshot
+	for _, snap := range s.snapshots {
+		if snap.Cloud != cloud || snap.Region != region || snap.ProviderAlias != alias {
+			continue
+		}
+		if snap.State != "ready" && snap.State != "archived" {
+			continue
+		}
+		if snap.ValidFrom.After(at) || (snap.ValidTo != nil && !snap.ValidTo.After(at)) {
+			continue
+		}
+		if best == nil || snap.ValidFrom.After(best.ValidFrom) {
+			best = snap
+		}
+	}
+	if best == nil {
+		return nil, nil
+	}
+	return copySnapshot(best), nil
+}
+
+// UpsertRateKey inserts or returns existing rate key
+func (s *MemoryStore) UpsertRateKey(ctx context.Context, key *db.RateKey) (*db.RateKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.upsertRateKey(key)
+}
+
+// GetRateKey retrieves a rate key by exact attributes
+func (s *MemoryStore) GetRateKey(ctx context.Context, cloud db.CloudProvider, service, productFamily, region string, attrs map[string]string) (*db.RateKey, error) {
+	identity, err := rateKeyIdentity(&db.RateKey{Cloud: cloud, Service: service, ProductFamily: productFamily, Region: region, Attributes: attrs})
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	id, ok := s.keyIndex[identity]
+	if !ok {
+		return nil, nil
+	}
+	copied := *s.keys[id]
+	return &copied, nil
+}
+
+// ListRateKeys returns the rate keys of cloud and region matching filter
+func (s *MemoryStore) ListRateKeys(ctx context.Context, cloud db.CloudProvider, region string, filter db.RateKeyFilter) ([]db.RateKey, error) {
+	s.mu.RLock()
+	var keys []db.RateKey
+	for _, key := range s.keys {
+		if key.Cloud != cloud || (region != "" && key.Region != region) {
+			continue
+		}
+		if (filter.Service != "" && key.Service != filter.Service) || (filter.ProductFamily != "" && key.ProductFamily != filter.ProductFamily) {
+			continue
+		}
+		if db.AttributesContain(key.Attributes, filter.Attributes) {
+			keys = append(keys, copyKey(key))
+		}
+	}
+	s.mu.RUnlock()
+
+	attrs := make(map[uuid.UUID]string, len(keys))
+	for _, key := range keys {
+		attrs[key.ID] = db.AttributesSortKey(key.Attributes)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.Service != b.Service {
+			return a.Service < b.Service
+		}
+		if a.ProductFamily != b.ProductFamily {
+			return a.ProductFamily < b.ProductFamily
+		}
+		if attrs[a.ID] != attrs[b.ID] {
+			return attrs[a.ID] < attrs[b.ID]
+		}
+		return a.Region < b.Region
+	})
+	if filter.Limit > 0 && len(keys) > filter.Limit {
+		keys = keys[:filter.Limit]
+	}
+	return keys, nil
+}
+
+// CreateRate inserts a pricing rate
+func (s *MemoryStore) CreateRate(ctx context.Context, rate *db.PricingRate) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.createRate(rate)
+}
+
+// BulkCreateRates inserts multiple rates atomically
+func (s *MemoryStore) BulkCreateRates(ctx context.Context, rates []*db.PricingRate) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	saved := s.save()
+	for _, rate := range rates {
+		if err := s.createRate(rate); err != nil {
+			s.restore(saved)
+			return err
+		}
+	}
+	return nil
+}
+
+// CountRates returns the count of rates in a snapshot
+func (s *MemoryStore) CountRates(ctx context.Context, snapshotID uuid.UUID) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	count := 0
+	for _, r := range s.rates {
+		if r.SnapshotID == snapshotID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// LockIngestion holds an in-process lock per cloud, region and alias
+func (s *MemoryStore) LockIngestion(ctx context.Context, cloud db.CloudProvider, region, alias string, wait bool) (func(), error) {
+	key := string(cloud) + "/" + region + "/" + alias
+	for {
+		s.lockMu.Lock()
+		held, ok := s.locks[key]
+		if !ok {
+			released := make(chan struct{})
+			s.locks[key] = released
+			s.lockMu.Unlock()
+
+			var once sync.Once
+			return func() {
+				once.Do(func() {
+					s.lockMu.Lock()
+					delete(s.locks, key)
+					s.lockMu.Unlock()
+					close(released)
+				})
+			}, nil
+		}
+		s.lockMu.Unlock()
+
+		if !wait {
+			return nil, db.ErrIngestionLocked
+		}
+		select {
+		case <-held:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// CountDistinctRateKeys returns the number of distinct rate keys in a snapshot
+func (s *MemoryStore) CountDistinctRateKeys(ctx context.Context, snapshotID uuid.UUID) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	keys := make(map[uuid.UUID]bool)
+	for _, r := range s.rates {
+		if r.SnapshotID == snapshotID {
+			keys[r.RateKeyID] = true
+		}
+	}
+	return len(keys), nil
+}
+
+// GetRatesBySnapshot returns every rate in a snapshot along with its rate key,
+// ordered like the PostgreSQL store
+func (s *MemoryStore) GetRatesBySnapshot(ctx context.Context, snapshotID uuid.UUID) ([]db.SnapshotRate, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []db.SnapshotRate
+	for _, r := range s.rates {
+		if r.SnapshotID == snapshotID {
+			result = append(result, db.SnapshotRate{RateKey: copyKey(s.keys[r.RateKeyID]), Rate: copyRate(r)})
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.RateKey.Service != b.RateKey.Service {
+			return a.RateKey.Service < b.RateKey.Service
+		}
+		if a.RateKey.ProductFamily != b.RateKey.ProductFamily {
+			return a.RateKey.ProductFamily < b.RateKey.ProductFamily
+		}
+		if a.Rate.Unit != b.Rate.Unit {
+			return a.Rate.Unit < b.Rate.Unit
+		}
+		return tierMinLess(a.Rate, b.Rate)
+	})
+	return result, nil
+}
+
+// GetRatesPage returns up to limit rates of a snapshot with IDs after the
+// given rate ID, ordered by ID like the PostgreSQL uuid comparison
+func (s *MemoryStore) GetRatesPage(ctx context.Context, snapshotID, after uuid.UUID, limit int) ([]db.SnapshotRate, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var result []db.SnapshotRate
+	for _, r := range s.rates {
+		if r.SnapshotID == snapshotID && bytes.Compare(r.ID[:], after[:]) > 0 {
+			result = append(result, db.SnapshotRate{RateKey: copyKey(s.keys[r.RateKeyID]), Rate: copyRate(r)})
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return bytes.Compare(result[i].Rate.ID[:], result[j].Rate.ID[:]) < 0 })
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+// DistinctCurrencies returns the currencies used by a snapshot's rates, sorted
+func (s *MemoryStore) DistinctCurrencies(ctx context.Context, snapshotID uuid.UUID) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	var currencies []string
+	for _, r := range s.rates {
+		if r.SnapshotID == snapshotID && !seen[r.Currency] {
+			seen[r.Currency] = true
+			currencies = append(currencies, r.Currency)
+		}
+	}
+	sort.Strings(currencies)
+	return currencies, nil
+}
+
+// ResolveRate looks up a rate from the active snapshot.
+// Dated rates effective on or before opts.AsOf win over undated ones; among
+// rates of the same date and tier the highest confidence wins.
+func (s *MemoryStore) ResolveRate(ctx context.Context, cloud db.CloudProvider, service, productFamily, region string, attrs map[string]string, unit, alias string, opts db.ResolveOptions) (*db.ResolvedRate, error) {
+	asOf := opts.AsOf
+	if asOf.IsZero() {
+		asOf = time.Now()
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	snapshot, candidates := s.matchRates(cloud, service, productFamily, region, attrs, unit, alias)
+	var best *db.PricingRate
+	for _, r := range candidates {
+		if r.EffectiveDate != nil && r.EffectiveDate.After(asOf) {
+			continue
+		}
+		if opts.Currency != "" && r.Currency != opts.Currency {
+			continue
+		}
+		if best == nil || betterRate(r, best) {
+			best = r
+		}
+	}
+	if best == nil {
+		return nil, nil
+	}
+
+	return &db.ResolvedRate{
+		Price:      best.Price,
+		Currency:   best.Currency,
+		Confidence: best.Confidence,
+		TierMin:    best.TierMin,
+		TierMax:    best.TierMax,
+		SnapshotID: snapshot.ID,
+		Source:     snapshot.Source,
+	}, nil
+}
+
+// ResolveAllMatching returns the rate ResolveRate would pick for every rate
+// key matching attrs, ordered by db.AttributesSortKey like the PostgreSQL query
+func (s *MemoryStore) ResolveAllMatching(ctx context.Context, cloud db.CloudProvider, service, productFamily, region string, attrs map[string]string, unit, alias string, opts db.ResolveOptions) ([]db.ResolvedRate, error) {
+	asOf := opts.AsOf
+	if asOf.IsZero() {
+		asOf = time.Now()
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	snapshot, candidates := s.matchRates(cloud, service, productFamily, region, attrs, unit, alias)
+	best := make(map[uuid.UUID]*db.PricingRate)
+	for _, r := range candidates {
+		if r.EffectiveDate != nil && r.EffectiveDate.After(asOf) {
+			continue
+		}
+		if opts.Currency != "" && r.Currency != opts.Currency {
+			continue
+		}
+		if cur, ok := best[r.RateKeyID]; !ok || betterRate(r, cur) {
+			best[r.RateKeyID] = r
+		}
+	}
+
+	sortKeys := make(map[uuid.UUID]string, len(best))
+	keyIDs := make([]uuid.UUID, 0, len(best))
+	for keyID := range best {
+		sortKeys[keyID] = db.AttributesSortKey(s.keys[keyID].Attributes)
+		keyIDs = append(keyIDs, keyID)
+	}
+	sort.Slice(keyIDs, func(i, j int) bool { return sortKeys[keyIDs[i]] < sortKeys[keyIDs[j]] })
+
+	rates := make([]db.ResolvedRate, 0, len(keyIDs))
+	for _, keyID := range keyIDs {
+		r := best[keyID]
+		rates = append(rates, db.ResolvedRate{
+			Price:      r.Price,
+			Currency:   r.Currency,
+			Confidence: r.Confidence,
+			TierMin:    r.TierMin,
+			TierMax:    r.TierMax,
+			SnapshotID: snapshot.ID,
+			Source:     snapshot.Source,
+			Attributes: copyKey(s.keys[keyID]).Attributes,
+		})
+	}
+	return rates, nil
+}
+
+// ResolveRatesBatch scans the snapshot's rates once, then picks the best rate
+// for each query as ResolveRate would
+func (s *MemoryStore) ResolveRatesBatch(ctx context.Context, snapshotID uuid.UUID, reqs []db.RateKeyQuery, opts db.ResolveOptions) (map[int]*db.ResolvedRate, error) {
+	asOf := opts.AsOf
+	if asOf.IsZero() {
+		asOf = time.Now()
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	results := make(map[int]*db.ResolvedRate)
+	snapshot := s.snapshot(snapshotID)
+	if snapshot == nil {
+		return results, nil
+	}
+
+	// service|family|unit -> usable rates of the snapshot
+	byKey := make(map[string][]*db.PricingRate)
+	for _, r := range s.rates {
+		if r.SnapshotID != snapshotID {
+			continue
+		}
+		if r.EffectiveDate != nil && r.EffectiveDate.After(asOf) {
+			continue
+		}
+		if opts.Currency != "" && r.Currency != opts.Currency {
+			continue
+		}
+		key := s.keys[r.RateKeyID]
+		group := key.Service + "|" + key.ProductFamily + "|" + r.Unit
+		byKey[group] = append(byKey[group], r)
+	}
+
+	for i, req := range reqs {
+		var best *db.PricingRate
+		for _, r := range byKey[req.Service+"|"+req.ProductFamily+"|"+req.Unit] {
+			if !db.AttributesContain(s.keys[r.RateKeyID].Attributes, req.Attributes) {
+				continue
+			}
+			if best == nil || betterRate(r, best) {
+				best = r
+			}
+		}
+		if best == nil {
+			continue
+		}
+		results[i] = &db.ResolvedRate{
+			Price:      best.Price,
+			Currency:   best.Currency,
+			Confidence: best.Confidence,
+			TierMin:    best.TierMin,
+			TierMax:    best.TierMax,
+			SnapshotID: snapshot.ID,
+			Source:     snapshot.Source,
+		}
+	}
+	return results, nil
+}
+
+// ResolveTieredRates returns all tiers for a rate, lowest tier first, from
+// each rate key's latest effective period on or before opts.AsOf
+func (s *MemoryStore) ResolveTieredRates(ctx context.Context, cloud db.CloudProvider, service, productFamily, region string, attrs map[string]string, unit, alias string, opts db.ResolveOptions) ([]db.TieredRate, error) {
+	asOf := opts.AsOf
+	if asOf.IsZero() {
+		asOf = time.Now()
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, candidates := s.matchRates(cloud, service, productFamily, region, attrs, unit, alias)
+	var usable []*db.PricingRate
+	latest := make(map[uuid.UUID]*db.PricingRate)
+	for _, r := range candidates {
+		if r.EffectiveDate != nil && r.EffectiveDate.After(asOf) {
+			continue
+		}
+		if opts.Currency != "" && r.Currency != opts.Currency {
+			continue
+		}
+		usable = append(usable, r)
+		if cur, ok := latest[r.RateKeyID]; !ok || laterEffective(r, cur) {
+			latest[r.RateKeyID] = r
+		}
+	}
+	sort.SliceStable(usable, func(i, j int) bool { return tierMinLess(*usable[i], *usable[j]) })
+
+	var tiers []db.TieredRate
+	for _, r := range usable {
+		if laterEffective(latest[r.RateKeyID], r) {
+			continue // an older period of the same rate key
+		}
+		t := db.TieredRate{Price: r.Price, Currency: r.Currency, Confidence: r.Confidence, Max: r.TierMax}
+		if r.TierMin != nil {
+			t.Min = *r.TierMin
+		}
+		tiers = append(tiers, t)
+	}
+	return tiers, nil
+}
+
+// BeginTx starts a transaction whose writes are applied on Commit
+func (s *MemoryStore) BeginTx(ctx context.Context) (db.Tx, error) {
+	return &MemoryTx{store: s}, nil
+}
+
+// createSnapshot inserts a snapshot; callers hold mu
+func (s *MemoryStore) createSnapshot(snapshot *db.PricingSnapshot) error {
+	if s.snapshot(snapshot.ID) != nil {
+		return fmt.Errorf("snapshot %s already exists", snapshot.ID)
+	}
+	for _, snap := range s.snapshots {
+		if snap.Cloud == snapshot.Cloud && snap.Region == snapshot.Region &&
+			snap.ProviderAlias == snapshot.ProviderAlias && snap.Hash == snapshot.Hash {
+			return fmt.Errorf("snapshot with hash %s already exists for %s/%s/%s",
+				snapshot.Hash, snapshot.Cloud, snapshot.Region, snapshot.ProviderAlias)
+		}
+	}
+	if snapshot.IsActive && s.activeSnapshot(snapshot.Cloud, snapshot.Region, snapshot.ProviderAlias) != nil {
+		return fmt.Errorf("an active snapshot already exists for %s/%s/%s", snapshot.Cloud, snapshot.Region, snapshot.ProviderAlias)
+	}
+
+	stored := copySnapshot(snapshot)
+	stored.State = "pending"
+	stored.CreatedAt = time.Now()
+	s.snapshots = append(s.snapshots, stored)
+	return nil
+}
+
+// activate makes id the only active snapshot for its cloud/region/alias; callers hold mu
+func (s *MemoryStore) activate(id uuid.UUID) error {
+	target := s.snapshot(id)
+	if target == nil {
+		return fmt.Errorf("snapshot not found: %s", id)
+	}
+	now := time.Now()
+	for _, snap := range s.snapshots {
+		if snap.Cloud == target.Cloud && snap.Region == target.Region && snap.ProviderAlias == target.ProviderAlias &&
+			snap.IsActive && snap.ID != id {
+			snap.IsActive = false
+			snap.State = "archived"
+			snap.ValidTo = &now
+		}
+	}
+	target.IsActive = true
+	target.State = "ready"
+	target.ValidTo = nil
+	return nil
+}
+
+// upsertRateKey returns the existing key with the same identity or stores a new one; callers hold mu
+func (s *MemoryStore) upsertRateKey(key *db.RateKey) (*db.RateKey, error) {
+	identity, err := rateKeyIdentity(key)
+	if err != nil {
+		return nil, err
+	}
+	if id, ok := s.keyIndex[identity]; ok {
+		key.ID = id
+		key.CreatedAt = s.keys[id].CreatedAt
+		return key, nil
+	}
+
+	if key.ID == uuid.Nil {
+		key.ID = uuid.New()
+	}
+	key.CreatedAt = time.Now()
+	stored := copyKey(key)
+	s.keys[key.ID] = &stored
+	s.keyIndex[identity] = key.ID
+	return key, nil
+}
+
+// checkRate enforces the foreign keys of pricing_rates; callers hold mu
+func (s *MemoryStore) checkRate(rate *db.PricingRate) error {
+	if s.snapshot(rate.SnapshotID) == nil {
+		return fmt.Errorf("rate %s references unknown snapshot %s", rate.ID, rate.SnapshotID)
+	}
+	if _, ok := s.keys[rate.RateKeyID]; !ok {
+		return fmt.Errorf("rate %s references unknown rate key %s", rate.ID, rate.RateKeyID)
+	}
+	// unique_rate: NULL tier bounds and effective dates are distinct, as in
+	// PostgreSQL
+	if rate.TierMin == nil || rate.TierMax == nil || rate.EffectiveDate == nil {
+		return nil
+	}
+	for _, r := range s.rates {
+		if r.SnapshotID == rate.SnapshotID && r.RateKeyID == rate.RateKeyID && r.Unit == rate.Unit &&
+			r.TierMin != nil && r.TierMax != nil && r.TierMin.Equal(*rate.TierMin) && r.TierMax.Equal(*rate.TierMax) &&
+			r.EffectiveDate != nil && r.EffectiveDate.Equal(*rate.EffectiveDate) {
+			return fmt.Errorf("duplicate rate for key %s in snapshot %s", rate.RateKeyID, rate.SnapshotID)
+		}
+	}
+	return nil
+}
+
+// createRate stores a rate; callers hold mu
+func (s *MemoryStore) createRate(rate *db.PricingRate) error {
+	if err := s.checkRate(rate); err != nil {
+		return err
+	}
+	stored := copyRate(rate)
+	stored.CreatedAt = time.Now()
+	s.rates = append(s.rates, &stored)
+	return nil
+}
+
+// snapshot finds a snapshot by ID; callers hold mu
+func (s *MemoryStore) snapshot(id uuid.UUID) *db.PricingSnapshot {
+	for _, snap := range s.snapshots {
+		if snap.ID == id {
+			return snap
+		}
+	}
+	return nil
+}
+
+// activeSnapshot finds the active snapshot for a target; callers hold mu
+func (s *MemoryStore) activeSnapshot(cloud db.CloudProvider, region, alias string) *db.PricingSnapshot {
+	for _, snap := range s.snapshots {
+		if snap.Cloud == cloud && snap.Region == region && snap.ProviderAlias == alias && snap.IsActive {
+			return snap
+		}
+	}
+	return nil
+}
+
+// matchRates returns the active snapshot's rates whose key contains attrs; callers hold mu
+func (s *MemoryStore) matchRates(cloud db.CloudProvider, service, productFamily, region string, attrs map[string]string, unit, alias string) (*db.PricingSnapshot, []*db.PricingRate) {
+	snapshot := s.activeSnapshot(cloud, region, alias)
+	if snapshot == nil {
+		return nil, nil
+	}
+
+	var matches []*db.PricingRate
+	for _, r := range s.rates {
+		if r.SnapshotID != snapshot.ID || r.Unit != unit {
+			continue
+		}
+		key := s.keys[r.RateKeyID]
+		if key.Cloud != cloud || key.Region != region || key.Service != service || key.ProductFamily != productFamily {
+			continue
+		}
+		if db.AttributesContain(key.Attributes, attrs) {
+			matches = append(matches, r)
+		}
+	}
+	return snapshot, matches
+}
+
+// betterRate orders by effective_date DESC NULLS LAST, then tier_min NULLS
+// FIRST, then confidence DESC
+func betterRate(a, b *db.PricingRate) bool {
+	switch {
+	case a.EffectiveDate != nil && b.EffectiveDate == nil:
+		return true
+	case a.EffectiveDate == nil && b.EffectiveDate != nil:
+		return false
+	case a.EffectiveDate != nil && !a.EffectiveDate.Equal(*b.EffectiveDate):
+		return a.EffectiveDate.After(*b.EffectiveDate)
+	case tierMinLess(*a, *b):
+		return true
+	case tierMinLess(*b, *a):
+		return false
+	}
+	return a.Confidence > b.Confidence
+}
+
+// laterEffective orders rates by effective_date DESC NULLS LAST: dated rates
+// are later than undated ones
+func laterEffective(a, b *db.PricingRate) bool {
+	switch {
+	case a.EffectiveDate == nil:
+		return false
+	case b.EffectiveDate == nil:
+		return true
+	}
+	return a.EffectiveDate.After(*b.EffectiveDate)
+}
+
+// tierMinLess orders rates by tier_min NULLS FIRST
+func tierMinLess(a, b db.PricingRate) bool {
+	switch {
+	case a.TierMin == nil:
+		return b.TierMin != nil
+	case b.TierMin == nil:
+		return false
+	}
+	return a.TierMin.LessThan(*b.TierMin)
+}
+
+// rateKeyIdentity is the unique_rate_key constraint as a string.
+// encoding/json sorts map keys, so equal attribute sets produce equal identities.
+func rateKeyIdentity(key *db.RateKey) (string, error) {
+	attrs := key.Attributes
+	if attrs == nil {
+		attrs = map[string]string{}
+	}
+	data, err := json.Marshal(attrs)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s|%s|%s|%s|%s", key.Cloud, key.Service, key.ProductFamily, key.Region, data), nil
+}
+
+// copySnapshot returns a deep copy of a snapshot. Empty metadata becomes nil,
+// as it reads back from PostgreSQL.
+func copySnapshot(snap *db.PricingSnapshot) *db.PricingSnapshot {
+	copied := *snap
+	copied.Metadata = nil
+	if len(snap.Metadata) > 0 {
+		copied.Metadata = make(map[string]string, len(snap.Metadata))
+		for k, v := range snap.Metadata {
+			copied.Metadata[k] = v
+		}
+	}
+	return &copied
+}
+
+// copyRate returns a copy of a rate with its own labels. Empty labels become
+// nil, as they read back from PostgreSQL.
+func copyRate(rate *db.PricingRate) db.PricingRate {
+	copied := *rate
+	copied.Labels = nil
+	if len(rate.Labels) > 0 {
+		copied.Labels = make(map[string]string, len(rate.Labels))
+		for k, v := range rate.Labels {
+			copied.Labels[k] = v
+		}
+	}
+	return copied
+}
+
+// copyKey returns a deep copy of a rate key
+func copyKey(key *db.RateKey) db.RateKey {
+	copied := *key
+	copied.Attributes = make(map[string]string, len(key.Attributes))
+	for k, v := range key.Attributes {
+		copied.Attributes[k] = v
+	}
+	return copied
+}
@@ -0,0 +1,114 @@
+// Package memstore - MemoryStore-specific tests
+package memstore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"testing"
+
+	"terraform-cost/db"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+func TestMemoryTxFailedCommitLeavesStoreUntouched(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	previous := commitSnapshot(t, store, "us-east-1", "hash-1", []conformanceRate{
+		{attrs: map[string]string{"instance_type": "t3.micro"}, price: "0.0104"},
+	})
+
+	tx, _ := store.BeginTx(ctx)
+	snapshot := db.NewSnapshotBuilder(db.AWS, "us-east-1", "test").Build("hash-2")
+	tx.CreateSnapshot(ctx, snapshot)
+	tx.ActivateSnapshot(ctx, snapshot.ID)
+	// References a rate key that was never written
+	tx.CreateRate(ctx, &db.PricingRate{
+		ID:         uuid.New(),
+		SnapshotID: snapshot.ID,
+		RateKeyID:  uuid.New(),
+		Unit:       "hrs",
+		Price:      decimal.NewFromFloat(0.02),
+		Currency:   "USD",
+	})
+	if err := tx.Commit(); err == nil {
+		t.Fatal("expected commit to fail on a dangling rate key")
+	}
+
+	if got, _ := store.GetSnapshot(ctx, snapshot.ID); got != nil {
+		t.Errorf("failed commit left snapshot %s behind", snapshot.ID)
+	}
+	active, _ := store.GetActiveSnapshot(ctx, db.AWS, "us-east-1", "default")
+	if active == nil || active.ID != previous.ID {
+		t.Errorf("failed commit changed the active snapshot to %+v", active)
+	}
+}
+
+func TestMemoryStoreRejectsDuplicateHash(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	first := db.NewSnapshotBuilder(db.AWS, "us-east-1", "test").Build("same")
+	if err := store.CreateSnapshot(ctx, first); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	second := db.NewSnapshotBuilder(db.AWS, "us-east-1", "test").Build("same")
+	if err := store.CreateSnapshot(ctx, second); err == nil {
+		t.Error("expected duplicate content hash to be rejected")
+	}
+}
+
+// BenchmarkResolveRatesBatch compares one batch lookup with a lookup per key.
+// Set DB_URL to measure the round trips saved against PostgreSQL.
+func BenchmarkResolveRatesBatch(b *testing.B) {
+	ctx := context.Background()
+	var store db.PricingStore = NewMemoryStore()
+	region := "bench-region"
+	if url := os.Getenv("DB_URL"); url != "" {
+		pg, err := db.NewPostgresStoreFromURL(url)
+		if err != nil {
+			b.Fatalf("failed to connect: %v", err)
+		}
+		store = pg
+		region = "bench-" + uuid.NewString()[:8]
+		b.Cleanup(func() {
+			conn, err := sql.Open("postgres", url)
+			if err == nil {
+				conn.ExecContext(ctx, "DELETE FROM pricing_snapshots WHERE region = $1", region)
+				conn.ExecContext(ctx, "DELETE FROM pricing_rate_keys WHERE region = $1", region)
+				conn.Close()
+			}
+			pg.Close()
+		})
+	}
+
+	const keys = 200
+	rates := make([]conformanceRate, keys)
+	reqs := make([]db.RateKeyQuery, keys)
+	for i := range rates {
+		attrs := map[string]string{"instance_type": fmt.Sprintf("m5.size%d", i), "os": "linux"}
+		rates[i] = conformanceRate{attrs: attrs, price: "0.0100"}
+		reqs[i] = db.RateKeyQuery{Service: "AmazonEC2", ProductFamily: "Compute Instance", Attributes: attrs, Unit: "hrs"}
+	}
+	snapshot := commitSnapshot(b, store, region, "hash-bench", rates)
+
+	b.Run("Single", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, req := range reqs {
+				if _, err := store.ResolveRate(ctx, db.AWS, req.Service, req.ProductFamily, region, req.Attributes, req.Unit, "default", db.ResolveOptions{}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+	b.Run("Batch", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := store.ResolveRatesBatch(ctx, snapshot.ID, reqs, db.ResolveOptions{}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,166 @@
+// Package memstore - Buffered transactions for MemoryStore
+package memstore
+
+import (
+	"context"
+	"fmt"
+
+	"terraform-cost/db"
+
+	"github.com/google/uuid"
+)
+
+// MemoryTx implements db.Tx. Writes are buffered and applied to the store
+// atomically on Commit; Rollback discards them.
+type MemoryTx struct {
+	store *MemoryStore
+	ops   []func(s *MemoryStore) error
+	keys  map[string]*db.RateKey  // keys upserted in this transaction
+	remap map[uuid.UUID]uuid.UUID // pending key ID -> ID committed concurrently
+	done  bool
+}
+
+// CreateSnapshot buffers a snapshot insert
+func (t *MemoryTx) CreateSnapshot(ctx context.Context, snapshot *db.PricingSnapshot) error {
+	copied := copySnapshot(snapshot)
+	return t.add(func(s *MemoryStore) error { return s.createSnapshot(copied) })
+}
+
+// UpsertRateKey returns the committed or pending key with the same identity,
+// or buffers a new one
+func (t *MemoryTx) UpsertRateKey(ctx context.Context, key *db.RateKey) (*db.RateKey, error) {
+	if t.done {
+		return nil, fmt.Errorf("transaction already finished")
+	}
+	identity, err := rateKeyIdentity(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if pending, ok := t.keys[identity]; ok {
+		key.ID = pending.ID
+		return key, nil
+	}
+	t.store.mu.RLock()
+	id, ok := t.store.keyIndex[identity]
+	t.store.mu.RUnlock()
+	if ok {
+		key.ID = id
+		return key, nil
+	}
+
+	if key.ID == uuid.Nil {
+		key.ID = uuid.New()
+	}
+	copied := copyKey(key)
+	if t.keys == nil {
+		t.keys = make(map[string]*db.RateKey)
+	}
+	t.keys[identity] = &copied
+	t.ops = append(t.ops, func(s *MemoryStore) error {
+		pending := copied.ID
+		stored, err := s.upsertRateKey(&copied)
+		if err != nil {
+			return err
+		}
+		if stored.ID != pending {
+			// Another transaction committed the same key first
+			t.remap[pending] = stored.ID
+		}
+		return nil
+	})
+	return key, nil
+}
+
+// CreateRate buffers a rate insert
+func (t *MemoryTx) CreateRate(ctx context.Context, rate *db.PricingRate) error {
+	copied := *rate
+	return t.add(func(s *MemoryStore) error {
+		if id, ok := t.remap[copied.RateKeyID]; ok {
+			copied.RateKeyID = id
+		}
+		return s.createRate(&copied)
+	})
+}
+
+// ActivateSnapshot buffers a snapshot activation
+func (t *MemoryTx) ActivateSnapshot(ctx context.Context, id uuid.UUID) error {
+	return t.add(func(s *MemoryStore) error { return s.activate(id) })
+}
+
+// Commit applies every buffered write, or none if any fails
+func (t *MemoryTx) Commit() error {
+	if t.done {
+		return fmt.Errorf("transaction already finished")
+	}
+	t.done = true
+	t.remap = make(map[uuid.UUID]uuid.UUID)
+
+	s := t.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	saved := s.save()
+	for _, op := range t.ops {
+		if err := op(s); err != nil {
+			s.restore(saved)
+			return err
+		}
+	}
+	return nil
+}
+
+// Rollback discards the buffered writes
+func (t *MemoryTx) Rollback() error {
+	t.done = true
+	t.ops = nil
+	return nil
+}
+
+// add buffers a write
+func (t *MemoryTx) add(op func(s *MemoryStore) error) error {
+	if t.done {
+		return fmt.Errorf("transaction already finished")
+	}
+	t.ops = append(t.ops, op)
+	return nil
+}
+
+// storeState is a copy of the store used to undo a failed commit
+type storeState struct {
+	snapshots []db.PricingSnapshot
+	keys      map[uuid.UUID]*db.RateKey
+	keyIndex  map[string]uuid.UUID
+	rates     int
+}
+
+// save captures the state a commit may change; callers hold mu
+func (s *MemoryStore) save() storeState {
+	state := storeState{
+		snapshots: make([]db.PricingSnapshot, len(s.snapshots)),
+		keys:      make(map[uuid.UUID]*db.RateKey, len(s.keys)),
+		keyIndex:  make(map[string]uuid.UUID, len(s.keyIndex)),
+		rates:     len(s.rates),
+	}
+	for i, snap := range s.snapshots {
+		state.snapshots[i] = *snap
+	}
+	for k, v := range s.keys {
+		state.keys[k] = v
+	}
+	for k, v := range s.keyIndex {
+		state.keyIndex[k] = v
+	}
+	return state
+}
+
+// restore undoes a failed commit; callers hold mu
+func (s *MemoryStore) restore(state storeState) {
+	s.snapshots = s.snapshots[:len(state.snapshots)]
+	for i := range state.snapshots {
+		*s.snapshots[i] = state.snapshots[i]
+	}
+	s.keys = state.keys
+	s.keyIndex = state.keyIndex
+	s.rates = s.rates[:state.rates]
+}
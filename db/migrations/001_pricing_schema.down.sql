@@ -0,0 +1,11 @@
+-- Rollback: Pricing database schema
+-- Drops every object created by 001. All pricing data is lost.
+
+DROP FUNCTION IF EXISTS get_active_rate(TEXT, TEXT, TEXT, TEXT, JSONB, TEXT, TEXT);
+DROP FUNCTION IF EXISTS activate_snapshot(UUID);
+
+DROP TABLE IF EXISTS service_catalog;
+DROP TABLE IF EXISTS pricing_metadata;
+DROP TABLE IF EXISTS pricing_rates;
+DROP TABLE IF EXISTS pricing_rate_keys;
+DROP TABLE IF EXISTS pricing_snapshots;
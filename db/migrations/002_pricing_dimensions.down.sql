@@ -0,0 +1,12 @@
+-- Rollback: Pricing dimensions schema
+
+DROP FUNCTION IF EXISTS get_rate_with_fallback(TEXT, TEXT, TEXT, JSONB, TEXT, TEXT);
+DROP FUNCTION IF EXISTS complete_ingestion(UUID, TEXT);
+DROP FUNCTION IF EXISTS validate_ingestion(UUID);
+
+DROP TABLE IF EXISTS dimension_fallback_rules;
+DROP TABLE IF EXISTS pricing_snapshot_source;
+DROP TABLE IF EXISTS ingestion_contracts;
+DROP TABLE IF EXISTS pricing_snapshot_ingestion;
+DROP TABLE IF EXISTS pricing_rate_dimensions;
+DROP TABLE IF EXISTS pricing_dimensions;
@@ -0,0 +1,10 @@
+-- Rollback: Scale hardening (coverage, allowlists, drift tracking)
+
+DROP FUNCTION IF EXISTS is_snapshot_complete(UUID, FLOAT);
+DROP FUNCTION IF EXISTS detect_pricing_drift(UUID, UUID, FLOAT);
+DROP FUNCTION IF EXISTS calculate_service_coverage(UUID, TEXT);
+
+DROP TABLE IF EXISTS pricing_drift_summary;
+DROP TABLE IF EXISTS pricing_drift_records;
+DROP TABLE IF EXISTS dimension_allowlists;
+DROP TABLE IF EXISTS pricing_snapshot_coverage;
@@ -0,0 +1,40 @@
+-- Rollback: Snapshot lifecycle state
+-- Restores the 001 activate_snapshot, which only toggles is_active.
+
+DROP FUNCTION IF EXISTS fail_snapshot(UUID, TEXT);
+
+CREATE OR REPLACE FUNCTION activate_snapshot(p_snapshot_id UUID)
+RETURNS VOID AS $$
+DECLARE
+    v_cloud TEXT;
+    v_region TEXT;
+    v_alias TEXT;
+BEGIN
+    -- Get snapshot details
+    SELECT cloud, region, provider_alias 
+    INTO v_cloud, v_region, v_alias
+    FROM pricing_snapshots 
+    WHERE id = p_snapshot_id;
+    
+    IF NOT FOUND THEN
+        RAISE EXCEPTION 'Snapshot not found: %', p_snapshot_id;
+    END IF;
+    
+    -- Deactivate existing
+    UPDATE pricing_snapshots
+    SET is_active = FALSE
+    WHERE cloud = v_cloud 
+      AND region = v_region 
+      AND provider_alias = v_alias
+      AND is_active = TRUE;
+    
+    -- Activate new
+    UPDATE pricing_snapshots
+    SET is_active = TRUE
+    WHERE id = p_snapshot_id;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP INDEX IF EXISTS idx_snapshots_state;
+
+ALTER TABLE pricing_snapshots DROP COLUMN IF EXISTS state;
@@ -0,0 +1,5 @@
+-- Rollback: Region aliases
+
+DROP VIEW IF EXISTS region_equivalence_groups;
+DROP FUNCTION IF EXISTS get_canonical_region(TEXT, TEXT);
+DROP TABLE IF EXISTS pricing_region_aliases;
@@ -0,0 +1,4 @@
+-- Rollback: Ensure active snapshots are in 'ready' state
+-- Data-only fix; the previous 'pending' states are not recoverable and
+-- 'ready' is valid for an active snapshot, so there is nothing to undo.
+SELECT 1;
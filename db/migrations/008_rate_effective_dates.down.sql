@@ -0,0 +1,20 @@
+-- Rollback: Multiple effective dates per rate
+-- The old uniqueness rule allows one price per rate, so future-dated
+-- duplicates are dropped first, keeping the earliest effective price.
+
+DELETE FROM pricing_rates a
+USING pricing_rates b
+WHERE a.id != b.id
+  AND a.snapshot_id = b.snapshot_id
+  AND a.rate_key_id = b.rate_key_id
+  AND a.unit = b.unit
+  AND a.tier_min = b.tier_min
+  AND a.tier_max = b.tier_max
+  AND (a.effective_date > b.effective_date
+       OR (a.effective_date IS NOT NULL AND b.effective_date IS NULL)
+       OR (a.effective_date IS NOT DISTINCT FROM b.effective_date AND a.id > b.id));
+
+ALTER TABLE pricing_rates DROP CONSTRAINT IF EXISTS unique_rate;
+
+ALTER TABLE pricing_rates
+ADD CONSTRAINT unique_rate UNIQUE (snapshot_id, rate_key_id, unit, tier_min, tier_max);
@@ -0,0 +1,8 @@
+-- Migration: Allow multiple effective dates per rate
+-- A snapshot may carry both the current and a future-dated price for the
+-- same rate key, so effective_date must be part of the uniqueness rule.
+
+ALTER TABLE pricing_rates DROP CONSTRAINT IF EXISTS unique_rate;
+
+ALTER TABLE pricing_rates
+ADD CONSTRAINT unique_rate UNIQUE (snapshot_id, rate_key_id, unit, tier_min, tier_max, effective_date);
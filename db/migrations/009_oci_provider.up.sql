@@ -0,0 +1,36 @@
+-- Migration: Allow Oracle Cloud (OCI) as a cloud provider
+-- The cloud CHECK constraints were declared inline in 001-003, so they
+-- carry Postgres's generated <table>_cloud_check names.
+
+ALTER TABLE pricing_snapshots DROP CONSTRAINT IF EXISTS pricing_snapshots_cloud_check;
+ALTER TABLE pricing_snapshots
+ADD CONSTRAINT pricing_snapshots_cloud_check CHECK (cloud IN ('aws', 'azure', 'gcp', 'oci'));
+
+ALTER TABLE pricing_rate_keys DROP CONSTRAINT IF EXISTS pricing_rate_keys_cloud_check;
+ALTER TABLE pricing_rate_keys
+ADD CONSTRAINT pricing_rate_keys_cloud_check CHECK (cloud IN ('aws', 'azure', 'gcp', 'oci'));
+
+ALTER TABLE service_catalog DROP CONSTRAINT IF EXISTS service_catalog_cloud_check;
+ALTER TABLE service_catalog
+ADD CONSTRAINT service_catalog_cloud_check CHECK (cloud IN ('aws', 'azure', 'gcp', 'oci'));
+
+ALTER TABLE pricing_dimensions DROP CONSTRAINT IF EXISTS pricing_dimensions_cloud_check;
+ALTER TABLE pricing_dimensions
+ADD CONSTRAINT pricing_dimensions_cloud_check CHECK (cloud IN ('aws', 'azure', 'gcp', 'oci'));
+
+ALTER TABLE ingestion_contracts DROP CONSTRAINT IF EXISTS ingestion_contracts_cloud_check;
+ALTER TABLE ingestion_contracts
+ADD CONSTRAINT ingestion_contracts_cloud_check CHECK (cloud IN ('aws', 'azure', 'gcp', 'oci'));
+
+ALTER TABLE dimension_fallback_rules DROP CONSTRAINT IF EXISTS dimension_fallback_rules_cloud_check;
+ALTER TABLE dimension_fallback_rules
+ADD CONSTRAINT dimension_fallback_rules_cloud_check CHECK (cloud IN ('aws', 'azure', 'gcp', 'oci'));
+
+ALTER TABLE dimension_allowlists DROP CONSTRAINT IF EXISTS dimension_allowlists_cloud_check;
+ALTER TABLE dimension_allowlists
+ADD CONSTRAINT dimension_allowlists_cloud_check CHECK (cloud IN ('aws', 'azure', 'gcp', 'oci'));
+
+INSERT INTO service_catalog (cloud, service, product_family, description, is_billable) VALUES
+('oci', 'Compute', 'Compute', 'OCI VM and bare metal instances', true),
+('oci', 'Block Storage', 'Storage', 'OCI block volumes', true)
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,39 @@
+-- Rollback: DigitalOcean provider
+-- Removes DigitalOcean data, which the restored cloud checks would reject.
+-- Rates cascade from the deleted snapshots and rate keys.
+
+DELETE FROM pricing_snapshots WHERE cloud = 'digitalocean';
+DELETE FROM pricing_rate_keys WHERE cloud = 'digitalocean';
+DELETE FROM service_catalog WHERE cloud = 'digitalocean';
+DELETE FROM pricing_dimensions WHERE cloud = 'digitalocean';
+DELETE FROM ingestion_contracts WHERE cloud = 'digitalocean';
+DELETE FROM dimension_fallback_rules WHERE cloud = 'digitalocean';
+DELETE FROM dimension_allowlists WHERE cloud = 'digitalocean';
+
+ALTER TABLE pricing_snapshots DROP CONSTRAINT IF EXISTS pricing_snapshots_cloud_check;
+ALTER TABLE pricing_snapshots
+ADD CONSTRAINT pricing_snapshots_cloud_check CHECK (cloud IN ('aws', 'azure', 'gcp', 'oci'));
+
+ALTER TABLE pricing_rate_keys DROP CONSTRAINT IF EXISTS pricing_rate_keys_cloud_check;
+ALTER TABLE pricing_rate_keys
+ADD CONSTRAINT pricing_rate_keys_cloud_check CHECK (cloud IN ('aws', 'azure', 'gcp', 'oci'));
+
+ALTER TABLE service_catalog DROP CONSTRAINT IF EXISTS service_catalog_cloud_check;
+ALTER TABLE service_catalog
+ADD CONSTRAINT service_catalog_cloud_check CHECK (cloud IN ('aws', 'azure', 'gcp', 'oci'));
+
+ALTER TABLE pricing_dimensions DROP CONSTRAINT IF EXISTS pricing_dimensions_cloud_check;
+ALTER TABLE pricing_dimensions
+ADD CONSTRAINT pricing_dimensions_cloud_check CHECK (cloud IN ('aws', 'azure', 'gcp', 'oci'));
+
+ALTER TABLE ingestion_contracts DROP CONSTRAINT IF EXISTS ingestion_contracts_cloud_check;
+ALTER TABLE ingestion_contracts
+ADD CONSTRAINT ingestion_contracts_cloud_check CHECK (cloud IN ('aws', 'azure', 'gcp', 'oci'));
+
+ALTER TABLE dimension_fallback_rules DROP CONSTRAINT IF EXISTS dimension_fallback_rules_cloud_check;
+ALTER TABLE dimension_fallback_rules
+ADD CONSTRAINT dimension_fallback_rules_cloud_check CHECK (cloud IN ('aws', 'azure', 'gcp', 'oci'));
+
+ALTER TABLE dimension_allowlists DROP CONSTRAINT IF EXISTS dimension_allowlists_cloud_check;
+ALTER TABLE dimension_allowlists
+ADD CONSTRAINT dimension_allowlists_cloud_check CHECK (cloud IN ('aws', 'azure', 'gcp', 'oci'));
@@ -0,0 +1,36 @@
+-- Migration: Allow DigitalOcean as a cloud provider
+-- Widens the cloud CHECK constraints replaced in 009.
+
+ALTER TABLE pricing_snapshots DROP CONSTRAINT IF EXISTS pricing_snapshots_cloud_check;
+ALTER TABLE pricing_snapshots
+ADD CONSTRAINT pricing_snapshots_cloud_check CHECK (cloud IN ('aws', 'azure', 'gcp', 'oci', 'digitalocean'));
+
+ALTER TABLE pricing_rate_keys DROP CONSTRAINT IF EXISTS pricing_rate_keys_cloud_check;
+ALTER TABLE pricing_rate_keys
+ADD CONSTRAINT pricing_rate_keys_cloud_check CHECK (cloud IN ('aws', 'azure', 'gcp', 'oci', 'digitalocean'));
+
+ALTER TABLE service_catalog DROP CONSTRAINT IF EXISTS service_catalog_cloud_check;
+ALTER TABLE service_catalog
+ADD CONSTRAINT service_catalog_cloud_check CHECK (cloud IN ('aws', 'azure', 'gcp', 'oci', 'digitalocean'));
+
+ALTER TABLE pricing_dimensions DROP CONSTRAINT IF EXISTS pricing_dimensions_cloud_check;
+ALTER TABLE pricing_dimensions
+ADD CONSTRAINT pricing_dimensions_cloud_check CHECK (cloud IN ('aws', 'azure', 'gcp', 'oci', 'digitalocean'));
+
+ALTER TABLE ingestion_contracts DROP CONSTRAINT IF EXISTS ingestion_contracts_cloud_check;
+ALTER TABLE ingestion_contracts
+ADD CONSTRAINT ingestion_contracts_cloud_check CHECK (cloud IN ('aws', 'azure', 'gcp', 'oci', 'digitalocean'));
+
+ALTER TABLE dimension_fallback_rules DROP CONSTRAINT IF EXISTS dimension_fallback_rules_cloud_check;
+ALTER TABLE dimension_fallback_rules
+ADD CONSTRAINT dimension_fallback_rules_cloud_check CHECK (cloud IN ('aws', 'azure', 'gcp', 'oci', 'digitalocean'));
+
+ALTER TABLE dimension_allowlists DROP CONSTRAINT IF EXISTS dimension_allowlists_cloud_check;
+ALTER TABLE dimension_allowlists
+ADD CONSTRAINT dimension_allowlists_cloud_check CHECK (cloud IN ('aws', 'azure', 'gcp', 'oci', 'digitalocean'));
+
+INSERT INTO service_catalog (cloud, service, product_family, description, is_billable) VALUES
+('digitalocean', 'Droplets', 'Compute', 'DigitalOcean Droplets', true),
+('digitalocean', 'Volumes', 'Storage', 'DigitalOcean block storage volumes', true),
+('digitalocean', 'Managed Databases', 'Database', 'DigitalOcean managed databases', true)
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,21 @@
+-- Rollback: Close the validity window of superseded snapshots
+-- Restores the 007 activate_snapshot, which leaves valid_to untouched.
+
+CREATE OR REPLACE FUNCTION activate_snapshot(p_snapshot_id UUID)
+RETURNS VOID AS $$
+BEGIN
+    -- Archive previous active snapshots
+    UPDATE pricing_snapshots 
+    SET is_active = FALSE, state = 'archived'
+    WHERE is_active = TRUE 
+    AND cloud = (SELECT cloud FROM pricing_snapshots WHERE id = p_snapshot_id)
+    AND region = (SELECT region FROM pricing_snapshots WHERE id = p_snapshot_id)
+    AND provider_alias = (SELECT provider_alias FROM pricing_snapshots WHERE id = p_snapshot_id)
+    AND id != p_snapshot_id;
+    
+    -- Activate new snapshot with state='ready'
+    UPDATE pricing_snapshots 
+    SET is_active = TRUE, state = 'ready'
+    WHERE id = p_snapshot_id;
+END;
+$$ LANGUAGE plpgsql;
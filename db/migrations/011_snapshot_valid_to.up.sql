@@ -0,0 +1,22 @@
+-- Migration: Close the validity window of superseded snapshots
+-- activate_snapshot now stamps valid_to on the snapshot it archives, so
+-- valid_from/valid_to form a time series of pricing validity.
+
+CREATE OR REPLACE FUNCTION activate_snapshot(p_snapshot_id UUID)
+RETURNS VOID AS $$
+BEGIN
+    -- Archive previous active snapshots and end their validity
+    UPDATE pricing_snapshots 
+    SET is_active = FALSE, state = 'archived', valid_to = NOW()
+    WHERE is_active = TRUE 
+    AND cloud = (SELECT cloud FROM pricing_snapshots WHERE id = p_snapshot_id)
+    AND region = (SELECT region FROM pricing_snapshots WHERE id = p_snapshot_id)
+    AND provider_alias = (SELECT provider_alias FROM pricing_snapshots WHERE id = p_snapshot_id)
+    AND id != p_snapshot_id;
+    
+    -- Activate new snapshot with state='ready'; a reactivated snapshot is open-ended again
+    UPDATE pricing_snapshots 
+    SET is_active = TRUE, state = 'ready', valid_to = NULL
+    WHERE id = p_snapshot_id;
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,5 @@
+-- Rollback: Provenance metadata on snapshots
+
+DROP INDEX IF EXISTS idx_snapshots_metadata;
+
+ALTER TABLE pricing_snapshots DROP COLUMN IF EXISTS metadata;
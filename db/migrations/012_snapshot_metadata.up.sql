@@ -0,0 +1,8 @@
+-- Migration: Provenance metadata on snapshots
+-- Records what triggered an ingestion (CI job ID, git SHA, operator) as
+-- queryable key/value pairs, beyond the free-text source column.
+
+ALTER TABLE pricing_snapshots
+    ADD COLUMN IF NOT EXISTS metadata JSONB NOT NULL DEFAULT '{}';
+
+CREATE INDEX IF NOT EXISTS idx_snapshots_metadata ON pricing_snapshots USING GIN (metadata);
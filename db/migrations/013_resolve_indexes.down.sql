@@ -0,0 +1,14 @@
+-- Rollback: Indexes for rate resolution
+-- Restores the 001 indexes before dropping their replacements.
+
+CREATE INDEX IF NOT EXISTS idx_rate_keys_lookup
+ON pricing_rate_keys (cloud, service, product_family, region);
+
+CREATE INDEX IF NOT EXISTS idx_rate_keys_attributes
+ON pricing_rate_keys USING GIN (attributes);
+
+CREATE INDEX IF NOT EXISTS idx_rates_lookup ON pricing_rates (snapshot_id, rate_key_id);
+
+DROP INDEX IF EXISTS idx_rates_resolve;
+DROP INDEX IF EXISTS idx_rate_keys_resolve;
+DROP INDEX IF EXISTS idx_rate_keys_attributes_path;
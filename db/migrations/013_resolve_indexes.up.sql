@@ -0,0 +1,30 @@
+-- Migration: Indexes for rate resolution
+-- ResolveRate filters rate keys by equality on (cloud, region, service,
+-- product_family) and containment on attributes (rk.attributes @> $6), then
+-- joins pricing_rates on (snapshot_id, rate_key_id) filtered by unit.
+--
+-- idx_rate_keys_attributes (001) uses the default jsonb_ops class, which
+-- indexes every key and value separately. jsonb_path_ops only supports @>
+-- but indexes whole paths, so containment lookups touch far fewer entries
+-- and the index is a fraction of the size.
+
+CREATE INDEX IF NOT EXISTS idx_rate_keys_attributes_path
+ON pricing_rate_keys USING GIN (attributes jsonb_path_ops);
+
+-- Equality columns in the order the resolver joins them to the snapshot
+CREATE INDEX IF NOT EXISTS idx_rate_keys_resolve
+ON pricing_rate_keys (cloud, region, service, product_family);
+
+-- Lets the rate lookup check the unit without visiting the heap
+CREATE INDEX IF NOT EXISTS idx_rates_resolve
+ON pricing_rates (snapshot_id, rate_key_id, unit);
+
+-- Superseded by the indexes above: idx_rate_keys_resolve covers the same
+-- columns as idx_rate_keys_lookup, idx_rates_resolve extends idx_rates_lookup,
+-- and jsonb_path_ops serves every @> lookup the jsonb_ops index did
+DROP INDEX IF EXISTS idx_rate_keys_lookup;
+DROP INDEX IF EXISTS idx_rates_lookup;
+DROP INDEX IF EXISTS idx_rate_keys_attributes;
+
+ANALYZE pricing_rate_keys;
+ANALYZE pricing_rates;
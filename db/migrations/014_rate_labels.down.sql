@@ -0,0 +1,3 @@
+-- Rollback: Source labels on rates
+
+ALTER TABLE pricing_rates DROP COLUMN IF EXISTS labels;
@@ -0,0 +1,7 @@
+-- Migration: Source labels on rates
+-- Source identifiers (AWS SKU and rate code, GCP SKU ID, Azure SKU ID) kept
+-- per rate for traceability. Unlike rate key attributes they are never
+-- matched on, so no index is needed.
+
+ALTER TABLE pricing_rates
+    ADD COLUMN IF NOT EXISTS labels JSONB NOT NULL DEFAULT '{}';
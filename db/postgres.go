@@ -4,15 +4,34 @@ package db
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
+// Retryable transaction failures: the whole transaction can simply be rerun
+const (
+	sqlStateSerializationFailure = "40001"
+	sqlStateDeadlockDetected     = "40P01"
+)
+
+// IsRetryableTxError reports whether err is a serialization failure or
+// deadlock, after which the transaction should be retried from the start
+func IsRetryableTxError(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	return pqErr.Code == sqlStateSerializationFailure || pqErr.Code == sqlStateDeadlockDetected
+}
+
 // PostgresStore implements PricingStore using PostgreSQL
 type PostgresStore struct {
 	db *sql.DB
@@ -88,13 +107,13 @@ func (s *PostgresStore) Close() error {
 func (s *PostgresStore) CreateSnapshot(ctx context.Context, snapshot *PricingSnapshot) error {
 	query := `
 		INSERT INTO pricing_snapshots 
-		(id, cloud, region, provider_alias, source, fetched_at, valid_from, valid_to, hash, version, is_active)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		(id, cloud, region, provider_alias, source, fetched_at, valid_from, valid_to, hash, version, is_active, metadata)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 	`
 	_, err := s.db.ExecContext(ctx, query,
 		snapshot.ID, snapshot.Cloud, snapshot.Region, snapshot.ProviderAlias,
 		snapshot.Source, snapshot.FetchedAt, snapshot.ValidFrom, snapshot.ValidTo,
-		snapshot.Hash, snapshot.Version, snapshot.IsActive,
+		snapshot.Hash, snapshot.Version, snapshot.IsActive, jsonStringMap(snapshot.Metadata),
 	)
 	return err
 }
@@ -102,14 +121,15 @@ func (s *PostgresStore) CreateSnapshot(ctx context.Context, snapshot *PricingSna
 // GetSnapshot retrieves a snapshot by ID
 func (s *PostgresStore) GetSnapshot(ctx context.Context, id uuid.UUID) (*PricingSnapshot, error) {
 	query := `
-		SELECT id, cloud, region, provider_alias, source, fetched_at, valid_from, valid_to, hash, version, is_active, created_at
+		SELECT id, cloud, region, provider_alias, source, fetched_at, valid_from, valid_to, hash, version, is_active, state, created_at, metadata
 		FROM pricing_snapshots WHERE id = $1
 	`
 	snapshot := &PricingSnapshot{}
 	err := s.db.QueryRowContext(ctx, query, id).Scan(
 		&snapshot.ID, &snapshot.Cloud, &snapshot.Region, &snapshot.ProviderAlias,
 		&snapshot.Source, &snapshot.FetchedAt, &snapshot.ValidFrom, &snapshot.ValidTo,
-		&snapshot.Hash, &snapshot.Version, &snapshot.IsActive, &snapshot.CreatedAt,
+		&snapshot.Hash, &snapshot.Version, &snapshot.IsActive, &snapshot.State, &snapshot.CreatedAt,
+		(*jsonStringMap)(&snapshot.Metadata),
 	)
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -120,7 +140,7 @@ func (s *PostgresStore) GetSnapshot(ctx context.Context, id uuid.UUID) (*Pricing
 // GetActiveSnapshot retrieves the active snapshot for a cloud/region/alias
 func (s *PostgresStore) GetActiveSnapshot(ctx context.Context, cloud CloudProvider, region, alias string) (*PricingSnapshot, error) {
 	query := `
-		SELECT id, cloud, region, provider_alias, source, fetched_at, valid_from, valid_to, hash, version, is_active, created_at
+		SELECT id, cloud, region, provider_alias, source, fetched_at, valid_from, valid_to, hash, version, is_active, state, created_at, metadata
 		FROM pricing_snapshots 
 		WHERE cloud = $1 AND region = $2 AND provider_alias = $3 AND is_active = TRUE
 	`
@@ -128,7 +148,8 @@ func (s *PostgresStore) GetActiveSnapshot(ctx context.Context, cloud CloudProvid
 	err := s.db.QueryRowContext(ctx, query, cloud, region, alias).Scan(
 		&snapshot.ID, &snapshot.Cloud, &snapshot.Region, &snapshot.ProviderAlias,
 		&snapshot.Source, &snapshot.FetchedAt, &snapshot.ValidFrom, &snapshot.ValidTo,
-		&snapshot.Hash, &snapshot.Version, &snapshot.IsActive, &snapshot.CreatedAt,
+		&snapshot.Hash, &snapshot.Version, &snapshot.IsActive, &snapshot.State, &snapshot.CreatedAt,
+		(*jsonStringMap)(&snapshot.Metadata),
 	)
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -136,18 +157,19 @@ func (s *PostgresStore) GetActiveSnapshot(ctx context.Context, cloud CloudProvid
 	return snapshot, err
 }
 
-// ActivateSnapshot activates a snapshot (deactivates others)
+// ActivateSnapshot activates a snapshot (deactivates others and sets their valid_to)
 func (s *PostgresStore) ActivateSnapshot(ctx context.Context, id uuid.UUID) error {
 	_, err := s.db.ExecContext(ctx, "SELECT activate_snapshot($1)", id)
 	return err
 }
 
-// ListSnapshots lists snapshots for a cloud/region
+// ListSnapshots lists snapshots for a cloud/region.
+// An empty cloud or region matches all values.
 func (s *PostgresStore) ListSnapshots(ctx context.Context, cloud CloudProvider, region string) ([]*PricingSnapshot, error) {
 	query := `
-		SELECT id, cloud, region, provider_alias, source, fetched_at, valid_from, valid_to, hash, version, is_active, created_at
+		SELECT id, cloud, region, provider_alias, source, fetched_at, valid_from, valid_to, hash, version, is_active, state, created_at, metadata
 		FROM pricing_snapshots 
-		WHERE cloud = $1 AND region = $2
+		WHERE ($1 = '' OR cloud = $1) AND ($2 = '' OR region = $2)
 		ORDER BY created_at DESC
 	`
 	rows, err := s.db.QueryContext(ctx, query, cloud, region)
@@ -162,14 +184,15 @@ func (s *PostgresStore) ListSnapshots(ctx context.Context, cloud CloudProvider,
 		err := rows.Scan(
 			&s.ID, &s.Cloud, &s.Region, &s.ProviderAlias,
 			&s.Source, &s.FetchedAt, &s.ValidFrom, &s.ValidTo,
-			&s.Hash, &s.Version, &s.IsActive, &s.CreatedAt,
+			&s.Hash, &s.Version, &s.IsActive, &s.State, &s.CreatedAt,
+			(*jsonStringMap)(&s.Metadata),
 		)
 		if err != nil {
 			return nil, err
 		}
 		snapshots = append(snapshots, s)
 	}
-	return snapshots, nil
+	return snapshots, rows.Err()
 }
 
 // UpsertRateKey inserts or returns existing rate key
@@ -219,17 +242,63 @@ func (s *PostgresStore) GetRateKey(ctx context.Context, cloud CloudProvider, ser
 	return key, nil
 }
 
+// ListRateKeys returns the rate keys of cloud and region matching filter
+func (s *PostgresStore) ListRateKeys(ctx context.Context, cloud CloudProvider, region string, filter RateKeyFilter) ([]RateKey, error) {
+	attrs := filter.Attributes
+	if attrs == nil {
+		attrs = map[string]string{}
+	}
+	attrsJSON, err := json.Marshal(attrs)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT id, cloud, service, product_family, region, attributes, created_at
+		FROM pricing_rate_keys
+		WHERE cloud = $1
+		  AND ($2 = '' OR region = $2)
+		  AND ($3 = '' OR service = $3)
+		  AND ($4 = '' OR product_family = $4)
+		  AND attributes @> $5
+		ORDER BY service COLLATE "C", product_family COLLATE "C", ` + attributesSortSQL + `, region COLLATE "C"`
+	args := []any{cloud, region, filter.Service, filter.ProductFamily, attrsJSON}
+	if filter.Limit > 0 {
+		query += " LIMIT $6"
+		args = append(args, filter.Limit)
+	}
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []RateKey
+	for rows.Next() {
+		var key RateKey
+		var attrsBytes []byte
+		if err := rows.Scan(&key.ID, &key.Cloud, &key.Service, &key.ProductFamily, &key.Region, &attrsBytes, &key.CreatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(attrsBytes, &key.Attributes); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
 // CreateRate inserts a pricing rate
 func (s *PostgresStore) CreateRate(ctx context.Context, rate *PricingRate) error {
 	query := `
 		INSERT INTO pricing_rates 
-		(id, snapshot_id, rate_key_id, unit, price, currency, confidence, tier_min, tier_max, effective_date)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		(id, snapshot_id, rate_key_id, unit, price, currency, confidence, tier_min, tier_max, effective_date, labels)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 	`
 	_, err := s.db.ExecContext(ctx, query,
 		rate.ID, rate.SnapshotID, rate.RateKeyID, rate.Unit,
 		rate.Price, rate.Currency, rate.Confidence,
-		rate.TierMin, rate.TierMax, rate.EffectiveDate,
+		rate.TierMin, rate.TierMax, rate.EffectiveDate, jsonStringMap(rate.Labels),
 	)
 	return err
 }
@@ -244,8 +313,8 @@ func (s *PostgresStore) BulkCreateRates(ctx context.Context, rates []*PricingRat
 
 	stmt, err := tx.PrepareContext(ctx, `
 		INSERT INTO pricing_rates 
-		(id, snapshot_id, rate_key_id, unit, price, currency, confidence, tier_min, tier_max, effective_date)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		(id, snapshot_id, rate_key_id, unit, price, currency, confidence, tier_min, tier_max, effective_date, labels)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 	`)
 	if err != nil {
 		return err
@@ -256,7 +325,7 @@ func (s *PostgresStore) BulkCreateRates(ctx context.Context, rates []*PricingRat
 		_, err := stmt.ExecContext(ctx,
 			rate.ID, rate.SnapshotID, rate.RateKeyID, rate.Unit,
 			rate.Price, rate.Currency, rate.Confidence,
-			rate.TierMin, rate.TierMax, rate.EffectiveDate,
+			rate.TierMin, rate.TierMax, rate.EffectiveDate, jsonStringMap(rate.Labels),
 		)
 		if err != nil {
 			return err
@@ -266,14 +335,14 @@ func (s *PostgresStore) BulkCreateRates(ctx context.Context, rates []*PricingRat
 	return tx.Commit()
 }
 
-// ResolveRate looks up a rate from the active snapshot
-func (s *PostgresStore) ResolveRate(ctx context.Context, cloud CloudProvider, service, productFamily, region string, attrs map[string]string, unit, alias string) (*ResolvedRate, error) {
-	attrsJSON, err := json.Marshal(attrs)
-	if err != nil {
-		return nil, err
-	}
+// attributesSortSQL is AttributesSortKey of the attributes column. The "C"
+// collation compares bytes as Go does, whatever the database locale.
+const attributesSortSQL = `COALESCE((SELECT string_agg(a.key || '=' || a.value, ',' ORDER BY a.key COLLATE "C")
+		FROM jsonb_each_text(attributes) a), '') COLLATE "C"`
 
-	query := `
+// resolveRateQuery picks the newest effective rate for one rate key match.
+// Migration 013 indexes its rate key filter and rate join.
+const resolveRateQuery = `
 		SELECT pr.price, pr.currency, pr.confidence, pr.tier_min, pr.tier_max, ps.id, ps.source
 		FROM pricing_snapshots ps
 		JOIN pricing_rate_keys rk ON rk.cloud = ps.cloud AND rk.region = ps.region
@@ -286,12 +355,28 @@ func (s *PostgresStore) ResolveRate(ctx context.Context, cloud CloudProvider, se
 		  AND rk.product_family = $5
 		  AND rk.attributes @> $6
 		  AND pr.unit = $7
-		ORDER BY pr.tier_min NULLS FIRST
+		  AND (pr.effective_date IS NULL OR pr.effective_date <= $8)
+		  AND ($9 = '' OR pr.currency = $9)
+		ORDER BY pr.effective_date DESC NULLS LAST, pr.tier_min NULLS FIRST, pr.confidence DESC
 		LIMIT 1
-	`
-	
+`
+
+// ResolveRate looks up a rate from the active snapshot.
+// Dated rates effective on or before opts.AsOf win over undated ones; among
+// rates of the same date and tier the highest confidence wins.
+func (s *PostgresStore) ResolveRate(ctx context.Context, cloud CloudProvider, service, productFamily, region string, attrs map[string]string, unit, alias string, opts ResolveOptions) (*ResolvedRate, error) {
+	attrsJSON, err := json.Marshal(attrs)
+	if err != nil {
+		return nil, err
+	}
+
+	asOf := opts.AsOf
+	if asOf.IsZero() {
+		asOf = time.Now()
+	}
+
 	rate := &ResolvedRate{}
-	err = s.db.QueryRowContext(ctx, query, cloud, region, alias, service, productFamily, attrsJSON, unit).Scan(
+	err = s.db.QueryRowContext(ctx, resolveRateQuery, cloud, region, alias, service, productFamily, attrsJSON, unit, asOf, opts.Currency).Scan(
 		&rate.Price, &rate.Currency, &rate.Confidence, &rate.TierMin, &rate.TierMax, &rate.SnapshotID, &rate.Source,
 	)
 	if err == sql.ErrNoRows {
@@ -300,30 +385,176 @@ func (s *PostgresStore) ResolveRate(ctx context.Context, cloud CloudProvider, se
 	return rate, err
 }
 
-// ResolveTieredRates returns all tiers for a rate
-func (s *PostgresStore) ResolveTieredRates(ctx context.Context, cloud CloudProvider, service, productFamily, region string, attrs map[string]string, unit, alias string) ([]TieredRate, error) {
+// ResolveAllMatching returns the rate ResolveRate would pick for every rate
+// key matching attrs, ordered by AttributesSortKey. More than one result
+// means the attributes are too partial to identify a single rate.
+func (s *PostgresStore) ResolveAllMatching(ctx context.Context, cloud CloudProvider, service, productFamily, region string, attrs map[string]string, unit, alias string, opts ResolveOptions) ([]ResolvedRate, error) {
 	attrsJSON, err := json.Marshal(attrs)
 	if err != nil {
 		return nil, err
 	}
 
+	asOf := opts.AsOf
+	if asOf.IsZero() {
+		asOf = time.Now()
+	}
+
 	query := `
-		SELECT pr.price, pr.confidence, pr.tier_min, pr.tier_max
-		FROM pricing_snapshots ps
+		SELECT price, currency, confidence, tier_min, tier_max, snapshot_id, source, attributes
+		FROM (
+			SELECT DISTINCT ON (rk.id)
+				pr.price, pr.currency, pr.confidence, pr.tier_min, pr.tier_max,
+				ps.id AS snapshot_id, ps.source, rk.attributes
+			FROM pricing_snapshots ps
+			JOIN pricing_rate_keys rk ON rk.cloud = ps.cloud AND rk.region = ps.region
+			JOIN pricing_rates pr ON pr.snapshot_id = ps.id AND pr.rate_key_id = rk.id
+			WHERE ps.cloud = $1
+			  AND ps.region = $2
+			  AND ps.provider_alias = $3
+			  AND ps.is_active = TRUE
+			  AND rk.service = $4
+			  AND rk.product_family = $5
+			  AND rk.attributes @> $6
+			  AND pr.unit = $7
+			  AND (pr.effective_date IS NULL OR pr.effective_date <= $8)
+			  AND ($9 = '' OR pr.currency = $9)
+			ORDER BY rk.id, pr.effective_date DESC NULLS LAST, pr.tier_min NULLS FIRST, pr.confidence DESC
+		) matches
+		ORDER BY ` + attributesSortSQL
+
+	rows, err := s.db.QueryContext(ctx, query, cloud, region, alias, service, productFamily, attrsJSON, unit, asOf, opts.Currency)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rates []ResolvedRate
+	for rows.Next() {
+		var rate ResolvedRate
+		var keyAttrs []byte
+		if err := rows.Scan(&rate.Price, &rate.Currency, &rate.Confidence, &rate.TierMin, &rate.TierMax, &rate.SnapshotID, &rate.Source, &keyAttrs); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(keyAttrs, &rate.Attributes); err != nil {
+			return nil, err
+		}
+		rates = append(rates, rate)
+	}
+	return rates, rows.Err()
+}
+
+// batchQuery is the JSON form of a RateKeyQuery passed to ResolveRatesBatch
+type batchQuery struct {
+	Service       string            `json:"service"`
+	ProductFamily string            `json:"product_family"`
+	Attributes    map[string]string `json:"attributes"`
+	Unit          string            `json:"unit"`
+}
+
+// ResolveRatesBatch sends all queries as one JSON array and joins it against
+// the snapshot's rate keys, keeping the best rate per query index.
+func (s *PostgresStore) ResolveRatesBatch(ctx context.Context, snapshotID uuid.UUID, reqs []RateKeyQuery, opts ResolveOptions) (map[int]*ResolvedRate, error) {
+	results := make(map[int]*ResolvedRate)
+	if len(reqs) == 0 {
+		return results, nil
+	}
+
+	queries := make([]batchQuery, len(reqs))
+	for i, req := range reqs {
+		attrs := req.Attributes
+		if attrs == nil {
+			attrs = map[string]string{}
+		}
+		queries[i] = batchQuery{Service: req.Service, ProductFamily: req.ProductFamily, Attributes: attrs, Unit: req.Unit}
+	}
+	queriesJSON, err := json.Marshal(queries)
+	if err != nil {
+		return nil, err
+	}
+
+	asOf := opts.AsOf
+	if asOf.IsZero() {
+		asOf = time.Now()
+	}
+
+	query := `
+		WITH queries AS (
+			SELECT (q.ordinality - 1)::int AS idx,
+			       q.value->>'service' AS service,
+			       q.value->>'product_family' AS product_family,
+			       q.value->'attributes' AS attributes,
+			       q.value->>'unit' AS unit
+			FROM jsonb_array_elements($2::jsonb) WITH ORDINALITY AS q(value, ordinality)
+		)
+		SELECT DISTINCT ON (q.idx)
+			q.idx, pr.price, pr.currency, pr.confidence, pr.tier_min, pr.tier_max, ps.id, ps.source
+		FROM queries q
+		JOIN pricing_snapshots ps ON ps.id = $1
 		JOIN pricing_rate_keys rk ON rk.cloud = ps.cloud AND rk.region = ps.region
-		JOIN pricing_rates pr ON pr.snapshot_id = ps.id AND pr.rate_key_id = rk.id
-		WHERE ps.cloud = $1
-		  AND ps.region = $2
-		  AND ps.provider_alias = $3
-		  AND ps.is_active = TRUE
-		  AND rk.service = $4
-		  AND rk.product_family = $5
-		  AND rk.attributes @> $6
-		  AND pr.unit = $7
-		ORDER BY pr.tier_min NULLS FIRST
+		  AND rk.service = q.service
+		  AND rk.product_family = q.product_family
+		  AND rk.attributes @> q.attributes
+		JOIN pricing_rates pr ON pr.snapshot_id = ps.id AND pr.rate_key_id = rk.id AND pr.unit = q.unit
+		WHERE (pr.effective_date IS NULL OR pr.effective_date <= $3)
+		  AND ($4 = '' OR pr.currency = $4)
+		ORDER BY q.idx, pr.effective_date DESC NULLS LAST, pr.tier_min NULLS FIRST, pr.confidence DESC
+	`
+
+	rows, err := s.db.QueryContext(ctx, query, snapshotID, queriesJSON, asOf, opts.Currency)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var idx int
+		rate := &ResolvedRate{}
+		if err := rows.Scan(&idx, &rate.Price, &rate.Currency, &rate.Confidence, &rate.TierMin, &rate.TierMax, &rate.SnapshotID, &rate.Source); err != nil {
+			return nil, err
+		}
+		results[idx] = rate
+	}
+	return results, rows.Err()
+}
+
+// ResolveTieredRates returns all tiers for a rate, lowest tier first. Like
+// ResolveRate it only considers rates effective on or before opts.AsOf, and
+// returns the tiers of each rate key's latest effective period.
+func (s *PostgresStore) ResolveTieredRates(ctx context.Context, cloud CloudProvider, service, productFamily, region string, attrs map[string]string, unit, alias string, opts ResolveOptions) ([]TieredRate, error) {
+	attrsJSON, err := json.Marshal(attrs)
+	if err != nil {
+		return nil, err
+	}
+
+	asOf := opts.AsOf
+	if asOf.IsZero() {
+		asOf = time.Now()
+	}
+
+	query := `
+		SELECT price, currency, confidence, tier_min, tier_max
+		FROM (
+			SELECT pr.price, pr.currency, pr.confidence, pr.tier_min, pr.tier_max,
+				rank() OVER (PARTITION BY rk.id ORDER BY pr.effective_date DESC NULLS LAST) AS period
+			FROM pricing_snapshots ps
+			JOIN pricing_rate_keys rk ON rk.cloud = ps.cloud AND rk.region = ps.region
+			JOIN pricing_rates pr ON pr.snapshot_id = ps.id AND pr.rate_key_id = rk.id
+			WHERE ps.cloud = $1
+			  AND ps.region = $2
+			  AND ps.provider_alias = $3
+			  AND ps.is_active = TRUE
+			  AND rk.service = $4
+			  AND rk.product_family = $5
+			  AND rk.attributes @> $6
+			  AND pr.unit = $7
+			  AND (pr.effective_date IS NULL OR pr.effective_date <= $8)
+			  AND ($9 = '' OR pr.currency = $9)
+		) tiers
+		WHERE period = 1
+		ORDER BY tier_min NULLS FIRST
 	`
-	
-	rows, err := s.db.QueryContext(ctx, query, cloud, region, alias, service, productFamily, attrsJSON, unit)
+
+	rows, err := s.db.QueryContext(ctx, query, cloud, region, alias, service, productFamily, attrsJSON, unit, asOf, opts.Currency)
 	if err != nil {
 		return nil, err
 	}
@@ -333,7 +564,7 @@ func (s *PostgresStore) ResolveTieredRates(ctx context.Context, cloud CloudProvi
 	for rows.Next() {
 		var t TieredRate
 		var tierMin, tierMax *decimal.Decimal
-		err := rows.Scan(&t.Price, &t.Confidence, &tierMin, &tierMax)
+		err := rows.Scan(&t.Price, &t.Currency, &t.Confidence, &tierMin, &tierMax)
 		if err != nil {
 			return nil, err
 		}
@@ -364,13 +595,13 @@ func (s *PostgresStore) BeginTx(ctx context.Context) (Tx, error) {
 func (t *PostgresTx) CreateSnapshot(ctx context.Context, snapshot *PricingSnapshot) error {
 	query := `
 		INSERT INTO pricing_snapshots 
-		(id, cloud, region, provider_alias, source, fetched_at, valid_from, valid_to, hash, version, is_active)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		(id, cloud, region, provider_alias, source, fetched_at, valid_from, valid_to, hash, version, is_active, metadata)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 	`
 	_, err := t.tx.ExecContext(ctx, query,
 		snapshot.ID, snapshot.Cloud, snapshot.Region, snapshot.ProviderAlias,
 		snapshot.Source, snapshot.FetchedAt, snapshot.ValidFrom, snapshot.ValidTo,
-		snapshot.Hash, snapshot.Version, snapshot.IsActive,
+		snapshot.Hash, snapshot.Version, snapshot.IsActive, jsonStringMap(snapshot.Metadata),
 	)
 	return err
 }
@@ -399,13 +630,13 @@ func (t *PostgresTx) UpsertRateKey(ctx context.Context, key *RateKey) (*RateKey,
 func (t *PostgresTx) CreateRate(ctx context.Context, rate *PricingRate) error {
 	query := `
 		INSERT INTO pricing_rates 
-		(id, snapshot_id, rate_key_id, unit, price, currency, confidence, tier_min, tier_max, effective_date)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		(id, snapshot_id, rate_key_id, unit, price, currency, confidence, tier_min, tier_max, effective_date, labels)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 	`
 	_, err := t.tx.ExecContext(ctx, query,
 		rate.ID, rate.SnapshotID, rate.RateKeyID, rate.Unit,
 		rate.Price, rate.Currency, rate.Confidence,
-		rate.TierMin, rate.TierMax, rate.EffectiveDate,
+		rate.TierMin, rate.TierMax, rate.EffectiveDate, jsonStringMap(rate.Labels),
 	)
 	return err
 }
@@ -413,7 +644,7 @@ func (t *PostgresTx) CreateRate(ctx context.Context, rate *PricingRate) error {
 // ActivateSnapshot activates a snapshot within a transaction
 func (t *PostgresTx) ActivateSnapshot(ctx context.Context, id uuid.UUID) error {
 	// Use the stored procedure which ensures state is updated to 'ready'
-	// and handles archiving of previous snapshots correctly.
+	// and handles archiving (and closing valid_to) of previous snapshots.
 	_, err := t.tx.ExecContext(ctx, "SELECT activate_snapshot($1)", id)
 	return err
 }
@@ -431,7 +662,7 @@ func (t *PostgresTx) Rollback() error {
 // FindSnapshotByHash finds a snapshot with matching content hash
 func (s *PostgresStore) FindSnapshotByHash(ctx context.Context, cloud CloudProvider, region, alias, hash string) (*PricingSnapshot, error) {
 	query := `
-		SELECT id, cloud, region, provider_alias, source, fetched_at, valid_from, valid_to, hash, version, is_active, created_at
+		SELECT id, cloud, region, provider_alias, source, fetched_at, valid_from, valid_to, hash, version, is_active, state, created_at, metadata
 		FROM pricing_snapshots 
 		WHERE cloud = $1 AND region = $2 AND provider_alias = $3 AND hash = $4
 		ORDER BY created_at DESC
@@ -441,7 +672,33 @@ func (s *PostgresStore) FindSnapshotByHash(ctx context.Context, cloud CloudProvi
 	err := s.db.QueryRowContext(ctx, query, cloud, region, alias, hash).Scan(
 		&snapshot.ID, &snapshot.Cloud, &snapshot.Region, &snapshot.ProviderAlias,
 		&snapshot.Source, &snapshot.FetchedAt, &snapshot.ValidFrom, &snapshot.ValidTo,
-		&snapshot.Hash, &snapshot.Version, &snapshot.IsActive, &snapshot.CreatedAt,
+		&snapshot.Hash, &snapshot.Version, &snapshot.IsActive, &snapshot.State, &snapshot.CreatedAt,
+		(*jsonStringMap)(&snapshot.Metadata),
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return snapshot, err
+}
+
+// GetSnapshotAsOf returns the snapshot whose [valid_from, valid_to) window
+// contains at, or nil. Snapshots that were never activated are ignored.
+func (s *PostgresStore) GetSnapshotAsOf(ctx context.Context, cloud CloudProvider, region, alias string, at time.Time) (*PricingSnapshot, error) {
+	query := `
+		SELECT id, cloud, region, provider_alias, source, fetched_at, valid_from, valid_to, hash, version, is_active, state, created_at, metadata
+		FROM pricing_snapshots 
+		WHERE cloud = $1 AND region = $2 AND provider_alias = $3
+		AND state IN ('ready', 'archived')
+		AND valid_from <= $4 AND (valid_to IS NULL OR valid_to > $4)
+		ORDER BY valid_from DESC
+		LIMIT 1
+	`
+	snapshot := &PricingSnapshot{}
+	err := s.db.QueryRowContext(ctx, query, cloud, region, alias, at).Scan(
+		&snapshot.ID, &snapshot.Cloud, &snapshot.Region, &snapshot.ProviderAlias,
+		&snapshot.Source, &snapshot.FetchedAt, &snapshot.ValidFrom, &snapshot.ValidTo,
+		&snapshot.Hash, &snapshot.Version, &snapshot.IsActive, &snapshot.State, &snapshot.CreatedAt,
+		(*jsonStringMap)(&snapshot.Metadata),
 	)
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -458,3 +715,216 @@ func (s *PostgresStore) CountRates(ctx context.Context, snapshotID uuid.UUID) (i
 	).Scan(&count)
 	return count, err
 }
+
+// LockIngestion takes a session advisory lock on a dedicated connection,
+// released (and the connection returned to the pool) by unlock
+func (s *PostgresStore) LockIngestion(ctx context.Context, cloud CloudProvider, region, alias string, wait bool) (func(), error) {
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	key := IngestionLockKey(cloud, region, alias)
+
+	if wait {
+		if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", key); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to take ingestion lock: %w", err)
+		}
+	} else {
+		var locked bool
+		if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", key).Scan(&locked); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to take ingestion lock: %w", err)
+		}
+		if !locked {
+			conn.Close()
+			return nil, ErrIngestionLocked
+		}
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", key)
+			conn.Close()
+		})
+	}, nil
+}
+
+// CountDistinctRateKeys returns the number of distinct rate keys in a
+// snapshot; tiers and effective-dated rows of one key count once
+func (s *PostgresStore) CountDistinctRateKeys(ctx context.Context, snapshotID uuid.UUID) (int, error) {
+	var count int
+	err := s.db.QueryRowContext(ctx,
+		"SELECT COUNT(DISTINCT rate_key_id) FROM pricing_rates WHERE snapshot_id = $1",
+		snapshotID,
+	).Scan(&count)
+	return count, err
+}
+
+// StorageStats reports the size of the pricing tables for capacity monitoring
+type StorageStats struct {
+	Snapshots       int64 `json:"snapshots"`
+	ActiveSnapshots int64 `json:"active_snapshots"`
+	RateKeys        int64 `json:"rate_keys"`
+	Rates           int64 `json:"rates"`
+
+	// On-disk sizes in bytes, including indexes and TOAST (pg_total_relation_size)
+	SnapshotsBytes int64 `json:"snapshots_bytes"`
+	RateKeysBytes  int64 `json:"rate_keys_bytes"`
+	RatesBytes     int64 `json:"rates_bytes"`
+}
+
+// TotalBytes is the on-disk size of all pricing tables
+func (s *StorageStats) TotalBytes() int64 {
+	return s.SnapshotsBytes + s.RateKeysBytes + s.RatesBytes
+}
+
+// StorageStats returns row counts and on-disk sizes of the pricing tables
+func (s *PostgresStore) StorageStats(ctx context.Context) (*StorageStats, error) {
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM pricing_snapshots),
+			(SELECT COUNT(*) FROM pricing_snapshots WHERE is_active = TRUE),
+			(SELECT COUNT(*) FROM pricing_rate_keys),
+			(SELECT COUNT(*) FROM pricing_rates),
+			pg_total_relation_size('pricing_snapshots'),
+			pg_total_relation_size('pricing_rate_keys'),
+			pg_total_relation_size('pricing_rates')
+	`
+	stats := &StorageStats{}
+	err := s.db.QueryRowContext(ctx, query).Scan(
+		&stats.Snapshots, &stats.ActiveSnapshots, &stats.RateKeys, &stats.Rates,
+		&stats.SnapshotsBytes, &stats.RateKeysBytes, &stats.RatesBytes,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read storage stats: %w", err)
+	}
+	return stats, nil
+}
+
+// DistinctCurrencies returns the currencies used by a snapshot's rates, sorted
+func (s *PostgresStore) DistinctCurrencies(ctx context.Context, snapshotID uuid.UUID) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT DISTINCT currency FROM pricing_rates WHERE snapshot_id = $1 ORDER BY currency",
+		snapshotID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var currencies []string
+	for rows.Next() {
+		var c string
+		if err := rows.Scan(&c); err != nil {
+			return nil, err
+		}
+		currencies = append(currencies, c)
+	}
+	return currencies, rows.Err()
+}
+
+// GetRatesBySnapshot returns every rate in a snapshot along with its rate key
+func (s *PostgresStore) GetRatesBySnapshot(ctx context.Context, snapshotID uuid.UUID) ([]SnapshotRate, error) {
+	query := `
+		SELECT rk.id, rk.cloud, rk.service, rk.product_family, rk.region, rk.attributes, rk.created_at,
+		       pr.id, pr.snapshot_id, pr.rate_key_id, pr.unit, pr.price, pr.currency, pr.confidence,
+		       pr.tier_min, pr.tier_max, pr.effective_date, pr.created_at, pr.labels
+		FROM pricing_rates pr
+		JOIN pricing_rate_keys rk ON rk.id = pr.rate_key_id
+		WHERE pr.snapshot_id = $1
+		ORDER BY rk.service, rk.product_family, pr.unit, pr.tier_min NULLS FIRST
+	`
+	rows, err := s.db.QueryContext(ctx, query, snapshotID)
+	if err != nil {
+		return nil, err
+	}
+	return scanSnapshotRates(rows)
+}
+
+// GetRatesPage returns up to limit rates of a snapshot with IDs after the
+// given rate ID, ordered by ID. Pass uuid.Nil to start; the last ID of a page
+// is the cursor for the next one.
+func (s *PostgresStore) GetRatesPage(ctx context.Context, snapshotID, after uuid.UUID, limit int) ([]SnapshotRate, error) {
+	query := `
+		SELECT rk.id, rk.cloud, rk.service, rk.product_family, rk.region, rk.attributes, rk.created_at,
+		       pr.id, pr.snapshot_id, pr.rate_key_id, pr.unit, pr.price, pr.currency, pr.confidence,
+		       pr.tier_min, pr.tier_max, pr.effective_date, pr.created_at, pr.labels
+		FROM pricing_rates pr
+		JOIN pricing_rate_keys rk ON rk.id = pr.rate_key_id
+		WHERE pr.snapshot_id = $1 AND pr.id > $2
+		ORDER BY pr.id
+		LIMIT $3
+	`
+	rows, err := s.db.QueryContext(ctx, query, snapshotID, after, limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanSnapshotRates(rows)
+}
+
+// scanSnapshotRates reads rate rows joined with their rate keys and closes rows
+func scanSnapshotRates(rows *sql.Rows) ([]SnapshotRate, error) {
+	defer rows.Close()
+
+	var rates []SnapshotRate
+	for rows.Next() {
+		var sr SnapshotRate
+		var attrsBytes []byte
+		err := rows.Scan(
+			&sr.RateKey.ID, &sr.RateKey.Cloud, &sr.RateKey.Service, &sr.RateKey.ProductFamily,
+			&sr.RateKey.Region, &attrsBytes, &sr.RateKey.CreatedAt,
+			&sr.Rate.ID, &sr.Rate.SnapshotID, &sr.Rate.RateKeyID, &sr.Rate.Unit,
+			&sr.Rate.Price, &sr.Rate.Currency, &sr.Rate.Confidence,
+			&sr.Rate.TierMin, &sr.Rate.TierMax, &sr.Rate.EffectiveDate, &sr.Rate.CreatedAt,
+			(*jsonStringMap)(&sr.Rate.Labels),
+		)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(attrsBytes, &sr.RateKey.Attributes); err != nil {
+			return nil, fmt.Errorf("failed to decode attributes for rate key %s: %w", sr.RateKey.ID, err)
+		}
+		rates = append(rates, sr)
+	}
+	return rates, rows.Err()
+}
+
+// jsonStringMap stores a string map (snapshot metadata, rate labels) as a
+// JSONB object
+type jsonStringMap map[string]string
+
+// Value encodes the map, writing {} rather than null when empty
+func (m jsonStringMap) Value() (driver.Value, error) {
+	if m == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(map[string]string(m))
+}
+
+// Scan decodes a JSONB object, leaving the map nil when it is empty
+func (m *jsonStringMap) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("unexpected JSON object type %T", src)
+	}
+
+	var decoded map[string]string
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			return fmt.Errorf("failed to decode JSON object: %w", err)
+		}
+	}
+	if len(decoded) == 0 {
+		decoded = nil
+	}
+	*m = decoded
+	return nil
+}
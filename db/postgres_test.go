@@ -0,0 +1,300 @@
+// Package db - PostgreSQL integration tests (require DB_URL)
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// openTestStore connects to DB_URL or skips the test when it is unset.
+// The database is expected to have all migrations applied.
+func openTestStore(t testing.TB) *PostgresStore {
+	t.Helper()
+	url := os.Getenv("DB_URL")
+	if url == "" {
+		t.Skip("DB_URL not set; skipping PostgreSQL integration test")
+	}
+	store, err := NewPostgresStoreFromURL(url)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+// testRegion returns a region name unique to this test run so tests never
+// collide with real snapshots, and removes everything under it afterwards.
+func testRegion(t testing.TB, store *PostgresStore) string {
+	t.Helper()
+	region := "test-" + uuid.NewString()[:8]
+	t.Cleanup(func() {
+		ctx := context.Background()
+		store.db.ExecContext(ctx, "DELETE FROM pricing_snapshots WHERE region = $1", region)
+		store.db.ExecContext(ctx, "DELETE FROM pricing_rate_keys WHERE region = $1", region)
+	})
+	return region
+}
+
+func TestPostgresResolveRateEffectiveDate(t *testing.T) {
+	store := openTestStore(t)
+	region := testRegion(t, store)
+	ctx := context.Background()
+
+	day := func(y int, m time.Month, d int) *time.Time {
+		tm := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+		return &tm
+	}
+
+	tx, err := store.BeginTx(ctx)
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	defer tx.Rollback()
+
+	snapshot := NewSnapshotBuilder(AWS, region, "test").Build(uuid.NewString())
+	if err := tx.CreateSnapshot(ctx, snapshot); err != nil {
+		t.Fatalf("create snapshot: %v", err)
+	}
+	key, err := tx.UpsertRateKey(ctx, &RateKey{
+		ID:            uuid.New(),
+		Cloud:         AWS,
+		Service:       "AmazonEC2",
+		ProductFamily: "Compute Instance",
+		Region:        region,
+		Attributes:    map[string]string{"instance_type": "t3.micro"},
+	})
+	if err != nil {
+		t.Fatalf("upsert key: %v", err)
+	}
+
+	prices := []struct {
+		price     string
+		effective *time.Time
+	}{
+		{"0.0900", nil},
+		{"0.1000", day(2024, 1, 1)},
+		{"0.1200", day(2024, 7, 1)},
+	}
+	for _, p := range prices {
+		err := tx.CreateRate(ctx, &PricingRate{
+			ID:            uuid.New(),
+			SnapshotID:    snapshot.ID,
+			RateKeyID:     key.ID,
+			Unit:          "hours",
+			Price:         decimal.RequireFromString(p.price),
+			Currency:      "USD",
+			Confidence:    1.0,
+			EffectiveDate: p.effective,
+		})
+		if err != nil {
+			t.Fatalf("create rate: %v", err)
+		}
+	}
+	if err := tx.ActivateSnapshot(ctx, snapshot.ID); err != nil {
+		t.Fatalf("activate: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		asOf time.Time
+		want string
+	}{
+		{"before any dated price falls back to undated", *day(2023, 6, 1), "0.09"},
+		{"between dates picks the older price", *day(2024, 3, 15), "0.1"},
+		{"on the effective date picks the new price", *day(2024, 7, 1), "0.12"},
+		{"after both dates picks the newest price", *day(2025, 1, 1), "0.12"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate, err := store.ResolveRate(ctx, AWS, "AmazonEC2", "Compute Instance", region,
+				map[string]string{"instance_type": "t3.micro"}, "hours", "default",
+				ResolveOptions{AsOf: tt.asOf})
+			if err != nil {
+				t.Fatalf("resolve: %v", err)
+			}
+			if rate == nil {
+				t.Fatal("expected a rate")
+			}
+			if !rate.Price.Equal(decimal.RequireFromString(tt.want)) {
+				t.Errorf("price = %s, want %s", rate.Price, tt.want)
+			}
+		})
+	}
+}
+
+func TestPostgresStorageStats(t *testing.T) {
+	store := openTestStore(t)
+	region := testRegion(t, store)
+	ctx := context.Background()
+
+	before, err := store.StorageStats(ctx)
+	if err != nil {
+		t.Fatalf("stats: %v", err)
+	}
+
+	tx, err := store.BeginTx(ctx)
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	defer tx.Rollback()
+
+	snapshot := NewSnapshotBuilder(AWS, region, "test").Build(uuid.NewString())
+	if err := tx.CreateSnapshot(ctx, snapshot); err != nil {
+		t.Fatalf("create snapshot: %v", err)
+	}
+	for _, instanceType := range []string{"t3.micro", "t3.large"} {
+		key, err := tx.UpsertRateKey(ctx, &RateKey{
+			ID:            uuid.New(),
+			Cloud:         AWS,
+			Service:       "AmazonEC2",
+			ProductFamily: "Compute Instance",
+			Region:        region,
+			Attributes:    map[string]string{"instance_type": instanceType},
+		})
+		if err != nil {
+			t.Fatalf("upsert key: %v", err)
+		}
+		err = tx.CreateRate(ctx, &PricingRate{
+			ID:         uuid.New(),
+			SnapshotID: snapshot.ID,
+			RateKeyID:  key.ID,
+			Unit:       "hours",
+			Price:      decimal.RequireFromString("0.0104"),
+			Currency:   "USD",
+			Confidence: 1.0,
+		})
+		if err != nil {
+			t.Fatalf("create rate: %v", err)
+		}
+	}
+	if err := tx.ActivateSnapshot(ctx, snapshot.ID); err != nil {
+		t.Fatalf("activate: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+
+	after, err := store.StorageStats(ctx)
+	if err != nil {
+		t.Fatalf("stats: %v", err)
+	}
+	if after.Snapshots-before.Snapshots != 1 || after.ActiveSnapshots-before.ActiveSnapshots != 1 {
+		t.Errorf("snapshot counts went from %+v to %+v, want +1 each", before, after)
+	}
+	if after.RateKeys-before.RateKeys != 2 || after.Rates-before.Rates != 2 {
+		t.Errorf("rate counts went from %+v to %+v, want +2 each", before, after)
+	}
+	if after.SnapshotsBytes <= 0 || after.RateKeysBytes <= 0 || after.RatesBytes <= 0 {
+		t.Errorf("expected positive table sizes, got %+v", after)
+	}
+}
+
+// seedLargeCatalog commits an active snapshot in region with n EC2 rate keys
+// ("instance_type": "type<i>") and one hourly rate each, then refreshes the
+// planner statistics so EXPLAIN sees a table of realistic size
+func seedLargeCatalog(t testing.TB, store *PostgresStore, region string, n int) {
+	t.Helper()
+	ctx := context.Background()
+
+	tx, err := store.BeginTx(ctx)
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	defer tx.Rollback()
+
+	snapshot := NewSnapshotBuilder(AWS, region, "test").Build(uuid.NewString())
+	if err := tx.CreateSnapshot(ctx, snapshot); err != nil {
+		t.Fatalf("create snapshot: %v", err)
+	}
+	pg := tx.(*PostgresTx)
+	_, err = pg.tx.ExecContext(ctx, `
+		INSERT INTO pricing_rate_keys (cloud, service, product_family, region, attributes)
+		SELECT 'aws', 'AmazonEC2', 'Compute Instance', $1,
+		       jsonb_build_object('instance_type', 'type' || g, 'os', CASE WHEN g % 2 = 0 THEN 'linux' ELSE 'windows' END)
+		FROM generate_series(1, $2) g`, region, n)
+	if err != nil {
+		t.Fatalf("seed rate keys: %v", err)
+	}
+	_, err = pg.tx.ExecContext(ctx, `
+		INSERT INTO pricing_rates (snapshot_id, rate_key_id, unit, price, currency, confidence)
+		SELECT $1, id, 'hours', 0.0104, 'USD', 1.0 FROM pricing_rate_keys WHERE region = $2`, snapshot.ID, region)
+	if err != nil {
+		t.Fatalf("seed rates: %v", err)
+	}
+	if err := tx.ActivateSnapshot(ctx, snapshot.ID); err != nil {
+		t.Fatalf("activate: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	for _, table := range []string{"pricing_rate_keys", "pricing_rates", "pricing_snapshots"} {
+		if _, err := store.db.ExecContext(ctx, "ANALYZE "+table); err != nil {
+			t.Fatalf("analyze %s: %v", table, err)
+		}
+	}
+}
+
+func TestPostgresResolveRateUsesIndexes(t *testing.T) {
+	store := openTestStore(t)
+	region := testRegion(t, store)
+	ctx := context.Background()
+	seedLargeCatalog(t, store, region, 20000)
+
+	attrs, _ := json.Marshal(map[string]string{"instance_type": "type777"})
+	rows, err := store.db.QueryContext(ctx, "EXPLAIN "+resolveRateQuery,
+		AWS, region, "default", "AmazonEC2", "Compute Instance", attrs, "hours", time.Now(), "")
+	if err != nil {
+		t.Fatalf("explain: %v", err)
+	}
+	defer rows.Close()
+	var plan []string
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			t.Fatalf("scan plan: %v", err)
+		}
+		plan = append(plan, line)
+	}
+	text := strings.Join(plan, "\n")
+
+	if strings.Contains(text, "Seq Scan on pricing_rate_keys") || strings.Contains(text, "Seq Scan on pricing_rates") {
+		t.Errorf("expected index scans for rate keys and rates, got plan:\n%s", text)
+	}
+	if !strings.Contains(text, "idx_rate_keys_") {
+		t.Errorf("expected a pricing_rate_keys index in the plan, got:\n%s", text)
+	}
+
+	rate, err := store.ResolveRate(ctx, AWS, "AmazonEC2", "Compute Instance", region,
+		map[string]string{"instance_type": "type777"}, "hours", "default", ResolveOptions{})
+	if err != nil || rate == nil {
+		t.Fatalf("expected type777 to resolve, got %v, %v", rate, err)
+	}
+}
+
+func BenchmarkPostgresResolveRate(b *testing.B) {
+	store := openTestStore(b)
+	region := testRegion(b, store)
+	ctx := context.Background()
+	const n = 100000
+	seedLargeCatalog(b, store, region, n)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		attrs := map[string]string{"instance_type": fmt.Sprintf("type%d", i%n+1)}
+		rate, err := store.ResolveRate(ctx, AWS, "AmazonEC2", "Compute Instance", region, attrs, "hours", "default", ResolveOptions{})
+		if err != nil || rate == nil {
+			b.Fatalf("resolve %v: %v, %v", attrs, rate, err)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+// Package db - Recording and replaying rate lookups
+// A RecordingResolver captures every lookup of an estimate so it can be
+// reproduced later, or replayed against another store to find which prices
+// changed under it.
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RecordedLookup is one resolution captured by a RecordingResolver. Exactly
+// one of Result, Tiered and Error is set.
+type RecordedLookup struct {
+	Request    ResolutionRequest       `json:"request"`
+	Tiered     bool                    `json:"tiered,omitempty"`
+	Result     *ResolutionResult       `json:"result,omitempty"`
+	TierResult *TieredResolutionResult `json:"tier_result,omitempty"`
+	Error      string                  `json:"error,omitempty"`
+	SnapshotID *uuid.UUID              `json:"snapshot_id,omitempty"`
+	RecordedAt time.Time               `json:"recorded_at"`
+}
+
+// RecordingResolver wraps a StrictResolver and records every Resolve and
+// ResolveTiered call in order. It is safe for concurrent use.
+type RecordingResolver struct {
+	inner   *StrictResolver
+	mu      sync.Mutex
+	lookups []RecordedLookup
+}
+
+// NewRecordingResolver creates a recorder around inner
+func NewRecordingResolver(inner *StrictResolver) *RecordingResolver {
+	return &RecordingResolver{inner: inner}
+}
+
+// Resolve resolves through the wrapped resolver and records the outcome
+func (r *RecordingResolver) Resolve(ctx context.Context, req ResolutionRequest) (*ResolutionResult, error) {
+	result, err := r.inner.Resolve(ctx, req)
+	lookup := RecordedLookup{Request: req, Result: result}
+	if result != nil && !result.IsSymbolic {
+		id := result.SnapshotID
+		lookup.SnapshotID = &id
+	}
+	r.record(lookup, err)
+	return result, err
+}
+
+// ResolveTiered resolves tiered pricing through the wrapped resolver and
+// records the outcome
+func (r *RecordingResolver) ResolveTiered(ctx context.Context, req ResolutionRequest) (*TieredResolutionResult, error) {
+	result, err := r.inner.ResolveTiered(ctx, req)
+	lookup := RecordedLookup{Request: req, Tiered: true, TierResult: result}
+	if result != nil && !result.IsSymbolic {
+		id := result.SnapshotID
+		lookup.SnapshotID = &id
+	}
+	r.record(lookup, err)
+	return result, err
+}
+
+func (r *RecordingResolver) record(lookup RecordedLookup, err error) {
+	if err != nil {
+		lookup.Error = err.Error()
+	}
+	lookup.RecordedAt = time.Now().UTC()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lookups = append(r.lookups, lookup)
+}
+
+// Lookups returns the recorded lookups in call order
+func (r *RecordingResolver) Lookups() []RecordedLookup {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]RecordedLookup(nil), r.lookups...)
+}
+
+// WriteJSON writes the recorded lookups as an indented JSON array
+func (r *RecordingResolver) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r.Lookups())
+}
+
+// ReadRecordedLookups reads lookups written by WriteJSON
+func ReadRecordedLookups(rd io.Reader) ([]RecordedLookup, error) {
+	var lookups []RecordedLookup
+	if err := json.NewDecoder(rd).Decode(&lookups); err != nil {
+		return nil, fmt.Errorf("failed to decode recorded lookups: %w", err)
+	}
+	return lookups, nil
+}
+
+// ReplayDivergence is a recorded lookup whose replay resolved differently
+type ReplayDivergence struct {
+	Index    int               `json:"index"`
+	Request  ResolutionRequest `json:"request"`
+	Recorded string            `json:"recorded"`
+	Replayed string            `json:"replayed"`
+}
+
+// Replay resolves every recorded request again with resolver, which may read
+// a different store, and returns the lookups whose price, currency, tiers,
+// symbolic state or error changed. Snapshot IDs are not compared, since a
+// replay against another store resolves from other snapshots.
+func Replay(ctx context.Context, resolver *StrictResolver, lookups []RecordedLookup) ([]ReplayDivergence, error) {
+	var divergences []ReplayDivergence
+	for i, lookup := range lookups {
+		if err := ctx.Err(); err != nil {
+			return divergences, err
+		}
+
+		var replayed RecordedLookup
+		if lookup.Tiered {
+			result, err := resolver.ResolveTiered(ctx, lookup.Request)
+			replayed = RecordedLookup{Tiered: true, TierResult: result}
+			if err != nil {
+				replayed.Error = err.Error()
+			}
+		} else {
+			result, err := resolver.Resolve(ctx, lookup.Request)
+			replayed = RecordedLookup{Result: result}
+			if err != nil {
+				replayed.Error = err.Error()
+			}
+		}
+
+		recorded, now := lookup.outcome(), replayed.outcome()
+		if recorded != now {
+			divergences = append(divergences, ReplayDivergence{Index: i, Request: lookup.Request, Recorded: recorded, Replayed: now})
+		}
+	}
+	return divergences, nil
+}
+
+// outcome summarises what a lookup resolved to, for comparing a recording
+// with its replay
+func (l RecordedLookup) outcome() string {
+	switch {
+	case l.Error != "":
+		return "error: " + l.Error
+	case l.Tiered && l.TierResult != nil:
+		if l.TierResult.IsSymbolic {
+			return "symbolic: " + l.TierResult.Reason
+		}
+		s := "tiers:"
+		for _, t := range l.TierResult.Tiers {
+			max := "inf"
+			if t.Max != nil {
+				max = t.Max.String()
+			}
+			s += fmt.Sprintf(" [%s-%s] %s %s", t.Min, max, t.Price, t.Currency)
+		}
+		return s
+	case !l.Tiered && l.Result != nil:
+		if l.Result.IsSymbolic || l.Result.Price == nil {
+			return "symbolic: " + l.Result.Reason
+		}
+		return fmt.Sprintf("%s %s", l.Result.Price, l.Result.Currency)
+	}
+	return "no result"
+}
@@ -0,0 +1,113 @@
+// Package db - Recording resolver tests
+package db
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+func TestRecordingResolverReplay(t *testing.T) {
+	ctx := context.Background()
+	recordedStore := &currencyStore{
+		snapshot: &PricingSnapshot{ID: uuid.New(), Source: "test"},
+		prices: map[string]decimal.Decimal{
+			"USD": decimal.RequireFromString("0.096"),
+			"EUR": decimal.RequireFromString("0.089"),
+		},
+	}
+	req := ResolutionRequest{Cloud: Azure, Service: "Virtual Machines", Region: "westeurope", Unit: "hours"}
+	currencies := []string{"USD", "EUR", "GBP"}
+
+	recorder := NewRecordingResolver(NewStrictResolver(recordedStore))
+	for _, currency := range currencies {
+		req.Currency = currency
+		if _, err := recorder.Resolve(ctx, req); err != nil {
+			t.Fatalf("resolve %s: %v", currency, err)
+		}
+	}
+
+	lookups := recorder.Lookups()
+	if len(lookups) != 3 {
+		t.Fatalf("expected 3 recorded lookups, got %d", len(lookups))
+	}
+	if lookups[0].SnapshotID == nil || *lookups[0].SnapshotID != recordedStore.snapshot.ID || lookups[0].Request.Currency != "USD" {
+		t.Errorf("expected the USD lookup with its snapshot first, got %+v", lookups[0])
+	}
+	if !lookups[2].Result.IsSymbolic || lookups[2].SnapshotID != nil {
+		t.Errorf("expected the GBP lookup to be recorded as symbolic, got %+v", lookups[2])
+	}
+
+	var buf bytes.Buffer
+	if err := recorder.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := ReadRecordedLookups(&buf)
+	if err != nil || len(decoded) != 3 {
+		t.Fatalf("round trip: %d lookups, %v", len(decoded), err)
+	}
+
+	// Replaying against the same prices finds nothing
+	divergences, err := Replay(ctx, NewStrictResolver(recordedStore), decoded)
+	if err != nil || len(divergences) != 0 {
+		t.Errorf("expected an identical replay, got %+v, %v", divergences, err)
+	}
+
+	// Another store with a changed EUR price and a new GBP price diverges
+	// on exactly those lookups; the different snapshot alone does not count
+	changedStore := &currencyStore{
+		snapshot: &PricingSnapshot{ID: uuid.New(), Source: "test"},
+		prices: map[string]decimal.Decimal{
+			"USD": decimal.RequireFromString("0.096"),
+			"EUR": decimal.RequireFromString("0.091"),
+			"GBP": decimal.RequireFromString("0.078"),
+		},
+	}
+	divergences, err = Replay(ctx, NewStrictResolver(changedStore), decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(divergences) != 2 || divergences[0].Index != 1 || divergences[1].Index != 2 {
+		t.Fatalf("expected the EUR and GBP lookups to diverge, got %+v", divergences)
+	}
+	if divergences[0].Recorded != "0.089 EUR" || divergences[0].Replayed != "0.091 EUR" {
+		t.Errorf("unexpected EUR divergence %+v", divergences[0])
+	}
+	if !strings.HasPrefix(divergences[1].Recorded, "symbolic") || divergences[1].Replayed != "0.078 GBP" {
+		t.Errorf("unexpected GBP divergence %+v", divergences[1])
+	}
+}
+
+func TestRecordingResolverConcurrentUse(t *testing.T) {
+	store := &currencyStore{
+		snapshot: &PricingSnapshot{ID: uuid.New(), Source: "test"},
+		prices:   map[string]decimal.Decimal{"USD": decimal.RequireFromString("0.096")},
+	}
+	recorder := NewRecordingResolver(NewStrictResolver(store))
+
+	// Run with -race: recording and snapshot tracking must not race
+	var wg sync.WaitGroup
+	for _, region := range []string{"westeurope", "northeurope", "eastus", "westus"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req := ResolutionRequest{Cloud: Azure, Service: "Virtual Machines", Region: region, Unit: "hours", Currency: "USD"}
+			for i := 0; i < 50; i++ {
+				if _, err := recorder.Resolve(context.Background(), req); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := len(recorder.Lookups()); n != 200 {
+		t.Errorf("expected 200 recorded lookups, got %d", n)
+	}
+}
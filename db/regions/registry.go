@@ -2,7 +2,11 @@
 // This is the source of truth for which regions can be billed.
 package regions
 
-import "terraform-cost/db"
+import (
+	"strings"
+
+	"terraform-cost/db"
+)
 
 // CloudRegion represents a billable region for a cloud provider
 type CloudRegion struct {
@@ -10,7 +14,7 @@ type CloudRegion struct {
 	Region        string
 	DisplayName   string
 	Billable      bool
-	PricingSource string // "api", "manual", "govcloud", "china"
+	PricingSource string // "api", "manual", "govcloud", "china", "local-zone", "wavelength"
 }
 
 // Registry holds all billable regions for all providers
@@ -26,6 +30,8 @@ func NewRegistry() *Registry {
 	r.regions[db.AWS] = awsRegions()
 	r.regions[db.Azure] = azureRegions()
 	r.regions[db.GCP] = gcpRegions()
+	r.regions[db.OCI] = ociRegions()
+	r.regions[db.DigitalOcean] = digitalOceanRegions()
 	return r
 }
 
@@ -61,6 +67,21 @@ func (r *Registry) IsBillable(provider db.CloudProvider, region string) bool {
 	return reg != nil && reg.Billable
 }
 
+// ParentRegion returns the region an edge zone (AWS Local Zone or Wavelength
+// Zone) belongs to, or region itself for any other region
+func (r *Registry) ParentRegion(provider db.CloudProvider, region string) string {
+	reg := r.GetRegion(provider, region)
+	if reg == nil || (reg.PricingSource != "local-zone" && reg.PricingSource != "wavelength") {
+		return region
+	}
+	// Zone codes extend the parent's: us-west-2-lax-1, us-east-1-wl1-bos-wlz-1
+	parts := strings.SplitN(region, "-", 4)
+	if len(parts) < 4 {
+		return region
+	}
+	return strings.Join(parts[:3], "-")
+}
+
 // awsRegions returns all AWS regions
 func awsRegions() []CloudRegion {
 	return []CloudRegion{
@@ -114,6 +135,32 @@ func awsRegions() []CloudRegion {
 		// China (separate pricing universe)
 		{db.AWS, "cn-north-1", "China (Beijing)", true, "china"},
 		{db.AWS, "cn-northwest-1", "China (Ningxia)", true, "china"},
+
+		// Local Zones (priced in the parent region's price list)
+		{db.AWS, "us-east-1-atl-1", "US East (Atlanta)", true, "local-zone"},
+		{db.AWS, "us-east-1-bos-1", "US East (Boston)", true, "local-zone"},
+		{db.AWS, "us-east-1-chi-1", "US East (Chicago)", true, "local-zone"},
+		{db.AWS, "us-east-1-dfw-1", "US East (Dallas)", true, "local-zone"},
+		{db.AWS, "us-east-1-iah-1", "US East (Houston)", true, "local-zone"},
+		{db.AWS, "us-east-1-mci-1", "US East (Kansas City 2)", true, "local-zone"},
+		{db.AWS, "us-east-1-mia-1", "US East (Miami)", true, "local-zone"},
+		{db.AWS, "us-east-1-msp-1", "US East (Minneapolis)", true, "local-zone"},
+		{db.AWS, "us-east-1-nyc-1", "US East (New York City)", true, "local-zone"},
+		{db.AWS, "us-east-1-phl-1", "US East (Philadelphia)", true, "local-zone"},
+		{db.AWS, "us-west-2-den-1", "US West (Denver)", true, "local-zone"},
+		{db.AWS, "us-west-2-las-1", "US West (Las Vegas)", true, "local-zone"},
+		{db.AWS, "us-west-2-lax-1", "US West (Los Angeles)", true, "local-zone"},
+		{db.AWS, "us-west-2-pdx-1", "US West (Portland)", true, "local-zone"},
+		{db.AWS, "us-west-2-phx-2", "US West (Phoenix)", true, "local-zone"},
+		{db.AWS, "us-west-2-sea-1", "US West (Seattle)", true, "local-zone"},
+
+		// Wavelength Zones (priced in the parent region's price list)
+		{db.AWS, "us-east-1-wl1-bos-wlz-1", "US East (Verizon) - Boston", true, "wavelength"},
+		{db.AWS, "us-east-1-wl1-nyc-wlz-1", "US East (Verizon) - New York", true, "wavelength"},
+		{db.AWS, "us-west-2-wl1-las-wlz-1", "US West (Verizon) - Las Vegas", true, "wavelength"},
+		{db.AWS, "us-west-2-wl1-sfo-wlz-1", "US West (Verizon) - San Francisco Bay Area", true, "wavelength"},
+		{db.AWS, "eu-west-2-wl1-lon-wlz-1", "Europe (Vodafone) - London", true, "wavelength"},
+		{db.AWS, "ap-northeast-1-wl1-nrt-wlz-1", "Asia Pacific (KDDI) - Tokyo", true, "wavelength"},
 	}
 }
 
@@ -251,3 +298,81 @@ func gcpRegions() []CloudRegion {
 		{db.GCP, "africa-south1", "Johannesburg", true, "api"},
 	}
 }
+
+// ociRegions returns all OCI regions
+func ociRegions() []CloudRegion {
+	return []CloudRegion{
+		// US
+		{db.OCI, "us-ashburn-1", "US East (Ashburn)", true, "api"},
+		{db.OCI, "us-phoenix-1", "US West (Phoenix)", true, "api"},
+		{db.OCI, "us-sanjose-1", "US West (San Jose)", true, "api"},
+		{db.OCI, "us-chicago-1", "US Midwest (Chicago)", true, "api"},
+
+		// Canada
+		{db.OCI, "ca-toronto-1", "Canada Southeast (Toronto)", true, "api"},
+		{db.OCI, "ca-montreal-1", "Canada Southeast (Montreal)", true, "api"},
+
+		// Latin America
+		{db.OCI, "sa-saopaulo-1", "Brazil East (Sao Paulo)", true, "api"},
+		{db.OCI, "sa-vinhedo-1", "Brazil Southeast (Vinhedo)", true, "api"},
+		{db.OCI, "sa-santiago-1", "Chile Central (Santiago)", true, "api"},
+		{db.OCI, "sa-valparaiso-1", "Chile West (Valparaiso)", true, "api"},
+		{db.OCI, "sa-bogota-1", "Colombia Central (Bogota)", true, "api"},
+		{db.OCI, "mx-queretaro-1", "Mexico Central (Queretaro)", true, "api"},
+		{db.OCI, "mx-monterrey-1", "Mexico Northeast (Monterrey)", true, "api"},
+
+		// Europe
+		{db.OCI, "uk-london-1", "UK South (London)", true, "api"},
+		{db.OCI, "uk-cardiff-1", "UK West (Newport)", true, "api"},
+		{db.OCI, "eu-frankfurt-1", "Germany Central (Frankfurt)", true, "api"},
+		{db.OCI, "eu-amsterdam-1", "Netherlands Northwest (Amsterdam)", true, "api"},
+		{db.OCI, "eu-zurich-1", "Switzerland North (Zurich)", true, "api"},
+		{db.OCI, "eu-madrid-1", "Spain Central (Madrid)", true, "api"},
+		{db.OCI, "eu-marseille-1", "France South (Marseille)", true, "api"},
+		{db.OCI, "eu-paris-1", "France Central (Paris)", true, "api"},
+		{db.OCI, "eu-milan-1", "Italy Northwest (Milan)", true, "api"},
+		{db.OCI, "eu-stockholm-1", "Sweden Central (Stockholm)", true, "api"},
+
+		// Asia Pacific
+		{db.OCI, "ap-tokyo-1", "Japan East (Tokyo)", true, "api"},
+		{db.OCI, "ap-osaka-1", "Japan Central (Osaka)", true, "api"},
+		{db.OCI, "ap-seoul-1", "South Korea Central (Seoul)", true, "api"},
+		{db.OCI, "ap-chuncheon-1", "South Korea North (Chuncheon)", true, "api"},
+		{db.OCI, "ap-mumbai-1", "India West (Mumbai)", true, "api"},
+		{db.OCI, "ap-hyderabad-1", "India South (Hyderabad)", true, "api"},
+		{db.OCI, "ap-singapore-1", "Singapore (Singapore)", true, "api"},
+		{db.OCI, "ap-singapore-2", "Singapore West (Singapore)", true, "api"},
+		{db.OCI, "ap-sydney-1", "Australia East (Sydney)", true, "api"},
+		{db.OCI, "ap-melbourne-1", "Australia Southeast (Melbourne)", true, "api"},
+
+		// Middle East & Africa
+		{db.OCI, "me-jeddah-1", "Saudi Arabia West (Jeddah)", true, "api"},
+		{db.OCI, "me-riyadh-1", "Saudi Arabia Central (Riyadh)", true, "api"},
+		{db.OCI, "me-dubai-1", "UAE East (Dubai)", true, "api"},
+		{db.OCI, "me-abudhabi-1", "UAE Central (Abu Dhabi)", true, "api"},
+		{db.OCI, "il-jerusalem-1", "Israel Central (Jerusalem)", true, "api"},
+		{db.OCI, "af-johannesburg-1", "South Africa Central (Johannesburg)", true, "api"},
+	}
+}
+
+// digitalOceanRegions returns all DigitalOcean regions
+func digitalOceanRegions() []CloudRegion {
+	return []CloudRegion{
+		// North America
+		{db.DigitalOcean, "nyc1", "New York 1", true, "api"},
+		{db.DigitalOcean, "nyc3", "New York 3", true, "api"},
+		{db.DigitalOcean, "sfo2", "San Francisco 2", true, "api"},
+		{db.DigitalOcean, "sfo3", "San Francisco 3", true, "api"},
+		{db.DigitalOcean, "tor1", "Toronto 1", true, "api"},
+
+		// Europe
+		{db.DigitalOcean, "ams3", "Amsterdam 3", true, "api"},
+		{db.DigitalOcean, "fra1", "Frankfurt 1", true, "api"},
+		{db.DigitalOcean, "lon1", "London 1", true, "api"},
+
+		// Asia Pacific
+		{db.DigitalOcean, "sgp1", "Singapore 1", true, "api"},
+		{db.DigitalOcean, "blr1", "Bangalore 1", true, "api"},
+		{db.DigitalOcean, "syd1", "Sydney 1", true, "api"},
+	}
+}
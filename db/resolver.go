@@ -4,6 +4,7 @@ package db
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/shopspring/decimal"
 )
@@ -44,7 +45,9 @@ type ResolveRequest struct {
 	Region        string
 	Attributes    map[string]string
 	Unit          string
-	Alias         string // Optional, uses default if empty
+	Alias         string    // Optional, uses default if empty
+	AsOf          time.Time // Optional, zero resolves the current price
+	Currency      string    // Optional, empty matches any currency
 }
 
 // ResolveResult contains the resolved rate or error info
@@ -68,7 +71,7 @@ func (r *Resolver) Resolve(ctx context.Context, req ResolveRequest) (*ResolveRes
 	}
 	if snapshot == nil {
 		if r.strictMode {
-			return nil, fmt.Errorf("strict mode: no active snapshot for %s/%s/%s", req.Cloud, req.Region, alias)
+			return nil, resolutionErrorf(ErrNoSnapshot, "strict mode: no active snapshot for %s/%s/%s", req.Cloud, req.Region, alias)
 		}
 		return &ResolveResult{
 			IsSymbolic: true,
@@ -76,18 +79,22 @@ func (r *Resolver) Resolve(ctx context.Context, req ResolveRequest) (*ResolveRes
 		}, nil
 	}
 
-	// Resolve the rate
-	rate, err := r.store.ResolveRate(ctx, req.Cloud, req.Service, req.ProductFamily, req.Region, req.Attributes, req.Unit, alias)
+	return r.resolveInSnapshot(ctx, req, alias)
+}
+
+// resolveInSnapshot resolves a rate once the active snapshot is known to exist
+func (r *Resolver) resolveInSnapshot(ctx context.Context, req ResolveRequest, alias string) (*ResolveResult, error) {
+	rate, err := r.store.ResolveRate(ctx, req.Cloud, req.Service, req.ProductFamily, req.Region, req.Attributes, req.Unit, alias, ResolveOptions{AsOf: req.AsOf, Currency: req.Currency})
 	if err != nil {
 		return nil, fmt.Errorf("failed to resolve rate: %w", err)
 	}
 	if rate == nil {
 		if r.strictMode {
-			return nil, fmt.Errorf("strict mode: no rate found for %s/%s/%s", req.Service, req.ProductFamily, req.Unit)
+			return nil, resolutionErrorf(ErrNoRate, "strict mode: no rate found for %s/%s/%s%s", req.Service, req.ProductFamily, req.Unit, currencySuffix(req.Currency))
 		}
 		return &ResolveResult{
 			IsSymbolic: true,
-			Reason:     fmt.Sprintf("rate not found: %s/%s/%s", req.Service, req.ProductFamily, req.Unit),
+			Reason:     fmt.Sprintf("rate not found: %s/%s/%s%s", req.Service, req.ProductFamily, req.Unit, currencySuffix(req.Currency)),
 		}, nil
 	}
 
@@ -97,6 +104,58 @@ func (r *Resolver) Resolve(ctx context.Context, req ResolveRequest) (*ResolveRes
 	}, nil
 }
 
+// snapshotGroup identifies the requests that share one active snapshot
+type snapshotGroup struct {
+	cloud  CloudProvider
+	region string
+	alias  string
+}
+
+// ResolveBatch resolves many requests, looking up each cloud/region/alias
+// active snapshot once. Results are in request order; in strict mode the first
+// unresolvable request fails the whole batch.
+func (r *Resolver) ResolveBatch(ctx context.Context, reqs []ResolveRequest) ([]*ResolveResult, error) {
+	results := make([]*ResolveResult, len(reqs))
+	snapshots := make(map[snapshotGroup]*PricingSnapshot)
+
+	for i, req := range reqs {
+		alias := req.Alias
+		if alias == "" {
+			alias = r.defaultAlias
+		}
+
+		group := snapshotGroup{cloud: req.Cloud, region: req.Region, alias: alias}
+		snapshot, seen := snapshots[group]
+		if !seen {
+			var err error
+			snapshot, err = r.store.GetActiveSnapshot(ctx, req.Cloud, req.Region, alias)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get active snapshot: %w", err)
+			}
+			snapshots[group] = snapshot
+		}
+
+		if snapshot == nil {
+			if r.strictMode {
+				return nil, resolutionErrorf(ErrNoSnapshot, "strict mode: no active snapshot for %s/%s/%s", req.Cloud, req.Region, alias)
+			}
+			results[i] = &ResolveResult{
+				IsSymbolic: true,
+				Reason:     fmt.Sprintf("no pricing snapshot for %s/%s", req.Cloud, req.Region),
+			}
+			continue
+		}
+
+		result, err := r.resolveInSnapshot(ctx, req, alias)
+		if err != nil {
+			return nil, err
+		}
+		results[i] = result
+	}
+
+	return results, nil
+}
+
 // ResolveTiered resolves tiered pricing (S3, data transfer, etc.)
 func (r *Resolver) ResolveTiered(ctx context.Context, req ResolveRequest) ([]TieredRate, error) {
 	alias := req.Alias
@@ -104,7 +163,8 @@ func (r *Resolver) ResolveTiered(ctx context.Context, req ResolveRequest) ([]Tie
 		alias = r.defaultAlias
 	}
 
-	return r.store.ResolveTieredRates(ctx, req.Cloud, req.Service, req.ProductFamily, req.Region, req.Attributes, req.Unit, alias)
+	return r.store.ResolveTieredRates(ctx, req.Cloud, req.Service, req.ProductFamily, req.Region, req.Attributes, req.Unit, alias,
+		ResolveOptions{AsOf: req.AsOf, Currency: req.Currency})
 }
 
 // CalculateTieredCost computes cost for tiered pricing
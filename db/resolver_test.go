@@ -0,0 +1,119 @@
+// Package db - Resolver tests
+package db
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// lookupCountingStore serves one snapshot per region and a price per
+// service, counting active snapshot lookups
+type lookupCountingStore struct {
+	PricingStore
+	snapshots map[string]*PricingSnapshot
+	prices    map[string]decimal.Decimal
+	lookups   int
+}
+
+func (s *lookupCountingStore) GetActiveSnapshot(ctx context.Context, cloud CloudProvider, region, alias string) (*PricingSnapshot, error) {
+	s.lookups++
+	return s.snapshots[region], nil
+}
+
+func (s *lookupCountingStore) ResolveRate(ctx context.Context, cloud CloudProvider, service, productFamily, region string, attrs map[string]string, unit, alias string, opts ResolveOptions) (*ResolvedRate, error) {
+	price, ok := s.prices[service]
+	if !ok {
+		return nil, nil
+	}
+	return &ResolvedRate{Price: price, Currency: "USD", Confidence: 1, SnapshotID: s.snapshots[region].ID}, nil
+}
+
+func newLookupCountingStore() *lookupCountingStore {
+	return &lookupCountingStore{
+		snapshots: map[string]*PricingSnapshot{
+			"us-east-1": {ID: uuid.New()},
+			"eu-west-1": {ID: uuid.New()},
+		},
+		prices: map[string]decimal.Decimal{
+			"AmazonEC2": decimal.RequireFromString("0.0104"),
+			"AmazonS3":  decimal.RequireFromString("0.023"),
+		},
+	}
+}
+
+func batchRequests(n int) []ResolveRequest {
+	regions := []string{"us-east-1", "eu-west-1", "ap-south-1"}
+	services := []string{"AmazonEC2", "AmazonS3", "AmazonRDS"}
+	reqs := make([]ResolveRequest, n)
+	for i := range reqs {
+		reqs[i] = ResolveRequest{Cloud: AWS, Service: services[i%len(services)], Region: regions[(i/len(services))%len(regions)], Unit: "hrs"}
+	}
+	return reqs
+}
+
+func TestResolveBatchMatchesResolve(t *testing.T) {
+	ctx := context.Background()
+	reqs := batchRequests(18)
+	store := newLookupCountingStore()
+	resolver := NewResolver(store)
+
+	batch, err := resolver.ResolveBatch(ctx, reqs)
+	if err != nil {
+		t.Fatalf("batch resolve failed: %v", err)
+	}
+	if store.lookups != 3 {
+		t.Errorf("expected one snapshot lookup per region, got %d", store.lookups)
+	}
+
+	for i, req := range reqs {
+		single, err := resolver.Resolve(ctx, req)
+		if err != nil {
+			t.Fatalf("resolve %d failed: %v", i, err)
+		}
+		got := batch[i]
+		if got.IsSymbolic != single.IsSymbolic || got.Reason != single.Reason {
+			t.Errorf("request %d: batch %+v, single %+v", i, got, single)
+			continue
+		}
+		if !got.IsSymbolic && (!got.Rate.Price.Equal(single.Rate.Price) || got.Rate.SnapshotID != single.Rate.SnapshotID) {
+			t.Errorf("request %d: batch rate %+v, single rate %+v", i, got.Rate, single.Rate)
+		}
+	}
+
+	if _, err := resolver.WithStrictMode(true).ResolveBatch(ctx, reqs); err == nil {
+		t.Error("expected strict mode to fail the batch on a missing rate")
+	}
+}
+
+// The fake store has no latency, so lookups/op is the number to compare
+func BenchmarkResolve(b *testing.B) {
+	for _, n := range []int{50, 500} {
+		reqs := batchRequests(n)
+		b.Run(fmt.Sprintf("PerItem/%d", n), func(b *testing.B) {
+			store := newLookupCountingStore()
+			resolver := NewResolver(store)
+			for i := 0; i < b.N; i++ {
+				for _, req := range reqs {
+					if _, err := resolver.Resolve(context.Background(), req); err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+			b.ReportMetric(float64(store.lookups)/float64(b.N), "lookups/op")
+		})
+		b.Run(fmt.Sprintf("Batch/%d", n), func(b *testing.B) {
+			store := newLookupCountingStore()
+			resolver := NewResolver(store)
+			for i := 0; i < b.N; i++ {
+				if _, err := resolver.ResolveBatch(context.Background(), reqs); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(store.lookups)/float64(b.N), "lookups/op")
+		})
+	}
+}
@@ -4,6 +4,9 @@ package db
 import (
 	"context"
 	"fmt"
+	"math"
+	"sync"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
@@ -19,14 +22,23 @@ const (
 	Strict
 )
 
-// StrictResolver provides strict-mode pricing resolution
+// StrictResolver provides strict-mode pricing resolution. Once configured it
+// is safe for concurrent use.
 type StrictResolver struct {
 	store        PricingStore
 	defaultAlias string
 	mode         StrictMode
+	mu           sync.Mutex           // Guards usedSnapshot
 	usedSnapshot map[string]uuid.UUID // Track snapshots used for auditability
+	discount     *DiscountOverlay
+	fuzzyFactor  float64 // Confidence multiplier for ambiguous permissive matches (0 = off)
+	configErr    error   // First invalid builder option, reported by Validate
 }
 
+// DefaultFuzzyConfidence is the confidence multiplier for permissive
+// resolutions whose attributes match several rates
+const DefaultFuzzyConfidence = 0.5
+
 // NewStrictResolver creates a new strict resolver
 func NewStrictResolver(store PricingStore) *StrictResolver {
 	return &StrictResolver{
@@ -34,6 +46,7 @@ func NewStrictResolver(store PricingStore) *StrictResolver {
 		defaultAlias: "default",
 		mode:         Permissive,
 		usedSnapshot: make(map[string]uuid.UUID),
+		fuzzyFactor:  DefaultFuzzyConfidence,
 	}
 }
 
@@ -49,6 +62,25 @@ func (r *StrictResolver) WithAlias(alias string) *StrictResolver {
 	return r
 }
 
+// WithDiscount applies negotiated discounts to every resolved price.
+// Results keep the undiscounted list price. An invalid config is reported by
+// Validate and fails every resolution.
+func (r *StrictResolver) WithDiscount(config DiscountConfig) *StrictResolver {
+	if err := config.Validate(); err != nil {
+		if r.configErr == nil {
+			r.configErr = fmt.Errorf("invalid discount config: %w", err)
+		}
+		return r
+	}
+	r.discount = NewDiscountOverlay(config)
+	return r
+}
+
+// Validate reports the first invalid option passed to a With* builder
+func (r *StrictResolver) Validate() error {
+	return r.configErr
+}
+
 // ResolutionRequest contains rate resolution parameters
 type ResolutionRequest struct {
 	Cloud         CloudProvider
@@ -58,6 +90,13 @@ type ResolutionRequest struct {
 	Attributes    map[string]string
 	Unit          string
 	Alias         string
+
+	// AsOf resolves the price effective at this time (zero = now)
+	AsOf time.Time
+
+	// Currency restricts resolution to rates in this currency (empty = any),
+	// so one estimate never mixes currencies
+	Currency string
 }
 
 // ResolutionResult contains resolution outcome
@@ -68,14 +107,31 @@ type ResolutionResult struct {
 	Confidence float64
 	SnapshotID uuid.UUID
 	Source     string
+
+	// Discount info: ListPrice is the catalog price before DiscountPercent
+	// was applied (nil when no discount applies)
+	ListPrice       *decimal.Decimal
+	DiscountPercent float64
 	
 	// Symbolic info
 	IsSymbolic bool
 	Reason     string
 }
 
+// WithFuzzyConfidence scales the confidence of permissive-mode resolutions
+// whose attributes match several rates, so the resolver fell back to picking
+// one of them (DefaultFuzzyConfidence unless set). factor is clamped to
+// [0, 1]; 0 disables the check.
+func (r *StrictResolver) WithFuzzyConfidence(factor float64) *StrictResolver {
+	r.fuzzyFactor = math.Max(0, math.Min(1, factor))
+	return r
+}
+
 // Resolve resolves a rate with strict mode enforcement
 func (r *StrictResolver) Resolve(ctx context.Context, req ResolutionRequest) (*ResolutionResult, error) {
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
 	alias := req.Alias
 	if alias == "" {
 		alias = r.defaultAlias
@@ -93,51 +149,148 @@ func (r *StrictResolver) Resolve(ctx context.Context, req ResolutionRequest) (*R
 	}
 	if snapshot == nil {
 		// Alias mismatch or missing snapshot - always fail
-		return nil, fmt.Errorf("no active pricing snapshot for %s/%s/%s", req.Cloud, req.Region, alias)
+		return nil, resolutionErrorf(ErrNoSnapshot, "no active pricing snapshot for %s/%s/%s", req.Cloud, req.Region, alias)
 	}
 	
 	// Track snapshot for audit
-	key := fmt.Sprintf("%s:%s:%s", req.Cloud, req.Region, alias)
-	r.usedSnapshot[key] = snapshot.ID
+	r.trackSnapshot(req.Cloud, req.Region, alias, snapshot.ID)
 	
-	// 3. Resolve rate
+	// 3. Resolve rate; strict mode also rejects attributes matching several rates
+	opts := ResolveOptions{AsOf: req.AsOf, Currency: req.Currency}
+	if r.mode == Strict {
+		rate, err := r.unambiguousRate(ctx, req, alias, opts)
+		if err != nil {
+			return nil, err
+		}
+		return r.result(req, snapshot, rate)
+	}
+
 	rate, err := r.store.ResolveRate(
 		ctx, req.Cloud, req.Service, req.ProductFamily,
-		req.Region, req.Attributes, req.Unit, alias,
+		req.Region, req.Attributes, req.Unit, alias, opts,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("rate resolution failed: %w", err)
 	}
+	if rate != nil && r.fuzzyFactor > 0 {
+		matches, err := r.store.ResolveAllMatching(
+			ctx, req.Cloud, req.Service, req.ProductFamily,
+			req.Region, req.Attributes, req.Unit, alias, opts,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("rate resolution failed: %w", err)
+		}
+		if len(matches) > 1 {
+			rate.Confidence *= r.fuzzyFactor
+		}
+	}
 	
-	// 4. Handle missing rate
+	return r.result(req, snapshot, rate)
+}
+
+// unambiguousRate resolves every matching rate and fails if the request's
+// attributes are too partial to pick one (nil when none match)
+func (r *StrictResolver) unambiguousRate(ctx context.Context, req ResolutionRequest, alias string, opts ResolveOptions) (*ResolvedRate, error) {
+	rates, err := r.store.ResolveAllMatching(
+		ctx, req.Cloud, req.Service, req.ProductFamily,
+		req.Region, req.Attributes, req.Unit, alias, opts,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("rate resolution failed: %w", err)
+	}
+
+	switch len(rates) {
+	case 0:
+		return nil, nil
+	case 1:
+		return &rates[0], nil
+	}
+	return nil, &AmbiguousRateError{Request: req, Matches: rates}
+}
+
+// result turns a resolved rate, or its absence, into a ResolutionResult
+func (r *StrictResolver) result(req ResolutionRequest, snapshot *PricingSnapshot, rate *ResolvedRate) (*ResolutionResult, error) {
+	// Handle missing rate
 	if rate == nil {
 		if r.mode == Strict {
-			return nil, fmt.Errorf("strict mode: no rate for %s/%s/%s unit=%s",
-				req.Service, req.ProductFamily, req.Region, req.Unit)
+			return nil, resolutionErrorf(ErrNoRate, "strict mode: no rate for %s/%s/%s unit=%s%s",
+				req.Service, req.ProductFamily, req.Region, req.Unit, currencySuffix(req.Currency))
 		}
 		
 		// Permissive mode - return symbolic
 		return &ResolutionResult{
 			IsSymbolic: true,
-			Reason:     fmt.Sprintf("rate not found: %s/%s/%s", req.Service, req.ProductFamily, req.Unit),
+			Reason:     fmt.Sprintf("rate not found: %s/%s/%s%s", req.Service, req.ProductFamily, req.Unit, currencySuffix(req.Currency)),
 			SnapshotID: snapshot.ID,
 			Source:     snapshot.Source,
 		}, nil
 	}
 	
-	// 5. Success
-	return &ResolutionResult{
+	// Success
+	result := &ResolutionResult{
 		Price:      &rate.Price,
 		Currency:   rate.Currency,
 		Confidence: rate.Confidence,
 		SnapshotID: rate.SnapshotID,
 		Source:     rate.Source,
 		IsSymbolic: false,
-	}, nil
+	}
+
+	// Apply negotiated discount
+	if r.discount != nil {
+		if price, pct := r.discount.Apply(req.Service, rate.Price); pct > 0 {
+			listPrice := rate.Price
+			result.Price = &price
+			result.ListPrice = &listPrice
+			result.DiscountPercent = pct
+		}
+	}
+	return result, nil
+}
+
+// ResolveAsOf resolves a rate from the snapshot that was valid at the given
+// time rather than the active one, for retrospective cost analysis.
+// Effective dates within that snapshot are also evaluated at that time
+// unless req.AsOf is set.
+func (r *StrictResolver) ResolveAsOf(ctx context.Context, req ResolutionRequest, at time.Time) (*ResolutionResult, error) {
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	alias := req.Alias
+	if alias == "" {
+		alias = r.defaultAlias
+	}
+	if alias == "" {
+		return nil, fmt.Errorf("provider alias cannot be empty")
+	}
+
+	snapshot, err := r.store.GetSnapshotAsOf(ctx, req.Cloud, req.Region, alias, at)
+	if err != nil {
+		return nil, fmt.Errorf("snapshot lookup failed: %w", err)
+	}
+	if snapshot == nil {
+		return nil, resolutionErrorf(ErrNoSnapshot, "no pricing snapshot for %s/%s/%s valid at %s", req.Cloud, req.Region, alias, at.Format(time.RFC3339))
+	}
+
+	r.trackSnapshot(req.Cloud, req.Region, alias, snapshot.ID)
+
+	asOf := req.AsOf
+	if asOf.IsZero() {
+		asOf = at
+	}
+	query := RateKeyQuery{Service: req.Service, ProductFamily: req.ProductFamily, Attributes: req.Attributes, Unit: req.Unit}
+	rates, err := r.store.ResolveRatesBatch(ctx, snapshot.ID, []RateKeyQuery{query}, ResolveOptions{AsOf: asOf, Currency: req.Currency})
+	if err != nil {
+		return nil, fmt.Errorf("rate resolution failed: %w", err)
+	}
+	return r.result(req, snapshot, rates[0])
 }
 
 // ResolveTiered resolves tiered pricing
 func (r *StrictResolver) ResolveTiered(ctx context.Context, req ResolutionRequest) (*TieredResolutionResult, error) {
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
 	alias := req.Alias
 	if alias == "" {
 		alias = r.defaultAlias
@@ -149,13 +302,14 @@ func (r *StrictResolver) ResolveTiered(ctx context.Context, req ResolutionReques
 		return nil, fmt.Errorf("snapshot lookup failed: %w", err)
 	}
 	if snapshot == nil {
-		return nil, fmt.Errorf("no active pricing snapshot for %s/%s/%s", req.Cloud, req.Region, alias)
+		return nil, resolutionErrorf(ErrNoSnapshot, "no active pricing snapshot for %s/%s/%s", req.Cloud, req.Region, alias)
 	}
 	
 	// Get tiers
 	tiers, err := r.store.ResolveTieredRates(
 		ctx, req.Cloud, req.Service, req.ProductFamily,
 		req.Region, req.Attributes, req.Unit, alias,
+		ResolveOptions{AsOf: req.AsOf, Currency: req.Currency},
 	)
 	if err != nil {
 		return nil, fmt.Errorf("tiered rate resolution failed: %w", err)
@@ -163,22 +317,39 @@ func (r *StrictResolver) ResolveTiered(ctx context.Context, req ResolutionReques
 	
 	if len(tiers) == 0 {
 		if r.mode == Strict {
-			return nil, fmt.Errorf("strict mode: no tiered rates for %s/%s/%s unit=%s",
-				req.Service, req.ProductFamily, req.Region, req.Unit)
+			return nil, resolutionErrorf(ErrNoRate, "strict mode: no tiered rates for %s/%s/%s unit=%s%s",
+				req.Service, req.ProductFamily, req.Region, req.Unit, currencySuffix(req.Currency))
 		}
 		
 		return &TieredResolutionResult{
 			IsSymbolic: true,
-			Reason:     fmt.Sprintf("tiered rates not found: %s/%s", req.Service, req.ProductFamily),
+			Reason:     fmt.Sprintf("tiered rates not found: %s/%s%s", req.Service, req.ProductFamily, currencySuffix(req.Currency)),
 			SnapshotID: snapshot.ID,
 		}, nil
 	}
 	
-	return &TieredResolutionResult{
+	result := &TieredResolutionResult{
 		Tiers:      tiers,
 		SnapshotID: snapshot.ID,
 		IsSymbolic: false,
-	}, nil
+	}
+	if r.discount != nil && r.discount.PercentFor(req.Service) > 0 {
+		result.ListTiers = tiers
+		result.Tiers = make([]TieredRate, len(tiers))
+		for i, t := range tiers {
+			t.Price, result.DiscountPercent = r.discount.Apply(req.Service, t.Price)
+			result.Tiers[i] = t
+		}
+	}
+	return result, nil
+}
+
+// currencySuffix describes a currency filter in error messages
+func currencySuffix(currency string) string {
+	if currency == "" {
+		return ""
+	}
+	return " in " + currency
 }
 
 // TieredResolutionResult contains tiered resolution outcome
@@ -187,6 +358,10 @@ type TieredResolutionResult struct {
 	SnapshotID uuid.UUID
 	IsSymbolic bool
 	Reason     string
+
+	// ListTiers are the undiscounted tiers when DiscountPercent was applied
+	ListTiers       []TieredRate
+	DiscountPercent float64
 }
 
 // CalculateCost computes cost from tiered rates and usage
@@ -194,8 +369,17 @@ func (r *TieredResolutionResult) CalculateCost(usage decimal.Decimal) (decimal.D
 	return CalculateTieredCost(usage, r.Tiers)
 }
 
+// trackSnapshot records the snapshot a resolution used for audit
+func (r *StrictResolver) trackSnapshot(cloud CloudProvider, region, alias string, id uuid.UUID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.usedSnapshot[fmt.Sprintf("%s:%s:%s", cloud, region, alias)] = id
+}
+
 // GetUsedSnapshots returns all snapshots used during resolution
 func (r *StrictResolver) GetUsedSnapshots() map[string]uuid.UUID {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	result := make(map[string]uuid.UUID)
 	for k, v := range r.usedSnapshot {
 		result[k] = v
@@ -205,6 +389,8 @@ func (r *StrictResolver) GetUsedSnapshots() map[string]uuid.UUID {
 
 // ResetSnapshots resets the snapshot tracking
 func (r *StrictResolver) ResetSnapshots() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	r.usedSnapshot = make(map[string]uuid.UUID)
 }
 
@@ -218,6 +404,8 @@ type SnapshotAudit struct {
 
 // GetAuditInfo returns audit information for all used snapshots
 func (r *StrictResolver) GetAuditInfo() []SnapshotAudit {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	var audits []SnapshotAudit
 	for key, id := range r.usedSnapshot {
 		parts := splitKey(key)
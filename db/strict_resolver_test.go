@@ -0,0 +1,260 @@
+// Package db - Strict resolver tests
+package db
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// datedRate is a rate with an optional effective date for the fake store
+type datedRate struct {
+	price     decimal.Decimal
+	effective *time.Time
+}
+
+// effectiveDateStore is a minimal PricingStore that applies the same
+// effective-date selection rule as the PostgreSQL query.
+type effectiveDateStore struct {
+	PricingStore
+	snapshot *PricingSnapshot
+	rates    []datedRate
+	lastOpts ResolveOptions
+}
+
+func (s *effectiveDateStore) GetActiveSnapshot(ctx context.Context, cloud CloudProvider, region, alias string) (*PricingSnapshot, error) {
+	return s.snapshot, nil
+}
+
+func (s *effectiveDateStore) ResolveRate(ctx context.Context, cloud CloudProvider, service, productFamily, region string, attrs map[string]string, unit, alias string, opts ResolveOptions) (*ResolvedRate, error) {
+	s.lastOpts = opts
+	asOf := opts.AsOf
+	if asOf.IsZero() {
+		asOf = time.Now()
+	}
+
+	var best *datedRate
+	for i := range s.rates {
+		r := &s.rates[i]
+		if r.effective != nil && r.effective.After(asOf) {
+			continue
+		}
+		switch {
+		case best == nil:
+			best = r
+		case best.effective == nil && r.effective != nil:
+			best = r
+		case best.effective != nil && r.effective != nil && r.effective.After(*best.effective):
+			best = r
+		}
+	}
+	if best == nil {
+		return nil, nil
+	}
+	return &ResolvedRate{Price: best.price, Currency: "USD", Confidence: 1, SnapshotID: s.snapshot.ID}, nil
+}
+
+func (s *effectiveDateStore) ResolveAllMatching(ctx context.Context, cloud CloudProvider, service, productFamily, region string, attrs map[string]string, unit, alias string, opts ResolveOptions) ([]ResolvedRate, error) {
+	return singleMatch(s.ResolveRate(ctx, cloud, service, productFamily, region, attrs, unit, alias, opts))
+}
+
+// singleMatch adapts a fake store's ResolveRate to ResolveAllMatching for
+// stores that hold a single rate key
+func singleMatch(rate *ResolvedRate, err error) ([]ResolvedRate, error) {
+	if rate == nil || err != nil {
+		return nil, err
+	}
+	return []ResolvedRate{*rate}, nil
+}
+
+func TestStrictResolverAsOf(t *testing.T) {
+	jan := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	jul := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	store := &effectiveDateStore{
+		snapshot: &PricingSnapshot{ID: uuid.New(), Source: "test"},
+		rates: []datedRate{
+			{price: decimal.NewFromFloat(0.10), effective: &jan},
+			{price: decimal.NewFromFloat(0.12), effective: &jul},
+		},
+	}
+	resolver := NewStrictResolver(store).WithMode(Strict)
+
+	req := ResolutionRequest{
+		Cloud:   AWS,
+		Service: "AmazonEC2",
+		Region:  "us-east-1",
+		Unit:    "hours",
+		AsOf:    time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+	}
+	result, err := resolver.Resolve(context.Background(), req)
+	if err != nil {
+		t.Fatalf("resolve failed: %v", err)
+	}
+	if !store.lastOpts.AsOf.Equal(req.AsOf) {
+		t.Errorf("AsOf not passed to store: got %v", store.lastOpts.AsOf)
+	}
+	if !result.Price.Equal(decimal.NewFromFloat(0.10)) {
+		t.Errorf("expected January price 0.10, got %s", result.Price)
+	}
+
+	req.AsOf = jul.Add(24 * time.Hour)
+	result, err = resolver.Resolve(context.Background(), req)
+	if err != nil {
+		t.Fatalf("resolve failed: %v", err)
+	}
+	if !result.Price.Equal(decimal.NewFromFloat(0.12)) {
+		t.Errorf("expected July price 0.12, got %s", result.Price)
+	}
+
+	// Before any effective date there is nothing to resolve
+	req.AsOf = jan.Add(-24 * time.Hour)
+	if _, err := resolver.Resolve(context.Background(), req); err == nil {
+		t.Error("expected strict mode error before the first effective date")
+	}
+}
+
+// currencyStore holds one rate per currency and filters on opts.Currency
+// like the PostgreSQL query
+type currencyStore struct {
+	PricingStore
+	snapshot *PricingSnapshot
+	prices   map[string]decimal.Decimal // currency -> price
+}
+
+func (s *currencyStore) GetActiveSnapshot(ctx context.Context, cloud CloudProvider, region, alias string) (*PricingSnapshot, error) {
+	return s.snapshot, nil
+}
+
+func (s *currencyStore) ResolveRate(ctx context.Context, cloud CloudProvider, service, productFamily, region string, attrs map[string]string, unit, alias string, opts ResolveOptions) (*ResolvedRate, error) {
+	price, ok := s.prices[opts.Currency]
+	if !ok {
+		return nil, nil
+	}
+	return &ResolvedRate{Price: price, Currency: opts.Currency, Confidence: 1, SnapshotID: s.snapshot.ID}, nil
+}
+
+func (s *currencyStore) ResolveAllMatching(ctx context.Context, cloud CloudProvider, service, productFamily, region string, attrs map[string]string, unit, alias string, opts ResolveOptions) ([]ResolvedRate, error) {
+	return singleMatch(s.ResolveRate(ctx, cloud, service, productFamily, region, attrs, unit, alias, opts))
+}
+
+func TestStrictResolverCurrency(t *testing.T) {
+	store := &currencyStore{
+		snapshot: &PricingSnapshot{ID: uuid.New(), Source: "test"},
+		prices: map[string]decimal.Decimal{
+			"USD": decimal.NewFromFloat(0.096),
+			"EUR": decimal.NewFromFloat(0.089),
+		},
+	}
+	req := ResolutionRequest{Cloud: Azure, Service: "Virtual Machines", Region: "westeurope", Unit: "hours", Currency: "EUR"}
+
+	result, err := NewStrictResolver(store).WithMode(Strict).Resolve(context.Background(), req)
+	if err != nil {
+		t.Fatalf("resolve failed: %v", err)
+	}
+	if result.Currency != "EUR" || !result.Price.Equal(decimal.NewFromFloat(0.089)) {
+		t.Errorf("expected EUR 0.089, got %s %s", result.Currency, result.Price)
+	}
+
+	// A currency the snapshot lacks is symbolic when permissive...
+	req.Currency = "GBP"
+	result, err = NewStrictResolver(store).Resolve(context.Background(), req)
+	if err != nil || !result.IsSymbolic || !strings.Contains(result.Reason, "GBP") {
+		t.Errorf("expected symbolic GBP result, got %+v (err %v)", result, err)
+	}
+
+	// ...and an error when strict
+	if _, err := NewStrictResolver(store).WithMode(Strict).Resolve(context.Background(), req); err == nil || !strings.Contains(err.Error(), "GBP") {
+		t.Errorf("expected strict GBP error, got %v", err)
+	}
+}
+
+// historyStore serves snapshots by validity window and resolves against
+// their rates, assuming at most one rate matches
+type historyStore struct {
+	PricingStore
+	snapshots []*PricingSnapshot
+	rates     map[uuid.UUID][]SnapshotRate
+}
+
+func (s *historyStore) GetSnapshotAsOf(ctx context.Context, cloud CloudProvider, region, alias string, at time.Time) (*PricingSnapshot, error) {
+	for _, snap := range s.snapshots {
+		if !snap.ValidFrom.After(at) && (snap.ValidTo == nil || snap.ValidTo.After(at)) {
+			return snap, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *historyStore) ResolveRatesBatch(ctx context.Context, id uuid.UUID, reqs []RateKeyQuery, opts ResolveOptions) (map[int]*ResolvedRate, error) {
+	results := make(map[int]*ResolvedRate)
+	for i, req := range reqs {
+		for _, r := range s.rates[id] {
+			if r.RateKey.Service == req.Service && r.Rate.Unit == req.Unit && AttributesContain(r.RateKey.Attributes, req.Attributes) {
+				results[i] = &ResolvedRate{Price: r.Rate.Price, Currency: r.Rate.Currency, Confidence: r.Rate.Confidence, SnapshotID: id}
+			}
+		}
+	}
+	return results, nil
+}
+
+func TestStrictResolverResolveAsOf(t *testing.T) {
+	jan := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	jul := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	old := &PricingSnapshot{ID: uuid.New(), Source: "old", ValidFrom: jan, ValidTo: &jul, State: "archived"}
+	current := &PricingSnapshot{ID: uuid.New(), Source: "current", ValidFrom: jul, State: "ready", IsActive: true}
+
+	rate := func(price string) []SnapshotRate {
+		return []SnapshotRate{
+			{
+				RateKey: RateKey{Cloud: AWS, Service: "AmazonEC2", ProductFamily: "Compute Instance", Region: "us-east-1", Attributes: map[string]string{"instance_type": "t3.micro", "os": "linux"}},
+				Rate:    PricingRate{Unit: "hours", Price: decimal.RequireFromString(price), Currency: "USD", Confidence: 1},
+			},
+		}
+	}
+	store := &historyStore{
+		snapshots: []*PricingSnapshot{old, current},
+		rates:     map[uuid.UUID][]SnapshotRate{old.ID: rate("0.0104"), current.ID: rate("0.0096")},
+	}
+	resolver := NewStrictResolver(store).WithMode(Strict)
+	req := ResolutionRequest{
+		Cloud:         AWS,
+		Service:       "AmazonEC2",
+		ProductFamily: "Compute Instance",
+		Region:        "us-east-1",
+		Attributes:    map[string]string{"instance_type": "t3.micro"},
+		Unit:          "hours",
+	}
+
+	tests := []struct {
+		at       time.Time
+		snapshot uuid.UUID
+		price    string
+	}{
+		{time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC), old.ID, "0.0104"},
+		{time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC), current.ID, "0.0096"},
+		{jul, current.ID, "0.0096"}, // ValidTo is exclusive
+	}
+	for _, tt := range tests {
+		result, err := resolver.ResolveAsOf(context.Background(), req, tt.at)
+		if err != nil {
+			t.Fatalf("ResolveAsOf(%s): %v", tt.at, err)
+		}
+		if result.SnapshotID != tt.snapshot || !result.Price.Equal(decimal.RequireFromString(tt.price)) {
+			t.Errorf("ResolveAsOf(%s) = %s from %s, want %s from %s", tt.at, result.Price, result.SnapshotID, tt.price, tt.snapshot)
+		}
+	}
+	// Before any snapshot existed
+	if _, err := resolver.ResolveAsOf(context.Background(), req, jan.AddDate(0, -1, 0)); err == nil {
+		t.Error("expected error when no snapshot was valid")
+	}
+
+	// Missing rate in a historical snapshot follows strict mode
+	req.Attributes = map[string]string{"instance_type": "m5.large"}
+	if _, err := resolver.ResolveAsOf(context.Background(), req, time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)); err == nil {
+		t.Error("expected strict mode error for missing historical rate")
+	}
+}
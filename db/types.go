@@ -4,6 +4,9 @@ package db
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
 	"time"
 
 	"github.com/google/uuid"
@@ -17,6 +20,9 @@ const (
 	AWS   CloudProvider = "aws"
 	Azure CloudProvider = "azure"
 	GCP   CloudProvider = "gcp"
+	OCI   CloudProvider = "oci"
+
+	DigitalOcean CloudProvider = "digitalocean"
 )
 
 // PricingSnapshot represents a point-in-time pricing capture
@@ -32,7 +38,11 @@ type PricingSnapshot struct {
 	Hash          string        `db:"hash" json:"hash"`
 	Version       string        `db:"version" json:"version"`
 	IsActive      bool          `db:"is_active" json:"is_active"`
+	State         string        `db:"state" json:"state,omitempty"` // pending|staging|ready|failed|archived
 	CreatedAt     time.Time     `db:"created_at" json:"created_at"`
+
+	// Metadata records ingestion provenance, e.g. CI job ID, git SHA, operator
+	Metadata map[string]string `db:"metadata" json:"metadata,omitempty"`
 }
 
 // RateKey represents a unique pricing lookup key
@@ -59,6 +69,15 @@ type PricingRate struct {
 	TierMax       *decimal.Decimal `db:"tier_max" json:"tier_max,omitempty"`
 	EffectiveDate *time.Time      `db:"effective_date" json:"effective_date,omitempty"`
 	CreatedAt     time.Time       `db:"created_at" json:"created_at"`
+
+	// Labels are source identifiers kept for traceability, not matching
+	Labels map[string]string `db:"labels" json:"labels,omitempty"`
+}
+
+// SnapshotRate is a stored rate joined with its rate key
+type SnapshotRate struct {
+	RateKey RateKey
+	Rate    PricingRate
 }
 
 // ResolvedRate is the result of a pricing lookup
@@ -70,6 +89,36 @@ type ResolvedRate struct {
 	TierMax    *decimal.Decimal
 	SnapshotID uuid.UUID
 	Source     string
+	Attributes map[string]string // Rate key attributes, set by ResolveAllMatching
+}
+
+// ResolveOptions narrows rate resolution beyond the rate key
+type ResolveOptions struct {
+	// AsOf selects the latest rate effective on or before this time.
+	// Rates without an effective date are used only as a fallback.
+	// Zero means now.
+	AsOf time.Time
+
+	// Currency only matches rates in this ISO currency code. Empty matches any.
+	Currency string
+}
+
+// RateKeyQuery is one lookup of ResolveRatesBatch. Cloud and region come from
+// the snapshot.
+type RateKeyQuery struct {
+	Service       string
+	ProductFamily string
+	Attributes    map[string]string
+	Unit          string
+}
+
+// RateKeyFilter narrows ListRateKeys. Empty fields match every key;
+// Attributes matches keys containing them, as resolution does.
+type RateKeyFilter struct {
+	Service       string
+	ProductFamily string
+	Attributes    map[string]string
+	Limit         int // 0 = no limit
 }
 
 // TieredRate represents a pricing tier
@@ -77,6 +126,7 @@ type TieredRate struct {
 	Min        decimal.Decimal
 	Max        *decimal.Decimal // nil = unlimited
 	Price      decimal.Decimal
+	Currency   string
 	Confidence float64
 }
 
@@ -89,28 +139,61 @@ type PricingStore interface {
 	ActivateSnapshot(ctx context.Context, id uuid.UUID) error
 	ListSnapshots(ctx context.Context, cloud CloudProvider, region string) ([]*PricingSnapshot, error)
 	FindSnapshotByHash(ctx context.Context, cloud CloudProvider, region, alias, hash string) (*PricingSnapshot, error)
+	GetSnapshotAsOf(ctx context.Context, cloud CloudProvider, region, alias string, at time.Time) (*PricingSnapshot, error)
 
 	// Rate Keys
 	UpsertRateKey(ctx context.Context, key *RateKey) (*RateKey, error)
 	GetRateKey(ctx context.Context, cloud CloudProvider, service, productFamily, region string, attrs map[string]string) (*RateKey, error)
 
+	// ListRateKeys returns every known rate key of cloud and region (all
+	// regions when empty) matching filter, whichever snapshots use them,
+	// ordered bytewise by service, product family, AttributesSortKey and region
+	ListRateKeys(ctx context.Context, cloud CloudProvider, region string, filter RateKeyFilter) ([]RateKey, error)
+
 	// Rates
 	CreateRate(ctx context.Context, rate *PricingRate) error
 	BulkCreateRates(ctx context.Context, rates []*PricingRate) error
 	CountRates(ctx context.Context, snapshotID uuid.UUID) (int, error)
+	CountDistinctRateKeys(ctx context.Context, snapshotID uuid.UUID) (int, error)
+	GetRatesBySnapshot(ctx context.Context, snapshotID uuid.UUID) ([]SnapshotRate, error)
+	GetRatesPage(ctx context.Context, snapshotID, after uuid.UUID, limit int) ([]SnapshotRate, error)
+	DistinctCurrencies(ctx context.Context, snapshotID uuid.UUID) ([]string, error)
 	
 	// Resolution
-	ResolveRate(ctx context.Context, cloud CloudProvider, service, productFamily, region string, attrs map[string]string, unit, alias string) (*ResolvedRate, error)
-	ResolveTieredRates(ctx context.Context, cloud CloudProvider, service, productFamily, region string, attrs map[string]string, unit, alias string) ([]TieredRate, error)
+	ResolveRate(ctx context.Context, cloud CloudProvider, service, productFamily, region string, attrs map[string]string, unit, alias string, opts ResolveOptions) (*ResolvedRate, error)
+	ResolveAllMatching(ctx context.Context, cloud CloudProvider, service, productFamily, region string, attrs map[string]string, unit, alias string, opts ResolveOptions) ([]ResolvedRate, error)
+	ResolveTieredRates(ctx context.Context, cloud CloudProvider, service, productFamily, region string, attrs map[string]string, unit, alias string, opts ResolveOptions) ([]TieredRate, error)
+
+	// ResolveRatesBatch resolves every query against one snapshot in a single
+	// round trip, picking rates like ResolveRate. The result is keyed by query
+	// index; queries without a rate are absent.
+	ResolveRatesBatch(ctx context.Context, snapshotID uuid.UUID, reqs []RateKeyQuery, opts ResolveOptions) (map[int]*ResolvedRate, error)
 
 	// Transactions
 	BeginTx(ctx context.Context) (Tx, error)
 
+	// LockIngestion serializes commits for one cloud, region and alias across
+	// processes. With wait it blocks until the lock is free; otherwise it
+	// returns ErrIngestionLocked while another ingestion holds it.
+	LockIngestion(ctx context.Context, cloud CloudProvider, region, alias string, wait bool) (unlock func(), err error)
+
 	// Health
 	Ping(ctx context.Context) error
 	Close() error
 }
 
+// ErrIngestionLocked is returned by LockIngestion when another ingestion is
+// committing the same cloud, region and alias
+var ErrIngestionLocked = errors.New("another ingestion is committing this region")
+
+// IngestionLockKey maps a cloud, region and alias to the 64-bit key of the
+// ingestion advisory lock
+func IngestionLockKey(cloud CloudProvider, region, alias string) int64 {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "ingestion:%s/%s/%s", cloud, region, alias)
+	return int64(h.Sum64())
+}
+
 // Tx is a transaction interface for atomic operations
 type Tx interface {
 	CreateSnapshot(ctx context.Context, snapshot *PricingSnapshot) error
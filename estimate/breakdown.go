@@ -0,0 +1,108 @@
+// Package estimate - Cost breakdowns by service and tag
+package estimate
+
+import (
+	"sort"
+
+	"github.com/shopspring/decimal"
+)
+
+// UntaggedGroup is the GroupByTag group of resources without the tag
+const UntaggedGroup = "(untagged)"
+
+// CostGroup is one group of a breakdown
+type CostGroup struct {
+	Key           string          `json:"key"`
+	MonthlyCost   decimal.Decimal `json:"monthly_cost"`
+	Percent       float64         `json:"percent"`        // share of the report's total
+	Resources     int             `json:"resources"`      // resources with a component in the group
+	SymbolicCount int             `json:"symbolic_count"` // unpriced components, not in MonthlyCost
+}
+
+// CostBreakdown splits a report's total into groups whose subtotals add up
+// to it, most expensive first
+type CostBreakdown struct {
+	Dimension        string          `json:"dimension"` // "service" or "tag:<key>"
+	Groups           []CostGroup     `json:"groups"`
+	TotalMonthlyCost decimal.Decimal `json:"total_monthly_cost"`
+	Currency         string          `json:"currency"`
+}
+
+// GroupByService breaks the estimate down by the pricing service of each
+// component, so a resource priced by several services is split between them
+func (r *CostReport) GroupByService() *CostBreakdown {
+	groups := newCostGroups()
+	for _, res := range r.Resources {
+		for _, item := range res.Components {
+			groups.add(item.Service, res.Address, item)
+		}
+	}
+	return groups.breakdown("service", r)
+}
+
+// GroupByTag breaks the estimate down by the value of one resource tag;
+// resources without it are grouped under UntaggedGroup
+func (r *CostReport) GroupByTag(tagKey string) *CostBreakdown {
+	groups := newCostGroups()
+	for _, res := range r.Resources {
+		key, ok := res.Tags[tagKey]
+		if !ok || key == "" {
+			key = UntaggedGroup
+		}
+		for _, item := range res.Components {
+			groups.add(key, res.Address, item)
+		}
+	}
+	return groups.breakdown("tag:"+tagKey, r)
+}
+
+// costGroups accumulates groups and the resources seen in each
+type costGroups struct {
+	groups    map[string]*CostGroup
+	resources map[string]map[string]bool
+}
+
+func newCostGroups() *costGroups {
+	return &costGroups{groups: make(map[string]*CostGroup), resources: make(map[string]map[string]bool)}
+}
+
+func (c *costGroups) add(key, address string, item LineItem) {
+	g, ok := c.groups[key]
+	if !ok {
+		g = &CostGroup{Key: key, MonthlyCost: decimal.Zero}
+		c.groups[key] = g
+		c.resources[key] = make(map[string]bool)
+	}
+	if !c.resources[key][address] {
+		c.resources[key][address] = true
+		g.Resources++
+	}
+	if item.IsSymbolic {
+		g.SymbolicCount++
+		return
+	}
+	g.MonthlyCost = g.MonthlyCost.Add(item.MonthlyCost)
+}
+
+// breakdown sorts the groups by cost, then key, and sets their percentages
+func (c *costGroups) breakdown(dimension string, r *CostReport) *CostBreakdown {
+	b := &CostBreakdown{
+		Dimension:        dimension,
+		Groups:           make([]CostGroup, 0, len(c.groups)),
+		TotalMonthlyCost: r.TotalMonthlyCost,
+		Currency:         r.Currency,
+	}
+	for _, g := range c.groups {
+		if r.TotalMonthlyCost.IsPositive() {
+			g.Percent, _ = g.MonthlyCost.Div(r.TotalMonthlyCost).Mul(decimal.NewFromInt(100)).Round(2).Float64()
+		}
+		b.Groups = append(b.Groups, *g)
+	}
+	sort.Slice(b.Groups, func(i, j int) bool {
+		if cmp := b.Groups[i].MonthlyCost.Cmp(b.Groups[j].MonthlyCost); cmp != 0 {
+			return cmp > 0
+		}
+		return b.Groups[i].Key < b.Groups[j].Key
+	})
+	return b
+}
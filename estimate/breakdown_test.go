@@ -0,0 +1,116 @@
+// Package estimate - Cost breakdown tests
+package estimate
+
+import (
+	"context"
+	"testing"
+
+	"terraform-cost/db"
+	"terraform-cost/plan"
+
+	"github.com/shopspring/decimal"
+)
+
+func breakdownReport(t *testing.T) *CostReport {
+	t.Helper()
+	resolver := &fakeResolver{
+		rates: map[string]db.TieredRate{
+			"AmazonEC2/hours": {Price: dec("0.1"), Confidence: 1.0},
+			"AmazonRDS/hours": {Price: dec("0.2"), Confidence: 1.0},
+		},
+		tiers: map[string][]db.TieredRate{
+			"AmazonEC2/GB-month": {{Min: decimal.Zero, Price: dec("0.08"), Confidence: 1.0}},
+			"AmazonRDS/GB-month": {{Min: decimal.Zero, Price: dec("0.115"), Confidence: 1.0}},
+		},
+	}
+	tagged := func(rr plan.ResourceRequest, tags map[string]string) plan.ResourceRequest {
+		rr.Tags = tags
+		return rr
+	}
+	team := func(name string) map[string]string { return map[string]string{"team": name} }
+
+	requests := []plan.ResourceRequest{
+		tagged(request("aws_instance.web", "instance", "AmazonEC2", "hours", 1), team("web")),
+		tagged(request("aws_instance.web", "root_volume", "AmazonEC2", "GB-month", 20), team("web")),
+		tagged(request("aws_instance.api", "instance", "AmazonEC2", "hours", 1), team("api")),
+		tagged(request("aws_db_instance.main", "instance", "AmazonRDS", "hours", 2), team("api")),
+		tagged(request("aws_db_instance.main", "storage", "AmazonRDS", "GB-month", 100), team("api")),
+		request("aws_ebs_volume.scratch", "storage", "AmazonEC2", "GB-month", 50),
+		request("aws_nat_gateway.main", "gateway", "AmazonVPC", "hours", 1),
+	}
+	report, err := NewEstimator(resolver).Estimate(context.Background(), requests, UsageAssumptions{})
+	if err != nil {
+		t.Fatalf("estimate failed: %v", err)
+	}
+	return report
+}
+
+// checkSubtotals asserts the groups add up to the report's total
+func checkSubtotals(t *testing.T, b *CostBreakdown, report *CostReport) {
+	t.Helper()
+	sum := decimal.Zero
+	var percent float64
+	for _, g := range b.Groups {
+		sum = sum.Add(g.MonthlyCost)
+		percent += g.Percent
+	}
+	if !sum.Equal(report.TotalMonthlyCost) || !b.TotalMonthlyCost.Equal(report.TotalMonthlyCost) {
+		t.Errorf("%s subtotals sum to %s, want %s", b.Dimension, sum, report.TotalMonthlyCost)
+	}
+	if percent < 99.9 || percent > 100.1 {
+		t.Errorf("%s percentages sum to %.2f", b.Dimension, percent)
+	}
+}
+
+func TestGroupByService(t *testing.T) {
+	report := breakdownReport(t)
+	b := report.GroupByService()
+	checkSubtotals(t, b, report)
+
+	// EC2: 73 + 1.6 + 73 + 4 = 151.6; RDS: 292 + 11.5 = 303.5; VPC unpriced
+	want := []struct {
+		key       string
+		cost      string
+		percent   float64
+		resources int
+		symbolic  int
+	}{
+		{"AmazonRDS", "303.5", 66.69, 1, 0},
+		{"AmazonEC2", "151.6", 33.31, 3, 0},
+		{"AmazonVPC", "0", 0, 1, 1},
+	}
+	if b.Dimension != "service" || len(b.Groups) != len(want) {
+		t.Fatalf("unexpected breakdown %+v", b)
+	}
+	for i, w := range want {
+		g := b.Groups[i]
+		if g.Key != w.key || !g.MonthlyCost.Equal(dec(w.cost)) || g.Percent != w.percent || g.Resources != w.resources || g.SymbolicCount != w.symbolic {
+			t.Errorf("group %d = %+v, want %+v", i, g, w)
+		}
+	}
+}
+
+func TestGroupByTag(t *testing.T) {
+	report := breakdownReport(t)
+	b := report.GroupByTag("team")
+	checkSubtotals(t, b, report)
+
+	// api: 73 + 292 + 11.5; web: 73 + 1.6; untagged: 4 and the unpriced gateway
+	want := map[string]string{"api": "376.5", "web": "74.6", UntaggedGroup: "4"}
+	if b.Dimension != "tag:team" || len(b.Groups) != len(want) {
+		t.Fatalf("unexpected breakdown %+v", b)
+	}
+	for _, g := range b.Groups {
+		if !g.MonthlyCost.Equal(dec(want[g.Key])) {
+			t.Errorf("team %s = %s, want %s", g.Key, g.MonthlyCost, want[g.Key])
+		}
+	}
+	if b.Groups[0].Key != "api" || b.Groups[2].Key != UntaggedGroup || b.Groups[2].Resources != 2 || b.Groups[2].SymbolicCount != 1 {
+		t.Errorf("expected groups ordered by cost with the untagged resources last, got %+v", b.Groups)
+	}
+
+	// A tag no resource has puts everything in one group
+	if all := report.GroupByTag("owner"); len(all.Groups) != 1 || all.Groups[0].Key != UntaggedGroup || all.Groups[0].Percent != 100 {
+		t.Errorf("expected a single untagged group, got %+v", all.Groups)
+	}
+}
@@ -0,0 +1,198 @@
+// Package estimate - Cross-cloud price comparison for equivalent workloads
+package estimate
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"terraform-cost/db"
+
+	"github.com/shopspring/decimal"
+)
+
+// ComputeProfile is a provider-neutral instance size
+type ComputeProfile struct {
+	VCPUs     int     `json:"vcpus"`
+	MemoryGiB float64 `json:"memory_gib"`
+	Class     string  `json:"class"` // only "general-purpose" is mapped today
+}
+
+// CompareSpec is one workload expressed as each cloud's pricing requests
+type CompareSpec struct {
+	Name    string
+	Targets []CompareTarget
+}
+
+// CompareTarget prices the workload on one cloud as the sum of its components
+type CompareTarget struct {
+	Cloud      db.CloudProvider
+	Region     string
+	Offering   string // e.g. "m6i.2xlarge"
+	Components []CompareComponent
+}
+
+// CompareComponent is one hourly rate multiplied by a quantity, e.g. GCP
+// prices vCPUs and memory separately
+type CompareComponent struct {
+	Request  db.ResolutionRequest
+	Quantity float64
+}
+
+// ProviderPrice is the resolved price of the workload on one cloud
+type ProviderPrice struct {
+	Cloud       db.CloudProvider `json:"cloud"`
+	Region      string           `json:"region"`
+	Offering    string           `json:"offering"`
+	HourlyPrice decimal.Decimal  `json:"hourly_price"`
+	MonthlyCost decimal.Decimal  `json:"monthly_cost"`
+	Currency    string           `json:"currency"`
+	Confidence  float64          `json:"confidence"`
+	IsSymbolic  bool             `json:"is_symbolic"`
+	Reason      string           `json:"reason,omitempty"`
+}
+
+// ComparisonReport lists each cloud's price, cheapest first
+type ComparisonReport struct {
+	Spec     string          `json:"spec"`
+	Prices   []ProviderPrice `json:"prices"`
+	Cheapest *ProviderPrice  `json:"cheapest,omitempty"` // nil when nothing could be priced
+}
+
+// generalPurposeInstances maps vCPU count to each cloud's current-generation
+// general-purpose size with 4 GiB per vCPU
+var generalPurposeInstances = map[int]struct{ aws, azure, gcp string }{
+	2:  {"m6i.large", "Standard_D2s_v5", "n2-standard-2"},
+	4:  {"m6i.xlarge", "Standard_D4s_v5", "n2-standard-4"},
+	8:  {"m6i.2xlarge", "Standard_D8s_v5", "n2-standard-8"},
+	16: {"m6i.4xlarge", "Standard_D16s_v5", "n2-standard-16"},
+	32: {"m6i.8xlarge", "Standard_D32s_v5", "n2-standard-32"},
+}
+
+// NewComputeCompareSpec maps a compute profile onto Linux on-demand requests
+// for each cloud with a region in regions. Keys follow each normalizer's
+// canonical attributes; GCP is priced per vCPU and per GiB of memory.
+func NewComputeCompareSpec(profile ComputeProfile, regions map[db.CloudProvider]string) (CompareSpec, error) {
+	class := profile.Class
+	if class == "" {
+		class = "general-purpose"
+	}
+	sizes, ok := generalPurposeInstances[profile.VCPUs]
+	if class != "general-purpose" || !ok || profile.MemoryGiB != float64(profile.VCPUs*4) {
+		return CompareSpec{}, fmt.Errorf("no equivalent instances for %s %d vCPU / %g GiB", class, profile.VCPUs, profile.MemoryGiB)
+	}
+
+	spec := CompareSpec{Name: fmt.Sprintf("%s %d vCPU / %g GiB", class, profile.VCPUs, profile.MemoryGiB)}
+	for _, cloud := range []db.CloudProvider{db.AWS, db.Azure, db.GCP} {
+		region, ok := regions[cloud]
+		if !ok {
+			continue
+		}
+		target := CompareTarget{Cloud: cloud, Region: region}
+		switch cloud {
+		case db.AWS:
+			target.Offering = sizes.aws
+			target.Components = []CompareComponent{{Quantity: 1, Request: db.ResolutionRequest{
+				Cloud: db.AWS, Service: "AmazonEC2", ProductFamily: "Compute Instance", Region: region, Unit: "hours",
+				Attributes: map[string]string{"instance_type": sizes.aws, "os": "linux", "tenancy": "shared"},
+			}}}
+		case db.Azure:
+			target.Offering = sizes.azure
+			target.Components = []CompareComponent{{Quantity: 1, Request: db.ResolutionRequest{
+				Cloud: db.Azure, Service: "Virtual Machines", ProductFamily: "Compute", Region: region, Unit: "hours",
+				Attributes: map[string]string{"vm_size": strings.ToLower(sizes.azure), "type": "consumption"},
+			}}}
+		case db.GCP:
+			target.Offering = sizes.gcp
+			gcpAttrs := func(resource string) map[string]string {
+				return map[string]string{"machine_type": "n2", "machine_class": "predefined", "resource_type": resource, "usage_type": "on_demand"}
+			}
+			target.Components = []CompareComponent{
+				{Quantity: float64(profile.VCPUs), Request: db.ResolutionRequest{
+					Cloud: db.GCP, Service: "Compute Engine", ProductFamily: "Compute", Region: region, Unit: "hours",
+					Attributes: gcpAttrs("cpu"),
+				}},
+				{Quantity: profile.MemoryGiB, Request: db.ResolutionRequest{
+					Cloud: db.GCP, Service: "Compute Engine", ProductFamily: "Compute", Region: region, Unit: "GB-hours",
+					Attributes: gcpAttrs("ram"),
+				}},
+			}
+		}
+		spec.Targets = append(spec.Targets, target)
+	}
+
+	if len(spec.Targets) == 0 {
+		return CompareSpec{}, fmt.Errorf("no regions given for AWS, Azure or GCP")
+	}
+	return spec, nil
+}
+
+// ComparePricing resolves the workload on every target cloud and reports each
+// hourly and monthly price with the cheapest. Clouds missing a rate are kept
+// as symbolic entries; all priced clouds must share one currency.
+func ComparePricing(ctx context.Context, resolver RateResolver, spec CompareSpec) (*ComparisonReport, error) {
+	report := &ComparisonReport{Spec: spec.Name}
+
+	for _, target := range spec.Targets {
+		price, err := priceTarget(ctx, resolver, target)
+		if err != nil {
+			return nil, fmt.Errorf("%s/%s: %w", target.Cloud, target.Region, err)
+		}
+		report.Prices = append(report.Prices, price)
+	}
+
+	sort.SliceStable(report.Prices, func(i, j int) bool {
+		a, b := report.Prices[i], report.Prices[j]
+		if a.IsSymbolic != b.IsSymbolic {
+			return !a.IsSymbolic
+		}
+		return a.HourlyPrice.LessThan(b.HourlyPrice)
+	})
+
+	for i := range report.Prices {
+		p := &report.Prices[i]
+		if p.IsSymbolic {
+			continue
+		}
+		if report.Cheapest == nil {
+			report.Cheapest = p
+		} else if p.Currency != report.Cheapest.Currency {
+			return nil, fmt.Errorf("cannot compare %s and %s prices", report.Cheapest.Currency, p.Currency)
+		}
+	}
+
+	return report, nil
+}
+
+// priceTarget sums a target's components into one hourly price
+func priceTarget(ctx context.Context, resolver RateResolver, target CompareTarget) (ProviderPrice, error) {
+	price := ProviderPrice{
+		Cloud:       target.Cloud,
+		Region:      target.Region,
+		Offering:    target.Offering,
+		HourlyPrice: decimal.Zero,
+		Confidence:  1.0,
+	}
+
+	for _, c := range target.Components {
+		res, err := resolver.Resolve(ctx, c.Request)
+		if err != nil {
+			return price, err
+		}
+		if res.IsSymbolic || res.Price == nil {
+			price.IsSymbolic = true
+			price.Reason = res.Reason
+			price.HourlyPrice = decimal.Zero
+			return price, nil
+		}
+		price.HourlyPrice = price.HourlyPrice.Add(res.Price.Mul(decimal.NewFromFloat(c.Quantity)))
+		price.Currency = res.Currency
+		if res.Confidence < price.Confidence {
+			price.Confidence = res.Confidence
+		}
+	}
+
+	price.MonthlyCost = price.HourlyPrice.Mul(decimal.NewFromInt(HoursPerMonth))
+	return price, nil
+}
@@ -0,0 +1,137 @@
+// Package estimate - Cross-cloud comparison tests
+package estimate
+
+import (
+	"context"
+	"testing"
+
+	"terraform-cost/db"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// seededRate is one stored rate in seededStore
+type seededRate struct {
+	key   db.RateKey
+	unit  string
+	price string
+}
+
+// seededStore holds one active snapshot per cloud/region and resolves rates
+// by attribute containment like the PostgreSQL query
+type seededStore struct {
+	db.PricingStore
+	rates []seededRate
+}
+
+func (s *seededStore) GetActiveSnapshot(ctx context.Context, cloud db.CloudProvider, region, alias string) (*db.PricingSnapshot, error) {
+	for _, r := range s.rates {
+		if r.key.Cloud == cloud && r.key.Region == region {
+			return &db.PricingSnapshot{ID: uuid.New(), Cloud: cloud, Region: region, ProviderAlias: alias, IsActive: true}, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *seededStore) ResolveRate(ctx context.Context, cloud db.CloudProvider, service, productFamily, region string, attrs map[string]string, unit, alias string, opts db.ResolveOptions) (*db.ResolvedRate, error) {
+	matches, err := s.ResolveAllMatching(ctx, cloud, service, productFamily, region, attrs, unit, alias, opts)
+	if err != nil || len(matches) == 0 {
+		return nil, err
+	}
+	return &matches[0], nil
+}
+
+func (s *seededStore) ResolveAllMatching(ctx context.Context, cloud db.CloudProvider, service, productFamily, region string, attrs map[string]string, unit, alias string, opts db.ResolveOptions) ([]db.ResolvedRate, error) {
+	var matches []db.ResolvedRate
+	for _, r := range s.rates {
+		k := r.key
+		if k.Cloud != cloud || k.Service != service || k.ProductFamily != productFamily || k.Region != region || r.unit != unit {
+			continue
+		}
+		if db.AttributesContain(k.Attributes, attrs) {
+			matches = append(matches, db.ResolvedRate{Price: dec(r.price), Currency: "USD", Confidence: 1.0, Attributes: k.Attributes})
+		}
+	}
+	return matches, nil
+}
+
+func TestComparePricingAcrossClouds(t *testing.T) {
+	store := &seededStore{rates: []seededRate{
+		{db.RateKey{Cloud: db.AWS, Service: "AmazonEC2", ProductFamily: "Compute Instance", Region: "us-east-1",
+			Attributes: map[string]string{"instance_type": "m6i.2xlarge", "os": "linux", "tenancy": "shared", "capacity_status": "used"}},
+			"hours", "0.384"},
+		{db.RateKey{Cloud: db.Azure, Service: "Virtual Machines", ProductFamily: "Compute", Region: "eastus",
+			Attributes: map[string]string{"vm_size": "standard_d8s_v5", "type": "consumption", "meter_name": "d8s v5"}},
+			"hours", "0.376"},
+		{db.RateKey{Cloud: db.GCP, Service: "Compute Engine", ProductFamily: "Compute", Region: "us-central1",
+			Attributes: map[string]string{"machine_type": "n2", "machine_class": "predefined", "resource_type": "cpu", "usage_type": "on_demand"}},
+			"hours", "0.031611"},
+		{db.RateKey{Cloud: db.GCP, Service: "Compute Engine", ProductFamily: "Compute", Region: "us-central1",
+			Attributes: map[string]string{"machine_type": "n2", "machine_class": "predefined", "resource_type": "ram", "usage_type": "on_demand"}},
+			"GB-hours", "0.004237"},
+	}}
+
+	spec, err := NewComputeCompareSpec(ComputeProfile{VCPUs: 8, MemoryGiB: 32}, map[db.CloudProvider]string{
+		db.AWS:   "us-east-1",
+		db.Azure: "eastus",
+		db.GCP:   "us-central1",
+	})
+	if err != nil {
+		t.Fatalf("spec failed: %v", err)
+	}
+
+	report, err := ComparePricing(context.Background(), db.NewStrictResolver(store), spec)
+	if err != nil {
+		t.Fatalf("compare failed: %v", err)
+	}
+	if len(report.Prices) != 3 {
+		t.Fatalf("expected 3 provider prices, got %d", len(report.Prices))
+	}
+
+	// GCP: 8 * 0.031611 + 32 * 0.004237 = 0.388472
+	want := []struct {
+		cloud  db.CloudProvider
+		hourly string
+	}{
+		{db.Azure, "0.376"},
+		{db.AWS, "0.384"},
+		{db.GCP, "0.388472"},
+	}
+	for i, w := range want {
+		p := report.Prices[i]
+		if p.Cloud != w.cloud || !p.HourlyPrice.Equal(dec(w.hourly)) || p.IsSymbolic {
+			t.Errorf("price %d = %s %s (symbolic=%t), want %s %s", i, p.Cloud, p.HourlyPrice, p.IsSymbolic, w.cloud, w.hourly)
+		}
+	}
+
+	if report.Cheapest == nil || report.Cheapest.Cloud != db.Azure || report.Cheapest.Offering != "Standard_D8s_v5" {
+		t.Fatalf("unexpected cheapest: %+v", report.Cheapest)
+	}
+	if !report.Cheapest.MonthlyCost.Equal(dec("0.376").Mul(decimal.NewFromInt(HoursPerMonth))) {
+		t.Errorf("monthly cost = %s", report.Cheapest.MonthlyCost)
+	}
+}
+
+func TestComparePricingKeepsMissingRatesSymbolic(t *testing.T) {
+	store := &seededStore{rates: []seededRate{
+		{db.RateKey{Cloud: db.AWS, Service: "AmazonEC2", ProductFamily: "Compute Instance", Region: "us-east-1",
+			Attributes: map[string]string{"instance_type": "m6i.xlarge"}}, "hours", "0.192"},
+	}}
+
+	spec, err := NewComputeCompareSpec(ComputeProfile{VCPUs: 8, MemoryGiB: 32}, map[db.CloudProvider]string{db.AWS: "us-east-1"})
+	if err != nil {
+		t.Fatalf("spec failed: %v", err)
+	}
+	report, err := ComparePricing(context.Background(), db.NewStrictResolver(store), spec)
+	if err != nil {
+		t.Fatalf("compare failed: %v", err)
+	}
+	if report.Cheapest != nil || !report.Prices[0].IsSymbolic {
+		t.Errorf("expected only a symbolic price, got %+v", report)
+	}
+
+	if _, err := NewComputeCompareSpec(ComputeProfile{VCPUs: 8, MemoryGiB: 64}, map[db.CloudProvider]string{db.AWS: "us-east-1"}); err == nil {
+		t.Error("expected an error for an unmapped profile")
+	}
+}
@@ -0,0 +1,194 @@
+// Package estimate - Monthly cost estimation from planned resources and resolved rates
+package estimate
+
+import (
+	"context"
+	"fmt"
+
+	"terraform-cost/db"
+	"terraform-cost/plan"
+
+	"github.com/shopspring/decimal"
+)
+
+// HoursPerMonth is the billing convention for hourly rates
+const HoursPerMonth = db.HoursPerMonth
+
+// RateResolver resolves pricing for a request (implemented by db.StrictResolver)
+type RateResolver interface {
+	Resolve(ctx context.Context, req db.ResolutionRequest) (*db.ResolutionResult, error)
+	ResolveTiered(ctx context.Context, req db.ResolutionRequest) (*db.TieredResolutionResult, error)
+}
+
+// LineItem is the monthly cost of one priced component
+type LineItem struct {
+	Component      string           `json:"component"`
+	Service        string           `json:"service"`
+	Unit           string           `json:"unit"`
+	Quantity       decimal.Decimal  `json:"quantity"` // monthly quantity in Unit
+	QuantitySource QuantitySource   `json:"quantity_source"`
+	UnitPrice      *decimal.Decimal `json:"unit_price,omitempty"` // nil for tiered or symbolic
+	MonthlyCost    decimal.Decimal  `json:"monthly_cost"`
+	Confidence     float64          `json:"confidence"`
+	IsSymbolic     bool             `json:"is_symbolic"`
+	Reason         string           `json:"reason,omitempty"`
+}
+
+// ResourceCost is the projected monthly cost of one resource
+type ResourceCost struct {
+	Address     string            `json:"address"`
+	Type        string            `json:"type"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	MonthlyCost decimal.Decimal   `json:"monthly_cost"`
+	IsSymbolic  bool              `json:"is_symbolic"` // any component unpriced
+	Components  []LineItem        `json:"components"`
+}
+
+// CostReport is the estimate for a whole plan
+type CostReport struct {
+	Resources        []ResourceCost  `json:"resources"`
+	TotalMonthlyCost decimal.Decimal `json:"total_monthly_cost"`
+	Currency         string          `json:"currency"`
+
+	// Confidence is the lowest component confidence, scaled by the
+	// fraction of components that could be priced
+	Confidence    float64 `json:"confidence"`
+	Partial       bool    `json:"partial"` // total excludes symbolic components
+	SymbolicCount int     `json:"symbolic_count"`
+}
+
+// DefaultCurrency is the currency estimates are priced in unless overridden
+const DefaultCurrency = "USD"
+
+// Estimator projects monthly costs for planned resources
+type Estimator struct {
+	resolver RateResolver
+	currency string
+}
+
+// NewEstimator creates an estimator backed by a rate resolver
+func NewEstimator(resolver RateResolver) *Estimator {
+	return &Estimator{resolver: resolver, currency: DefaultCurrency}
+}
+
+// WithCurrency prices every component in currency; rates in other
+// currencies are treated as missing rather than summed together
+func (e *Estimator) WithCurrency(currency string) *Estimator {
+	e.currency = currency
+	return e
+}
+
+// Estimate resolves every request and aggregates monthly costs per resource.
+// Hourly rates are multiplied by HoursPerMonth; monthly and per-unit rates
+// are priced through their tiers using the planned or assumed quantity.
+func (e *Estimator) Estimate(ctx context.Context, requests []plan.ResourceRequest, usage UsageAssumptions) (*CostReport, error) {
+	report := &CostReport{
+		TotalMonthlyCost: decimal.Zero,
+		Currency:         e.currency,
+		Confidence:       1.0,
+	}
+
+	index := make(map[string]int)
+	priced := 0
+	for _, rr := range requests {
+		item, err := e.estimateComponent(ctx, rr, usage)
+		if err != nil {
+			return nil, fmt.Errorf("%s (%s): %w", rr.Address, rr.Component, err)
+		}
+
+		i, ok := index[rr.Address]
+		if !ok {
+			i = len(report.Resources)
+			index[rr.Address] = i
+			report.Resources = append(report.Resources, ResourceCost{
+				Address:     rr.Address,
+				Type:        rr.Type,
+				Tags:        rr.Tags,
+				MonthlyCost: decimal.Zero,
+			})
+		}
+		res := &report.Resources[i]
+		res.Components = append(res.Components, item)
+
+		if item.IsSymbolic {
+			res.IsSymbolic = true
+			report.SymbolicCount++
+			continue
+		}
+		priced++
+		res.MonthlyCost = res.MonthlyCost.Add(item.MonthlyCost)
+		report.TotalMonthlyCost = report.TotalMonthlyCost.Add(item.MonthlyCost)
+		if item.Confidence < report.Confidence {
+			report.Confidence = item.Confidence
+		}
+	}
+
+	total := priced + report.SymbolicCount
+	switch {
+	case total == 0:
+		report.Confidence = 0
+	case report.SymbolicCount > 0:
+		report.Partial = true
+		if priced == 0 {
+			report.Confidence = 0
+		} else {
+			report.Confidence *= float64(priced) / float64(total)
+		}
+	}
+
+	return report, nil
+}
+
+// estimateComponent prices a single component for one month
+func (e *Estimator) estimateComponent(ctx context.Context, rr plan.ResourceRequest, usage UsageAssumptions) (LineItem, error) {
+	item := LineItem{
+		Component: rr.Component,
+		Service:   rr.Request.Service,
+		Unit:      rr.Request.Unit,
+	}
+
+	req := rr.Request
+	if req.Currency == "" {
+		req.Currency = e.currency
+	}
+
+	hourly := db.IsHourlyUnit(rr.Request.Unit)
+	quantity, source := usage.quantityFor(rr)
+	if hourly && source == QuantityFromPlan {
+		quantity *= HoursPerMonth
+	}
+	item.Quantity = decimal.NewFromFloat(quantity)
+	item.QuantitySource = source
+
+	if hourly {
+		res, err := e.resolver.Resolve(ctx, req)
+		if err != nil {
+			return item, err
+		}
+		if res.IsSymbolic || res.Price == nil {
+			item.IsSymbolic = true
+			item.Reason = res.Reason
+			return item, nil
+		}
+		item.UnitPrice = res.Price
+		item.MonthlyCost = res.Price.Mul(item.Quantity)
+		item.Confidence = res.Confidence
+		return item, nil
+	}
+
+	tiered, err := e.resolver.ResolveTiered(ctx, req)
+	if err != nil {
+		return item, err
+	}
+	if tiered.IsSymbolic || len(tiered.Tiers) == 0 {
+		item.IsSymbolic = true
+		item.Reason = tiered.Reason
+		return item, nil
+	}
+	if len(tiered.Tiers) == 1 {
+		price := tiered.Tiers[0].Price
+		item.UnitPrice = &price
+	}
+	item.MonthlyCost, item.Confidence = tiered.CalculateCost(item.Quantity)
+	return item, nil
+}
@@ -0,0 +1,202 @@
+// Package estimate - Estimator tests
+package estimate
+
+import (
+	"context"
+	"testing"
+
+	"terraform-cost/db"
+	"terraform-cost/plan"
+
+	"github.com/shopspring/decimal"
+)
+
+// fakeResolver serves rates keyed by "service/unit"
+type fakeResolver struct {
+	rates map[string]db.TieredRate
+	tiers map[string][]db.TieredRate
+}
+
+func (f *fakeResolver) Resolve(ctx context.Context, req db.ResolutionRequest) (*db.ResolutionResult, error) {
+	rate, ok := f.rates[req.Service+"/"+req.Unit]
+	if !ok {
+		return &db.ResolutionResult{IsSymbolic: true, Reason: "rate not found"}, nil
+	}
+	return &db.ResolutionResult{Price: &rate.Price, Currency: "USD", Confidence: rate.Confidence}, nil
+}
+
+func (f *fakeResolver) ResolveTiered(ctx context.Context, req db.ResolutionRequest) (*db.TieredResolutionResult, error) {
+	tiers, ok := f.tiers[req.Service+"/"+req.Unit]
+	if !ok {
+		return &db.TieredResolutionResult{IsSymbolic: true, Reason: "tiered rates not found"}, nil
+	}
+	return &db.TieredResolutionResult{Tiers: tiers}, nil
+}
+
+func dec(s string) decimal.Decimal {
+	return decimal.RequireFromString(s)
+}
+
+func decPtr(s string) *decimal.Decimal {
+	d := dec(s)
+	return &d
+}
+
+func request(address, component, service, unit string, quantity float64) plan.ResourceRequest {
+	return plan.ResourceRequest{
+		Address:   address,
+		Type:      "aws_test",
+		Component: component,
+		Quantity:  quantity,
+		Request:   db.ResolutionRequest{Cloud: db.AWS, Service: service, Region: "us-east-1", Unit: unit},
+	}
+}
+
+func TestEstimateInstanceAndVolume(t *testing.T) {
+	resolver := &fakeResolver{
+		rates: map[string]db.TieredRate{
+			"AmazonEC2/hours": {Price: dec("0.0104"), Confidence: 1.0},
+		},
+		tiers: map[string][]db.TieredRate{
+			"AmazonEC2/GB-month": {{Min: decimal.Zero, Price: dec("0.08"), Confidence: 0.9}},
+		},
+	}
+
+	requests := []plan.ResourceRequest{
+		request("aws_instance.web", "instance", "AmazonEC2", "hours", 1),
+		request("aws_instance.web", "root_volume", "AmazonEC2", "GB-month", 20),
+		request("aws_ebs_volume.data", "storage", "AmazonEC2", "GB-month", 100),
+	}
+
+	report, err := NewEstimator(resolver).Estimate(context.Background(), requests, UsageAssumptions{})
+	if err != nil {
+		t.Fatalf("estimate failed: %v", err)
+	}
+
+	if len(report.Resources) != 2 {
+		t.Fatalf("expected 2 resources, got %d", len(report.Resources))
+	}
+
+	// 0.0104 * 730 + 0.08 * 20
+	web := report.Resources[0]
+	if !web.MonthlyCost.Equal(dec("9.192")) {
+		t.Errorf("web monthly cost = %s, want 9.192", web.MonthlyCost)
+	}
+	if !web.Components[0].Quantity.Equal(dec("730")) {
+		t.Errorf("instance hours = %s, want 730", web.Components[0].Quantity)
+	}
+
+	// 0.08 * 100
+	if data := report.Resources[1]; !data.MonthlyCost.Equal(dec("8")) {
+		t.Errorf("volume monthly cost = %s, want 8", data.MonthlyCost)
+	}
+
+	if !report.TotalMonthlyCost.Equal(dec("17.192")) {
+		t.Errorf("total = %s, want 17.192", report.TotalMonthlyCost)
+	}
+	if report.Partial || report.Confidence != 0.9 {
+		t.Errorf("expected complete estimate at confidence 0.9, got partial=%v confidence=%v", report.Partial, report.Confidence)
+	}
+}
+
+func TestEstimateTieredStorage(t *testing.T) {
+	resolver := &fakeResolver{
+		tiers: map[string][]db.TieredRate{
+			"AmazonS3/GB-month": {
+				{Min: dec("0"), Max: decPtr("51200"), Price: dec("0.023"), Confidence: 1.0},
+				{Min: dec("51200"), Price: dec("0.022"), Confidence: 1.0},
+			},
+		},
+	}
+
+	requests := []plan.ResourceRequest{request("aws_s3_bucket.logs", "storage", "AmazonS3", "GB-month", 0)}
+	usage := UsageAssumptions{Resources: map[string]float64{"aws_s3_bucket.logs.storage": 61200}}
+
+	report, err := NewEstimator(resolver).Estimate(context.Background(), requests, usage)
+	if err != nil {
+		t.Fatalf("estimate failed: %v", err)
+	}
+
+	// 51200 * 0.023 + 10000 * 0.022
+	if !report.TotalMonthlyCost.Equal(dec("1397.6")) {
+		t.Errorf("tiered total = %s, want 1397.6", report.TotalMonthlyCost)
+	}
+	if report.Resources[0].Components[0].UnitPrice != nil {
+		t.Error("tiered components should not report a single unit price")
+	}
+}
+
+func TestEstimateMarksMissingPricingSymbolic(t *testing.T) {
+	resolver := &fakeResolver{
+		rates: map[string]db.TieredRate{
+			"AmazonEC2/hours": {Price: dec("0.096"), Confidence: 1.0},
+		},
+	}
+
+	requests := []plan.ResourceRequest{
+		request("aws_instance.app", "instance", "AmazonEC2", "hours", 1),
+		request("aws_instance.app", "root_volume", "AmazonEC2", "GB-month", 8),
+	}
+
+	report, err := NewEstimator(resolver).Estimate(context.Background(), requests, UsageAssumptions{})
+	if err != nil {
+		t.Fatalf("estimate failed: %v", err)
+	}
+
+	if !report.Partial || report.SymbolicCount != 1 {
+		t.Errorf("expected partial report with 1 symbolic component, got %+v", report)
+	}
+	if report.Confidence != 0.5 {
+		t.Errorf("confidence = %v, want 0.5 (half the components priced)", report.Confidence)
+	}
+	app := report.Resources[0]
+	if !app.IsSymbolic || !app.Components[1].IsSymbolic || app.Components[1].Reason == "" {
+		t.Errorf("expected symbolic root volume with reason, got %+v", app.Components[1])
+	}
+	// Only the instance is included in the total
+	if !report.TotalMonthlyCost.Equal(dec("70.08")) {
+		t.Errorf("total = %s, want 70.08", report.TotalMonthlyCost)
+	}
+}
+
+// recordingResolver notes the currency of every request it serves
+type recordingResolver struct {
+	fakeResolver
+	currencies []string
+}
+
+func (r *recordingResolver) Resolve(ctx context.Context, req db.ResolutionRequest) (*db.ResolutionResult, error) {
+	r.currencies = append(r.currencies, req.Currency)
+	return r.fakeResolver.Resolve(ctx, req)
+}
+
+func (r *recordingResolver) ResolveTiered(ctx context.Context, req db.ResolutionRequest) (*db.TieredResolutionResult, error) {
+	r.currencies = append(r.currencies, req.Currency)
+	return r.fakeResolver.ResolveTiered(ctx, req)
+}
+
+func TestEstimateRequestsOneCurrency(t *testing.T) {
+	resolver := &recordingResolver{fakeResolver: fakeResolver{
+		rates: map[string]db.TieredRate{"AmazonEC2/hours": {Price: dec("0.0104"), Confidence: 1.0}},
+	}}
+	requests := []plan.ResourceRequest{
+		request("aws_instance.web", "compute", "AmazonEC2", "hours", 1),
+		request("aws_ebs_volume.data", "storage", "AmazonEC2", "GB-Mo", 100),
+	}
+
+	report, err := NewEstimator(resolver).WithCurrency("EUR").Estimate(context.Background(), requests, UsageAssumptions{})
+	if err != nil {
+		t.Fatalf("estimate failed: %v", err)
+	}
+	if report.Currency != "EUR" {
+		t.Errorf("report currency = %s, want EUR", report.Currency)
+	}
+	for i, c := range resolver.currencies {
+		if c != "EUR" {
+			t.Errorf("request %d resolved in %q, want EUR", i, c)
+		}
+	}
+	if len(resolver.currencies) != 2 {
+		t.Errorf("expected 2 resolutions, got %d", len(resolver.currencies))
+	}
+}
@@ -0,0 +1,198 @@
+// Package estimate - Instance resizing recommendations from planned resources
+package estimate
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"terraform-cost/db"
+	"terraform-cost/plan"
+
+	"github.com/shopspring/decimal"
+)
+
+// sizeAttribute is the rate key attribute holding an instance size
+const sizeAttribute = "instance_type"
+
+// CatalogLister lists every rate key matching partial attributes
+// (implemented by db.PricingStore)
+type CatalogLister interface {
+	ResolveAllMatching(ctx context.Context, cloud db.CloudProvider, service, productFamily, region string, attrs map[string]string, unit, alias string, opts db.ResolveOptions) ([]db.ResolvedRate, error)
+}
+
+// Recommendation is a cheaper size of the same instance family
+type Recommendation struct {
+	Address              string          `json:"address"`
+	Component            string          `json:"component"`
+	CurrentType          string          `json:"current_type"`
+	SuggestedType        string          `json:"suggested_type"`
+	CurrentMonthlyCost   decimal.Decimal `json:"current_monthly_cost"`
+	SuggestedMonthlyCost decimal.Decimal `json:"suggested_monthly_cost"`
+	MonthlySavings       decimal.Decimal `json:"monthly_savings"`
+
+	// ReducesVCPU and ReducesMemory flag downgrades that cut capacity,
+	// when the catalog lists vcpu and memory for both sizes
+	ReducesVCPU   bool `json:"reduces_vcpu"`
+	ReducesMemory bool `json:"reduces_memory"`
+}
+
+// String formats the recommendation as "<address>: t3.large → t3.medium saves 30.37/mo"
+func (r Recommendation) String() string {
+	return fmt.Sprintf("%s: %s → %s saves %s/mo", r.Address, r.CurrentType, r.SuggestedType, r.MonthlySavings.StringFixed(2))
+}
+
+// Recommender suggests cheaper sizes for planned instances
+type Recommender struct {
+	estimator *Estimator
+	catalog   CatalogLister
+}
+
+// NewRecommender creates a recommender pricing sizes with estimator and
+// finding them in catalog
+func NewRecommender(estimator *Estimator, catalog CatalogLister) *Recommender {
+	return &Recommender{estimator: estimator, catalog: catalog}
+}
+
+// Recommend prices every cheaper size in the same family as each planned
+// instance and returns them ranked by monthly savings. Sizes only differ in
+// instance_type; every other requested attribute is kept.
+func (r *Recommender) Recommend(ctx context.Context, requests []plan.ResourceRequest, usage UsageAssumptions) ([]Recommendation, error) {
+	var recommendations []Recommendation
+	for _, rr := range requests {
+		current, ok := rr.Request.Attributes[sizeAttribute]
+		if !ok || !db.IsHourlyUnit(rr.Request.Unit) {
+			continue
+		}
+		recs, err := r.recommendSizes(ctx, rr, current, usage)
+		if err != nil {
+			return nil, fmt.Errorf("%s (%s): %w", rr.Address, rr.Component, err)
+		}
+		recommendations = append(recommendations, recs...)
+	}
+
+	sort.SliceStable(recommendations, func(i, j int) bool {
+		return recommendations[i].MonthlySavings.GreaterThan(recommendations[j].MonthlySavings)
+	})
+	return recommendations, nil
+}
+
+// recommendSizes prices the cheaper sizes of one component's family
+func (r *Recommender) recommendSizes(ctx context.Context, rr plan.ResourceRequest, current string, usage UsageAssumptions) ([]Recommendation, error) {
+	currentItem, err := r.estimator.estimateComponent(ctx, rr, usage)
+	if err != nil || currentItem.IsSymbolic {
+		return nil, err
+	}
+
+	req := rr.Request
+	alias := req.Alias
+	if alias == "" {
+		alias = "default"
+	}
+	others := make(map[string]string, len(req.Attributes))
+	for k, v := range req.Attributes {
+		if k != sizeAttribute {
+			others[k] = v
+		}
+	}
+	currency := req.Currency
+	if currency == "" {
+		currency = r.estimator.currency
+	}
+	catalog, err := r.catalog.ResolveAllMatching(ctx, req.Cloud, req.Service, req.ProductFamily, req.Region, others, req.Unit, alias,
+		db.ResolveOptions{AsOf: req.AsOf, Currency: currency})
+	if err != nil {
+		return nil, err
+	}
+	specs := instanceSpecs(catalog)
+
+	family := instanceFamily(current)
+	var recs []Recommendation
+	for _, size := range db.DistinctAttributeValues(catalog, sizeAttribute) {
+		if size == current || instanceFamily(size) != family {
+			continue
+		}
+		candidate := rr
+		candidate.Request.Attributes = copyAttributes(req.Attributes)
+		candidate.Request.Attributes[sizeAttribute] = size
+		item, err := r.estimator.estimateComponent(ctx, candidate, usage)
+		if err != nil {
+			return nil, err
+		}
+		if item.IsSymbolic || !item.MonthlyCost.LessThan(currentItem.MonthlyCost) {
+			continue
+		}
+
+		rec := Recommendation{
+			Address:              rr.Address,
+			Component:            rr.Component,
+			CurrentType:          current,
+			SuggestedType:        size,
+			CurrentMonthlyCost:   currentItem.MonthlyCost,
+			SuggestedMonthlyCost: item.MonthlyCost,
+			MonthlySavings:       currentItem.MonthlyCost.Sub(item.MonthlyCost),
+		}
+		if from, ok := specs[current]; ok {
+			if to, ok := specs[size]; ok {
+				rec.ReducesVCPU = from.vcpu > 0 && to.vcpu > 0 && to.vcpu < from.vcpu
+				rec.ReducesMemory = from.memoryGiB > 0 && to.memoryGiB > 0 && to.memoryGiB < from.memoryGiB
+			}
+		}
+		recs = append(recs, rec)
+	}
+	return recs, nil
+}
+
+// instanceSpec is the capacity the catalog lists for an instance size
+type instanceSpec struct {
+	vcpu      float64
+	memoryGiB float64
+}
+
+// instanceSpecs reads the vcpu and memory ("8 GiB") attributes per size
+func instanceSpecs(rates []db.ResolvedRate) map[string]instanceSpec {
+	specs := make(map[string]instanceSpec)
+	for _, r := range rates {
+		size, ok := r.Attributes[sizeAttribute]
+		if !ok {
+			continue
+		}
+		if _, seen := specs[size]; seen {
+			continue
+		}
+		specs[size] = instanceSpec{
+			vcpu:      leadingNumber(r.Attributes["vcpu"]),
+			memoryGiB: leadingNumber(r.Attributes["memory"]),
+		}
+	}
+	return specs
+}
+
+// leadingNumber parses the number starting s ("1,952 GiB" -> 1952), 0 if none
+func leadingNumber(s string) float64 {
+	fields := strings.Fields(strings.ReplaceAll(s, ",", ""))
+	if len(fields) == 0 {
+		return 0
+	}
+	n, _ := strconv.ParseFloat(fields[0], 64)
+	return n
+}
+
+// instanceFamily is an instance type without its size: m5.large -> m5,
+// db.r6g.xlarge -> db.r6g
+func instanceFamily(instanceType string) string {
+	if i := strings.LastIndex(instanceType, "."); i > 0 {
+		return instanceType[:i]
+	}
+	return instanceType
+}
+
+func copyAttributes(attrs map[string]string) map[string]string {
+	c := make(map[string]string, len(attrs))
+	for k, v := range attrs {
+		c[k] = v
+	}
+	return c
+}
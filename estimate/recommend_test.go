@@ -0,0 +1,121 @@
+// Package estimate - Resizing recommendation tests
+package estimate
+
+import (
+	"context"
+	"testing"
+
+	"terraform-cost/db"
+	"terraform-cost/db/memstore"
+	"terraform-cost/plan"
+
+	"github.com/google/uuid"
+)
+
+// seedEC2Catalog commits an active us-east-1 snapshot of linux instances,
+// given as instance type -> {vcpu, memory, hourly price}
+func seedEC2Catalog(t *testing.T, instances map[string][3]string) *memstore.MemoryStore {
+	t.Helper()
+	ctx := context.Background()
+	store := memstore.NewMemoryStore()
+
+	tx, err := store.BeginTx(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshot := db.NewSnapshotBuilder(db.AWS, "us-east-1", "test").Build("hash-catalog")
+	if err := tx.CreateSnapshot(ctx, snapshot); err != nil {
+		t.Fatal(err)
+	}
+	for instanceType, spec := range instances {
+		key, err := tx.UpsertRateKey(ctx, &db.RateKey{
+			ID:            uuid.New(),
+			Cloud:         db.AWS,
+			Service:       "AmazonEC2",
+			ProductFamily: "Compute Instance",
+			Region:        "us-east-1",
+			Attributes: map[string]string{
+				"instance_type": instanceType, "os": "linux", "tenancy": "shared",
+				"vcpu": spec[0], "memory": spec[1],
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = tx.CreateRate(ctx, &db.PricingRate{
+			ID: uuid.New(), SnapshotID: snapshot.ID, RateKeyID: key.ID,
+			Unit: "hours", Price: dec(spec[2]), Currency: "USD", Confidence: 1.0,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tx.ActivateSnapshot(ctx, snapshot.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+func instanceRequest(address, instanceType string) plan.ResourceRequest {
+	return plan.ResourceRequest{
+		Address:   address,
+		Type:      "aws_instance",
+		Component: "instance",
+		Quantity:  1,
+		Request: db.ResolutionRequest{
+			Cloud: db.AWS, Service: "AmazonEC2", ProductFamily: "Compute Instance", Region: "us-east-1", Unit: "hours",
+			Attributes: map[string]string{"instance_type": instanceType, "os": "linux", "tenancy": "shared"},
+		},
+	}
+}
+
+func TestRecommenderSuggestsCheaperSameFamilySizes(t *testing.T) {
+	store := seedEC2Catalog(t, map[string][3]string{
+		"t3.micro":  {"2", "1 GiB", "0.0104"},
+		"t3.medium": {"2", "4 GiB", "0.0416"},
+		"t3.large":  {"2", "8 GiB", "0.0832"},
+		"t3.xlarge": {"4", "16 GiB", "0.1664"},
+		"m5.large":  {"2", "8 GiB", "0.0500"}, // cheaper, but another family
+	})
+	recommender := NewRecommender(NewEstimator(db.NewStrictResolver(store)), store)
+
+	recs, err := recommender.Recommend(context.Background(), []plan.ResourceRequest{
+		instanceRequest("aws_instance.web", "t3.large"),
+		instanceRequest("aws_instance.batch", "t3.xlarge"),
+		instanceRequest("aws_instance.tiny", "t3.micro"),
+	}, UsageAssumptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// xlarge has three cheaper t3 sizes, large two, micro none
+	if len(recs) != 5 {
+		t.Fatalf("expected 5 recommendations, got %d: %v", len(recs), recs)
+	}
+	for i, rec := range recs {
+		if rec.SuggestedType == "m5.large" {
+			t.Errorf("suggested another family: %s", rec)
+		}
+		if i > 0 && rec.MonthlySavings.GreaterThan(recs[i-1].MonthlySavings) {
+			t.Errorf("recommendations not ranked by savings: %s after %s", rec, recs[i-1])
+		}
+	}
+
+	best := recs[0]
+	if best.Address != "aws_instance.batch" || best.SuggestedType != "t3.micro" || !best.MonthlySavings.Equal(dec("113.88")) {
+		t.Errorf("expected t3.xlarge → t3.micro to save the most, got %s", best)
+	}
+	for _, rec := range recs {
+		if rec.Address == "aws_instance.web" && rec.SuggestedType == "t3.medium" {
+			if !rec.MonthlySavings.Equal(dec("30.368")) || rec.ReducesVCPU || !rec.ReducesMemory {
+				t.Errorf("t3.large → t3.medium: expected $30.368 savings cutting memory only, got %+v", rec)
+			}
+		}
+		if rec.Address == "aws_instance.batch" && !rec.ReducesVCPU {
+			t.Errorf("expected every downsize from t3.xlarge to cut vCPUs, got %+v", rec)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+// Package estimate - Usage assumptions for metered resources
+package estimate
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"terraform-cost/plan"
+)
+
+// QuantitySource records where a component's monthly quantity came from
+type QuantitySource string
+
+const (
+	QuantityFromResource QuantitySource = "resource" // per-address-and-component assumption
+	QuantityFromPlan     QuantitySource = "plan"     // planned configuration (instance count, volume size)
+	QuantityFromService  QuantitySource = "service"  // per-service assumption
+	QuantityUnspecified  QuantitySource = "none"     // usage-based with no assumption: priced at 0
+)
+
+// UsageAssumptions supplies monthly usage for usage-dependent components.
+//
+// A component's quantity is taken from, in order:
+//  1. Resources["address.component"]; a resource's components are keyed
+//     separately, so a Lambda's requests never take its duration quantity
+//  2. the planned quantity, when the configuration determines it
+//  3. Services["Service/unit"], e.g. "AWSLambda/requests"
+//
+// Anything else is usage-based with no assumption and costs 0.
+// Quantities are monthly, in the rate's unit: requests for Lambda
+// invocations, GB-seconds for Lambda duration, GB-month for S3 storage,
+// GB for NAT data processed.
+type UsageAssumptions struct {
+	Resources map[string]float64 `json:"resources,omitempty"`
+	Services  map[string]float64 `json:"services,omitempty"`
+}
+
+// LoadUsageAssumptions reads assumptions from JSON:
+//
+//	{"resources": {"aws_lambda_function.api.requests": 1000000},
+//	 "services":  {"AmazonS3/GB-month": 500}}
+func LoadUsageAssumptions(r io.Reader) (UsageAssumptions, error) {
+	var usage UsageAssumptions
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&usage); err != nil {
+		return UsageAssumptions{}, fmt.Errorf("failed to decode usage assumptions: %w", err)
+	}
+	if err := usage.Validate(); err != nil {
+		return UsageAssumptions{}, err
+	}
+	return usage, nil
+}
+
+// LoadUsageAssumptionsFile reads assumptions from a JSON file
+func LoadUsageAssumptionsFile(path string) (UsageAssumptions, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return UsageAssumptions{}, err
+	}
+	defer f.Close()
+	return LoadUsageAssumptions(f)
+}
+
+// Validate rejects empty keys, malformed service keys and negative quantities
+func (u UsageAssumptions) Validate() error {
+	for k, v := range u.Resources {
+		if k == "" {
+			return fmt.Errorf("usage assumption has empty resource address")
+		}
+		if v < 0 {
+			return fmt.Errorf("usage assumption for %s is negative: %v", k, v)
+		}
+	}
+	for k, v := range u.Services {
+		if service, unit, ok := strings.Cut(k, "/"); !ok || service == "" || unit == "" {
+			return fmt.Errorf("service usage key %q must be Service/unit", k)
+		}
+		if v < 0 {
+			return fmt.Errorf("usage assumption for %s is negative: %v", k, v)
+		}
+	}
+	return nil
+}
+
+// quantityFor returns the monthly quantity for a component and its source.
+// Planned quantities for hourly units are instance counts, not hours.
+func (u UsageAssumptions) quantityFor(rr plan.ResourceRequest) (float64, QuantitySource) {
+	if v, ok := u.Resources[rr.Address+"."+rr.Component]; ok {
+		return v, QuantityFromResource
+	}
+	if rr.Quantity > 0 {
+		return rr.Quantity, QuantityFromPlan
+	}
+	if v, ok := u.Services[rr.Request.Service+"/"+rr.Request.Unit]; ok {
+		return v, QuantityFromService
+	}
+	return 0, QuantityUnspecified
+}
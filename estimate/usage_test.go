@@ -0,0 +1,97 @@
+// Package estimate - Usage assumption tests
+package estimate
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"terraform-cost/db"
+	"terraform-cost/plan"
+)
+
+func TestEstimateLambdaFromUsageAssumptions(t *testing.T) {
+	usage, err := LoadUsageAssumptionsFile("testdata/usage.json")
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+
+	resolver := &fakeResolver{
+		tiers: map[string][]db.TieredRate{
+			"AWSLambda/requests":   {{Price: dec("0.0000002"), Confidence: 1.0}},
+			"AWSLambda/GB-seconds": {{Price: dec("0.0000166667"), Confidence: 1.0}},
+		},
+	}
+
+	requests := []plan.ResourceRequest{
+		request("aws_lambda_function.api", "requests", "AWSLambda", "requests", 0),
+		request("aws_lambda_function.api", "duration", "AWSLambda", "GB-seconds", 0),
+		request("aws_lambda_function.worker", "requests", "AWSLambda", "requests", 0),
+	}
+
+	report, err := NewEstimator(resolver).Estimate(context.Background(), requests, usage)
+	if err != nil {
+		t.Fatalf("estimate failed: %v", err)
+	}
+
+	// 5M requests * $0.20/M = $1.00
+	// 5M invocations * 250ms * 0.5GB = 625,000 GB-s * $0.0000166667 = $10.4166875
+	api := report.Resources[0]
+	if !api.Components[0].MonthlyCost.Equal(dec("1")) {
+		t.Errorf("request cost = %s, want 1", api.Components[0].MonthlyCost)
+	}
+	if !api.Components[1].MonthlyCost.Equal(dec("10.4166875")) {
+		t.Errorf("duration cost = %s, want 10.4166875", api.Components[1].MonthlyCost)
+	}
+	if api.Components[0].QuantitySource != QuantityFromResource {
+		t.Errorf("quantity source = %s, want resource", api.Components[0].QuantitySource)
+	}
+
+	// No assumption for the worker: usage-based defaults to zero, not symbolic
+	worker := report.Resources[1].Components[0]
+	if !worker.MonthlyCost.IsZero() || worker.IsSymbolic || worker.QuantitySource != QuantityUnspecified {
+		t.Errorf("expected zero-cost unspecified usage, got %+v", worker)
+	}
+	if report.Partial {
+		t.Error("unspecified usage should not make the report partial")
+	}
+}
+
+func TestUsageAssumptionPrecedence(t *testing.T) {
+	usage := UsageAssumptions{
+		Resources: map[string]float64{"aws_ebs_volume.big.storage": 2000, "aws_lambda_function.api": 1000000},
+		Services:  map[string]float64{"AmazonEC2/GB-month": 50, "AmazonS3/GB-month": 500},
+	}
+
+	cases := []struct {
+		rr     plan.ResourceRequest
+		want   float64
+		source QuantitySource
+	}{
+		{request("aws_ebs_volume.big", "storage", "AmazonEC2", "GB-month", 100), 2000, QuantityFromResource},
+		{request("aws_ebs_volume.small", "storage", "AmazonEC2", "GB-month", 10), 10, QuantityFromPlan},
+		{request("aws_s3_bucket.logs", "storage", "AmazonS3", "GB-month", 0), 500, QuantityFromService},
+		{request("aws_nat_gateway.main", "data_processed", "AmazonEC2", "GB", 0), 0, QuantityUnspecified},
+		// A bare address does not apply to every component of the resource
+		{request("aws_lambda_function.api", "duration", "AWSLambda", "GB-seconds", 0), 0, QuantityUnspecified},
+	}
+	for _, c := range cases {
+		got, source := usage.quantityFor(c.rr)
+		if got != c.want || source != c.source {
+			t.Errorf("%s: got %v (%s), want %v (%s)", c.rr.Address, got, source, c.want, c.source)
+		}
+	}
+}
+
+func TestLoadUsageAssumptionsValidation(t *testing.T) {
+	bad := []string{
+		`{"resources": {"aws_s3_bucket.logs": -1}}`,
+		`{"services": {"AmazonS3": 10}}`,
+		`{"unknown": {}}`,
+	}
+	for _, in := range bad {
+		if _, err := LoadUsageAssumptions(strings.NewReader(in)); err == nil {
+			t.Errorf("expected %s to be rejected", in)
+		}
+	}
+}
@@ -8,4 +8,4 @@ require (
 	github.com/shopspring/decimal v1.4.0
 )
 
-require github.com/golang-migrate/migrate/v4 v4.19.1 // indirect
+require github.com/golang-migrate/migrate/v4 v4.19.1
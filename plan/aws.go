@@ -0,0 +1,312 @@
+// Package plan - AWS resource mappings
+package plan
+
+import (
+	"fmt"
+	"strings"
+)
+
+// component is one priced dimension of a resource
+type component struct {
+	name          string
+	service       string
+	productFamily string
+	unit          string
+	attrs         map[string]string
+	quantity      float64
+}
+
+// resourceMapper converts planned attribute values into priced components
+type resourceMapper func(v attributeValues) ([]component, error)
+
+// awsMappers returns mappings for the AWS resources covered by stub pricing.
+// Attribute keys and values follow AWSPricingAPINormalizer's canonical form.
+func awsMappers() map[string]resourceMapper {
+	return map[string]resourceMapper{
+		"aws_instance":              mapAWSInstance,
+		"aws_ebs_volume":            mapAWSEBSVolume,
+		"aws_db_instance":           mapAWSDBInstance,
+		"aws_elasticache_cluster":   mapAWSElastiCacheCluster,
+		"aws_nat_gateway":           mapAWSNATGateway,
+		"aws_lb":                    mapAWSLoadBalancer,
+		"aws_alb":                   mapAWSLoadBalancer,
+		"aws_lambda_function":       mapAWSLambdaFunction,
+		"aws_s3_bucket":             mapAWSS3Bucket,
+		"aws_dynamodb_table":        mapAWSDynamoDBTable,
+		"aws_secretsmanager_secret": mapAWSSecretsManagerSecret,
+	}
+}
+
+func mapAWSInstance(v attributeValues) ([]component, error) {
+	instanceType, ok := v.str("instance_type")
+	if !ok {
+		return nil, fmt.Errorf("instance_type not known at plan time")
+	}
+
+	tenancy := "shared"
+	if t, ok := v.str("tenancy"); ok && t != "default" {
+		tenancy = t
+	}
+
+	components := []component{{
+		name:          "instance",
+		service:       "AmazonEC2",
+		productFamily: "Compute Instance",
+		unit:          "hours",
+		attrs: map[string]string{
+			"instance_type": strings.ToLower(instanceType),
+			"os":            "linux",
+			"tenancy":       tenancy,
+		},
+		quantity: 1,
+	}}
+
+	// Root volume is billed as EBS storage
+	if root, ok := v.block("root_block_device"); ok {
+		if size, ok := root.num("volume_size"); ok && size > 0 {
+			components = append(components, ebsStorage("root_volume", root.strOr("volume_type", "gp3"), size))
+		}
+	}
+
+	return components, nil
+}
+
+func mapAWSEBSVolume(v attributeValues) ([]component, error) {
+	size, ok := v.num("size")
+	if !ok {
+		return nil, fmt.Errorf("size not known at plan time")
+	}
+	volumeType := v.strOr("type", "gp2")
+
+	components := []component{ebsStorage("storage", volumeType, size)}
+
+	if iops, ok := v.num("iops"); ok && iops > 0 && volumeType != "gp2" {
+		components = append(components, component{
+			name:          "iops",
+			service:       "AmazonEC2",
+			productFamily: "Storage",
+			unit:          "iops-mo",
+			attrs:         map[string]string{"volume_type": volumeType, "usage_type": "iops"},
+			quantity:      iops,
+		})
+	}
+
+	return components, nil
+}
+
+// ebsStorage prices GB-months of an EBS volume type
+func ebsStorage(name, volumeType string, sizeGB float64) component {
+	return component{
+		name:          name,
+		service:       "AmazonEC2",
+		productFamily: "Storage",
+		unit:          "GB-month",
+		attrs:         map[string]string{"volume_type": strings.ToLower(volumeType)},
+		quantity:      sizeGB,
+	}
+}
+
+// awsRDSEngines maps Terraform engine names to pricing databaseEngine values
+var awsRDSEngines = map[string]string{
+	"mysql":             "mysql",
+	"postgres":          "postgresql",
+	"mariadb":           "mariadb",
+	"aurora-mysql":      "aurora mysql",
+	"aurora-postgresql": "aurora postgresql",
+}
+
+func mapAWSDBInstance(v attributeValues) ([]component, error) {
+	instanceClass, ok := v.str("instance_class")
+	if !ok {
+		return nil, fmt.Errorf("instance_class not known at plan time")
+	}
+	engine, ok := v.str("engine")
+	if !ok {
+		return nil, fmt.Errorf("engine not known at plan time")
+	}
+	pricingEngine, ok := awsRDSEngines[engine]
+	if !ok {
+		return nil, fmt.Errorf("unsupported RDS engine %q", engine)
+	}
+
+	components := []component{{
+		name:          "instance",
+		service:       "AmazonRDS",
+		productFamily: "Database Instance",
+		unit:          "hours",
+		attrs: map[string]string{
+			"instance_type": strings.ToLower(instanceClass),
+			"engine":        pricingEngine,
+		},
+		quantity: 1,
+	}}
+
+	if storage, ok := v.num("allocated_storage"); ok && storage > 0 {
+		volumeType := "general purpose (ssd)"
+		if st, _ := v.str("storage_type"); st == "io1" || st == "io2" {
+			volumeType = "provisioned iops (ssd)"
+		}
+		components = append(components, component{
+			name:          "storage",
+			service:       "AmazonRDS",
+			productFamily: "Database Storage",
+			unit:          "GB-month",
+			attrs:         map[string]string{"volume_class": volumeType},
+			quantity:      storage,
+		})
+	}
+
+	return components, nil
+}
+
+func mapAWSElastiCacheCluster(v attributeValues) ([]component, error) {
+	nodeType, ok := v.str("node_type")
+	if !ok {
+		return nil, fmt.Errorf("node_type not known at plan time")
+	}
+	engine, ok := v.str("engine")
+	if !ok {
+		return nil, fmt.Errorf("engine not known at plan time")
+	}
+	nodes, ok := v.num("num_cache_nodes")
+	if !ok || nodes < 1 {
+		nodes = 1
+	}
+
+	return []component{{
+		name:          "node",
+		service:       "AmazonElastiCache",
+		productFamily: "Cache Instance",
+		unit:          "hours",
+		attrs: map[string]string{
+			"instance_type": strings.ToLower(nodeType),
+			"cacheengine":   strings.ToLower(engine),
+		},
+		quantity: nodes,
+	}}, nil
+}
+
+func mapAWSNATGateway(v attributeValues) ([]component, error) {
+	return []component{
+		{
+			name:          "gateway",
+			service:       "AmazonEC2",
+			productFamily: "NAT Gateway",
+			unit:          "hours",
+			attrs:         map[string]string{"usage_type": "natgateway-hours"},
+			quantity:      1,
+		},
+		{
+			name:          "data_processed",
+			service:       "AmazonEC2",
+			productFamily: "NAT Gateway",
+			unit:          "GB",
+			attrs:         map[string]string{"usage_type": "natgateway-bytes"},
+		},
+	}, nil
+}
+
+// awsLBFamilies maps load_balancer_type to the pricing productFamily attribute
+var awsLBFamilies = map[string]string{
+	"application": "load balancer-application",
+	"network":     "load balancer-network",
+}
+
+func mapAWSLoadBalancer(v attributeValues) ([]component, error) {
+	lbType := v.strOr("load_balancer_type", "application")
+	family, ok := awsLBFamilies[lbType]
+	if !ok {
+		return nil, fmt.Errorf("unsupported load_balancer_type %q", lbType)
+	}
+
+	return []component{{
+		name:          "load_balancer",
+		service:       "ElasticLoadBalancing",
+		productFamily: "Load Balancer",
+		unit:          "hours",
+		attrs:         map[string]string{"product_family": family},
+		quantity:      1,
+	}}, nil
+}
+
+func mapAWSLambdaFunction(v attributeValues) ([]component, error) {
+	return []component{
+		{
+			name:          "requests",
+			service:       "AWSLambda",
+			productFamily: "Serverless",
+			unit:          "requests",
+			attrs:         map[string]string{"group": "aws-lambda-requests"},
+		},
+		{
+			name:          "duration",
+			service:       "AWSLambda",
+			productFamily: "Serverless",
+			unit:          "GB-seconds",
+			attrs:         map[string]string{"group": "aws-lambda-duration"},
+		},
+	}, nil
+}
+
+func mapAWSS3Bucket(v attributeValues) ([]component, error) {
+	return []component{{
+		name:          "storage",
+		service:       "AmazonS3",
+		productFamily: "Storage",
+		unit:          "GB-month",
+		attrs:         map[string]string{"storage_class": "general purpose"},
+	}}, nil
+}
+
+func mapAWSDynamoDBTable(v attributeValues) ([]component, error) {
+	if v.strOr("billing_mode", "PROVISIONED") == "PAY_PER_REQUEST" {
+		return []component{
+			{
+				name:          "write_requests",
+				service:       "AmazonDynamoDB",
+				productFamily: "Amazon DynamoDB PayPerRequest Throughput",
+				unit:          "writerequestunits",
+				attrs:         map[string]string{"group": "ddb-writeunits"},
+			},
+			{
+				name:          "read_requests",
+				service:       "AmazonDynamoDB",
+				productFamily: "Amazon DynamoDB PayPerRequest Throughput",
+				unit:          "readrequestunits",
+				attrs:         map[string]string{"group": "ddb-readunits"},
+			},
+		}, nil
+	}
+
+	wcu, _ := v.num("write_capacity")
+	rcu, _ := v.num("read_capacity")
+	return []component{
+		{
+			name:          "write_capacity",
+			service:       "AmazonDynamoDB",
+			productFamily: "Provisioned Throughput",
+			unit:          "writecapacityunit-hrs",
+			attrs:         map[string]string{"group": "ddb-writeunits"},
+			quantity:      wcu,
+		},
+		{
+			name:          "read_capacity",
+			service:       "AmazonDynamoDB",
+			productFamily: "Provisioned Throughput",
+			unit:          "readcapacityunit-hrs",
+			attrs:         map[string]string{"group": "ddb-readunits"},
+			quantity:      rcu,
+		},
+	}, nil
+}
+
+func mapAWSSecretsManagerSecret(v attributeValues) ([]component, error) {
+	return []component{{
+		name:          "secret",
+		service:       "AWSSecretsManager",
+		productFamily: "Secret",
+		unit:          "secrets",
+		attrs:         map[string]string{"usage_type": "secretmonth"},
+		quantity:      1,
+	}}, nil
+}
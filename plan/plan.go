@@ -0,0 +1,253 @@
+// Package plan - Terraform plan JSON parsing into pricing resolution requests
+package plan
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"terraform-cost/db"
+)
+
+// TerraformPlan is the subset of `terraform show -json` output we read
+type TerraformPlan struct {
+	FormatVersion   string                  `json:"format_version"`
+	Variables       map[string]PlanVariable `json:"variables"`
+	ResourceChanges []ResourceChange        `json:"resource_changes"`
+	Configuration   PlanConfiguration       `json:"configuration"`
+}
+
+// PlanVariable is a root module input variable value
+type PlanVariable struct {
+	Value interface{} `json:"value"`
+}
+
+// ResourceChange describes the planned change to one resource instance
+type ResourceChange struct {
+	Address           string `json:"address"`
+	ModuleAddress     string `json:"module_address,omitempty"`
+	Mode              string `json:"mode"`
+	Type              string `json:"type"`
+	Name              string `json:"name"`
+	ProviderName      string `json:"provider_name"`
+	ProviderConfigKey string `json:"provider_config_key,omitempty"`
+	Change            Change `json:"change"`
+}
+
+// Change holds the before/after values of a resource
+type Change struct {
+	Actions []string               `json:"actions"`
+	Before  map[string]interface{} `json:"before"`
+	After   map[string]interface{} `json:"after"`
+}
+
+// PlanConfiguration is the subset of the configuration block we read
+type PlanConfiguration struct {
+	ProviderConfig map[string]ProviderConfig `json:"provider_config"`
+}
+
+// ProviderConfig is a provider block from the configuration
+type ProviderConfig struct {
+	Name        string                        `json:"name"`
+	Alias       string                        `json:"alias,omitempty"`
+	Expressions map[string]ProviderExpression `json:"expressions,omitempty"`
+}
+
+// ProviderExpression is an argument expression in a provider block
+type ProviderExpression struct {
+	ConstantValue interface{} `json:"constant_value,omitempty"`
+	References    []string    `json:"references,omitempty"`
+}
+
+// ResourceRequest is one priced component of a planned resource
+type ResourceRequest struct {
+	Address   string
+	Type      string
+	Component string // e.g. "instance", "storage"
+
+	// Quantity of the priced unit the resource provisions: instance count
+	// for hourly rates, GB for storage. Zero means usage-dependent.
+	Quantity float64
+
+	// Tags are the resource's planned tags (tags_all when set)
+	Tags map[string]string
+
+	Request db.ResolutionRequest
+}
+
+// UnmappedResource is a resource that produced no resolution requests
+type UnmappedResource struct {
+	Address string
+	Type    string
+	Reason  string
+}
+
+// Result is the outcome of parsing a plan
+type Result struct {
+	Requests []ResourceRequest
+	Unmapped []UnmappedResource
+}
+
+// Parser converts Terraform plans into resolution requests
+type Parser struct {
+	defaultRegion string
+	alias         string
+	mappers       map[string]resourceMapper
+}
+
+// NewParser creates a parser with the built-in resource mappings
+func NewParser() *Parser {
+	return &Parser{
+		mappers: awsMappers(),
+	}
+}
+
+// WithDefaultRegion sets the region used when the plan does not pin one
+func (p *Parser) WithDefaultRegion(region string) *Parser {
+	p.defaultRegion = region
+	return p
+}
+
+// WithAlias sets the pricing provider alias on every request
+func (p *Parser) WithAlias(alias string) *Parser {
+	p.alias = alias
+	return p
+}
+
+// SupportedTypes returns the Terraform resource types that can be priced
+func (p *Parser) SupportedTypes() []string {
+	types := make([]string, 0, len(p.mappers))
+	for t := range p.mappers {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// ParseFile parses a plan JSON file
+func (p *Parser) ParseFile(path string) (*Result, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return p.Parse(f)
+}
+
+// Parse reads `terraform show -json` output and maps its resource changes
+func (p *Parser) Parse(r io.Reader) (*Result, error) {
+	var tfPlan TerraformPlan
+	if err := json.NewDecoder(r).Decode(&tfPlan); err != nil {
+		return nil, fmt.Errorf("failed to decode plan: %w", err)
+	}
+	if tfPlan.FormatVersion == "" {
+		return nil, fmt.Errorf("not a terraform plan: missing format_version")
+	}
+	return p.Map(&tfPlan), nil
+}
+
+// Map converts a decoded plan into resolution requests
+func (p *Parser) Map(tfPlan *TerraformPlan) *Result {
+	result := &Result{}
+
+	for _, rc := range tfPlan.ResourceChanges {
+		if rc.Mode != "managed" || !rc.willExist() {
+			continue
+		}
+
+		mapper, ok := p.mappers[rc.Type]
+		if !ok {
+			result.Unmapped = append(result.Unmapped, UnmappedResource{
+				Address: rc.Address,
+				Type:    rc.Type,
+				Reason:  "unsupported resource type",
+			})
+			continue
+		}
+
+		region := tfPlan.providerRegion(rc.ProviderConfigKey)
+		if region == "" {
+			region = p.defaultRegion
+		}
+		if region == "" {
+			result.Unmapped = append(result.Unmapped, UnmappedResource{
+				Address: rc.Address,
+				Type:    rc.Type,
+				Reason:  "region not known at plan time",
+			})
+			continue
+		}
+
+		values := attributeValues(rc.Change.After)
+		components, err := mapper(values)
+		if err != nil {
+			result.Unmapped = append(result.Unmapped, UnmappedResource{
+				Address: rc.Address,
+				Type:    rc.Type,
+				Reason:  err.Error(),
+			})
+			continue
+		}
+
+		for _, c := range components {
+			result.Requests = append(result.Requests, ResourceRequest{
+				Address:   rc.Address,
+				Type:      rc.Type,
+				Component: c.name,
+				Quantity:  c.quantity,
+				Tags:      values.tags(),
+				Request: db.ResolutionRequest{
+					Cloud:         db.AWS,
+					Service:       c.service,
+					ProductFamily: c.productFamily,
+					Region:        region,
+					Attributes:    c.attrs,
+					Unit:          c.unit,
+					Alias:         p.alias,
+				},
+			})
+		}
+	}
+
+	return result
+}
+
+// willExist reports whether the resource exists after apply
+func (rc ResourceChange) willExist() bool {
+	for _, a := range rc.Change.Actions {
+		if a == "create" || a == "update" || a == "no-op" {
+			return true
+		}
+	}
+	return false
+}
+
+// providerRegion returns the region configured for a provider, following
+// a single var.* reference into the plan's variables
+func (tp *TerraformPlan) providerRegion(configKey string) string {
+	if configKey == "" {
+		configKey = "aws"
+	}
+	cfg, ok := tp.Configuration.ProviderConfig[configKey]
+	if !ok {
+		return ""
+	}
+	expr, ok := cfg.Expressions["region"]
+	if !ok {
+		return ""
+	}
+	if s, ok := expr.ConstantValue.(string); ok {
+		return s
+	}
+	for _, ref := range expr.References {
+		if name := strings.TrimPrefix(ref, "var."); name != ref {
+			if v, ok := tp.Variables[name].Value.(string); ok {
+				return v
+			}
+		}
+	}
+	return ""
+}
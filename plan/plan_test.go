@@ -0,0 +1,124 @@
+// Package plan - Plan parsing tests
+package plan
+
+import (
+	"strings"
+	"testing"
+
+	"terraform-cost/db"
+)
+
+func TestParsePlanFixture(t *testing.T) {
+	result, err := NewParser().ParseFile("testdata/plan.json")
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+
+	want := []struct {
+		address, component, service, unit string
+		attrs                             map[string]string
+		quantity                          float64
+	}{
+		{"aws_instance.web", "instance", "AmazonEC2", "hours",
+			map[string]string{"instance_type": "t3.micro", "os": "linux", "tenancy": "shared"}, 1},
+		{"aws_instance.web", "root_volume", "AmazonEC2", "GB-month",
+			map[string]string{"volume_type": "gp3"}, 20},
+		{"aws_db_instance.main", "instance", "AmazonRDS", "hours",
+			map[string]string{"instance_type": "db.t3.micro", "engine": "postgresql"}, 1},
+		{"aws_db_instance.main", "storage", "AmazonRDS", "GB-month",
+			map[string]string{"volume_class": "general purpose (ssd)"}, 20},
+	}
+
+	if len(result.Requests) != len(want) {
+		t.Fatalf("expected %d requests, got %d: %+v", len(want), len(result.Requests), result.Requests)
+	}
+	for i, w := range want {
+		got := result.Requests[i]
+		if got.Address != w.address || got.Component != w.component || got.Quantity != w.quantity {
+			t.Errorf("request %d: got %s/%s x%v, want %s/%s x%v",
+				i, got.Address, got.Component, got.Quantity, w.address, w.component, w.quantity)
+		}
+		req := got.Request
+		if req.Cloud != db.AWS || req.Service != w.service || req.Unit != w.unit || req.Region != "us-west-2" {
+			t.Errorf("request %d: unexpected resolution request %+v", i, req)
+		}
+		for k, v := range w.attrs {
+			if req.Attributes[k] != v {
+				t.Errorf("request %d: %s = %q, want %q", i, k, req.Attributes[k], v)
+			}
+		}
+	}
+
+	// Planned tags ride along with every component of the resource
+	if tags := result.Requests[1].Tags; tags["Name"] != "web" {
+		t.Errorf("expected the instance tags on its components, got %v", tags)
+	}
+	if tags := result.Requests[2].Tags; tags != nil {
+		t.Errorf("expected no tags on the untagged database, got %v", tags)
+	}
+
+	// Deleted resources and data sources are skipped; unsupported types are reported
+	if len(result.Unmapped) != 1 {
+		t.Fatalf("expected 1 unmapped resource, got %+v", result.Unmapped)
+	}
+	if u := result.Unmapped[0]; u.Address != "aws_iam_role.app" || u.Reason != "unsupported resource type" {
+		t.Errorf("unexpected unmapped resource: %+v", u)
+	}
+}
+
+func TestParseUnknownValuesAreUnmapped(t *testing.T) {
+	planJSON := `{
+		"format_version": "1.2",
+		"resource_changes": [{
+			"address": "aws_instance.app", "mode": "managed", "type": "aws_instance",
+			"change": {"actions": ["create"], "after": {}, "after_unknown": {"instance_type": true}}
+		}],
+		"configuration": {"provider_config": {"aws": {"name": "aws", "expressions": {"region": {"constant_value": "eu-west-1"}}}}}
+	}`
+
+	result, err := NewParser().Parse(strings.NewReader(planJSON))
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if len(result.Requests) != 0 || len(result.Unmapped) != 1 {
+		t.Fatalf("expected one unmapped resource, got %+v", result)
+	}
+	if !strings.Contains(result.Unmapped[0].Reason, "instance_type") {
+		t.Errorf("expected reason to name the unknown attribute, got %q", result.Unmapped[0].Reason)
+	}
+}
+
+func TestParseDefaultRegion(t *testing.T) {
+	planJSON := `{
+		"format_version": "1.2",
+		"resource_changes": [{
+			"address": "aws_nat_gateway.main", "mode": "managed", "type": "aws_nat_gateway",
+			"change": {"actions": ["create"], "after": {}}
+		}]
+	}`
+
+	result, _ := NewParser().Parse(strings.NewReader(planJSON))
+	if len(result.Unmapped) != 1 || result.Unmapped[0].Reason != "region not known at plan time" {
+		t.Errorf("expected region to be required, got %+v", result)
+	}
+
+	result, _ = NewParser().WithDefaultRegion("us-east-1").WithAlias("prod").Parse(strings.NewReader(planJSON))
+	if len(result.Requests) != 2 {
+		t.Fatalf("expected gateway hours and data processed, got %+v", result.Requests)
+	}
+	for _, r := range result.Requests {
+		if r.Request.Region != "us-east-1" || r.Request.Alias != "prod" {
+			t.Errorf("expected default region and alias, got %+v", r.Request)
+		}
+	}
+	// Data processed depends on usage
+	if result.Requests[1].Component != "data_processed" || result.Requests[1].Quantity != 0 {
+		t.Errorf("expected usage-dependent data component, got %+v", result.Requests[1])
+	}
+}
+
+func TestParseRejectsNonPlan(t *testing.T) {
+	if _, err := NewParser().Parse(strings.NewReader(`{"resources": []}`)); err == nil {
+		t.Error("expected error for JSON without format_version")
+	}
+}
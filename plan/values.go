@@ -0,0 +1,69 @@
+// Package plan - Typed access to planned attribute values
+package plan
+
+import (
+	"strconv"
+)
+
+// attributeValues wraps a resource's planned attribute map
+type attributeValues map[string]interface{}
+
+// str returns a non-empty string (or number rendered as a string)
+func (v attributeValues) str(key string) (string, bool) {
+	switch val := v[key].(type) {
+	case string:
+		return val, val != ""
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64), true
+	}
+	return "", false
+}
+
+// strOr returns the string value or a default when unset
+func (v attributeValues) strOr(key, def string) string {
+	if s, ok := v.str(key); ok {
+		return s
+	}
+	return def
+}
+
+// num returns a numeric value, accepting numeric strings
+func (v attributeValues) num(key string) (float64, bool) {
+	switch val := v[key].(type) {
+	case float64:
+		return val, true
+	case string:
+		f, err := strconv.ParseFloat(val, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// tags returns a resource's tags, preferring tags_all, which includes the
+// provider's default_tags; nil when the resource has none
+func (v attributeValues) tags() map[string]string {
+	raw, ok := v["tags_all"].(map[string]interface{})
+	if !ok || len(raw) == 0 {
+		raw, _ = v["tags"].(map[string]interface{})
+	}
+	if len(raw) == 0 {
+		return nil
+	}
+	tags := make(map[string]string, len(raw))
+	for k, val := range raw {
+		if s, ok := val.(string); ok {
+			tags[k] = s
+		}
+	}
+	return tags
+}
+
+// block returns the first element of a nested block list
+func (v attributeValues) block(key string) (attributeValues, bool) {
+	list, ok := v[key].([]interface{})
+	if !ok || len(list) == 0 {
+		return nil, false
+	}
+	m, ok := list[0].(map[string]interface{})
+	return attributeValues(m), ok
+}